@@ -438,6 +438,17 @@ worker: Toast`,
 			want: `merge_commit: deadbeef
 close_reason: rejected`,
 		},
+		{
+			name: "release train fields",
+			fields: &MRFields{
+				Branch:      "polecat/Nux/gt-xyz",
+				ConvoyID:    "hq-cv-abc",
+				TrainStatus: TrainStatusGreen,
+			},
+			want: `branch: polecat/Nux/gt-xyz
+convoy_id: hq-cv-abc
+train_status: green`,
+		},
 	}
 
 	for _, tt := range tests {
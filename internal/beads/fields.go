@@ -187,8 +187,15 @@ type MRFields struct {
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt string // Convoy creation time (ISO 8601) for starvation prevention
+
+	// Release trains: set to TrainStatusGreen when tests pass and the MR is
+	// held until every member of its train is green
+	TrainStatus string
 }
 
+// TrainStatusGreen marks an MR that passed tests and is held for its release train.
+const TrainStatusGreen = "green"
+
 // ParseMRFields extracts structured merge-request fields from an issue's description.
 // Fields are expected as "key: value" lines, with optional prose text mixed in.
 // Returns nil if no MR fields are found.
@@ -261,6 +268,9 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "convoy_created_at", "convoy-created-at", "convoycreatedat":
 			fields.ConvoyCreatedAt = value
 			hasFields = true
+		case "train_status", "train-status", "trainstatus":
+			fields.TrainStatus = value
+			hasFields = true
 		}
 	}
 
@@ -325,6 +335,9 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConvoyCreatedAt != "" {
 		lines = append(lines, "convoy_created_at: "+fields.ConvoyCreatedAt)
 	}
+	if fields.TrainStatus != "" {
+		lines = append(lines, "train_status: "+fields.TrainStatus)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -371,6 +384,9 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"convoy_created_at":  true,
 		"convoy-created-at":  true,
 		"convoycreatedat":    true,
+		"train_status":       true,
+		"train-status":       true,
+		"trainstatus":        true,
 	}
 
 	// Collect non-MR lines from existing description
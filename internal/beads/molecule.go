@@ -6,6 +6,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/steveyegge/gastown/internal/flow"
 )
 
 // MoleculeStep represents a parsed step from a molecule definition.
@@ -18,6 +20,19 @@ type MoleculeStep struct {
 	Tier         string         // Optional tier hint: haiku, sonnet, opus
 	Type         string         // Step type: "task" (default), "wait", etc.
 	Backoff      *BackoffConfig // Backoff configuration for wait-type steps
+
+	// Control flow (see package flow for condition syntax)
+	When          string // Run only if this condition holds; skipped otherwise
+	Foreach       string // Comma-separated list; one step per item, as {{item}}
+	Until         string // Repeat the step until this condition holds
+	MaxIterations int    // Bound for Until (default 10)
+
+	Outputs []string // Named outputs recorded by gt mol step done --output
+
+	// Failure handling (see package flow)
+	Timeout   string // How long the step may run, e.g. "30m"
+	Retries   int    // Retries after a failure before OnFailure applies
+	OnFailure string // escalate (default), skip, fail-molecule or goto:<ref>
 }
 
 // BackoffConfig defines exponential backoff parameters for wait-type steps.
@@ -49,6 +64,34 @@ var typeLineRegex = regexp.MustCompile(`(?i)^Type:\s*(\w+)\s*$`)
 // Parses backoff configuration for wait-type steps.
 var backoffLineRegex = regexp.MustCompile(`(?i)^Backoff:\s*(.+)$`)
 
+// The control flow and failure handling lines below are case-sensitive, so
+// instruction prose such as "when: the build is green" stays prose.
+
+// whenLineRegex matches "When: <condition>" lines.
+var whenLineRegex = regexp.MustCompile(`^When:\s*(.+)$`)
+
+// foreachLineRegex matches "Foreach: item1, item2, ..." lines, usually a
+// {{variable}} expanded at instantiation.
+var foreachLineRegex = regexp.MustCompile(`^Foreach:\s*(.+)$`)
+
+// untilLineRegex matches "Until: <condition>" lines.
+var untilLineRegex = regexp.MustCompile(`^Until:\s*(.+)$`)
+
+// maxIterationsLineRegex matches "MaxIterations: N" lines.
+var maxIterationsLineRegex = regexp.MustCompile(`^MaxIterations:\s*(\d+)\s*$`)
+
+// outputsLineRegex matches "Outputs: key1, key2, ..." lines.
+var outputsLineRegex = regexp.MustCompile(`^Outputs:\s*(.+)$`)
+
+// timeoutLineRegex matches "Timeout: 30m" lines.
+var timeoutLineRegex = regexp.MustCompile(`^Timeout:\s*(\S+)\s*$`)
+
+// retriesLineRegex matches "Retries: N" lines.
+var retriesLineRegex = regexp.MustCompile(`^Retries:\s*(\d+)\s*$`)
+
+// onFailureLineRegex matches "OnFailure: escalate|skip|fail-molecule|goto:<ref>" lines.
+var onFailureLineRegex = regexp.MustCompile(`^OnFailure:\s*(.+?)\s*$`)
+
 // templateVarRegex matches {{variable}} placeholders.
 var templateVarRegex = regexp.MustCompile(`\{\{(\w+)\}\}`)
 
@@ -62,6 +105,14 @@ var templateVarRegex = regexp.MustCompile(`\{\{(\w+)\}\}`)
 //	Tier: haiku|sonnet|opus  # optional
 //	Type: task|wait  # optional, default is "task"
 //	Backoff: base=30s, multiplier=2, max=10m  # optional, for wait-type steps
+//	When: <condition>  # optional, skip the step when false
+//	Foreach: {{list}}  # optional, one step per comma-separated item
+//	Until: <condition>  # optional, repeat the step until true
+//	MaxIterations: 5  # optional, bound for Until (default 10)
+//	Outputs: approach, branch  # optional, values later steps use as {{steps.<ref>.<key>}}
+//	Timeout: 30m  # optional, how long the step may run
+//	Retries: 2  # optional, retries after a failure
+//	OnFailure: escalate|skip|fail-molecule|goto:<ref>  # optional, default escalate
 //
 // Returns an empty slice if no steps are found.
 func ParseMoleculeSteps(description string) ([]MoleculeStep, error) {
@@ -127,6 +178,44 @@ func ParseMoleculeSteps(description string) ([]MoleculeStep, error) {
 				continue
 			}
 
+			// Check for control flow lines
+			if matches := whenLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.When = strings.TrimSpace(matches[1])
+				continue
+			}
+			if matches := foreachLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.Foreach = strings.TrimSpace(matches[1])
+				continue
+			}
+			if matches := untilLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.Until = strings.TrimSpace(matches[1])
+				continue
+			}
+			if matches := maxIterationsLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.MaxIterations, _ = strconv.Atoi(matches[1])
+				continue
+			}
+
+			// Check for Outputs: line
+			if matches := outputsLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.Outputs = append(currentStep.Outputs, splitList(matches[1])...)
+				continue
+			}
+
+			// Check for failure handling lines
+			if matches := timeoutLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.Timeout = matches[1]
+				continue
+			}
+			if matches := retriesLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.Retries, _ = strconv.Atoi(matches[1])
+				continue
+			}
+			if matches := onFailureLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.OnFailure = strings.ToLower(matches[1])
+				continue
+			}
+
 			// Regular instruction line
 			instructionLines = append(instructionLines, line)
 		}
@@ -236,6 +325,15 @@ func ExpandTemplateVars(text string, ctx map[string]string) string {
 	})
 }
 
+// contextLookup resolves condition variables from an instantiation context.
+func contextLookup(ctx map[string]string) flow.Lookup {
+	return func(ref string) (string, bool) {
+		_, _, name := flow.SplitRef(ref)
+		v, ok := ctx[name]
+		return v, ok
+	}
+}
+
 // InstantiateOptions configures molecule instantiation behavior.
 type InstantiateOptions struct {
 	// Context map for {{variable}} substitution
@@ -361,6 +459,56 @@ func (b *Beads) instantiateFromChildren(mol *Issue, parent *Issue, templates []*
 
 // instantiateFromMarkdown creates steps from embedded markdown (old format).
 func (b *Beads) instantiateFromMarkdown(mol *Issue, parent *Issue, opts InstantiateOptions) ([]*Issue, error) {
+	drafts, err := DraftMoleculeSteps(mol, parent, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create child issues for each step
+	var createdIssues []*Issue
+	createdIDs := make(map[string]string) // draft ID -> issue ID
+	for _, draft := range drafts {
+		childOpts := CreateOptions{
+			Title:       draft.Title,
+			Type:        draft.Type,
+			Priority:    draft.Priority,
+			Description: draft.Description,
+			Parent:      draft.Parent,
+		}
+
+		child, err := b.Create(childOpts)
+		if err != nil {
+			// Attempt to clean up created issues on failure (best-effort cleanup)
+			for _, created := range createdIssues {
+				_ = b.Close(created.ID)
+			}
+			return nil, fmt.Errorf("creating step %q: %w", stepRef(draft), err)
+		}
+
+		createdIssues = append(createdIssues, child)
+		createdIDs[draft.ID] = child.ID
+	}
+
+	// Wire inter-step dependencies based on Needs: declarations
+	for _, draft := range drafts {
+		for _, dep := range draft.DependsOn {
+			childID, dependsOnID := createdIDs[draft.ID], createdIDs[dep]
+			if err := b.AddDependency(childID, dependsOnID); err != nil {
+				// Log but don't fail - the issues are created
+				// This is non-atomic but bd CLI doesn't support transactions
+				return createdIssues, fmt.Errorf("adding dependency %s -> %s: %w", childID, dependsOnID, err)
+			}
+		}
+	}
+
+	return createdIssues, nil
+}
+
+// DraftMoleculeSteps builds the step beads a markdown molecule
+// instantiates into, without creating them. Drafts are open, numbered
+// <parent>.<n> in step order, and depend on each other by those IDs, so
+// the molecule step functions can run a molecule in memory.
+func DraftMoleculeSteps(mol *Issue, parent *Issue, opts InstantiateOptions) ([]*Issue, error) {
 	// Parse steps from molecule
 	steps, err := ParseMoleculeSteps(mol.Description)
 	if err != nil {
@@ -385,68 +533,120 @@ func (b *Beads) instantiateFromMarkdown(mol *Issue, parent *Issue, opts Instanti
 			}
 		}
 	}
+	if err := validateStepControl(steps); err != nil {
+		return nil, err
+	}
 
-	// Create child issues for each step
-	var createdIssues []*Issue
-	stepIssueIDs := make(map[string]string) // step ref -> issue ID
+	// Draft a child issue for each step. A Foreach step becomes one child
+	// per item; a step with no items is dropped, and its dependents wait on
+	// what it waited on.
+	var drafts []*Issue
+	stepIssueIDs := make(map[string][]string) // step ref -> issue IDs
+	stepNeeds := make(map[string][]string)    // issue ID -> step refs it needs
 
 	for _, step := range steps {
-		// Expand template variables in instructions
-		instructions := step.Instructions
-		if opts.Context != nil {
-			instructions = ExpandTemplateVars(instructions, opts.Context)
+		items := []string{""}
+		if step.Foreach != "" {
+			items = flow.SplitList(ExpandTemplateVars(step.Foreach, opts.Context))
 		}
 
-		// Build description with provenance metadata
-		description := instructions
-		if description != "" {
-			description += "\n\n"
-		}
-		description += fmt.Sprintf("instantiated_from: %s\nstep: %s", mol.ID, step.Ref)
-		if step.Tier != "" {
-			description += fmt.Sprintf("\ntier: %s", step.Tier)
-		}
+		for i, item := range items {
+			ctx := opts.Context
+			if step.Foreach != "" {
+				ctx = make(map[string]string, len(opts.Context)+2)
+				for k, v := range opts.Context {
+					ctx[k] = v
+				}
+				ctx["item"], ctx["index"] = item, strconv.Itoa(i+1)
+			}
 
-		// Create the child issue
-		childOpts := CreateOptions{
-			Title:       step.Title,
-			Type:        "task",
-			Priority:    parent.Priority,
-			Description: description,
-			Parent:      parent.ID,
-		}
+			// Expand template variables in instructions
+			instructions := step.Instructions
+			title := step.Title
+			if ctx != nil {
+				instructions = ExpandTemplateVars(instructions, ctx)
+				title = ExpandTemplateVars(title, ctx)
+			}
 
-		child, err := b.Create(childOpts)
-		if err != nil {
-			// Attempt to clean up created issues on failure (best-effort cleanup)
-			for _, created := range createdIssues {
-				_ = b.Close(created.ID)
+			// Build description with provenance metadata
+			description := instructions
+			if description != "" {
+				description += "\n\n"
+			}
+			description += fmt.Sprintf("instantiated_from: %s\nstep: %s", mol.ID, step.Ref)
+			if step.Foreach != "" {
+				description += fmt.Sprintf("\nitem: %s", item)
+			}
+			if step.Tier != "" {
+				description += fmt.Sprintf("\ntier: %s", step.Tier)
+			}
+			if step.When != "" {
+				when, err := flow.Bind(step.When, contextLookup(ctx))
+				if err != nil {
+					return nil, fmt.Errorf("step %q when: %w", step.Ref, err)
+				}
+				description += fmt.Sprintf("\nwhen: %s", when)
+			}
+			if step.Until != "" {
+				until, err := flow.Bind(step.Until, contextLookup(ctx))
+				if err != nil {
+					return nil, fmt.Errorf("step %q until: %w", step.Ref, err)
+				}
+				description += fmt.Sprintf("\nuntil: %s\nmax_iterations: %d",
+					until, StepControl{MaxIterations: step.MaxIterations}.Iterations())
+			}
+			if len(step.Outputs) > 0 {
+				description += fmt.Sprintf("\noutputs: %s", strings.Join(step.Outputs, ", "))
+			}
+			if step.Timeout != "" {
+				description += fmt.Sprintf("\ntimeout: %s", step.Timeout)
+			}
+			if step.Retries > 0 {
+				description += fmt.Sprintf("\nretries: %d", step.Retries)
+			}
+			if step.OnFailure != "" {
+				description += fmt.Sprintf("\non_failure: %s", step.OnFailure)
+			}
+			if len(step.WaitsFor) > 0 {
+				description += fmt.Sprintf("\nwaits_for: %s", strings.Join(step.WaitsFor, ", "))
 			}
-			return nil, fmt.Errorf("creating step %q: %w", step.Ref, err)
-		}
 
-		createdIssues = append(createdIssues, child)
-		stepIssueIDs[step.Ref] = child.ID
+			draft := &Issue{
+				ID:          fmt.Sprintf("%s.%d", parent.ID, len(drafts)+1),
+				Title:       title,
+				Description: description,
+				Status:      "open",
+				Priority:    parent.Priority,
+				Type:        "task",
+				Parent:      parent.ID,
+			}
+			drafts = append(drafts, draft)
+			stepIssueIDs[step.Ref] = append(stepIssueIDs[step.Ref], draft.ID)
+			stepNeeds[draft.ID] = step.Needs
+		}
 	}
 
 	// Wire inter-step dependencies based on Needs: declarations
-	for _, step := range steps {
-		if len(step.Needs) == 0 {
-			continue
-		}
-
-		childID := stepIssueIDs[step.Ref]
-		for _, need := range step.Needs {
-			dependsOnID := stepIssueIDs[need]
-			if err := b.AddDependency(childID, dependsOnID); err != nil {
-				// Log but don't fail - the issues are created
-				// This is non-atomic but bd CLI doesn't support transactions
-				return createdIssues, fmt.Errorf("adding dependency %s -> %s: %w", childID, dependsOnID, err)
-			}
+	for _, draft := range drafts {
+		for _, need := range stepNeeds[draft.ID] {
+			draft.DependsOn = append(draft.DependsOn, dependencyIssues(need, stepMap, stepIssueIDs)...)
 		}
 	}
 
-	return createdIssues, nil
+	return drafts, nil
+}
+
+// dependencyIssues returns the issues standing in for a needed step: its
+// children, or for a dropped Foreach step, those of the steps it needed.
+func dependencyIssues(ref string, stepMap map[string]*MoleculeStep, stepIssueIDs map[string][]string) []string {
+	if ids := stepIssueIDs[ref]; len(ids) > 0 {
+		return ids
+	}
+	var ids []string
+	for _, need := range stepMap[ref].Needs {
+		ids = append(ids, dependencyIssues(need, stepMap, stepIssueIDs)...)
+	}
+	return ids
 }
 
 // ValidateMolecule checks if an issue is a valid molecule definition.
@@ -505,7 +705,7 @@ func ValidateMolecule(mol *Issue) error {
 		return err
 	}
 
-	return nil
+	return validateStepControl(steps)
 }
 
 // detectCycles checks for circular dependencies in the step graph using DFS.
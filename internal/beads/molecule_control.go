@@ -0,0 +1,430 @@
+// Package beads molecule control flow - when, foreach and until steps.
+package beads
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/flow"
+)
+
+// StepSkippedLabel marks a step bead closed because its when condition
+// was false.
+const StepSkippedLabel = "step:skipped"
+
+// StepControl is the control flow recorded on an instantiated step bead.
+// It is read from the description's metadata lines:
+//
+//	step: <ref>
+//	item: <foreach item>
+//	when: <condition>
+//	until: <condition>
+//	max_iterations: <n>
+//	iteration: <n>  # completed iterations of an until step
+//	outputs: <key>, <key>  # outputs the step must record
+//	tier: haiku|sonnet|opus  # model tier hint
+//	timeout: <duration>  # how long the step may run
+//	retries: <n>  # retries after a failure
+//	on_failure: <policy>  # escalate, skip, fail-molecule or goto:<ref>
+//	attempt: <n>  # failed attempts so far
+//	started: <RFC 3339 time>  # when the current attempt started
+//	waits_for: <condition>, ...  # e.g. all-children (fan-in gate)
+//
+// Keys are lowercase and case-sensitive: instantiation writes them, and
+// instruction prose that happens to start with "When:" is not control.
+type StepControl struct {
+	Ref           string
+	Item          string
+	When          string
+	Until         string
+	MaxIterations int
+	Iteration     int
+	Outputs       []string
+	Tier          string
+	Timeout       string
+	Retries       int
+	OnFailure     string
+	Attempt       int
+	Started       string
+	WaitsFor      []string
+}
+
+// stepControlLineRegex matches a step metadata line.
+var stepControlLineRegex = regexp.MustCompile(`^(step|item|when|until|max_iterations|iteration|outputs|tier|timeout|retries|on_failure|attempt|started|waits_for):\s*(.+?)\s*$`)
+
+// iterationLineRegex matches the iteration line rewritten by SetStepIteration.
+var iterationLineRegex = regexp.MustCompile(`(?m)^iteration:.*$`)
+
+// ParseStepControl reads control flow metadata from a step description.
+func ParseStepControl(description string) StepControl {
+	var c StepControl
+	for _, line := range strings.Split(description, "\n") {
+		m := stepControlLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		switch m[1] {
+		case "step":
+			c.Ref = m[2]
+		case "item":
+			c.Item = m[2]
+		case "when":
+			c.When = m[2]
+		case "until":
+			c.Until = m[2]
+		case "iteration":
+			c.Iteration, _ = strconv.Atoi(m[2])
+		case "outputs":
+			c.Outputs = splitList(m[2])
+		case "tier":
+			if tierLineRegex.MatchString("Tier: " + m[2]) {
+				c.Tier = strings.ToLower(m[2])
+			}
+		case "timeout":
+			c.Timeout = m[2]
+		case "retries":
+			c.Retries, _ = strconv.Atoi(m[2])
+		case "on_failure":
+			c.OnFailure = strings.ToLower(m[2])
+		case "attempt":
+			c.Attempt, _ = strconv.Atoi(m[2])
+		case "started":
+			c.Started = m[2]
+		case "waits_for":
+			c.WaitsFor = splitList(strings.ToLower(m[2]))
+		default: // max_iterations
+			c.MaxIterations, _ = strconv.Atoi(m[2])
+		}
+	}
+	return c
+}
+
+// Iterations returns the most times an until step may run.
+func (c StepControl) Iterations() int {
+	if c.MaxIterations > 0 {
+		return c.MaxIterations
+	}
+	return flow.DefaultMaxIterations
+}
+
+// SetStepIteration records an until step's completed iterations in its
+// description.
+func SetStepIteration(description string, n int) string {
+	line := fmt.Sprintf("iteration: %d", n)
+	if iterationLineRegex.MatchString(description) {
+		return iterationLineRegex.ReplaceAllString(description, line)
+	}
+	if description != "" {
+		description += "\n"
+	}
+	return description + line
+}
+
+// stepRef returns the ref conditions use for a step bead: its step: line,
+// or the ID suffix after the molecule ID.
+func stepRef(issue *Issue) string {
+	if ref := ParseStepControl(issue.Description).Ref; ref != "" {
+		return ref
+	}
+	if i := strings.LastIndex(issue.ID, "."); i >= 0 {
+		return issue.ID[i+1:]
+	}
+	return issue.ID
+}
+
+// foreachRef returns the foreach step a ref is an item of when it has the
+// form <ref>.<n>, as gt formula run pours workflow plans with foreach
+// steps already expanded, or "".
+func foreachRef(ref string) string {
+	i := strings.LastIndex(ref, ".")
+	if i <= 0 {
+		return ""
+	}
+	if _, err := strconv.Atoi(ref[i+1:]); err != nil {
+		return ""
+	}
+	return ref[:i]
+}
+
+// matchesRef reports whether a step with ref stepRef answers to ref: the
+// same ref, or an item of the foreach step ref.
+func matchesRef(stepRef, ref string) bool {
+	return stepRef == ref || foreachRef(stepRef) == ref
+}
+
+func hasLabel(issue *Issue, label string) bool {
+	return contains(issue.Labels, label)
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// StepLookup resolves steps.<ref>.<key> condition references against a
+// molecule's step beads: status, iterations, or a recorded output. A ref
+// expanded by foreach is done once all its items are closed (failed if any
+// of them failed), and its outputs join the items' values with ", "; items
+// are the steps sharing its step: line, or poured as <ref>.<n>. Skipped
+// holds step IDs that are being skipped but aren't closed yet.
+func StepLookup(steps []*Issue, skipped map[string]bool) flow.Lookup {
+	return func(r string) (string, bool) {
+		ref, key, _ := flow.SplitRef(r)
+		if ref == "" {
+			return "", false
+		}
+		var matched, closed, skips, fails, iterations int
+		var values []string
+		for _, s := range steps {
+			if !matchesRef(stepRef(s), ref) && s.ID != ref {
+				continue
+			}
+			matched++
+			switch {
+			case skipped[s.ID] || (s.Status == "closed" && hasLabel(s, StepSkippedLabel)):
+				closed++
+				skips++
+			case s.Status == "closed":
+				closed++
+			}
+			if hasLabel(s, StepFailedLabel) {
+				fails++
+			}
+			iterations = max(iterations, ParseStepControl(s.Description).Iteration)
+			if v, ok := StepOutputs(s.Description)[key]; ok {
+				values = append(values, v)
+			}
+		}
+		if matched == 0 {
+			return "", false
+		}
+		switch key {
+		case "status":
+			switch {
+			case fails > 0:
+				return "failed", true
+			case closed < matched:
+				return "pending", true
+			case skips == matched:
+				return "skipped", true
+			}
+			return "done", true
+		case "iterations":
+			return strconv.Itoa(iterations), true
+		}
+		if len(values) == 0 {
+			return "", false
+		}
+		return strings.Join(values, ", "), true
+	}
+}
+
+// NextStep is the outcome of evaluating a molecule's control flow.
+type NextStep struct {
+	Next     *Issue   // step to run now, or nil
+	Skip     []*Issue // open steps whose when condition is false, in order
+	Complete bool     // no steps remain once Skip is closed
+}
+
+// FindNextStep decides which open step of a molecule runs next. Steps
+// whose dependencies are closed have their when condition evaluated; false
+// ones are skipped, which counts as closed for their dependents, so skips
+// cascade until a runnable step is found. Only "open" steps are
+// candidates; in_progress steps are being worked, and open steps marked
+// failed are escalated, and both block completion.
+func FindNextStep(steps []*Issue) (*NextStep, error) {
+	result := &NextStep{}
+	skipped := make(map[string]bool)
+	closed := make(map[string]bool)
+	for _, s := range steps {
+		if s.Status == "closed" {
+			closed[s.ID] = true
+		}
+	}
+	lookup := StepLookup(steps, skipped)
+
+	for {
+		progressed := false
+		for _, s := range steps {
+			if s.Status != "open" || closed[s.ID] || hasLabel(s, StepFailedLabel) || !allClosed(s.DependsOn, closed) {
+				continue
+			}
+			ctl := ParseStepControl(s.Description)
+			if ctl.When != "" {
+				c, err := flow.ParseCond(ctl.When)
+				if err != nil {
+					return nil, fmt.Errorf("step %s: %w", s.ID, err)
+				}
+				if !c.Eval(lookup) {
+					result.Skip = append(result.Skip, s)
+					skipped[s.ID] = true
+					closed[s.ID] = true
+					progressed = true
+					continue
+				}
+			}
+			result.Next = s
+			return result, nil
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	result.Complete = true
+	for _, s := range steps {
+		if !closed[s.ID] {
+			result.Complete = false
+			break
+		}
+	}
+	return result, nil
+}
+
+// RepeatStep decides whether a finished until step must run again: its
+// condition is still false and it has iterations left. It returns the
+// number of iterations completed, including this one.
+func RepeatStep(step *Issue, steps []*Issue) (bool, int, error) {
+	ctl := ParseStepControl(step.Description)
+	n := ctl.Iteration + 1
+	if ctl.Until == "" {
+		return false, n, nil
+	}
+	c, err := flow.ParseCond(ctl.Until)
+	if err != nil {
+		return false, n, fmt.Errorf("step %s: %w", step.ID, err)
+	}
+	// The finished step may carry outputs newer than the listed copy
+	current := make([]*Issue, len(steps))
+	for i, s := range steps {
+		current[i] = s
+		if s.ID == step.ID {
+			current[i] = step
+		}
+	}
+	lookup := StepLookup(current, nil)
+	self := stepRef(step)
+	done := c.Eval(func(r string) (string, bool) {
+		if ref, key, _ := flow.SplitRef(r); matchesRef(self, ref) && key == "iterations" {
+			return strconv.Itoa(n), true
+		}
+		return lookup(r)
+	})
+	return !done && n < ctl.Iterations(), n, nil
+}
+
+func allClosed(ids []string, closed map[string]bool) bool {
+	for _, id := range ids {
+		if !closed[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateStepControl checks the control flow, outputs and failure
+// handling of parsed molecule steps: conditions must parse, and conditions and
+// {{steps.<ref>.<key>}} placeholders may only reference steps that finish
+// first (an until condition may also reference its own step) and outputs
+// those steps declare.
+func validateStepControl(steps []MoleculeStep) error {
+	needs := make(map[string][]string)
+	outputs := make(map[string][]string)
+	refs := make(map[string]bool)
+	for _, s := range steps {
+		refs[s.Ref] = true
+		if group := foreachRef(s.Ref); group != "" {
+			refs[group] = true
+			outputs[group] = s.Outputs
+		}
+	}
+	for _, s := range steps {
+		if err := validateStepFailure(s, refs); err != nil {
+			return err
+		}
+		needs[s.Ref] = s.Needs
+		outputs[s.Ref] = s.Outputs
+		for _, o := range s.Outputs {
+			if !ValidOutputName(o) {
+				return fmt.Errorf("step %q has invalid output name %q", s.Ref, o)
+			}
+		}
+	}
+	// checkRef checks that a steps.<ref>.<key> reference names a step that
+	// finishes first, and an output that step declares.
+	checkRef := func(s MoleculeStep, kind, r string, before map[string]bool) error {
+		ref, key, _ := flow.SplitRef(r)
+		if ref == "" {
+			return nil
+		}
+		if !before[ref] {
+			return fmt.Errorf("step %q %s references step %q, which it does not need", s.Ref, kind, ref)
+		}
+		if !reservedOutputKeys[key] && !contains(outputs[ref], key) {
+			return fmt.Errorf("step %q %s references output %q, which step %q does not declare", s.Ref, kind, key, ref)
+		}
+		return nil
+	}
+	for _, s := range steps {
+		before := make(map[string]bool)
+		var visit func(string)
+		visit = func(ref string) {
+			for _, n := range needs[ref] {
+				if !before[n] {
+					before[n] = true
+					if group := foreachRef(n); group != "" {
+						before[group] = true
+					}
+					visit(n)
+				}
+			}
+		}
+		visit(s.Ref)
+
+		check := func(kind, cond string) error {
+			c, err := flow.ParseCond(cond)
+			if err != nil {
+				return fmt.Errorf("step %q %s: %w", s.Ref, kind, err)
+			}
+			for _, r := range c.Refs() {
+				if err := checkRef(s, kind, r, before); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for _, r := range StepOutputRefs(s.Instructions) {
+			if err := checkRef(s, "instructions", r, before); err != nil {
+				return err
+			}
+		}
+		if s.When != "" {
+			if err := check("when", s.When); err != nil {
+				return err
+			}
+		}
+		if s.Until != "" {
+			before[s.Ref] = true
+			if group := foreachRef(s.Ref); group != "" {
+				before[group] = true
+			}
+			if err := check("until", s.Until); err != nil {
+				return err
+			}
+		} else if s.MaxIterations != 0 {
+			return fmt.Errorf("step %q sets MaxIterations without Until", s.Ref)
+		}
+		if s.MaxIterations < 0 || s.MaxIterations > flow.MaxIterationsLimit {
+			return fmt.Errorf("step %q MaxIterations must be between 1 and %d", s.Ref, flow.MaxIterationsLimit)
+		}
+	}
+	return nil
+}
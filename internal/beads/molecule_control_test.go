@@ -0,0 +1,222 @@
+package beads
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/flow"
+)
+
+func TestParseMoleculeSteps_WithControlFlow(t *testing.T) {
+	desc := `## Step: scan
+Scan {{item}} for stuck polecats.
+Foreach: {{rigs}}
+
+## Step: nudge
+Nudge stuck polecats.
+Needs: scan
+When: steps.scan.status == "done" && {{mode}} != "quiet"
+
+## Step: wait
+Wait for activity.
+Needs: nudge
+Until: steps.wait.iterations >= 3
+MaxIterations: 5`
+
+	steps, err := ParseMoleculeSteps(desc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d", len(steps))
+	}
+	if steps[0].Foreach != "{{rigs}}" {
+		t.Errorf("scan Foreach = %q", steps[0].Foreach)
+	}
+	if steps[1].When != `steps.scan.status == "done" && {{mode}} != "quiet"` {
+		t.Errorf("nudge When = %q", steps[1].When)
+	}
+	if steps[2].Until != "steps.wait.iterations >= 3" || steps[2].MaxIterations != 5 {
+		t.Errorf("wait Until = %q, MaxIterations = %d", steps[2].Until, steps[2].MaxIterations)
+	}
+	if strings.Contains(steps[2].Instructions, "Until") {
+		t.Errorf("control lines should not be part of instructions: %q", steps[2].Instructions)
+	}
+
+	mol := &Issue{ID: "mol-patrol", Type: "molecule", Description: desc}
+	if err := ValidateMolecule(mol); err != nil {
+		t.Errorf("ValidateMolecule: %v", err)
+	}
+}
+
+func TestParseMoleculeSteps_ControlLinesCaseSensitive(t *testing.T) {
+	steps, err := ParseMoleculeSteps(`## Step: deploy
+Deploy the release.
+when: the build is green, tag it
+Timeout: 30m
+When: {{deep}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if steps[0].When != "{{deep}}" || steps[0].Timeout != "30m" {
+		t.Errorf("When = %q, Timeout = %q", steps[0].When, steps[0].Timeout)
+	}
+	if !strings.Contains(steps[0].Instructions, "when: the build is green") {
+		t.Errorf("prose line lost from instructions: %q", steps[0].Instructions)
+	}
+
+	// A variable left unset at instantiation makes the step's condition false
+	when, err := flow.Bind(steps[0].When, contextLookup(map[string]string{"mode": "full"}))
+	if err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	next, err := FindNextStep([]*Issue{controlStep("gt-mol.1", "open", "step: deploy\nwhen: "+when)})
+	if err != nil {
+		t.Fatalf("FindNextStep: %v", err)
+	}
+	if next.Next != nil || len(next.Skip) != 1 {
+		t.Errorf("Next = %v, Skip = %v; want deploy skipped", next.Next, next.Skip)
+	}
+}
+
+func TestValidateMolecule_ControlFlowErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		desc string
+		want string
+	}{
+		{"bad condition", "## Step: a\nDo it.\nWhen: x = 1", "unknown operator"},
+		{"unneeded step", "## Step: a\nA.\n\n## Step: b\nB.\nWhen: steps.a.status == \"done\"", "does not need"},
+		{"max without until", "## Step: a\nA.\nMaxIterations: 3", "without Until"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMolecule(&Issue{ID: "mol-x", Type: "molecule", Description: tt.desc})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ValidateMolecule error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func controlStep(id, status, desc string, dependsOn ...string) *Issue {
+	return &Issue{ID: id, Status: status, Description: desc, DependsOn: dependsOn}
+}
+
+func TestFindNextStep(t *testing.T) {
+	steps := []*Issue{
+		controlStep("gt-mol.1", "closed", "step: scan\niteration: 0"),
+		controlStep("gt-mol.2", "open", "step: nudge\nwhen: steps.scan.status == \"skipped\"", "gt-mol.1"),
+		controlStep("gt-mol.3", "open", "step: report\nwhen: steps.nudge.status == \"skipped\"", "gt-mol.2"),
+		controlStep("gt-mol.4", "open", "step: cleanup", "gt-mol.3"),
+	}
+
+	next, err := FindNextStep(steps)
+	if err != nil {
+		t.Fatalf("FindNextStep: %v", err)
+	}
+	// nudge is skipped (scan was done), which makes report's condition true
+	if len(next.Skip) != 1 || next.Skip[0].ID != "gt-mol.2" {
+		t.Errorf("Skip = %v, want [gt-mol.2]", next.Skip)
+	}
+	if next.Next == nil || next.Next.ID != "gt-mol.3" {
+		t.Fatalf("Next = %v, want gt-mol.3", next.Next)
+	}
+
+	// Skips cascade through to completion
+	steps[2].Description = "step: report\nwhen: false"
+	steps[3].Description = "step: cleanup\nwhen: steps.report.status == \"done\""
+	next, err = FindNextStep(steps)
+	if err != nil {
+		t.Fatalf("FindNextStep: %v", err)
+	}
+	if next.Next != nil || !next.Complete || len(next.Skip) != 3 {
+		t.Errorf("Next = %v, Complete = %v, Skip = %d; want all skipped and complete", next.Next, next.Complete, len(next.Skip))
+	}
+}
+
+func TestRepeatStep(t *testing.T) {
+	wait := controlStep("gt-mol.2", "in_progress", "Wait.\n\nstep: wait\nuntil: steps.check.status == \"done\"\nmax_iterations: 3")
+	check := controlStep("gt-mol.1", "open", "step: check")
+	steps := []*Issue{check, wait}
+
+	repeat, n, err := RepeatStep(wait, steps)
+	if err != nil || !repeat || n != 1 {
+		t.Fatalf("RepeatStep = %v, %d, %v; want repeat after iteration 1", repeat, n, err)
+	}
+
+	wait.Description = SetStepIteration(wait.Description, 2)
+	if got := ParseStepControl(wait.Description).Iteration; got != 2 {
+		t.Errorf("Iteration = %d after SetStepIteration", got)
+	}
+	if repeat, n, _ := RepeatStep(wait, steps); repeat || n != 3 {
+		t.Errorf("RepeatStep = %v, %d; want stop at max_iterations", repeat, n)
+	}
+
+	wait.Description = SetStepIteration(wait.Description, 0)
+	check.Status = "closed"
+	if repeat, _, _ := RepeatStep(wait, steps); repeat {
+		t.Error("RepeatStep should stop once the until condition holds")
+	}
+}
+
+func TestParseStepControl_Tier(t *testing.T) {
+	desc := "Review the design.\n\nstep: review\ntier: Opus"
+	if got := ParseStepControl(desc).Tier; got != "opus" {
+		t.Errorf("Tier = %q, want opus", got)
+	}
+	if got := ParseStepControl("Tier: gpt-5").Tier; got != "" {
+		t.Errorf("unknown tier parsed as %q", got)
+	}
+}
+
+func TestDraftMoleculeSteps_PouredForeachItems(t *testing.T) {
+	mol := &Issue{ID: "mol-patrol", Description: `## Step: scan.1
+Scan gastown
+Outputs: stuck
+
+## Step: scan.2
+Scan beads
+Outputs: stuck
+
+## Step: nudge
+Nudge stuck polecats
+Needs: scan.1, scan.2
+When: steps.scan.stuck > 0
+`}
+	steps, err := DraftMoleculeSteps(mol, &Issue{ID: "gt-mol", Priority: 2}, InstantiateOptions{})
+	if err != nil {
+		t.Fatalf("DraftMoleculeSteps: %v", err)
+	}
+	if len(steps) != 3 || steps[2].ID != "gt-mol.3" || strings.Join(steps[2].DependsOn, ",") != "gt-mol.1,gt-mol.2" {
+		t.Fatalf("drafts = %+v", steps)
+	}
+	if steps[0].Status != "open" || steps[0].Priority != 2 || steps[0].Parent != "gt-mol" {
+		t.Errorf("draft = %+v, want open child of gt-mol", steps[0])
+	}
+
+	// steps.scan covers both poured items
+	for i, stuck := range []string{"0", "1"} {
+		steps[i].Status = "closed"
+		steps[i].Description = SetStepOutputs(steps[i].Description, map[string]string{"stuck": stuck})
+	}
+	if v, _ := StepLookup(steps, nil)("steps.scan.stuck"); v != "0, 1" {
+		t.Errorf("steps.scan.stuck = %q, want \"0, 1\"", v)
+	}
+	if v, _ := StepLookup(steps, nil)("steps.scan.status"); v != "done" {
+		t.Errorf("steps.scan.status = %q, want done", v)
+	}
+}
+
+func TestSetStepLinesKeepProse(t *testing.T) {
+	desc := "Iteration: tune until green.\nAttempt: the fix.\nStarted: from main.\n\nstep: tune"
+	got := SetStepStarted(SetStepAttempt(SetStepIteration(desc, 2), 1), time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC))
+	if !strings.HasPrefix(got, desc+"\n") {
+		t.Errorf("prose rewritten:\n%s", got)
+	}
+	ctl := ParseStepControl(got)
+	if ctl.Iteration != 2 || ctl.Attempt != 1 || ctl.Started != "2026-01-05T12:00:00Z" {
+		t.Errorf("control = %+v", ctl)
+	}
+}
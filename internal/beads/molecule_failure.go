@@ -0,0 +1,170 @@
+// Package beads molecule step failures - timeouts, retries and on_failure.
+package beads
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/flow"
+)
+
+// StepFailedLabel marks a step bead that failed once its retries were used
+// up. An escalated step stays open with it; skipped and fail-molecule
+// steps are closed.
+const StepFailedLabel = "step:failed"
+
+// MoleculeFailedLabel marks a molecule root stopped by a fail-molecule step.
+const MoleculeFailedLabel = "mol:failed"
+
+// FailureRetry is the DecideStepFailure action for a step with retries
+// left: it runs again.
+const FailureRetry = "retry"
+
+// StepFailure is what to do about a failed step attempt.
+type StepFailure struct {
+	Action  string   // FailureRetry, or an on_failure action (flow.OnFailure*)
+	Attempt int      // failed attempts so far, including this one
+	Target  string   // step ref a goto runs next
+	Reopen  []*Issue // steps a goto runs again: the target and what depends on it, in order
+	Close   bool     // the failed step is closed (skip, fail-molecule, or a goto that doesn't rerun it)
+}
+
+// DecideStepFailure decides what happens to a step that just failed. The
+// step is retried while it has retries left; after that its on_failure
+// policy applies. A step that keeps failing through goto loops is
+// escalated after flow.MaxFailures failures.
+func DecideStepFailure(step *Issue, steps []*Issue) (*StepFailure, error) {
+	ctl := ParseStepControl(step.Description)
+	f := &StepFailure{Attempt: ctl.Attempt + 1}
+	if f.Attempt <= ctl.Retries {
+		f.Action = FailureRetry
+		return f, nil
+	}
+	action, target, err := flow.ParseOnFailure(ctl.OnFailure)
+	if err != nil {
+		return nil, fmt.Errorf("step %s: %w", step.ID, err)
+	}
+	if action == flow.OnFailureGoto && f.Attempt >= flow.MaxFailures {
+		action = flow.OnFailureEscalate
+	}
+	f.Action = action
+
+	switch action {
+	case flow.OnFailureSkip, flow.OnFailureFailMolecule:
+		f.Close = true
+	case flow.OnFailureGoto:
+		f.Target = target
+		reopen := make(map[string]bool)
+		for _, s := range steps {
+			if matchesRef(stepRef(s), target) || s.ID == target {
+				reopen[s.ID] = true
+			}
+		}
+		if len(reopen) == 0 {
+			return nil, fmt.Errorf("step %s: on_failure goto:%s names no step in the molecule", step.ID, target)
+		}
+		// Everything downstream of the target runs again
+		for changed := true; changed; {
+			changed = false
+			for _, s := range steps {
+				if reopen[s.ID] {
+					continue
+				}
+				for _, dep := range s.DependsOn {
+					if reopen[dep] {
+						reopen[s.ID] = true
+						changed = true
+						break
+					}
+				}
+			}
+		}
+		for _, s := range steps {
+			if reopen[s.ID] && s.ID != step.ID {
+				f.Reopen = append(f.Reopen, s)
+			}
+		}
+		f.Close = !reopen[step.ID]
+	}
+	return f, nil
+}
+
+// attemptLineRegex and startedLineRegex match the lines rewritten by
+// SetStepAttempt and SetStepStarted.
+var (
+	attemptLineRegex = regexp.MustCompile(`(?m)^attempt:.*$`)
+	startedLineRegex = regexp.MustCompile(`(?m)^started:.*$`)
+)
+
+// SetStepAttempt records a step's failed attempts in its description.
+func SetStepAttempt(description string, n int) string {
+	return setStepLine(description, attemptLineRegex, "attempt: "+strconv.Itoa(n))
+}
+
+// SetStepStarted records when a step's current attempt started, the start
+// of its timeout.
+func SetStepStarted(description string, t time.Time) string {
+	return setStepLine(description, startedLineRegex, "started: "+t.UTC().Format(time.RFC3339))
+}
+
+func setStepLine(description string, re *regexp.Regexp, line string) string {
+	if re.MatchString(description) {
+		return re.ReplaceAllString(description, line)
+	}
+	if description != "" {
+		description += "\n"
+	}
+	return description + line
+}
+
+// StepDeadline returns when a step with a timeout runs out of time,
+// counting from its started: line, or for a step being worked without one
+// (pinned, hooked or in_progress), from its last update. It reports false
+// for steps without a timeout or not yet started.
+func StepDeadline(step *Issue) (time.Time, bool) {
+	ctl := ParseStepControl(step.Description)
+	if ctl.Timeout == "" || step.Status == "closed" || hasLabel(step, StepFailedLabel) {
+		return time.Time{}, false
+	}
+	d, err := flow.ParseTimeout(ctl.Timeout)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start := ctl.Started
+	if start == "" {
+		switch step.Status {
+		case StatusPinned, StatusHooked, "in_progress":
+			start = step.UpdatedAt
+		default:
+			return time.Time{}, false
+		}
+	}
+	t, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.Add(d), true
+}
+
+// validateStepFailure checks a markdown step's timeout, retries and
+// OnFailure policy; refs are the molecule's step refs.
+func validateStepFailure(s MoleculeStep, refs map[string]bool) error {
+	if s.Timeout != "" {
+		if _, err := flow.ParseTimeout(s.Timeout); err != nil {
+			return fmt.Errorf("step %q: %w", s.Ref, err)
+		}
+	}
+	if s.Retries < 0 || s.Retries > flow.MaxRetries {
+		return fmt.Errorf("step %q Retries must be between 0 and %d", s.Ref, flow.MaxRetries)
+	}
+	action, target, err := flow.ParseOnFailure(s.OnFailure)
+	if err != nil {
+		return fmt.Errorf("step %q: %w", s.Ref, err)
+	}
+	if action == flow.OnFailureGoto && (target == s.Ref || !refs[target]) {
+		return fmt.Errorf("step %q OnFailure goto:%s must name another step", s.Ref, target)
+	}
+	return nil
+}
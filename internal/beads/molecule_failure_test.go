@@ -0,0 +1,107 @@
+package beads
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMoleculeSteps_FailureHandling(t *testing.T) {
+	desc := `## Step: implement
+Implement it.
+
+## Step: test
+Run the tests.
+Needs: implement
+Timeout: 30m
+Retries: 2
+OnFailure: goto:implement`
+
+	steps, err := ParseMoleculeSteps(desc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := steps[1]
+	if s.Timeout != "30m" || s.Retries != 2 || s.OnFailure != "goto:implement" {
+		t.Errorf("test step = timeout %q, retries %d, on_failure %q", s.Timeout, s.Retries, s.OnFailure)
+	}
+	if strings.Contains(s.Instructions, "Retries") {
+		t.Errorf("failure lines should not be part of instructions: %q", s.Instructions)
+	}
+	if err := ValidateMolecule(&Issue{ID: "mol-x", Type: "molecule", Description: desc}); err != nil {
+		t.Errorf("ValidateMolecule: %v", err)
+	}
+
+	for _, bad := range []string{"Timeout: soon", "Retries: 99", "OnFailure: retry", "OnFailure: goto:nowhere"} {
+		d := "## Step: a\nA.\n" + bad
+		if err := ValidateMolecule(&Issue{ID: "mol-x", Type: "molecule", Description: d}); err == nil {
+			t.Errorf("ValidateMolecule accepted %q", bad)
+		}
+	}
+}
+
+func TestDecideStepFailure(t *testing.T) {
+	impl := controlStep("gt-mol.1", "closed", "step: implement")
+	test := controlStep("gt-mol.2", "in_progress", "step: test\nretries: 1\non_failure: goto:implement", "gt-mol.1")
+	report := controlStep("gt-mol.3", "open", "step: report", "gt-mol.2")
+	steps := []*Issue{impl, test, report}
+
+	f, err := DecideStepFailure(test, steps)
+	if err != nil || f.Action != FailureRetry || f.Attempt != 1 {
+		t.Fatalf("first failure = %+v, %v; want retry", f, err)
+	}
+
+	test.Description = SetStepAttempt(test.Description, 1)
+	f, err = DecideStepFailure(test, steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Action != "goto" || f.Close || len(f.Reopen) != 2 || f.Reopen[0] != impl || f.Reopen[1] != report {
+		t.Errorf("goto = %+v; want implement and report reopened, test rerun", f)
+	}
+
+	test.Description = "step: test\non_failure: skip"
+	if f, _ := DecideStepFailure(test, steps); f.Action != "skip" || !f.Close {
+		t.Errorf("skip = %+v", f)
+	}
+	test.Description = "step: test"
+	if f, _ := DecideStepFailure(test, steps); f.Action != "escalate" || f.Close {
+		t.Errorf("default = %+v, want escalate, left open", f)
+	}
+}
+
+func TestStepFailedStatus(t *testing.T) {
+	a := controlStep("gt-mol.1", "open", "step: a")
+	a.Labels = []string{StepFailedLabel}
+	b := controlStep("gt-mol.2", "open", "step: b\nwhen: steps.a.status == \"failed\"")
+	steps := []*Issue{a, b}
+
+	if got, _ := StepLookup(steps, nil)("steps.a.status"); got != "failed" {
+		t.Errorf("status = %q, want failed", got)
+	}
+	// An escalated step waits for a human; it is neither run nor complete
+	next, err := FindNextStep(steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Next != b || next.Complete {
+		t.Errorf("Next = %v, Complete = %v; want b", next.Next, next.Complete)
+	}
+}
+
+func TestStepDeadline(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := controlStep("gt-mol.1", "open", "step: a\ntimeout: 30m")
+	if _, ok := StepDeadline(s); ok {
+		t.Error("an open step that never started has no deadline")
+	}
+	s.Status = StatusPinned
+	s.UpdatedAt = start.Format(time.RFC3339)
+	if d, ok := StepDeadline(s); !ok || !d.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("deadline = %v, %v", d, ok)
+	}
+	s.Description = SetStepStarted(s.Description, start.Add(time.Hour))
+	if d, _ := StepDeadline(s); !d.Equal(start.Add(90 * time.Minute)) {
+		t.Errorf("deadline from started = %v", d)
+	}
+}
@@ -0,0 +1,143 @@
+// Package beads molecule and convoy dependency graphs.
+package beads
+
+import (
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/dag"
+)
+
+// WaitsForAllChildren is the waits_for condition of a fan-in step: it waits
+// for every child bonded to the molecule, not just the steps it needs.
+const WaitsForAllChildren = "all-children"
+
+// MoleculeGraph builds the dependency graph of a molecule from its root's
+// children: steps linked by their dependencies, plus any children bonded
+// at run time (which aren't instantiated steps), feeding the steps that
+// wait for all-children.
+func MoleculeGraph(root *Issue, children []*Issue) *dag.Graph {
+	g := dag.New(root.ID + ": " + root.Title)
+
+	closed := make(map[string]bool)
+	var bonded []string
+	for _, c := range children {
+		if c.Status == "closed" {
+			closed[c.ID] = true
+		}
+		if !strings.Contains(c.Description, "instantiated_from:") {
+			bonded = append(bonded, c.ID)
+		}
+	}
+
+	needs := make(map[string][]string)
+	for _, c := range children {
+		needs[c.ID] = c.DependsOn
+		if contains(ParseStepControl(c.Description).WaitsFor, WaitsForAllChildren) {
+			for _, id := range bonded {
+				if id != c.ID {
+					needs[c.ID] = append(needs[c.ID], id)
+				}
+			}
+		}
+	}
+
+	for _, c := range children {
+		ready := true
+		for _, dep := range needs[c.ID] {
+			if !closed[dep] {
+				ready = false
+				break
+			}
+		}
+		g.AddNode(dag.Node{ID: c.ID, Label: c.Title, Status: graphStatus(c, ready)})
+	}
+	for _, c := range children {
+		for _, dep := range needs[c.ID] {
+			g.AddEdge(dep, c.ID)
+		}
+	}
+	return g
+}
+
+// ConvoyGraph builds the dependency graph of the issues a convoy tracks,
+// linked by their blocking dependencies and grouped by rig. issues holds
+// the details of the tracked IDs; an ID without details is drawn as an
+// external issue. rigOf names the rig of an issue, or "" for none.
+func ConvoyGraph(convoy *Issue, tracked []string, issues map[string]*Issue, rigOf func(id string) string) *dag.Graph {
+	g := dag.New(convoy.ID + ": " + convoy.Title)
+
+	isTracked := make(map[string]bool, len(tracked))
+	for _, id := range tracked {
+		isTracked[id] = true
+	}
+	needs := make(map[string][]string)
+	for _, id := range tracked {
+		issue := issues[id]
+		if issue == nil {
+			continue
+		}
+		for _, dep := range issue.DependsOn {
+			needs[id] = append(needs[id], externalRefID(dep))
+		}
+		for _, dep := range issue.Dependencies {
+			if dep.DependencyType == "" || dep.DependencyType == "blocks" {
+				needs[id] = append(needs[id], externalRefID(dep.ID))
+			}
+		}
+	}
+
+	for _, id := range tracked {
+		n := dag.Node{ID: id, Label: "(external)"}
+		if rigOf != nil {
+			n.Group = rigOf(id)
+		}
+		if issue := issues[id]; issue != nil {
+			ready := true
+			for _, dep := range needs[id] {
+				if isTracked[dep] && (issues[dep] == nil || issues[dep].Status != "closed") {
+					ready = false
+					break
+				}
+			}
+			n.Label = issue.Title
+			n.Status = graphStatus(issue, ready)
+		}
+		g.AddNode(n)
+	}
+	for _, id := range tracked {
+		for _, dep := range needs[id] {
+			g.AddEdge(dep, id)
+		}
+	}
+	return g
+}
+
+// graphStatus maps a bead to its graph status; ready says whether
+// everything it waits for is closed.
+func graphStatus(issue *Issue, ready bool) string {
+	switch {
+	case hasLabel(issue, StepFailedLabel):
+		return dag.StatusFailed
+	case issue.Status == "closed" && hasLabel(issue, StepSkippedLabel):
+		return dag.StatusSkipped
+	case issue.Status == "closed":
+		return dag.StatusDone
+	case issue.Status == "in_progress" || issue.Status == StatusHooked || issue.Status == StatusPinned:
+		return dag.StatusActive
+	case ready:
+		return dag.StatusReady
+	default:
+		return dag.StatusBlocked
+	}
+}
+
+// externalRefID returns the issue ID of a cross-rig reference
+// (external:<rig>:<id>), or ref itself.
+func externalRefID(ref string) string {
+	if strings.HasPrefix(ref, "external:") {
+		if parts := strings.SplitN(ref, ":", 3); len(parts) == 3 {
+			return parts[2]
+		}
+	}
+	return ref
+}
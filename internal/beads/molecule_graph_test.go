@@ -0,0 +1,80 @@
+package beads
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/dag"
+)
+
+func TestMoleculeGraph(t *testing.T) {
+	root := &Issue{ID: "gt-mol", Title: "Patrol"}
+	children := []*Issue{
+		{ID: "gt-mol.1", Title: "Survey", Status: "closed", Description: "instantiated_from: mol-patrol\nstep: survey"},
+		{ID: "gt-mol.2", Title: "Aggregate", Status: "open", DependsOn: []string{"gt-mol.1"},
+			Description: "instantiated_from: mol-patrol\nstep: aggregate\nwaits_for: all-children"},
+		{ID: "gt-mol.3", Title: "Arm: nux", Status: "closed"},
+		{ID: "gt-mol.4", Title: "Arm: toast", Status: "in_progress"},
+		{ID: "gt-mol.5", Title: "Report", Status: "open", DependsOn: []string{"gt-mol.2"},
+			Description: "instantiated_from: mol-patrol\nstep: report", Labels: []string{StepFailedLabel}},
+	}
+
+	g := MoleculeGraph(root, children)
+	if g.Name != "gt-mol: Patrol" {
+		t.Errorf("Name = %q", g.Name)
+	}
+
+	// The fan-in step waits on its need and every bonded arm
+	if got, want := g.Needs("gt-mol.2"), []string{"gt-mol.1", "gt-mol.3", "gt-mol.4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Needs(aggregate) = %v, want %v", got, want)
+	}
+
+	statuses := map[string]string{
+		"gt-mol.1": dag.StatusDone,
+		"gt-mol.2": dag.StatusBlocked, // toast's arm is still running
+		"gt-mol.4": dag.StatusActive,
+		"gt-mol.5": dag.StatusFailed,
+	}
+	for id, want := range statuses {
+		if got := g.Node(id).Status; got != want {
+			t.Errorf("%s status = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestConvoyGraph(t *testing.T) {
+	convoy := &Issue{ID: "hq-cv-1", Title: "Release"}
+	tracked := []string{"gt-a", "bd-b", "gt-c"}
+	issues := map[string]*Issue{
+		"gt-a": {ID: "gt-a", Title: "API", Status: "open",
+			Dependencies: []IssueDep{{ID: "external:beads:bd-b", DependencyType: "blocks"}, {ID: "gt-x", DependencyType: "parent-child"}}},
+		"bd-b": {ID: "bd-b", Title: "Storage", Status: "closed"},
+	}
+	rigOf := func(id string) string {
+		if id[:3] == "bd-" {
+			return "beads"
+		}
+		return "gastown"
+	}
+
+	g := ConvoyGraph(convoy, tracked, issues, rigOf)
+	if got := g.Needs("gt-a"); !reflect.DeepEqual(got, []string{"bd-b"}) {
+		t.Errorf("Needs(gt-a) = %v, want [bd-b]", got)
+	}
+	if n := g.Node("gt-a"); n.Status != dag.StatusReady || n.Group != "gastown" {
+		t.Errorf("gt-a = %+v, want ready in gastown", n)
+	}
+	if n := g.Node("gt-c"); n.Label != "(external)" || n.Status != "" {
+		t.Errorf("gt-c = %+v, want external without status", n)
+	}
+}
+
+func TestParseStepControlWaitsFor(t *testing.T) {
+	c := ParseStepControl("step: aggregate\nwaits_for: All-Children\nWhen: the fan-out is done")
+	if !reflect.DeepEqual(c.WaitsFor, []string{WaitsForAllChildren}) {
+		t.Errorf("WaitsFor = %v", c.WaitsFor)
+	}
+	if c.When != "" {
+		t.Errorf("When = %q, want prose ignored", c.When)
+	}
+}
@@ -0,0 +1,155 @@
+// Package beads molecule step outputs - data passed between steps.
+package beads
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Step outputs are recorded on a step bead's description, one line per key
+// with the value Go-quoted so multi-line values stay on one line:
+//
+//	output.approach: "token bucket in redis"
+//
+// Later steps reference them as {{steps.<ref>.<key>}}, and conditions as
+// steps.<ref>.<key>.
+
+// outputLineRegex matches a recorded step output line.
+var outputLineRegex = regexp.MustCompile(`(?m)^output\.([A-Za-z_][A-Za-z0-9_-]*):\s*(".*")\s*$`)
+
+// outputNameRegex matches a valid output key.
+var outputNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// stepOutputRefRegex matches {{steps.<ref>.<key>}} placeholders.
+var stepOutputRefRegex = regexp.MustCompile(`\{\{\s*(steps\.[A-Za-z0-9_.-]+\.[A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// reservedOutputKeys are step state keys conditions can always reference.
+var reservedOutputKeys = map[string]bool{"status": true, "iterations": true}
+
+// ValidOutputName reports whether name can be used as an output key.
+func ValidOutputName(name string) bool {
+	return outputNameRegex.MatchString(name) && !reservedOutputKeys[name]
+}
+
+// StepOutputs returns the outputs recorded in a step description.
+func StepOutputs(description string) map[string]string {
+	outputs := make(map[string]string)
+	for _, m := range outputLineRegex.FindAllStringSubmatch(description, -1) {
+		if v, err := strconv.Unquote(m[2]); err == nil {
+			outputs[m[1]] = v
+		}
+	}
+	return outputs
+}
+
+// SetStepOutputs records outputs in a step description, replacing earlier
+// values of the same keys. New keys are appended in sorted order.
+func SetStepOutputs(description string, outputs map[string]string) string {
+	set := make(map[string]bool)
+	description = outputLineRegex.ReplaceAllStringFunc(description, func(line string) string {
+		key := outputLineRegex.FindStringSubmatch(line)[1]
+		if v, ok := outputs[key]; ok {
+			set[key] = true
+			return fmt.Sprintf("output.%s: %s", key, strconv.Quote(v))
+		}
+		return line
+	})
+
+	keys := make([]string, 0, len(outputs))
+	for k := range outputs {
+		if !set[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if description != "" {
+			description += "\n"
+		}
+		description += fmt.Sprintf("output.%s: %s", k, strconv.Quote(outputs[k]))
+	}
+	return description
+}
+
+// CheckStepOutputs validates outputs against those the step declares. Keys
+// must be valid; when the step declares outputs, keys must be among them
+// and, if final, every declared output must be recorded (earlier values
+// count).
+func CheckStepOutputs(step *Issue, outputs map[string]string, final bool) error {
+	for k := range outputs {
+		if !ValidOutputName(k) {
+			return fmt.Errorf("invalid output name %q", k)
+		}
+	}
+	declared := ParseStepControl(step.Description).Outputs
+	if len(declared) == 0 {
+		return nil
+	}
+
+	var unknown []string
+	for k := range outputs {
+		if !contains(declared, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("step %s does not declare output(s): %s (declared: %s)",
+			step.ID, strings.Join(unknown, ", "), strings.Join(declared, ", "))
+	}
+
+	if final {
+		recorded := StepOutputs(step.Description)
+		var missing []string
+		for _, k := range declared {
+			if _, ok := outputs[k]; !ok {
+				if _, ok := recorded[k]; !ok {
+					missing = append(missing, k)
+				}
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("step %s declares output(s) not yet recorded: %s (use --output %s=<value>)",
+				step.ID, strings.Join(missing, ", "), missing[0])
+		}
+	}
+	return nil
+}
+
+// ExpandStepOutputs replaces {{steps.<ref>.<key>}} placeholders with the
+// outputs (or status/iterations) of a molecule's step beads. Unknown
+// references are left as-is.
+func ExpandStepOutputs(text string, steps []*Issue) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	lookup := StepLookup(steps, nil)
+	return stepOutputRefRegex.ReplaceAllStringFunc(text, func(m string) string {
+		if v, ok := lookup(stepOutputRefRegex.FindStringSubmatch(m)[1]); ok {
+			return v
+		}
+		return m
+	})
+}
+
+// StepOutputRefs returns the steps.<ref>.<key> references used as
+// {{...}} placeholders in text.
+func StepOutputRefs(text string) []string {
+	var refs []string
+	for _, m := range stepOutputRefRegex.FindAllStringSubmatch(text, -1) {
+		refs = append(refs, m[1])
+	}
+	return refs
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package beads
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStepOutputsRoundTrip(t *testing.T) {
+	desc := "Design the limiter.\n\nstep: design\noutputs: approach, notes"
+	desc = SetStepOutputs(desc, map[string]string{"notes": "line one\nline \"two\"", "approach": "token bucket"})
+	desc = SetStepOutputs(desc, map[string]string{"approach": "leaky bucket"})
+
+	want := map[string]string{"approach": "leaky bucket", "notes": "line one\nline \"two\""}
+	if got := StepOutputs(desc); !reflect.DeepEqual(got, want) {
+		t.Errorf("StepOutputs = %v, want %v", got, want)
+	}
+	if n := strings.Count(desc, "output.approach:"); n != 1 {
+		t.Errorf("approach recorded %d times:\n%s", n, desc)
+	}
+	if got := ParseStepControl(desc).Outputs; !reflect.DeepEqual(got, []string{"approach", "notes"}) {
+		t.Errorf("declared outputs = %v", got)
+	}
+}
+
+func TestCheckStepOutputs(t *testing.T) {
+	step := &Issue{ID: "gt-mol.1", Description: "step: design\noutputs: approach, risk"}
+
+	if err := CheckStepOutputs(step, map[string]string{"aproach": "x"}, false); err == nil || !strings.Contains(err.Error(), "does not declare") {
+		t.Errorf("expected undeclared output error, got %v", err)
+	}
+	if err := CheckStepOutputs(step, map[string]string{"approach": "x"}, true); err == nil || !strings.Contains(err.Error(), "risk") {
+		t.Errorf("expected missing risk error, got %v", err)
+	}
+	step.Description = SetStepOutputs(step.Description, map[string]string{"risk": "low"})
+	if err := CheckStepOutputs(step, map[string]string{"approach": "x"}, true); err != nil {
+		t.Errorf("earlier outputs should count: %v", err)
+	}
+	free := &Issue{ID: "gt-mol.2", Description: "step: anything"}
+	if err := CheckStepOutputs(free, map[string]string{"whatever": "x"}, true); err != nil {
+		t.Errorf("steps without declared outputs accept any: %v", err)
+	}
+	if err := CheckStepOutputs(free, map[string]string{"status": "x"}, false); err == nil {
+		t.Error("expected reserved name error")
+	}
+}
+
+func TestExpandStepOutputs(t *testing.T) {
+	steps := []*Issue{
+		{ID: "gt-mol.1", Status: "closed", Description: SetStepOutputs("step: design", map[string]string{"approach": "token bucket"})},
+		{ID: "gt-mol.2", Status: "closed", Description: SetStepOutputs("step: scan\nitem: a", map[string]string{"found": "1"})},
+		{ID: "gt-mol.3", Status: "closed", Description: SetStepOutputs("step: scan\nitem: b", map[string]string{"found": "2"})},
+	}
+	got := ExpandStepOutputs("Implement {{steps.design.approach}} ({{steps.scan.found}}; {{steps.scan.status}}; {{steps.design.missing}})", steps)
+	want := "Implement token bucket (1, 2; done; {{steps.design.missing}})"
+	if got != want {
+		t.Errorf("ExpandStepOutputs = %q, want %q", got, want)
+	}
+}
+
+func TestValidateMolecule_OutputReferences(t *testing.T) {
+	desc := `## Step: design
+Pick an approach.
+Outputs: approach
+
+## Step: implement
+Implement {{steps.design.approach}}.
+Needs: design`
+	if err := ValidateMolecule(&Issue{ID: "mol-x", Type: "molecule", Description: desc}); err != nil {
+		t.Errorf("ValidateMolecule: %v", err)
+	}
+
+	bad := strings.Replace(desc, "design.approach", "design.plan", 1)
+	err := ValidateMolecule(&Issue{ID: "mol-x", Type: "molecule", Description: bad})
+	if err == nil || !strings.Contains(err.Error(), "does not declare") {
+		t.Errorf("expected undeclared output error, got %v", err)
+	}
+}
@@ -231,6 +231,25 @@ func GetRigPathForPrefix(townRoot, prefix string) string {
 	return ""
 }
 
+// GetRigForPrefix returns the name of the rig that owns a bead ID prefix
+// (e.g., "gt-"). Returns empty string for town-level beads and unknown
+// prefixes.
+// The townRoot should be the Gas Town root directory (e.g., ~/gt).
+func GetRigForPrefix(townRoot, prefix string) string {
+	routes, err := LoadRoutes(filepath.Join(townRoot, ".beads"))
+	if err != nil || routes == nil {
+		return ""
+	}
+
+	for _, r := range routes {
+		if r.Prefix == prefix && r.Path != "." {
+			return strings.SplitN(r.Path, "/", 2)[0]
+		}
+	}
+
+	return ""
+}
+
 // ResolveHookDir determines the directory for running bd update on a bead.
 // Since bd update doesn't support routing or redirects, we must resolve the
 // actual rig directory from the bead's prefix. hookWorkDir is only used as
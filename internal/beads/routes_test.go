@@ -52,6 +52,32 @@ func TestGetPrefixForRig_NoRoutesFile(t *testing.T) {
 	}
 }
 
+func TestGetRigForPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	beadsDir := filepath.Join(tmpDir, ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	routesContent := `{"prefix": "gt-", "path": "gastown/mayor/rig"}
+{"prefix": "hq-", "path": "."}
+`
+	if err := os.WriteFile(filepath.Join(beadsDir, "routes.jsonl"), []byte(routesContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"gt-":  "gastown",
+		"hq-":  "", // town-level
+		"xyz-": "", // unknown
+	}
+	for prefix, expected := range tests {
+		if result := GetRigForPrefix(tmpDir, prefix); result != expected {
+			t.Errorf("GetRigForPrefix(%q) = %q, want %q", prefix, result, expected)
+		}
+	}
+}
+
 func TestExtractPrefix(t *testing.T) {
 	tests := []struct {
 		beadID   string
@@ -403,26 +403,15 @@ func formatTownlogSummary(e townlog.Event) string {
 func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, error) {
 	var entries []AuditEntry
 
-	eventsPath := filepath.Join(townRoot, events.EventsFile)
-	file, err := os.Open(eventsPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No events file yet
-		}
-		return nil, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+	err := events.Scan(townRoot, since, func(line []byte) error {
 		var e events.Event
-		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
-			continue // Skip malformed lines
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil // Skip malformed lines
 		}
 
 		// Apply actor filter
 		if actor != "" && !matchesActor(e.Actor, actor) {
-			continue
+			return nil
 		}
 
 		// Parse timestamp
@@ -430,7 +419,7 @@ func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, e
 
 		// Apply since filter
 		if !since.IsZero() && ts.Before(since) {
-			continue
+			return nil
 		}
 
 		entries = append(entries, AuditEntry{
@@ -440,9 +429,10 @@ func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, e
 			Actor:     e.Actor,
 			Summary:   formatFeedSummary(e),
 		})
-	}
+		return nil
+	})
 
-	return entries, nil
+	return entries, err
 }
 
 // formatFeedSummary creates a readable summary from a feed event.
@@ -7,18 +7,21 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 var (
 	broadcastRig    string
 	broadcastAll    bool
 	broadcastDryRun bool
+	broadcastUrgent bool
 )
 
 func init() {
 	broadcastCmd.Flags().StringVar(&broadcastRig, "rig", "", "Only broadcast to workers in this rig")
 	broadcastCmd.Flags().BoolVar(&broadcastAll, "all", false, "Include all agents (mayor, witness, etc.), not just workers")
 	broadcastCmd.Flags().BoolVar(&broadcastDryRun, "dry-run", false, "Show what would be sent without sending")
+	broadcastCmd.Flags().BoolVar(&broadcastUrgent, "urgent", false, "Deliver to agents in a quiet window too")
 	rootCmd.AddCommand(broadcastCmd)
 }
 
@@ -32,6 +35,8 @@ By default, only workers (polecats and crew) receive the message.
 Use --all to include infrastructure agents (mayor, deacon, witness, refinery).
 
 The message is sent as a nudge to each worker's Claude Code session.
+Agents in a quiet window (see "quiet_policies" in config/messaging.json)
+get it in a digest when the window ends, unless --urgent is set.
 
 Examples:
   gt broadcast "Check your mail"
@@ -93,7 +98,8 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 
 	// Send nudges
 	t := tmux.NewTmux()
-	var succeeded, failed int
+	townRoot, _ := workspace.FindFromCwd()
+	var succeeded, failed, held int
 	var failures []string
 
 	fmt.Printf("Broadcasting to %d agent(s)...\n\n", len(targets))
@@ -101,6 +107,12 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 	for i, agent := range targets {
 		agentName := formatAgentName(agent)
 
+		if decision, err := holdForQuietPolicy(townRoot, agentName, agent.Name, message, broadcastUrgent); err == nil && decision.Quiet {
+			held++
+			fmt.Printf("  %s %s %s %s\n", style.Dim.Render("○"), AgentTypeIcons[agent.Type], agentName, style.Dim.Render("(held: "+decision.Reason+")"))
+			continue
+		}
+
 		if err := t.NudgeSession(agent.Name, message); err != nil {
 			failed++
 			failures = append(failures, fmt.Sprintf("%s: %v", agentName, err))
@@ -127,6 +139,9 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("%s Broadcast complete: %d agent(s) nudged\n", style.SuccessPrefix, succeeded)
+	if held > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("%d held for quiet-window digest", held)))
+	}
 	return nil
 }
 
@@ -22,14 +22,18 @@ var configCmd = &cobra.Command{
 	Long: `Manage Gas Town configuration settings.
 
 This command allows you to view and modify configuration settings
-for your Gas Town workspace, including agent aliases and defaults.
+for your Gas Town workspace, including agent aliases and defaults,
+and checks config files against their schemas.
 
 Commands:
   gt config agent list              List all agents (built-in and custom)
   gt config agent get <name>         Show agent configuration
   gt config agent set <name> <cmd>   Set custom agent command
   gt config agent remove <name>      Remove custom agent
-  gt config default-agent [name]     Get or set default agent`,
+  gt config default-agent [name]     Get or set default agent
+  gt config lint                     Validate all config files
+  gt config migrate                  Upgrade config files to current schemas
+  gt config schema [kind]            Export JSON Schemas for config files`,
 }
 
 // Agent subcommands
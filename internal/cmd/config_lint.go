@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Config lint/migrate/schema flags
+var (
+	configLintJSON      bool
+	configMigrateDryRun bool
+	configSchemaOut     string
+)
+
+var configLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Validate all config files in the town and rigs",
+	Long: `Validate every config file in the town and its rigs against its schema.
+
+Reports JSON syntax errors, unknown keys (typos are otherwise silently
+ignored), wrong value types, wrong "type" tags, unsupported or outdated
+schema versions, and the same semantic checks the loaders apply.
+Each issue is reported as file:line:column: key: message.
+
+Exits with status 1 if any errors are found (warnings alone pass).
+
+Examples:
+  gt config lint           # Lint the town
+  gt config lint --json    # Machine-readable issues`,
+	RunE: runConfigLint,
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade config files to the current schema versions",
+	Long: `Upgrade config files written by older gt versions to the current schemas.
+
+Each outdated file is backed up next to the original as <file>.v<N>.bak,
+then rewritten in place. A migrated file must pass validation before it
+replaces the original.
+
+Examples:
+  gt config migrate            # Upgrade outdated files
+  gt config migrate --dry-run  # Show what would change`,
+	RunE: runConfigMigrate,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema [kind]",
+	Short: "Export JSON Schemas for config files",
+	Long: `Print the JSON Schema for a kind of config file, or list the kinds.
+
+Kinds: ` + configKindNames() + `
+
+Examples:
+  gt config schema                  # List kinds and their locations
+  gt config schema rigs             # Print the rigs.json schema
+  gt config schema --out schemas/   # Write <kind>.schema.json for every kind`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigSchema,
+}
+
+func init() {
+	configLintCmd.Flags().BoolVar(&configLintJSON, "json", false, "Output as JSON")
+	configMigrateCmd.Flags().BoolVar(&configMigrateDryRun, "dry-run", false, "Show what would be migrated without writing")
+	configSchemaCmd.Flags().StringVar(&configSchemaOut, "out", "", "Write every schema into this directory")
+
+	configCmd.AddCommand(configLintCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+func runConfigLint(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	issues := config.LintTown(townRoot)
+	for i := range issues {
+		if rel, err := filepath.Rel(townRoot, issues[i].File); err == nil {
+			issues[i].File = rel
+		}
+	}
+
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Level == config.LintError {
+			errorCount++
+		}
+	}
+
+	if configLintJSON {
+		if issues == nil {
+			issues = []config.LintIssue{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			return err
+		}
+	} else {
+		files := len(config.FindConfigFiles(townRoot))
+		for _, issue := range issues {
+			marker := style.Warning.Render("⚠")
+			if issue.Level == config.LintError {
+				marker = style.Error.Render("✗")
+			}
+			fmt.Printf("%s %s\n", marker, issue)
+		}
+		if len(issues) == 0 {
+			fmt.Printf("%s %d config file(s) OK\n", style.Success.Render("✓"), files)
+		} else {
+			fmt.Printf("\n%d file(s) checked: %d error(s), %d warning(s)\n", files, errorCount, len(issues)-errorCount)
+		}
+	}
+
+	if errorCount > 0 {
+		// Issues are already printed; just signal failure
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	results, err := config.MigrateTown(townRoot, configMigrateDryRun)
+	for _, r := range results {
+		rel, relErr := filepath.Rel(townRoot, r.Path)
+		if relErr != nil {
+			rel = r.Path
+		}
+		verb := "Migrated"
+		if configMigrateDryRun {
+			verb = "Would migrate"
+		}
+		fmt.Printf("%s %s %s (v%d → v%d)\n", style.Success.Render("✓"), verb, style.Bold.Render(rel), r.From, r.To)
+		for _, step := range r.Applied {
+			fmt.Printf("   %s\n", style.Dim.Render(step))
+		}
+		if r.Backup != "" {
+			fmt.Printf("   %s\n", style.Dim.Render("backup: "+filepath.Base(r.Backup)))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println("All config files are at their current schema version.")
+	}
+	return nil
+}
+
+func runConfigSchema(cmd *cobra.Command, args []string) error {
+	if configSchemaOut != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--out writes every schema; omit the kind")
+		}
+		if err := os.MkdirAll(configSchemaOut, 0755); err != nil {
+			return fmt.Errorf("creating %s: %w", configSchemaOut, err)
+		}
+		for _, k := range config.Kinds {
+			data, err := json.MarshalIndent(k.Schema(), "", "  ")
+			if err != nil {
+				return fmt.Errorf("encoding %s schema: %w", k.Name, err)
+			}
+			path := filepath.Join(configSchemaOut, k.Name+".schema.json")
+			if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil { //nolint:gosec // G306: schemas are public
+				return fmt.Errorf("writing %s: %w", path, err)
+			}
+			fmt.Printf("%s Wrote %s\n", style.Success.Render("✓"), path)
+		}
+		return nil
+	}
+
+	if len(args) == 0 {
+		fmt.Printf("%s\n\n", style.Bold.Render("Config kinds:"))
+		for _, k := range config.Kinds {
+			fmt.Printf("  %-14s %-28s %s\n", k.Name, k.Location, style.Dim.Render(fmt.Sprintf("v%d", k.CurrentVersion)))
+		}
+		return nil
+	}
+
+	k := config.KindByName(args[0])
+	if k == nil {
+		return fmt.Errorf("unknown config kind %q (valid: %s)", args[0], configKindNames())
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(k.Schema())
+}
+
+// configKindNames returns the config kind names, comma-separated.
+func configKindNames() string {
+	names := make([]string, 0, len(config.Kinds))
+	for _, k := range config.Kinds {
+		names = append(names, k.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -16,6 +16,8 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/dag"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tui/convoy"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -62,7 +64,9 @@ func looksLikeIssueID(s string) bool {
 var (
 	convoyMolecule     string
 	convoyNotify       string
+	convoyTrain        bool
 	convoyStatusJSON   bool
+	convoyStatusFormat string
 	convoyListJSON     bool
 	convoyListStatus   string
 	convoyListAll      bool
@@ -105,9 +109,16 @@ TRACKING SEMANTICS:
   - Cross-prefix capable (convoy in hq-* tracks issues in gt-*, bd-*)
   - Landed: all tracked issues closed → notification sent to subscribers
 
+DEPENDENCIES AND TRAINS:
+  - A convoy can wait for upstream convoys (--after, gt convoy after):
+    it is blocked, and doesn't land, until they have landed
+  - A release train (--train) lands together: refineries hold each
+    member's green MR until every member is green (gt mq train)
+
 COMMANDS:
   create    Create a convoy tracking specified issues
   add       Add issues to an existing convoy (reopens if closed)
+  after     Make a convoy wait for upstream convoys to land
   close     Close a convoy (manually, regardless of tracked issue status)
   status    Show convoy progress, tracked issues, and active workers
   list      List convoys (the dashboard view)`,
@@ -125,7 +136,9 @@ Examples:
   gt convoy create "Deploy v2.0" gt-abc bd-xyz
   gt convoy create "Release prep" gt-abc --notify           # defaults to mayor/
   gt convoy create "Release prep" gt-abc --notify ops/      # notify ops/
-  gt convoy create "Feature rollout" gt-a gt-b gt-c --molecule mol-release`,
+  gt convoy create "Feature rollout" gt-a gt-b gt-c --molecule mol-release
+  gt convoy create "Client update" gt-d --after hq-cv-abc   # wait for hq-cv-abc
+  gt convoy create "API v2" gt-e ap-f --train               # land rigs together`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runConvoyCreate,
 }
@@ -136,7 +149,16 @@ var convoyStatusCmd = &cobra.Command{
 	Long: `Show detailed status for a convoy.
 
 Displays convoy metadata, tracked issues, and completion progress.
-Without an ID, shows status of all active convoys.`,
+Without an ID, shows status of all active convoys.
+
+With --format, renders the dependencies between the tracked issues
+instead, grouped by rig and colored by status: ascii for the terminal,
+dot (Graphviz), mermaid, or svg.
+
+Examples:
+  gt convoy status hq-cv-abc
+  gt convoy status hq-cv-abc --format ascii
+  gt convoy status hq-cv-abc --format dot | dot -Tsvg > convoy.svg`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runConvoyStatus,
 }
@@ -187,7 +209,7 @@ var convoyStrandedCmd = &cobra.Command{
 	Long: `Find convoys that have ready issues but no workers processing them.
 
 A convoy is "stranded" when:
-- Convoy is open
+- Convoy is open, and not waiting for an upstream convoy to land
 - Has tracked issues where:
   - status = open (not in_progress, not closed)
   - not blocked (all dependencies met)
@@ -227,9 +249,11 @@ func init() {
 	convoyCreateCmd.Flags().StringVar(&convoyMolecule, "molecule", "", "Associated molecule ID")
 	convoyCreateCmd.Flags().StringVar(&convoyNotify, "notify", "", "Address to notify on completion (default: mayor/ if flag used without value)")
 	convoyCreateCmd.Flags().Lookup("notify").NoOptDefVal = "mayor/"
+	convoyCreateCmd.Flags().BoolVar(&convoyTrain, "train", false, "Release train: hold member MRs until all are green, then land together")
 
 	// Status flags
 	convoyStatusCmd.Flags().BoolVar(&convoyStatusJSON, "json", false, "Output as JSON")
+	convoyStatusCmd.Flags().StringVar(&convoyStatusFormat, "format", "", "Render the dependency graph: ascii, dot, mermaid, or svg")
 
 	// List flags
 	convoyListCmd.Flags().BoolVar(&convoyListJSON, "json", false, "Output as JSON")
@@ -297,6 +321,9 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	if convoyMolecule != "" {
 		description += fmt.Sprintf("\nMolecule: %s", convoyMolecule)
 	}
+	if convoyTrain {
+		description += "\nTrain: true"
+	}
 
 	// Generate convoy ID with cv- prefix
 	convoyID := fmt.Sprintf("hq-cv-%s", generateShortID())
@@ -338,6 +365,12 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Add 'blocks' relations on upstream convoys
+	upstreams, err := addConvoyUpstreams(townBeads, convoyID, convoyAfter)
+	if err != nil {
+		style.PrintWarning("%v", err)
+	}
+
 	// Output
 	fmt.Printf("%s Created convoy 🚚 %s\n\n", style.Bold.Render("✓"), convoyID)
 	fmt.Printf("  Name:     %s\n", name)
@@ -351,6 +384,12 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	if convoyMolecule != "" {
 		fmt.Printf("  Molecule: %s\n", convoyMolecule)
 	}
+	if len(upstreams) > 0 {
+		fmt.Printf("  After:    %s\n", strings.Join(upstreams, ", "))
+	}
+	if convoyTrain {
+		fmt.Println("  Train:    member MRs land together")
+	}
 
 	fmt.Printf("\n  %s\n", style.Dim.Render("Convoy auto-closes when all tracked issues complete"))
 
@@ -635,6 +674,11 @@ func findStrandedConvoys(townBeads string) ([]strandedConvoyInfo, error) {
 
 	// Check each convoy for stranded state
 	for _, convoy := range convoys {
+		// Convoys waiting for an upstream convoy aren't fed yet
+		if len(openConvoys(getUpstreamConvoys(townBeads, convoy.ID))) > 0 {
+			continue
+		}
+
 		tracked := getTrackedIssues(townBeads, convoy.ID)
 		if len(tracked) == 0 {
 			continue
@@ -727,45 +771,68 @@ func isReadyIssue(t trackedIssueInfo, blockedIssues map[string]bool) bool {
 
 // checkAndCloseCompletedConvoys finds open convoys where all tracked issues are closed
 // and auto-closes them. Returns the list of convoys that were closed.
+//
+// A convoy waiting for an upstream convoy doesn't land until the upstream has,
+// and a release train doesn't land while any member MR is unmerged. Landing one
+// convoy can unblock others, so convoys are checked until none close.
 func checkAndCloseCompletedConvoys(townBeads string) ([]struct{ ID, Title string }, error) {
 	var closed []struct{ ID, Title string }
+	landedIDs := make(map[string]bool)
+
+	for {
+		// List all open convoys
+		listArgs := []string{"list", "--type=convoy", "--status=open", "--json"}
+		listCmd := exec.Command("bd", listArgs...)
+		listCmd.Dir = townBeads
+		var stdout bytes.Buffer
+		listCmd.Stdout = &stdout
+
+		if err := listCmd.Run(); err != nil {
+			return nil, fmt.Errorf("listing convoys: %w", err)
+		}
 
-	// List all open convoys
-	listArgs := []string{"list", "--type=convoy", "--status=open", "--json"}
-	listCmd := exec.Command("bd", listArgs...)
-	listCmd.Dir = townBeads
-	var stdout bytes.Buffer
-	listCmd.Stdout = &stdout
+		var convoys []struct {
+			ID          string `json:"id"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
+			return nil, fmt.Errorf("parsing convoy list: %w", err)
+		}
 
-	if err := listCmd.Run(); err != nil {
-		return nil, fmt.Errorf("listing convoys: %w", err)
-	}
+		// Check each convoy
+		landed := 0
+		for _, convoy := range convoys {
+			if landedIDs[convoy.ID] {
+				continue // Closed on an earlier pass
+			}
+			tracked := getTrackedIssues(townBeads, convoy.ID)
+			if len(tracked) == 0 {
+				continue // No tracked issues, nothing to check
+			}
 
-	var convoys []struct {
-		ID    string `json:"id"`
-		Title string `json:"title"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
-		return nil, fmt.Errorf("parsing convoy list: %w", err)
-	}
+			// Check if all tracked issues are closed
+			allClosed := true
+			for _, t := range tracked {
+				if t.Status != "closed" && t.Status != "tombstone" {
+					allClosed = false
+					break
+				}
+			}
+			if !allClosed {
+				continue
+			}
 
-	// Check each convoy
-	for _, convoy := range convoys {
-		tracked := getTrackedIssues(townBeads, convoy.ID)
-		if len(tracked) == 0 {
-			continue // No tracked issues, nothing to check
-		}
+			// Upstream convoys land first
+			if len(openConvoys(getUpstreamConvoys(townBeads, convoy.ID))) > 0 {
+				continue
+			}
 
-		// Check if all tracked issues are closed
-		allClosed := true
-		for _, t := range tracked {
-			if t.Status != "closed" && t.Status != "tombstone" {
-				allClosed = false
-				break
+			// Train members close their issues on submit; wait for the merges
+			if isTrainConvoy(convoy.Description) && !trainLanded(getTrainMembers(filepath.Dir(townBeads), tracked)) {
+				continue
 			}
-		}
 
-		if allClosed {
 			// Close the convoy
 			closeArgs := []string{"close", convoy.ID, "-r", "All tracked issues completed"}
 			closeCmd := exec.Command("bd", closeArgs...)
@@ -777,13 +844,17 @@ func checkAndCloseCompletedConvoys(townBeads string) ([]struct{ ID, Title string
 			}
 
 			closed = append(closed, struct{ ID, Title string }{convoy.ID, convoy.Title})
+			landedIDs[convoy.ID] = true
+			landed++
 
 			// Check if convoy has notify address and send notification
 			notifyConvoyCompletion(townBeads, convoy.ID, convoy.Title)
 		}
-	}
 
-	return closed, nil
+		if landed == 0 {
+			return closed, nil
+		}
+	}
 }
 
 // notifyConvoyCompletion sends a notification if the convoy has a notify address.
@@ -832,6 +903,9 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 
 	// If no ID provided, show all active convoys
 	if len(args) == 0 {
+		if convoyStatusFormat != "" {
+			return fmt.Errorf("--format needs a convoy ID")
+		}
 		return showAllConvoyStatus(townBeads)
 	}
 
@@ -897,22 +971,45 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if convoyStatusFormat != "" {
+		return showConvoyGraph(townBeads, &beads.Issue{ID: convoy.ID, Title: convoy.Title}, tracked)
+	}
+
+	// Convoy dependencies and release train members
+	upstream := getUpstreamConvoys(townBeads, convoyID)
+	downstream := getDownstreamConvoys(townBeads, convoyID)
+	train := isTrainConvoy(convoy.Description)
+	var members []trainMember
+	if train {
+		members = getTrainMembers(filepath.Dir(townBeads), tracked)
+	}
+
 	if convoyStatusJSON {
 		type jsonStatus struct {
-			ID        string             `json:"id"`
-			Title     string             `json:"title"`
-			Status    string             `json:"status"`
-			Tracked   []trackedIssueInfo `json:"tracked"`
-			Completed int                `json:"completed"`
-			Total     int                `json:"total"`
+			ID           string             `json:"id"`
+			Title        string             `json:"title"`
+			Status       string             `json:"status"`
+			Tracked      []trackedIssueInfo `json:"tracked"`
+			Completed    int                `json:"completed"`
+			Total        int                `json:"total"`
+			Blocked      bool               `json:"blocked,omitempty"`
+			Upstream     []convoyRef        `json:"upstream,omitempty"`
+			Downstream   []convoyRef        `json:"downstream,omitempty"`
+			Train        bool               `json:"train,omitempty"`
+			TrainMembers []trainMember      `json:"train_members,omitempty"`
 		}
 		out := jsonStatus{
-			ID:        convoy.ID,
-			Title:     convoy.Title,
-			Status:    convoy.Status,
-			Tracked:   tracked,
-			Completed: completed,
-			Total:     len(tracked),
+			ID:           convoy.ID,
+			Title:        convoy.Title,
+			Status:       convoy.Status,
+			Tracked:      tracked,
+			Completed:    completed,
+			Total:        len(tracked),
+			Blocked:      len(openConvoys(upstream)) > 0,
+			Upstream:     upstream,
+			Downstream:   downstream,
+			Train:        train,
+			TrainMembers: members,
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -921,12 +1018,25 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 
 	// Human-readable output
 	fmt.Printf("🚚 %s %s\n\n", style.Bold.Render(convoy.ID+":"), convoy.Title)
-	fmt.Printf("  Status:    %s\n", formatConvoyStatus(convoy.Status))
+	statusDisplay := formatConvoyStatus(convoy.Status)
+	if convoy.Status == "open" && len(openConvoys(upstream)) > 0 {
+		statusDisplay += " " + style.Warning.Render("(blocked)")
+	}
+	fmt.Printf("  Status:    %s\n", statusDisplay)
 	fmt.Printf("  Progress:  %d/%d completed\n", completed, len(tracked))
 	fmt.Printf("  Created:   %s\n", convoy.CreatedAt)
 	if convoy.ClosedAt != "" {
 		fmt.Printf("  Closed:    %s\n", convoy.ClosedAt)
 	}
+	if len(upstream) > 0 {
+		fmt.Printf("  After:     %s\n", formatConvoyRefs(upstream))
+	}
+	if len(downstream) > 0 {
+		fmt.Printf("  Before:    %s\n", formatConvoyRefs(downstream))
+	}
+	if train {
+		fmt.Printf("  Train:     %d/%d members green or landed\n", len(members)-len(trainWaitingOn(members)), len(members))
+	}
 
 	if len(tracked) > 0 {
 		fmt.Printf("\n  %s\n", style.Bold.Render("Tracked Issues:"))
@@ -961,6 +1071,63 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if train && len(members) > 0 {
+		fmt.Printf("\n  %s\n", style.Bold.Render("Release Train:"))
+		for _, m := range members {
+			line := fmt.Sprintf("    %s %s: %s", trainStateIcon(m.State), m.IssueID, m.State)
+			if m.MRID != "" {
+				line += "  " + style.Dim.Render(m.MRID)
+			}
+			if m.Rig != "" {
+				line += "  " + style.Dim.Render("["+m.Rig+"]")
+			}
+			fmt.Println(line)
+		}
+	}
+
+	return nil
+}
+
+// formatConvoyRefs lists convoys with a ✓ for those that have landed.
+func formatConvoyRefs(refs []convoyRef) string {
+	parts := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Status == "closed" {
+			parts = append(parts, ref.ID+" ✓")
+		} else {
+			parts = append(parts, ref.ID)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// showConvoyGraph renders the dependencies between a convoy's tracked
+// issues, which may span rigs.
+func showConvoyGraph(townBeads string, convoy *beads.Issue, tracked []trackedIssueInfo) error {
+	ids := make([]string, 0, len(tracked))
+	for _, t := range tracked {
+		ids = append(ids, t.ID)
+	}
+	issues, err := beads.New(townBeads).ShowMultiple(ids)
+	if err != nil {
+		return fmt.Errorf("getting tracked issues: %w", err)
+	}
+	// A failed batch lookup still leaves the status getTrackedIssues found
+	for _, t := range tracked {
+		if issues[t.ID] == nil && t.Status != "unknown" {
+			issues[t.ID] = &beads.Issue{ID: t.ID, Title: t.Title, Status: t.Status}
+		}
+	}
+
+	townRoot := filepath.Dir(townBeads)
+	rigOf := func(id string) string {
+		return beads.GetRigForPrefix(townRoot, beads.ExtractPrefix(id))
+	}
+	out, err := dag.Render(beads.ConvoyGraph(convoy, ids, issues, rigOf), convoyStatusFormat)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
 	return nil
 }
 
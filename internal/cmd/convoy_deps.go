@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var convoyAfter []string
+
+var convoyAfterCmd = &cobra.Command{
+	Use:   "after <convoy-id> <upstream-convoy-id> [upstream-convoy-id...]",
+	Short: "Make a convoy wait for other convoys to land",
+	Long: `Make a convoy depend on one or more upstream convoys.
+
+A convoy with an open upstream is blocked until the upstream lands:
+  - Stranded detection skips it, so its work isn't fed to polecats
+  - It doesn't auto-close, even when all its tracked issues are done
+  - Release train MRs in it are held by the refineries
+
+When an upstream lands, the convoy watcher re-checks its downstream convoys,
+which land in turn if their own work is complete.
+
+The dependency is a 'blocks' relation between the convoy beads, so cycles
+are rejected. Remove one with: bd dep remove <convoy-id> <upstream-convoy-id>
+
+Examples:
+  gt convoy after hq-cv-client hq-cv-backend
+  gt convoy create "Client rollout" gt-abc --after hq-cv-backend`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runConvoyAfter,
+}
+
+func init() {
+	convoyCreateCmd.Flags().StringSliceVar(&convoyAfter, "after", nil, "Upstream convoy(s) that must land first")
+	convoyCmd.AddCommand(convoyAfterCmd)
+}
+
+func runConvoyAfter(cmd *cobra.Command, args []string) error {
+	convoyID := args[0]
+
+	townBeads, err := getTownBeadsDir()
+	if err != nil {
+		return err
+	}
+
+	convoy, err := beads.New(townBeads).Show(convoyID)
+	if err != nil {
+		return fmt.Errorf("convoy '%s' not found", convoyID)
+	}
+	if convoy.Type != "convoy" {
+		return fmt.Errorf("'%s' is not a convoy (type: %s)", convoyID, convoy.Type)
+	}
+
+	added, err := addConvoyUpstreams(townBeads, convoyID, args[1:])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Convoy 🚚 %s now waits for %s\n", style.Bold.Render("✓"), convoyID, strings.Join(added, ", "))
+	if blocking := openConvoys(getUpstreamConvoys(townBeads, convoyID)); len(blocking) > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("Blocked until %d upstream convoy(s) land", len(blocking))))
+	}
+	return nil
+}
+
+// addConvoyUpstreams makes convoyID depend on each upstream convoy.
+// Every upstream must be a convoy, and no dependency may close a cycle.
+// Returns the upstreams that were added.
+func addConvoyUpstreams(townBeads, convoyID string, upstreams []string) ([]string, error) {
+	b := beads.New(townBeads)
+	upstreamsOf := func(id string) []string {
+		var ids []string
+		for _, ref := range getUpstreamConvoys(townBeads, id) {
+			ids = append(ids, ref.ID)
+		}
+		return ids
+	}
+
+	var added []string
+	for _, upstreamID := range upstreams {
+		upstream, err := b.Show(upstreamID)
+		if err != nil {
+			return added, fmt.Errorf("upstream convoy '%s' not found", upstreamID)
+		}
+		if upstream.Type != "convoy" {
+			return added, fmt.Errorf("'%s' is not a convoy (type: %s)", upstreamID, upstream.Type)
+		}
+		if convoyDependencyCycle(convoyID, upstreamID, upstreamsOf) {
+			return added, fmt.Errorf("%s already waits for %s: dependency would create a cycle", upstreamID, convoyID)
+		}
+
+		depCmd := exec.Command("bd", "dep", "add", convoyID, upstreamID, "--type=blocks")
+		depCmd.Dir = townBeads
+		var stderr bytes.Buffer
+		depCmd.Stderr = &stderr
+		if err := depCmd.Run(); err != nil {
+			return added, fmt.Errorf("adding dependency on %s: %w (%s)", upstreamID, err, strings.TrimSpace(stderr.String()))
+		}
+		added = append(added, upstreamID)
+	}
+	return added, nil
+}
+
+// convoyDependencyCycle reports whether making convoyID wait for upstreamID
+// would close a cycle, i.e. convoyID is upstreamID or one of its upstreams.
+func convoyDependencyCycle(convoyID, upstreamID string, upstreamsOf func(string) []string) bool {
+	seen := make(map[string]bool)
+	queue := []string{upstreamID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == convoyID {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		queue = append(queue, upstreamsOf(id)...)
+	}
+	return false
+}
+
+// convoyRef is a convoy on the other end of a convoy dependency.
+type convoyRef struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// getUpstreamConvoys returns the convoys that convoyID waits for.
+func getUpstreamConvoys(townBeads, convoyID string) []convoyRef {
+	safeConvoyID := strings.ReplaceAll(convoyID, "'", "''")
+	return queryConvoyRefs(townBeads, fmt.Sprintf(`
+		SELECT i.id, i.title, i.status FROM dependencies d
+		JOIN issues i ON i.id = d.depends_on_id
+		WHERE d.issue_id = '%s' AND d.type = 'blocks' AND i.issue_type = 'convoy'`, safeConvoyID))
+}
+
+// getDownstreamConvoys returns the convoys that wait for convoyID.
+func getDownstreamConvoys(townBeads, convoyID string) []convoyRef {
+	safeConvoyID := strings.ReplaceAll(convoyID, "'", "''")
+	return queryConvoyRefs(townBeads, fmt.Sprintf(`
+		SELECT i.id, i.title, i.status FROM dependencies d
+		JOIN issues i ON i.id = d.issue_id
+		WHERE d.depends_on_id = '%s' AND d.type = 'blocks' AND i.issue_type = 'convoy'`, safeConvoyID))
+}
+
+// queryConvoyRefs runs a convoy dependency query against town beads.
+func queryConvoyRefs(townBeads, query string) []convoyRef {
+	dbPath := filepath.Join(townBeads, "beads.db")
+	queryCmd := exec.Command("sqlite3", "-json", dbPath, query)
+	var stdout bytes.Buffer
+	queryCmd.Stdout = &stdout
+	if err := queryCmd.Run(); err != nil {
+		return nil
+	}
+
+	var refs []convoyRef
+	if err := json.Unmarshal(stdout.Bytes(), &refs); err != nil {
+		return nil
+	}
+	return refs
+}
+
+// openConvoys returns the convoys that haven't landed yet.
+func openConvoys(refs []convoyRef) []convoyRef {
+	var open []convoyRef
+	for _, ref := range refs {
+		if ref.Status != "closed" && ref.Status != "tombstone" {
+			open = append(open, ref)
+		}
+	}
+	return open
+}
+
+// convoyRefIDs returns the IDs of refs.
+func convoyRefIDs(refs []convoyRef) []string {
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ID)
+	}
+	return ids
+}
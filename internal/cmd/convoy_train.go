@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// A release train is a convoy whose MRs land together: each rig's refinery
+// holds a member's MR once its tests pass, and lands it only when every
+// member is green (or already landed). Trains are marked by a "Train: true"
+// line in the convoy description.
+
+// Train member states.
+const (
+	trainMemberLanded  = "landed"  // MR merged, or issue closed without one
+	trainMemberGreen   = "green"   // MR passed tests and is held
+	trainMemberPending = "pending" // MR waiting to be tested
+	trainMemberWorking = "working" // no MR submitted yet
+)
+
+// trainMember is one tracked issue of a release train.
+type trainMember struct {
+	IssueID string `json:"issue_id"`
+	Rig     string `json:"rig,omitempty"`
+	MRID    string `json:"mr_id,omitempty"`
+	State   string `json:"state"`
+}
+
+// trainConvoy is an open release train convoy.
+type trainConvoy struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// isTrainConvoy reports whether a convoy description marks a release train.
+func isTrainConvoy(description string) bool {
+	for _, line := range strings.Split(description, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "Train:"); ok {
+			value = strings.ToLower(strings.TrimSpace(value))
+			return value == "true" || value == "yes"
+		}
+	}
+	return false
+}
+
+// findTrainConvoy returns the open release train tracking issueID, or nil.
+// A preferred convoy ID (an MR's convoy_id field) wins when several do.
+func findTrainConvoy(townBeads, issueID, preferred string) *trainConvoy {
+	dbPath := filepath.Join(townBeads, "beads.db")
+	safeIssueID := strings.ReplaceAll(issueID, "'", "''")
+	query := fmt.Sprintf(`
+		SELECT DISTINCT i.id, i.title, i.description
+		FROM dependencies d
+		JOIN issues i ON d.issue_id = i.id
+		WHERE d.type = 'tracks'
+		AND i.issue_type = 'convoy'
+		AND i.status = 'open'
+		AND (d.depends_on_id = '%s' OR d.depends_on_id LIKE '%%:%s')
+	`, safeIssueID, safeIssueID)
+
+	queryCmd := exec.Command("sqlite3", "-json", dbPath, query)
+	var stdout bytes.Buffer
+	queryCmd.Stdout = &stdout
+	if err := queryCmd.Run(); err != nil {
+		return nil
+	}
+
+	var convoys []trainConvoy
+	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
+		return nil
+	}
+
+	var found *trainConvoy
+	for i := range convoys {
+		if !isTrainConvoy(convoys[i].Description) {
+			continue
+		}
+		if found == nil || convoys[i].ID == preferred {
+			found = &convoys[i]
+		}
+	}
+	return found
+}
+
+// getTrainMembers finds the MR for each tracked issue, looking in the
+// merge queue of the issue's rig.
+func getTrainMembers(townRoot string, tracked []trackedIssueInfo) []trainMember {
+	// MRs by source issue, listed once per rig. An open MR wins over
+	// closed ones, which only say whether earlier work merged.
+	mrsByRig := make(map[string]map[string]*beads.Issue)
+	rigMRs := func(rigName string) map[string]*beads.Issue {
+		if mrs, ok := mrsByRig[rigName]; ok {
+			return mrs
+		}
+		mrs := make(map[string]*beads.Issue)
+		mrsByRig[rigName] = mrs
+		if rigName == "" {
+			return mrs
+		}
+		issues, err := beads.New(filepath.Join(townRoot, rigName)).List(beads.ListOptions{
+			Type:     "merge-request",
+			Status:   "all",
+			Priority: -1,
+		})
+		if err != nil {
+			return mrs
+		}
+		for _, issue := range issues {
+			fields := beads.ParseMRFields(issue)
+			if fields == nil || fields.SourceIssue == "" {
+				continue
+			}
+			if prev := mrs[fields.SourceIssue]; prev == nil || prev.Status == "closed" {
+				mrs[fields.SourceIssue] = issue
+			}
+		}
+		return mrs
+	}
+
+	members := make([]trainMember, 0, len(tracked))
+	for _, t := range tracked {
+		m := trainMember{
+			IssueID: t.ID,
+			Rig:     beads.GetRigForPrefix(townRoot, beads.ExtractPrefix(t.ID)),
+		}
+		mr := rigMRs(m.Rig)[t.ID]
+		if mr != nil && mr.Status != "closed" {
+			m.MRID = mr.ID
+		}
+		m.State = trainMemberState(t.Status, mr)
+		members = append(members, m)
+	}
+	return members
+}
+
+// trainMemberState decides a member's state from its issue status and its
+// latest MR, if any. The MR outranks the issue status, since polecats close
+// their issue when they submit.
+func trainMemberState(issueStatus string, mr *beads.Issue) string {
+	if mr != nil {
+		fields := beads.ParseMRFields(mr)
+		if mr.Status == "closed" {
+			if fields == nil || fields.CloseReason == "" || fields.CloseReason == "merged" {
+				return trainMemberLanded
+			}
+			return trainMemberWorking // rejected or superseded: needs a new MR
+		}
+		if fields != nil && fields.TrainStatus == beads.TrainStatusGreen {
+			return trainMemberGreen
+		}
+		return trainMemberPending
+	}
+	if issueStatus == "closed" || issueStatus == "tombstone" {
+		return trainMemberLanded
+	}
+	return trainMemberWorking
+}
+
+// trainWaitingOn returns the members that keep the train from landing:
+// those neither green nor landed.
+func trainWaitingOn(members []trainMember) []trainMember {
+	var waiting []trainMember
+	for _, m := range members {
+		if m.State != trainMemberGreen && m.State != trainMemberLanded {
+			waiting = append(waiting, m)
+		}
+	}
+	return waiting
+}
+
+// trainLanded reports whether every member of a train has landed.
+func trainLanded(members []trainMember) bool {
+	for _, m := range members {
+		if m.State != trainMemberLanded {
+			return false
+		}
+	}
+	return true
+}
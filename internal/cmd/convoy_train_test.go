@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestConvoyDependencyCycle(t *testing.T) {
+	// client waits for api, api waits for storage
+	upstreams := map[string][]string{
+		"hq-cv-client": {"hq-cv-api"},
+		"hq-cv-api":    {"hq-cv-storage"},
+	}
+	upstreamsOf := func(id string) []string { return upstreams[id] }
+
+	tests := []struct {
+		convoy, upstream string
+		want             bool
+	}{
+		{"hq-cv-docs", "hq-cv-client", false},
+		{"hq-cv-client", "hq-cv-storage", false},
+		{"hq-cv-storage", "hq-cv-client", true},
+		{"hq-cv-api", "hq-cv-client", true},
+		{"hq-cv-api", "hq-cv-api", true},
+	}
+	for _, tt := range tests {
+		if got := convoyDependencyCycle(tt.convoy, tt.upstream, upstreamsOf); got != tt.want {
+			t.Errorf("convoyDependencyCycle(%s, %s) = %v, want %v", tt.convoy, tt.upstream, got, tt.want)
+		}
+	}
+}
+
+func TestOpenConvoys(t *testing.T) {
+	refs := []convoyRef{
+		{ID: "hq-cv-a", Status: "closed"},
+		{ID: "hq-cv-b", Status: "open"},
+		{ID: "hq-cv-c", Status: "tombstone"},
+	}
+	if got := convoyRefIDs(openConvoys(refs)); len(got) != 1 || got[0] != "hq-cv-b" {
+		t.Errorf("openConvoys() = %v, want [hq-cv-b]", got)
+	}
+	if got := formatConvoyRefs(refs[:2]); got != "hq-cv-a ✓, hq-cv-b" {
+		t.Errorf("formatConvoyRefs() = %q", got)
+	}
+}
+
+func TestIsTrainConvoy(t *testing.T) {
+	tests := []struct {
+		desc string
+		want bool
+	}{
+		{"Convoy tracking 2 issues\nTrain: true", true},
+		{"Convoy tracking 2 issues\nNotify: mayor/\n  Train: yes", true},
+		{"Convoy tracking 2 issues\nTrain: false", false},
+		{"Convoy tracking 2 issues", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isTrainConvoy(tt.desc); got != tt.want {
+			t.Errorf("isTrainConvoy(%q) = %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestTrainMemberState(t *testing.T) {
+	mr := func(status, desc string) *beads.Issue {
+		return &beads.Issue{ID: "gt-mr", Status: status, Description: desc}
+	}
+
+	tests := []struct {
+		name        string
+		issueStatus string
+		mr          *beads.Issue
+		want        string
+	}{
+		{"no MR yet", "in_progress", nil, trainMemberWorking},
+		{"closed without MR", "closed", nil, trainMemberLanded},
+		{"submitted", "closed", mr("open", "branch: b\nsource_issue: gt-a"), trainMemberPending},
+		{"green", "closed", mr("open", "source_issue: gt-a\ntrain_status: green"), trainMemberGreen},
+		{"merged", "closed", mr("closed", "source_issue: gt-a"), trainMemberLanded},
+		{"merged by engineer", "closed", mr("closed", "source_issue: gt-a\nclose_reason: merged"), trainMemberLanded},
+		{"rejected", "open", mr("closed", "source_issue: gt-a\nclose_reason: rejected"), trainMemberWorking},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trainMemberState(tt.issueStatus, tt.mr); got != tt.want {
+				t.Errorf("trainMemberState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecideTrain(t *testing.T) {
+	green := []trainMember{
+		{IssueID: "gt-api", Rig: "backend", State: trainMemberGreen},
+		{IssueID: "cl-ui", Rig: "client", State: trainMemberLanded},
+	}
+	d := decideTrain("gt-mr-1", "hq-cv-train", nil, green)
+	if !d.Land {
+		t.Errorf("all green: Land = false (%s)", d.Reason)
+	}
+	if trainLanded(green) {
+		t.Error("trainLanded() = true with a member still held")
+	}
+
+	waiting := append([]trainMember{{IssueID: "cl-auth", Rig: "client", State: trainMemberPending}}, green...)
+	d = decideTrain("gt-mr-1", "hq-cv-train", nil, waiting)
+	if d.Land || !strings.Contains(d.Reason, "cl-auth (pending)") {
+		t.Errorf("member pending: Land = %v, Reason = %q", d.Land, d.Reason)
+	}
+
+	d = decideTrain("gt-mr-1", "hq-cv-train", []convoyRef{{ID: "hq-cv-schema", Status: "open"}}, green)
+	if d.Land || !strings.Contains(d.Reason, "hq-cv-schema") {
+		t.Errorf("upstream open: Land = %v, Reason = %q", d.Land, d.Reason)
+	}
+}
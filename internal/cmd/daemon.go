@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/daemon"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -24,6 +25,8 @@ The daemon is a simple Go process that:
 - Pokes agents periodically (heartbeat)
 - Processes lifecycle requests (cycle, restart, shutdown)
 - Restarts sessions when agents request cycling
+- Optionally serves Prometheus metrics (mayor/daemon.json):
+    "metrics": {"enabled": true, "listen": "127.0.0.1:9464"}
 
 The daemon is a "dumb scheduler" - all intelligence is in agents.`,
 }
@@ -190,6 +193,14 @@ func runDaemonStatus(cmd *cobra.Command, args []string) error {
 					state.LastHeartbeat.Format("15:04:05"),
 					state.HeartbeatCount)
 			}
+			if cfg, err := config.LoadDaemonPatrolConfig(config.DaemonPatrolConfigPath(townRoot)); err == nil &&
+				cfg.Metrics != nil && cfg.Metrics.Enabled {
+				listen := cfg.Metrics.Listen
+				if listen == "" {
+					listen = config.DefaultMetricsListen
+				}
+				fmt.Printf("  Metrics: http://%s/metrics\n", listen)
+			}
 
 			// Check if binary is newer than process
 			if binaryModTime, err := getBinaryModTime(); err == nil {
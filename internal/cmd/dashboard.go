@@ -28,6 +28,7 @@ The dashboard shows real-time convoy status with:
 - Progress tracking for each convoy
 - Last activity indicator (green/yellow/red)
 - Auto-refresh every 30 seconds via htmx
+- Dependency graph of each convoy (/graph/<id>), also for molecule roots
 
 Example:
   gt dashboard              # Start on default port 8080
@@ -54,11 +55,24 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("creating convoy fetcher: %w", err)
 	}
 
-	// Create the handler
-	handler, err := web.NewConvoyHandler(fetcher)
+	// Create the handlers
+	convoyHandler, err := web.NewConvoyHandler(fetcher)
 	if err != nil {
 		return fmt.Errorf("creating convoy handler: %w", err)
 	}
+	traceHandler, err := web.NewTraceHandler(fetcher)
+	if err != nil {
+		return fmt.Errorf("creating trace handler: %w", err)
+	}
+	graphHandler, err := web.NewGraphHandler(fetcher)
+	if err != nil {
+		return fmt.Errorf("creating graph handler: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", convoyHandler)
+	mux.Handle("/trace/", traceHandler)
+	mux.Handle("/graph/", graphHandler)
 
 	// Build the URL
 	url := fmt.Sprintf("http://localhost:%d", dashboardPort)
@@ -74,7 +88,7 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", dashboardPort),
-		Handler:           handler,
+		Handler:           mux,
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       30 * time.Second,
 		WriteTimeout:      60 * time.Second,
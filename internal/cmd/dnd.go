@@ -3,9 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dnd"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -26,6 +30,18 @@ Subcommands:
 
 Without arguments, toggles DND mode.
 
+Quiet policies ("quiet_policies" in ~/gt/config/messaging.json) mute
+agents automatically, e.g. during quiet hours or while a molecule wait
+step is active. Held nudges and mail notifications arrive as a digest
+when the window ends; urgent ones always go through. Example:
+
+  "quiet_policies": {
+    "gastown/*":  [{"name": "night", "quiet_hours": {"start": "22:00", "end": "07:00"}}],
+    "*/witness":  [{"during_wait_step": true}]
+  }
+
+Status shows the policies that apply to you and how many nudges are held.
+
 Related: gt notify - for fine-grained notification level control`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runDnd,
@@ -121,9 +137,69 @@ func runDnd(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Notification level: %s\n", icon, style.Bold.Render(levelDisplay))
 		fmt.Printf("  %s\n", style.Dim.Render(description))
 
+		showQuietPolicies(townRoot, buildAgentIdentity(ctx))
+
 	default:
 		return fmt.Errorf("unknown action %q: use on, off, or status", action)
 	}
 
 	return nil
 }
+
+// showQuietPolicies prints the quiet policies for an agent, marking the
+// active one, and how many nudges are held for its digest.
+func showQuietPolicies(townRoot, address string) {
+	cfg, err := dnd.LoadConfig(townRoot)
+	if err != nil {
+		fmt.Printf("\n%s %v\n", style.WarningPrefix, err)
+		return
+	}
+	policies := dnd.PoliciesFor(cfg, address)
+	if len(policies) == 0 {
+		return
+	}
+
+	now := time.Now()
+	decision := dnd.Evaluate(cfg, townRoot, address, now)
+	fmt.Printf("\n%s\n", style.Bold.Render("Quiet policies:"))
+	for _, pm := range policies {
+		marker := style.Dim.Render("○")
+		if decision.Quiet && decision.Policy == pm.Label() {
+			marker = style.Bold.Render("●")
+		}
+		fmt.Printf("  %s %s %s\n", marker, pm.Label(), style.Dim.Render(describeQuietPolicy(pm.Policy)))
+	}
+	if decision.Quiet {
+		fmt.Printf("  %s\n", style.Dim.Render("Quiet now ("+decision.Reason+"); urgent notifications still go through"))
+	}
+
+	held, _ := dnd.ListHeld(townRoot)
+	count := 0
+	for _, n := range held {
+		if dnd.MatchAddress(n.Address, address) {
+			count++
+		}
+	}
+	if count > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("%d notification(s) held for the digest", count)))
+	}
+}
+
+// describeQuietPolicy renders a quiet policy's conditions for display.
+func describeQuietPolicy(p config.QuietPolicy) string {
+	var parts []string
+	if qh := p.QuietHours; qh != nil {
+		window := qh.Start + "-" + qh.End
+		if len(qh.Days) > 0 {
+			window += " " + strings.Join(qh.Days, ",")
+		}
+		if qh.Timezone != "" {
+			window += " " + qh.Timezone
+		}
+		parts = append(parts, window)
+	}
+	if p.DuringWaitStep {
+		parts = append(parts, "during wait steps")
+	}
+	return strings.Join(parts, ", ")
+}
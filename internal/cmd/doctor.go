@@ -80,6 +80,11 @@ Patrol checks:
   - patrol-plugins-accessible Verify plugin directories
   - patrol-roles-have-prompts Verify role prompts exist
 
+Plugin checks:
+  - plugin:<name>            Checks declared by [doctor] sections in
+                             <town>/plugins/*/plugin.md and <rig>/plugins/*/plugin.md
+                             (fixable when the plugin declares a fix command)
+
 Use --fix to attempt automatic fixes for issues that support it.
 Use --rig to check a specific rig instead of the entire workspace.`,
 	RunE: runDoctor,
@@ -185,6 +190,9 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		d.RegisterAll(doctor.RigChecks()...)
 	}
 
+	// Third-party checks declared by town and rig plugins
+	d.RegisterAll(doctor.PluginChecks(townRoot, doctorRig)...)
+
 	// Run checks
 	var report *doctor.Report
 	if doctorFix {
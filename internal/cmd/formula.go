@@ -11,7 +11,8 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dag"
+	"github.com/steveyegge/gastown/internal/formula"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 	"golang.org/x/text/cases"
@@ -22,10 +23,26 @@ import (
 var (
 	formulaListJSON   bool
 	formulaShowJSON   bool
+	formulaShowFormat string
 	formulaRunPR      int
 	formulaRunRig     string
 	formulaRunDryRun  bool
+	formulaRunVars    []string
 	formulaCreateType string
+
+	formulaInstallNames []string
+	formulaInstallRig   string
+	formulaInstallForce bool
+	formulaUpdateDryRun bool
+	formulaUpdateForce  bool
+	formulaUseRig       string
+	formulaUseUnpin     bool
+	formulaUseForce     bool
+
+	formulaTestFile    string
+	formulaTestVars    []string
+	formulaTestVerbose bool
+	formulaTestJSON    bool
 )
 
 var formulaCmd = &cobra.Command{
@@ -44,7 +61,12 @@ Commands:
   list    List available formulas from all search paths
   show    Display formula details (steps, variables, composition)
   run     Execute a formula (pour and dispatch)
+  feed    Dispatch a formula convoy's units as they unblock
+  test    Run a formula's tests against a simulated agent
   create  Create a new formula template
+  install Install formulas from a git repository or path
+  update  Update installed formulas to their sources' latest versions
+  use     Choose the version of an installed formula a town or rig uses
 
 Search paths (in order):
   1. .beads/formulas/ (project)
@@ -55,7 +77,9 @@ Examples:
   gt formula list                    # List all formulas
   gt formula show shiny              # Show formula details
   gt formula run shiny --pr=123      # Run formula on PR #123
-  gt formula create my-workflow      # Create new formula template`,
+  gt formula test patrol             # Run patrol.test.toml
+  gt formula create my-workflow      # Create new formula template
+  gt formula install https://github.com/acme/formulas.git@v2`,
 }
 
 var formulaListCmd = &cobra.Command{
@@ -81,13 +105,18 @@ var formulaShowCmd = &cobra.Command{
 
 Shows:
   - Formula metadata (name, type, description)
-  - Variables with defaults and constraints
+  - Variables with types, defaults and constraints, and a usage synopsis
   - Steps with dependencies
   - Composition rules (extends, aspects)
 
+With --format, renders the step dependency graph instead: ascii for the
+terminal, dot (Graphviz), mermaid, or svg.
+
 Examples:
   gt formula show shiny
-  gt formula show rule-of-five --json`,
+  gt formula show rule-of-five --json
+  gt formula show shiny --format ascii
+  gt formula show shiny --format dot | dot -Tpng > shiny.png`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFormulaShow,
 }
@@ -95,12 +124,27 @@ Examples:
 var formulaRunCmd = &cobra.Command{
 	Use:   "run [name]",
 	Short: "Execute a formula",
-	Long: `Execute a formula by pouring it and dispatching work.
+	Long: `Execute a formula by turning it into tracked work and dispatching it.
 
 This command:
   1. Looks up the formula by name (or uses default from rig config)
-  2. Pours it to create a molecule (or uses existing proto)
-  3. Dispatches the molecule to available workers
+  2. Parses and validates it, resolving --var values against its vars/inputs
+  3. Creates a convoy bead tracking the formula's work
+  4. Dispatches it to polecats
+
+How each formula type is executed:
+  workflow   Poured as one molecule, a step per formula step, and slung
+             to a single polecat, which works the steps in order
+             (gt mol step done evaluates their when/until conditions)
+  expansion  Templates instantiated once per value of their target
+             (e.g. --var target=gt-abc --var target=gt-def)
+  convoy     Parallel legs, then synthesis once the legs close
+  aspect     Parallel aspects, then synthesis if the formula defines one
+
+Expansion, convoy and aspect formulas get one bead per unit, with
+dependencies mirroring the formula. Units with no dependencies are slung
+now; the daemon slings the rest (gt formula feed) as their dependencies
+close.
 
 For PR-based workflows, use --pr to specify the GitHub PR number.
 
@@ -108,20 +152,130 @@ If no formula name is provided, uses the default formula configured in
 the rig's settings/config.json under workflow.default_formula.
 
 Options:
-  --pr=N      Run formula on GitHub PR #N
-  --rig=NAME  Target specific rig (default: current or gastown)
-  --dry-run   Show what would happen without executing
+  --pr=N         Run formula on GitHub PR #N (sets var pr)
+  --rig=NAME     Target specific rig (default: current or gastown)
+  --var=KEY=VAL  Set a variable or input (repeatable)
+  --dry-run      Show what would happen without executing
 
 Examples:
-  gt formula run shiny                    # Run formula in current rig
-  gt formula run                          # Run default formula from rig config
-  gt formula run shiny --pr=123           # Run on PR #123
-  gt formula run security-audit --rig=beads  # Run in specific rig
-  gt formula run release --dry-run        # Preview execution`,
+  gt formula run shiny --var feature=login   # Run workflow in current rig
+  gt formula run                             # Run default formula from rig config
+  gt formula run code-review --pr=123        # Run convoy on PR #123
+  gt formula run rule-of-five --var target=gt-abc
+  gt formula run design --rig=beads --var problem="rate limiting"
+  gt formula run release --dry-run           # Preview execution`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runFormulaRun,
 }
 
+var formulaTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Test a formula against a simulated agent",
+	Long: `Test a formula by running it with a simulated agent.
+
+The formula is planned as gt formula run would, then executed in memory:
+no beads are created and nothing is dispatched. The agent skips steps
+whose when condition is false, completes ready steps one at a time in
+formula order, repeats until steps, and records outputs.
+
+Test cases live next to the formula as <name>.test.toml (or --file).
+Each case sets vars, scripts the agent's outcomes per step (outputs,
+failures, waits), and asserts on the result:
+
+  [[case]]
+  name = "quick mode"
+  vars = { mode = "quick", rigs = "gastown, beads" }
+
+  [[case.steps.wait]]              # one entry per attempt
+  outputs = { activity = "no" }
+  [[case.steps.wait]]
+  outputs = { activity = "yes" }
+
+  [case.expect]
+  order = ["inbox", "scan.1", "scan.2", "wait", "wait"]
+  skipped = ["nudge"]
+  state = "complete"               # complete, failed or stuck
+  contains = { "scan.2" = "Scan beads" }
+  outputs = { "wait.activity" = "yes" }
+
+A case with error = "..." expects planning or the run to fail with that
+text. Without a test file, one smoke run uses --var values and defaults,
+filling declared outputs with placeholders.
+
+Exits 1 if any case fails, so formulas can be tested in CI.
+
+Examples:
+  gt formula test patrol
+  gt formula test patrol --verbose          # Show each agent action
+  gt formula test ./ci/release.formula.toml --file ./ci/release.test.toml
+  gt formula test design --var problem="rate limiting"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaTest,
+}
+
+var formulaInstallCmd = &cobra.Command{
+	Use:   "install <git-url|path>[@version]",
+	Short: "Install formulas from a git repository or path",
+	Long: `Install formulas from a git repository or local path into the town.
+
+Every *.formula.toml at the top of the source, in formulas/ or in
+.beads/formulas/ is installed (or only those named with --formula). For
+git sources, @version is a tag, branch or commit to check out; for paths
+it is the formula version the file must declare.
+
+Each formula's version field identifies what is installed. Versions are
+kept side by side in .beads/formulas/.registry/, and the lockfile
+.beads/formulas/formulas.lock.json records each version's source, commit
+and sha256 hash. Commit the lockfile to share exactly which formulas a
+town runs. Reinstalling a version with different content is refused:
+bump the formula's version instead.
+
+The installed version becomes the town's default. With --rig, the rig is
+pinned to it instead: the formula is written to the rig's
+.beads/formulas/, which bd and gt search first when working in the rig.
+
+Examples:
+  gt formula install https://github.com/acme/formulas.git@v2
+  gt formula install git@github.com:acme/formulas.git --formula mol-polecat-work
+  gt formula install ../shared/mol-polecat-work.formula.toml@3 --rig beads`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaInstall,
+}
+
+var formulaUpdateCmd = &cobra.Command{
+	Use:   "update [name...]",
+	Short: "Update installed formulas from their sources",
+	Long: `Update formulas installed with gt formula install.
+
+Each formula's source is fetched again (at the same git ref, so a branch
+moves and a tag stays put). A newer version is installed and becomes the
+town default; rigs pinned to a version stay pinned. A source that
+changed a formula without bumping its version is reported and skipped
+unless --force is given.
+
+Examples:
+  gt formula update                     # All installed formulas
+  gt formula update mol-polecat-work --dry-run`,
+	RunE: runFormulaUpdate,
+}
+
+var formulaUseCmd = &cobra.Command{
+	Use:   "use <name>[@version]",
+	Short: "Choose which installed version of a formula to use",
+	Long: `Choose which installed version of a formula the town or a rig uses.
+
+Without a version, lists the installed versions with their hashes and
+sources, the town default, and rig pins.
+
+Examples:
+  gt formula use mol-polecat-work              # Show installed versions
+  gt formula use mol-polecat-work@2            # Town default is v2
+  gt formula use mol-polecat-work@3 --rig beads
+  gt formula use mol-polecat-work --rig beads --unpin`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaUse,
+}
+
 var formulaCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new formula template",
@@ -149,11 +303,33 @@ func init() {
 
 	// Show flags
 	formulaShowCmd.Flags().BoolVar(&formulaShowJSON, "json", false, "Output as JSON")
+	formulaShowCmd.Flags().StringVar(&formulaShowFormat, "format", "", "Render the dependency graph: ascii, dot, mermaid, or svg")
 
 	// Run flags
 	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "GitHub PR number to run formula on")
 	formulaRunCmd.Flags().StringVar(&formulaRunRig, "rig", "", "Target rig (default: current or gastown)")
 	formulaRunCmd.Flags().BoolVar(&formulaRunDryRun, "dry-run", false, "Preview execution without running")
+	formulaRunCmd.Flags().StringArrayVar(&formulaRunVars, "var", nil, "Variable/input value (key=value, repeatable)")
+
+	// Install flags
+	formulaInstallCmd.Flags().StringArrayVar(&formulaInstallNames, "formula", nil, "Install only this formula from the source (repeatable)")
+	formulaInstallCmd.Flags().StringVar(&formulaInstallRig, "rig", "", "Pin this rig to the installed version instead of changing the town default")
+	formulaInstallCmd.Flags().BoolVar(&formulaInstallForce, "force", false, "Replace a version with different content, or a locally modified formula file")
+
+	// Update flags
+	formulaUpdateCmd.Flags().BoolVar(&formulaUpdateDryRun, "dry-run", false, "Show available updates without installing them")
+	formulaUpdateCmd.Flags().BoolVar(&formulaUpdateForce, "force", false, "Take changes made without a version bump, and replace locally modified files")
+
+	// Use flags
+	formulaUseCmd.Flags().StringVar(&formulaUseRig, "rig", "", "Pin this rig instead of changing the town default")
+	formulaUseCmd.Flags().BoolVar(&formulaUseUnpin, "unpin", false, "Return the rig to the town default")
+	formulaUseCmd.Flags().BoolVar(&formulaUseForce, "force", false, "Replace a locally modified formula file")
+
+	// Test flags
+	formulaTestCmd.Flags().StringVar(&formulaTestFile, "file", "", "Test file (default: <name>.test.toml beside the formula)")
+	formulaTestCmd.Flags().StringArrayVar(&formulaTestVars, "var", nil, "Variable/input value for the smoke run (key=value, repeatable)")
+	formulaTestCmd.Flags().BoolVarP(&formulaTestVerbose, "verbose", "v", false, "Show each action of the simulated agent")
+	formulaTestCmd.Flags().BoolVar(&formulaTestJSON, "json", false, "Output as JSON")
 
 	// Create flags
 	formulaCreateCmd.Flags().StringVar(&formulaCreateType, "type", "task", "Formula type: task, workflow, or patrol")
@@ -162,7 +338,11 @@ func init() {
 	formulaCmd.AddCommand(formulaListCmd)
 	formulaCmd.AddCommand(formulaShowCmd)
 	formulaCmd.AddCommand(formulaRunCmd)
+	formulaCmd.AddCommand(formulaTestCmd)
 	formulaCmd.AddCommand(formulaCreateCmd)
+	formulaCmd.AddCommand(formulaInstallCmd)
+	formulaCmd.AddCommand(formulaUpdateCmd)
+	formulaCmd.AddCommand(formulaUseCmd)
 
 	rootCmd.AddCommand(formulaCmd)
 }
@@ -180,9 +360,13 @@ func runFormulaList(cmd *cobra.Command, args []string) error {
 	return bdCmd.Run()
 }
 
-// runFormulaShow delegates to bd formula show
+// runFormulaShow delegates to bd formula show, then prints a usage
+// synopsis for local TOML formulas.
 func runFormulaShow(cmd *cobra.Command, args []string) error {
 	formulaName := args[0]
+	if formulaShowFormat != "" {
+		return showFormulaGraph(formulaName)
+	}
 	bdArgs := []string{"formula", "show", formulaName}
 	if formulaShowJSON {
 		bdArgs = append(bdArgs, "--json")
@@ -191,322 +375,98 @@ func runFormulaShow(cmd *cobra.Command, args []string) error {
 	bdCmd := exec.Command("bd", bdArgs...)
 	bdCmd.Stdout = os.Stdout
 	bdCmd.Stderr = os.Stderr
-	return bdCmd.Run()
-}
-
-// runFormulaRun executes a formula by spawning a convoy of polecats.
-// For convoy-type formulas, it creates a convoy bead, creates leg beads,
-// and slings each leg to a separate polecat with leg-specific prompts.
-func runFormulaRun(cmd *cobra.Command, args []string) error {
-	// Determine target rig first (needed for default formula lookup)
-	targetRig := formulaRunRig
-	var rigPath string
-	if targetRig == "" {
-		// Try to detect from current directory
-		townRoot, err := workspace.FindFromCwd()
-		if err == nil && townRoot != "" {
-			rigName, r, rigErr := findCurrentRig(townRoot)
-			if rigErr == nil && rigName != "" {
-				targetRig = rigName
-				if r != nil {
-					rigPath = r.Path
-				}
-			}
-			// If we still don't have a target rig but have townRoot, use gastown
-			if targetRig == "" {
-				targetRig = "gastown"
-				rigPath = filepath.Join(townRoot, "gastown")
-			}
-		} else {
-			// No town root found, fall back to gastown without rigPath
-			targetRig = "gastown"
-		}
-	} else {
-		// If rig specified, construct path
-		townRoot, err := workspace.FindFromCwd()
-		if err == nil && townRoot != "" {
-			rigPath = filepath.Join(townRoot, targetRig)
-		}
+	if err := bdCmd.Run(); err != nil {
+		return err
 	}
-
-	// Get formula name from args or default
-	var formulaName string
-	if len(args) > 0 {
-		formulaName = args[0]
-	} else {
-		// Try to get default formula from rig config
-		if rigPath != "" {
-			formulaName = config.GetDefaultFormula(rigPath)
-		}
-		if formulaName == "" {
-			return fmt.Errorf("no formula specified and no default formula configured\n\nTo set a default formula, add to your rig's settings/config.json:\n  \"workflow\": {\n    \"default_formula\": \"<formula-name>\"\n  }")
-		}
-		fmt.Printf("%s Using default formula: %s\n", style.Dim.Render("Note:"), formulaName)
-	}
-
-	// Find the formula file
-	formulaPath, err := findFormulaFile(formulaName)
-	if err != nil {
-		return fmt.Errorf("finding formula: %w", err)
+	if !formulaShowJSON {
+		showFormulaUsage(formulaName)
 	}
-
-	// Parse the formula
-	f, err := parseFormulaFile(formulaPath)
-	if err != nil {
-		return fmt.Errorf("parsing formula: %w", err)
-	}
-
-	// Handle dry-run mode
-	if formulaRunDryRun {
-		return dryRunFormula(f, formulaName, targetRig)
-	}
-
-	// Currently only convoy formulas are supported for execution
-	if f.Type != "convoy" {
-		fmt.Printf("%s Formula type '%s' not yet supported for execution.\n",
-			style.Dim.Render("Note:"), f.Type)
-		fmt.Printf("Currently only 'convoy' formulas can be run.\n")
-		fmt.Printf("\nTo run '%s' manually:\n", formulaName)
-		fmt.Printf("  1. View formula:   gt formula show %s\n", formulaName)
-		fmt.Printf("  2. Cook to proto:  bd cook %s\n", formulaName)
-		fmt.Printf("  3. Pour molecule:  bd pour %s\n", formulaName)
-		fmt.Printf("  4. Sling to rig:   gt sling <mol-id> %s\n", targetRig)
-		return nil
-	}
-
-	// Execute convoy formula
-	return executeConvoyFormula(f, formulaName, targetRig)
-}
-
-// dryRunFormula shows what would happen without executing
-func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
-	fmt.Printf("%s Would execute formula:\n", style.Dim.Render("[dry-run]"))
-	fmt.Printf("  Formula: %s\n", style.Bold.Render(formulaName))
-	fmt.Printf("  Type:    %s\n", f.Type)
-	fmt.Printf("  Rig:     %s\n", targetRig)
-	if formulaRunPR > 0 {
-		fmt.Printf("  PR:      #%d\n", formulaRunPR)
-	}
-
-	if f.Type == "convoy" && len(f.Legs) > 0 {
-		fmt.Printf("\n  Legs (%d parallel):\n", len(f.Legs))
-		for _, leg := range f.Legs {
-			fmt.Printf("    • %s: %s\n", leg.ID, leg.Title)
-		}
-		if f.Synthesis != nil {
-			fmt.Printf("\n  Synthesis:\n")
-			fmt.Printf("    • %s\n", f.Synthesis.Title)
-		}
-	}
-
 	return nil
 }
 
-// executeConvoyFormula spawns a convoy of polecats to execute a convoy formula
-func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
-	fmt.Printf("%s Executing convoy formula: %s\n\n",
-		style.Bold.Render("🚚"), formulaName)
-
-	// Get town beads directory for convoy creation
-	townRoot, err := workspace.FindFromCwd()
+// showFormulaGraph renders a local TOML formula's dependency graph.
+func showFormulaGraph(formulaName string) error {
+	path, err := findFormulaFile(formulaName)
 	if err != nil {
-		return fmt.Errorf("finding town root: %w", err)
+		return err
 	}
-	townBeads := filepath.Join(townRoot, ".beads")
-
-	// Step 1: Create convoy bead
-	convoyID := fmt.Sprintf("hq-cv-%s", generateFormulaShortID())
-	convoyTitle := fmt.Sprintf("%s: %s", formulaName, f.Description)
-	if len(convoyTitle) > 80 {
-		convoyTitle = convoyTitle[:77] + "..."
+	if !strings.HasSuffix(path, ".toml") {
+		return fmt.Errorf("--format needs a TOML formula, got %s", path)
 	}
-
-	// Build description with formula context
-	description := fmt.Sprintf("Formula convoy: %s\n\nLegs: %d\nRig: %s",
-		formulaName, len(f.Legs), targetRig)
-	if formulaRunPR > 0 {
-		description += fmt.Sprintf("\nPR: #%d", formulaRunPR)
+	f, err := formula.ParseFile(path)
+	if err != nil {
+		return err
 	}
-
-	createArgs := []string{
-		"create",
-		"--type=convoy",
-		"--id=" + convoyID,
-		"--title=" + convoyTitle,
-		"--description=" + description,
+	g, err := f.Graph()
+	if err != nil {
+		return err
 	}
-
-	createCmd := exec.Command("bd", createArgs...)
-	createCmd.Dir = townBeads
-	createCmd.Stderr = os.Stderr
-	if err := createCmd.Run(); err != nil {
-		return fmt.Errorf("creating convoy bead: %w", err)
+	out, err := dag.Render(g, formulaShowFormat)
+	if err != nil {
+		return err
 	}
+	fmt.Print(out)
+	return nil
+}
 
-	fmt.Printf("%s Created convoy: %s\n", style.Bold.Render("✓"), convoyID)
-
-	// Step 2: Create leg beads and track them
-	legBeads := make(map[string]string) // leg.ID -> bead ID
-	for _, leg := range f.Legs {
-		legBeadID := fmt.Sprintf("hq-leg-%s", generateFormulaShortID())
-
-		// Build leg description with prompt if available
-		legDesc := leg.Description
-		if f.Prompts != nil {
-			if basePrompt, ok := f.Prompts["base"]; ok {
-				legDesc = fmt.Sprintf("%s\n\n---\nBase Prompt:\n%s", leg.Description, basePrompt)
-			}
-		}
-
-		legArgs := []string{
-			"create",
-			"--type=task",
-			"--id=" + legBeadID,
-			"--title=" + leg.Title,
-			"--description=" + legDesc,
-		}
-
-		legCmd := exec.Command("bd", legArgs...)
-		legCmd.Dir = townBeads
-		legCmd.Stderr = os.Stderr
-		if err := legCmd.Run(); err != nil {
-			fmt.Printf("%s Failed to create leg bead for %s: %v\n",
-				style.Dim.Render("Warning:"), leg.ID, err)
-			continue
-		}
-
-		// Track the leg with the convoy
-		trackArgs := []string{"dep", "add", convoyID, legBeadID, "--type=tracks"}
-		trackCmd := exec.Command("bd", trackArgs...)
-		trackCmd.Dir = townBeads
-		if err := trackCmd.Run(); err != nil {
-			fmt.Printf("%s Failed to track leg %s: %v\n",
-				style.Dim.Render("Warning:"), leg.ID, err)
-		}
-
-		legBeads[leg.ID] = legBeadID
-		fmt.Printf("  %s Created leg: %s (%s)\n", style.Dim.Render("○"), leg.ID, legBeadID)
+// showFormulaUsage prints how to run a formula and its typed variables.
+func showFormulaUsage(formulaName string) {
+	path, err := findFormulaFile(formulaName)
+	if err != nil || !strings.HasSuffix(path, ".toml") {
+		return
 	}
-
-	// Step 3: Create synthesis bead if defined
-	var synthesisBeadID string
-	if f.Synthesis != nil {
-		synthesisBeadID = fmt.Sprintf("hq-syn-%s", generateFormulaShortID())
-
-		synDesc := f.Synthesis.Description
-		if synDesc == "" {
-			synDesc = "Synthesize findings from all legs into unified output"
+	f, err := formula.ParseFile(path)
+	if err != nil {
+		fmt.Printf("\n%s %v\n", style.WarningPrefix, err)
+		return
+	}
+
+	fmt.Printf("\n%s\n  %s\n", style.Bold.Render("Usage:"), f.Usage())
+	specs := f.VarSpecs()
+	if len(specs) == 0 {
+		return
+	}
+	fmt.Printf("\n%s\n", style.Bold.Render("Variables:"))
+	for _, s := range specs {
+		var notes []string
+		switch {
+		case s.Required:
+			notes = append(notes, "required")
+		case len(s.RequiredUnless) > 0:
+			notes = append(notes, "required unless "+strings.Join(s.RequiredUnless, "/"))
 		}
-
-		synArgs := []string{
-			"create",
-			"--type=task",
-			"--id=" + synthesisBeadID,
-			"--title=" + f.Synthesis.Title,
-			"--description=" + synDesc,
+		if s.Default != "" {
+			notes = append(notes, "default "+s.Default)
 		}
-
-		synCmd := exec.Command("bd", synArgs...)
-		synCmd.Dir = townBeads
-		synCmd.Stderr = os.Stderr
-		if err := synCmd.Run(); err != nil {
-			fmt.Printf("%s Failed to create synthesis bead: %v\n",
-				style.Dim.Render("Warning:"), err)
-		} else {
-			// Track synthesis with convoy
-			trackArgs := []string{"dep", "add", convoyID, synthesisBeadID, "--type=tracks"}
-			trackCmd := exec.Command("bd", trackArgs...)
-			trackCmd.Dir = townBeads
-			_ = trackCmd.Run()
-
-			// Add dependencies: synthesis depends on all legs
-			for _, legBeadID := range legBeads {
-				depArgs := []string{"dep", "add", synthesisBeadID, legBeadID}
-				depCmd := exec.Command("bd", depArgs...)
-				depCmd.Dir = townBeads
-				_ = depCmd.Run()
-			}
-
-			fmt.Printf("  %s Created synthesis: %s\n", style.Dim.Render("★"), synthesisBeadID)
+		if len(s.Choices) > 0 {
+			notes = append(notes, "one of "+strings.Join(s.Choices, ", "))
 		}
-	}
-
-	// Step 4: Sling each leg to a polecat
-	fmt.Printf("\n%s Dispatching legs to polecats...\n\n", style.Bold.Render("→"))
-
-	slingCount := 0
-	for _, leg := range f.Legs {
-		legBeadID, ok := legBeads[leg.ID]
-		if !ok {
-			continue
+		if s.Pattern != "" {
+			notes = append(notes, "matches /"+s.Pattern+"/")
 		}
-
-		// Build context message for the polecat
-		contextMsg := fmt.Sprintf("Convoy leg: %s\nFocus: %s", leg.Title, leg.Focus)
-
-		// Use gt sling with args for leg-specific context
-		slingArgs := []string{
-			"sling", legBeadID, targetRig,
-			"-a", leg.Description,
-			"-s", leg.Title,
+		line := fmt.Sprintf("  %-16s %s", s.Name, s.Type)
+		if len(notes) > 0 {
+			line = fmt.Sprintf("  %-16s %-9s %s", s.Name, s.Type, style.Dim.Render("("+strings.Join(notes, "; ")+")"))
 		}
-
-		slingCmd := exec.Command("gt", slingArgs...)
-		slingCmd.Stdout = os.Stdout
-		slingCmd.Stderr = os.Stderr
-
-		if err := slingCmd.Run(); err != nil {
-			fmt.Printf("%s Failed to sling leg %s: %v\n",
-				style.Dim.Render("Warning:"), leg.ID, err)
-			// Add comment to bead about failure
-			commentArgs := []string{"comment", legBeadID, fmt.Sprintf("Failed to sling: %v", err)}
-			commentCmd := exec.Command("bd", commentArgs...)
-			commentCmd.Dir = townBeads
-			_ = commentCmd.Run()
-			continue
+		fmt.Println(line)
+		if s.Description != "" {
+			fmt.Printf("  %-16s %s\n", "", s.Description)
 		}
-
-		slingCount++
-		_ = contextMsg // Used in future for richer context
 	}
-
-	// Summary
-	fmt.Printf("\n%s Convoy dispatched!\n", style.Bold.Render("✓"))
-	fmt.Printf("  Convoy:  %s\n", convoyID)
-	fmt.Printf("  Legs:    %d dispatched\n", slingCount)
-	if synthesisBeadID != "" {
-		fmt.Printf("  Synthesis: %s (blocked until legs complete)\n", synthesisBeadID)
-	}
-	fmt.Printf("\n  Track progress: gt convoy status %s\n", convoyID)
-
-	return nil
-}
-
-// formulaData holds parsed formula information
-type formulaData struct {
-	Name        string
-	Description string
-	Type        string
-	Legs        []formulaLeg
-	Synthesis   *formulaSynthesis
-	Prompts     map[string]string
-}
-
-type formulaLeg struct {
-	ID          string
-	Title       string
-	Focus       string
-	Description string
-}
-
-type formulaSynthesis struct {
-	Title       string
-	Description string
-	DependsOn   []string
 }
 
 // findFormulaFile searches for a formula file by name
 func findFormulaFile(name string) (string, error) {
+	// name@<version> selects an installed version from the town registry
+	if strings.Contains(name, "@") {
+		if townRoot, err := workspace.FindFromCwd(); err == nil {
+			if path, ok := formula.FindVersion(filepath.Join(townRoot, ".beads", "formulas"), name); ok {
+				return path, nil
+			}
+		}
+		return "", fmt.Errorf("formula version '%s' is not installed (see gt formula use)", name)
+	}
+
 	// Search paths in order
 	searchPaths := []string{}
 
@@ -539,180 +499,6 @@ func findFormulaFile(name string) (string, error) {
 	return "", fmt.Errorf("formula '%s' not found in search paths", name)
 }
 
-// parseFormulaFile parses a formula file into formulaData
-func parseFormulaFile(path string) (*formulaData, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	// Use simple TOML parsing for the fields we need
-	// (avoids importing the full formula package which might cause cycles)
-	f := &formulaData{
-		Prompts: make(map[string]string),
-	}
-
-	content := string(data)
-
-	// Parse formula name
-	if match := extractTOMLValue(content, "formula"); match != "" {
-		f.Name = match
-	}
-
-	// Parse description
-	if match := extractTOMLMultiline(content, "description"); match != "" {
-		f.Description = match
-	}
-
-	// Parse type
-	if match := extractTOMLValue(content, "type"); match != "" {
-		f.Type = match
-	}
-
-	// Parse legs (convoy formulas)
-	f.Legs = extractLegs(content)
-
-	// Parse synthesis
-	f.Synthesis = extractSynthesis(content)
-
-	// Parse prompts
-	f.Prompts = extractPrompts(content)
-
-	return f, nil
-}
-
-// extractTOMLValue extracts a simple quoted value from TOML
-func extractTOMLValue(content, key string) string {
-	// Match: key = "value" or key = 'value'
-	for _, line := range strings.Split(content, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, key+" =") || strings.HasPrefix(line, key+"=") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				val := strings.TrimSpace(parts[1])
-				// Remove quotes
-				if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') {
-					return val[1 : len(val)-1]
-				}
-				return val
-			}
-		}
-	}
-	return ""
-}
-
-// extractTOMLMultiline extracts a multiline string (""" ... """)
-func extractTOMLMultiline(content, key string) string {
-	// Look for key = """
-	keyPattern := key + ` = """`
-	idx := strings.Index(content, keyPattern)
-	if idx == -1 {
-		// Try single-line
-		return extractTOMLValue(content, key)
-	}
-
-	start := idx + len(keyPattern)
-	end := strings.Index(content[start:], `"""`)
-	if end == -1 {
-		return ""
-	}
-
-	return strings.TrimSpace(content[start : start+end])
-}
-
-// extractLegs parses [[legs]] sections from TOML
-func extractLegs(content string) []formulaLeg {
-	var legs []formulaLeg
-
-	// Split by [[legs]]
-	sections := strings.Split(content, "[[legs]]")
-	for i, section := range sections {
-		if i == 0 {
-			continue // Skip content before first [[legs]]
-		}
-
-		// Find where this section ends (next [[ or EOF)
-		endIdx := strings.Index(section, "[[")
-		if endIdx == -1 {
-			endIdx = len(section)
-		}
-		section = section[:endIdx]
-
-		leg := formulaLeg{
-			ID:          extractTOMLValue(section, "id"),
-			Title:       extractTOMLValue(section, "title"),
-			Focus:       extractTOMLValue(section, "focus"),
-			Description: extractTOMLMultiline(section, "description"),
-		}
-
-		if leg.ID != "" {
-			legs = append(legs, leg)
-		}
-	}
-
-	return legs
-}
-
-// extractSynthesis parses [synthesis] section from TOML
-func extractSynthesis(content string) *formulaSynthesis {
-	idx := strings.Index(content, "[synthesis]")
-	if idx == -1 {
-		return nil
-	}
-
-	section := content[idx:]
-	// Find where section ends
-	if endIdx := strings.Index(section[1:], "\n["); endIdx != -1 {
-		section = section[:endIdx+1]
-	}
-
-	syn := &formulaSynthesis{
-		Title:       extractTOMLValue(section, "title"),
-		Description: extractTOMLMultiline(section, "description"),
-	}
-
-	// Parse depends_on array
-	if depsLine := extractTOMLValue(section, "depends_on"); depsLine != "" {
-		// Simple array parsing: ["a", "b", "c"]
-		depsLine = strings.Trim(depsLine, "[]")
-		for _, dep := range strings.Split(depsLine, ",") {
-			dep = strings.Trim(strings.TrimSpace(dep), `"'`)
-			if dep != "" {
-				syn.DependsOn = append(syn.DependsOn, dep)
-			}
-		}
-	}
-
-	if syn.Title == "" && syn.Description == "" {
-		return nil
-	}
-
-	return syn
-}
-
-// extractPrompts parses [prompts] section from TOML
-func extractPrompts(content string) map[string]string {
-	prompts := make(map[string]string)
-
-	idx := strings.Index(content, "[prompts]")
-	if idx == -1 {
-		return prompts
-	}
-
-	section := content[idx:]
-	// Find where section ends
-	if endIdx := strings.Index(section[1:], "\n["); endIdx != -1 {
-		section = section[:endIdx+1]
-	}
-
-	// Extract base prompt
-	if base := extractTOMLMultiline(section, "base"); base != "" {
-		prompts["base"] = base
-	}
-
-	return prompts
-}
-
 // generateFormulaShortID generates a short random ID (5 lowercase chars)
 func generateFormulaShortID() string {
 	b := make([]byte, 3)
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var formulaFeedCmd = &cobra.Command{
+	Use:   "feed <convoy-id>",
+	Short: "Dispatch formula units whose dependencies have closed",
+	Long: `Sling the units of a formula convoy that have become ready.
+
+gt formula run slings the units with no dependencies. As units close, the
+daemon's convoy watcher runs this command, which slings every tracked unit
+that is open, unassigned, and has all its dependencies closed to the
+convoy's rig. Run it by hand to catch up when the daemon is down.
+
+Convoys not created by gt formula run, and workflow convoys (whose steps
+are worked as one molecule), have nothing to feed.
+
+Examples:
+  gt formula feed hq-cv-abc`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaFeed,
+}
+
+func init() {
+	formulaCmd.AddCommand(formulaFeedCmd)
+}
+
+// formulaUnitBead is a unit bead tracked by a formula convoy.
+type formulaUnitBead struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Assignee    string   `json:"assignee"`
+	Needs       []string `json:"-"` // beads it depends on
+}
+
+func runFormulaFeed(cmd *cobra.Command, args []string) error {
+	convoyID := args[0]
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	townBeads := filepath.Join(townRoot, ".beads")
+
+	convoy, err := beads.New(townBeads).Show(convoyID)
+	if err != nil {
+		return fmt.Errorf("convoy '%s' not found", convoyID)
+	}
+	if convoy.Type != "convoy" {
+		return fmt.Errorf("'%s' is not a convoy (type: %s)", convoyID, convoy.Type)
+	}
+	targetRig := convoyField(convoy.Description, "Rig")
+	if convoyField(convoy.Description, "Formula") == "" || targetRig == "" {
+		fmt.Printf("%s %s is not a formula convoy\n", style.Dim.Render("○"), convoyID)
+		return nil
+	}
+	if convoy.Status == "closed" {
+		fmt.Printf("%s %s is closed\n", style.Dim.Render("○"), convoyID)
+		return nil
+	}
+
+	units, statuses, err := getFormulaUnits(townBeads, convoyID)
+	if err != nil {
+		return err
+	}
+
+	ready := readyFormulaUnits(units, statuses)
+	if len(ready) == 0 {
+		fmt.Printf("%s No units ready in %s\n", style.Dim.Render("○"), convoyID)
+		return nil
+	}
+
+	slung := 0
+	for _, u := range ready {
+		if slingFormulaUnit(townBeads, u.ID, u.Title, u.Description, targetRig) {
+			slung++
+		}
+	}
+	fmt.Printf("%s Fed %d/%d ready unit(s) of %s to %s\n", style.Bold.Render("✓"), slung, len(ready), convoyID, targetRig)
+	return nil
+}
+
+// convoyField returns the value of a "Key: value" line in a convoy
+// description, or "".
+func convoyField(description, key string) string {
+	for _, line := range strings.Split(description, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), key+":"); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// getFormulaUnits returns the unit beads a convoy tracks, with their
+// dependencies, and the status of every bead they depend on.
+func getFormulaUnits(townBeads, convoyID string) ([]formulaUnitBead, map[string]string, error) {
+	dbPath := filepath.Join(townBeads, "beads.db")
+	safeConvoyID := strings.ReplaceAll(convoyID, "'", "''")
+
+	var units []formulaUnitBead
+	if err := querySQLiteJSON(dbPath, fmt.Sprintf(`
+		SELECT i.id, i.title, i.description, i.status, COALESCE(i.assignee, '') AS assignee
+		FROM dependencies t
+		JOIN issues i ON i.id = t.depends_on_id
+		WHERE t.issue_id = '%s' AND t.type = 'tracks'`, safeConvoyID), &units); err != nil {
+		return nil, nil, fmt.Errorf("listing units of %s: %w", convoyID, err)
+	}
+
+	var deps []struct {
+		IssueID     string `json:"issue_id"`
+		DependsOnID string `json:"depends_on_id"`
+		Status      string `json:"status"`
+	}
+	if err := querySQLiteJSON(dbPath, fmt.Sprintf(`
+		SELECT d.issue_id, d.depends_on_id, i.status
+		FROM dependencies d
+		JOIN dependencies t ON t.depends_on_id = d.issue_id
+		JOIN issues i ON i.id = d.depends_on_id
+		WHERE t.issue_id = '%s' AND t.type = 'tracks' AND d.type = 'blocks'`, safeConvoyID), &deps); err != nil {
+		return nil, nil, fmt.Errorf("listing unit dependencies of %s: %w", convoyID, err)
+	}
+
+	statuses := make(map[string]string)
+	needs := make(map[string][]string)
+	for _, d := range deps {
+		statuses[d.DependsOnID] = d.Status
+		needs[d.IssueID] = append(needs[d.IssueID], d.DependsOnID)
+	}
+	for i := range units {
+		units[i].Needs = needs[units[i].ID]
+		statuses[units[i].ID] = units[i].Status
+	}
+	return units, statuses, nil
+}
+
+// querySQLiteJSON runs a query with sqlite3 -json and decodes its rows.
+// An empty result leaves v untouched.
+func querySQLiteJSON(dbPath, query string, v any) error {
+	queryCmd := exec.Command("sqlite3", "-json", dbPath, query)
+	var stdout, stderr bytes.Buffer
+	queryCmd.Stdout = &stdout
+	queryCmd.Stderr = &stderr
+	if err := queryCmd.Run(); err != nil {
+		return fmt.Errorf("%w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil
+	}
+	return json.Unmarshal(stdout.Bytes(), v)
+}
+
+// readyFormulaUnits returns the units that can be dispatched now: open,
+// unassigned, and with every bead they depend on closed.
+func readyFormulaUnits(units []formulaUnitBead, statuses map[string]string) []formulaUnitBead {
+	var ready []formulaUnitBead
+	for _, u := range units {
+		if u.Status != "open" || u.Assignee != "" {
+			continue
+		}
+		unblocked := true
+		for _, need := range u.Needs {
+			if statuses[need] != "closed" {
+				unblocked = false
+				break
+			}
+		}
+		if unblocked {
+			ready = append(ready, u)
+		}
+	}
+	return ready
+}
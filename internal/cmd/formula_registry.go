@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// openTownRegistry opens the formula registry in the town's .beads/formulas.
+func openTownRegistry() (*formula.Registry, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	return formula.OpenRegistry(filepath.Join(townRoot, ".beads", "formulas"))
+}
+
+// rigFormulasDir returns the formulas directory bd and gt search first
+// when working in a rig.
+func rigFormulasDir(rigPath string) string {
+	return filepath.Join(beads.ResolveBeadsDir(rigPath), "formulas")
+}
+
+// formulaTargetDir returns where to activate a formula version: the town's
+// formulas directory, or the rig's when --rig is set.
+func formulaTargetDir(reg *formula.Registry, rigName string) (string, error) {
+	if rigName == "" {
+		return reg.Dir, nil
+	}
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return "", err
+	}
+	return rigFormulasDir(r.Path), nil
+}
+
+// runFormulaInstall installs formulas from a git repository or path into
+// the town registry, and makes them the town's (or a rig's) version.
+func runFormulaInstall(cmd *cobra.Command, args []string) error {
+	reg, err := openTownRegistry()
+	if err != nil {
+		return err
+	}
+	dir, err := formulaTargetDir(reg, formulaInstallRig)
+	if err != nil {
+		return err
+	}
+
+	src := formula.ParseSource(args[0])
+	fmt.Printf("Fetching %s...\n", src)
+	fetched, commit, err := formula.FetchSource(src)
+	if err != nil {
+		return err
+	}
+	if len(formulaInstallNames) > 0 {
+		var selected []formula.FetchedFormula
+		for _, name := range formulaInstallNames {
+			ff := findFetched(fetched, name)
+			if ff == nil {
+				return fmt.Errorf("%s has no formula %s", src.Location, name)
+			}
+			selected = append(selected, *ff)
+		}
+		fetched = selected
+	}
+
+	for _, ff := range fetched {
+		added, err := reg.Add(ff, src, commit, formulaInstallForce)
+		if err != nil {
+			return err
+		}
+		if err := reg.Activate(ff.Name, ff.Version, formulaInstallRig, dir, formulaInstallForce); err != nil {
+			return err
+		}
+		note := "installed"
+		if !added {
+			note = "already installed"
+		}
+		where := "town default"
+		if formulaInstallRig != "" {
+			where = "pinned for rig " + formulaInstallRig
+		}
+		fmt.Printf("%s %s v%d %s (%s)\n", style.SuccessPrefix, style.Bold.Render(ff.Name), ff.Version, note, where)
+	}
+	if commit != "" {
+		fmt.Printf("  %s\n", style.Dim.Render("commit "+shortCommit(commit)))
+	}
+	return reg.Save()
+}
+
+// runFormulaUpdate re-fetches the sources of locked formulas and installs
+// newer versions as the town default. Rig pins are left alone.
+func runFormulaUpdate(cmd *cobra.Command, args []string) error {
+	reg, err := openTownRegistry()
+	if err != nil {
+		return err
+	}
+	names := args
+	if len(names) == 0 {
+		names = reg.Names()
+	}
+	if len(names) == 0 {
+		fmt.Println("No formulas installed from a registry source (see gt formula install).")
+		return nil
+	}
+
+	type fetchResult struct {
+		formulas []formula.FetchedFormula
+		commit   string
+		err      error
+	}
+	cache := make(map[string]*fetchResult) // by source, so each is fetched once
+	changed := false
+	for _, name := range names {
+		entry := reg.Lock.Formulas[name]
+		if entry == nil {
+			return fmt.Errorf("%s is not in the lockfile", name)
+		}
+		current := entry.Versions[entry.Default]
+		src := formula.Source{Location: current.Source, Ref: current.Ref, Git: formula.ParseSource(current.Source).Git}
+		res := cache[src.String()]
+		if res == nil {
+			res = &fetchResult{}
+			res.formulas, res.commit, res.err = formula.FetchSource(src)
+			cache[src.String()] = res
+		}
+		if res.err != nil {
+			fmt.Printf("%s %s: %v\n", style.ErrorPrefix, name, res.err)
+			continue
+		}
+		ff := findFetched(res.formulas, name)
+		switch {
+		case ff == nil:
+			fmt.Printf("%s %s: no longer in %s\n", style.WarningPrefix, name, src)
+			continue
+		case ff.SHA256 == current.SHA256:
+			fmt.Printf("  %s v%d up to date\n", name, entry.Default)
+			continue
+		case ff.Version < entry.Default:
+			fmt.Printf("%s %s: source is at v%d, older than v%d; skipped\n", style.WarningPrefix, name, ff.Version, entry.Default)
+			continue
+		case ff.Version == entry.Default && !formulaUpdateForce:
+			fmt.Printf("%s %s v%d changed in %s without a version bump; skipped (use --force to take it)\n",
+				style.WarningPrefix, name, ff.Version, src)
+			continue
+		}
+
+		if formulaUpdateDryRun {
+			fmt.Printf("  %s v%d → v%d %s\n", name, entry.Default, ff.Version, style.Dim.Render("(dry run)"))
+			continue
+		}
+		from := entry.Default
+		if _, err := reg.Add(*ff, src, res.commit, formulaUpdateForce); err != nil {
+			return err
+		}
+		if err := reg.Activate(name, ff.Version, "", reg.Dir, formulaUpdateForce); err != nil {
+			return err
+		}
+		changed = true
+		fmt.Printf("%s %s v%d → v%d\n", style.SuccessPrefix, style.Bold.Render(name), from, ff.Version)
+		for _, rig := range sortedRigPins(entry) {
+			fmt.Printf("    %s\n", style.Dim.Render(fmt.Sprintf("rig %s stays pinned to v%d", rig, entry.Rigs[rig])))
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return reg.Save()
+}
+
+// runFormulaUse chooses which installed version of a formula the town or
+// a rig uses, or shows the installed versions.
+func runFormulaUse(cmd *cobra.Command, args []string) error {
+	reg, err := openTownRegistry()
+	if err != nil {
+		return err
+	}
+	name, version := args[0], ""
+	if i := strings.LastIndex(name, "@"); i > 0 {
+		name, version = name[:i], name[i+1:]
+	}
+	entry := reg.Lock.Formulas[name]
+	if entry == nil {
+		return fmt.Errorf("%s is not installed from a registry source (see gt formula install)", name)
+	}
+	dir, err := formulaTargetDir(reg, formulaUseRig)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case formulaUseUnpin:
+		if formulaUseRig == "" {
+			return fmt.Errorf("--unpin requires --rig")
+		}
+		if err := reg.Unpin(name, formulaUseRig, dir, formulaUseForce); err != nil {
+			return err
+		}
+		fmt.Printf("%s rig %s now uses the town's %s v%d\n", style.SuccessPrefix, formulaUseRig, name, entry.Default)
+		return reg.Save()
+	case version != "":
+		v, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+		if err != nil {
+			return fmt.Errorf("invalid version %q", version)
+		}
+		if err := reg.Activate(name, v, formulaUseRig, dir, formulaUseForce); err != nil {
+			return err
+		}
+		where := "town"
+		if formulaUseRig != "" {
+			where = "rig " + formulaUseRig
+		}
+		fmt.Printf("%s %s now uses %s v%d\n", style.SuccessPrefix, where, name, v)
+		return reg.Save()
+	}
+
+	fmt.Printf("%s\n", style.Bold.Render(name))
+	for _, v := range reg.Versions(name) {
+		lv := entry.Versions[v]
+		var marks []string
+		if v == entry.Default {
+			marks = append(marks, "town default")
+		}
+		for _, rig := range sortedRigPins(entry) {
+			if entry.Rigs[rig] == v {
+				marks = append(marks, "rig "+rig)
+			}
+		}
+		from := lv.Source
+		if lv.Ref != "" {
+			from += "@" + lv.Ref
+		}
+		if lv.Commit != "" {
+			from += " " + shortCommit(lv.Commit)
+		}
+		line := fmt.Sprintf("  v%-3d %s  %s", v, lv.SHA256[:12], from)
+		if len(marks) > 0 {
+			line += "  " + style.Bold.Render("← "+strings.Join(marks, ", "))
+		}
+		fmt.Println(line)
+	}
+	if status := reg.Status(name, "", reg.Dir); status != "ok" {
+		fmt.Printf("  %s town copy is %s; reinstall with gt formula use %s@%d --force\n", style.WarningPrefix, status, name, entry.Default)
+	}
+	return nil
+}
+
+// findRigFormulaFile returns a formula pinned in a rig's formulas directory.
+func findRigFormulaFile(name, rigPath string) (string, bool) {
+	if rigPath == "" {
+		return "", false
+	}
+	path := filepath.Join(rigFormulasDir(rigPath), name+".formula.toml")
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+func findFetched(fetched []formula.FetchedFormula, name string) *formula.FetchedFormula {
+	for i := range fetched {
+		if fetched[i].Name == name {
+			return &fetched[i]
+		}
+	}
+	return nil
+}
+
+func sortedRigPins(entry *formula.LockEntry) []string {
+	rigs := make([]string, 0, len(entry.Rigs))
+	for rig := range entry.Rigs {
+		rigs = append(rigs, rig)
+	}
+	sort.Strings(rigs)
+	return rigs
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
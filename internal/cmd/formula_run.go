@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// runFormulaRun executes a formula: it plans the formula's units of work,
+// creates a convoy tracking them, and dispatches what is ready.
+func runFormulaRun(cmd *cobra.Command, args []string) error {
+	// Determine target rig first (needed for default formula lookup)
+	targetRig := formulaRunRig
+	var rigPath string
+	if targetRig == "" {
+		// Try to detect from current directory
+		townRoot, err := workspace.FindFromCwd()
+		if err == nil && townRoot != "" {
+			rigName, r, rigErr := findCurrentRig(townRoot)
+			if rigErr == nil && rigName != "" {
+				targetRig = rigName
+				if r != nil {
+					rigPath = r.Path
+				}
+			}
+			// If we still don't have a target rig but have townRoot, use gastown
+			if targetRig == "" {
+				targetRig = "gastown"
+				rigPath = filepath.Join(townRoot, "gastown")
+			}
+		} else {
+			// No town root found, fall back to gastown without rigPath
+			targetRig = "gastown"
+		}
+	} else {
+		// If rig specified, construct path
+		townRoot, err := workspace.FindFromCwd()
+		if err == nil && townRoot != "" {
+			rigPath = filepath.Join(townRoot, targetRig)
+		}
+	}
+
+	// Get formula name from args or default
+	var formulaName string
+	if len(args) > 0 {
+		formulaName = args[0]
+	} else {
+		// Try to get default formula from rig config
+		if rigPath != "" {
+			formulaName = config.GetDefaultFormula(rigPath)
+		}
+		if formulaName == "" {
+			return fmt.Errorf("no formula specified and no default formula configured\n\nTo set a default formula, add to your rig's settings/config.json:\n  \"workflow\": {\n    \"default_formula\": \"<formula-name>\"\n  }")
+		}
+		fmt.Printf("%s Using default formula: %s\n", style.Dim.Render("Note:"), formulaName)
+	}
+
+	// Find and parse the formula, preferring a version pinned for the rig
+	formulaPath, pinned := findRigFormulaFile(formulaName, rigPath)
+	if !pinned {
+		var err error
+		formulaPath, err = findFormulaFile(formulaName)
+		if err != nil {
+			return fmt.Errorf("finding formula: %w", err)
+		}
+	}
+	if strings.HasSuffix(formulaPath, ".json") {
+		return fmt.Errorf("%s: only TOML formulas can be run (use bd cook / bd mol pour for JSON formulas)", formulaPath)
+	}
+	f, err := formula.ParseFile(formulaPath)
+	if err != nil {
+		return fmt.Errorf("parsing formula: %w", err)
+	}
+
+	vars, err := parseFormulaVars(formulaRunVars)
+	if err != nil {
+		return err
+	}
+	if formulaRunPR > 0 && len(vars["pr"]) == 0 && f.VarSpec("pr") != nil {
+		vars["pr"] = []string{strconv.Itoa(formulaRunPR)}
+	}
+
+	plan, err := f.Plan(formula.PlanOptions{
+		Vars:     vars,
+		Check:    checkFormulaVarValue,
+		Describe: describeFormulaTarget,
+	})
+	if err != nil {
+		return fmt.Errorf("planning formula %s: %w\n\nUsage: %s", formulaName, err, f.Usage())
+	}
+
+	// Handle dry-run mode
+	if formulaRunDryRun {
+		return dryRunFormula(f, plan, formulaName, targetRig)
+	}
+
+	return executeFormulaPlan(f, plan, formulaName, formulaPath, targetRig)
+}
+
+// parseFormulaVars parses repeated key=value flags. A key may repeat.
+func parseFormulaVars(pairs []string) (map[string][]string, error) {
+	vars := make(map[string][]string)
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected key=value", pair)
+		}
+		vars[key] = append(vars[key], value)
+	}
+	return vars, nil
+}
+
+// checkFormulaVarValue verifies that bead-id and rig variables name a
+// bead or rig that exists.
+func checkFormulaVarValue(spec formula.VarSpec, value string) error {
+	switch spec.Type {
+	case formula.VarBeadID:
+		if err := verifyBeadExists(value); err != nil {
+			return fmt.Errorf("%s: %w", spec.Name, err)
+		}
+	case formula.VarRig:
+		if _, ok := IsRigName(value); !ok {
+			return fmt.Errorf("%s: rig '%s' not found", spec.Name, value)
+		}
+	}
+	return nil
+}
+
+// validateSlingFormulaVars checks --var values against a formula's
+// declarations before anything is cooked. Formulas that aren't local TOML
+// files are left to bd.
+func validateSlingFormulaVars(formulaName string, pairs []string) error {
+	path, err := findFormulaFile(formulaName)
+	if err != nil {
+		path, err = findFormulaFile("mol-" + formulaName)
+	}
+	if err != nil || !strings.HasSuffix(path, ".toml") {
+		return nil
+	}
+	f, err := formula.ParseFile(path)
+	if err != nil {
+		return fmt.Errorf("parsing formula %s: %w", formulaName, err)
+	}
+	vars, err := parseFormulaVars(pairs)
+	if err != nil {
+		return err
+	}
+	if _, err := f.ResolveVars(vars, checkFormulaVarValue); err != nil {
+		return fmt.Errorf("formula %s: %w\n\nUsage: %s", formulaName, err, f.Usage())
+	}
+	return nil
+}
+
+// describeFormulaTarget looks up an expansion target bead's title and
+// description. Values that aren't beads fall back to the value itself.
+func describeFormulaTarget(value string) (string, string) {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return "", ""
+	}
+	issue, err := beads.New(townRoot).Show(value)
+	if err != nil {
+		return "", ""
+	}
+	return issue.Title, issue.Description
+}
+
+// dryRunFormula shows what would happen without executing
+func dryRunFormula(f *formula.Formula, plan *formula.Plan, formulaName, targetRig string) error {
+	fmt.Printf("%s Would execute formula:\n", style.Dim.Render("[dry-run]"))
+	fmt.Printf("  Formula: %s\n", style.Bold.Render(formulaName))
+	fmt.Printf("  Type:    %s\n", f.Type)
+	fmt.Printf("  Rig:     %s\n", targetRig)
+	if formulaRunPR > 0 {
+		fmt.Printf("  PR:      #%d\n", formulaRunPR)
+	}
+	if len(plan.Vars) > 0 {
+		fmt.Printf("  Vars:\n")
+		for _, k := range sortedKeys(plan.Vars) {
+			fmt.Printf("    %s = %s\n", k, plan.Vars[k])
+		}
+	}
+
+	fmt.Printf("\n  Units (%d):\n", len(plan.Units))
+	for i, wave := range plan.Waves() {
+		label := "dispatched now"
+		if i > 0 {
+			label = "after dependencies close"
+		}
+		fmt.Printf("    Wave %d (%s):\n", i+1, label)
+		for _, id := range wave {
+			u := plan.Unit(id)
+			line := fmt.Sprintf("      • %s: %s", u.ID, u.Title)
+			if len(u.Needs) > 0 {
+				line += style.Dim.Render(fmt.Sprintf("  (needs %s)", strings.Join(u.Needs, ", ")))
+			}
+			if u.When != "" {
+				line += style.Dim.Render("  when " + u.When)
+			}
+			if u.Until != "" {
+				line += style.Dim.Render(fmt.Sprintf("  until %s (max %d)", u.Until, u.MaxIterations))
+			}
+			if u.Tier != "" {
+				line += style.Dim.Render("  tier " + u.Tier)
+			}
+			if u.Timeout != "" {
+				line += style.Dim.Render("  timeout " + u.Timeout)
+			}
+			if u.Retries > 0 || u.OnFailure != "" {
+				line += style.Dim.Render(fmt.Sprintf("  retries %d, on failure %s", u.Retries, cmp.Or(u.OnFailure, "escalate")))
+			}
+			fmt.Println(line)
+		}
+	}
+
+	return nil
+}
+
+// executeFormulaPlan creates a convoy for the plan and dispatches it. A
+// workflow is poured as one molecule and slung to a single polecat; other
+// formulas get one bead per unit, with the ready units slung now and the
+// rest fed by gt formula feed as their dependencies close.
+func executeFormulaPlan(f *formula.Formula, plan *formula.Plan, formulaName, formulaPath, targetRig string) error {
+	fmt.Printf("%s Executing %s formula: %s\n\n",
+		style.Bold.Render("🚚"), f.Type, formulaName)
+
+	// Get town beads directory for convoy creation
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return fmt.Errorf("finding town root: %w", err)
+	}
+	townBeads := filepath.Join(townRoot, ".beads")
+
+	// Step 1: Create convoy bead
+	convoyID := fmt.Sprintf("hq-cv-%s", generateFormulaShortID())
+	convoyTitle := fmt.Sprintf("%s: %s", formulaName, firstLine(f.Description))
+	if len(convoyTitle) > 80 {
+		convoyTitle = convoyTitle[:77] + "..."
+	}
+
+	// Structured fields are read back by gt synthesis and gt formula feed
+	description := fmt.Sprintf("Formula: %s\nFormula-Path: %s\nType: %s\nUnits: %d\nRig: %s",
+		formulaName, formulaPath, f.Type, len(plan.Units), targetRig)
+	if formulaRunPR > 0 {
+		description += fmt.Sprintf("\nPR: #%d", formulaRunPR)
+	}
+	for _, k := range sortedKeys(plan.Vars) {
+		description += fmt.Sprintf("\nVar %s: %s", k, plan.Vars[k])
+	}
+
+	if err := runBdInDir(townBeads, "create",
+		"--type=convoy",
+		"--id="+convoyID,
+		"--title="+convoyTitle,
+		"--description="+description,
+	); err != nil {
+		return fmt.Errorf("creating convoy bead: %w", err)
+	}
+
+	fmt.Printf("%s Created convoy: %s\n", style.Bold.Render("✓"), convoyID)
+
+	if plan.Type == formula.TypeWorkflow {
+		return pourWorkflowMolecule(plan, townBeads, convoyID, convoyTitle, targetRig)
+	}
+
+	// Step 2: Create one bead per unit, in dependency order
+	unitBeads := make(map[string]string) // unit ID -> bead ID
+	for _, u := range plan.Units {
+		beadID := fmt.Sprintf("%s-%s", formulaUnitPrefix(u.Kind), generateFormulaShortID())
+		if err := runBdInDir(townBeads, "create",
+			"--type=task",
+			"--id="+beadID,
+			"--title="+u.Title,
+			"--description="+u.Description,
+		); err != nil {
+			// Dependents created without this bead would lose their edge
+			// to it and be slung too early, so nothing is dispatched
+			return fmt.Errorf("creating bead for %s %s: %w (convoy %s has %d of %d units; nothing dispatched)",
+				u.Kind, u.ID, err, convoyID, len(unitBeads), len(plan.Units))
+		}
+
+		// Track the unit with the convoy
+		if err := runBdInDir(townBeads, "dep", "add", convoyID, beadID, "--type=tracks"); err != nil {
+			fmt.Printf("%s Failed to track %s: %v\n",
+				style.Dim.Render("Warning:"), u.ID, err)
+		}
+
+		// Block on the unit's dependencies
+		for _, need := range u.Needs {
+			needBead, ok := unitBeads[need]
+			if !ok {
+				continue
+			}
+			if err := runBdInDir(townBeads, "dep", "add", beadID, needBead); err != nil {
+				fmt.Printf("%s Failed to add dependency %s -> %s: %v\n",
+					style.Dim.Render("Warning:"), u.ID, need, err)
+			}
+		}
+
+		unitBeads[u.ID] = beadID
+		marker := "○"
+		if u.Kind == formula.UnitSynthesis {
+			marker = "★"
+		}
+		fmt.Printf("  %s Created %s: %s (%s)\n", style.Dim.Render(marker), u.Kind, u.ID, beadID)
+	}
+
+	// Step 3: Sling ready units to polecats
+	fmt.Printf("\n%s Dispatching ready work to polecats...\n\n", style.Bold.Render("→"))
+
+	slingCount, readyCount := 0, 0
+	for _, id := range plan.Ready() {
+		beadID, ok := unitBeads[id]
+		if !ok {
+			continue
+		}
+		readyCount++
+		u := plan.Unit(id)
+		if slingFormulaUnit(townBeads, beadID, u.Title, u.Description, targetRig) {
+			slingCount++
+		}
+	}
+
+	// Summary
+	blocked := len(unitBeads) - readyCount
+	fmt.Printf("\n%s Formula dispatched!\n", style.Bold.Render("✓"))
+	fmt.Printf("  Convoy:  %s\n", convoyID)
+	fmt.Printf("  Units:   %d dispatched", slingCount)
+	if blocked > 0 {
+		fmt.Printf(", %d waiting on dependencies", blocked)
+	}
+	fmt.Println()
+	if blocked > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf(
+			"The daemon runs gt formula feed %s as units close; run it by hand if the daemon is down", convoyID)))
+	}
+	fmt.Printf("\n  Track progress: gt convoy status %s\n", convoyID)
+
+	return nil
+}
+
+// pourWorkflowMolecule pours a workflow plan as one molecule: a root bead
+// with a child step per unit, tracked by the convoy and slung to a single
+// polecat. The polecat works the steps in order with gt mol step done,
+// which evaluates their When/Until conditions as it goes.
+func pourWorkflowMolecule(plan *formula.Plan, townBeads, convoyID, title, targetRig string) error {
+	b := beads.New(townBeads)
+	root, err := b.Create(beads.CreateOptions{
+		Title:       title,
+		Type:        "epic",
+		Description: fmt.Sprintf("Formula: %s\nConvoy: %s", plan.Formula, convoyID),
+	})
+	if err != nil {
+		return fmt.Errorf("creating molecule root: %w", err)
+	}
+
+	mol := &beads.Issue{ID: root.ID, Description: plan.MoleculeMarkdown()}
+	steps, err := b.InstantiateMolecule(mol, root, beads.InstantiateOptions{})
+	if err != nil {
+		return fmt.Errorf("pouring molecule %s: %w", root.ID, err)
+	}
+	fmt.Printf("%s Poured molecule: %s (%d steps)\n", style.Bold.Render("✓"), root.ID, len(steps))
+	for _, step := range steps {
+		fmt.Printf("  %s %s: %s\n", style.Dim.Render("○"), step.ID, step.Title)
+	}
+
+	if err := runBdInDir(townBeads, "dep", "add", convoyID, root.ID, "--type=tracks"); err != nil {
+		fmt.Printf("%s Failed to track %s: %v\n", style.Dim.Render("Warning:"), root.ID, err)
+	}
+
+	fmt.Printf("\n%s Dispatching molecule to a polecat...\n\n", style.Bold.Render("→"))
+	if !slingFormulaUnit(townBeads, root.ID, root.Title, "", targetRig) {
+		return fmt.Errorf("molecule %s created but not dispatched: run gt sling %s %s", root.ID, root.ID, targetRig)
+	}
+
+	fmt.Printf("\n%s Formula dispatched!\n", style.Bold.Render("✓"))
+	fmt.Printf("  Convoy:   %s\n", convoyID)
+	fmt.Printf("  Molecule: %s (%d steps)\n", root.ID, len(steps))
+	fmt.Printf("\n  Track progress: gt mol progress %s\n", root.ID)
+	return nil
+}
+
+// slingFormulaUnit slings a unit bead to the target rig, recording a
+// failure as a comment on the bead. Reports whether it was slung.
+func slingFormulaUnit(townBeads, beadID, title, args, targetRig string) bool {
+	slingArgs := []string{"sling", beadID, targetRig, "-s", title}
+	if args != "" {
+		slingArgs = append(slingArgs, "-a", args)
+	}
+	slingCmd := exec.Command("gt", slingArgs...)
+	slingCmd.Stdout = os.Stdout
+	slingCmd.Stderr = os.Stderr
+
+	if err := slingCmd.Run(); err != nil {
+		fmt.Printf("%s Failed to sling %s: %v\n",
+			style.Dim.Render("Warning:"), beadID, err)
+		_ = runBdInDir(townBeads, "comment", beadID, fmt.Sprintf("Failed to sling: %v", err))
+		return false
+	}
+	return true
+}
+
+// formulaUnitPrefix returns the bead ID prefix for a plan unit kind.
+func formulaUnitPrefix(kind string) string {
+	switch kind {
+	case formula.UnitLeg:
+		return "hq-leg"
+	case formula.UnitSynthesis:
+		return "hq-syn"
+	case formula.UnitAspect:
+		return "hq-asp"
+	default:
+		return "hq-step"
+	}
+}
+
+// runBdInDir runs a bd command in dir, showing its errors.
+func runBdInDir(dir string, args ...string) error {
+	c := exec.Command("bd", args...)
+	c.Dir = dir
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// sortedKeys returns a map's keys in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+func TestParseFormulaVars(t *testing.T) {
+	vars, err := parseFormulaVars([]string{"target=gt-1", "target=gt-2", "note=a=b", "empty="})
+	if err != nil {
+		t.Fatalf("parseFormulaVars: %v", err)
+	}
+	want := map[string][]string{
+		"target": {"gt-1", "gt-2"},
+		"note":   {"a=b"},
+		"empty":  {""},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("vars = %v, want %v", vars, want)
+	}
+
+	for _, bad := range []string{"novalue", "=x"} {
+		if _, err := parseFormulaVars([]string{bad}); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+const triageWorkflow = `
+formula = "triage"
+type = "workflow"
+
+[[steps]]
+id = "scan"
+title = "Scan for stuck polecats"
+description = "List polecats with no activity."
+outputs = ["stuck"]
+
+[[steps]]
+id = "nudge"
+title = "Nudge stuck polecats"
+needs = ["scan"]
+when = "steps.scan.stuck > 0"
+
+[[steps]]
+id = "report"
+title = "Report"
+needs = ["nudge"]
+`
+
+func TestWorkflowMoleculeRunsPastFirstStep(t *testing.T) {
+	f, err := formula.Parse([]byte(triageWorkflow))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	plan, err := f.Plan(formula.PlanOptions{})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	steps, err := beads.ParseMoleculeSteps(plan.MoleculeMarkdown())
+	if err != nil {
+		t.Fatalf("ParseMoleculeSteps: %v", err)
+	}
+	if len(steps) != 3 {
+		t.Fatalf("got %d steps, want 3", len(steps))
+	}
+	if steps[0].Title != "Scan for stuck polecats" || !strings.Contains(steps[0].Instructions, "no activity") {
+		t.Errorf("scan = %q / %q", steps[0].Title, steps[0].Instructions)
+	}
+	if !reflect.DeepEqual(steps[0].Outputs, []string{"stuck"}) {
+		t.Errorf("scan outputs = %v", steps[0].Outputs)
+	}
+	if !reflect.DeepEqual(steps[1].Needs, []string{"scan"}) || steps[1].When != "steps.scan.stuck > 0" {
+		t.Errorf("nudge needs = %v, when = %q", steps[1].Needs, steps[1].When)
+	}
+
+	// Pour the steps as a molecule would, then work it
+	ids := make(map[string]string)
+	var issues []*beads.Issue
+	for i, s := range steps {
+		issue := &beads.Issue{
+			ID:          fmt.Sprintf("hq-mol.%d", i+1),
+			Status:      "open",
+			Description: "step: " + s.Ref,
+		}
+		if s.When != "" {
+			issue.Description += "\nwhen: " + s.When
+		}
+		for _, need := range s.Needs {
+			issue.DependsOn = append(issue.DependsOn, ids[need])
+		}
+		ids[s.Ref] = issue.ID
+		issues = append(issues, issue)
+	}
+
+	next, err := beads.FindNextStep(issues)
+	if err != nil || next.Next == nil || next.Next.ID != "hq-mol.1" {
+		t.Fatalf("first step = %+v, %v; want hq-mol.1", next, err)
+	}
+
+	// Scan finds nothing stuck: nudge is skipped and report runs
+	issues[0].Status = "closed"
+	issues[0].Description = beads.SetStepOutputs(issues[0].Description, map[string]string{"stuck": "0"})
+	next, err = beads.FindNextStep(issues)
+	if err != nil {
+		t.Fatalf("FindNextStep: %v", err)
+	}
+	if next.Next == nil || next.Next.ID != "hq-mol.3" {
+		t.Fatalf("after scan: next = %+v, want hq-mol.3", next.Next)
+	}
+	if len(next.Skip) != 1 || next.Skip[0].ID != "hq-mol.2" {
+		t.Errorf("after scan: skip = %v, want [hq-mol.2]", next.Skip)
+	}
+}
+
+func TestReadyFormulaUnits(t *testing.T) {
+	units := []formulaUnitBead{
+		{ID: "hq-leg-a", Status: "closed"},
+		{ID: "hq-leg-b", Status: "hooked", Assignee: "gastown/polecats/nux"},
+		{ID: "hq-leg-c", Status: "open"},
+		{ID: "hq-step-d", Status: "open", Needs: []string{"hq-leg-a"}},
+		{ID: "hq-syn-e", Status: "open", Needs: []string{"hq-leg-a", "hq-leg-b"}},
+	}
+	statuses := make(map[string]string)
+	ready := func() []string {
+		for _, u := range units {
+			statuses[u.ID] = u.Status
+		}
+		var ids []string
+		for _, u := range readyFormulaUnits(units, statuses) {
+			ids = append(ids, u.ID)
+		}
+		return ids
+	}
+
+	if got := ready(); !reflect.DeepEqual(got, []string{"hq-leg-c", "hq-step-d"}) {
+		t.Errorf("ready = %v, want [hq-leg-c hq-step-d]", got)
+	}
+
+	// The last leg closing unblocks the synthesis
+	units[1].Status = "closed"
+	units[2].Status, units[3].Status = "hooked", "hooked"
+	if got := ready(); !reflect.DeepEqual(got, []string{"hq-syn-e"}) {
+		t.Errorf("ready = %v, want [hq-syn-e]", got)
+	}
+}
+
+func TestConvoyField(t *testing.T) {
+	desc := "Formula: code-review\nFormula-Path: /x\nRig: gastown\nVar pr: 12"
+	if got := convoyField(desc, "Formula"); got != "code-review" {
+		t.Errorf("Formula = %q", got)
+	}
+	if got := convoyField(desc, "Rig"); got != "gastown" {
+		t.Errorf("Rig = %q", got)
+	}
+	if got := convoyField(desc, "Train"); got != "" {
+		t.Errorf("Train = %q, want empty", got)
+	}
+}
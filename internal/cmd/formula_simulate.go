@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// runFormulaTest runs a formula's test cases against a simulated agent, or
+// a single smoke run when the formula has no test file.
+func runFormulaTest(cmd *cobra.Command, args []string) error {
+	path, err := formulaTestPath(args[0])
+	if err != nil {
+		return err
+	}
+	f, err := formula.ParseFile(path)
+	if err != nil {
+		return fmt.Errorf("parsing formula: %w", err)
+	}
+
+	testPath := formulaTestFile
+	if testPath == "" {
+		testPath = strings.TrimSuffix(strings.TrimSuffix(path, ".toml"), ".formula") + ".test.toml"
+		if _, err := os.Stat(testPath); err != nil {
+			return smokeTestFormula(cmd, f)
+		}
+	}
+	tf, err := formula.ParseTestFile(testPath)
+	if err != nil {
+		return err
+	}
+	if tf.Formula != "" && tf.Formula != f.Name {
+		return fmt.Errorf("%s tests formula %q, not %q", testPath, tf.Formula, f.Name)
+	}
+
+	results := make([]formula.TestResult, 0, len(tf.Cases))
+	failed := 0
+	for _, c := range tf.Cases {
+		res := f.RunTest(c, nil)
+		if !res.Passed {
+			failed++
+		}
+		results = append(results, res)
+	}
+
+	if formulaTestJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("%s %s (%s)\n", style.Bold.Render("Testing"), f.Name, testPath)
+		for _, res := range results {
+			if res.Passed {
+				fmt.Printf("  %s %s\n", style.SuccessPrefix, res.Name)
+			} else {
+				fmt.Printf("  %s %s\n", style.ErrorPrefix, res.Name)
+				for _, msg := range res.Failures {
+					fmt.Printf("      %s\n", strings.ReplaceAll(msg, "\n", "\n      "))
+				}
+			}
+			if formulaTestVerbose && res.Run != nil {
+				printSimEvents(res.Run)
+			}
+		}
+		fmt.Printf("\n%d passed, %d failed\n", len(results)-failed, failed)
+	}
+	if failed > 0 {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// smokeTestFormula plans a formula with --var values and defaults and runs
+// it with an agent that completes every step, filling declared outputs
+// with placeholders.
+func smokeTestFormula(cmd *cobra.Command, f *formula.Formula) error {
+	vars, err := parseFormulaVars(formulaTestVars)
+	if err != nil {
+		return err
+	}
+	plan, err := f.Plan(formula.PlanOptions{Vars: vars})
+	if err != nil {
+		return fmt.Errorf("formula %s: %w\n\nUsage: %s", f.Name, err, f.Usage())
+	}
+	run, err := plan.Simulate(formula.SimOptions{FillOutputs: true})
+	if err != nil {
+		return err
+	}
+
+	if formulaTestJSON {
+		data, err := json.MarshalIndent(run, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("%s %s (no test file; smoke run with defaults)\n", style.Bold.Render("Testing"), f.Name)
+		if formulaTestVerbose {
+			printSimEvents(run)
+		} else {
+			fmt.Printf("  Order: %s\n", strings.Join(run.Order, " → "))
+		}
+		fmt.Printf("  State: %s\n", run.State)
+	}
+	if run.State != formula.RunComplete {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// printSimEvents prints what the simulated agent did.
+func printSimEvents(run *formula.SimResult) {
+	for _, e := range run.Events {
+		line := fmt.Sprintf("%-5s %s", e.Action, e.Unit)
+		if e.Iteration > 1 {
+			line += fmt.Sprintf(" (iteration %d)", e.Iteration)
+		}
+		if e.Detail != "" {
+			line += " " + style.Dim.Render(e.Detail)
+		}
+		fmt.Printf("      %s\n", line)
+	}
+}
+
+// formulaTestPath resolves a formula name, or a path to a formula file.
+func formulaTestPath(name string) (string, error) {
+	if strings.HasSuffix(name, ".toml") {
+		if _, err := os.Stat(name); err == nil {
+			return name, nil
+		}
+	}
+	path, err := findFormulaFile(name)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(path, ".toml") {
+		return "", fmt.Errorf("formula %s is not a TOML formula: %s", name, path)
+	}
+	return path, nil
+}
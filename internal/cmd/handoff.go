@@ -349,6 +349,13 @@ var claudeEnvVars = []string{
 // This needs to be the actual command to execute (e.g., claude), not a session attach command.
 // The command includes a cd to the correct working directory for the role.
 func buildRestartCommand(sessionName string) (string, error) {
+	return buildTierRestartCommand(sessionName, "")
+}
+
+// buildTierRestartCommand is like buildRestartCommand, but runs the agent
+// for a molecule step's model tier (see config.ResolveTierAgentConfig) and
+// exports GT_TIER. An empty tier uses the default agent.
+func buildTierRestartCommand(sessionName, tier string) (string, error) {
 	// Detect town root from current directory
 	townRoot := detectTownRootFromCwd()
 	if townRoot == "" {
@@ -383,15 +390,27 @@ func buildRestartCommand(sessionName string) (string, error) {
 	// 3. export Claude-related env vars (not inherited by fresh shell)
 	// 4. run claude with the startup beacon (triggers immediate context loading)
 	// Use exec to ensure clean process replacement.
+	runtimeConfig := config.LoadRuntimeConfig("")
 	runtimeCmd := config.GetRuntimeCommandWithPrompt("", beacon)
+	if tier != "" {
+		rigPath := ""
+		if identity.Rig != "" {
+			rigPath = filepath.Join(townRoot, identity.Rig)
+		}
+		// The tier's agent may keep its session ID in a different env var
+		runtimeConfig, _ = config.ResolveTierAgentConfig(tier, string(identity.Role), townRoot, rigPath)
+		runtimeCmd = runtimeConfig.BuildCommandWithPrompt(beacon)
+	}
 
 	// Build environment exports - role vars first, then Claude vars
 	var exports []string
 	if gtRole != "" {
-		runtimeConfig := config.LoadRuntimeConfig("")
 		exports = append(exports, "GT_ROLE="+gtRole)
 		exports = append(exports, "BD_ACTOR="+gtRole)
 		exports = append(exports, "GIT_AUTHOR_NAME="+gtRole)
+		if tier != "" {
+			exports = append(exports, "GT_TIER="+tier)
+		}
 		if runtimeConfig.Session != nil && runtimeConfig.Session.SessionIDEnv != "" {
 			exports = append(exports, "GT_SESSION_ID_ENV="+runtimeConfig.Session.SessionIDEnv)
 		}
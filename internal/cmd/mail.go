@@ -18,6 +18,9 @@ var (
 	mailNotify        bool
 	mailSendSelf      bool
 	mailCC            []string // CC recipients
+	mailSendAt        string
+	mailSendIn        string
+	mailExpires       string
 	mailInboxJSON     bool
 	mailReadJSON      bool
 	mailInboxUnread   bool
@@ -35,12 +38,22 @@ var (
 	mailSearchBody    bool
 	mailSearchArchive bool
 	mailSearchJSON    bool
+	mailSearchAll     bool
+	mailSearchReindex bool
 
 	// Announces flags
 	mailAnnouncesJSON bool
 
 	// Clear flags
 	mailClearAll bool
+
+	// Scheduled flags
+	mailScheduledJSON   bool
+	mailScheduledCancel string
+
+	// Rules flags
+	mailRulesJSON bool
+	mailRulesAs   string
 )
 
 var mailCmd = &cobra.Command{
@@ -116,6 +129,15 @@ Priority levels:
 
 Use --urgent as shortcut for --priority 0.
 
+Scheduling:
+  --at <time>       Deliver at a time: RFC3339, "2006-01-02 15:04", or
+                    "15:04" (next occurrence, local time)
+  --in <duration>   Deliver after a delay (e.g., 30m, 2h, 1d)
+  --expires <dur>   Auto-archive unread after this long (counted from delivery)
+
+Deferred messages are held by the daemon until due; see 'gt mail scheduled'.
+Expired unread messages disappear from inboxes and are archived.
+
 Examples:
   gt mail send greenplace/Toast -s "Status check" -m "How's that bug fix going?"
   gt mail send mayor/ -s "Work complete" -m "Finished gt-abc"
@@ -125,7 +147,9 @@ Examples:
   gt mail send mayor/ -s "Re: Status" -m "Done" --reply-to msg-abc123
   gt mail send --self -s "Handoff" -m "Context for next session"
   gt mail send greenplace/Toast -s "Update" -m "Progress report" --cc overseer
-  gt mail send list:oncall -s "Alert" -m "System down"`,
+  gt mail send list:oncall -s "Alert" -m "System down"
+  gt mail send mayor/ -s "Standup" -m "Post status" --at 09:00
+  gt mail send gastown/refinery -s "Merge ready" -m "gt-abc" --expires 2h`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runMailSend,
 }
@@ -354,28 +378,40 @@ Examples:
 var mailSearchCmd = &cobra.Command{
 	Use:   "search <query>",
 	Short: "Search messages by content",
-	Long: `Search inbox for messages matching a pattern.
+	Long: `Search your inbox and archived mail.
 
 SYNTAX:
   gt mail search <query> [flags]
 
-The query is a regular expression pattern. Search is case-insensitive by default.
+Search uses the town's mail index, which is updated as mail is sent,
+read and archived, and rebuilt by the daemon. Matching is case-insensitive.
+
+QUERY:
+  word              Term in subject or body, matched as a prefix
+  "two words"       Exact phrase
+  from:<addr>       Sender contains addr
+  to:<addr>         Recipient or CC contains addr
+  thread:<id>       Thread ID
+  type:<type>       task, scavenge, notification, reply
+  after:<date>      Sent on or after date (2006-01-02 or RFC3339)
+  before:<date>     Sent before date
+All parts must match.
 
 FLAGS:
-  --from <sender>   Filter by sender address (substring match)
+  --from <sender>   Filter by sender address (same as from:)
   --subject         Only search subject lines
   --body            Only search message body
-  --archive         Include archived (closed) messages
+  --all             Search every agent's mail (overseer only)
+  --reindex         Rebuild the mail index first
   --json            Output as JSON
 
-By default, searches both subject and body text.
-
 Examples:
-  gt mail search "urgent"                    # Find messages with "urgent"
-  gt mail search "status.*check" --subject   # Regex in subjects only
-  gt mail search "error" --from witness      # From witness, containing "error"
-  gt mail search "handoff" --archive         # Include archived messages
-  gt mail search "" --from mayor/            # All messages from mayor`,
+  gt mail search urgent                          # Find messages with "urgent"
+  gt mail search '"merge ready"' --subject       # Phrase in subjects only
+  gt mail search error --from witness            # From witness, containing "error"
+  gt mail search 'from:mayor/ after:2026-01-05'  # Mayor's mail since Jan 5
+  gt mail search 'gt-abc to:refinery' --all      # Town-wide (overseer)
+  gt mail search "" --from mayor/                # All messages from mayor`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMailSearch,
 }
@@ -412,6 +448,77 @@ Examples:
 	RunE: runMailAnnounces,
 }
 
+var mailScheduledCmd = &cobra.Command{
+	Use:   "scheduled",
+	Short: "List or cancel mail waiting for deferred delivery",
+	Long: `List messages sent with --at or --in that the daemon has not delivered yet.
+
+Examples:
+  gt mail scheduled                    # List pending deliveries
+  gt mail scheduled --json             # As JSON
+  gt mail scheduled --cancel msg-abc   # Drop a pending message`,
+	Args: cobra.NoArgs,
+	RunE: runMailScheduled,
+}
+
+var mailRulesCmd = &cobra.Command{
+	Use:   "rules [address]",
+	Short: "Show mail rules that apply to an inbox",
+	Long: `Show the delivery rules that apply to an address (default: your own).
+
+Rules live in ~/gt/config/messaging.json under "rules", keyed by recipient
+address or pattern ("mayor/", "*/witness"). They are evaluated when mail is
+delivered: rules for exact addresses first, then patterns, each in order.
+Every matching rule applies until one with "stop": true.
+
+Match fields (all set fields must match):
+  from       Sender address pattern ("gastown/*", "deacon/")
+  subject    Regular expression on the subject
+  type       task, scavenge, notification, reply
+  priority   low, normal, high, urgent
+  thread     Thread ID
+
+Actions:
+  archive             Archive without showing in the inbox
+  mark-read           Deliver already read
+  forward:<address>   Also send a copy (address or list:name)
+  priority:<level>    Re-prioritize
+  nudge               Nudge the session instead of mailing (mail if not running)
+
+Example messaging.json:
+  "rules": {
+    "*/witness": [
+      {"name": "done-noise", "match": {"subject": "^POLECAT_DONE"}, "actions": ["mark-read"]}
+    ],
+    "mayor/": [
+      {"match": {"from": "*/refinery", "type": "notification"}, "actions": ["archive"], "stop": true}
+    ]
+  }
+
+Examples:
+  gt mail rules                        # Rules for your inbox
+  gt mail rules gastown/witness        # Rules for another inbox
+  gt mail rules test hq-abc            # What the rules would do with a message`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMailRules,
+}
+
+var mailRulesTestCmd = &cobra.Command{
+	Use:   "test <message-id>",
+	Short: "Show what mail rules would do with a message",
+	Long: `Evaluate the recipient's mail rules against an existing message.
+
+Nothing is changed; the matching rules and resulting actions are printed.
+Use --as to evaluate the rules of a different recipient.
+
+Examples:
+  gt mail rules test hq-abc
+  gt mail rules test hq-abc --as mayor/
+  gt mail rules test hq-abc --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMailRulesTest,
+}
+
 func init() {
 	// Send flags
 	mailSendCmd.Flags().StringVarP(&mailSubject, "subject", "s", "", "Message subject (required)")
@@ -426,6 +533,9 @@ func init() {
 	mailSendCmd.Flags().BoolVar(&mailPermanent, "permanent", false, "Send as permanent (not ephemeral, synced to remote)")
 	mailSendCmd.Flags().BoolVar(&mailSendSelf, "self", false, "Send to self (auto-detect from cwd)")
 	mailSendCmd.Flags().StringArrayVar(&mailCC, "cc", nil, "CC recipients (can be used multiple times)")
+	mailSendCmd.Flags().StringVar(&mailSendAt, "at", "", "Deliver at this time (RFC3339, \"2006-01-02 15:04\", or \"15:04\")")
+	mailSendCmd.Flags().StringVar(&mailSendIn, "in", "", "Deliver after this delay (e.g., 30m, 2h, 1d)")
+	mailSendCmd.Flags().StringVar(&mailExpires, "expires", "", "Archive unread after this long (e.g., 2h, 1d)")
 	_ = mailSendCmd.MarkFlagRequired("subject") // cobra flags: error only at runtime if missing
 
 	// Inbox flags
@@ -457,6 +567,8 @@ func init() {
 	mailSearchCmd.Flags().BoolVar(&mailSearchBody, "body", false, "Only search message body")
 	mailSearchCmd.Flags().BoolVar(&mailSearchArchive, "archive", false, "Include archived messages")
 	mailSearchCmd.Flags().BoolVar(&mailSearchJSON, "json", false, "Output as JSON")
+	mailSearchCmd.Flags().BoolVar(&mailSearchAll, "all", false, "Search all mail in the town (overseer only)")
+	mailSearchCmd.Flags().BoolVar(&mailSearchReindex, "reindex", false, "Rebuild the mail index before searching")
 
 	// Announces flags
 	mailAnnouncesCmd.Flags().BoolVar(&mailAnnouncesJSON, "json", false, "Output as JSON")
@@ -464,6 +576,16 @@ func init() {
 	// Clear flags
 	mailClearCmd.Flags().BoolVar(&mailClearAll, "all", false, "Clear all messages (default behavior)")
 
+	// Scheduled flags
+	mailScheduledCmd.Flags().BoolVar(&mailScheduledJSON, "json", false, "Output as JSON")
+	mailScheduledCmd.Flags().StringVar(&mailScheduledCancel, "cancel", "", "Cancel the pending message with this ID")
+
+	// Rules flags
+	mailRulesCmd.Flags().BoolVar(&mailRulesJSON, "json", false, "Output as JSON")
+	mailRulesTestCmd.Flags().BoolVar(&mailRulesJSON, "json", false, "Output as JSON")
+	mailRulesTestCmd.Flags().StringVar(&mailRulesAs, "as", "", "Evaluate the rules of this recipient instead")
+	mailRulesCmd.AddCommand(mailRulesTestCmd)
+
 	// Add subcommands
 	mailCmd.AddCommand(mailSendCmd)
 	mailCmd.AddCommand(mailInboxCmd)
@@ -481,6 +603,8 @@ func init() {
 	mailCmd.AddCommand(mailClearCmd)
 	mailCmd.AddCommand(mailSearchCmd)
 	mailCmd.AddCommand(mailAnnouncesCmd)
+	mailCmd.AddCommand(mailScheduledCmd)
+	mailCmd.AddCommand(mailRulesCmd)
 
 	rootCmd.AddCommand(mailCmd)
 }
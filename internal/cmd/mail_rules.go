@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// runMailRules lists the mail rules that apply to an address.
+func runMailRules(cmd *cobra.Command, args []string) error {
+	workDir, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	address := detectSender()
+	if len(args) > 0 {
+		address = args[0]
+	}
+
+	rules, err := mail.NewRouter(workDir).Rules(address)
+	if err != nil {
+		return err
+	}
+
+	if mailRulesJSON {
+		if rules == nil {
+			rules = []mail.RuleMatch{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rules)
+	}
+
+	if len(rules) == 0 {
+		fmt.Printf("%s No mail rules for %s\n", style.Dim.Render("○"), address)
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Mail rules for %s (%d):", address, len(rules))))
+	for i, rm := range rules {
+		fmt.Printf("  %d. %s %s\n", i+1, style.Bold.Render(rm.Label()), style.Dim.Render("("+rm.Pattern+")"))
+		fmt.Printf("     match:   %s\n", describeRuleMatch(rm.Rule.Match))
+		fmt.Printf("     actions: %s\n", strings.Join(rm.Rule.Actions, ", "))
+		if rm.Rule.Stop {
+			fmt.Printf("     %s\n", style.Dim.Render("stops evaluation"))
+		}
+	}
+	return nil
+}
+
+// runMailRulesTest evaluates mail rules against an existing message.
+func runMailRulesTest(cmd *cobra.Command, args []string) error {
+	msgID := args[0]
+
+	workDir, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	router := mail.NewRouter(workDir)
+	mailbox, err := router.GetMailbox(detectSender())
+	if err != nil {
+		return fmt.Errorf("getting mailbox: %w", err)
+	}
+	msg, err := mailbox.Get(msgID)
+	if err != nil {
+		if errors.Is(err, mail.ErrMessageNotFound) {
+			return fmt.Errorf("message %s not found", msgID)
+		}
+		return fmt.Errorf("getting message: %w", err)
+	}
+	if mailRulesAs != "" {
+		msg.To = mailRulesAs
+	}
+
+	outcome, err := router.EvaluateRules(msg)
+	if err != nil {
+		return err
+	}
+
+	if mailRulesJSON {
+		if outcome.Matched == nil {
+			outcome.Matched = []mail.RuleMatch{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(outcome)
+	}
+
+	fmt.Printf("%s %s → %s\n", style.Bold.Render(msg.ID), msg.Subject, msg.To)
+	fmt.Printf("   %s\n\n", style.Dim.Render(fmt.Sprintf("from %s, type %s, priority %s", msg.From, msg.Type, msg.Priority)))
+
+	if len(outcome.Matched) == 0 {
+		fmt.Printf("%s No rules match; delivered normally\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	fmt.Printf("%s\n", style.Bold.Render("Matching rules:"))
+	for _, rm := range outcome.Matched {
+		fmt.Printf("  %s %s: %s\n", style.Success.Render("✓"), rm.Label(), strings.Join(rm.Rule.Actions, ", "))
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Result:"))
+	for _, line := range describeRuleOutcome(outcome) {
+		fmt.Printf("  %s\n", line)
+	}
+	return nil
+}
+
+// describeRuleMatch renders a rule's match conditions for display.
+func describeRuleMatch(m config.MailRuleMatch) string {
+	var parts []string
+	if m.From != "" {
+		parts = append(parts, "from="+m.From)
+	}
+	if m.Subject != "" {
+		parts = append(parts, fmt.Sprintf("subject=/%s/", m.Subject))
+	}
+	if m.Type != "" {
+		parts = append(parts, "type="+m.Type)
+	}
+	if m.Priority != "" {
+		parts = append(parts, "priority="+m.Priority)
+	}
+	if m.Thread != "" {
+		parts = append(parts, "thread="+m.Thread)
+	}
+	if len(parts) == 0 {
+		return "all mail"
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeRuleOutcome explains the delivery a rule outcome leads to.
+func describeRuleOutcome(o *mail.RuleOutcome) []string {
+	var lines []string
+	if o.Priority != "" {
+		lines = append(lines, "Priority set to "+string(o.Priority))
+	}
+	for _, target := range o.Forward {
+		lines = append(lines, "Copy forwarded to "+target)
+	}
+	switch {
+	case o.Archive:
+		lines = append(lines, "Archived (not shown in the inbox)")
+	case o.Nudge:
+		lines = append(lines, "Nudged to the recipient's session (mailed if it isn't running)")
+	case o.MarkRead:
+		lines = append(lines, "Delivered already read")
+	default:
+		lines = append(lines, "Delivered to the inbox")
+	}
+	return lines
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// deliverAtLayouts are the absolute time formats accepted by --at,
+// besides RFC3339. Times without a zone are local.
+var deliverAtLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+}
+
+// parseDeliverAt resolves --at / --in into a delivery time.
+// Returns the zero time when neither is set (deliver now).
+func parseDeliverAt(at, in string, now time.Time) (time.Time, error) {
+	if at != "" && in != "" {
+		return time.Time{}, fmt.Errorf("--at and --in are mutually exclusive")
+	}
+
+	if in != "" {
+		d, err := parseDuration(in)
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid --in %q: want a positive duration like 30m, 2h or 1d", in)
+		}
+		return now.Add(d), nil
+	}
+
+	if at == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		return checkFuture(t, now, at)
+	}
+	for _, layout := range deliverAtLayouts {
+		if t, err := time.ParseInLocation(layout, at, now.Location()); err == nil {
+			return checkFuture(t, now, at)
+		}
+	}
+
+	// Bare clock time: the next occurrence, today or tomorrow
+	if clock, err := time.ParseInLocation("15:04", at, now.Location()); err == nil {
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --at %q: want RFC3339, \"2006-01-02 15:04\" or \"15:04\"", at)
+}
+
+// checkFuture rejects absolute delivery times that have already passed.
+func checkFuture(t, now time.Time, raw string) (time.Time, error) {
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("--at %q is in the past", raw)
+	}
+	return t, nil
+}
+
+// runMailScheduled lists or cancels mail waiting for deferred delivery.
+func runMailScheduled(cmd *cobra.Command, args []string) error {
+	workDir, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	router := mail.NewRouter(workDir)
+
+	if mailScheduledCancel != "" {
+		if err := router.CancelScheduled(mailScheduledCancel); err != nil {
+			if errors.Is(err, mail.ErrMessageNotFound) {
+				return fmt.Errorf("no scheduled message %s", mailScheduledCancel)
+			}
+			return fmt.Errorf("cancelling %s: %w", mailScheduledCancel, err)
+		}
+		fmt.Printf("%s Cancelled scheduled message %s\n", style.Bold.Render("✓"), mailScheduledCancel)
+		return nil
+	}
+
+	messages, err := router.ListScheduled()
+	if err != nil {
+		return fmt.Errorf("listing scheduled mail: %w", err)
+	}
+
+	if mailScheduledJSON {
+		if messages == nil {
+			messages = []*mail.Message{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(messages)
+	}
+
+	if len(messages) == 0 {
+		fmt.Printf("%s No scheduled mail\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Scheduled mail (%d):", len(messages))))
+	now := time.Now()
+	for _, msg := range messages {
+		due := msg.DeliverAt.Sub(now).Round(time.Minute)
+		when := fmt.Sprintf("in %s", due)
+		if due <= 0 {
+			when = "due (awaiting daemon)"
+		}
+		fmt.Printf("  %s %s → %s\n", style.Bold.Render(msg.ID), msg.Subject, msg.To)
+		fmt.Printf("     %s\n", style.Dim.Render(fmt.Sprintf("%s, %s", msg.DeliverAt.Local().Format("2006-01-02 15:04"), when)))
+		if msg.ExpiresAt != nil {
+			fmt.Printf("     %s\n", style.Dim.Render("expires "+msg.ExpiresAt.Local().Format("2006-01-02 15:04")))
+		}
+	}
+	return nil
+}
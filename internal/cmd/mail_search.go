@@ -23,29 +23,40 @@ func runMailSearch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
-	// Get mailbox
-	router := mail.NewRouter(workDir)
-	mailbox, err := router.GetMailbox(address)
-	if err != nil {
-		return fmt.Errorf("getting mailbox: %w", err)
-	}
-
 	// Build search options
 	opts := mail.SearchOptions{
 		Query:       query,
 		FromFilter:  mailSearchFrom,
 		SubjectOnly: mailSearchSubject,
 		BodyOnly:    mailSearchBody,
+		Reindex:     mailSearchReindex,
 	}
 
-	// Execute search
-	messages, err := mailbox.Search(opts)
+	// Execute search: town-wide for the overseer, else the caller's mailbox
+	router := mail.NewRouter(workDir)
+	var messages []*mail.Message
+	if mailSearchAll {
+		if address != "overseer" {
+			return fmt.Errorf("--all is only available to the overseer (you are %s)", address)
+		}
+		address = "all mail"
+		messages, err = router.SearchTown(opts)
+	} else {
+		mailbox, mbErr := router.GetMailbox(address)
+		if mbErr != nil {
+			return fmt.Errorf("getting mailbox: %w", mbErr)
+		}
+		messages, err = mailbox.Search(opts)
+	}
 	if err != nil {
 		return fmt.Errorf("searching messages: %w", err)
 	}
 
 	// JSON output
 	if mailSearchJSON {
+		if messages == nil {
+			messages = []*mail.Message{}
+		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		return enc.Encode(messages)
@@ -79,9 +90,15 @@ func runMailSearch(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("  %s %s%s%s%s\n", readMarker, msg.Subject, typeMarker, priorityMarker, wispMarker)
-		fmt.Printf("    %s from %s\n",
-			style.Dim.Render(msg.ID),
-			msg.From)
+		if mailSearchAll {
+			fmt.Printf("    %s from %s to %s\n",
+				style.Dim.Render(msg.ID),
+				msg.From, msg.To)
+		} else {
+			fmt.Printf("    %s from %s\n",
+				style.Dim.Render(msg.ID),
+				msg.From)
+		}
 		fmt.Printf("    %s\n",
 			style.Dim.Render(msg.Timestamp.Format("2006-01-02 15:04")))
 	}
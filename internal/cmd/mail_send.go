@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/events"
@@ -87,6 +88,27 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 	// Set CC recipients
 	msg.CC = mailCC
 
+	// Deferred delivery and expiry (expiry counts from delivery)
+	now := time.Now()
+	deliverAt, err := parseDeliverAt(mailSendAt, mailSendIn, now)
+	if err != nil {
+		return err
+	}
+	if !deliverAt.IsZero() {
+		msg.DeliverAt = &deliverAt
+	}
+	if mailExpires != "" {
+		ttl, err := parseDuration(mailExpires)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid --expires %q: want a positive duration like 2h or 1d", mailExpires)
+		}
+		expiresAt := now.Add(ttl)
+		if msg.DeliverAt != nil {
+			expiresAt = msg.DeliverAt.Add(ttl)
+		}
+		msg.ExpiresAt = &expiresAt
+	}
+
 	// Handle reply-to: auto-set type to reply and look up thread
 	if mailReplyTo != "" {
 		msg.ReplyTo = mailReplyTo
@@ -129,8 +151,17 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 	// Log mail event to activity feed
 	_ = events.LogFeed(events.TypeMail, from, events.MailPayload(to, mailSubject))
 
-	fmt.Printf("%s Message sent to %s\n", style.Bold.Render("✓"), to)
-	fmt.Printf("  Subject: %s\n", mailSubject)
+	if msg.DeliverAt != nil {
+		fmt.Printf("%s Message scheduled for %s\n", style.Bold.Render("✓"), to)
+		fmt.Printf("  Subject: %s\n", mailSubject)
+		fmt.Printf("  Deliver: %s (in %s)\n", msg.DeliverAt.Format("2006-01-02 15:04 MST"), msg.DeliverAt.Sub(now).Round(time.Minute))
+	} else {
+		fmt.Printf("%s Message sent to %s\n", style.Bold.Render("✓"), to)
+		fmt.Printf("  Subject: %s\n", mailSubject)
+	}
+	if msg.ExpiresAt != nil {
+		fmt.Printf("  Expires: %s\n", msg.ExpiresAt.Format("2006-01-02 15:04 MST"))
+	}
 
 	// Show fan-out recipients for list addresses
 	if len(listRecipients) > 0 {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/steveyegge/gastown/internal/config"
 )
@@ -381,3 +382,45 @@ func TestAnnounceMessageParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDeliverAt(t *testing.T) {
+	now := time.Date(2026, 3, 1, 14, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		at, in  string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "neither", want: time.Time{}},
+		{name: "in hours", in: "2h", want: now.Add(2 * time.Hour)},
+		{name: "in days", in: "1d", want: now.Add(24 * time.Hour)},
+		{name: "in negative", in: "-1h", wantErr: true},
+		{name: "in garbage", in: "soon", wantErr: true},
+		{name: "at clock later today", at: "15:30", want: time.Date(2026, 3, 1, 15, 30, 0, 0, time.Local)},
+		{name: "at clock tomorrow", at: "09:00", want: time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)},
+		{name: "at date time", at: "2026-03-05 08:15", want: time.Date(2026, 3, 5, 8, 15, 0, 0, time.Local)},
+		{name: "at rfc3339", at: "2026-03-05T08:15:00Z", want: time.Date(2026, 3, 5, 8, 15, 0, 0, time.UTC)},
+		{name: "at past", at: "2026-02-01 08:00", wantErr: true},
+		{name: "at garbage", at: "tomorrow", wantErr: true},
+		{name: "both", at: "15:30", in: "2h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDeliverAt(tt.at, tt.in, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseDeliverAt(%q, %q) = %v, want error", tt.at, tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDeliverAt(%q, %q): %v", tt.at, tt.in, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDeliverAt(%q, %q) = %v, want %v", tt.at, tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -6,7 +6,8 @@ import (
 
 // Molecule command flags
 var (
-	moleculeJSON bool
+	moleculeJSON           bool
+	moleculeProgressFormat string
 )
 
 var moleculeCmd = &cobra.Command{
@@ -52,8 +53,14 @@ Given a root issue (the parent of molecule steps), displays:
 
 This is useful for the Witness to monitor molecule execution.
 
-Example:
-  gt molecule progress gt-abc`,
+With --format, renders the step dependency graph instead, colored by
+status: ascii for the terminal, dot (Graphviz), mermaid, or svg. Children
+bonded at run time are drawn feeding the steps that wait for all-children.
+
+Examples:
+  gt molecule progress gt-abc
+  gt molecule progress gt-abc --format ascii
+  gt molecule progress gt-abc --format mermaid`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMoleculeProgress,
 }
@@ -227,6 +234,7 @@ close steps with 'bd close' - that skips the auto-continuation logic.`,
 func init() {
 	// Progress flags
 	moleculeProgressCmd.Flags().BoolVar(&moleculeJSON, "json", false, "Output as JSON")
+	moleculeProgressCmd.Flags().StringVar(&moleculeProgressFormat, "format", "", "Render the dependency graph: ascii, dot, mermaid, or svg")
 
 	// Attachment flags
 	moleculeAttachmentCmd.Flags().BoolVar(&moleculeJSON, "json", false, "Output as JSON")
@@ -11,7 +11,9 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/dnd"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 var (
@@ -137,6 +139,14 @@ func runMoleculeAwaitSignal(cmd *cobra.Command, args []string) error {
 
 	startTime := time.Now()
 
+	// Record the wait step for during_wait_step quiet policies
+	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
+		address := detectSender()
+		if err := dnd.MarkWaiting(townRoot, address, startTime.Add(timeout+time.Minute)); err == nil {
+			defer func() { _ = dnd.ClearWaiting(townRoot, address) }()
+		}
+	}
+
 	// Start bd activity --follow
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -6,11 +6,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dag"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -107,8 +109,10 @@ type MoleculeProgressInfo struct {
 	InProgress   int      `json:"in_progress_steps"`
 	ReadySteps   []string `json:"ready_steps"`
 	BlockedSteps []string `json:"blocked_steps"`
+	FailedSteps  []string `json:"failed_steps,omitempty"` // failed after retries (open ones are escalated)
 	Percent      int      `json:"percent_complete"`
 	Complete     bool     `json:"complete"`
+	Failed       bool     `json:"failed,omitempty"` // stopped by a fail-molecule step
 }
 
 // MoleculeStatusInfo contains status information for an agent's work.
@@ -170,6 +174,15 @@ func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no steps found for %s (not a molecule root?)", rootID)
 	}
 
+	if moleculeProgressFormat != "" {
+		out, err := dag.Render(beads.MoleculeGraph(root, children), moleculeProgressFormat)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+		return nil
+	}
+
 	// Build progress info
 	progress := MoleculeProgressInfo{
 		RootID:    rootID,
@@ -195,13 +208,19 @@ func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 	// Categorize steps
 	for _, child := range children {
 		progress.TotalSteps++
+		failed := slices.Contains(child.Labels, beads.StepFailedLabel)
+		if failed {
+			progress.FailedSteps = append(progress.FailedSteps, child.ID)
+		}
 
-		switch child.Status {
-		case "closed":
+		switch {
+		case child.Status == "closed":
 			progress.DoneSteps++
-		case "in_progress":
+		case failed:
+			// Escalated: waits for help, neither ready nor in progress
+		case child.Status == "in_progress":
 			progress.InProgress++
-		case "open":
+		case child.Status == "open":
 			// Check if all dependencies are closed
 			allDepsClosed := true
 			for _, depID := range child.DependsOn {
@@ -224,6 +243,7 @@ func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 		progress.Percent = (progress.DoneSteps * 100) / progress.TotalSteps
 	}
 	progress.Complete = progress.DoneSteps == progress.TotalSteps
+	progress.Failed = slices.Contains(root.Labels, beads.MoleculeFailedLabel)
 
 	// JSON output
 	if moleculeJSON {
@@ -255,8 +275,13 @@ func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Println()
 	fmt.Printf("  Blocked:     %d\n", len(progress.BlockedSteps))
+	if len(progress.FailedSteps) > 0 {
+		fmt.Printf("  Failed:      %d (%s)\n", len(progress.FailedSteps), strings.Join(progress.FailedSteps, ", "))
+	}
 
-	if progress.Complete {
+	if progress.Failed {
+		fmt.Printf("\n  %s\n", style.Bold.Render("✗ Molecule failed"))
+	} else if progress.Complete {
 		fmt.Printf("\n  %s\n", style.Bold.Render("✓ Molecule complete!"))
 	}
 
@@ -520,13 +545,19 @@ func getMoleculeProgressInfo(b *beads.Beads, moleculeRootID string) (*MoleculePr
 	// Categorize steps
 	for _, child := range children {
 		progress.TotalSteps++
+		failed := slices.Contains(child.Labels, beads.StepFailedLabel)
+		if failed {
+			progress.FailedSteps = append(progress.FailedSteps, child.ID)
+		}
 
-		switch child.Status {
-		case "closed":
+		switch {
+		case child.Status == "closed":
 			progress.DoneSteps++
-		case "in_progress":
+		case failed:
+			// Escalated: waits for help, neither ready nor in progress
+		case child.Status == "in_progress":
 			progress.InProgress++
-		case "open":
+		case child.Status == "open":
 			// Check if all dependencies are closed
 			allDepsClosed := true
 			for _, depID := range child.DependsOn {
@@ -549,6 +580,7 @@ func getMoleculeProgressInfo(b *beads.Beads, moleculeRootID string) (*MoleculePr
 		progress.Percent = (progress.DoneSteps * 100) / progress.TotalSteps
 	}
 	progress.Complete = progress.DoneSteps == progress.TotalSteps
+	progress.Failed = slices.Contains(root.Labels, beads.MoleculeFailedLabel)
 
 	return progress, nil
 }
@@ -559,6 +591,10 @@ func determineNextAction(status MoleculeStatusInfo) string {
 		return ""
 	}
 
+	if status.Progress.Failed {
+		return "Molecule failed - see the escalation for step(s) " + strings.Join(status.Progress.FailedSteps, ", ")
+	}
+
 	if status.Progress.Complete {
 		return "Molecule complete! Close the bead: bd close " + status.PinnedBead.ID
 	}
@@ -575,6 +611,10 @@ func determineNextAction(status MoleculeStatusInfo) string {
 		return "All remaining steps are blocked - waiting on dependencies"
 	}
 
+	if len(status.Progress.FailedSteps) > 0 {
+		return "Failed step(s) escalated - waiting for help: " + strings.Join(status.Progress.FailedSteps, ", ")
+	}
+
 	return ""
 }
 
@@ -662,8 +702,13 @@ func outputMoleculeStatus(status MoleculeStatusInfo) error {
 		}
 		fmt.Println()
 		fmt.Printf("  Blocked:     %d\n", len(status.Progress.BlockedSteps))
+		if len(status.Progress.FailedSteps) > 0 {
+			fmt.Printf("  Failed:      %d (%s)\n", len(status.Progress.FailedSteps), strings.Join(status.Progress.FailedSteps, ", "))
+		}
 
-		if status.Progress.Complete {
+		if status.Progress.Failed {
+			fmt.Printf("\n%s\n", style.Bold.Render("✗ Molecule failed"))
+		} else if status.Progress.Complete {
 			fmt.Printf("\n%s\n", style.Bold.Render("✓ Molecule complete!"))
 		}
 	}
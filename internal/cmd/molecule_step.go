@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
@@ -24,31 +25,61 @@ This command handles the step-to-step transition for polecats:
 
 1. Closes the completed step (bd close <step-id>)
 2. Extracts the molecule ID from the step
-3. Finds the next ready step (dependency-aware)
+3. Finds the next ready step (dependency-aware), skipping steps whose
+   When: condition is false
 4. If next step exists:
    - Updates the hook to point to the next step
-   - Respawns the pane for a fresh session
+   - Respawns the pane for a fresh session, using the agent for the
+     step's Tier: (see "tiers" in town and rig settings)
 5. If molecule complete:
    - Clears the hook
    - Sends POLECAT_DONE to witness
    - Exits the session
 
+Outputs recorded with --output are stored on the step bead. Later steps
+reference them as {{steps.<ref>.<key>}} (resolved in gt prime) or in
+When:/Until: conditions as steps.<ref>.<key>. A step that declares
+Outputs: must record all of them before it can close.
+
+A step with an Until: condition is not closed while the condition is false
+and it has iterations left (MaxIterations, default 10): it stays open and
+runs again in a fresh session.
+
+With --failed, the step is reported as failed instead. It runs again in a
+fresh session while it has Retries: left; after that its OnFailure: policy
+applies:
+  escalate       - the step stays open, marked failed, and is escalated (default)
+  skip           - the step is closed as failed and the molecule carries on
+  fail-molecule  - the step is closed as failed and the molecule stops
+  goto:<step>    - <step> and the steps after it run again
+Later steps can test steps.<ref>.status == "failed". Steps that run past
+their Timeout: are failed the same way by 'gt mol step overdue --fail'.
+
 IMPORTANT: This is the canonical way to complete molecule steps. Do NOT manually
 close steps with 'bd close' - it skips the auto-continuation logic.
 
-Example:
-  gt mol step done gt-abc.1    # Complete step 1 of molecule gt-abc`,
+Examples:
+  gt mol step done gt-abc.1    # Complete step 1 of molecule gt-abc
+  gt mol step done gt-abc.1 --output approach="token bucket"
+  gt mol step done gt-abc.1 --output plan=@design.md   # value from a file
+  gt mol step done gt-abc.2 --failed --reason "tests fail on main"`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMoleculeStepDone,
 }
 
 var (
-	moleculeStepDryRun bool
+	moleculeStepDryRun  bool
+	moleculeStepOutputs []string
+	moleculeStepFailed  bool
+	moleculeStepReason  string
 )
 
 func init() {
 	moleculeStepDoneCmd.Flags().BoolVarP(&moleculeStepDryRun, "dry-run", "n", false, "Show what would be done without executing")
 	moleculeStepDoneCmd.Flags().BoolVar(&moleculeJSON, "json", false, "Output as JSON")
+	moleculeStepDoneCmd.Flags().StringArrayVar(&moleculeStepOutputs, "output", nil, "Record a step output (key=value, or key=@file; repeatable)")
+	moleculeStepDoneCmd.Flags().BoolVar(&moleculeStepFailed, "failed", false, "Report the step as failed (retries, then its OnFailure: policy)")
+	moleculeStepDoneCmd.Flags().StringVar(&moleculeStepReason, "reason", "", "Why the step failed (with --failed)")
 }
 
 // StepDoneResult is the result of a step done operation.
@@ -59,7 +90,10 @@ type StepDoneResult struct {
 	NextStepID   string `json:"next_step_id,omitempty"`
 	NextStepTitle string `json:"next_step_title,omitempty"`
 	Complete     bool   `json:"complete"`
-	Action       string `json:"action"` // "continue", "done", "no_more_ready"
+	Action       string `json:"action"` // "continue", "repeat", "done", "no_more_ready", "retry", "escalated", "failed"
+	Outputs      map[string]string `json:"outputs,omitempty"`
+	Failure      string `json:"failure,omitempty"` // on_failure action taken for a failed step
+	Attempt      int    `json:"attempt,omitempty"` // failed attempts of the step so far
 }
 
 func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
@@ -104,7 +138,49 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		MoleculeID: moleculeID,
 	}
 
-	// Step 3: Close the step
+	// Step 3: Record outputs, then close the step unless it repeats until
+	// a condition holds
+	outputs, err := parseStepOutputs(moleculeStepOutputs)
+	if err != nil {
+		return err
+	}
+	if err := beads.CheckStepOutputs(step, outputs, false); err != nil {
+		return err
+	}
+	if len(outputs) > 0 {
+		step.Description = beads.SetStepOutputs(step.Description, outputs)
+		result.Outputs = outputs
+	}
+	if moleculeStepFailed {
+		return runMoleculeStepFailed(cwd, townRoot, workDir, b, step, result)
+	}
+	if moleculeStepReason != "" {
+		return fmt.Errorf("--reason requires --failed")
+	}
+
+	repeat, err := repeatUntilStep(b, step, moleculeID, moleculeStepDryRun)
+	if err != nil {
+		return fmt.Errorf("evaluating until condition: %w", err)
+	}
+	if !repeat {
+		if err := beads.CheckStepOutputs(step, nil, true); err != nil {
+			return err
+		}
+		if err := recordStepOutputs(b, step, outputs, moleculeStepDryRun); err != nil {
+			return err
+		}
+	}
+	if repeat {
+		result.NextStepID = step.ID
+		result.NextStepTitle = step.Title
+		result.Action = "repeat"
+		if moleculeJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(result)
+		}
+		return handleStepContinue(cwd, townRoot, workDir, step, moleculeStepDryRun)
+	}
 	if moleculeStepDryRun {
 		fmt.Printf("[dry-run] Would close step: %s\n", stepID)
 		result.StepClosed = true
@@ -116,8 +192,16 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Closed step %s: %s\n", style.Bold.Render("✓"), stepID, step.Title)
 	}
 
+	return continueMolecule(cwd, townRoot, workDir, b, result)
+}
+
+// continueMolecule finds the step to run after result.StepID and hands off
+// to it, or finishes the molecule.
+func continueMolecule(cwd, townRoot, workDir string, b *beads.Beads, result StepDoneResult) error {
+	moleculeID := result.MoleculeID
+
 	// Step 4: Find the next ready step
-	nextStep, allComplete, err := findNextReadyStep(b, moleculeID)
+	nextStep, allComplete, err := findNextReadyStep(b, moleculeID, moleculeStepDryRun)
 	if err != nil {
 		return fmt.Errorf("finding next step: %w", err)
 	}
@@ -159,6 +243,47 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// parseStepOutputs parses --output key=value flags. A value starting with
+// @ is read from that file, without its trailing newline.
+func parseStepOutputs(pairs []string) (map[string]string, error) {
+	outputs := make(map[string]string)
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --output %q: expected key=value", pair)
+		}
+		if !beads.ValidOutputName(key) {
+			return nil, fmt.Errorf("invalid output name %q", key)
+		}
+		if path, ok := strings.CutPrefix(value, "@"); ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("reading output %s: %w", key, err)
+			}
+			value = strings.TrimRight(string(data), "\n")
+		}
+		outputs[key] = value
+	}
+	return outputs, nil
+}
+
+// recordStepOutputs saves outputs on the step bead. step.Description
+// already carries them.
+func recordStepOutputs(b *beads.Beads, step *beads.Issue, outputs map[string]string, dryRun bool) error {
+	if len(outputs) == 0 {
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("[dry-run] Would record outputs on %s: %s\n", step.ID, strings.Join(sortedKeys(outputs), ", "))
+		return nil
+	}
+	if err := b.Update(step.ID, beads.UpdateOptions{Description: &step.Description}); err != nil {
+		return fmt.Errorf("recording outputs: %w", err)
+	}
+	fmt.Printf("%s Recorded outputs: %s\n", style.Bold.Render("✓"), strings.Join(sortedKeys(outputs), ", "))
+	return nil
+}
+
 // extractMoleculeIDFromStep extracts the molecule ID from a step ID.
 // Step IDs have format: mol-id.N where N is the step number.
 // Examples:
@@ -190,7 +315,10 @@ func extractMoleculeIDFromStep(stepID string) string {
 // Returns (nextStep, allComplete, error).
 // If all steps are complete, returns (nil, true, nil).
 // If no steps are ready but some are blocked/in_progress, returns (nil, false, nil).
-func findNextReadyStep(b *beads.Beads, moleculeID string) (*beads.Issue, bool, error) {
+//
+// Steps whose When: condition is false are closed as skipped on the way
+// (see beads.FindNextStep); with dryRun they are only reported.
+func findNextReadyStep(b *beads.Beads, moleculeID string, dryRun bool) (*beads.Issue, bool, error) {
 	// Get all children of the molecule
 	children, err := b.List(beads.ListOptions{
 		Parent:   moleculeID,
@@ -205,52 +333,68 @@ func findNextReadyStep(b *beads.Beads, moleculeID string) (*beads.Issue, bool, e
 		return nil, true, nil // No steps = complete
 	}
 
-	// Build set of closed step IDs and collect open steps
-	// Note: "open" means not started. "in_progress" means someone's working on it.
-	// We only consider "open" steps as candidates for the next step.
-	closedIDs := make(map[string]bool)
-	var openSteps []*beads.Issue
-	hasNonClosedSteps := false
-
-	for _, child := range children {
-		switch child.Status {
-		case "closed":
-			closedIDs[child.ID] = true
-		case "open":
-			openSteps = append(openSteps, child)
-			hasNonClosedSteps = true
-		default:
-			// in_progress or other status - not closed, not available
-			hasNonClosedSteps = true
-		}
+	next, err := beads.FindNextStep(children)
+	if err != nil {
+		return nil, false, err
 	}
 
-	// Check if all complete
-	if !hasNonClosedSteps {
-		return nil, true, nil
+	for _, step := range next.Skip {
+		when := beads.ParseStepControl(step.Description).When
+		if dryRun {
+			fmt.Printf("[dry-run] Would skip step %s: when %s is false\n", step.ID, when)
+			continue
+		}
+		if err := b.Update(step.ID, beads.UpdateOptions{AddLabels: []string{beads.StepSkippedLabel}}); err != nil {
+			return nil, false, fmt.Errorf("labeling skipped step %s: %w", step.ID, err)
+		}
+		if err := b.CloseWithReason("skipped: when "+when+" is false", step.ID); err != nil {
+			return nil, false, fmt.Errorf("skipping step %s: %w", step.ID, err)
+		}
+		fmt.Printf("%s Skipped step %s: %s %s\n", style.Dim.Render("○"), step.ID, step.Title,
+			style.Dim.Render("(when "+when+" is false)"))
 	}
 
-	// Find ready steps (open steps with all dependencies closed)
-	for _, step := range openSteps {
-		allDepsClosed := true
-		for _, depID := range step.DependsOn {
-			if !closedIDs[depID] {
-				allDepsClosed = false
-				break
-			}
-		}
+	return next.Next, next.Complete, nil
+}
 
-		if len(step.DependsOn) == 0 || allDepsClosed {
-			return step, false, nil
-		}
+// repeatUntilStep handles a finished step with an Until: condition. If the
+// condition is still false and iterations remain, it records the iteration
+// and leaves the step open to run again, returning true.
+func repeatUntilStep(b *beads.Beads, step *beads.Issue, moleculeID string, dryRun bool) (bool, error) {
+	ctl := beads.ParseStepControl(step.Description)
+	if ctl.Until == "" {
+		return false, nil
+	}
+	children, err := b.List(beads.ListOptions{
+		Parent:   moleculeID,
+		Status:   "all",
+		Priority: -1,
+	})
+	if err != nil {
+		return false, fmt.Errorf("listing molecule steps: %w", err)
+	}
+	repeat, n, err := beads.RepeatStep(step, children)
+	if err != nil || !repeat {
+		return false, err
 	}
 
-	// No ready steps (all blocked or in_progress)
-	return nil, false, nil
+	if dryRun {
+		fmt.Printf("[dry-run] Would repeat step %s (iteration %d/%d): until %s is false\n",
+			step.ID, n+1, ctl.Iterations(), ctl.Until)
+		return true, nil
+	}
+	desc := beads.SetStepIteration(step.Description, n)
+	status := "open"
+	if err := b.Update(step.ID, beads.UpdateOptions{Description: &desc, Status: &status}); err != nil {
+		return false, fmt.Errorf("recording iteration: %w", err)
+	}
+	fmt.Printf("%s Repeating step %s (iteration %d/%d): %s\n", style.Bold.Render("↻"),
+		step.ID, n+1, ctl.Iterations(), style.Dim.Render("until "+ctl.Until))
+	return true, nil
 }
 
 // handleStepContinue handles continuing to the next step.
-func handleStepContinue(cwd, townRoot, _ string, nextStep *beads.Issue, dryRun bool) error { // workDir unused but kept for signature consistency
+func handleStepContinue(cwd, townRoot, workDir string, nextStep *beads.Issue, dryRun bool) error {
 	fmt.Printf("\n%s Next step: %s\n", style.Bold.Render("→"), nextStep.ID)
 	fmt.Printf("  %s\n", nextStep.Title)
 
@@ -280,10 +424,22 @@ func handleStepContinue(cwd, townRoot, _ string, nextStep *beads.Issue, dryRun b
 
 	if dryRun {
 		fmt.Printf("\n[dry-run] Would pin next step: %s\n", nextStep.ID)
+		if tier := beads.ParseStepControl(nextStep.Description).Tier; tier != "" {
+			fmt.Printf("[dry-run] Would respawn pane with the agent for tier %s\n", tier)
+			return nil
+		}
 		fmt.Printf("[dry-run] Would respawn pane\n")
 		return nil
 	}
 
+	// A step with a timeout starts its clock now
+	if beads.ParseStepControl(nextStep.Description).Timeout != "" {
+		desc := beads.SetStepStarted(nextStep.Description, time.Now())
+		if err := beads.New(workDir).Update(nextStep.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+			style.PrintWarning("could not record start of %s: %v", nextStep.ID, err)
+		}
+	}
+
 	// Pin the next step bead
 	pinCmd := exec.Command("bd", "update", nextStep.ID, "--status=pinned", "--assignee="+agentID)
 	pinCmd.Dir = gitRoot
@@ -313,10 +469,19 @@ func handleStepContinue(cwd, townRoot, _ string, nextStep *beads.Issue, dryRun b
 		return fmt.Errorf("getting session name: %w", err)
 	}
 
-	restartCmd, err := buildRestartCommand(currentSession)
+	// The next session runs on the agent for the step's model tier
+	tier := beads.ParseStepControl(nextStep.Description).Tier
+	restartCmd, err := buildTierRestartCommand(currentSession, tier)
 	if err != nil {
 		return fmt.Errorf("building restart command: %w", err)
 	}
+	if current := os.Getenv("GT_TIER"); tier != current {
+		if tier == "" {
+			fmt.Printf("%s Step has no tier: switching back to the default agent\n", style.Dim.Render("ℹ"))
+		} else {
+			fmt.Printf("%s Step tier %s: switching agent\n", style.Dim.Render("ℹ"), tier)
+		}
+	}
 
 	fmt.Printf("\n%s Respawning for next step...\n", style.Bold.Render("🔄"))
 
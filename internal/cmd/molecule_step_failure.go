@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/flow"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// moleculeStepOverdueCmd is the "gt mol step overdue" command.
+var moleculeStepOverdueCmd = &cobra.Command{
+	Use:   "overdue",
+	Short: "List molecule steps past their timeout",
+	Long: `List molecule steps that have run past their Timeout:.
+
+A step's timeout counts from when its current attempt started: when gt mol
+step done pinned it or retried it, or for steps without that record, when a
+worked (pinned, hooked or in_progress) step was last updated.
+
+With --fail, each overdue step is failed as if its agent had run
+'gt mol step done --failed': it is retried while it has Retries: left, then
+handled by its OnFailure: policy. The assignee is nudged to pick up the
+result with gt prime. The Witness runs this in its patrol.
+
+Examples:
+  gt mol step overdue            # List overdue steps in this rig
+  gt mol step overdue --fail     # Fail them and nudge their agents`,
+	Args: cobra.NoArgs,
+	RunE: runMoleculeStepOverdue,
+}
+
+var moleculeOverdueFail bool
+
+func init() {
+	moleculeStepOverdueCmd.Flags().BoolVar(&moleculeOverdueFail, "fail", false, "Fail overdue steps (retry or apply OnFailure:) and nudge their agents")
+	moleculeStepOverdueCmd.Flags().BoolVarP(&moleculeStepDryRun, "dry-run", "n", false, "With --fail, show what would be done without executing")
+	moleculeStepOverdueCmd.Flags().BoolVar(&moleculeJSON, "json", false, "Output as JSON")
+
+	moleculeStepCmd.AddCommand(moleculeStepOverdueCmd)
+}
+
+// OverdueStep is a molecule step past its timeout.
+type OverdueStep struct {
+	StepID     string    `json:"step_id"`
+	Title      string    `json:"title"`
+	MoleculeID string    `json:"molecule_id"`
+	Assignee   string    `json:"assignee,omitempty"`
+	Timeout    string    `json:"timeout"`
+	Deadline   time.Time `json:"deadline"`
+	Failure    string    `json:"failure,omitempty"` // action taken with --fail
+}
+
+// runMoleculeStepFailed handles gt mol step done --failed.
+func runMoleculeStepFailed(cwd, townRoot, workDir string, b *beads.Beads, step *beads.Issue, result StepDoneResult) error {
+	reason := moleculeStepReason
+	if reason == "" {
+		reason = "reported by agent"
+	}
+	steps, err := listMoleculeSteps(b, result.MoleculeID)
+	if err != nil {
+		return err
+	}
+	f, err := applyStepFailure(b, step, steps, result.MoleculeID, reason, moleculeStepDryRun)
+	if err != nil {
+		return err
+	}
+	result.Failure = f.Action
+	result.Attempt = f.Attempt
+	result.StepClosed = f.Close
+
+	switch f.Action {
+	case beads.FailureRetry:
+		result.Action = "retry"
+		result.NextStepID = step.ID
+		result.NextStepTitle = step.Title
+	case flow.OnFailureEscalate:
+		result.Action = "escalated"
+	case flow.OnFailureFailMolecule:
+		result.Action = "failed"
+	default: // skip, goto
+		return continueMolecule(cwd, townRoot, workDir, b, result)
+	}
+
+	if moleculeJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	if f.Action == beads.FailureRetry {
+		return handleStepContinue(cwd, townRoot, workDir, step, moleculeStepDryRun)
+	}
+	return handleMoleculeStopped(cwd, townRoot, result.MoleculeID, moleculeStepDryRun)
+}
+
+// applyStepFailure records a failed attempt of a step and applies the
+// outcome beads.DecideStepFailure chooses: a retry leaves the step open
+// with its timeout restarted; escalate marks it failed and escalates;
+// skip and fail-molecule close it as failed; goto reopens the target and
+// the steps after it.
+func applyStepFailure(b *beads.Beads, step *beads.Issue, steps []*beads.Issue, moleculeID, reason string, dryRun bool) (*beads.StepFailure, error) {
+	f, err := beads.DecideStepFailure(step, steps)
+	if err != nil {
+		return nil, err
+	}
+	ctl := beads.ParseStepControl(step.Description)
+	desc := beads.SetStepAttempt(step.Description, f.Attempt)
+
+	if dryRun {
+		fmt.Printf("[dry-run] Step %s failed (attempt %d): %s\n", step.ID, f.Attempt, reason)
+		switch f.Action {
+		case beads.FailureRetry:
+			fmt.Printf("[dry-run] Would retry step %s (attempt %d/%d)\n", step.ID, f.Attempt+1, ctl.Retries+1)
+		case flow.OnFailureGoto:
+			fmt.Printf("[dry-run] Would go to step %s, reopening %d step(s)\n", f.Target, len(f.Reopen))
+		default:
+			fmt.Printf("[dry-run] Would apply on_failure %s\n", f.Action)
+		}
+		return f, nil
+	}
+
+	update := beads.UpdateOptions{Description: &desc}
+	if f.Action == beads.FailureRetry || (f.Action == flow.OnFailureGoto && !f.Close) {
+		desc = beads.SetStepStarted(desc, time.Now())
+		status := "open"
+		update.Status = &status
+	} else {
+		update.AddLabels = []string{beads.StepFailedLabel}
+	}
+	if err := b.Update(step.ID, update); err != nil {
+		return nil, fmt.Errorf("recording failure of %s: %w", step.ID, err)
+	}
+
+	switch f.Action {
+	case beads.FailureRetry:
+		fmt.Printf("%s Step %s failed: %s\n", style.Bold.Render("✗"), step.ID, reason)
+		fmt.Printf("%s Retrying step %s (attempt %d/%d)\n", style.Bold.Render("↻"), step.ID, f.Attempt+1, ctl.Retries+1)
+		return f, nil
+	case flow.OnFailureGoto:
+		for _, s := range f.Reopen {
+			status := "open"
+			if err := b.Update(s.ID, beads.UpdateOptions{
+				Status:       &status,
+				RemoveLabels: []string{beads.StepFailedLabel, beads.StepSkippedLabel},
+			}); err != nil {
+				return nil, fmt.Errorf("reopening step %s: %w", s.ID, err)
+			}
+		}
+	case flow.OnFailureEscalate, flow.OnFailureFailMolecule:
+		escalateStepFailure(step, moleculeID, reason, f.Action)
+	}
+	if f.Action == flow.OnFailureFailMolecule {
+		if err := b.Update(moleculeID, beads.UpdateOptions{AddLabels: []string{beads.MoleculeFailedLabel}}); err != nil {
+			style.PrintWarning("could not mark molecule %s failed: %v", moleculeID, err)
+		}
+	}
+	if f.Close {
+		if err := b.CloseWithReason("failed: "+reason, step.ID); err != nil {
+			return nil, fmt.Errorf("closing failed step %s: %w", step.ID, err)
+		}
+	}
+
+	fmt.Printf("%s Step %s failed after %d attempt(s): %s\n", style.Bold.Render("✗"), step.ID, f.Attempt, reason)
+	switch f.Action {
+	case flow.OnFailureGoto:
+		fmt.Printf("  Going to step %s (%d step(s) reopened)\n", f.Target, len(f.Reopen))
+	case flow.OnFailureSkip:
+		fmt.Printf("  Skipped; the molecule carries on\n")
+	case flow.OnFailureFailMolecule:
+		fmt.Printf("  Molecule %s failed\n", moleculeID)
+	default:
+		fmt.Printf("  Escalated; the step waits for help\n")
+	}
+	return f, nil
+}
+
+// escalateStepFailure raises an escalation for a failed step. Failing to
+// escalate is reported but doesn't undo the failure.
+func escalateStepFailure(step *beads.Issue, moleculeID, reason, action string) {
+	severity := "medium"
+	if action == flow.OnFailureFailMolecule {
+		severity = "high"
+	}
+	escCmd := exec.Command("gt", "escalate", fmt.Sprintf("Molecule step %s failed: %s", step.ID, step.Title),
+		"--severity", severity,
+		"--reason", reason,
+		"--related", step.ID,
+		"--source", "molecule:"+moleculeID)
+	escCmd.Stdout = os.Stdout
+	escCmd.Stderr = os.Stderr
+	if err := escCmd.Run(); err != nil {
+		style.PrintWarning("could not escalate failed step %s: %v", step.ID, err)
+	}
+}
+
+// handleMoleculeStopped ends work on a molecule that is waiting on an
+// escalated step or has failed. Polecats exit with gt done --status
+// ESCALATED.
+func handleMoleculeStopped(cwd, townRoot, moleculeID string, dryRun bool) error {
+	roleInfo, err := GetRoleWithContext(cwd, townRoot)
+	if err != nil {
+		return fmt.Errorf("detecting role: %w", err)
+	}
+	if roleInfo.Role != RolePolecat {
+		fmt.Printf("\nMolecule %s is stopped until the escalation is resolved.\n", moleculeID)
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("[dry-run] Would run gt done --status ESCALATED\n")
+		return nil
+	}
+	fmt.Printf("%s Signaling escalation to witness...\n", style.Bold.Render("📤"))
+	doneCmd := exec.Command("gt", "done", "--status", ExitEscalated)
+	doneCmd.Stdout = os.Stdout
+	doneCmd.Stderr = os.Stderr
+	return doneCmd.Run()
+}
+
+// listMoleculeSteps returns all step beads of a molecule.
+func listMoleculeSteps(b *beads.Beads, moleculeID string) ([]*beads.Issue, error) {
+	steps, err := b.List(beads.ListOptions{
+		Parent:   moleculeID,
+		Status:   "all",
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing molecule steps: %w", err)
+	}
+	return steps, nil
+}
+
+func runMoleculeStepOverdue(cmd *cobra.Command, args []string) error {
+	workDir, err := findLocalBeadsDir()
+	if err != nil {
+		return fmt.Errorf("not in a beads workspace: %w", err)
+	}
+	b := beads.New(workDir)
+
+	issues, err := b.List(beads.ListOptions{Status: "all", Priority: -1})
+	if err != nil {
+		return fmt.Errorf("listing steps: %w", err)
+	}
+	now := time.Now()
+	var overdue []OverdueStep
+	for _, issue := range issues {
+		deadline, ok := beads.StepDeadline(issue)
+		if !ok || now.Before(deadline) {
+			continue
+		}
+		moleculeID := extractMoleculeIDFromStep(issue.ID)
+		if moleculeID == "" {
+			continue
+		}
+		o := OverdueStep{
+			StepID:     issue.ID,
+			Title:      issue.Title,
+			MoleculeID: moleculeID,
+			Assignee:   issue.Assignee,
+			Timeout:    beads.ParseStepControl(issue.Description).Timeout,
+			Deadline:   deadline,
+		}
+		if moleculeOverdueFail {
+			action, err := failOverdueStep(b, issue, o)
+			if err != nil {
+				style.PrintWarning("%v", err)
+			}
+			o.Failure = action
+		}
+		overdue = append(overdue, o)
+	}
+
+	if moleculeJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(overdue)
+	}
+	if len(overdue) == 0 {
+		fmt.Println("No overdue steps.")
+		return nil
+	}
+	if !moleculeOverdueFail {
+		for _, o := range overdue {
+			who := o.Assignee
+			if who == "" {
+				who = "unassigned"
+			}
+			fmt.Printf("  %s %s: %s %s\n", style.WarningPrefix, o.StepID, o.Title,
+				style.Dim.Render(fmt.Sprintf("(%s, timeout %s, overdue %s)", who, o.Timeout, now.Sub(o.Deadline).Round(time.Second))))
+		}
+		fmt.Printf("\nRun 'gt mol step overdue --fail' to fail them.\n")
+	}
+	return nil
+}
+
+// failOverdueStep fails a step that ran past its timeout and nudges its
+// assignee, whose session is still on the old attempt.
+func failOverdueStep(b *beads.Beads, step *beads.Issue, o OverdueStep) (string, error) {
+	steps, err := listMoleculeSteps(b, o.MoleculeID)
+	if err != nil {
+		return "", err
+	}
+	reason := fmt.Sprintf("timed out after %s", o.Timeout)
+	f, err := applyStepFailure(b, step, steps, o.MoleculeID, reason, moleculeStepDryRun)
+	if err != nil {
+		return "", fmt.Errorf("failing %s: %w", step.ID, err)
+	}
+	if o.Assignee == "" || moleculeStepDryRun {
+		return f.Action, nil
+	}
+
+	msg := fmt.Sprintf("Step %s %s (%s). Stop work on it and run 'gt prime' to see what to do next.", step.ID, reason, f.Action)
+	nudgeCmd := exec.Command("gt", "nudge", o.Assignee, msg)
+	nudgeCmd.Stdout = os.Stdout
+	nudgeCmd.Stderr = os.Stderr
+	if err := nudgeCmd.Run(); err != nil {
+		style.PrintWarning("could not nudge %s: %v", o.Assignee, err)
+	}
+	return f.Action, nil
+}
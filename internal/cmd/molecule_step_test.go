@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/steveyegge/gastown/internal/beads"
@@ -433,3 +435,24 @@ func TestStepDoneScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStepOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.md")
+	if err := os.WriteFile(path, []byte("# Plan\nUse a queue.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseStepOutputs([]string{"approach=token bucket", "plan=@" + path, "empty="})
+	if err != nil {
+		t.Fatalf("parseStepOutputs: %v", err)
+	}
+	if got["approach"] != "token bucket" || got["plan"] != "# Plan\nUse a queue." || got["empty"] != "" {
+		t.Errorf("outputs = %q", got)
+	}
+
+	for _, bad := range []string{"novalue", "=x", "bad key=x", "status=done", "f=@/nonexistent/file"} {
+		if _, err := parseStepOutputs([]string{bad}); err == nil {
+			t.Errorf("parseStepOutputs(%q) succeeded, want error", bad)
+		}
+	}
+}
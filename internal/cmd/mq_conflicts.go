@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ conflicts flags
+var (
+	mqConflictsRun  bool
+	mqConflictsJSON bool
+)
+
+var mqConflictsCmd = &cobra.Command{
+	Use:   "conflicts <rig>",
+	Short: "Show the conflict matrix for in-flight polecat branches",
+	Long: `Show likely merge conflicts between a rig's in-flight polecat branches.
+
+The daemon analyzes active polecat branches on every heartbeat, computing
+pairwise file and hunk overlap between branches and against the merge
+target. New hunk-level overlaps are mailed to the witness and the affected
+polecats as CONFLICT_WARNING messages, well before the refinery hits them.
+
+Levels:
+  hunk   Both sides change overlapping lines (merge will almost certainly conflict)
+  file   Both sides change the same file in different places
+
+Examples:
+  gt mq conflicts greenplace          # Show the last analysis
+  gt mq conflicts greenplace --run    # Analyze now (and send new warnings)
+  gt mq conflicts greenplace --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQConflicts,
+}
+
+func init() {
+	mqConflictsCmd.Flags().BoolVar(&mqConflictsRun, "run", false, "Run a fresh analysis instead of showing the last one")
+	mqConflictsCmd.Flags().BoolVar(&mqConflictsJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqConflictsCmd)
+}
+
+func runMQConflicts(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+
+	var matrix *refinery.ConflictMatrix
+	if mqConflictsRun {
+		matrix, _, err = refinery.NewConflictAnalyzer(r).Run()
+		if err != nil {
+			return fmt.Errorf("analyzing conflicts: %w", err)
+		}
+	} else {
+		matrix, err = refinery.LoadConflictMatrix(r.Path)
+		if err != nil {
+			return fmt.Errorf("loading conflict matrix: %w", err)
+		}
+	}
+
+	if mqConflictsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(matrix)
+	}
+
+	if matrix == nil {
+		fmt.Printf("No conflict analysis for %s yet %s\n", r.Name,
+			style.Dim.Render("(run with --run, or wait for the next daemon heartbeat)"))
+		return nil
+	}
+
+	fmt.Printf("%s %s → %s\n", style.Bold.Render("⚔ Conflict matrix:"), r.Name, matrix.Target)
+	fmt.Printf("   %d branches analyzed %s\n", len(matrix.Branches),
+		formatTimeAgo(matrix.GeneratedAt.Format(time.RFC3339)))
+
+	if len(matrix.Overlaps) == 0 {
+		fmt.Printf("\n   %s No overlapping changes\n", style.Success.Render("✓"))
+		return nil
+	}
+
+	fmt.Println()
+	for _, o := range matrix.Overlaps {
+		left := o.A
+		if p := matrix.Polecat(o.A); p != "" {
+			left = fmt.Sprintf("%s (%s)", o.A, p)
+		}
+		right := o.B
+		if o.Target {
+			right += " (target)"
+		} else if p := matrix.Polecat(o.B); p != "" {
+			right = fmt.Sprintf("%s (%s)", o.B, p)
+		}
+
+		icon := "○"
+		files := o.Files
+		if o.Level == refinery.ConflictHunk {
+			icon = style.Warning.Render("⚠")
+			files = o.HunkFiles
+		}
+		fmt.Printf("   %s %s ↔ %s %s\n", icon, left, right, style.Dim.Render(fmt.Sprintf("[%s]", o.Level)))
+		fmt.Printf("     %s\n", style.Dim.Render(strings.Join(files, ", ")))
+	}
+	return nil
+}
@@ -147,6 +147,8 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		if issue.Status == "open" {
 			if len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0 {
 				displayStatus = "blocked"
+			} else if fields != nil && fields.TrainStatus == beads.TrainStatusGreen {
+				displayStatus = "held" // green, waiting for its release train
 			} else {
 				displayStatus = "ready"
 			}
@@ -161,6 +163,8 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			styledStatus = style.Warning.Render("active")
 		case "blocked":
 			styledStatus = style.Dim.Render("blocked")
+		case "held":
+			styledStatus = style.Info.Render("held")
 		case "closed":
 			styledStatus = style.Dim.Render("closed")
 		}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -37,6 +38,9 @@ type MRStatusOutput struct {
 	// Dependencies
 	DependsOn []DependencyInfo `json:"depends_on,omitempty"`
 	Blocks    []DependencyInfo `json:"blocks,omitempty"`
+
+	// Likely conflicts with other in-flight branches (from the last analyzer pass)
+	ConflictRisk []refinery.BranchOverlap `json:"conflict_risk,omitempty"`
 }
 
 // DependencyInfo represents a dependency or blocker.
@@ -119,6 +123,11 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 		})
 	}
 
+	// Add conflict risk for open MRs
+	if mrFields != nil && issue.Status != "closed" {
+		output.ConflictRisk = loadConflictRisk(mrFields.Rig, mrFields.Branch)
+	}
+
 	// JSON output
 	if mqStatusJSON {
 		enc := json.NewEncoder(os.Stdout)
@@ -127,7 +136,51 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Human-readable output
-	return printMqStatus(issue, mrFields)
+	if err := printMqStatus(issue, mrFields); err != nil {
+		return err
+	}
+	printConflictRisk(output.ConflictRisk, mrFields)
+	return nil
+}
+
+// loadConflictRisk returns the overlaps involving a branch from the rig's
+// most recent conflict matrix. Returns nil if no analysis is available.
+func loadConflictRisk(rigName, branch string) []refinery.BranchOverlap {
+	if rigName == "" || branch == "" {
+		return nil
+	}
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return nil
+	}
+	matrix, err := refinery.LoadConflictMatrix(r.Path)
+	if err != nil || matrix == nil {
+		return nil
+	}
+	return matrix.ForBranch(branch)
+}
+
+// printConflictRisk prints the Conflict Risk section of gt mq status.
+func printConflictRisk(overlaps []refinery.BranchOverlap, mrFields *beads.MRFields) {
+	if len(overlaps) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Conflict Risk"))
+	for _, o := range overlaps {
+		other := o.Other(mrFields.Branch)
+		if o.Target {
+			other += " (target)"
+		}
+		icon := "○"
+		files := o.Files
+		if o.Level == refinery.ConflictHunk {
+			icon = style.Warning.Render("⚠")
+			files = o.HunkFiles
+		}
+		fmt.Printf("   %s %s %s\n", icon, other, style.Dim.Render(fmt.Sprintf("[%s]", o.Level)))
+		fmt.Printf("     %s\n", style.Dim.Render(strings.Join(files, ", ")))
+	}
 }
 
 // printMqStatus prints detailed MR status in human-readable format.
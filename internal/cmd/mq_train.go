@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ train command flags
+var (
+	mqTrainCheck bool
+	mqTrainReset bool
+	mqTrainJSON  bool
+)
+
+var mqTrainCmd = &cobra.Command{
+	Use:   "train <rig> <mr-id>",
+	Short: "Gate a merge request on its release train",
+	Long: `Decide whether a tested merge request may land, given its release train.
+
+A release train is a convoy created with --train: its MRs, across all member
+rigs, land together. The Refinery runs this after an MR's tests pass. It
+marks the MR green, then checks the train:
+
+  LAND  Not in a train, or every member is green or already landed.
+        Merge as usual.
+  HOLD  Some member has no MR yet, is untested, or the convoy waits for
+        an upstream convoy. Leave the MR open and its branch intact.
+
+Held MRs show as 'held' in gt mq list. Once the last member goes green, each
+refinery sees LAND on its next pass and lands its MR.
+
+Exit status is 0 for LAND and 1 for HOLD.
+
+Examples:
+  gt mq train gastown gt-mr-abc          # Mark green, then decide
+  gt mq train gastown gt-mr-abc --check  # Decide without marking
+  gt mq train gastown gt-mr-abc --reset  # Tests failed: withdraw green`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQTrain,
+}
+
+func init() {
+	mqTrainCmd.Flags().BoolVar(&mqTrainCheck, "check", false, "Decide without marking the MR green")
+	mqTrainCmd.Flags().BoolVar(&mqTrainReset, "reset", false, "Withdraw the MR's green status (e.g. after failed tests)")
+	mqTrainCmd.Flags().BoolVar(&mqTrainJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqTrainCmd)
+}
+
+// trainDecision is the outcome of gating an MR on its release train.
+type trainDecision struct {
+	MRID     string        `json:"mr_id"`
+	ConvoyID string        `json:"convoy_id,omitempty"`
+	Land     bool          `json:"land"`
+	Reason   string        `json:"reason"`
+	Members  []trainMember `json:"members,omitempty"`
+}
+
+func runMQTrain(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	townRoot, r, err := getRig(rigName)
+	if err != nil {
+		return err
+	}
+
+	b := beads.New(r.BeadsPath())
+	mr, err := b.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("merge request '%s' not found", mrID)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+
+	if mqTrainReset {
+		if fields.TrainStatus == "" {
+			fmt.Printf("%s %s is not held\n", style.Dim.Render("○"), mrID)
+			return nil
+		}
+		fields.TrainStatus = ""
+		if err := setMRTrainFields(b, mr, fields); err != nil {
+			return err
+		}
+		fmt.Printf("%s Withdrew %s from its release train\n", style.Bold.Render("✓"), mrID)
+		return nil
+	}
+
+	townBeads := filepath.Join(townRoot, ".beads")
+	train := findTrainConvoy(townBeads, fields.SourceIssue, fields.ConvoyID)
+
+	var decision trainDecision
+	if train == nil {
+		decision = trainDecision{MRID: mrID, Land: true, Reason: "not in a release train"}
+	} else {
+		if !mqTrainCheck && (fields.TrainStatus != beads.TrainStatusGreen || fields.ConvoyID != train.ID) {
+			fields.TrainStatus = beads.TrainStatusGreen
+			fields.ConvoyID = train.ID
+			if err := setMRTrainFields(b, mr, fields); err != nil {
+				return err
+			}
+		}
+		upstreams := openConvoys(getUpstreamConvoys(townBeads, train.ID))
+		members := getTrainMembers(townRoot, getTrackedIssues(townBeads, train.ID))
+		decision = decideTrain(mrID, train.ID, upstreams, members)
+	}
+
+	if mqTrainJSON {
+		if err := outputJSON(decision); err != nil {
+			return err
+		}
+	} else {
+		printTrainDecision(decision)
+	}
+	if !decision.Land {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// setMRTrainFields writes updated MR fields back to the MR bead.
+func setMRTrainFields(b *beads.Beads, mr *beads.Issue, fields *beads.MRFields) error {
+	desc := beads.SetMRFields(mr, fields)
+	if err := b.Update(mr.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+		return fmt.Errorf("updating %s: %w", mr.ID, err)
+	}
+	return nil
+}
+
+// decideTrain lands an MR once its train's upstream convoys have landed and
+// every member is green or landed.
+func decideTrain(mrID, convoyID string, upstreams []convoyRef, members []trainMember) trainDecision {
+	d := trainDecision{MRID: mrID, ConvoyID: convoyID, Members: members}
+	if len(upstreams) > 0 {
+		d.Reason = "waits for upstream convoy " + strings.Join(convoyRefIDs(upstreams), ", ")
+		return d
+	}
+	if waiting := trainWaitingOn(members); len(waiting) > 0 {
+		names := make([]string, 0, len(waiting))
+		for _, m := range waiting {
+			names = append(names, fmt.Sprintf("%s (%s)", m.IssueID, m.State))
+		}
+		d.Reason = "waiting on " + strings.Join(names, ", ")
+		return d
+	}
+	d.Land = true
+	d.Reason = fmt.Sprintf("all %d members green", len(members))
+	return d
+}
+
+func printTrainDecision(d trainDecision) {
+	if d.ConvoyID == "" {
+		fmt.Printf("%s %s: %s\n", style.Success.Render("LAND"), d.MRID, d.Reason)
+		return
+	}
+	if d.Land {
+		fmt.Printf("%s %s: train %s %s\n", style.Success.Render("LAND"), d.MRID, d.ConvoyID, d.Reason)
+	} else {
+		fmt.Printf("%s %s: train %s %s\n", style.Warning.Render("HOLD"), d.MRID, d.ConvoyID, d.Reason)
+	}
+	for _, m := range d.Members {
+		fmt.Printf("  %s %-12s %-10s %s\n", trainStateIcon(m.State), m.IssueID, m.Rig, style.Dim.Render(m.State))
+	}
+}
+
+// trainStateIcon returns the status symbol for a train member state.
+func trainStateIcon(state string) string {
+	switch state {
+	case trainMemberLanded:
+		return style.Success.Render("✓")
+	case trainMemberGreen:
+		return style.Success.Render("●")
+	case trainMemberPending:
+		return style.Warning.Render("⧖")
+	default:
+		return style.Dim.Render("○")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dnd"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
@@ -17,11 +18,20 @@ import (
 
 var nudgeMessageFlag string
 var nudgeForceFlag bool
+var nudgeUrgentFlag bool
+var nudgeWaitAckFlag bool
+var nudgeAckTimeoutFlag time.Duration
+var nudgeRetriesFlag int
 
 func init() {
 	rootCmd.AddCommand(nudgeCmd)
 	nudgeCmd.Flags().StringVarP(&nudgeMessageFlag, "message", "m", "", "Message to send")
 	nudgeCmd.Flags().BoolVarP(&nudgeForceFlag, "force", "f", false, "Send even if target has DND enabled")
+	nudgeCmd.Flags().BoolVar(&nudgeUrgentFlag, "urgent", false, "Send even if target is in a quiet window")
+	nudgeCmd.Flags().BoolVar(&nudgeWaitAckFlag, "wait-ack", false, "Wait for receipt, retry, and fall back to mail if unconfirmed")
+	nudgeCmd.Flags().DurationVar(&nudgeAckTimeoutFlag, "ack-timeout", 30*time.Second, "How long each attempt waits for receipt (with --wait-ack)")
+	nudgeCmd.Flags().IntVar(&nudgeRetriesFlag, "retries", 3, "Delivery attempts before falling back to mail (with --wait-ack)")
+	nudgeCmd.AddCommand(nudgeAckCmd)
 }
 
 var nudgeCmd = &cobra.Command{
@@ -53,17 +63,43 @@ DND (Do Not Disturb):
   If the target has DND enabled (gt dnd on), the nudge is skipped.
   Use --force to override DND and send anyway.
 
+Quiet policies:
+  Targets covered by an active quiet policy ("quiet_policies" in
+  ~/gt/config/messaging.json, e.g. quiet hours) don't get the nudge now:
+  it is held and delivered with others as a digest when the window ends.
+  Use --urgent (or --force) to deliver immediately.
+
+Acknowledgement (--wait-ack):
+  tmux accepting the keystrokes doesn't mean the agent saw them. With
+  --wait-ack the nudge carries an ID and an ack token; receipt is confirmed
+  when the agent runs 'gt nudge ack <id>' or the token scrolls up out of
+  the input area. Unconfirmed nudges are retried with backoff, then mailed
+  to the target so they aren't lost. Exits 1 if the nudge was not confirmed.
+
 Examples:
   gt nudge greenplace/furiosa "Check your mail and start working"
   gt nudge greenplace/alpha -m "What's your status?"
   gt nudge mayor "Status update requested"
   gt nudge witness "Check polecat health"
   gt nudge deacon session-started
-  gt nudge channel:workers "New priority work available"`,
+  gt nudge channel:workers "New priority work available"
+  gt nudge greenplace/alpha --wait-ack "Rebase onto main before submitting"`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runNudge,
 }
 
+var nudgeAckCmd = &cobra.Command{
+	Use:   "ack <nudge-id>",
+	Short: "Acknowledge receipt of a nudge",
+	Long: `Acknowledges a nudge sent with delivery tracking.
+
+Tracked nudges end with a token like "[nudge-1a2b3c4d: ack with 'gt nudge ack
+nudge-1a2b3c4d']". Running the command tells the sender the nudge arrived, so
+it stops retrying and doesn't fall back to mail.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNudgeAck,
+}
+
 func runNudge(cmd *cobra.Command, args []string) error {
 	target := args[0]
 
@@ -119,9 +155,11 @@ func runNudge(cmd *cobra.Command, args []string) error {
 	}
 
 	t := tmux.NewTmux()
+	urgent := nudgeUrgentFlag || nudgeForceFlag
 
 	// Expand role shortcuts to session names
 	// These shortcuts let users type "mayor" instead of "gt-mayor"
+	address := target
 	switch target {
 	case "mayor":
 		target = session.MayorSessionName()
@@ -134,6 +172,7 @@ func runNudge(cmd *cobra.Command, args []string) error {
 		if roleInfo.Rig == "" {
 			return fmt.Errorf("cannot determine rig for %s shortcut (not in a rig context)", target)
 		}
+		address = roleInfo.Rig + "/" + target
 		if target == "witness" {
 			target = session.WitnessSessionName(roleInfo.Rig)
 		} else {
@@ -155,7 +194,12 @@ func runNudge(cmd *cobra.Command, args []string) error {
 			return nil
 		}
 
-		if err := t.NudgeSession(deaconSession, message); err != nil {
+		if decision, err := holdForQuietPolicy(townRoot, "deacon", deaconSession, message, urgent); err != nil || decision.Quiet {
+			return reportHeldNudge("deacon", decision, err)
+		}
+
+		rec, err := deliverNudge(t, townRoot, "deacon/", deaconSession, sender, message)
+		if err != nil {
 			return fmt.Errorf("nudging deacon: %w", err)
 		}
 
@@ -166,7 +210,7 @@ func runNudge(cmd *cobra.Command, args []string) error {
 			_ = LogNudge(townRoot, "deacon", message)
 		}
 		_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload("", "deacon", message))
-		return nil
+		return reportNudgeReceipt(cmd, rec)
 	}
 
 	// Check if target is rig/polecat format or raw session name
@@ -193,8 +237,13 @@ func runNudge(cmd *cobra.Command, args []string) error {
 			sessionName = mgr.SessionName(polecatName)
 		}
 
+		if decision, err := holdForQuietPolicy(townRoot, target, sessionName, message, urgent); err != nil || decision.Quiet {
+			return reportHeldNudge(target, decision, err)
+		}
+
 		// Send nudge using the reliable NudgeSession
-		if err := t.NudgeSession(sessionName, message); err != nil {
+		rec, err := deliverNudge(t, townRoot, target, sessionName, sender, message)
+		if err != nil {
 			return fmt.Errorf("nudging session: %w", err)
 		}
 
@@ -205,6 +254,7 @@ func runNudge(cmd *cobra.Command, args []string) error {
 			_ = LogNudge(townRoot, target, message)
 		}
 		_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload(rigName, target, message))
+		return reportNudgeReceipt(cmd, rec)
 	} else {
 		// Raw session name (legacy)
 		exists, err := t.HasSession(target)
@@ -215,7 +265,12 @@ func runNudge(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("session %q not found", target)
 		}
 
-		if err := t.NudgeSession(target, message); err != nil {
+		if decision, err := holdForQuietPolicy(townRoot, address, target, message, urgent); err != nil || decision.Quiet {
+			return reportHeldNudge(address, decision, err)
+		}
+
+		rec, err := deliverNudge(t, townRoot, mailFallbackAddress(address), target, sender, message)
+		if err != nil {
 			return fmt.Errorf("nudging session: %w", err)
 		}
 
@@ -226,9 +281,8 @@ func runNudge(cmd *cobra.Command, args []string) error {
 			_ = LogNudge(townRoot, target, message)
 		}
 		_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload("", target, message))
+		return reportNudgeReceipt(cmd, rec)
 	}
-
-	return nil
 }
 
 // runNudgeChannel nudges all members of a named channel.
@@ -312,7 +366,14 @@ func runNudgeChannel(channelName, message string) error {
 
 	fmt.Printf("Nudging channel %q (%d target(s))...\n\n", channelName, len(targets))
 
+	var held int
 	for i, sessionName := range targets {
+		address := sessionAddress(sessionName, agents)
+		if decision, err := holdForQuietPolicy(townRoot, address, sessionName, prefixedMessage, nudgeUrgentFlag); err == nil && decision.Quiet {
+			held++
+			fmt.Printf("  %s %s %s\n", style.Dim.Render("○"), sessionName, style.Dim.Render("(held: "+decision.Reason+")"))
+			continue
+		}
 		if err := t.NudgeSession(sessionName, prefixedMessage); err != nil {
 			failed++
 			failures = append(failures, fmt.Sprintf("%s: %v", sessionName, err))
@@ -343,6 +404,9 @@ func runNudgeChannel(channelName, message string) error {
 	}
 
 	fmt.Printf("%s Channel nudge complete: %d target(s) nudged\n", style.SuccessPrefix, succeeded)
+	if held > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("%d held for quiet-window digest", held)))
+	}
 	return nil
 }
 
@@ -447,6 +511,41 @@ func shouldNudgeTarget(townRoot, targetAddress string, force bool) (bool, string
 	return level != beads.NotifyMuted, level, nil
 }
 
+// holdForQuietPolicy holds a nudge for the target's digest if one of its
+// quiet policies is active, and returns the policy decision. Urgent nudges
+// are never held.
+func holdForQuietPolicy(townRoot, address, sessionName, message string, urgent bool) (dnd.Decision, error) {
+	if urgent || townRoot == "" {
+		return dnd.Decision{}, nil
+	}
+	decision := dnd.Check(townRoot, address, time.Now())
+	if !decision.Quiet {
+		return decision, nil
+	}
+	return decision, dnd.Hold(townRoot, dnd.HeldNudge{Address: address, Session: sessionName, Message: message})
+}
+
+// reportHeldNudge reports a nudge held by a quiet policy.
+func reportHeldNudge(target string, decision dnd.Decision, err error) error {
+	if err != nil {
+		return fmt.Errorf("holding nudge: %w", err)
+	}
+	fmt.Printf("%s %s is in a quiet window (%s) - nudge held for digest\n", style.Dim.Render("○"), target, decision.Reason)
+	fmt.Printf("  Use %s to deliver now\n", style.Bold.Render("--urgent"))
+	return nil
+}
+
+// sessionAddress returns the agent address for a session name, or the
+// session name itself for sessions that aren't known agents.
+func sessionAddress(sessionName string, agents []*AgentSession) string {
+	for _, agent := range agents {
+		if agent.Name == sessionName {
+			return formatAgentName(agent)
+		}
+	}
+	return sessionName
+}
+
 // addressToAgentBeadID converts a target address to an agent bead ID.
 // Examples:
 //   - "mayor" -> "gt-{town}-mayor"
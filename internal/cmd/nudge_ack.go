@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/nudge"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// deliverNudge sends a nudge to a session. With --wait-ack it goes through
+// the tracked delivery layer and returns its record; otherwise it is a
+// plain NudgeSession and the record is nil.
+func deliverNudge(t *tmux.Tmux, townRoot, address, sessionName, sender, message string) (*nudge.Record, error) {
+	if !nudgeWaitAckFlag || townRoot == "" {
+		return nil, t.NudgeSession(sessionName, message)
+	}
+
+	fmt.Printf("%s Waiting for %s to confirm receipt...\n", style.Dim.Render("⏳"), sessionName)
+	rec, err := nudge.NewDeliverer(townRoot, t).Send(nudge.Request{
+		Session: sessionName,
+		Address: address,
+		From:    sender,
+		Message: message,
+	}, nudge.Options{
+		Attempts:     nudgeRetriesFlag,
+		AckTimeout:   nudgeAckTimeoutFlag,
+		MailFallback: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// reportNudgeReceipt prints the outcome of a tracked nudge and exits
+// non-zero when receipt wasn't confirmed.
+func reportNudgeReceipt(cmd *cobra.Command, rec *nudge.Record) error {
+	if rec == nil {
+		return nil
+	}
+
+	switch rec.Status {
+	case nudge.StatusAcked:
+		fmt.Printf("%s Receipt acknowledged (%s, attempt %d)\n", style.Success.Render("✓"), rec.ID, rec.Attempts)
+		return nil
+	case nudge.StatusSeen:
+		fmt.Printf("%s Receipt confirmed in pane (%s, attempt %d)\n", style.Success.Render("✓"), rec.ID, rec.Attempts)
+		return nil
+	case nudge.StatusMailed:
+		fmt.Printf("%s Not confirmed after %d attempt(s) - sent as mail to %s\n", style.WarningPrefix, rec.Attempts, rec.Address)
+	default:
+		fmt.Printf("%s Not confirmed after %d attempt(s) (%s)\n", style.ErrorPrefix, rec.Attempts, rec.ID)
+		if rec.Error != "" {
+			fmt.Printf("  %s\n", style.Dim.Render(rec.Error))
+		}
+	}
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return NewSilentExit(1)
+}
+
+// mailFallbackAddress returns the mail address for a nudge target, or ""
+// for raw session names that have no mailbox.
+func mailFallbackAddress(target string) string {
+	switch target {
+	case "mayor", "deacon":
+		return target + "/"
+	}
+	if strings.Contains(target, "/") {
+		return target
+	}
+	return ""
+}
+
+// runNudgeAck acknowledges receipt of a tracked nudge.
+func runNudgeAck(cmd *cobra.Command, args []string) error {
+	id := args[0]
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	rec, err := nudge.Ack(townRoot, id)
+	if err != nil {
+		if errors.Is(err, nudge.ErrNotFound) {
+			return fmt.Errorf("no tracked nudge %s", id)
+		}
+		return fmt.Errorf("acknowledging %s: %w", id, err)
+	}
+
+	fmt.Printf("%s Acknowledged %s\n", style.Bold.Render("✓"), rec.ID)
+	return nil
+}
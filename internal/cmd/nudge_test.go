@@ -110,3 +110,18 @@ func TestResolveNudgePattern(t *testing.T) {
 		})
 	}
 }
+
+func TestMailFallbackAddress(t *testing.T) {
+	tests := map[string]string{
+		"mayor":           "mayor/",
+		"deacon":          "deacon/",
+		"gastown/alpha":   "gastown/alpha",
+		"gastown/witness": "gastown/witness",
+		"gt-gastown-beta": "",
+	}
+	for target, want := range tests {
+		if got := mailFallbackAddress(target); got != want {
+			t.Errorf("mailFallbackAddress(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
@@ -350,6 +350,19 @@ func outputPluginShowText(p *plugin.Plugin) error {
 		}
 	}
 
+	// Doctor check
+	if p.Doctor != nil {
+		fmt.Println()
+		fmt.Printf("%s\n", style.Bold.Render("Doctor check:"))
+		fmt.Printf("  Run: %s\n", p.Doctor.Run)
+		if p.Doctor.Fix != "" {
+			fmt.Printf("  Fix: %s\n", p.Doctor.Fix)
+		}
+		if p.Doctor.Category != "" {
+			fmt.Printf("  Category: %s\n", p.Doctor.Category)
+		}
+	}
+
 	// Instructions preview
 	if p.Instructions != "" {
 		fmt.Println()
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Polecat pool flags
+var (
+	polecatPoolFill    bool
+	polecatPoolRefresh bool
+	polecatPoolDrain   bool
+	polecatPoolJSON    bool
+)
+
+var polecatPoolCmd = &cobra.Command{
+	Use:   "pool <rig>",
+	Short: "Show or manage warm spare worktrees for fast spawn",
+	Long: `Show or manage a rig's pool of warm spare worktrees.
+
+Spares are idle git worktrees, already checked out at origin's tip, that
+polecat spawn claims instead of creating a worktree from scratch. For large
+repos this turns a multi-second checkout into a rename.
+
+The pool is configured in <rig>/settings/config.json:
+
+  "worktree_pool": {
+    "size": 4,
+    "refresh_interval": "15m"
+  }
+
+The daemon replenishes the pool and re-syncs spares to origin on every
+heartbeat. Use the flags below to do the same by hand.
+
+Examples:
+  gt polecat pool greenplace            # Show spares
+  gt polecat pool greenplace --fill     # Create missing spares now
+  gt polecat pool greenplace --refresh  # Re-sync stale spares to origin
+  gt polecat pool greenplace --drain    # Remove all spares`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolecatPool,
+}
+
+func init() {
+	polecatPoolCmd.Flags().BoolVar(&polecatPoolFill, "fill", false, "Create spares up to the configured size")
+	polecatPoolCmd.Flags().BoolVar(&polecatPoolRefresh, "refresh", false, "Re-sync stale spares to origin")
+	polecatPoolCmd.Flags().BoolVar(&polecatPoolDrain, "drain", false, "Remove all spares")
+	polecatPoolCmd.Flags().BoolVar(&polecatPoolJSON, "json", false, "Output as JSON")
+
+	polecatCmd.AddCommand(polecatPoolCmd)
+}
+
+// PolecatPoolOutput is the JSON output for gt polecat pool.
+type PolecatPoolOutput struct {
+	Rig             string          `json:"rig"`
+	Size            int             `json:"size"`
+	RefreshInterval string          `json:"refresh_interval"`
+	Spares          []polecat.Spare `json:"spares"`
+}
+
+func runPolecatPool(cmd *cobra.Command, args []string) error {
+	if polecatPoolDrain && (polecatPoolFill || polecatPoolRefresh) {
+		return fmt.Errorf("--drain cannot be combined with --fill or --refresh")
+	}
+
+	mgr, r, err := getPolecatManager(args[0])
+	if err != nil {
+		return err
+	}
+	pool := mgr.WorktreePool()
+
+	if polecatPoolDrain {
+		removed, err := pool.Drain()
+		if err != nil {
+			return fmt.Errorf("draining pool: %w", err)
+		}
+		fmt.Printf("%s Removed %d spare(s) from %s\n", style.Bold.Render("✓"), removed, r.Name)
+		if pool.Size() > 0 {
+			fmt.Printf("  %s\n", style.Dim.Render("The daemon will refill the pool; set worktree_pool.size to 0 to disable it"))
+		}
+		return nil
+	}
+
+	if polecatPoolFill {
+		if pool.Size() == 0 {
+			return fmt.Errorf("worktree pool is disabled for %s (set worktree_pool.size in settings/config.json)", r.Name)
+		}
+		created, err := pool.Replenish()
+		if err != nil {
+			return fmt.Errorf("filling pool: %w", err)
+		}
+		if !polecatPoolJSON {
+			fmt.Printf("%s Created %d spare(s)\n", style.Bold.Render("✓"), created)
+		}
+	}
+
+	if polecatPoolRefresh {
+		refreshed, err := pool.Refresh()
+		if err != nil {
+			return fmt.Errorf("refreshing pool: %w", err)
+		}
+		if !polecatPoolJSON {
+			fmt.Printf("%s Refreshed %d spare(s)\n", style.Bold.Render("✓"), refreshed)
+		}
+	}
+
+	spares, err := pool.List()
+	if err != nil {
+		return err
+	}
+
+	if polecatPoolJSON {
+		if spares == nil {
+			spares = []polecat.Spare{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(PolecatPoolOutput{
+			Rig:             r.Name,
+			Size:            pool.Size(),
+			RefreshInterval: pool.RefreshInterval().String(),
+			Spares:          spares,
+		})
+	}
+
+	if polecatPoolFill || polecatPoolRefresh {
+		fmt.Println()
+	}
+	fmt.Printf("%s %s: %d/%d spares %s\n", style.Bold.Render("Worktree pool"), r.Name,
+		len(spares), pool.Size(), style.Dim.Render(fmt.Sprintf("(refresh every %s)", pool.RefreshInterval())))
+	if pool.Size() == 0 && len(spares) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("Disabled (set worktree_pool.size in settings/config.json)"))
+		return nil
+	}
+	for _, s := range spares {
+		commit := s.Commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		fmt.Printf("  ● %s  %s  synced %s ago\n", s.ID, commit, time.Since(s.SyncedAt).Round(time.Second))
+	}
+	return nil
+}
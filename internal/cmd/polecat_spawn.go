@@ -38,6 +38,7 @@ type SlingSpawnOptions struct {
 	Create   bool   // Create polecat if it doesn't exist (currently always true for sling)
 	HookBead string // Bead ID to set as hook_bead at spawn time (atomic assignment)
 	Agent    string // Agent override for this spawn (e.g., "gemini", "codex", "claude-haiku")
+	Tier     string // Model tier of the hooked step (haiku, sonnet, opus); ignored if Agent is set
 }
 
 // SpawnPolecatForSling creates a fresh polecat and optionally starts its session.
@@ -141,6 +142,10 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 				return nil, err
 			}
 			startOpts.Command = cmd
+		} else if opts.Tier != "" {
+			cmd, agent := config.BuildPolecatStartupCommandForTier(rigName, polecatName, r.Path, "", opts.Tier)
+			fmt.Printf("Tier %s: using %s\n", opts.Tier, agent)
+			startOpts.Command = cmd
 		}
 		if err := polecatSessMgr.Start(polecatName, startOpts); err != nil {
 			return nil, fmt.Errorf("starting session: %w", err)
@@ -22,6 +22,7 @@ var primeHookMode bool
 var primeDryRun bool
 var primeState bool
 var primeExplain bool
+var primeOnlySection string
 
 // Role represents a detected agent role.
 type Role string
@@ -61,7 +62,18 @@ HOOK MODE (--hook):
   Claude Code sends JSON on stdin:
     {"session_id": "uuid", "transcript_path": "/path", "source": "startup|resume"}
 
-  Other agents can set GT_SESSION_ID environment variable instead.`,
+  Other agents can set GT_SESSION_ID environment variable instead.
+
+CONTEXT BUDGET:
+  Output is assembled from prioritized sections. When settings/config.json
+  sets a prime budget (estimated tokens, ~4 chars each), low-priority sections
+  are summarized and then replaced by a pointer to the command that shows them:
+
+    "prime": {"budget": 8000, "budgets": {"polecat": 6000, "witness/gemini": 3000}}
+
+  Budget keys are role, agent runtime, or role/runtime; the most specific wins.
+  Use --explain to see what was included, summarized or omitted, and
+  --section <name> to print one section in full.`,
 	RunE: runPrime,
 }
 
@@ -73,7 +85,9 @@ func init() {
 	primeCmd.Flags().BoolVar(&primeState, "state", false,
 		"Show detected session state only (normal/post-handoff/crash/autonomous)")
 	primeCmd.Flags().BoolVar(&primeExplain, "explain", false,
-		"Show why each section was included")
+		"Show why each section was included and how the context budget applied")
+	primeCmd.Flags().StringVar(&primeOnlySection, "section", "",
+		"Print one section in full, ignoring the budget (implies --dry-run): "+strings.Join(primeSectionNames, ", "))
 	rootCmd.AddCommand(primeCmd)
 }
 
@@ -86,6 +100,13 @@ func runPrime(cmd *cobra.Command, args []string) error {
 	if primeState && (primeHookMode || primeDryRun || primeExplain) {
 		return fmt.Errorf("--state cannot be combined with other flags")
 	}
+	if primeOnlySection != "" {
+		if !validPrimeSection(primeOnlySection) {
+			return fmt.Errorf("unknown section %q (valid: %s)", primeOnlySection, strings.Join(primeSectionNames, ", "))
+		}
+		// A single section is a lookup, not a session start
+		primeDryRun = true
+	}
 
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -192,57 +213,84 @@ func runPrime(cmd *cobra.Command, args []string) error {
 		emitSessionEvent(ctx)
 	}
 
+	// Assemble prioritized sections, then fit them to the context budget
+	asm := &primeAssembler{only: primeOnlySection}
+
 	// Output session metadata for seance discovery
-	explain(true, "Session metadata: always included for seance discovery")
-	outputSessionMetadata(ctx)
+	asm.add("session", primePriorityCritical, "", func() {
+		explain(true, "Session metadata: always included for seance discovery")
+		outputSessionMetadata(ctx)
+	})
 
 	// Output context
-	explain(true, fmt.Sprintf("Role context: detected role is %s", ctx.Role))
-	if err := outputPrimeContext(ctx); err != nil {
-		return err
+	var contextErr error
+	asm.add("role", primePriorityCritical, "", func() {
+		explain(true, fmt.Sprintf("Role context: detected role is %s", ctx.Role))
+		contextErr = outputPrimeContext(ctx)
+	})
+	if contextErr != nil {
+		return contextErr
 	}
 
 	// Output handoff content if present
-	outputHandoffContent(ctx)
+	asm.add("handoff", primePriorityHigh, "", func() { outputHandoffContent(ctx) })
 
 	// Output attachment status (for autonomous work detection)
-	outputAttachmentStatus(ctx)
+	asm.add("attachment", primePriorityHigh, "", func() { outputAttachmentStatus(ctx) })
 
 	// Check for slung work on hook (from gt sling)
 	// If found, we're in autonomous mode - skip normal startup directive
-	hasSlungWork := checkSlungWork(ctx)
-	explain(hasSlungWork, "Autonomous mode: hooked/in-progress work detected")
+	var hasSlungWork bool
+	asm.add("work", primePriorityCritical, "gt hook", func() {
+		hasSlungWork = checkSlungWork(ctx)
+		explain(hasSlungWork, "Autonomous mode: hooked/in-progress work detected")
+	})
 
 	// Output molecule context if working on a molecule step
-	outputMoleculeContext(ctx)
+	asm.add("molecule", primePriorityHigh, "gt mol status", func() { outputMoleculeContext(ctx) })
 
 	// Output previous session checkpoint for crash recovery
-	outputCheckpointContext(ctx)
+	asm.add("checkpoint", primePriorityNormal, "", func() { outputCheckpointContext(ctx) })
 
 	// Run bd prime to output beads workflow context
-	if !primeDryRun {
-		runBdPrime(cwd)
-	} else {
-		explain(true, "bd prime: skipped in dry-run mode")
-	}
+	asm.add("beads", primePriorityLow, "bd prime", func() {
+		if !primeDryRun {
+			runBdPrime(cwd)
+		} else {
+			explain(true, "bd prime: skipped in dry-run mode")
+		}
+	})
 
 	// Run gt mail check --inject to inject any pending mail
-	if !primeDryRun {
-		runMailCheckInject(cwd)
-	} else {
-		explain(true, "gt mail check --inject: skipped in dry-run mode")
-	}
+	asm.add("mail", primePriorityNormal, "gt mail inbox", func() {
+		if !primeDryRun {
+			runMailCheckInject(cwd)
+		} else {
+			explain(true, "gt mail check --inject: skipped in dry-run mode")
+		}
+	})
 
 	// For Mayor, check for pending escalations
 	if ctx.Role == RoleMayor {
-		checkPendingEscalations(ctx)
+		asm.add("escalations", primePriorityNormal, "bd list --status=open --tag=escalation", func() {
+			checkPendingEscalations(ctx)
+		})
 	}
 
 	// Output startup directive for roles that should announce themselves
 	// Skip if in autonomous mode (slung work provides its own directive)
 	if !hasSlungWork {
-		explain(true, "Startup directive: normal mode (no hooked work)")
-		outputStartupDirective(ctx)
+		asm.add("startup", primePriorityCritical, "", func() {
+			explain(true, "Startup directive: normal mode (no hooked work)")
+			outputStartupDirective(ctx)
+		})
+	}
+
+	budget, key, runtime := resolvePrimeBudget(ctx)
+	asm.apply(budget)
+	asm.write()
+	if primeOnlySection == "" {
+		asm.explainBudget(budget, key, string(ctx.Role), runtime)
 	}
 
 	return nil
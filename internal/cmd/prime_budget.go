@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// primePriority orders prime sections for budgeting. Lower priorities are
+// summarized, then omitted, first; critical sections are always kept whole.
+type primePriority int
+
+const (
+	primePriorityLow primePriority = iota
+	primePriorityNormal
+	primePriorityHigh
+	primePriorityCritical
+)
+
+func (p primePriority) String() string {
+	switch p {
+	case primePriorityLow:
+		return "low"
+	case primePriorityNormal:
+		return "normal"
+	case primePriorityHigh:
+		return "high"
+	default:
+		return "critical"
+	}
+}
+
+// Budget actions applied to a section.
+const (
+	primeActionIncluded   = "included"
+	primeActionSummarized = "summarized"
+	primeActionOmitted    = "omitted"
+	primeActionEmpty      = "empty"
+)
+
+// primeSummaryLines is how many lines of a section survive summarizing.
+const primeSummaryLines = 12
+
+// primeSection is one block of gt prime output.
+type primeSection struct {
+	name     string
+	priority primePriority
+	pointer  string // command that shows the full section
+	output   string // captured output, rewritten by the budget
+	tokens   int    // estimated tokens of the original output
+	action   string
+}
+
+// primeSectionNames lists the sections in output order, for --section.
+var primeSectionNames = []string{
+	"session", "role", "handoff", "attachment", "work", "molecule",
+	"checkpoint", "beads", "mail", "escalations", "startup",
+}
+
+// primeAssembler collects prime sections and applies the context budget.
+type primeAssembler struct {
+	sections []*primeSection
+	only     string // --section: collect just this one, unbudgeted
+}
+
+// add runs fn with stdout captured and records its output as a section.
+func (a *primeAssembler) add(name string, priority primePriority, pointer string, fn func()) {
+	if a.only != "" && a.only != name {
+		return
+	}
+	if pointer == "" {
+		pointer = "gt prime --section " + name
+	}
+	out := capturePrimeOutput(fn)
+	s := &primeSection{
+		name:     name,
+		priority: priority,
+		pointer:  pointer,
+		output:   out,
+		tokens:   estimateTokens(out),
+		action:   primeActionIncluded,
+	}
+	if strings.TrimSpace(stripExplain(out)) == "" {
+		s.action = primeActionEmpty
+	}
+	a.sections = append(a.sections, s)
+}
+
+// capturePrimeOutput runs fn and returns what it wrote to stdout.
+func capturePrimeOutput(fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		fn()
+		return ""
+	}
+	old := os.Stdout
+	os.Stdout = w
+
+	// Drain concurrently so large sections can't fill the pipe and block fn
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		_ = r.Close()
+		done <- buf.String()
+	}()
+
+	func() {
+		defer func() {
+			os.Stdout = old
+			_ = w.Close()
+		}()
+		fn()
+	}()
+	return <-done
+}
+
+// estimateTokens approximates the token count of prime output at four
+// characters per token. --explain annotations don't count.
+func estimateTokens(s string) int {
+	return (len(stripExplain(s)) + 3) / 4
+}
+
+// stripExplain removes --explain annotations from section output.
+func stripExplain(s string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if !strings.HasPrefix(line, "[EXPLAIN]") {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// total returns the estimated tokens of the sections as currently rewritten.
+func (a *primeAssembler) total() int {
+	n := 0
+	for _, s := range a.sections {
+		n += estimateTokens(s.output)
+	}
+	return n
+}
+
+// apply fits the sections into budget tokens (0 = unlimited). Sections
+// are summarized from the lowest priority up, and if that isn't enough,
+// replaced by a pointer to the command that shows them.
+func (a *primeAssembler) apply(budget int) {
+	if budget <= 0 || a.only != "" {
+		return
+	}
+	passes := []struct {
+		action  string
+		rewrite func(*primeSection) string
+	}{
+		{primeActionSummarized, summarizePrimeSection},
+		{primeActionOmitted, omitPrimeSection},
+	}
+	for _, pass := range passes {
+		for p := primePriorityLow; p < primePriorityCritical; p++ {
+			// Later sections of a priority go first: earlier ones carry identity
+			for i := len(a.sections) - 1; i >= 0; i-- {
+				if a.total() <= budget {
+					return
+				}
+				s := a.sections[i]
+				if s.priority != p || s.action == primeActionEmpty || s.action == primeActionOmitted {
+					continue
+				}
+				out := pass.rewrite(s)
+				if estimateTokens(out) >= estimateTokens(s.output) {
+					continue
+				}
+				s.output = out
+				s.action = pass.action
+			}
+		}
+	}
+}
+
+// summarizePrimeSection keeps a section's first lines and points at the rest.
+func summarizePrimeSection(s *primeSection) string {
+	lines := strings.Split(strings.TrimRight(s.output, "\n"), "\n")
+	if len(lines) <= primeSummaryLines {
+		return s.output
+	}
+	kept := strings.Join(lines[:primeSummaryLines], "\n")
+	return fmt.Sprintf("%s\n[prime: %s truncated, %d more lines - run `%s`]\n",
+		kept, s.name, len(lines)-primeSummaryLines, s.pointer)
+}
+
+// omitPrimeSection replaces a section with a pointer to its command.
+func omitPrimeSection(s *primeSection) string {
+	return fmt.Sprintf("\n[prime: %s omitted to fit context budget - run `%s`]\n", s.name, s.pointer)
+}
+
+// write prints the assembled sections.
+func (a *primeAssembler) write() {
+	for _, s := range a.sections {
+		fmt.Print(s.output)
+	}
+}
+
+// explainBudget prints how the budget treated each section (--explain).
+func (a *primeAssembler) explainBudget(budget int, key, role, runtime string) {
+	if !primeExplain {
+		return
+	}
+	limit := "unlimited"
+	if budget > 0 {
+		limit = fmt.Sprintf("%d tokens", budget)
+	}
+	source := "prime.budget"
+	if key != "" {
+		source = fmt.Sprintf("prime.budgets[%q]", key)
+	}
+	fmt.Printf("\n[EXPLAIN] Context budget: %s (%s, role=%s runtime=%s), ~%d tokens emitted\n",
+		limit, source, role, runtime, a.total())
+	if budget > 0 && a.total() > budget {
+		fmt.Println("[EXPLAIN] Still over budget: critical sections are never summarized or omitted")
+	}
+	for _, s := range a.sections {
+		detail := fmt.Sprintf("~%d tokens", s.tokens)
+		switch s.action {
+		case primeActionSummarized, primeActionOmitted:
+			detail = fmt.Sprintf("~%d -> ~%d tokens, see `%s`", s.tokens, estimateTokens(s.output), s.pointer)
+		case primeActionEmpty:
+			detail = "nothing to show"
+		}
+		fmt.Printf("[EXPLAIN]   %-12s %-8s %-10s %s\n", s.name, s.priority, s.action, detail)
+	}
+}
+
+// resolvePrimeBudget returns the prime budget for the context's role and
+// agent runtime, with the settings key it came from.
+func resolvePrimeBudget(ctx RoleContext) (budget int, key, runtime string) {
+	var rigPath string
+	if ctx.Rig != "" {
+		rigPath = filepath.Join(ctx.TownRoot, ctx.Rig)
+	}
+	runtime, _ = config.ResolveRoleAgentName(string(ctx.Role), ctx.TownRoot, rigPath)
+
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(ctx.TownRoot))
+	if err != nil {
+		return 0, "", runtime
+	}
+	budget, key = settings.Prime.BudgetFor(string(ctx.Role), runtime)
+	return budget, key, runtime
+}
+
+// validPrimeSection reports whether name is a known prime section.
+func validPrimeSection(name string) bool {
+	for _, n := range primeSectionNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -72,12 +72,24 @@ func showMoleculeExecutionPrompt(workDir, moleculeID string) {
 		fmt.Printf("**Step ID:** %s\n", step.ID)
 		fmt.Printf("**Status:** %s (ready to execute)\n\n", step.Status)
 
-		// Show step description if available
+		// Show step description if available, with earlier steps' outputs
+		// filled in
 		if step.Description != "" {
 			fmt.Println("### Instructions")
 			fmt.Println()
+			description := step.Description
+			if strings.Contains(description, "{{") {
+				children, err := beads.New(workDir).List(beads.ListOptions{
+					Parent:   moleculeID,
+					Status:   "all",
+					Priority: -1,
+				})
+				if err == nil {
+					description = beads.ExpandStepOutputs(description, children)
+				}
+			}
 			// Indent the description for readability
-			lines := strings.Split(step.Description, "\n")
+			lines := strings.Split(description, "\n")
 			for _, line := range lines {
 				fmt.Printf("%s\n", line)
 			}
@@ -230,6 +242,35 @@ func showMoleculeProgress(b *beads.Beads, rootID string) {
 	if len(readySteps) > 0 {
 		fmt.Printf("Ready steps: %s\n", strings.Join(readySteps, ", "))
 	}
+
+	showStepOutputs(children)
+}
+
+// showStepOutputs lists the outputs molecule steps have recorded, so they
+// reach later steps across handoffs.
+func showStepOutputs(children []*beads.Issue) {
+	var lines []string
+	for _, child := range children {
+		ref := beads.ParseStepControl(child.Description).Ref
+		if ref == "" {
+			ref = child.ID
+		}
+		outputs := beads.StepOutputs(child.Description)
+		for _, key := range sortedKeys(outputs) {
+			value := outputs[key]
+			if first, _, multi := strings.Cut(value, "\n"); multi {
+				value = first + " …"
+			}
+			lines = append(lines, fmt.Sprintf("  {{steps.%s.%s}} = %s", ref, key, value))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Println("Step outputs:")
+	for _, line := range lines {
+		fmt.Println(line)
+	}
 }
 
 // outputDeaconPatrolContext shows patrol molecule status for the Deacon.
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -167,3 +168,72 @@ func TestPrimeFlagCombinations(t *testing.T) {
 		})
 	}
 }
+
+func TestPrimeAssemblerBudget(t *testing.T) {
+	long := strings.Repeat("beads workflow line\n", 40)
+	newAssembler := func() *primeAssembler {
+		a := &primeAssembler{}
+		a.add("role", primePriorityCritical, "", func() { fmt.Print(strings.Repeat("role context line\n", 20)) })
+		a.add("checkpoint", primePriorityNormal, "", func() { fmt.Print(strings.Repeat("checkpoint line\n", 20)) })
+		a.add("beads", primePriorityLow, "bd prime", func() { fmt.Print(long) })
+		a.add("mail", primePriorityNormal, "gt mail inbox", func() {})
+		return a
+	}
+
+	t.Run("unlimited", func(t *testing.T) {
+		a := newAssembler()
+		a.apply(0)
+		if a.sections[2].output != long || a.sections[2].action != primeActionIncluded {
+			t.Errorf("beads section changed without a budget: %s", a.sections[2].action)
+		}
+		if a.sections[3].action != primeActionEmpty {
+			t.Errorf("mail action = %s, want empty", a.sections[3].action)
+		}
+	})
+
+	t.Run("summarizes low priority first", func(t *testing.T) {
+		a := newAssembler()
+		a.apply(a.total() - 50)
+		if got := a.sections[2].action; got != primeActionSummarized {
+			t.Fatalf("beads action = %s, want summarized", got)
+		}
+		if !strings.Contains(a.sections[2].output, "run `bd prime`") {
+			t.Errorf("summary lacks pointer: %q", a.sections[2].output)
+		}
+		if got := a.sections[1].action; got != primeActionIncluded {
+			t.Errorf("checkpoint action = %s, want included", got)
+		}
+	})
+
+	t.Run("omits before touching critical", func(t *testing.T) {
+		a := newAssembler()
+		a.apply(1)
+		if got := a.sections[0].action; got != primeActionIncluded {
+			t.Errorf("critical role section action = %s", got)
+		}
+		for _, s := range a.sections[1:3] {
+			if s.action != primeActionOmitted {
+				t.Errorf("%s action = %s, want omitted", s.name, s.action)
+			}
+			if !strings.Contains(s.output, "omitted to fit context budget") {
+				t.Errorf("%s output = %q", s.name, s.output)
+			}
+		}
+	})
+
+	t.Run("single section", func(t *testing.T) {
+		a := &primeAssembler{only: "beads"}
+		a.add("role", primePriorityCritical, "", func() { fmt.Print("role\n") })
+		a.add("beads", primePriorityLow, "bd prime", func() { fmt.Print(long) })
+		a.apply(1)
+		if len(a.sections) != 1 || a.sections[0].output != long {
+			t.Errorf("--section should collect beads in full, got %d sections", len(a.sections))
+		}
+	})
+}
+
+func TestEstimateTokensSkipsExplain(t *testing.T) {
+	if got := estimateTokens("abcdefgh\n[EXPLAIN] ignored annotation\n"); got != 3 {
+		t.Errorf("estimateTokens = %d, want 3", got)
+	}
+}
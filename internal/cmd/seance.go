@@ -1,12 +1,10 @@
 package cmd
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -54,7 +52,8 @@ The --talk flag spawns: claude --fork-session --resume <id>
 This loads the predecessor's full context without modifying their session.
 
 Sessions are discovered from:
-  1. Events emitted by SessionStart hooks (~/gt/.events.jsonl)
+  1. Events emitted by SessionStart hooks (~/gt/.events.jsonl and its
+     rotated segments in ~/gt/.events/)
   2. The [GAS TOWN] beacon makes sessions searchable in /resume`,
 	RunE: runSeance,
 }
@@ -232,43 +231,28 @@ func runSeanceTalk(sessionID, prompt string) error {
 	return nil
 }
 
-// discoverSessions reads session_start events from our event stream.
+// discoverSessions reads session_start events from our event stream,
+// including rotated segments.
 func discoverSessions(townRoot string) ([]sessionEvent, error) {
-	eventsPath := filepath.Join(townRoot, events.EventsFile)
-
-	file, err := os.Open(eventsPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	defer file.Close()
-
 	var sessions []sessionEvent
-	scanner := bufio.NewScanner(file)
-
-	// Increase buffer for large lines
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
+	err := events.Scan(townRoot, time.Time{}, func(line []byte) error {
 		var event sessionEvent
-		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
-			continue
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil
 		}
 
 		if event.Type == events.TypeSessionStart {
 			sessions = append(sessions, event)
 		}
-	}
+		return nil
+	})
 
 	// Sort by timestamp descending (most recent first)
 	sort.Slice(sessions, func(i, j int) bool {
 		return sessions[i].Timestamp > sessions[j].Timestamp
 	})
 
-	return sessions, scanner.Err()
+	return sessions, err
 }
 
 func getPayloadString(payload map[string]interface{}, key string) string {
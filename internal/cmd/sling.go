@@ -237,6 +237,8 @@ func runSling(cmd *cobra.Command, args []string) error {
 					HookBead: beadID, // Set atomically at spawn time
 					Agent:    slingAgent,
 				}
+				// A step bead's tier picks the polecat's model
+				spawnOpts.Tier = slingTier(beadID)
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
 					return fmt.Errorf("spawning polecat: %w", spawnErr)
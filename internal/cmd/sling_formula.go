@@ -81,6 +81,11 @@ func runSlingFormula(args []string) error {
 	}
 	townBeadsDir := filepath.Join(townRoot, ".beads")
 
+	// Catch bad vars before a polecat or dog is spawned for them
+	if err := validateSlingFormulaVars(formulaName, slingVars); err != nil {
+		return err
+	}
+
 	// Determine target (self or specified)
 	var target string
 	if len(args) > 1 {
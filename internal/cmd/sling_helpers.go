@@ -16,9 +16,10 @@ import (
 
 // beadInfo holds status and assignee for a bead.
 type beadInfo struct {
-	Title    string `json:"title"`
-	Status   string `json:"status"`
-	Assignee string `json:"assignee"`
+	Title       string `json:"title"`
+	Status      string `json:"status"`
+	Assignee    string `json:"assignee"`
+	Description string `json:"description"`
 }
 
 // verifyBeadExists checks that the bead exists using bd show.
@@ -76,6 +77,43 @@ func getBeadInfo(beadID string) (*beadInfo, error) {
 	return &infos[0], nil
 }
 
+// slingTier returns the model tier for a slung bead: a step bead's own
+// tier, or for a molecule root (or a bead with a molecule attached) the
+// tier of the molecule's first ready step. Returns "" when there is none.
+func slingTier(beadID string) string {
+	info, err := getBeadInfo(beadID)
+	if err != nil {
+		return ""
+	}
+	ctl := beads.ParseStepControl(info.Description)
+	if ctl.Tier != "" || ctl.Ref != "" {
+		return ctl.Tier
+	}
+
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return ""
+	}
+	moleculeID := beadID
+	if fields := beads.ParseAttachmentFields(&beads.Issue{Description: info.Description}); fields != nil && fields.AttachedMolecule != "" {
+		moleculeID = fields.AttachedMolecule
+	}
+	// bd list doesn't route by prefix; list from the molecule's rig
+	steps, err := beads.New(beads.ResolveHookDir(townRoot, moleculeID, "")).List(beads.ListOptions{
+		Parent:   moleculeID,
+		Status:   "all",
+		Priority: -1,
+	})
+	if err != nil || len(steps) == 0 {
+		return ""
+	}
+	next, err := beads.FindNextStep(steps)
+	if err != nil || next.Next == nil {
+		return ""
+	}
+	return beads.ParseStepControl(next.Next.Description).Tier
+}
+
 // storeArgsInBead stores args in the bead's description using attached_args field.
 // This enables no-tmux mode where agents discover args via gt prime / bd show.
 func storeArgsInBead(beadID, args string) error {
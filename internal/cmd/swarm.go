@@ -391,34 +391,11 @@ func runSwarmStart(cmd *cobra.Command, args []string) error {
 func runSwarmDispatch(cmd *cobra.Command, args []string) error {
 	epicID := args[0]
 
-	// Find the epic's rig by trying to show it in each rig
-	rigs, townRoot, err := getAllRigs()
+	foundRig, townRoot, err := findEpicRig(epicID, swarmDispatchRig)
 	if err != nil {
 		return err
 	}
 
-	var foundRig *rig.Rig
-	for _, r := range rigs {
-		// If --rig specified, only check that rig
-		if swarmDispatchRig != "" && r.Name != swarmDispatchRig {
-			continue
-		}
-		// Use BeadsPath() to ensure we read from git-synced location
-		checkCmd := exec.Command("bd", "show", epicID, "--json")
-		checkCmd.Dir = r.BeadsPath()
-		if err := checkCmd.Run(); err == nil {
-			foundRig = r
-			break
-		}
-	}
-
-	if foundRig == nil {
-		if swarmDispatchRig != "" {
-			return fmt.Errorf("epic '%s' not found in rig '%s'", epicID, swarmDispatchRig)
-		}
-		return fmt.Errorf("epic '%s' not found in any rig", epicID)
-	}
-
 	// Get swarm/epic status to find ready tasks
 	statusCmd := exec.Command("bd", "swarm", "status", epicID, "--json")
 	statusCmd.Dir = foundRig.BeadsPath()
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/swarm"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// Swarm schedule flags
+var (
+	swarmScheduleRig          string
+	swarmScheduleWorkers      int
+	swarmScheduleStallTimeout time.Duration
+	swarmScheduleMaxAttempts  int
+	swarmScheduleStop         bool
+	swarmTickRig              string
+	swarmTickJSON             bool
+)
+
+var swarmScheduleCmd = &cobra.Command{
+	Use:   "schedule <epic-id>",
+	Short: "Keep a swarm fed with workers until it lands",
+	Long: `Schedule a swarm for continuous dispatch.
+
+Unlike 'gt swarm dispatch' (one task, one time), a scheduled swarm is
+advanced on every daemon heartbeat:
+  - Newly unblocked tasks are slung to fresh polecats, up to --workers at once
+  - Tasks whose polecat has been dead for --stall-timeout are re-queued
+  - Tasks that stall more than --max-attempts times fail the swarm
+  - Swarm state moves created → active → merging → landed automatically
+
+Schedules are stored in <rig>/.runtime/swarms/<epic-id>.json.
+
+Examples:
+  gt swarm schedule gt-abc                  # Schedule with defaults (3 workers)
+  gt swarm schedule gt-abc --workers 5      # Raise the concurrency cap
+  gt swarm schedule gt-abc --stop           # Stop scheduling`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSwarmSchedule,
+}
+
+var swarmTickCmd = &cobra.Command{
+	Use:   "tick",
+	Short: "Advance scheduled swarms once",
+	Long: `Run one scheduler pass over all scheduled swarms.
+
+The daemon does this on every heartbeat. Witness patrols can call it to
+react faster when polecats finish.
+
+Examples:
+  gt swarm tick                   # All rigs
+  gt swarm tick --rig greenplace  # One rig
+  gt swarm tick --json            # Machine-readable results`,
+	Args: cobra.NoArgs,
+	RunE: runSwarmTick,
+}
+
+func init() {
+	swarmScheduleCmd.Flags().StringVar(&swarmScheduleRig, "rig", "", "Rig containing the epic (auto-detected if not specified)")
+	swarmScheduleCmd.Flags().IntVar(&swarmScheduleWorkers, "workers", swarm.DefaultTargetWorkers, "Maximum concurrent workers")
+	swarmScheduleCmd.Flags().DurationVar(&swarmScheduleStallTimeout, "stall-timeout", swarm.DefaultStallTimeout, "Re-queue tasks whose worker has been dead this long")
+	swarmScheduleCmd.Flags().IntVar(&swarmScheduleMaxAttempts, "max-attempts", swarm.DefaultMaxAttempts, "Dispatch attempts per task before the swarm fails")
+	swarmScheduleCmd.Flags().BoolVar(&swarmScheduleStop, "stop", false, "Stop scheduling this swarm")
+
+	swarmTickCmd.Flags().StringVar(&swarmTickRig, "rig", "", "Only advance swarms in this rig")
+	swarmTickCmd.Flags().BoolVar(&swarmTickJSON, "json", false, "Output as JSON")
+
+	swarmCmd.AddCommand(swarmScheduleCmd)
+	swarmCmd.AddCommand(swarmTickCmd)
+}
+
+// findEpicRig returns the rig whose beads contain the given epic.
+func findEpicRig(epicID, rigName string) (*rig.Rig, string, error) {
+	rigs, townRoot, err := getAllRigs()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, r := range rigs {
+		if rigName != "" && r.Name != rigName {
+			continue
+		}
+		checkCmd := exec.Command("bd", "show", epicID, "--json")
+		checkCmd.Dir = r.BeadsPath()
+		if err := checkCmd.Run(); err == nil {
+			return r, townRoot, nil
+		}
+	}
+
+	if rigName != "" {
+		return nil, "", fmt.Errorf("epic '%s' not found in rig '%s'", epicID, rigName)
+	}
+	return nil, "", fmt.Errorf("epic '%s' not found in any rig", epicID)
+}
+
+func runSwarmSchedule(cmd *cobra.Command, args []string) error {
+	epicID := args[0]
+
+	if swarmScheduleWorkers < 1 {
+		return fmt.Errorf("--workers must be at least 1")
+	}
+
+	r, _, err := findEpicRig(epicID, swarmScheduleRig)
+	if err != nil {
+		return err
+	}
+
+	if swarmScheduleStop {
+		if err := swarm.RemoveSchedule(r.Path, epicID); err != nil {
+			return fmt.Errorf("removing schedule: %w", err)
+		}
+		fmt.Printf("%s Stopped scheduling swarm %s\n", style.Bold.Render("✓"), epicID)
+		return nil
+	}
+
+	// Verify the epic is a swarm before scheduling it
+	if _, err := swarm.NewManager(r).LoadSwarm(epicID); err != nil {
+		return err
+	}
+
+	sched, err := swarm.LoadSchedule(r.Path, epicID)
+	if err != nil {
+		sched = swarm.NewSchedule(epicID, r.Name)
+	}
+	sched.TargetWorkers = swarmScheduleWorkers
+	sched.StallTimeout = swarmScheduleStallTimeout
+	sched.MaxAttempts = swarmScheduleMaxAttempts
+
+	if err := swarm.SaveSchedule(r.Path, sched); err != nil {
+		return fmt.Errorf("saving schedule: %w", err)
+	}
+
+	fmt.Printf("%s Scheduled swarm %s in %s\n", style.Bold.Render("✓"), epicID, r.Name)
+	fmt.Printf("  Workers: %d  Stall timeout: %s  Max attempts: %d\n",
+		sched.TargetWorkers, sched.StallTimeout, sched.MaxAttempts)
+	fmt.Printf("  %s\n", style.Dim.Render("The daemon advances scheduled swarms each heartbeat (or run 'gt swarm tick')"))
+	return nil
+}
+
+func runSwarmTick(cmd *cobra.Command, args []string) error {
+	rigs, townRoot, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+
+	t := tmux.NewTmux()
+	alive := func(rigName, assignee string) bool {
+		name := assignee[strings.LastIndex(assignee, "/")+1:]
+		return name != "" && t.IsClaudeRunning(session.PolecatSessionName(rigName, name))
+	}
+
+	var results []*swarm.TickResult
+	for _, r := range rigs {
+		if swarmTickRig != "" && r.Name != swarmTickRig {
+			continue
+		}
+
+		schedules, err := swarm.ListSchedules(r.Path)
+		if err != nil {
+			style.PrintWarning("listing swarm schedules for %s: %v", r.Name, err)
+			continue
+		}
+
+		scheduler := swarm.NewScheduler(swarm.NewManager(r), &swarm.SlingDispatcher{TownRoot: townRoot}, alive)
+		for _, sched := range schedules {
+			if sched.State.IsTerminal() {
+				continue
+			}
+			result, err := scheduler.Tick(sched)
+			if err != nil {
+				style.PrintWarning("swarm %s: %v", sched.SwarmID, err)
+				continue
+			}
+			if err := swarm.SaveSchedule(r.Path, sched); err != nil {
+				style.PrintWarning("saving schedule for %s: %v", sched.SwarmID, err)
+			}
+			results = append(results, result)
+		}
+	}
+
+	if swarmTickJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No scheduled swarms")
+		return nil
+	}
+
+	for _, result := range results {
+		fmt.Printf("%s %s [%s] %d active\n", style.Bold.Render("●"), result.SwarmID, result.State, result.Active)
+		for _, id := range result.Dispatched {
+			fmt.Printf("  → dispatched %s\n", id)
+		}
+		for _, id := range result.Requeued {
+			fmt.Printf("  ↺ re-queued %s\n", id)
+		}
+		for _, e := range result.Errors {
+			fmt.Printf("  %s\n", style.Dim.Render("! "+e))
+		}
+	}
+	return nil
+}
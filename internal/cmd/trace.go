@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/trace"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Trace command flags
+var (
+	traceJSON bool
+)
+
+var traceCmd = &cobra.Command{
+	Use:     "trace <bead-id>",
+	GroupID: GroupDiag,
+	Short:   "Show the lifecycle timeline of a bead or convoy",
+	Long: `Reconstruct one ordered timeline for a bead from everywhere Gas Town
+records it, with the time spent in each phase:
+
+  queued → spawned → working → done → in_mq → merged
+
+Sources:
+  - Beads: creation and close
+  - Activity events: sling, hook, unhook, done (including rotated segments)
+  - Town log: spawn and done
+  - Refinery MQ log: merge started, failed, skipped, merged
+  - Git: commits on the polecat branch
+  - Mail: messages mentioning the bead to its workers, witness and refinery
+  - Session cost wisps attributed to the bead
+
+For a convoy, every tracked bead is traced and the timelines are merged.
+Convoy phases run from the first bead entering a phase to the last leaving it.
+
+Examples:
+  gt trace gt-abc          # Timeline for one bead
+  gt trace hq-cv-xyz       # Timeline for every bead in a convoy
+  gt trace gt-abc --json   # Machine-readable output`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrace,
+}
+
+func init() {
+	traceCmd.Flags().BoolVar(&traceJSON, "json", false, "Output as JSON")
+
+	rootCmd.AddCommand(traceCmd)
+}
+
+func runTrace(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	tl, err := trace.New(townRoot).Trace(args[0])
+	if err != nil {
+		return err
+	}
+
+	if traceJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tl)
+	}
+
+	printTraceHeader(tl)
+	if len(tl.Beads) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Beads"))
+		for _, b := range tl.Beads {
+			fmt.Printf("   %-12s %-8s %s  %s\n", b.Bead, traceCurrentPhase(b), formatTracePhases(b.Phases), style.Dim.Render(b.Title))
+		}
+	}
+	printTracePhases(tl.Phases)
+	printTraceEntries(tl, len(tl.Beads) > 0)
+
+	if len(tl.Warnings) > 0 {
+		fmt.Println()
+		for _, w := range tl.Warnings {
+			fmt.Printf("%s %s\n", style.Warning.Render("⚠"), style.Dim.Render(w))
+		}
+	}
+	return nil
+}
+
+func printTraceHeader(tl *trace.Timeline) {
+	title := tl.Bead
+	if tl.Title != "" {
+		title += ": " + tl.Title
+	}
+	fmt.Printf("%s %s\n", style.Bold.Render("🔎 Trace"), title)
+
+	details := []string{"Status: " + tl.Status}
+	if tl.Rig != "" {
+		details = append(details, "Rig: "+tl.Rig)
+	}
+	if len(tl.Workers) > 0 {
+		details = append(details, "Workers: "+strings.Join(tl.Workers, ", "))
+	}
+	if tl.Branch != "" {
+		details = append(details, "Branch: "+tl.Branch)
+	}
+	if tl.CostUSD > 0 {
+		details = append(details, fmt.Sprintf("Cost: $%.2f", tl.CostUSD))
+	}
+	fmt.Printf("   %s\n", style.Dim.Render(strings.Join(details, " · ")))
+}
+
+func printTracePhases(phases []trace.Span) {
+	fmt.Printf("\n%s\n", style.Bold.Render("Phases"))
+	if len(phases) == 0 {
+		fmt.Printf("   %s\n", style.Dim.Render("No lifecycle milestones found"))
+		return
+	}
+	for _, s := range phases {
+		duration := formatDuration(s.Duration)
+		switch {
+		case s.Phase == trace.PhaseMerged:
+			duration = style.Success.Render("✓ " + s.Start.Local().Format("2006-01-02 15:04"))
+		case s.Ongoing:
+			duration = style.Warning.Render(duration + " (current)")
+		}
+		fmt.Printf("   %-8s %s\n", s.Phase, duration)
+	}
+}
+
+func printTraceEntries(tl *trace.Timeline, showBead bool) {
+	fmt.Printf("\n%s\n", style.Bold.Render("Timeline"))
+	for _, e := range tl.Entries {
+		marker := " "
+		if e.Milestone != "" {
+			marker = style.Bold.Render("▸")
+		}
+		bead := ""
+		if showBead {
+			bead = fmt.Sprintf("%-12s ", e.Bead)
+		}
+		fmt.Printf("   %s %s %s%-9s %s",
+			style.Dim.Render(e.Timestamp.Local().Format("2006-01-02 15:04:05")),
+			marker, bead, "["+e.Source+"]", e.Summary)
+		if e.Actor != "" && !strings.Contains(e.Summary, e.Actor) {
+			fmt.Printf(" %s", style.Dim.Render("by "+e.Actor))
+		}
+		fmt.Println()
+	}
+}
+
+// traceCurrentPhase returns a bead's latest phase, or "-" if none was found.
+func traceCurrentPhase(tl *trace.Timeline) string {
+	if tl.Phase == "" {
+		return "-"
+	}
+	return string(tl.Phase)
+}
+
+// formatTracePhases renders phase durations on one line, e.g. "queued 5m 0s → working 1h 2m".
+func formatTracePhases(phases []trace.Span) string {
+	var parts []string
+	for _, s := range phases {
+		if s.Phase == trace.PhaseMerged {
+			parts = append(parts, "merged")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", s.Phase, formatDuration(s.Duration)))
+	}
+	return strings.Join(parts, " → ")
+}
@@ -3,6 +3,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -430,6 +431,20 @@ func IsKnownPreset(name string) bool {
 	return ok
 }
 
+// validateAgentRegistry validates an AgentRegistry loaded from settings/agents.json.
+// User entries replace built-in presets wholesale, so each needs a command.
+func validateAgentRegistry(c *AgentRegistry) error {
+	if c.Version > CurrentAgentRegistryVersion {
+		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, c.Version, CurrentAgentRegistryVersion)
+	}
+	for name, preset := range c.Agents {
+		if preset == nil || preset.Command == "" {
+			return fmt.Errorf("%w: command for agent '%s'", ErrMissingField, name)
+		}
+	}
+	return nil
+}
+
 // SaveAgentRegistry writes the agent registry to a file.
 func SaveAgentRegistry(path string, registry *AgentRegistry) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
@@ -0,0 +1,329 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Lint issue levels.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintIssue is one problem found in a config file.
+// Line and Column are 1-based; zero when the issue isn't tied to a position.
+type LintIssue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Key     string `json:"key,omitempty"` // dotted path, e.g. "rigs.gastown.git_url"
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// String formats the issue as file:line:col: key: message.
+func (i LintIssue) String() string {
+	var b strings.Builder
+	b.WriteString(i.File)
+	if i.Line > 0 {
+		fmt.Fprintf(&b, ":%d:%d", i.Line, i.Column)
+	}
+	b.WriteString(": ")
+	if i.Key != "" {
+		b.WriteString(i.Key + ": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// LintTown lints every config file in the town and its registered rigs.
+func LintTown(townRoot string) []LintIssue {
+	var issues []LintIssue
+	for _, f := range FindConfigFiles(townRoot) {
+		issues = append(issues, LintFile(f.Path, f.Kind)...)
+	}
+	return issues
+}
+
+// LintFile checks a config file against its kind's schema and validation:
+// JSON syntax, unknown keys, value types, the type tag, the schema version
+// and the same semantic checks the loader applies.
+func LintFile(path string, k *Kind) []LintIssue {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is a discovered config file
+	if err != nil {
+		return []LintIssue{{File: path, Level: LintError, Message: err.Error()}}
+	}
+
+	l := &linter{file: path, data: data}
+	if issue := l.syntax(); issue != nil {
+		return []LintIssue{*issue}
+	}
+
+	l.dec = json.NewDecoder(bytes.NewReader(data))
+	l.dec.UseNumber()
+	if err := l.walk(k.Schema(), ""); err != nil {
+		l.add(l.offset(), "", LintError, err.Error())
+		return l.issues
+	}
+
+	l.checkVersion(k)
+
+	if !l.hasErrors() {
+		v := k.newValue()
+		if err := json.Unmarshal(data, v); err != nil {
+			l.issues = append(l.issues, LintIssue{File: path, Level: LintError, Message: err.Error()})
+		} else if err := k.validate(v); err != nil {
+			l.issues = append(l.issues, LintIssue{File: path, Level: LintError, Message: err.Error()})
+		}
+	}
+	return l.issues
+}
+
+// linter walks a JSON document alongside its schema, recording issues with
+// source positions.
+type linter struct {
+	file   string
+	data   []byte
+	dec    *json.Decoder
+	issues []LintIssue
+
+	// version is the top-level "version" value and where it appeared.
+	version    json.Number
+	versionOff int
+}
+
+// syntax reports a JSON syntax error with its position, or nil.
+func (l *linter) syntax() *LintIssue {
+	var v interface{}
+	err := json.Unmarshal(l.data, &v)
+	if err == nil {
+		return nil
+	}
+	issue := &LintIssue{File: l.file, Level: LintError, Message: err.Error()}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		issue.Line, issue.Column = l.position(int(syntaxErr.Offset) - 1)
+	}
+	return issue
+}
+
+// walk checks the next JSON value against s.
+func (l *linter) walk(s *Schema, path string) error {
+	off := l.offset()
+	jsonType := l.peekType(off)
+
+	if !s.allows(jsonType) {
+		l.add(off, path, LintError, fmt.Sprintf("expected %s, got %s", strings.Join(s.Types, " or "), jsonType))
+		return l.dec.Decode(&json.RawMessage{})
+	}
+
+	tok, err := l.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '[' {
+			items := s.Items
+			if items == nil {
+				items = &Schema{}
+			}
+			for i := 0; l.dec.More(); i++ {
+				if err := l.walk(items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		} else {
+			for l.dec.More() {
+				keyOff := l.offset()
+				keyTok, err := l.dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyTok.(string)
+				child := joinKey(path, key)
+
+				prop := s.Properties[key]
+				if prop == nil {
+					prop = s.AdditionalProperties
+				}
+				if prop == nil && s.Closed {
+					msg := "unknown key"
+					if near := nearestKey(key, s.Properties); near != "" {
+						msg += fmt.Sprintf(" (did you mean %q?)", near)
+					}
+					l.add(keyOff, child, LintError, msg)
+					if err := l.dec.Decode(&json.RawMessage{}); err != nil {
+						return err
+					}
+					continue
+				}
+				if prop == nil {
+					prop = &Schema{}
+				}
+				if path == "" && key == "version" {
+					l.versionOff = l.offset()
+				}
+				if err := l.walk(prop, child); err != nil {
+					return err
+				}
+			}
+		}
+		_, err := l.dec.Token() // closing delimiter
+		return err
+
+	case string:
+		if c, ok := s.Const.(string); ok && v != "" && v != c {
+			l.add(off, path, LintError, fmt.Sprintf("expected %q, got %q", c, v))
+		}
+		if s.Format == "date-time" && v != "" {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				l.add(off, path, LintError, fmt.Sprintf("invalid date-time %q", v))
+			}
+		}
+
+	case json.Number:
+		if path == "version" {
+			l.version = v
+		}
+		if s.Max != nil {
+			if n, err := v.Int64(); err == nil && n > int64(*s.Max) {
+				l.add(off, path, LintError, fmt.Sprintf("%d is greater than the maximum %d", n, *s.Max))
+			}
+		}
+	}
+	return nil
+}
+
+// checkVersion warns about files without a version or on an older schema.
+func (l *linter) checkVersion(k *Kind) {
+	if _, ok := k.Schema().Properties["version"]; !ok {
+		return
+	}
+	if l.version == "" {
+		l.add(-1, "version", LintWarning, fmt.Sprintf("missing schema version (current is %d)", k.CurrentVersion))
+		return
+	}
+	if n, err := l.version.Int64(); err == nil && n < int64(k.CurrentVersion) {
+		l.add(l.versionOff, "version", LintWarning,
+			fmt.Sprintf("schema version %d is older than current %d; run 'gt config migrate'", n, k.CurrentVersion))
+	}
+}
+
+// offset returns the byte offset of the next token, skipping whitespace and
+// the separators the decoder hasn't consumed yet.
+func (l *linter) offset() int {
+	off := int(l.dec.InputOffset())
+	for off < len(l.data) {
+		switch l.data[off] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			off++
+			continue
+		}
+		break
+	}
+	return off
+}
+
+// peekType returns the JSON type of the value starting at off.
+func (l *linter) peekType(off int) string {
+	if off >= len(l.data) {
+		return "null"
+	}
+	switch c := l.data[off]; {
+	case c == '{':
+		return "object"
+	case c == '[':
+		return "array"
+	case c == '"':
+		return "string"
+	case c == 't' || c == 'f':
+		return "boolean"
+	case c == 'n':
+		return "null"
+	default:
+		end := off
+		for end < len(l.data) && strings.IndexByte("+-0123456789.eE", l.data[end]) >= 0 {
+			end++
+		}
+		if bytes.ContainsAny(l.data[off:end], ".eE") {
+			return "number"
+		}
+		return "integer"
+	}
+}
+
+// add records an issue at the given byte offset (-1 for no position).
+func (l *linter) add(off int, key, level, msg string) {
+	issue := LintIssue{File: l.file, Key: key, Level: level, Message: msg}
+	if off >= 0 {
+		issue.Line, issue.Column = l.position(off)
+	}
+	l.issues = append(l.issues, issue)
+}
+
+// position converts a byte offset into a 1-based line and column.
+func (l *linter) position(off int) (line, col int) {
+	if off > len(l.data) {
+		off = len(l.data)
+	}
+	before := l.data[:off]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = off - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+func (l *linter) hasErrors() bool {
+	for _, i := range l.issues {
+		if i.Level == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// nearestKey suggests the known property closest to a mistyped key.
+func nearestKey(key string, props map[string]*Schema) string {
+	best, bestDist := "", 3
+	for name := range props {
+		if d := editDistance(strings.ToLower(key), name); d < bestDist || (d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestLintFile(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		kind    string
+		content string
+		want    []string // substrings of issue strings, in order
+	}{
+		{
+			name:    "valid town",
+			kind:    "town",
+			content: `{"type": "town", "version": 2, "name": "hq", "created_at": "2026-01-01T00:00:00Z"}`,
+		},
+		{
+			name: "unknown key with position and suggestion",
+			kind: "rigs",
+			content: `{
+  "version": 1,
+  "rigs": {
+    "gastown": {
+      "gti_url": "https://example.com/repo.git"
+    }
+  }
+}`,
+			want: []string{`:5:7: rigs.gastown.gti_url: unknown key (did you mean "git_url"?)`},
+		},
+		{
+			name:    "wrong value type",
+			kind:    "daemon",
+			content: `{"type": "daemon-patrol-config", "version": 1, "heartbeat": {"enabled": "yes"}}`,
+			want:    []string{`:1:73: heartbeat.enabled: expected boolean, got string`},
+		},
+		{
+			name:    "wrong type tag",
+			kind:    "mayor",
+			content: `{"type": "town", "version": 1}`,
+			want:    []string{`type: expected "mayor-config", got "town"`},
+		},
+		{
+			name:    "newer version",
+			kind:    "escalation",
+			content: `{"type": "escalation", "version": 9}`,
+			want:    []string{`version: 9 is greater than the maximum 1`},
+		},
+		{
+			name:    "older version",
+			kind:    "town",
+			content: `{"type": "town", "version": 1, "name": "hq"}`,
+			want:    []string{`version: schema version 1 is older than current 2`},
+		},
+		{
+			name:    "missing version",
+			kind:    "accounts",
+			content: `{"accounts": {}}`,
+			want:    []string{`version: missing schema version`},
+		},
+		{
+			name:    "semantic validation",
+			kind:    "escalation",
+			content: `{"type": "escalation", "version": 1, "stale_threshold": "soon"}`,
+			want:    []string{`invalid stale_threshold`},
+		},
+		{
+			name:    "syntax error",
+			kind:    "town",
+			content: "{\n  \"name\": \"hq\",\n}",
+			want:    []string{`:3:1: invalid character '}'`},
+		},
+		{
+			name:    "free-form maps accept any key",
+			kind:    "messaging",
+			content: `{"type": "messaging", "version": 1, "lists": {"oncall": ["mayor/"]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "config.json")
+			writeConfig(t, path, tt.content)
+
+			issues := LintFile(path, KindByName(tt.kind))
+			if len(issues) != len(tt.want) {
+				t.Fatalf("LintFile() = %v, want %d issue(s)", issues, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := issues[i].String(); !strings.Contains(got, want) {
+					t.Errorf("issue %d = %q, want it to contain %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLintTownFindsRigFiles(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	writeConfig(t, filepath.Join(townRoot, "mayor", "rigs.json"),
+		`{"version": 1, "rigs": {"gastown": {"git_url": "x", "added_at": "2026-01-01T00:00:00Z"}}}`)
+	writeConfig(t, filepath.Join(townRoot, "gastown", "settings", "config.json"),
+		`{"type": "rig-settings", "version": 1, "merge_queue": {"enabled": true, "on_conflic": "assign_back"}}`)
+
+	issues := LintTown(townRoot)
+	if len(issues) != 1 || issues[0].Key != "merge_queue.on_conflic" {
+		t.Fatalf("LintTown() = %v, want one unknown-key issue in rig settings", issues)
+	}
+}
+
+func TestKindSchemas(t *testing.T) {
+	t.Parallel()
+	for _, k := range Kinds {
+		data, err := json.Marshal(k.Schema())
+		if err != nil {
+			t.Fatalf("%s schema: %v", k.Name, err)
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("%s schema is not JSON: %v", k.Name, err)
+		}
+		if doc["type"] != "object" || doc["additionalProperties"] != false {
+			t.Errorf("%s schema root = type %v, additionalProperties %v", k.Name, doc["type"], doc["additionalProperties"])
+		}
+		props, _ := doc["properties"].(map[string]interface{})
+		if _, ok := props["version"]; !ok {
+			t.Errorf("%s schema has no version property", k.Name)
+		}
+	}
+}
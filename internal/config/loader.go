@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -211,6 +213,16 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.WorktreePool != nil {
+		if err := validateWorktreePoolConfig(c.WorktreePool); err != nil {
+			return err
+		}
+	}
+	if c.Sandbox != nil {
+		if err := validateSandboxConfig(c.Sandbox); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -243,6 +255,55 @@ func validateMergeQueueConfig(c *MergeQueueConfig) error {
 	return nil
 }
 
+// validateWorktreePoolConfig validates a WorktreePoolConfig.
+func validateWorktreePoolConfig(c *WorktreePoolConfig) error {
+	if c.Size < 0 {
+		return fmt.Errorf("%w: worktree_pool.size must be non-negative", ErrMissingField)
+	}
+	if c.RefreshInterval != "" {
+		if _, err := time.ParseDuration(c.RefreshInterval); err != nil {
+			return fmt.Errorf("invalid worktree_pool.refresh_interval: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateEventsConfig validates an EventsConfig.
+func validateEventsConfig(c *EventsConfig) error {
+	if c.MaxSizeMB < 0 {
+		return fmt.Errorf("%w: events.max_size_mb must be non-negative", ErrMissingField)
+	}
+	if c.MaxAge != "" {
+		if _, err := time.ParseDuration(c.MaxAge); err != nil {
+			return fmt.Errorf("invalid events.max_age: %w", err)
+		}
+	}
+	for visibility, retention := range c.Retention {
+		if visibility != "audit" && visibility != "feed" {
+			return fmt.Errorf("invalid events.retention key '%s': want 'audit' or 'feed'", visibility)
+		}
+		if _, err := time.ParseDuration(retention); err != nil {
+			return fmt.Errorf("invalid events.retention.%s: %w", visibility, err)
+		}
+	}
+	return nil
+}
+
+// validateSandboxConfig validates a SandboxConfig.
+func validateSandboxConfig(c *SandboxConfig) error {
+	if c.Network != "" && c.Network != SandboxNetworkAllow && c.Network != SandboxNetworkDeny {
+		return fmt.Errorf("invalid sandbox.network: got '%s', want '%s' or '%s'",
+			c.Network, SandboxNetworkAllow, SandboxNetworkDeny)
+	}
+	if c.CPUQuota != "" && !strings.HasSuffix(c.CPUQuota, "%") {
+		return fmt.Errorf("invalid sandbox.cpu_quota: %q must be a percentage (e.g., \"200%%\")", c.CPUQuota)
+	}
+	if c.TasksMax < 0 {
+		return fmt.Errorf("%w: sandbox.tasks_max must be non-negative", ErrMissingField)
+	}
+	return nil
+}
+
 // NewRigConfig creates a new RigConfig (identity only).
 func NewRigConfig(name, gitURL string) *RigConfig {
 	return &RigConfig{
@@ -426,6 +487,11 @@ func validateDaemonPatrolConfig(c *DaemonPatrolConfig) error {
 	if c.Version > CurrentDaemonPatrolConfigVersion {
 		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, c.Version, CurrentDaemonPatrolConfigVersion)
 	}
+	if c.Metrics != nil && c.Metrics.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Listen); err != nil {
+			return fmt.Errorf("invalid metrics.listen %q: %w", c.Metrics.Listen, err)
+		}
+	}
 	return nil
 }
 
@@ -654,6 +720,12 @@ func validateMessagingConfig(c *MessagingConfig) error {
 	if c.NudgeChannels == nil {
 		c.NudgeChannels = make(map[string][]string)
 	}
+	if c.Rules == nil {
+		c.Rules = make(map[string][]MailRule)
+	}
+	if c.QuietPolicies == nil {
+		c.QuietPolicies = make(map[string][]QuietPolicy)
+	}
 
 	// Validate lists have at least one recipient
 	for name, recipients := range c.Lists {
@@ -692,9 +764,115 @@ func validateMessagingConfig(c *MessagingConfig) error {
 		}
 	}
 
+	// Validate mail rules
+	for pattern, rules := range c.Rules {
+		if pattern == "" {
+			return fmt.Errorf("%w: mail rules address cannot be empty", ErrMissingField)
+		}
+		for i, rule := range rules {
+			if err := validateMailRule(rule); err != nil {
+				return fmt.Errorf("rules '%s'[%d]: %w", pattern, i, err)
+			}
+		}
+	}
+
+	// Validate quiet policies
+	for pattern, policies := range c.QuietPolicies {
+		if pattern == "" {
+			return fmt.Errorf("%w: quiet policy address cannot be empty", ErrMissingField)
+		}
+		for i, policy := range policies {
+			if err := validateQuietPolicy(policy); err != nil {
+				return fmt.Errorf("quiet_policies '%s'[%d]: %w", pattern, i, err)
+			}
+		}
+	}
+
 	return nil
 }
 
+// validateQuietPolicy checks that a quiet policy has a valid condition.
+func validateQuietPolicy(p QuietPolicy) error {
+	if p.QuietHours == nil && !p.DuringWaitStep {
+		return fmt.Errorf("%w: quiet_hours or during_wait_step", ErrMissingField)
+	}
+	if qh := p.QuietHours; qh != nil {
+		for _, t := range []string{qh.Start, qh.End} {
+			if _, err := time.Parse("15:04", t); err != nil {
+				return fmt.Errorf("invalid quiet_hours time %q: want HH:MM", t)
+			}
+		}
+		if qh.Start == qh.End {
+			return fmt.Errorf("quiet_hours start and end are both %s", qh.Start)
+		}
+		for _, day := range qh.Days {
+			if _, ok := QuietDays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("invalid quiet_hours day %q (valid: mon, tue, wed, thu, fri, sat, sun)", day)
+			}
+		}
+		if qh.Timezone != "" {
+			if _, err := time.LoadLocation(qh.Timezone); err != nil {
+				return fmt.Errorf("invalid quiet_hours timezone %q: %w", qh.Timezone, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMailRule checks a mail rule's match conditions and actions.
+func validateMailRule(rule MailRule) error {
+	if rule.Match.Subject != "" {
+		if _, err := regexp.Compile(rule.Match.Subject); err != nil {
+			return fmt.Errorf("invalid subject pattern: %w", err)
+		}
+	}
+	if rule.Match.Type != "" && !isMailType(rule.Match.Type) {
+		return fmt.Errorf("invalid type %q (valid: task, scavenge, notification, reply)", rule.Match.Type)
+	}
+	if rule.Match.Priority != "" && !isMailPriority(rule.Match.Priority) {
+		return fmt.Errorf("invalid priority %q (valid: low, normal, high, urgent)", rule.Match.Priority)
+	}
+	if len(rule.Actions) == 0 {
+		return fmt.Errorf("%w: actions", ErrMissingField)
+	}
+	for _, action := range rule.Actions {
+		name, arg, _ := strings.Cut(action, ":")
+		switch name {
+		case "archive", "mark-read", "nudge":
+			if arg != "" {
+				return fmt.Errorf("action %q takes no argument", name)
+			}
+		case "forward":
+			if arg == "" {
+				return fmt.Errorf("%w: forward address", ErrMissingField)
+			}
+		case "priority":
+			if !isMailPriority(arg) {
+				return fmt.Errorf("invalid priority %q in action %q (valid: low, normal, high, urgent)", arg, action)
+			}
+		default:
+			return fmt.Errorf("unknown action %q (valid: archive, mark-read, nudge, forward:<address>, priority:<level>)", action)
+		}
+	}
+	return nil
+}
+
+func isMailType(s string) bool {
+	switch s {
+	case "task", "scavenge", "notification", "reply":
+		return true
+	}
+	return false
+}
+
+func isMailPriority(s string) bool {
+	switch s {
+	case "low", "normal", "high", "urgent":
+		return true
+	}
+	return false
+}
+
 // MessagingConfigPath returns the standard path for messaging config in a town.
 func MessagingConfigPath(townRoot string) string {
 	return filepath.Join(townRoot, "config", "messaging.json")
@@ -753,16 +931,16 @@ func LoadOrCreateTownSettings(path string) (*TownSettings, error) {
 	if err := json.Unmarshal(data, &settings); err != nil {
 		return nil, err
 	}
+	if err := validateTownSettings(&settings); err != nil {
+		return nil, err
+	}
 	return &settings, nil
 }
 
 // SaveTownSettings saves town settings to a file.
 func SaveTownSettings(path string, settings *TownSettings) error {
-	if settings.Type != "town-settings" && settings.Type != "" {
-		return fmt.Errorf("%w: expected type 'town-settings', got '%s'", ErrInvalidType, settings.Type)
-	}
-	if settings.Version > CurrentTownSettingsVersion {
-		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, settings.Version, CurrentTownSettingsVersion)
+	if err := validateTownSettings(settings); err != nil {
+		return err
 	}
 
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
@@ -781,6 +959,43 @@ func SaveTownSettings(path string, settings *TownSettings) error {
 	return nil
 }
 
+// validateTownSettings validates a TownSettings.
+func validateTownSettings(c *TownSettings) error {
+	if c.Type != "town-settings" && c.Type != "" {
+		return fmt.Errorf("%w: expected type 'town-settings', got '%s'", ErrInvalidType, c.Type)
+	}
+	if c.Version > CurrentTownSettingsVersion {
+		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, c.Version, CurrentTownSettingsVersion)
+	}
+	if c.Events != nil {
+		if err := validateEventsConfig(c.Events); err != nil {
+			return err
+		}
+	}
+	if c.Prime != nil {
+		if err := validatePrimeConfig(c.Prime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePrimeConfig validates a PrimeConfig.
+func validatePrimeConfig(c *PrimeConfig) error {
+	if c.Budget < 0 {
+		return fmt.Errorf("prime.budget must be non-negative, got %d", c.Budget)
+	}
+	for key, budget := range c.Budgets {
+		if key == "" {
+			return fmt.Errorf("%w: prime.budgets key cannot be empty", ErrMissingField)
+		}
+		if budget < 0 {
+			return fmt.Errorf("prime.budgets[%q] must be non-negative, got %d", key, budget)
+		}
+	}
+	return nil
+}
+
 // ResolveAgentConfig resolves the agent configuration for a rig.
 // It looks up the agent by name in town settings (custom agents) and built-in presets.
 //
@@ -994,6 +1209,66 @@ func ResolveRoleAgentName(role, townRoot, rigPath string) (agentName string, isR
 	return "claude", false
 }
 
+// ResolveTierAgentConfig resolves the agent configuration for a molecule
+// step with a model tier hint ("haiku", "sonnet", "opus"). It returns the
+// config and a short description of the choice for display.
+//
+// Resolution order:
+//  1. Rig's Tiers[tier] - if set, look up that agent
+//  2. Town's Tiers[tier] - if set, look up that agent
+//  3. The role's agent (ResolveRoleAgentConfig), with --model <tier> if it
+//     runs Claude; other runtimes have no model flag and ignore the tier
+//
+// An empty tier resolves to the role's agent unchanged.
+func ResolveTierAgentConfig(tier, role, townRoot, rigPath string) (*RuntimeConfig, string) {
+	base := ResolveRoleAgentConfig(role, townRoot, rigPath)
+	if tier == "" {
+		return base, ""
+	}
+
+	var rigSettings *RigSettings
+	if rigPath != "" {
+		if rs, err := LoadRigSettings(RigSettingsPath(rigPath)); err == nil {
+			rigSettings = rs
+		}
+	}
+	townSettings, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot))
+	if err != nil {
+		townSettings = NewTownSettings()
+	}
+
+	if rigSettings != nil && rigSettings.Tiers[tier] != "" {
+		return lookupAgentConfig(rigSettings.Tiers[tier], townSettings, rigSettings), rigSettings.Tiers[tier]
+	}
+	if townSettings.Tiers[tier] != "" {
+		return lookupAgentConfig(townSettings.Tiers[tier], townSettings, rigSettings), townSettings.Tiers[tier]
+	}
+	if filepath.Base(base.Command) == "claude" {
+		return base.WithModel(tier), "claude --model " + tier
+	}
+	return base, filepath.Base(base.Command) + " (no model flag; tier ignored)"
+}
+
+// WithModel returns a copy of the config whose args select model, replacing
+// any --model already given.
+func (rc *RuntimeConfig) WithModel(model string) *RuntimeConfig {
+	cp := *rc
+	out := *normalizeRuntimeConfig(&cp) // nil args mean defaults; keep them
+	args := out.Args
+	out.Args = nil
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--model" && i+1 < len(args):
+			i++
+		case strings.HasPrefix(arg, "--model="):
+		default:
+			out.Args = append(out.Args, arg)
+		}
+	}
+	out.Args = append(out.Args, "--model", model)
+	return &out
+}
+
 // lookupAgentConfig looks up an agent by name.
 // Checks rig-level custom agents first, then town's custom agents, then built-in presets from agents.go.
 func lookupAgentConfig(name string, townSettings *TownSettings, rigSettings *RigSettings) *RuntimeConfig {
@@ -1300,6 +1575,27 @@ func BuildPolecatStartupCommandWithAgentOverride(rigName, polecatName, rigPath,
 	return BuildStartupCommandWithAgentOverride(AgentEnvSimple("polecat", rigName, polecatName), rigPath, prompt, agentOverride)
 }
 
+// BuildPolecatStartupCommandForTier is like BuildPolecatStartupCommand, but
+// runs the agent for a model tier (see ResolveTierAgentConfig) and sets
+// GT_TIER so the session knows its tier. It also returns a description of
+// the agent chosen.
+func BuildPolecatStartupCommandForTier(rigName, polecatName, rigPath, prompt, tier string) (string, string) {
+	townRoot := filepath.Dir(rigPath)
+	rc, desc := ResolveTierAgentConfig(tier, "polecat", townRoot, rigPath)
+	rc = normalizeRuntimeConfig(rc)
+
+	envVars := AgentEnvSimple("polecat", rigName, polecatName)
+	envVars["GT_ROOT"] = townRoot
+	envVars["GT_TIER"] = tier
+	if rc.Session != nil && rc.Session.SessionIDEnv != "" {
+		envVars["GT_SESSION_ID_ENV"] = rc.Session.SessionIDEnv
+	}
+	if prompt != "" {
+		return PrependEnv(rc.BuildCommandWithPrompt(prompt), envVars), desc
+	}
+	return PrependEnv(rc.BuildCommand(), envVars), desc
+}
+
 // BuildCrewStartupCommand builds the startup command for a crew member.
 // Sets GT_ROLE, GT_RIG, GT_CREW, BD_ACTOR, and GIT_AUTHOR_NAME.
 func BuildCrewStartupCommand(rigName, crewName, rigPath, prompt string) string {
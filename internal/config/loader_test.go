@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -319,6 +320,51 @@ func TestRigSettingsValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative worktree_pool size",
+			settings: &RigSettings{
+				Type:         "rig-settings",
+				Version:      1,
+				WorktreePool: &WorktreePoolConfig{Size: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid worktree_pool refresh_interval",
+			settings: &RigSettings{
+				Type:         "rig-settings",
+				Version:      1,
+				WorktreePool: &WorktreePoolConfig{Size: 2, RefreshInterval: "soon"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid sandbox",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Enabled: true, CPUQuota: "200%", MemoryMax: "4G", Network: SandboxNetworkDeny},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid sandbox network",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Enabled: true, Network: "maybe"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid sandbox cpu_quota",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Enabled: true, CPUQuota: "2"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -722,6 +768,120 @@ func TestMessagingConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid config with mail rules",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules: map[string][]MailRule{
+					"*/witness": {
+						{Match: MailRuleMatch{Subject: "^POLECAT_DONE", Type: "notification"}, Actions: []string{"mark-read"}},
+						{Match: MailRuleMatch{From: "gastown/*"}, Actions: []string{"forward:list:oncall", "priority:high"}, Stop: true},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "mail rule with invalid subject pattern",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules: map[string][]MailRule{
+					"mayor/": {{Match: MailRuleMatch{Subject: "("}, Actions: []string{"archive"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mail rule with no actions",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules: map[string][]MailRule{
+					"mayor/": {{Match: MailRuleMatch{From: "deacon/"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mail rule with unknown action",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules: map[string][]MailRule{
+					"mayor/": {{Actions: []string{"delete"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mail rule with invalid priority action",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules: map[string][]MailRule{
+					"mayor/": {{Actions: []string{"priority:critical"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mail rule forward without address",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules: map[string][]MailRule{
+					"mayor/": {{Actions: []string{"forward:"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid quiet policies",
+			config: &MessagingConfig{
+				Version: 1,
+				QuietPolicies: map[string][]QuietPolicy{
+					"gastown/*": {{QuietHours: &QuietHours{Start: "22:00", End: "07:00", Days: []string{"Mon", "fri"}, Timezone: "UTC"}}},
+					"*/witness": {{DuringWaitStep: true}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "quiet policy without condition",
+			config: &MessagingConfig{
+				Version: 1,
+				QuietPolicies: map[string][]QuietPolicy{
+					"mayor/": {{Name: "empty"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "quiet hours with invalid time",
+			config: &MessagingConfig{
+				Version: 1,
+				QuietPolicies: map[string][]QuietPolicy{
+					"mayor/": {{QuietHours: &QuietHours{Start: "10pm", End: "07:00"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "quiet hours with invalid day",
+			config: &MessagingConfig{
+				Version: 1,
+				QuietPolicies: map[string][]QuietPolicy{
+					"mayor/": {{QuietHours: &QuietHours{Start: "22:00", End: "07:00", Days: []string{"someday"}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "quiet hours with invalid timezone",
+			config: &MessagingConfig{
+				Version: 1,
+				QuietPolicies: map[string][]QuietPolicy{
+					"mayor/": {{QuietHours: &QuietHours{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"}}},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1349,6 +1509,24 @@ func TestDaemonPatrolConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid metrics listen",
+			config: &DaemonPatrolConfig{
+				Type:    "daemon-patrol-config",
+				Version: 1,
+				Metrics: &MetricsConfig{Enabled: true, Listen: ":9464"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "metrics listen without port rejected",
+			config: &DaemonPatrolConfig{
+				Type:    "daemon-patrol-config",
+				Version: 1,
+				Metrics: &MetricsConfig{Enabled: true, Listen: "localhost"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1489,6 +1667,39 @@ func TestNewDaemonPatrolConfig(t *testing.T) {
 	}
 }
 
+func TestPrimeConfigBudgetFor(t *testing.T) {
+	t.Parallel()
+	c := &PrimeConfig{
+		Budget: 8000,
+		Budgets: map[string]int{
+			"polecat":        6000,
+			"gemini":         5000,
+			"witness/gemini": 3000,
+		},
+	}
+	tests := []struct {
+		role, runtime string
+		want          int
+		wantKey       string
+	}{
+		{"witness", "gemini", 3000, "witness/gemini"},
+		{"polecat", "gemini", 6000, "polecat"},
+		{"crew", "gemini", 5000, "gemini"},
+		{"mayor", "claude", 8000, ""},
+	}
+	for _, tt := range tests {
+		got, key := c.BudgetFor(tt.role, tt.runtime)
+		if got != tt.want || key != tt.wantKey {
+			t.Errorf("BudgetFor(%q, %q) = %d, %q; want %d, %q", tt.role, tt.runtime, got, key, tt.want, tt.wantKey)
+		}
+	}
+
+	var unset *PrimeConfig
+	if got, _ := unset.BudgetFor("mayor", "claude"); got != 0 {
+		t.Errorf("nil config budget = %d, want 0", got)
+	}
+}
+
 func TestSaveTownSettings(t *testing.T) {
 	t.Parallel()
 	t.Run("saves valid town settings", func(t *testing.T) {
@@ -1578,6 +1789,47 @@ func TestSaveTownSettings(t *testing.T) {
 		}
 	})
 
+	t.Run("rejects invalid events config", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		settingsPath := filepath.Join(tmpDir, "config.json")
+
+		for _, events := range []*EventsConfig{
+			{MaxSizeMB: -1},
+			{MaxAge: "daily"},
+			{Retention: map[string]string{"both": "24h"}},
+			{Retention: map[string]string{"audit": "two weeks"}},
+		} {
+			settings := NewTownSettings()
+			settings.Events = events
+			if err := SaveTownSettings(settingsPath, settings); err == nil {
+				t.Errorf("expected error for events config %+v", events)
+			}
+		}
+
+		settings := NewTownSettings()
+		settings.Events = DefaultEventsConfig()
+		if err := SaveTownSettings(settingsPath, settings); err != nil {
+			t.Errorf("default events config rejected: %v", err)
+		}
+	})
+
+	t.Run("rejects invalid prime config", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		settingsPath := filepath.Join(tmpDir, "config.json")
+
+		for _, prime := range []*PrimeConfig{
+			{Budget: -1},
+			{Budgets: map[string]int{"polecat": -5}},
+			{Budgets: map[string]int{"": 100}},
+		} {
+			settings := NewTownSettings()
+			settings.Prime = prime
+			if err := SaveTownSettings(settingsPath, settings); err == nil {
+				t.Errorf("expected error for prime config %+v", prime)
+			}
+		}
+	})
+
 	t.Run("roundtrip save and load", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		settingsPath := filepath.Join(tmpDir, "config.json")
@@ -2321,3 +2573,54 @@ func TestEscalationConfigPath(t *testing.T) {
 		t.Errorf("EscalationConfigPath = %q, want %q", path, expected)
 	}
 }
+
+func TestResolveTierAgentConfig(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+
+	townSettings := NewTownSettings()
+	townSettings.Tiers = map[string]string{"opus": "gemini"}
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	rigSettings := NewRigSettings()
+	rigSettings.Tiers = map[string]string{"haiku": "codex"}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), rigSettings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	tests := []struct {
+		tier    string
+		command string
+		desc    string
+	}{
+		{"haiku", "codex", "codex"},  // rig tier
+		{"opus", "gemini", "gemini"}, // town tier
+		{"sonnet", "claude", "claude --model sonnet"},
+		{"", "claude", ""},
+	}
+	for _, tt := range tests {
+		rc, desc := ResolveTierAgentConfig(tt.tier, "polecat", townRoot, rigPath)
+		if rc.Command != tt.command || desc != tt.desc {
+			t.Errorf("tier %q: command = %q, desc = %q; want %q, %q", tt.tier, rc.Command, desc, tt.command, tt.desc)
+		}
+	}
+
+	rc, _ := ResolveTierAgentConfig("sonnet", "polecat", townRoot, rigPath)
+	if got := strings.Join(rc.Args, " "); !strings.HasSuffix(got, "--model sonnet") || !strings.Contains(got, "--dangerously-skip-permissions") {
+		t.Errorf("sonnet args = %q", got)
+	}
+}
+
+func TestRuntimeConfigWithModel(t *testing.T) {
+	t.Parallel()
+	rc := &RuntimeConfig{Command: "claude", Args: []string{"--model", "opus", "--verbose", "--model=haiku"}}
+	got := rc.WithModel("sonnet")
+	if want := []string{"--verbose", "--model", "sonnet"}; !reflect.DeepEqual(got.Args, want) {
+		t.Errorf("Args = %v, want %v", got.Args, want)
+	}
+	if rc.Args[1] != "opus" {
+		t.Error("WithModel modified the original config")
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Migration upgrades one kind of config file by one schema version.
+// Apply edits the raw JSON document in place; the framework bumps "version".
+type Migration struct {
+	Kind        string
+	From        int // upgrades From -> From+1
+	Description string
+	Apply       func(doc map[string]interface{}) error
+}
+
+// migrations holds every registered schema migration. When a config type's
+// Current*Version is bumped, add a migration here from the previous version.
+var migrations = []Migration{
+	{
+		Kind:        "town",
+		From:        1,
+		Description: "add federation identity (owner, public_name are optional)",
+		Apply:       func(map[string]interface{}) error { return nil },
+	},
+}
+
+// MigrationResult describes a migrated (or migratable) config file.
+type MigrationResult struct {
+	Path    string   `json:"path"`
+	Kind    string   `json:"kind"`
+	From    int      `json:"from"`
+	To      int      `json:"to"`
+	Backup  string   `json:"backup,omitempty"`
+	Applied []string `json:"applied"`
+}
+
+// BackupPath returns where MigrateFile saves the original of path before
+// upgrading it from version from.
+func BackupPath(path string, from int) string {
+	return fmt.Sprintf("%s.v%d.bak", path, from)
+}
+
+// MigrateFile upgrades a config file to its kind's current schema version.
+// The original is copied to BackupPath first, and the upgraded document must
+// pass the loader's validation before it replaces the file. Files that are
+// current, newer, or carry no version are left alone (nil result).
+// With dryRun, the migration is checked but nothing is written.
+func MigrateFile(path string, k *Kind, dryRun bool) (*MigrationResult, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is a discovered config file
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	num, ok := doc["version"].(json.Number)
+	if !ok {
+		return nil, nil
+	}
+	from64, err := num.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid version %q", path, num)
+	}
+	from := int(from64)
+	if from < 1 || from >= k.CurrentVersion {
+		return nil, nil
+	}
+
+	result := &MigrationResult{Path: path, Kind: k.Name, From: from, To: k.CurrentVersion}
+	for v := from; v < k.CurrentVersion; v++ {
+		m := findMigration(k.Name, v)
+		if m == nil {
+			return nil, fmt.Errorf("%s: no migration for %s config from version %d to %d", path, k.Name, v, v+1)
+		}
+		if err := m.Apply(doc); err != nil {
+			return nil, fmt.Errorf("%s: migrating %s config from version %d: %w", path, k.Name, v, err)
+		}
+		result.Applied = append(result.Applied, fmt.Sprintf("v%d→v%d: %s", v, v+1, m.Description))
+	}
+	doc["version"] = k.CurrentVersion
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", path, err)
+	}
+	out = append(out, '\n')
+
+	// The upgraded file must load cleanly before it replaces the original
+	v := k.newValue()
+	if err := json.Unmarshal(out, v); err != nil {
+		return nil, fmt.Errorf("%s: migrated config does not parse: %w", path, err)
+	}
+	if err := k.validate(v); err != nil {
+		return nil, fmt.Errorf("%s: migrated config is invalid: %w", path, err)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	result.Backup = BackupPath(path, from)
+	if err := util.AtomicWriteFile(result.Backup, data, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("writing backup: %w", err)
+	}
+	if err := util.AtomicWriteFile(path, out, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// MigrateTown upgrades every outdated config file in the town and its rigs.
+// It stops at the first failure; files already migrated keep their backups.
+func MigrateTown(townRoot string, dryRun bool) ([]*MigrationResult, error) {
+	var results []*MigrationResult
+	for _, f := range FindConfigFiles(townRoot) {
+		r, err := MigrateFile(f.Path, f.Kind, dryRun)
+		if err != nil {
+			return results, err
+		}
+		if r != nil {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+func findMigration(kind string, from int) *Migration {
+	for i := range migrations {
+		if migrations[i].Kind == kind && migrations[i].From == from {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
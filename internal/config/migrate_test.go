@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "town.json")
+	original := `{"type": "town", "version": 1, "name": "hq", "created_at": "2026-01-01T00:00:00Z"}`
+	writeConfig(t, path, original)
+	k := KindByName("town")
+
+	// Dry run reports the upgrade without touching the file
+	result, err := MigrateFile(path, k, true)
+	if err != nil {
+		t.Fatalf("MigrateFile dry run: %v", err)
+	}
+	if result == nil || result.From != 1 || result.To != CurrentTownVersion || len(result.Applied) != 1 {
+		t.Fatalf("dry run result = %+v", result)
+	}
+	if _, err := os.Stat(BackupPath(path, 1)); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote a backup")
+	}
+
+	result, err = MigrateFile(path, k, false)
+	if err != nil {
+		t.Fatalf("MigrateFile: %v", err)
+	}
+	backup, err := os.ReadFile(result.Backup)
+	if err != nil || string(backup) != original {
+		t.Errorf("backup = %q, %v; want original contents", backup, err)
+	}
+
+	loaded, err := LoadTownConfig(path)
+	if err != nil {
+		t.Fatalf("LoadTownConfig after migration: %v", err)
+	}
+	if loaded.Version != CurrentTownVersion || loaded.Name != "hq" {
+		t.Errorf("migrated config = %+v", loaded)
+	}
+	if issues := LintFile(path, k); len(issues) != 0 {
+		t.Errorf("migrated config has lint issues: %v", issues)
+	}
+
+	// Already current: nothing to do
+	if result, err := MigrateFile(path, k, false); err != nil || result != nil {
+		t.Errorf("second MigrateFile = %+v, %v; want nil, nil", result, err)
+	}
+}
+
+func TestMigrateFileMissingMigration(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"type": "rig", "version": 1, "name": "gastown"}`)
+
+	k := *KindByName("rig")
+	k.CurrentVersion = 2
+	if _, err := MigrateFile(path, &k, false); err == nil {
+		t.Fatal("MigrateFile without a registered migration should fail")
+	}
+	if _, err := os.Stat(BackupPath(path, 1)); !os.IsNotExist(err) {
+		t.Errorf("failed migration left a backup")
+	}
+}
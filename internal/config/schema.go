@@ -0,0 +1,323 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// Kind describes one kind of config file: its Go type, current schema
+// version, expected "type" tag and semantic validation. Kinds drive JSON
+// Schema export, gt config lint and gt config migrate.
+type Kind struct {
+	// Name identifies the kind on the command line (e.g., "town", "rig-settings").
+	Name string
+
+	// Location describes where files of this kind live, relative to the
+	// town or rig root (e.g., "mayor/town.json").
+	Location string
+
+	// TypeTag is the required value of the file's "type" key ("" if none).
+	TypeTag string
+
+	// CurrentVersion is the newest schema version this build understands.
+	CurrentVersion int
+
+	// newValue returns a pointer to a zero value of the config type.
+	newValue func() interface{}
+
+	// validate runs the loader's semantic checks on a decoded value.
+	validate func(v interface{}) error
+}
+
+// Kinds lists every versioned config file kind, in lint order.
+var Kinds = []*Kind{
+	{
+		Name: "town", Location: "mayor/town.json", TypeTag: "town", CurrentVersion: CurrentTownVersion,
+		newValue: func() interface{} { return &TownConfig{} },
+		validate: func(v interface{}) error { return validateTownConfig(v.(*TownConfig)) },
+	},
+	{
+		Name: "rigs", Location: "mayor/rigs.json", CurrentVersion: CurrentRigsVersion,
+		newValue: func() interface{} { return &RigsConfig{} },
+		validate: func(v interface{}) error { return validateRigsConfig(v.(*RigsConfig)) },
+	},
+	{
+		Name: "mayor", Location: "mayor/config.json", TypeTag: "mayor-config", CurrentVersion: CurrentMayorConfigVersion,
+		newValue: func() interface{} { return &MayorConfig{} },
+		validate: func(v interface{}) error { return validateMayorConfig(v.(*MayorConfig)) },
+	},
+	{
+		Name: "daemon", Location: "mayor/daemon.json", TypeTag: "daemon-patrol-config", CurrentVersion: CurrentDaemonPatrolConfigVersion,
+		newValue: func() interface{} { return &DaemonPatrolConfig{} },
+		validate: func(v interface{}) error { return validateDaemonPatrolConfig(v.(*DaemonPatrolConfig)) },
+	},
+	{
+		Name: "accounts", Location: "mayor/accounts.json", CurrentVersion: CurrentAccountsVersion,
+		newValue: func() interface{} { return &AccountsConfig{} },
+		validate: func(v interface{}) error { return validateAccountsConfig(v.(*AccountsConfig)) },
+	},
+	{
+		Name: "overseer", Location: "mayor/overseer.json", TypeTag: "overseer", CurrentVersion: CurrentOverseerVersion,
+		newValue: func() interface{} { return &OverseerConfig{} },
+		validate: func(v interface{}) error { return validateOverseerConfig(v.(*OverseerConfig)) },
+	},
+	{
+		Name: "town-settings", Location: "settings/config.json", TypeTag: "town-settings", CurrentVersion: CurrentTownSettingsVersion,
+		newValue: func() interface{} { return &TownSettings{} },
+		validate: func(v interface{}) error { return validateTownSettings(v.(*TownSettings)) },
+	},
+	{
+		Name: "agents", Location: "settings/agents.json", CurrentVersion: CurrentAgentRegistryVersion,
+		newValue: func() interface{} { return &AgentRegistry{} },
+		validate: func(v interface{}) error { return validateAgentRegistry(v.(*AgentRegistry)) },
+	},
+	{
+		Name: "escalation", Location: "settings/escalation.json", TypeTag: "escalation", CurrentVersion: CurrentEscalationVersion,
+		newValue: func() interface{} { return &EscalationConfig{} },
+		validate: func(v interface{}) error { return validateEscalationConfig(v.(*EscalationConfig)) },
+	},
+	{
+		Name: "messaging", Location: "config/messaging.json", TypeTag: "messaging", CurrentVersion: CurrentMessagingVersion,
+		newValue: func() interface{} { return &MessagingConfig{} },
+		validate: func(v interface{}) error { return validateMessagingConfig(v.(*MessagingConfig)) },
+	},
+	{
+		Name: "rig", Location: "<rig>/config.json", TypeTag: "rig", CurrentVersion: CurrentRigConfigVersion,
+		newValue: func() interface{} { return &RigConfig{} },
+		validate: func(v interface{}) error { return validateRigConfig(v.(*RigConfig)) },
+	},
+	{
+		Name: "rig-settings", Location: "<rig>/settings/config.json", TypeTag: "rig-settings", CurrentVersion: CurrentRigSettingsVersion,
+		newValue: func() interface{} { return &RigSettings{} },
+		validate: func(v interface{}) error { return validateRigSettings(v.(*RigSettings)) },
+	},
+	{
+		Name: "rig-agents", Location: "<rig>/settings/agents.json", CurrentVersion: CurrentAgentRegistryVersion,
+		newValue: func() interface{} { return &AgentRegistry{} },
+		validate: func(v interface{}) error { return validateAgentRegistry(v.(*AgentRegistry)) },
+	},
+}
+
+// KindByName returns the config kind with the given name, or nil.
+func KindByName(name string) *Kind {
+	for _, k := range Kinds {
+		if k.Name == name {
+			return k
+		}
+	}
+	return nil
+}
+
+// ConfigFile is a config file found in a town, with its kind.
+type ConfigFile struct {
+	Path string
+	Kind *Kind
+}
+
+// FindConfigFiles returns the existing config files of a town and its
+// registered rigs. Rigs are read from mayor/rigs.json; if that file is
+// missing or broken, only town-level files are returned.
+func FindConfigFiles(townRoot string) []ConfigFile {
+	var files []ConfigFile
+	add := func(root string, k *Kind) {
+		rel := strings.TrimPrefix(k.Location, "<rig>/")
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, ConfigFile{Path: path, Kind: k})
+		}
+	}
+
+	for _, k := range Kinds {
+		if !strings.HasPrefix(k.Location, "<rig>/") {
+			add(townRoot, k)
+		}
+	}
+
+	rigs, err := LoadRigsConfig(filepath.Join(townRoot, constants.DirMayor, constants.FileRigsJSON))
+	if err != nil {
+		return files
+	}
+	names := make([]string, 0, len(rigs.Rigs))
+	for name := range rigs.Rigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, k := range Kinds {
+			if strings.HasPrefix(k.Location, "<rig>/") {
+				add(filepath.Join(townRoot, name), k)
+			}
+		}
+	}
+	return files
+}
+
+// JSONSchemaDraft is the JSON Schema dialect of exported schemas.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document, limited to the keywords Gas Town
+// config types need.
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Types lists the allowed JSON types ("object", "string", ...).
+	// Marshaled as "type": a string when there is one, an array otherwise.
+	Types  []string    `json:"-"`
+	Format string      `json:"format,omitempty"`
+	Const  interface{} `json:"const,omitempty"`
+	Max    *int        `json:"maximum,omitempty"`
+
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+
+	// AdditionalProperties is the schema for keys not in Properties.
+	// Nil with Closed set means unknown keys are not allowed.
+	AdditionalProperties *Schema `json:"-"`
+	Closed               bool    `json:"-"`
+}
+
+// MarshalJSON renders Types as "type" and the additionalProperties keyword.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	out := struct {
+		*plain
+		Type                 interface{} `json:"type,omitempty"`
+		AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	}{plain: (*plain)(s)}
+
+	switch len(s.Types) {
+	case 0:
+	case 1:
+		out.Type = s.Types[0]
+	default:
+		out.Type = s.Types
+	}
+	switch {
+	case s.AdditionalProperties != nil:
+		out.AdditionalProperties = s.AdditionalProperties
+	case s.Closed:
+		out.AdditionalProperties = false
+	}
+	return json.Marshal(out)
+}
+
+// allows reports whether the schema accepts the given JSON type.
+// A schema without types accepts anything; "number" accepts integers.
+func (s *Schema) allows(jsonType string) bool {
+	if len(s.Types) == 0 {
+		return true
+	}
+	for _, t := range s.Types {
+		if t == jsonType || (t == "number" && jsonType == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// Schema returns the JSON Schema for this kind of config file.
+// Unknown keys are disallowed at every level, "type" is pinned to the
+// kind's tag and "version" is capped at the current version.
+func (k *Kind) Schema() *Schema {
+	s := schemaForType(reflect.TypeOf(k.newValue()).Elem(), map[reflect.Type]bool{})
+	s.Schema = JSONSchemaDraft
+	s.Title = reflect.TypeOf(k.newValue()).Elem().Name()
+	s.Description = fmt.Sprintf("Gas Town %s config (%s), schema version %d.", k.Name, k.Location, k.CurrentVersion)
+	if k.TypeTag != "" {
+		if t, ok := s.Properties["type"]; ok {
+			t.Const = k.TypeTag
+		}
+	}
+	if v, ok := s.Properties["version"]; ok {
+		max := k.CurrentVersion
+		v.Max = &max
+	}
+	return s
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaForType derives a schema from a Go type using encoding/json rules.
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Types: []string{"string"}, Format: "date-time"}
+	case t.Kind() == reflect.String:
+		s = &Schema{Types: []string{"string"}}
+	case t.Kind() == reflect.Bool:
+		s = &Schema{Types: []string{"boolean"}}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = &Schema{Types: []string{"integer"}}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = &Schema{Types: []string{"number"}}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s = &Schema{Types: []string{"array"}, Items: schemaForType(t.Elem(), visiting)}
+		nullable = true
+	case t.Kind() == reflect.Map:
+		s = &Schema{Types: []string{"object"}, AdditionalProperties: schemaForType(t.Elem(), visiting)}
+		nullable = true
+	case t.Kind() == reflect.Struct:
+		if visiting[t] {
+			// Recursive type: accept any object below this point
+			return &Schema{Types: []string{"object"}}
+		}
+		visiting[t] = true
+		s = &Schema{Types: []string{"object"}, Properties: map[string]*Schema{}, Closed: true}
+		addStructFields(s, t, visiting)
+		delete(visiting, t)
+	default:
+		// interface{} and anything else: any JSON value
+		return &Schema{}
+	}
+
+	if nullable {
+		s.Types = append(s.Types, "null")
+	}
+	return s
+}
+
+// addStructFields adds a struct's JSON fields to s, flattening embedded structs.
+func addStructFields(s *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructFields(s, ft, visiting)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = schemaForType(f.Type, visiting)
+	}
+}
@@ -56,6 +56,76 @@ type TownSettings struct {
 	// This allows cost optimization by using different models for different roles.
 	// Example: {"mayor": "claude-opus", "witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
+
+	// Tiers maps molecule step tiers ("haiku", "sonnet", "opus") to agent
+	// names, so a step marked Tier: haiku runs on a cheaper agent. Without a
+	// mapping, Claude agents get --model <tier> and others ignore the tier.
+	// Example: {"haiku": "claude-haiku", "opus": "claude-opus"}
+	Tiers map[string]string `json:"tiers,omitempty"`
+
+	// Events configures rotation and retention of the raw events log.
+	// Nil uses DefaultEventsConfig.
+	Events *EventsConfig `json:"events,omitempty"`
+
+	// Prime bounds the context gt prime injects into fresh sessions.
+	// Nil means unlimited.
+	Prime *PrimeConfig `json:"prime,omitempty"`
+}
+
+// PrimeConfig sets context budgets for gt prime output. Budgets are in
+// estimated tokens (about 4 characters each); 0 means unlimited. Over
+// budget, low-priority sections are summarized and then replaced by a
+// pointer to the command that shows them.
+type PrimeConfig struct {
+	// Budget is the default budget for all roles and runtimes.
+	Budget int `json:"budget,omitempty"`
+
+	// Budgets overrides Budget by role ("polecat"), agent runtime ("gemini")
+	// or both ("polecat/gemini"). The most specific key wins.
+	// Example: {"polecat": 6000, "witness/claude-haiku": 3000}
+	Budgets map[string]int `json:"budgets,omitempty"`
+}
+
+// BudgetFor returns the prime budget for a role and agent runtime, and the
+// key it came from ("" for the default).
+func (c *PrimeConfig) BudgetFor(role, runtime string) (int, string) {
+	if c == nil {
+		return 0, ""
+	}
+	for _, key := range []string{role + "/" + runtime, role, runtime} {
+		if budget, ok := c.Budgets[key]; ok {
+			return budget, key
+		}
+	}
+	return c.Budget, ""
+}
+
+// EventsConfig represents rotation and retention settings for the town's
+// raw events log (.events.jsonl). The active log is rotated into compressed,
+// dated segments under .events/, which are compacted as events expire.
+type EventsConfig struct {
+	// MaxSizeMB rotates the active log once it reaches this size.
+	// 0 disables size-based rotation.
+	MaxSizeMB int `json:"max_size_mb,omitempty"`
+
+	// MaxAge rotates the active log once its oldest event is this old (e.g., "24h").
+	MaxAge string `json:"max_age,omitempty"`
+
+	// Retention maps a visibility level ("audit" or "feed") to how long its
+	// events are kept in rotated segments (e.g., "720h"). A level that is
+	// missing or "0" is kept forever. Events with visibility "both" are kept
+	// for the longer of the two.
+	Retention map[string]string `json:"retention,omitempty"`
+}
+
+// DefaultEventsConfig returns the default events log rotation settings.
+// Rotation is on; retention is not, so no events expire unless the town
+// configures it.
+func DefaultEventsConfig() *EventsConfig {
+	return &EventsConfig{
+		MaxSizeMB: 64,
+		MaxAge:    "24h",
+	}
 }
 
 // NewTownSettings creates a new TownSettings with defaults.
@@ -92,8 +162,19 @@ type DaemonPatrolConfig struct {
 	Version   int                     `json:"version"`             // schema version
 	Heartbeat *HeartbeatConfig        `json:"heartbeat,omitempty"` // heartbeat settings
 	Patrols   map[string]PatrolConfig `json:"patrols,omitempty"`   // named patrol configurations
+	Metrics   *MetricsConfig          `json:"metrics,omitempty"`   // Prometheus exporter settings
+}
+
+// MetricsConfig configures the daemon's Prometheus/OpenMetrics exporter.
+// When enabled, the daemon serves town health metrics at http://<listen>/metrics.
+type MetricsConfig struct {
+	Enabled bool   `json:"enabled"`          // whether to serve /metrics
+	Listen  string `json:"listen,omitempty"` // host:port, default DefaultMetricsListen
 }
 
+// DefaultMetricsListen is the default address for the daemon's metrics endpoint.
+const DefaultMetricsListen = "127.0.0.1:9464"
+
 // HeartbeatConfig represents heartbeat settings for daemon.
 type HeartbeatConfig struct {
 	Enabled  bool   `json:"enabled"`            // whether heartbeat is enabled
@@ -207,14 +288,16 @@ type WorkflowConfig struct {
 
 // RigSettings represents per-rig behavioral configuration (settings/config.json).
 type RigSettings struct {
-	Type       string            `json:"type"`                  // "rig-settings"
-	Version    int               `json:"version"`               // schema version
-	MergeQueue *MergeQueueConfig `json:"merge_queue,omitempty"` // merge queue settings
-	Theme      *ThemeConfig      `json:"theme,omitempty"`       // tmux theme settings
-	Namepool   *NamepoolConfig   `json:"namepool,omitempty"`    // polecat name pool settings
-	Crew       *CrewConfig       `json:"crew,omitempty"`        // crew startup settings
-	Workflow   *WorkflowConfig   `json:"workflow,omitempty"`    // workflow settings
-	Runtime    *RuntimeConfig    `json:"runtime,omitempty"`     // LLM runtime settings (deprecated: use Agent)
+	Type         string              `json:"type"`                    // "rig-settings"
+	Version      int                 `json:"version"`                 // schema version
+	MergeQueue   *MergeQueueConfig   `json:"merge_queue,omitempty"`   // merge queue settings
+	Theme        *ThemeConfig        `json:"theme,omitempty"`         // tmux theme settings
+	Namepool     *NamepoolConfig     `json:"namepool,omitempty"`      // polecat name pool settings
+	WorktreePool *WorktreePoolConfig `json:"worktree_pool,omitempty"` // warm spare worktrees for polecat spawn
+	Sandbox      *SandboxConfig      `json:"sandbox,omitempty"`       // polecat session sandboxing
+	Crew         *CrewConfig         `json:"crew,omitempty"`          // crew startup settings
+	Workflow     *WorkflowConfig     `json:"workflow,omitempty"`      // workflow settings
+	Runtime      *RuntimeConfig      `json:"runtime,omitempty"`       // LLM runtime settings (deprecated: use Agent)
 
 	// Agent selects which agent preset to use for this rig.
 	// Can be a built-in preset ("claude", "gemini", "codex", "cursor", "auggie", "amp")
@@ -234,6 +317,10 @@ type RigSettings struct {
 	// Overrides TownSettings.RoleAgents for this specific rig.
 	// Example: {"witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
+
+	// Tiers maps molecule step tiers to agent names for this rig.
+	// Overrides TownSettings.Tiers for this specific rig.
+	Tiers map[string]string `json:"tiers,omitempty"`
 }
 
 // CrewConfig represents crew workspace settings for a rig.
@@ -705,6 +792,65 @@ type NamepoolConfig struct {
 	MaxBeforeNumbering int `json:"max_before_numbering,omitempty"`
 }
 
+// WorktreePoolConfig represents warm spare worktree settings for a rig.
+// Spares are idle, pre-synced git worktrees that polecat spawn can claim
+// instead of checking out the repo from scratch.
+type WorktreePoolConfig struct {
+	// Size is the number of idle spares the daemon keeps ready.
+	// 0 (the default) disables pooling.
+	Size int `json:"size"`
+
+	// RefreshInterval is how often spares are re-synced to origin's tip (e.g., "15m").
+	RefreshInterval string `json:"refresh_interval,omitempty"`
+}
+
+// DefaultWorktreePoolConfig returns a WorktreePoolConfig with pooling disabled.
+func DefaultWorktreePoolConfig() *WorktreePoolConfig {
+	return &WorktreePoolConfig{
+		Size:            0,
+		RefreshInterval: "15m",
+	}
+}
+
+// Sandbox network modes.
+const (
+	SandboxNetworkAllow = "allow"
+	SandboxNetworkDeny  = "deny"
+)
+
+// SandboxConfig represents resource and filesystem limits for polecat sessions.
+// Polecats run with permission prompts disabled; the sandbox bounds what they
+// can consume and touch. Starting a polecat fails if an enabled sandbox
+// cannot be applied (the required tools are missing).
+type SandboxConfig struct {
+	// Enabled turns sandboxing on for polecat sessions in this rig.
+	Enabled bool `json:"enabled"`
+
+	// CPUQuota limits CPU time via a cgroup v2 scope (e.g., "200%" = two cores).
+	CPUQuota string `json:"cpu_quota,omitempty"`
+
+	// MemoryMax limits memory via a cgroup v2 scope (e.g., "4G").
+	MemoryMax string `json:"memory_max,omitempty"`
+
+	// TasksMax limits the number of processes and threads (0 = no limit).
+	TasksMax int `json:"tasks_max,omitempty"`
+
+	// Filesystem restricts writes to the polecat worktree, shared beads,
+	// and the git object store, and masks the user's home directory.
+	Filesystem bool `json:"filesystem"`
+
+	// Network is "allow" (default) or "deny". Deny runs the session in an
+	// empty network namespace, which also blocks hosted model APIs unless
+	// the runtime talks to a local endpoint.
+	Network string `json:"network,omitempty"`
+
+	// ReadPaths are extra paths visible read-only inside the sandbox.
+	ReadPaths []string `json:"read_paths,omitempty"`
+
+	// WritePaths are extra writable paths inside the sandbox (e.g., build caches).
+	WritePaths []string `json:"write_paths,omitempty"`
+}
+
 // DefaultNamepoolConfig returns a NamepoolConfig with sensible defaults.
 func DefaultNamepoolConfig() *NamepoolConfig {
 	return &NamepoolConfig{
@@ -762,6 +908,18 @@ type MessagingConfig struct {
 	// Like mailing lists but for tmux send-keys instead of durable mail.
 	// Example: {"workers": ["gastown/polecats/*", "gastown/crew/*"], "witnesses": ["*/witness"]}
 	NudgeChannels map[string][]string `json:"nudge_channels,omitempty"`
+
+	// Rules filter mail on delivery, keyed by recipient address pattern.
+	// Keys are an exact address ("mayor/") or a pattern ("*/witness").
+	// Example: {"*/witness": [{"match": {"subject": "^POLECAT_DONE"}, "actions": ["mark-read"]}]}
+	Rules map[string][]MailRule `json:"rules,omitempty"`
+
+	// QuietPolicies are time-based do-not-disturb policies, keyed by agent
+	// address pattern like Rules. While a policy is active, nudges, broadcasts
+	// and new-mail notifications are held and delivered as a digest when it
+	// ends; urgent ones still go through.
+	// Example: {"gastown/*": [{"quiet_hours": {"start": "22:00", "end": "07:00"}}]}
+	QuietPolicies map[string][]QuietPolicy `json:"quiet_policies,omitempty"`
 }
 
 // QueueConfig represents a work queue configuration.
@@ -784,6 +942,84 @@ type AnnounceConfig struct {
 	RetainCount int `json:"retain_count,omitempty"`
 }
 
+// MailRule is a delivery-time filter for a recipient's mail.
+// Every matching rule's actions apply, in order, until a rule with Stop.
+type MailRule struct {
+	// Name identifies the rule in gt mail rules output (optional).
+	Name string `json:"name,omitempty"`
+
+	// Match selects messages; all set fields must match. Empty matches all.
+	Match MailRuleMatch `json:"match"`
+
+	// Actions to take on matching messages:
+	//   - "archive"            → Archive without showing in the inbox
+	//   - "mark-read"          → Deliver already read
+	//   - "forward:<address>"  → Also send a copy to an address or list:name
+	//   - "priority:<level>"   → Re-prioritize (low, normal, high, urgent)
+	//   - "nudge"              → Nudge the recipient's session instead of mailing
+	//                            (falls back to mail if the session isn't running)
+	Actions []string `json:"actions"`
+
+	// Stop ends rule evaluation after this rule matches.
+	Stop bool `json:"stop,omitempty"`
+}
+
+// MailRuleMatch holds the conditions of a MailRule.
+type MailRuleMatch struct {
+	// From is a sender address pattern ("gastown/*", "*/witness", "mayor/").
+	From string `json:"from,omitempty"`
+
+	// Subject is a regular expression matched against the subject.
+	Subject string `json:"subject,omitempty"`
+
+	// Type is a message type (task, scavenge, notification, reply).
+	Type string `json:"type,omitempty"`
+
+	// Priority is a priority level (low, normal, high, urgent).
+	Priority string `json:"priority,omitempty"`
+
+	// Thread is a thread ID.
+	Thread string `json:"thread,omitempty"`
+}
+
+// QuietPolicy is a do-not-disturb window for an agent or role. The policy is
+// active when any of its conditions holds.
+type QuietPolicy struct {
+	// Name identifies the policy in gt dnd status output (optional).
+	Name string `json:"name,omitempty"`
+
+	// QuietHours mutes notifications during a daily time window.
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+
+	// DuringWaitStep mutes notifications while the agent is in a molecule
+	// step of type wait (gt mol step await-signal).
+	DuringWaitStep bool `json:"during_wait_step,omitempty"`
+}
+
+// QuietHours is a daily window in HH:MM (24h) local time. A window whose
+// end is before its start runs past midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	// Days limits the window to days it starts on ("mon".."sun"); empty = every day.
+	Days []string `json:"days,omitempty"`
+
+	// Timezone is an IANA zone name (default: the machine's local zone).
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// QuietDays maps quiet_hours day names to weekdays.
+var QuietDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
 // CurrentMessagingVersion is the current schema version for MessagingConfig.
 const CurrentMessagingVersion = 1
 
@@ -796,6 +1032,8 @@ func NewMessagingConfig() *MessagingConfig {
 		Queues:        make(map[string]QueueConfig),
 		Announces:     make(map[string]AnnounceConfig),
 		NudgeChannels: make(map[string][]string),
+		Rules:         make(map[string][]MailRule),
+		QuietPolicies: make(map[string][]QuietPolicy),
 	}
 }
 
@@ -185,6 +185,10 @@ func RoleEmoji(role string) string {
 // Used to identify if a tmux pane is at a shell prompt vs running a command.
 var SupportedShells = []string{"bash", "zsh", "sh", "fish", "tcsh", "ksh"}
 
+// SandboxWrappers lists launcher binaries that sandboxed agents run under.
+// A pane reporting one of these is checked for an agent further down its process tree.
+var SandboxWrappers = []string{"bwrap", "systemd-run"}
+
 // Path helpers construct common paths.
 
 // MayorRigsPath returns the path to rigs.json within a town root.
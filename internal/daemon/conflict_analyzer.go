@@ -0,0 +1,40 @@
+package daemon
+
+import (
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// analyzeConflicts runs conflict pre-detection for every operational rig.
+// Each pass computes file/hunk overlap between in-flight polecat branches
+// (and against the merge target), saves the matrix for `gt mq status` and
+// the dashboard, and mails the witness and polecats about new hunk overlaps.
+func (d *Daemon) analyzeConflicts() {
+	for _, rigName := range d.getKnownRigs() {
+		if operational, reason := d.isRigOperational(rigName); !operational {
+			d.logger.Printf("Skipping conflict analysis for %s: %s", rigName, reason)
+			continue
+		}
+
+		r := &rig.Rig{
+			Name: rigName,
+			Path: filepath.Join(d.config.TownRoot, rigName),
+		}
+
+		matrix, fresh, err := refinery.NewConflictAnalyzer(r).Run()
+		if err != nil {
+			d.logger.Printf("Conflict analysis for %s failed: %v", rigName, err)
+			continue
+		}
+
+		for _, o := range fresh {
+			d.logger.Printf("Conflict risk in %s: %s and %s overlap in %v", rigName, o.A, o.B, o.HunkFiles)
+		}
+		if len(fresh) > 0 {
+			d.logger.Printf("Conflict analysis for %s: %d branches, %d overlaps, %d new warnings",
+				rigName, len(matrix.Branches), len(matrix.Overlaps), len(fresh))
+		}
+	}
+}
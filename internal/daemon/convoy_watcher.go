@@ -15,7 +15,8 @@ import (
 
 // ConvoyWatcher monitors bd activity for issue closes and triggers convoy completion checks.
 // When an issue closes, it checks if the issue is tracked by any convoy and runs the
-// completion check if all tracked issues are now closed.
+// completion check if all tracked issues are now closed. When a convoy itself lands,
+// the convoys waiting for it are unblocked and checked the same way.
 type ConvoyWatcher struct {
 	townRoot string
 	ctx      context.Context
@@ -136,6 +137,14 @@ func (w *ConvoyWatcher) processLine(line string) {
 
 	w.logger("convoy watcher: detected close of %s", event.IssueID)
 
+	// A landed convoy unblocks the convoys waiting for it
+	if downstream := w.getDownstreamConvoys(event.IssueID); len(downstream) > 0 {
+		w.logger("convoy watcher: %s landed, unblocking %d convoy(s): %v", event.IssueID, len(downstream), downstream)
+		for _, convoyID := range downstream {
+			w.checkConvoyCompletion(convoyID)
+		}
+	}
+
 	// Check if this issue is tracked by any convoy
 	convoyIDs := w.getTrackingConvoys(event.IssueID)
 	if len(convoyIDs) == 0 {
@@ -144,12 +153,50 @@ func (w *ConvoyWatcher) processLine(line string) {
 
 	w.logger("convoy watcher: %s is tracked by %d convoy(s): %v", event.IssueID, len(convoyIDs), convoyIDs)
 
-	// Check each tracking convoy for completion
+	// Feed formula convoys the units this close unblocked, then check each
+	// tracking convoy for completion
 	for _, convoyID := range convoyIDs {
+		if w.isFormulaConvoy(convoyID) {
+			w.feedFormulaConvoy(convoyID)
+		}
 		w.checkConvoyCompletion(convoyID)
 	}
 }
 
+// isFormulaConvoy reports whether an open convoy was created by gt formula
+// run, whose units are dispatched as their dependencies close.
+func (w *ConvoyWatcher) isFormulaConvoy(convoyID string) bool {
+	dbPath := filepath.Join(w.townRoot, ".beads", "beads.db")
+	query := fmt.Sprintf(`
+		SELECT id AS issue_id FROM issues
+		WHERE id = '%s'
+		AND status = 'open'
+		AND description LIKE 'Formula:%%'
+	`, strings.ReplaceAll(convoyID, "'", "''"))
+
+	return len(queryIssueIDs(dbPath, query)) > 0
+}
+
+// feedFormulaConvoy runs gt formula feed to sling the convoy's newly
+// unblocked units.
+func (w *ConvoyWatcher) feedFormulaConvoy(convoyID string) {
+	w.logger("convoy watcher: feeding formula convoy %s", convoyID)
+
+	feedCmd := exec.Command("gt", "formula", "feed", convoyID)
+	feedCmd.Dir = w.townRoot
+	var feedStdout, feedStderr bytes.Buffer
+	feedCmd.Stdout = &feedStdout
+	feedCmd.Stderr = &feedStderr
+
+	if err := feedCmd.Run(); err != nil {
+		w.logger("convoy watcher: gt formula feed %s failed: %v: %s", convoyID, err, feedStderr.String())
+		return
+	}
+	if output := strings.TrimSpace(feedStdout.String()); output != "" {
+		w.logger("convoy watcher: %s", output)
+	}
+}
+
 // getTrackingConvoys returns convoy IDs that track the given issue.
 func (w *ConvoyWatcher) getTrackingConvoys(issueID string) []string {
 	townBeads := filepath.Join(w.townRoot, ".beads")
@@ -167,6 +214,30 @@ func (w *ConvoyWatcher) getTrackingConvoys(issueID string) []string {
 		AND (depends_on_id = '%s' OR depends_on_id LIKE '%%:%s')
 	`, safeIssueID, safeIssueID)
 
+	return queryIssueIDs(dbPath, query)
+}
+
+// getDownstreamConvoys returns open convoys that wait for the given convoy
+// (gt convoy after). Empty unless issueID is itself a convoy.
+func (w *ConvoyWatcher) getDownstreamConvoys(issueID string) []string {
+	dbPath := filepath.Join(w.townRoot, ".beads", "beads.db")
+	safeIssueID := strings.ReplaceAll(issueID, "'", "''")
+
+	// Downstream convoy -> upstream convoy is a "blocks" dependency
+	query := fmt.Sprintf(`
+		SELECT DISTINCT d.issue_id FROM dependencies d
+		JOIN issues i ON i.id = d.issue_id
+		WHERE d.type = 'blocks'
+		AND d.depends_on_id = '%s'
+		AND i.issue_type = 'convoy'
+		AND i.status = 'open'
+	`, safeIssueID)
+
+	return queryIssueIDs(dbPath, query)
+}
+
+// queryIssueIDs runs a query selecting issue_id and returns the IDs.
+func queryIssueIDs(dbPath, query string) []string {
 	queryCmd := exec.Command("sqlite3", "-json", dbPath, query)
 	var stdout bytes.Buffer
 	queryCmd.Stdout = &stdout
@@ -182,11 +253,11 @@ func (w *ConvoyWatcher) getTrackingConvoys(issueID string) []string {
 		return nil
 	}
 
-	convoyIDs := make([]string, 0, len(results))
+	ids := make([]string, 0, len(results))
 	for _, r := range results {
-		convoyIDs = append(convoyIDs, r.IssueID)
+		ids = append(ids, r.IssueID)
 	}
-	return convoyIDs
+	return ids
 }
 
 // checkConvoyCompletion checks if all issues tracked by a convoy are closed.
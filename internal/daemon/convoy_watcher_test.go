@@ -2,6 +2,9 @@ package daemon
 
 import (
 	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -87,3 +90,45 @@ func TestIsCloseEvent(t *testing.T) {
 		t.Error("should not detect create as close")
 	}
 }
+
+func TestGetDownstreamConvoys(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+
+	townRoot := t.TempDir()
+	beadsDir := filepath.Join(townRoot, ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	schema := `
+		CREATE TABLE issues (id TEXT PRIMARY KEY, issue_type TEXT, status TEXT);
+		CREATE TABLE dependencies (issue_id TEXT, depends_on_id TEXT, type TEXT);
+		INSERT INTO issues VALUES
+			('hq-cv-backend', 'convoy', 'closed'),
+			('hq-cv-client', 'convoy', 'open'),
+			('hq-cv-docs', 'convoy', 'closed'),
+			('hq-task', 'task', 'open');
+		INSERT INTO dependencies VALUES
+			('hq-cv-client', 'hq-cv-backend', 'blocks'),
+			('hq-cv-docs', 'hq-cv-backend', 'blocks'),
+			('hq-task', 'hq-cv-backend', 'blocks'),
+			('hq-cv-backend', 'gt-abc', 'tracks');`
+	if out, err := exec.Command("sqlite3", filepath.Join(beadsDir, "beads.db"), schema).CombinedOutput(); err != nil {
+		t.Fatalf("creating db: %v: %s", err, out)
+	}
+
+	w := NewConvoyWatcher(townRoot, t.Logf)
+
+	// Only open convoys wait on the landed one
+	got := w.getDownstreamConvoys("hq-cv-backend")
+	if len(got) != 1 || got[0] != "hq-cv-client" {
+		t.Errorf("getDownstreamConvoys() = %v, want [hq-cv-client]", got)
+	}
+	if got := w.getDownstreamConvoys("gt-abc"); len(got) != 0 {
+		t.Errorf("getDownstreamConvoys(issue) = %v, want none", got)
+	}
+	if got := w.getTrackingConvoys("gt-abc"); len(got) != 1 || got[0] != "hq-cv-backend" {
+		t.Errorf("getTrackingConvoys() = %v, want [hq-cv-backend]", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -44,10 +45,19 @@ type Daemon struct {
 	cancel        context.CancelFunc
 	curator       *feed.Curator
 	convoyWatcher *ConvoyWatcher
+	metricsServer *http.Server
 
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
 	recentDeaths []sessionDeath
+
+	// Sessions with a tracked nudge still being delivered
+	nudgesMu       sync.Mutex
+	nudgesInFlight map[string]bool
+
+	// Rigs whose worktree pool is being replenished or refreshed
+	poolsMu       sync.Mutex
+	poolsInFlight map[string]bool
 }
 
 // sessionDeath records a detected session death for mass death analysis.
@@ -152,6 +162,9 @@ func (d *Daemon) Run() error {
 		d.logger.Println("Convoy watcher started")
 	}
 
+	// Serve Prometheus metrics if enabled in mayor/daemon.json
+	d.startMetricsServer(state)
+
 	// Initial heartbeat
 	d.heartbeat(state)
 
@@ -233,6 +246,24 @@ func (d *Daemon) heartbeat(state *State) {
 	// This validates tmux sessions are still alive for polecats with work-on-hook
 	d.checkPolecatSessionHealth()
 
+	// 12. Advance scheduled swarms (dispatch ready tasks, re-queue stalled ones)
+	d.scheduleSwarms()
+
+	// 13. Pre-detect merge conflicts between in-flight polecat branches
+	d.analyzeConflicts()
+
+	// 14. Replenish and refresh warm spare worktrees for fast polecat spawn
+	d.maintainWorktreePools()
+
+	// 15. Rotate the events log into segments and expire old events
+	d.rotateEvents()
+
+	// 16. Deliver due scheduled mail, archive expired mail, refresh the mail index
+	d.processScheduledMail()
+
+	// 17. Deliver nudges held by quiet policies once their window has ended
+	d.flushHeldNudges()
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -380,9 +411,7 @@ func (d *Daemon) checkDeaconHeartbeat() {
 	} else {
 		// Stuck but not critically - nudge to wake up
 		d.logger.Printf("Deacon stuck for %s - nudging session", age.Round(time.Minute))
-		if err := d.tmux.NudgeSession(sessionName, "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness"); err != nil {
-			d.logger.Printf("Error nudging stuck Deacon: %v", err)
-		}
+		d.reliableNudge(sessionName, "deacon/", "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness")
 	}
 }
 
@@ -594,6 +623,12 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("Convoy watcher stopped")
 	}
 
+	// Stop metrics server
+	if d.metricsServer != nil {
+		_ = d.metricsServer.Close()
+		d.logger.Println("Metrics server stopped")
+	}
+
 	state.Running = false
 	if err := SaveState(d.config.TownRoot, state); err != nil {
 		d.logger.Printf("Warning: failed to save final state: %v", err)
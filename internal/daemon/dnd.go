@@ -0,0 +1,26 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/dnd"
+)
+
+// flushHeldNudges delivers a digest of the nudges and mail notifications
+// held by quiet policies to each agent whose quiet window has ended.
+// Agents whose session isn't running keep their digest until it is.
+func (d *Daemon) flushHeldNudges() {
+	flushed, err := dnd.Flush(d.config.TownRoot, time.Now(), func(session, text string) (bool, error) {
+		running, err := d.tmux.HasSession(session)
+		if err != nil || !running {
+			return false, nil
+		}
+		return true, d.tmux.NudgeSession(session, text)
+	})
+	if err != nil {
+		d.logger.Printf("Flushing held nudges failed: %v", err)
+	}
+	if flushed > 0 {
+		d.logger.Printf("Delivered held-nudge digests to %d agent(s)", flushed)
+	}
+}
@@ -0,0 +1,29 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// rotateEvents rotates the town's .events.jsonl into a compressed segment once
+// it outgrows the configured size or age, then drops segment events that are
+// past their visibility level's retention (events in town settings).
+func (d *Daemon) rotateEvents() {
+	policy := events.LoadRotationPolicy(d.config.TownRoot)
+	now := time.Now()
+
+	seg, err := events.Rotate(d.config.TownRoot, policy, now)
+	if err != nil {
+		d.logger.Printf("Events rotation failed: %v", err)
+	} else if seg != nil {
+		d.logger.Printf("Rotated events log into %s (%d events)", seg.File, seg.Count)
+	}
+
+	dropped, err := events.Compact(d.config.TownRoot, policy, now)
+	if err != nil {
+		d.logger.Printf("Events compaction failed: %v", err)
+	} else if dropped > 0 {
+		d.logger.Printf("Expired %d event(s) from rotated segments", dropped)
+	}
+}
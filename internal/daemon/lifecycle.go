@@ -402,7 +402,7 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 	// Send propulsion nudge to trigger autonomous execution.
 	// Wait for beacon to be fully processed (needs to be separate prompt)
 	time.Sleep(2 * time.Second)
-	_ = d.tmux.NudgeSession(sessionName, session.PropulsionNudgeForRole(parsed.RoleType, workDir)) // Non-fatal
+	d.reliableNudge(sessionName, recipient, session.PropulsionNudgeForRole(parsed.RoleType, workDir))
 
 	return nil
 }
@@ -821,7 +821,11 @@ func (d *Daemon) checkRigGUPPViolations(rigName string) {
 				d.logger.Printf("GUPP violation: agent %s has hook_bead=%s but hasn't updated in %v (timeout: %v)",
 					agent.ID, agent.HookBead, age.Round(time.Minute), GUPPViolationTimeout)
 
-				// Notify the witness for this rig
+				// Nudge the stuck agent to resume its hooked work, and notify
+				// the witness for this rig (which is the only mail it gets)
+				d.trackedNudge(sessionName, rigName+"/"+polecatName, fmt.Sprintf(
+					"GUPP: you have %s on your hook but haven't progressed in %v. Run 'gt hook' and continue the work.",
+					agent.HookBead, age.Round(time.Minute)), guppNudgeOptions)
 				d.notifyWitnessOfGUPP(rigName, agent.ID, agent.HookBead, age)
 			}
 		}
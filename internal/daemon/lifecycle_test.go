@@ -1,10 +1,12 @@
 package daemon
 
 import (
+	"bytes"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -252,3 +254,28 @@ func TestBeadsMessage_Serialization(t *testing.T) {
 		t.Errorf("From mismatch")
 	}
 }
+
+func TestTrackedNudge_OnePerSession(t *testing.T) {
+	var logs bytes.Buffer
+	d := testDaemon()
+	d.logger = log.New(&logs, "", 0)
+
+	// A nudge still being retried blocks another to the same session
+	d.nudgesInFlight = map[string]bool{"gt-gastown-nux": true}
+	d.trackedNudge("gt-gastown-nux", "gastown/nux", "GUPP: resume", guppNudgeOptions)
+	if !strings.Contains(logs.String(), "still in flight") {
+		t.Errorf("second nudge not skipped; log: %q", logs.String())
+	}
+	if len(d.nudgesInFlight) != 1 {
+		t.Errorf("nudgesInFlight = %v", d.nudgesInFlight)
+	}
+}
+
+func TestGUPPNudgeOptions_NoMailFallback(t *testing.T) {
+	if guppNudgeOptions.MailFallback {
+		t.Error("GUPP nudges must not fall back to mail")
+	}
+	if !daemonNudgeOptions.MailFallback || guppNudgeOptions.Attempts != daemonNudgeOptions.Attempts {
+		t.Errorf("guppNudgeOptions = %+v, want daemonNudgeOptions without mail", guppNudgeOptions)
+	}
+}
@@ -0,0 +1,35 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// processScheduledMail delivers deferred mail that has come due
+// (gt mail send --at/--in), archives unread mail past its expiry
+// (gt mail send --expires) and rebuilds the mail search index once it is
+// missing or stale; sends and reads keep it current in between.
+func (d *Daemon) processScheduledMail() {
+	router := mail.NewRouterWithTownRoot(d.config.TownRoot, d.config.TownRoot)
+	now := time.Now()
+
+	delivered, err := router.DeliverDue(now)
+	if err != nil {
+		d.logger.Printf("Scheduled mail delivery failed: %v", err)
+	}
+	if delivered > 0 {
+		d.logger.Printf("Delivered %d scheduled message(s)", delivered)
+	}
+
+	archived, err := router.ArchiveExpired(now)
+	if err != nil {
+		d.logger.Printf("Expired mail cleanup failed: %v", err)
+	} else if archived > 0 {
+		d.logger.Printf("Archived %d expired message(s)", archived)
+	}
+
+	if _, err := router.RefreshIndex(now); err != nil {
+		d.logger.Printf("Mail index rebuild failed: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/metrics"
+)
+
+// metricsCacheTTL bounds how often a scrape re-collects. Collection shells out
+// to bd (polecats, escalations, costs), so back-to-back scrapes from several
+// Prometheus servers reuse the last result.
+const metricsCacheTTL = 30 * time.Second
+
+// metricsServer serves the town's health metrics at /metrics.
+type metricsServer struct {
+	d       *Daemon
+	started time.Time
+
+	mu       sync.Mutex
+	cached   []byte
+	cachedAt time.Time
+}
+
+// startMetricsServer starts the Prometheus exporter if metrics are enabled in
+// mayor/daemon.json. The server is closed on daemon shutdown.
+func (d *Daemon) startMetricsServer(state *State) {
+	cfg, err := config.LoadDaemonPatrolConfig(config.DaemonPatrolConfigPath(d.config.TownRoot))
+	if err != nil {
+		if !errors.Is(err, config.ErrNotFound) {
+			d.logger.Printf("Warning: metrics disabled, cannot load daemon config: %v", err)
+		}
+		return
+	}
+	if cfg.Metrics == nil || !cfg.Metrics.Enabled {
+		return
+	}
+	listen := cfg.Metrics.Listen
+	if listen == "" {
+		listen = config.DefaultMetricsListen
+	}
+
+	ms := &metricsServer{d: d, started: state.StartedAt}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", ms)
+
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	d.metricsServer = server
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			d.logger.Printf("Metrics server stopped: %v", err)
+		}
+	}()
+	d.logger.Printf("Serving metrics at http://%s/metrics", listen)
+}
+
+// ServeHTTP renders the cached metrics, re-collecting once the cache expires.
+func (ms *metricsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ms.mu.Lock()
+	if ms.cached == nil || time.Since(ms.cachedAt) > metricsCacheTTL {
+		var buf bytes.Buffer
+		if err := metrics.Write(&buf, ms.collect()); err != nil {
+			ms.mu.Unlock()
+			http.Error(w, "Failed to render metrics", http.StatusInternalServerError)
+			return
+		}
+		ms.cached = buf.Bytes()
+		ms.cachedAt = time.Now()
+	}
+	body := ms.cached
+	ms.mu.Unlock()
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	_, _ = w.Write(body)
+}
+
+// collect gathers town metrics plus the daemon's own state.
+func (ms *metricsServer) collect() []*metrics.Family {
+	townRoot := ms.d.config.TownRoot
+	families := metrics.NewCollector(townRoot, ms.d.getKnownRigs(), ms.started).Collect()
+	return append(daemonMetrics(townRoot, time.Now()), families...)
+}
+
+// daemonMetrics reports heartbeat progress from the persisted daemon state.
+func daemonMetrics(townRoot string, now time.Time) []*metrics.Family {
+	heartbeats := &metrics.Family{
+		Name: "gastown_daemon_heartbeats",
+		Help: "Daemon heartbeat cycles completed since start.",
+		Type: metrics.TypeCounter,
+	}
+	heartbeatAge := &metrics.Family{
+		Name: "gastown_daemon_heartbeat_age_seconds",
+		Help: "Seconds since the daemon last completed a heartbeat (-1 if never).",
+		Type: metrics.TypeGauge,
+		Unit: "seconds",
+	}
+	startTime := &metrics.Family{
+		Name: "gastown_daemon_start_time_seconds",
+		Help: "Unix time the daemon started.",
+		Type: metrics.TypeGauge,
+		Unit: "seconds",
+	}
+
+	state, err := LoadState(townRoot)
+	if err != nil {
+		state = &State{}
+	}
+	heartbeats.Set(float64(state.HeartbeatCount), nil)
+	if state.LastHeartbeat.IsZero() {
+		heartbeatAge.Set(-1, nil)
+	} else {
+		heartbeatAge.Set(now.Sub(state.LastHeartbeat).Seconds(), nil)
+	}
+	startTime.Set(float64(state.StartedAt.Unix()), nil)
+
+	return []*metrics.Family{heartbeats, heartbeatAge, startTime}
+}
@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/nudge"
+)
+
+// daemonNudgeOptions keeps tracked daemon nudges short: they run in the
+// background, and unconfirmed nudges become mail rather than being lost.
+var daemonNudgeOptions = nudge.Options{
+	Attempts:     3,
+	AckTimeout:   20 * time.Second,
+	Backoff:      10 * time.Second,
+	MailFallback: true,
+}
+
+// guppNudgeOptions are daemonNudgeOptions without the mail fallback. The
+// GUPP check repeats every heartbeat and mails the witness itself, so an
+// unresponsive polecat would otherwise collect a mail per heartbeat.
+var guppNudgeOptions = func() nudge.Options {
+	opts := daemonNudgeOptions
+	opts.MailFallback = false
+	return opts
+}()
+
+// reliableNudge delivers a nudge in the background with receipt tracking,
+// retries with backoff, and a mail fallback to address. The heartbeat
+// doesn't wait for the outcome; it is logged.
+func (d *Daemon) reliableNudge(sessionName, address, message string) {
+	d.trackedNudge(sessionName, address, message, daemonNudgeOptions)
+}
+
+// trackedNudge delivers a nudge in the background with the given options.
+// A session gets one tracked nudge at a time: while one is still being
+// retried, later ones are dropped rather than piling up.
+func (d *Daemon) trackedNudge(sessionName, address, message string, opts nudge.Options) {
+	d.nudgesMu.Lock()
+	if d.nudgesInFlight[sessionName] {
+		d.nudgesMu.Unlock()
+		d.logger.Printf("Nudge to %s skipped: previous nudge still in flight", sessionName)
+		return
+	}
+	if d.nudgesInFlight == nil {
+		d.nudgesInFlight = make(map[string]bool)
+	}
+	d.nudgesInFlight[sessionName] = true
+	d.nudgesMu.Unlock()
+
+	go func() {
+		defer func() {
+			d.nudgesMu.Lock()
+			delete(d.nudgesInFlight, sessionName)
+			d.nudgesMu.Unlock()
+		}()
+
+		rec, err := nudge.NewDeliverer(d.config.TownRoot, d.tmux).Send(nudge.Request{
+			Session: sessionName,
+			Address: address,
+			From:    "deacon/",
+			Message: message,
+		}, opts)
+		switch {
+		case err != nil:
+			d.logger.Printf("Nudge to %s failed: %v", sessionName, err)
+		case rec.Delivered():
+			// Confirmed; nothing to report
+		case rec.Status == nudge.StatusMailed:
+			d.logger.Printf("Nudge %s to %s unconfirmed after %d attempt(s); mailed to %s",
+				rec.ID, sessionName, rec.Attempts, address)
+		default:
+			d.logger.Printf("Nudge %s to %s unconfirmed after %d attempt(s): %s",
+				rec.ID, sessionName, rec.Attempts, rec.Error)
+		}
+	}()
+}
@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/swarm"
+)
+
+// scheduleSwarms advances every scheduled swarm in every rig.
+// Swarms are scheduled with `gt swarm schedule`; each heartbeat dispatches
+// newly unblocked tasks, re-queues tasks whose polecat died, and updates
+// the swarm state. Witness patrols can drive the same logic via `gt swarm tick`.
+func (d *Daemon) scheduleSwarms() {
+	for _, rigName := range d.getKnownRigs() {
+		if operational, reason := d.isRigOperational(rigName); !operational {
+			d.logger.Printf("Skipping swarm scheduling for %s: %s", rigName, reason)
+			continue
+		}
+		d.scheduleRigSwarms(rigName)
+	}
+}
+
+// scheduleRigSwarms advances the scheduled swarms in one rig.
+func (d *Daemon) scheduleRigSwarms(rigName string) {
+	r := &rig.Rig{
+		Name: rigName,
+		Path: filepath.Join(d.config.TownRoot, rigName),
+	}
+
+	schedules, err := swarm.ListSchedules(r.Path)
+	if err != nil {
+		d.logger.Printf("Error listing swarm schedules for %s: %v", rigName, err)
+		return
+	}
+	if len(schedules) == 0 {
+		return
+	}
+
+	scheduler := swarm.NewScheduler(
+		swarm.NewManager(r),
+		&swarm.SlingDispatcher{TownRoot: d.config.TownRoot},
+		d.isSwarmWorkerAlive,
+	)
+
+	for _, sched := range schedules {
+		if sched.State.IsTerminal() {
+			continue
+		}
+
+		result, err := scheduler.Tick(sched)
+		if err != nil {
+			d.logger.Printf("Swarm %s: scheduler tick failed: %v", sched.SwarmID, err)
+			continue
+		}
+		if err := swarm.SaveSchedule(r.Path, sched); err != nil {
+			d.logger.Printf("Swarm %s: failed to save schedule: %v", sched.SwarmID, err)
+		}
+
+		for _, id := range result.Requeued {
+			d.logger.Printf("Swarm %s: re-queued stalled task %s", sched.SwarmID, id)
+		}
+		for _, id := range result.Dispatched {
+			d.logger.Printf("Swarm %s: dispatched %s", sched.SwarmID, id)
+		}
+		for _, e := range result.Errors {
+			d.logger.Printf("Swarm %s: %s", sched.SwarmID, e)
+		}
+		if sched.State == swarm.SwarmFailed {
+			d.logger.Printf("Swarm %s failed: %s", sched.SwarmID, sched.Error)
+		}
+	}
+}
+
+// isSwarmWorkerAlive checks whether a task assignee's polecat session is running.
+// Assignees look like "<rig>/polecats/<name>" or "<rig>/<name>".
+func (d *Daemon) isSwarmWorkerAlive(rigName, assignee string) bool {
+	name := assignee
+	if idx := strings.LastIndex(assignee, "/"); idx >= 0 {
+		name = assignee[idx+1:]
+	}
+	if name == "" {
+		return false
+	}
+	return d.tmux.IsClaudeRunning(session.PolecatSessionName(rigName, name))
+}
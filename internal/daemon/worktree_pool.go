@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// maintainWorktreePools keeps each rig's warm spare worktrees topped up and
+// synced to origin. Rigs opt in via worktree_pool.size in settings/config.json;
+// polecat spawn claims spares instead of checking out a fresh worktree.
+// Checkouts and fetches are slow, so they run in the background.
+func (d *Daemon) maintainWorktreePools() {
+	for _, rigName := range d.getKnownRigs() {
+		if operational, reason := d.isRigOperational(rigName); !operational {
+			d.logger.Printf("Skipping worktree pool for %s: %s", rigName, reason)
+			continue
+		}
+
+		r := &rig.Rig{
+			Name: rigName,
+			Path: filepath.Join(d.config.TownRoot, rigName),
+		}
+		pool := polecat.NewManager(r, git.NewGit(r.Path)).WorktreePool()
+
+		// Size 0 with no spares left is the common case: nothing to do
+		if pool.Size() == 0 {
+			if spares, _ := pool.List(); len(spares) == 0 {
+				continue
+			}
+		}
+
+		d.trackedPoolMaintenance(rigName, pool)
+	}
+}
+
+// trackedPoolMaintenance replenishes and refreshes a rig's pool in the
+// background. A rig gets one run at a time: while one is still going,
+// later heartbeats skip the rig rather than piling up checkouts.
+func (d *Daemon) trackedPoolMaintenance(rigName string, pool *polecat.WorktreePool) {
+	d.poolsMu.Lock()
+	if d.poolsInFlight[rigName] {
+		d.poolsMu.Unlock()
+		d.logger.Printf("Worktree pool for %s: skipped, previous maintenance still running", rigName)
+		return
+	}
+	if d.poolsInFlight == nil {
+		d.poolsInFlight = make(map[string]bool)
+	}
+	d.poolsInFlight[rigName] = true
+	d.poolsMu.Unlock()
+
+	go func() {
+		defer func() {
+			d.poolsMu.Lock()
+			delete(d.poolsInFlight, rigName)
+			d.poolsMu.Unlock()
+		}()
+
+		created, err := pool.Replenish()
+		if err != nil {
+			d.logger.Printf("Worktree pool for %s: replenish failed: %v", rigName, err)
+		} else if created > 0 {
+			d.logger.Printf("Worktree pool for %s: created %d spare(s)", rigName, created)
+		}
+
+		refreshed, err := pool.Refresh()
+		if err != nil {
+			d.logger.Printf("Worktree pool for %s: refresh failed: %v", rigName, err)
+		} else if refreshed > 0 {
+			d.logger.Printf("Worktree pool for %s: refreshed %d spare(s)", rigName, refreshed)
+		}
+	}()
+}
@@ -0,0 +1,24 @@
+package daemon
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestTrackedPoolMaintenance_OnePerRig(t *testing.T) {
+	var logs bytes.Buffer
+	d := testDaemon()
+	d.logger = log.New(&logs, "", 0)
+
+	// A run still checking out spares blocks another for the same rig
+	d.poolsInFlight = map[string]bool{"gastown": true}
+	d.trackedPoolMaintenance("gastown", nil)
+	if !strings.Contains(logs.String(), "still running") {
+		t.Errorf("second run not skipped; log: %q", logs.String())
+	}
+	if len(d.poolsInFlight) != 1 {
+		t.Errorf("poolsInFlight = %v", d.poolsInFlight)
+	}
+}
@@ -0,0 +1,111 @@
+package dag
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// asciiIcons and asciiStyles match style.DAGProgress, plus the failed and
+// skipped states molecules can reach.
+var asciiIcons = map[string]string{
+	StatusDone:    "✓",
+	StatusActive:  "⧖",
+	StatusReady:   "○",
+	StatusBlocked: "◌",
+	StatusFailed:  "✗",
+	StatusSkipped: "⊘",
+	"":            "•",
+}
+
+var asciiStyles = map[string]lipgloss.Style{
+	StatusDone:    style.Success,
+	StatusActive:  style.Warning,
+	StatusReady:   style.Info,
+	StatusBlocked: style.Dim,
+	StatusFailed:  style.Error,
+	StatusSkipped: style.Dim,
+	"":            lipgloss.NewStyle(),
+}
+
+// asciiMaxNeeds is how many dependencies a line names before summarizing
+// the rest, so fan-in gates over many children stay on one line.
+const asciiMaxNeeds = 3
+
+// ASCII renders the graph for the terminal: one line per node, waves
+// numbered down a rail, each node followed by what it waits for.
+//
+//	1 ┬ ✓ design     Design the API
+//	  │
+//	2 ├ ⧖ implement  Implement          ◀ design
+//	  ├ ○ docs       Write docs         ◀ design
+//	  │
+//	3 └ ◌ test       Test               ◀ implement, docs
+func (g *Graph) ASCII() string {
+	var sb strings.Builder
+	if g.Name != "" {
+		sb.WriteString(style.Bold.Render(g.Name) + "\n")
+	}
+	waves := g.Waves()
+	if len(waves) == 0 {
+		sb.WriteString(style.Dim.Render("  (no steps)") + "\n")
+		return sb.String()
+	}
+
+	idWidth, labelWidth := 0, 0
+	for _, n := range g.Nodes {
+		idWidth = max(idWidth, len([]rune(n.ID)))
+		labelWidth = max(labelWidth, len([]rune(truncate(n.Label, 40))))
+	}
+	numWidth := len(fmt.Sprint(len(waves)))
+
+	total, line := len(g.Nodes), 0
+	for w, ids := range waves {
+		if w > 0 {
+			fmt.Fprintf(&sb, " %*s │\n", numWidth, "")
+		}
+		for i, id := range ids {
+			n := g.Node(id)
+			line++
+			num := ""
+			if i == 0 {
+				num = fmt.Sprint(w + 1)
+			}
+			rail := "├"
+			switch {
+			case total == 1:
+				rail = "─"
+			case line == 1:
+				rail = "┬"
+			case line == total:
+				rail = "└"
+			}
+			st := asciiStyles[n.Status]
+			icon := asciiIcons[n.Status]
+			if icon == "" {
+				icon = asciiIcons[""]
+			}
+			text := fmt.Sprintf(" %*s %s %s %s  %-*s", numWidth, num, rail, st.Render(icon),
+				st.Render(fmt.Sprintf("%-*s", idWidth, n.ID)), labelWidth, truncate(n.Label, 40))
+			if n.Group != "" {
+				text += "  " + style.Dim.Render("["+n.Group+"]")
+			}
+			if needs := g.Needs(id); len(needs) > 0 && w > 0 {
+				text += "  " + style.Dim.Render("◀ "+summarize(needs))
+			}
+			sb.WriteString(strings.TrimRight(text, " ") + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// summarize joins IDs, naming at most asciiMaxNeeds of them.
+func summarize(ids []string) string {
+	if len(ids) <= asciiMaxNeeds {
+		return strings.Join(ids, ", ")
+	}
+	return fmt.Sprintf("%s +%d more", strings.Join(ids[:asciiMaxNeeds], ", "), len(ids)-asciiMaxNeeds)
+}
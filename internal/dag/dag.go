@@ -0,0 +1,197 @@
+// Package dag renders dependency graphs of formulas, molecules and convoys.
+// Callers build a Graph from their own dependency data (formula.TopologicalSort
+// for formulas, bead dependencies for molecules and convoys) and render it as
+// a terminal ASCII graph, Graphviz DOT, Mermaid, or SVG for the dashboard.
+//
+// Every format lays the graph out in waves: a node's wave is one past the
+// latest wave of the nodes it depends on, so a wave only needs earlier waves.
+package dag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node statuses. A node without a status (a formula step, which has no run
+// state) renders neutrally.
+const (
+	StatusDone    = "done"        // closed
+	StatusActive  = "in_progress" // being worked
+	StatusReady   = "ready"       // open with its dependencies done
+	StatusBlocked = "blocked"     // open and waiting on a dependency
+	StatusFailed  = "failed"      // failed and escalated
+	StatusSkipped = "skipped"     // closed without running
+)
+
+// Output formats.
+const (
+	FormatASCII   = "ascii"
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+	FormatSVG     = "svg"
+)
+
+// Formats lists the output formats Render accepts.
+var Formats = []string{FormatASCII, FormatDOT, FormatMermaid, FormatSVG}
+
+// Node is one unit of work in a graph.
+type Node struct {
+	ID     string
+	Label  string // title shown beside the ID
+	Status string // Status*, or empty
+	Group  string // cluster the node is drawn in, e.g. a convoy issue's rig
+	URL    string // link for DOT and SVG output
+}
+
+// Edge is a dependency: To waits for From.
+type Edge struct {
+	From string
+	To   string
+}
+
+// Graph is a set of nodes and the dependencies between them.
+type Graph struct {
+	Name  string
+	Nodes []Node
+	Edges []Edge
+}
+
+// New returns an empty graph with the given name.
+func New(name string) *Graph {
+	return &Graph{Name: name}
+}
+
+// AddNode adds a node. Nodes keep the order they are added in within a wave,
+// so adding them in dependency order gives a stable layout.
+func (g *Graph) AddNode(n Node) {
+	g.Nodes = append(g.Nodes, n)
+}
+
+// AddEdge records that to depends on from. Edges to or from unknown nodes
+// and repeated edges are ignored.
+func (g *Graph) AddEdge(from, to string) {
+	if from == to || g.Node(from) == nil || g.Node(to) == nil {
+		return
+	}
+	for _, e := range g.Edges {
+		if e.From == from && e.To == to {
+			return
+		}
+	}
+	g.Edges = append(g.Edges, Edge{From: from, To: to})
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id string) *Node {
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == id {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// Needs returns the IDs of the nodes id depends on, in edge order.
+func (g *Graph) Needs(id string) []string {
+	var needs []string
+	for _, e := range g.Edges {
+		if e.To == id {
+			needs = append(needs, e.From)
+		}
+	}
+	return needs
+}
+
+// Waves groups node IDs into waves; each wave only depends on earlier ones.
+// A cycle (which beads may hold even though formulas reject them) stops
+// the layout after one pass per node rather than looping.
+func (g *Graph) Waves() [][]string {
+	wave := make(map[string]int, len(g.Nodes))
+	for pass := 0; pass < len(g.Nodes); pass++ {
+		changed := false
+		for _, e := range g.Edges {
+			if wave[e.From]+1 > wave[e.To] {
+				wave[e.To] = wave[e.From] + 1
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	var waves [][]string
+	for _, n := range g.Nodes {
+		w := wave[n.ID]
+		for len(waves) <= w {
+			waves = append(waves, nil)
+		}
+		waves[w] = append(waves[w], n.ID)
+	}
+	// A cycle can leave gaps; drop empty waves
+	out := waves[:0]
+	for _, w := range waves {
+		if len(w) > 0 {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// groups returns the node groups in first-seen order.
+func (g *Graph) groups() []string {
+	var groups []string
+	seen := make(map[string]bool)
+	for _, n := range g.Nodes {
+		if n.Group != "" && !seen[n.Group] {
+			seen[n.Group] = true
+			groups = append(groups, n.Group)
+		}
+	}
+	return groups
+}
+
+// Render renders the graph in the given format.
+func Render(g *Graph, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case FormatASCII:
+		return g.ASCII(), nil
+	case FormatDOT:
+		return g.DOT(), nil
+	case FormatMermaid:
+		return g.Mermaid(), nil
+	case FormatSVG:
+		return g.SVG(), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// statusColor is the fill and stroke a status is drawn with.
+type statusColor struct {
+	fill, stroke string
+}
+
+var statusColors = map[string]statusColor{
+	StatusDone:    {"#bbf7d0", "#16a34a"},
+	StatusActive:  {"#fef08a", "#ca8a04"},
+	StatusReady:   {"#bfdbfe", "#2563eb"},
+	StatusBlocked: {"#e5e7eb", "#6b7280"},
+	StatusFailed:  {"#fecaca", "#dc2626"},
+	StatusSkipped: {"#f3f4f6", "#9ca3af"},
+	"":            {"#ffffff", "#374151"},
+}
+
+func colorFor(status string) statusColor {
+	if c, ok := statusColors[status]; ok {
+		return c
+	}
+	return statusColors[""]
+}
+
+// truncate shortens s to n runes, ending in an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
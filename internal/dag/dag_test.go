@@ -0,0 +1,132 @@
+package dag
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// diamond is design -> (implement, docs) -> test.
+func diamond() *Graph {
+	g := New("mol-feature")
+	g.AddNode(Node{ID: "test", Label: "Run the tests", Status: StatusBlocked})
+	g.AddNode(Node{ID: "design", Label: "Design the API", Status: StatusDone})
+	g.AddNode(Node{ID: "implement", Label: `Implement "v2"`, Status: StatusActive, URL: "/trace/implement"})
+	g.AddNode(Node{ID: "docs", Label: "Write <docs>", Status: StatusReady})
+	g.AddEdge("design", "implement")
+	g.AddEdge("design", "docs")
+	g.AddEdge("implement", "test")
+	g.AddEdge("docs", "test")
+	return g
+}
+
+func TestWaves(t *testing.T) {
+	g := diamond()
+	want := [][]string{{"design"}, {"implement", "docs"}, {"test"}}
+	if got := g.Waves(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Waves() = %v, want %v", got, want)
+	}
+}
+
+func TestWavesCycle(t *testing.T) {
+	g := New("")
+	g.AddNode(Node{ID: "a"})
+	g.AddNode(Node{ID: "b"})
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "a")
+	var n int
+	for _, w := range g.Waves() {
+		n += len(w)
+	}
+	if n != 2 {
+		t.Errorf("Waves() placed %d nodes, want 2", n)
+	}
+}
+
+func TestAddEdgeIgnoresUnknownAndRepeats(t *testing.T) {
+	g := diamond()
+	g.AddEdge("design", "implement")
+	g.AddEdge("design", "missing")
+	g.AddEdge("docs", "docs")
+	if len(g.Edges) != 4 {
+		t.Errorf("got %d edges, want 4", len(g.Edges))
+	}
+	if got := g.Needs("test"); !reflect.DeepEqual(got, []string{"implement", "docs"}) {
+		t.Errorf("Needs(test) = %v", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{FormatASCII, []string{"mol-feature", "design", "◀ implement, docs", "⧖"}},
+		{FormatDOT, []string{
+			`digraph "mol-feature" {`,
+			`"design" -> "implement";`,
+			`label="implement\nImplement \"v2\""`,
+			`URL="/trace/implement"`,
+		}},
+		{FormatMermaid, []string{
+			"flowchart LR",
+			`n1["design<br/>Design the API"]:::done`,
+			"n1 --> n2",
+			"Write #lt;docs#gt;",
+			"Implement #quot;v2#quot;",
+			"classDef in_progress fill:#fef08a",
+		}},
+		{FormatSVG, []string{
+			"<svg ",
+			`<a href="/trace/implement">`,
+			"Write &lt;docs&gt;",
+			`marker-end="url(#arrow)"`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out, err := Render(diamond(), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("%s output missing %q:\n%s", tt.format, want, out)
+				}
+			}
+		})
+	}
+
+	if _, err := Render(diamond(), "png"); err == nil {
+		t.Error("Render(png) should fail")
+	}
+}
+
+func TestRenderGroups(t *testing.T) {
+	g := New("convoy")
+	g.AddNode(Node{ID: "gt-1", Group: "gastown"})
+	g.AddNode(Node{ID: "bd-2", Group: "beads"})
+	g.AddEdge("bd-2", "gt-1")
+
+	if dot := g.DOT(); !strings.Contains(dot, "subgraph cluster_0") || !strings.Contains(dot, `label="beads"`) {
+		t.Errorf("DOT missing clusters:\n%s", dot)
+	}
+	if m := g.Mermaid(); !strings.Contains(m, `subgraph g1["beads"]`) {
+		t.Errorf("Mermaid missing subgraphs:\n%s", m)
+	}
+	if a := g.ASCII(); !strings.Contains(a, "[beads]") {
+		t.Errorf("ASCII missing group:\n%s", a)
+	}
+}
+
+func TestASCIISummarizesFanIn(t *testing.T) {
+	g := New("")
+	g.AddNode(Node{ID: "gate"})
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		g.AddNode(Node{ID: id})
+		g.AddEdge(id, "gate")
+	}
+	if out := g.ASCII(); !strings.Contains(out, "◀ a, b, c +2 more") {
+		t.Errorf("ASCII fan-in not summarized:\n%s", out)
+	}
+}
@@ -0,0 +1,61 @@
+package dag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DOT renders the graph in Graphviz DOT, left to right, with grouped nodes
+// in clusters. Pipe it to `dot -Tsvg` or `dot -Tpng`.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(g.Name))
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	sb.WriteString("  edge [color=\"#6b7280\"];\n")
+
+	for i, group := range g.groups() {
+		fmt.Fprintf(&sb, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "    label=%s;\n", dotQuote(group))
+		for _, n := range g.Nodes {
+			if n.Group == group {
+				sb.WriteString("  " + dotNode(n))
+			}
+		}
+		sb.WriteString("  }\n")
+	}
+	for _, n := range g.Nodes {
+		if n.Group == "" {
+			sb.WriteString(dotNode(n))
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %s -> %s;\n", dotQuote(e.From), dotQuote(e.To))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func dotNode(n Node) string {
+	label := n.ID
+	if n.Label != "" {
+		label += "\n" + n.Label
+	}
+	c := colorFor(n.Status)
+	attrs := fmt.Sprintf("label=%s, fillcolor=%s, color=%s", dotQuote(label), dotQuote(c.fill), dotQuote(c.stroke))
+	if n.Status != "" {
+		attrs += ", tooltip=" + dotQuote(n.Status)
+	}
+	if n.URL != "" {
+		attrs += ", URL=" + dotQuote(n.URL)
+	}
+	return fmt.Sprintf("  %s [%s];\n", dotQuote(n.ID), attrs)
+}
+
+// dotQuote quotes s as a DOT ID.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
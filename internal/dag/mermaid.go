@@ -0,0 +1,66 @@
+package dag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mermaid renders the graph as a Mermaid flowchart, with grouped nodes in
+// subgraphs. GitHub and most markdown viewers draw it inline.
+func (g *Graph) Mermaid() string {
+	// Bead IDs aren't all valid Mermaid IDs, so nodes are numbered
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for i, group := range g.groups() {
+		fmt.Fprintf(&sb, "  subgraph g%d[\"%s\"]\n", i, mermaidText(group))
+		for _, n := range g.Nodes {
+			if n.Group == group {
+				sb.WriteString("  " + mermaidNode(ids[n.ID], n))
+			}
+		}
+		sb.WriteString("  end\n")
+	}
+	for _, n := range g.Nodes {
+		if n.Group == "" {
+			sb.WriteString(mermaidNode(ids[n.ID], n))
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %s --> %s\n", ids[e.From], ids[e.To])
+	}
+
+	used := make(map[string]bool)
+	for _, n := range g.Nodes {
+		if n.Status != "" && !used[n.Status] {
+			used[n.Status] = true
+			c := colorFor(n.Status)
+			fmt.Fprintf(&sb, "  classDef %s fill:%s,stroke:%s\n", n.Status, c.fill, c.stroke)
+		}
+	}
+	return sb.String()
+}
+
+func mermaidNode(id string, n Node) string {
+	label := mermaidText(n.ID)
+	if n.Label != "" {
+		label += "<br/>" + mermaidText(n.Label)
+	}
+	line := fmt.Sprintf("  %s[\"%s\"]", id, label)
+	if n.Status != "" {
+		line += ":::" + n.Status
+	}
+	return line + "\n"
+}
+
+// mermaidEscaper escapes label text as Mermaid entity codes, so titles
+// can't end the label or inject markup.
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+func mermaidText(s string) string {
+	return mermaidEscaper.Replace(s)
+}
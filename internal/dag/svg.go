@@ -0,0 +1,87 @@
+package dag
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// SVG layout, in pixels.
+const (
+	svgNodeWidth  = 200
+	svgNodeHeight = 44
+	svgColumnGap  = 56
+	svgRowGap     = 14
+	svgPadding    = 16
+)
+
+// SVG renders the graph as a standalone SVG image: one column per wave,
+// left to right, nodes filled by status and linked to their URL.
+func (g *Graph) SVG() string {
+	type point struct{ x, y int }
+	pos := make(map[string]point, len(g.Nodes))
+	waves := g.Waves()
+	rows := 0
+	for w, ids := range waves {
+		rows = max(rows, len(ids))
+		for r, id := range ids {
+			pos[id] = point{
+				x: svgPadding + w*(svgNodeWidth+svgColumnGap),
+				y: svgPadding + r*(svgNodeHeight+svgRowGap),
+			}
+		}
+	}
+	width := 2*svgPadding + max(len(waves)*(svgNodeWidth+svgColumnGap)-svgColumnGap, 0)
+	height := 2*svgPadding + max(rows*(svgNodeHeight+svgRowGap)-svgRowGap, 0)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif" font-size="12">`+"\n",
+		width, height, width, height)
+	if g.Name != "" {
+		fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(g.Name))
+	}
+	sb.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="#6b7280"/></marker></defs>` + "\n")
+
+	// Edges first, so nodes draw over them
+	for _, e := range g.Edges {
+		from, to := pos[e.From], pos[e.To]
+		x1, y1 := from.x+svgNodeWidth, from.y+svgNodeHeight/2
+		x2, y2 := to.x, to.y+svgNodeHeight/2
+		mid := (x1 + x2) / 2
+		fmt.Fprintf(&sb, `<path d="M%d,%d C%d,%d %d,%d %d,%d" fill="none" stroke="#6b7280" stroke-width="1.5" marker-end="url(#arrow)"/>`+"\n",
+			x1, y1, mid, y1, mid, y2, x2, y2)
+	}
+
+	for _, n := range g.Nodes {
+		p := pos[n.ID]
+		c := colorFor(n.Status)
+		tip := n.ID
+		if n.Label != "" {
+			tip += ": " + n.Label
+		}
+		if n.Status != "" {
+			tip += " (" + n.Status + ")"
+		}
+		sub := n.Label
+		if n.Group != "" {
+			sub = n.Group + " · " + sub
+		}
+
+		if n.URL != "" {
+			fmt.Fprintf(&sb, `<a href="%s">`, html.EscapeString(n.URL))
+		}
+		fmt.Fprintf(&sb, `<g class="node %s"><title>%s</title>`, html.EscapeString(n.Status), html.EscapeString(tip))
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="%s" stroke="%s" stroke-width="1.5"/>`,
+			p.x, p.y, svgNodeWidth, svgNodeHeight, c.fill, c.stroke)
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-weight="bold" fill="#111827">%s</text>`,
+			p.x+10, p.y+18, html.EscapeString(truncate(n.ID, 28)))
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" fill="#374151">%s</text></g>`,
+			p.x+10, p.y+35, html.EscapeString(truncate(sub, 30)))
+		if n.URL != "" {
+			sb.WriteString("</a>")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
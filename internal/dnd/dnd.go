@@ -0,0 +1,179 @@
+// Package dnd evaluates time-based do-not-disturb policies for agents and
+// holds the nudges they suppress until the quiet window ends.
+//
+// Policies live in config/messaging.json under "quiet_policies", keyed by
+// agent address pattern. They complement the manual notification level
+// (gt dnd / gt notify) stored on agent beads: either one mutes an agent.
+package dnd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Decision is the outcome of evaluating quiet policies for an agent.
+type Decision struct {
+	Quiet  bool   `json:"quiet"`
+	Policy string `json:"policy,omitempty"` // label of the active policy
+	Reason string `json:"reason,omitempty"` // e.g. "quiet hours 22:00-07:00"
+}
+
+// PolicyMatch is a quiet policy that applies to an address, with the
+// address pattern it was configured under.
+type PolicyMatch struct {
+	Pattern string             `json:"pattern"`
+	Index   int                `json:"index"`
+	Policy  config.QuietPolicy `json:"policy"`
+}
+
+// Label returns the policy's name, or its position when unnamed.
+func (m PolicyMatch) Label() string {
+	if m.Policy.Name != "" {
+		return m.Policy.Name
+	}
+	return fmt.Sprintf("%s[%d]", m.Pattern, m.Index)
+}
+
+// LoadConfig loads the town's messaging config for policy evaluation.
+// A town without messaging.json has no policies.
+func LoadConfig(townRoot string) (*config.MessagingConfig, error) {
+	cfg, err := config.LoadMessagingConfig(config.MessagingConfigPath(townRoot))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("loading messaging config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Check evaluates the town's quiet policies for an agent address.
+// A broken messaging config never mutes anyone.
+func Check(townRoot, address string, now time.Time) Decision {
+	if townRoot == "" {
+		return Decision{}
+	}
+	cfg, err := LoadConfig(townRoot)
+	if err != nil {
+		return Decision{}
+	}
+	return Evaluate(cfg, townRoot, address, now)
+}
+
+// Evaluate reports whether any quiet policy for address is active at now.
+func Evaluate(cfg *config.MessagingConfig, townRoot, address string, now time.Time) Decision {
+	for _, pm := range PoliciesFor(cfg, address) {
+		p := pm.Policy
+		if qh := p.QuietHours; qh != nil && InQuietHours(qh, now) {
+			return Decision{Quiet: true, Policy: pm.Label(), Reason: fmt.Sprintf("quiet hours %s-%s", qh.Start, qh.End)}
+		}
+		if p.DuringWaitStep && IsWaiting(townRoot, address, now) {
+			return Decision{Quiet: true, Policy: pm.Label(), Reason: "wait step active"}
+		}
+	}
+	return Decision{}
+}
+
+// PoliciesFor returns the quiet policies that apply to an address, exact
+// addresses first, then patterns in sorted order.
+func PoliciesFor(cfg *config.MessagingConfig, address string) []PolicyMatch {
+	if cfg == nil {
+		return nil
+	}
+	var exact, wild []string
+	for pattern := range cfg.QuietPolicies {
+		if !MatchAddress(pattern, address) {
+			continue
+		}
+		if strings.ContainsAny(pattern, "*?[") {
+			wild = append(wild, pattern)
+		} else {
+			exact = append(exact, pattern)
+		}
+	}
+	sort.Strings(exact)
+	sort.Strings(wild)
+
+	var matches []PolicyMatch
+	for _, pattern := range append(exact, wild...) {
+		for i, p := range cfg.QuietPolicies[pattern] {
+			matches = append(matches, PolicyMatch{Pattern: pattern, Index: i, Policy: p})
+		}
+	}
+	return matches
+}
+
+// InQuietHours reports whether now falls inside the daily window. A window
+// past midnight belongs to the day it starts on.
+func InQuietHours(qh *config.QuietHours, now time.Time) bool {
+	start, err1 := time.Parse("15:04", qh.Start)
+	end, err2 := time.Parse("15:04", qh.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	if qh.Timezone != "" {
+		if loc, err := time.LoadLocation(qh.Timezone); err == nil {
+			now = now.In(loc)
+		}
+	}
+
+	minutes := now.Hour()*60 + now.Minute()
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+
+	day := now.Weekday()
+	switch {
+	case startMin < endMin:
+		if minutes < startMin || minutes >= endMin {
+			return false
+		}
+	case minutes >= startMin:
+		// Evening part of an overnight window
+	case minutes < endMin:
+		// Morning part: the window started yesterday
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+	return onDay(qh.Days, day)
+}
+
+// onDay reports whether day is one of days (empty = every day).
+func onDay(days []string, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if wd, ok := config.QuietDays[strings.ToLower(d)]; ok && wd == day {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAddress reports whether address matches a policy address pattern.
+// Both sides are normalized ("mayor" = "mayor/", crew/ and polecats/
+// dropped); "*" matches one path segment, and a bare "*" matches every agent.
+func MatchAddress(pattern, address string) bool {
+	if pattern == "*" {
+		return true
+	}
+	ok, err := path.Match(normalizeAddress(pattern), normalizeAddress(address))
+	return err == nil && ok
+}
+
+// normalizeAddress reduces an agent address to rig/name form.
+func normalizeAddress(address string) string {
+	address = strings.TrimSuffix(address, "/")
+	parts := strings.Split(address, "/")
+	if len(parts) == 3 && (parts[1] == "crew" || parts[1] == "polecats") {
+		return parts[0] + "/" + parts[2]
+	}
+	return address
+}
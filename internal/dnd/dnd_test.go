@@ -0,0 +1,176 @@
+package dnd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestInQuietHours(t *testing.T) {
+	// 2026-01-05 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+	overnight := &config.QuietHours{Start: "22:00", End: "07:00"}
+	daytime := &config.QuietHours{Start: "12:00", End: "13:30"}
+	weeknights := &config.QuietHours{Start: "22:00", End: "07:00", Days: []string{"mon", "Tue", "wed", "thu", "fri"}}
+	tokyo := &config.QuietHours{Start: "22:00", End: "07:00", Timezone: "Asia/Tokyo"}
+
+	tests := []struct {
+		name string
+		qh   *config.QuietHours
+		now  time.Time
+		want bool
+	}{
+		{"overnight evening", overnight, at(5, 23, 0), true},
+		{"overnight at start", overnight, at(5, 22, 0), true},
+		{"overnight morning", overnight, at(6, 6, 59), true},
+		{"overnight at end", overnight, at(6, 7, 0), false},
+		{"overnight midday", overnight, at(5, 15, 0), false},
+		{"daytime inside", daytime, at(5, 13, 0), true},
+		{"daytime before", daytime, at(5, 11, 59), false},
+		{"daytime after", daytime, at(5, 13, 30), false},
+		{"weeknight friday evening", weeknights, at(9, 23, 0), true},
+		{"weeknight saturday morning", weeknights, at(10, 6, 0), true},
+		{"weeknight saturday evening", weeknights, at(10, 23, 0), false},
+		{"weeknight monday morning", weeknights, at(5, 6, 0), false},
+		{"timezone", tokyo, at(5, 14, 0), true}, // 23:00 in Tokyo
+		{"timezone outside", tokyo, at(5, 23, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InQuietHours(tt.qh, tt.now); got != tt.want {
+				t.Errorf("InQuietHours(%s-%s, %s) = %v, want %v", tt.qh.Start, tt.qh.End, tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoliciesFor(t *testing.T) {
+	cfg := config.NewMessagingConfig()
+	cfg.QuietPolicies = map[string][]config.QuietPolicy{
+		"*/witness":       {{Name: "patrol", DuringWaitStep: true}},
+		"gastown/witness": {{Name: "night", QuietHours: &config.QuietHours{Start: "22:00", End: "07:00"}}},
+		"gastown/*":       {{DuringWaitStep: true}},
+		"beads/*":         {{DuringWaitStep: true}},
+	}
+
+	got := PoliciesFor(cfg, "gastown/witness")
+	var labels []string
+	for _, pm := range got {
+		labels = append(labels, pm.Label())
+	}
+	want := []string{"night", "patrol", "gastown/*[0]"}
+	if len(labels) != len(want) {
+		t.Fatalf("PoliciesFor = %v, want %v", labels, want)
+	}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Errorf("PoliciesFor = %v, want %v", labels, want)
+			break
+		}
+	}
+
+	if got := PoliciesFor(cfg, "gastown/crew/max"); len(got) != 1 || got[0].Pattern != "gastown/*" {
+		t.Errorf("PoliciesFor(crew) = %+v, want gastown/* only", got)
+	}
+	if got := PoliciesFor(nil, "mayor/"); got != nil {
+		t.Errorf("PoliciesFor(nil) = %+v, want nil", got)
+	}
+}
+
+func TestEvaluateWaitStep(t *testing.T) {
+	townRoot := t.TempDir()
+	cfg := config.NewMessagingConfig()
+	cfg.QuietPolicies = map[string][]config.QuietPolicy{
+		"*/witness": {{Name: "patrol", DuringWaitStep: true}},
+	}
+	now := time.Now()
+
+	if d := Evaluate(cfg, townRoot, "gastown/witness", now); d.Quiet {
+		t.Fatalf("Evaluate before wait = %+v, want not quiet", d)
+	}
+
+	if err := MarkWaiting(townRoot, "gastown/witness", now.Add(time.Minute)); err != nil {
+		t.Fatalf("MarkWaiting: %v", err)
+	}
+	d := Evaluate(cfg, townRoot, "gastown/witness", now)
+	if !d.Quiet || d.Policy != "patrol" {
+		t.Errorf("Evaluate during wait = %+v, want quiet by patrol", d)
+	}
+	if d := Evaluate(cfg, townRoot, "gastown/witness", now.Add(2*time.Minute)); d.Quiet {
+		t.Errorf("Evaluate after wait deadline = %+v, want not quiet", d)
+	}
+	if d := Evaluate(cfg, townRoot, "gastown/refinery", now); d.Quiet {
+		t.Errorf("Evaluate for other agent = %+v, want not quiet", d)
+	}
+
+	if err := ClearWaiting(townRoot, "gastown/witness"); err != nil {
+		t.Fatalf("ClearWaiting: %v", err)
+	}
+	if d := Evaluate(cfg, townRoot, "gastown/witness", now); d.Quiet {
+		t.Errorf("Evaluate after clear = %+v, want not quiet", d)
+	}
+}
+
+func TestHoldAndFlush(t *testing.T) {
+	townRoot := t.TempDir()
+	writeConfig := func(cfg *config.MessagingConfig) {
+		t.Helper()
+		if err := config.SaveMessagingConfig(config.MessagingConfigPath(townRoot), cfg); err != nil {
+			t.Fatalf("saving messaging config: %v", err)
+		}
+	}
+
+	// Quiet all day for the witness
+	cfg := config.NewMessagingConfig()
+	cfg.QuietPolicies = map[string][]config.QuietPolicy{
+		"gastown/witness": {{QuietHours: &config.QuietHours{Start: "00:00", End: "23:59"}}},
+	}
+	writeConfig(cfg)
+
+	for _, msg := range []string{"first", "second"} {
+		if err := Hold(townRoot, HeldNudge{Address: "gastown/witness", Session: "gt-gastown-witness", Message: msg}); err != nil {
+			t.Fatalf("Hold: %v", err)
+		}
+	}
+	held, err := ListHeld(townRoot)
+	if err != nil || len(held) != 2 {
+		t.Fatalf("ListHeld = %v, %v; want 2 nudges", held, err)
+	}
+
+	var delivered []string
+	deliver := func(session, text string) (bool, error) {
+		delivered = append(delivered, session+": "+text)
+		return true, nil
+	}
+	midday := time.Date(2026, 1, 5, 12, 0, 0, 0, time.Local)
+
+	// Still quiet: nothing is delivered
+	if n, err := Flush(townRoot, midday, deliver); err != nil || n != 0 {
+		t.Fatalf("Flush while quiet = %d, %v; want 0", n, err)
+	}
+
+	// Session not running: nudges stay held
+	cfg.QuietPolicies = nil
+	writeConfig(cfg)
+	if n, err := Flush(townRoot, midday, func(string, string) (bool, error) { return false, nil }); err != nil || n != 0 {
+		t.Fatalf("Flush without session = %d, %v; want 0", n, err)
+	}
+
+	if n, err := Flush(townRoot, midday, deliver); err != nil || n != 1 {
+		t.Fatalf("Flush = %d, %v; want 1", n, err)
+	}
+	if len(delivered) != 1 {
+		t.Fatalf("delivered %d digests, want 1", len(delivered))
+	}
+	if want := Digest(held); delivered[0] != "gt-gastown-witness: "+want {
+		t.Errorf("digest = %q, want %q", delivered[0], want)
+	}
+	if _, err := os.Stat(filepath.Join(heldDir(townRoot), "gt-gastown-witness.json")); !os.IsNotExist(err) {
+		t.Errorf("held file should be removed after flush, stat err = %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package dnd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// heldDirName holds suppressed nudges, one file per agent session.
+const heldDirName = "dnd-held"
+
+// waitingDirName holds markers for agents inside a wait step.
+const waitingDirName = "dnd-waiting"
+
+// maxHeldPerSession caps the digest; the oldest nudges are dropped first.
+const maxHeldPerSession = 50
+
+// HeldNudge is a nudge suppressed by a quiet policy.
+type HeldNudge struct {
+	Address string    `json:"address"`
+	Session string    `json:"session"`
+	Message string    `json:"message"`
+	HeldAt  time.Time `json:"held_at"`
+}
+
+// heldDir returns the directory for held nudges in a town.
+func heldDir(townRoot string) string {
+	return filepath.Join(townRoot, constants.DirRuntime, heldDirName)
+}
+
+// heldPath returns the held-nudge file for a tmux session.
+func heldPath(townRoot, session string) string {
+	return filepath.Join(heldDir(townRoot), session+".json")
+}
+
+// Hold queues a nudge for delivery in the session's digest.
+func Hold(townRoot string, n HeldNudge) error {
+	if n.Session == "" {
+		return fmt.Errorf("held nudge has no session")
+	}
+	if n.HeldAt.IsZero() {
+		n.HeldAt = time.Now()
+	}
+	return withHeldLock(townRoot, n.Session, func() error {
+		held, err := readHeld(heldPath(townRoot, n.Session))
+		if err != nil {
+			return err
+		}
+		held = append(held, n)
+		if len(held) > maxHeldPerSession {
+			held = held[len(held)-maxHeldPerSession:]
+		}
+		return util.AtomicWriteJSON(heldPath(townRoot, n.Session), held)
+	})
+}
+
+// ListHeld returns all held nudges in the town, grouped by session and
+// oldest first within each session.
+func ListHeld(townRoot string) ([]HeldNudge, error) {
+	entries, err := os.ReadDir(heldDir(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var all []HeldNudge
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		held, err := readHeld(filepath.Join(heldDir(townRoot), e.Name()))
+		if err != nil {
+			continue
+		}
+		all = append(all, held...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Session < all[j].Session })
+	return all, nil
+}
+
+// Deliverer sends a digest to a tmux session. It returns false if the
+// session isn't running, in which case the nudges stay held.
+type Deliverer func(session, text string) (bool, error)
+
+// Flush delivers a digest to every session whose quiet window has ended.
+// Returns the number of sessions that received a digest.
+func Flush(townRoot string, now time.Time, deliver Deliverer) (int, error) {
+	cfg, err := LoadConfig(townRoot)
+	if err != nil {
+		return 0, err
+	}
+
+	held, err := ListHeld(townRoot)
+	if err != nil {
+		return 0, err
+	}
+	bySession := make(map[string][]HeldNudge)
+	var sessions []string
+	for _, n := range held {
+		if _, ok := bySession[n.Session]; !ok {
+			sessions = append(sessions, n.Session)
+		}
+		bySession[n.Session] = append(bySession[n.Session], n)
+	}
+
+	flushed := 0
+	for _, session := range sessions {
+		nudges := bySession[session]
+		if Evaluate(cfg, townRoot, nudges[0].Address, now).Quiet {
+			continue
+		}
+		err := withHeldLock(townRoot, session, func() error {
+			// Re-read under the lock so nudges held since ListHeld aren't lost
+			current, err := readHeld(heldPath(townRoot, session))
+			if err != nil || len(current) == 0 {
+				return err
+			}
+			ok, err := deliver(session, Digest(current))
+			if err != nil || !ok {
+				return err
+			}
+			flushed++
+			return os.Remove(heldPath(townRoot, session))
+		})
+		if err != nil {
+			return flushed, fmt.Errorf("flushing held nudges for %s: %w", session, err)
+		}
+	}
+	return flushed, nil
+}
+
+// Digest renders held nudges as a single nudge.
+func Digest(held []HeldNudge) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔕 Quiet window ended. %d notification(s) held while you were in DND:", len(held))
+	for _, n := range held {
+		fmt.Fprintf(&b, "\n- [%s] %s", n.HeldAt.Local().Format("15:04"), n.Message)
+	}
+	return b.String()
+}
+
+func readHeld(path string) ([]HeldNudge, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the town runtime dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var held []HeldNudge
+	if err := json.Unmarshal(data, &held); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return held, nil
+}
+
+// withHeldLock runs fn holding the file lock for a session's held nudges.
+func withHeldLock(townRoot, session string, fn func() error) error {
+	if err := os.MkdirAll(heldDir(townRoot), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(heldPath(townRoot, session) + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking held nudges: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
+// waitingPath returns the wait-step marker for an agent address.
+func waitingPath(townRoot, address string) string {
+	name := strings.ReplaceAll(normalizeAddress(address), "/", "-")
+	return filepath.Join(townRoot, constants.DirRuntime, waitingDirName, name)
+}
+
+// MarkWaiting records that an agent is inside a wait step until the given
+// time, for during_wait_step policies. The deadline keeps an interrupted
+// wait from muting the agent forever.
+func MarkWaiting(townRoot, address string, until time.Time) error {
+	p := waitingPath(townRoot, address)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(p, []byte(until.UTC().Format(time.RFC3339)), 0644)
+}
+
+// ClearWaiting records that an agent has left its wait step.
+func ClearWaiting(townRoot, address string) error {
+	if err := os.Remove(waitingPath(townRoot, address)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsWaiting reports whether an agent is inside a wait step at now.
+func IsWaiting(townRoot, address string, now time.Time) bool {
+	if townRoot == "" {
+		return false
+	}
+	data, err := os.ReadFile(waitingPath(townRoot, address)) //nolint:gosec // G304: path is under the town runtime dir
+	if err != nil {
+		return false
+	}
+	until, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	return err == nil && now.Before(until)
+}
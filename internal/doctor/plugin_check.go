@@ -0,0 +1,236 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/plugin"
+)
+
+// defaultPluginCheckTimeout bounds a plugin check's run or fix command
+// when the plugin does not declare its own timeout.
+const defaultPluginCheckTimeout = 30 * time.Second
+
+// PluginCheck runs a health check declared in a plugin's [doctor] section.
+// Plugins live in <town>/plugins/ and <rig>/plugins/, so teams can add
+// project-specific checks without modifying gt itself.
+type PluginCheck struct {
+	BaseCheck
+	plugin *plugin.Plugin
+	spec   *plugin.DoctorCheck
+}
+
+// NewPluginCheck creates a doctor check from a plugin with a [doctor] section.
+func NewPluginCheck(p *plugin.Plugin) *PluginCheck {
+	spec := p.Doctor
+
+	name := spec.Name
+	if name == "" {
+		name = p.Name
+	}
+	if p.RigName != "" {
+		name = p.RigName + "/" + name
+	}
+
+	desc := spec.Description
+	if desc == "" {
+		desc = p.Description
+	}
+
+	return &PluginCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "plugin:" + name,
+			CheckDescription: desc,
+			CheckCategory:    pluginCheckCategory(spec.Category),
+		},
+		plugin: p,
+		spec:   spec,
+	}
+}
+
+// PluginChecks discovers doctor checks from town and rig plugin directories.
+// If rigName is set, only that rig's plugins are scanned in addition to the
+// town-level plugins; otherwise all registered rigs are scanned.
+func PluginChecks(townRoot, rigName string) []Check {
+	var rigNames []string
+	if rigName != "" {
+		rigNames = []string{rigName}
+	} else {
+		rigNames, _ = discoverRigs(townRoot)
+		slices.Sort(rigNames)
+	}
+
+	plugins, err := plugin.NewScanner(townRoot, rigNames).DiscoverDoctorChecks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: discovering plugin checks: %v\n", err)
+		return nil
+	}
+
+	checks := make([]Check, 0, len(plugins))
+	for _, p := range plugins {
+		checks = append(checks, NewPluginCheck(p))
+	}
+	return checks
+}
+
+// CanFix returns true if the plugin declares a fix command.
+func (c *PluginCheck) CanFix() bool {
+	return c.spec.Fix != ""
+}
+
+// Run executes the plugin's check command and maps its exit code to a status.
+func (c *PluginCheck) Run(ctx *CheckContext) *CheckResult {
+	output, exitCode, err := c.exec(ctx, c.spec.Run)
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: "Check command failed to run",
+			Details: []string{err.Error()},
+		}
+	}
+
+	message, details := splitPluginOutput(output)
+	result := &CheckResult{
+		Name:    c.Name(),
+		Message: message,
+		Details: details,
+	}
+
+	switch exitCode {
+	case 0:
+		result.Status = StatusOK
+		if result.Message == "" {
+			result.Message = "OK"
+		}
+		return result
+	case 1:
+		result.Status = StatusWarning
+	default:
+		result.Status = StatusError
+	}
+
+	if result.Message == "" {
+		result.Message = fmt.Sprintf("Check exited with status %d", exitCode)
+	}
+	// A fix command wins; the plugin's hint covers checks without one
+	if c.CanFix() {
+		result.FixHint = "Run 'gt doctor --fix' to fix"
+	} else {
+		result.FixHint = c.spec.FixHint
+	}
+	return result
+}
+
+// Fix executes the plugin's fix command.
+func (c *PluginCheck) Fix(ctx *CheckContext) error {
+	if !c.CanFix() {
+		return ErrCannotFix
+	}
+
+	output, exitCode, err := c.exec(ctx, c.spec.Fix)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		message, _ := splitPluginOutput(output)
+		if message == "" {
+			message = fmt.Sprintf("exit status %d", exitCode)
+		}
+		return fmt.Errorf("fix command failed: %s", message)
+	}
+	return nil
+}
+
+// exec runs a shell command in the plugin directory with the doctor context
+// exported as environment variables. A non-zero exit is reported via the
+// exit code, not as an error.
+func (c *PluginCheck) exec(ctx *CheckContext, command string) (string, int, error) {
+	timeout := defaultPluginCheckTimeout
+	if c.spec.Timeout != "" {
+		d, err := time.ParseDuration(c.spec.Timeout)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid timeout %q: %w", c.spec.Timeout, err)
+		}
+		timeout = d
+	}
+
+	execCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(execCtx, "sh", "-c", command) //nolint:gosec // G204: command comes from a trusted plugin directory
+	cmd.Dir = c.plugin.Path
+	cmd.Env = append(os.Environ(), c.env(ctx)...)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	if execCtx.Err() == context.DeadlineExceeded {
+		return out.String(), 0, fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return out.String(), exitErr.ExitCode(), nil
+		}
+		return out.String(), 0, err
+	}
+	return out.String(), 0, nil
+}
+
+// env returns the environment passed to plugin check commands.
+func (c *PluginCheck) env(ctx *CheckContext) []string {
+	env := []string{
+		"GT_ROOT=" + ctx.TownRoot,
+		"GT_PLUGIN=" + c.plugin.Name,
+		"GT_PLUGIN_DIR=" + c.plugin.Path,
+	}
+	if c.plugin.RigName != "" {
+		env = append(env,
+			"GT_RIG="+c.plugin.RigName,
+			"GT_RIG_PATH="+filepath.Join(ctx.TownRoot, c.plugin.RigName),
+		)
+	}
+	if ctx.Verbose {
+		env = append(env, "GT_DOCTOR_VERBOSE=1")
+	}
+	return env
+}
+
+// splitPluginOutput splits command output into a message (first non-empty
+// line) and details (remaining non-empty lines).
+func splitPluginOutput(output string) (string, []string) {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.TrimSpace(lines[0]), lines[1:]
+}
+
+// pluginCheckCategory maps a declared category onto a known doctor category.
+// Unknown or empty categories are grouped under CategoryPlugins so they are
+// still shown in the report.
+func pluginCheckCategory(category string) string {
+	for _, known := range CategoryOrder {
+		if strings.EqualFold(category, known) {
+			return known
+		}
+	}
+	return CategoryPlugins
+}
@@ -0,0 +1,184 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCheckPlugin creates a plugin directory with a [doctor] section.
+func writeCheckPlugin(t *testing.T, dir, name, doctorSection string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "+++\nname = \"" + name + "\"\ndescription = \"test check\"\n\n[doctor]\n" + doctorSection + "+++\n"
+	if err := os.WriteFile(filepath.Join(dir, "plugin.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeRigsJSON registers the given rigs in mayor/rigs.json.
+func writeRigsJSON(t *testing.T, townRoot string, rigs ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var entries []string
+	for _, rig := range rigs {
+		entries = append(entries, `"`+rig+`": {}`)
+	}
+	data := `{"version": 1, "rigs": {` + strings.Join(entries, ", ") + `}}`
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "rigs.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginChecks_Discovery(t *testing.T) {
+	townRoot := t.TempDir()
+	writeRigsJSON(t, townRoot, "myrig")
+	writeCheckPlugin(t, filepath.Join(townRoot, "plugins", "town-check"), "town-check", "run = \"true\"\n")
+	writeCheckPlugin(t, filepath.Join(townRoot, "myrig", "plugins", "db-up"), "db-up", "run = \"true\"\ncategory = \"rig\"\n")
+
+	checks := PluginChecks(townRoot, "")
+	if len(checks) != 2 {
+		t.Fatalf("expected 2 plugin checks, got %d", len(checks))
+	}
+	if checks[0].Name() != "plugin:town-check" {
+		t.Errorf("expected name 'plugin:town-check', got %q", checks[0].Name())
+	}
+	if checks[1].Name() != "plugin:myrig/db-up" {
+		t.Errorf("expected name 'plugin:myrig/db-up', got %q", checks[1].Name())
+	}
+	if cat := checks[0].(*PluginCheck).Category(); cat != CategoryPlugins {
+		t.Errorf("expected default category %q, got %q", CategoryPlugins, cat)
+	}
+	if cat := checks[1].(*PluginCheck).Category(); cat != CategoryRig {
+		t.Errorf("expected category %q, got %q", CategoryRig, cat)
+	}
+}
+
+func TestPluginCheck_ExitCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     string
+		status  CheckStatus
+		message string
+	}{
+		{"ok", `echo "all good"`, StatusOK, "all good"},
+		{"warning", `echo "db slow"; echo "latency 900ms"; exit 1`, StatusWarning, "db slow"},
+		{"error", `echo "db down"; exit 3`, StatusError, "db down"},
+		{"silent error", `exit 2`, StatusError, "Check exited with status 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			townRoot := t.TempDir()
+			writeCheckPlugin(t, filepath.Join(townRoot, "plugins", "c"), "c", "run = '"+tt.run+"'\n")
+
+			checks := PluginChecks(townRoot, "")
+			if len(checks) != 1 {
+				t.Fatalf("expected 1 plugin check, got %d", len(checks))
+			}
+
+			result := checks[0].Run(&CheckContext{TownRoot: townRoot})
+			if result.Status != tt.status {
+				t.Errorf("expected status %v, got %v", tt.status, result.Status)
+			}
+			if result.Message != tt.message {
+				t.Errorf("expected message %q, got %q", tt.message, result.Message)
+			}
+		})
+	}
+}
+
+func TestPluginCheck_Environment(t *testing.T) {
+	townRoot := t.TempDir()
+	writeRigsJSON(t, townRoot, "myrig")
+	writeCheckPlugin(t, filepath.Join(townRoot, "myrig", "plugins", "env"), "env",
+		"run = 'echo \"$GT_ROOT|$GT_RIG|$(pwd)\"'\n")
+
+	checks := PluginChecks(townRoot, "myrig")
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 plugin check, got %d", len(checks))
+	}
+
+	result := checks[0].Run(&CheckContext{TownRoot: townRoot, RigName: "myrig"})
+	parts := strings.Split(result.Message, "|")
+	if len(parts) != 3 {
+		t.Fatalf("unexpected output %q", result.Message)
+	}
+	if parts[0] != townRoot {
+		t.Errorf("expected GT_ROOT %q, got %q", townRoot, parts[0])
+	}
+	if parts[1] != "myrig" {
+		t.Errorf("expected GT_RIG 'myrig', got %q", parts[1])
+	}
+	if !strings.HasSuffix(parts[2], filepath.Join("myrig", "plugins", "env")) {
+		t.Errorf("expected to run in plugin dir, got %q", parts[2])
+	}
+}
+
+func TestPluginCheck_FixHint(t *testing.T) {
+	townRoot := t.TempDir()
+	writeCheckPlugin(t, filepath.Join(townRoot, "plugins", "manual"), "manual",
+		"run = 'exit 1'\nfix_hint = 'Ask the overseer'\n")
+	writeCheckPlugin(t, filepath.Join(townRoot, "plugins", "auto"), "auto",
+		"run = 'exit 1'\nfix = 'true'\nfix_hint = 'Ask the overseer'\n")
+
+	hints := make(map[string]string)
+	for _, check := range PluginChecks(townRoot, "") {
+		hints[check.Name()] = check.Run(&CheckContext{TownRoot: townRoot}).FixHint
+	}
+	for name, hint := range hints {
+		switch {
+		case strings.Contains(name, "manual") && hint != "Ask the overseer":
+			t.Errorf("%s: FixHint = %q, want the plugin's hint", name, hint)
+		case strings.Contains(name, "auto") && !strings.Contains(hint, "gt doctor --fix"):
+			t.Errorf("%s: FixHint = %q, want the --fix hint", name, hint)
+		}
+	}
+	if len(hints) != 2 {
+		t.Errorf("expected 2 plugin checks, got %v", hints)
+	}
+}
+
+func TestPluginCheck_Fix(t *testing.T) {
+	townRoot := t.TempDir()
+	writeCheckPlugin(t, filepath.Join(townRoot, "plugins", "marker"), "marker",
+		"run = 'test -f marker || { echo \"marker missing\"; exit 1; }'\nfix = 'touch marker'\n")
+
+	d := NewDoctor()
+	d.RegisterAll(PluginChecks(townRoot, "")...)
+	ctx := &CheckContext{TownRoot: townRoot}
+
+	report := d.Run(ctx)
+	if report.Summary.Warnings != 1 {
+		t.Fatalf("expected 1 warning before fix, got %d", report.Summary.Warnings)
+	}
+	if report.Checks[0].FixHint == "" {
+		t.Error("expected fix hint for fixable plugin check")
+	}
+
+	report = d.Fix(ctx)
+	if report.Summary.OK != 1 {
+		t.Fatalf("expected check to pass after fix, got %+v", report.Checks[0])
+	}
+	if !strings.HasSuffix(report.Checks[0].Message, "(fixed)") {
+		t.Errorf("expected fixed message, got %q", report.Checks[0].Message)
+	}
+}
+
+func TestPluginCheck_NotFixable(t *testing.T) {
+	townRoot := t.TempDir()
+	writeCheckPlugin(t, filepath.Join(townRoot, "plugins", "c"), "c", "run = \"true\"\n")
+
+	checks := PluginChecks(townRoot, "")
+	if checks[0].CanFix() {
+		t.Error("expected CanFix false without fix command")
+	}
+	if err := checks[0].Fix(&CheckContext{TownRoot: townRoot}); err != ErrCannotFix {
+		t.Errorf("expected ErrCannotFix, got %v", err)
+	}
+}
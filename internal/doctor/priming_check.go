@@ -261,7 +261,7 @@ func (c *PrimingCheck) checkRigPriming(townRoot string) []primingIssue {
 		if dirExists(polecatsDir) {
 			pcEntries, _ := os.ReadDir(polecatsDir)
 			for _, pcEntry := range pcEntries {
-				if !pcEntry.IsDir() || pcEntry.Name() == ".claude" {
+				if !pcEntry.IsDir() || strings.HasPrefix(pcEntry.Name(), ".") {
 					continue
 				}
 				polecatPath := filepath.Join(polecatsDir, pcEntry.Name())
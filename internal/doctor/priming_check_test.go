@@ -0,0 +1,35 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrimingCheck_SkipsDotDirsInPolecats(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "myrig")
+	for _, dir := range []string{
+		filepath.Join(rigPath, ".beads"),
+		filepath.Join(rigPath, "polecats", ".pool"),
+		filepath.Join(rigPath, "polecats", ".claude"),
+		filepath.Join(rigPath, "polecats", "toast"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(rigPath, ".beads", "PRIME.md"), []byte("# prime\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := NewPrimingCheck().checkRigPriming(townRoot)
+
+	var locations []string
+	for _, issue := range issues {
+		locations = append(locations, issue.location)
+	}
+	if len(issues) != 1 || issues[0].location != "myrig/polecats/toast" {
+		t.Errorf("issue locations = %v, want only myrig/polecats/toast", locations)
+	}
+}
@@ -290,7 +290,7 @@ func (c *HooksPathConfiguredCheck) Run(ctx *CheckContext) *CheckResult {
 	polecatDir := filepath.Join(rigPath, "polecats")
 	if entries, err := os.ReadDir(polecatDir); err == nil {
 		for _, entry := range entries {
-			if entry.IsDir() {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
 				clonePaths = append(clonePaths, filepath.Join(polecatDir, entry.Name()))
 			}
 		}
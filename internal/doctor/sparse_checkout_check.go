@@ -65,7 +65,7 @@ func (c *SparseCheckoutCheck) Run(ctx *CheckContext) *CheckResult {
 	polecatDir := filepath.Join(c.rigPath, "polecats")
 	if entries, err := os.ReadDir(polecatDir); err == nil {
 		for _, entry := range entries {
-			if entry.IsDir() {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
 				repoPaths = append(repoPaths, filepath.Join(polecatDir, entry.Name()))
 			}
 		}
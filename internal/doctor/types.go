@@ -19,6 +19,7 @@ const (
 	CategoryConfig        = "Configuration"
 	CategoryCleanup       = "Cleanup"
 	CategoryHooks         = "Hooks"
+	CategoryPlugins       = "Plugins"
 )
 
 // CategoryOrder defines the display order for categories
@@ -30,6 +31,7 @@ var CategoryOrder = []string{
 	CategoryConfig,
 	CategoryCleanup,
 	CategoryHooks,
+	CategoryPlugins,
 }
 
 // CheckStatus represents the result status of a health check.
@@ -2,6 +2,10 @@
 //
 // Events are written to ~/gt/.events.jsonl (raw audit log) and later
 // curated by the feed daemon into ~/.feed.jsonl (user-facing).
+//
+// The daemon rotates the raw log into compressed, dated segments under
+// ~/gt/.events/ and expires old events per visibility level. Use Scan or
+// ReadSince to read across segments and the active log.
 package events
 
 import (
@@ -12,6 +16,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gofrs/flock"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -73,9 +78,28 @@ const (
 // EventsFile is the name of the raw events log.
 const EventsFile = ".events.jsonl"
 
+// LockFile is the name of the lock file that serialises appends to the
+// events log with its rotation, across every gt process.
+const LockFile = EventsFile + ".lock"
+
 // mutex protects concurrent writes to the events file.
 var mutex sync.Mutex
 
+// withLogLock runs fn holding the events log lock. Writers hold it for
+// each append and Rotate for the rename, so no process can still be
+// appending to a log once it has been moved aside.
+func withLogLock(townRoot string, fn func() error) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	lock := flock.New(filepath.Join(townRoot, LockFile))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking events log: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
 // Log writes an event to the events log.
 // The event is appended to ~/gt/.events.jsonl.
 // Returns nil if logging fails (events are best-effort).
@@ -120,20 +144,18 @@ func write(event Event) error {
 	data = append(data, '\n')
 
 	// Append to file with proper locking
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
-	if err != nil {
-		return fmt.Errorf("opening events file: %w", err)
-	}
-	defer f.Close()
-
-	if _, err := f.Write(data); err != nil {
-		return fmt.Errorf("writing event: %w", err)
-	}
-
-	return nil
+	return withLogLock(townRoot, func() error {
+		f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
+		if err != nil {
+			return fmt.Errorf("opening events file: %w", err)
+		}
+		defer f.Close()
+
+		if _, err := f.Write(data); err != nil {
+			return fmt.Errorf("writing event: %w", err)
+		}
+		return nil
+	})
 }
 
 // Payload helpers for common event structures.
@@ -0,0 +1,654 @@
+package events
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// SegmentsDir is the directory (under the town root) holding rotated,
+// gzip-compressed segments of the events log.
+const SegmentsDir = ".events"
+
+// segmentIndexFile lists the segments in SegmentsDir with their time ranges.
+const segmentIndexFile = "index.json"
+
+// rotatingSuffix marks an active log that has been renamed aside for rotation
+// but not yet compressed. Readers include it so no events go missing mid-rotation.
+const rotatingSuffix = ".rotating"
+
+// maxLineSize bounds a single event line when scanning.
+const maxLineSize = 1024 * 1024
+
+// Segment describes one rotated, compressed slice of the events log.
+type Segment struct {
+	File  string    `json:"file"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Count int       `json:"count"`
+	Size  int64     `json:"size"` // compressed bytes
+}
+
+// SegmentIndex lists rotated segments, oldest first.
+type SegmentIndex struct {
+	Segments []Segment `json:"segments"`
+}
+
+// RotationPolicy controls when the active log is rotated and how long
+// events are kept in rotated segments.
+type RotationPolicy struct {
+	// MaxSize rotates the active log once it reaches this many bytes (0 = never).
+	MaxSize int64
+
+	// MaxAge rotates the active log once its oldest event is this old (0 = never).
+	MaxAge time.Duration
+
+	// Retention is how long events are kept, by visibility level.
+	// A missing or zero entry keeps events of that level forever.
+	Retention map[string]time.Duration
+}
+
+// PolicyFromConfig converts town events settings into a RotationPolicy.
+// An unset MaxAge falls back to config.DefaultEventsConfig; retention
+// applies only to the visibility levels configured.
+func PolicyFromConfig(cfg *config.EventsConfig) RotationPolicy {
+	defaults := config.DefaultEventsConfig()
+	if cfg == nil {
+		cfg = defaults
+	}
+
+	p := RotationPolicy{Retention: make(map[string]time.Duration)}
+
+	p.MaxSize = int64(cfg.MaxSizeMB) * 1024 * 1024
+
+	maxAge := cfg.MaxAge
+	if maxAge == "" {
+		maxAge = defaults.MaxAge
+	}
+	if d, err := time.ParseDuration(maxAge); err == nil && d > 0 {
+		p.MaxAge = d
+	}
+
+	for _, visibility := range []string{VisibilityAudit, VisibilityFeed} {
+		// Unconfigured levels are kept forever
+		if d, err := time.ParseDuration(cfg.Retention[visibility]); err == nil && d > 0 {
+			p.Retention[visibility] = d
+		}
+	}
+	return p
+}
+
+// LoadRotationPolicy reads the rotation policy from town settings.
+// Missing or unreadable settings yield the default policy.
+func LoadRotationPolicy(townRoot string) RotationPolicy {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return PolicyFromConfig(nil)
+	}
+	return PolicyFromConfig(settings.Events)
+}
+
+// retentionFor returns how long an event with the given visibility is kept.
+// Zero means forever. "both" events are kept for the longer of audit and feed.
+func (p RotationPolicy) retentionFor(visibility string) time.Duration {
+	switch visibility {
+	case VisibilityFeed:
+		return p.Retention[VisibilityFeed]
+	case VisibilityBoth:
+		audit, feed := p.Retention[VisibilityAudit], p.Retention[VisibilityFeed]
+		if audit == 0 || feed == 0 {
+			return 0
+		}
+		if audit > feed {
+			return audit
+		}
+		return feed
+	default:
+		return p.Retention[VisibilityAudit]
+	}
+}
+
+// SegmentIndexPath returns the path of the segment index.
+func SegmentIndexPath(townRoot string) string {
+	return filepath.Join(townRoot, SegmentsDir, segmentIndexFile)
+}
+
+// LoadSegmentIndex loads the segment index. If the index is missing but
+// segments exist on disk, it is rebuilt from file names (with unknown time
+// ranges, so readers never skip them).
+func LoadSegmentIndex(townRoot string) (*SegmentIndex, error) {
+	data, err := os.ReadFile(SegmentIndexPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted town root
+	if err == nil {
+		var idx SegmentIndex
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return nil, fmt.Errorf("parsing segment index: %w", err)
+		}
+		return &idx, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	idx := &SegmentIndex{}
+	files, _ := filepath.Glob(filepath.Join(townRoot, SegmentsDir, "events-*.jsonl.gz"))
+	sort.Strings(files)
+	for _, f := range files {
+		seg := Segment{File: filepath.Base(f)}
+		if info, err := os.Stat(f); err == nil {
+			seg.Size = info.Size()
+		}
+		idx.Segments = append(idx.Segments, seg)
+	}
+	return idx, nil
+}
+
+// saveSegmentIndex writes the segment index, oldest segment first.
+func saveSegmentIndex(townRoot string, idx *SegmentIndex) error {
+	sort.SliceStable(idx.Segments, func(i, j int) bool {
+		return idx.Segments[i].File < idx.Segments[j].File
+	})
+	return util.AtomicWriteJSON(SegmentIndexPath(townRoot), idx)
+}
+
+// Rotate moves the active events log into a new compressed segment if it has
+// outgrown the policy's size or age limit. Returns the new segment, or nil if
+// rotation was not due.
+//
+// The rename happens under the events log lock, so every append made by any
+// process lands either in the segment or in the new active log. Bytes that
+// still reach the moved-aside log (a writer that kept it open without the
+// lock) are carried into the new active log before it is removed.
+func Rotate(townRoot string, policy RotationPolicy, now time.Time) (*Segment, error) {
+	activePath := filepath.Join(townRoot, EventsFile)
+	rotatingPath := activePath + rotatingSuffix
+
+	var due bool
+	var rotatedSize int64
+	err := withLogLock(townRoot, func() error {
+		// A leftover .rotating file means a previous rotation was interrupted:
+		// finish that one first and leave the active log for the next pass.
+		if _, err := os.Stat(rotatingPath); os.IsNotExist(err) {
+			ok, err := rotationDue(activePath, policy, now)
+			if err != nil || !ok {
+				return err
+			}
+			if err := os.Rename(activePath, rotatingPath); err != nil {
+				return fmt.Errorf("moving active log aside: %w", err)
+			}
+		}
+		info, err := os.Stat(rotatingPath)
+		if err != nil {
+			return err
+		}
+		due, rotatedSize = true, info.Size()
+		return nil
+	})
+	if err != nil || !due {
+		return nil, err
+	}
+
+	// Load the index first: if it has to be rebuilt from disk, it must not
+	// already contain the segment we are about to install
+	idx, err := LoadSegmentIndex(townRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	seg, err := compressSegment(townRoot, rotatingPath, rotatedSize, now)
+	if err != nil {
+		return nil, err
+	}
+	if seg != nil {
+		idx.Segments = append(idx.Segments, *seg)
+		if err := saveSegmentIndex(townRoot, idx); err != nil {
+			return nil, fmt.Errorf("saving segment index: %w", err)
+		}
+	}
+
+	err = withLogLock(townRoot, func() error {
+		if err := carryOver(rotatingPath, activePath, rotatedSize); err != nil {
+			return fmt.Errorf("carrying late events into active log: %w", err)
+		}
+		return os.Remove(rotatingPath)
+	})
+	if err != nil {
+		return seg, fmt.Errorf("removing rotated log: %w", err)
+	}
+	return seg, nil
+}
+
+// carryOver appends whatever was written to the rotated log past offset
+// to the active log.
+func carryOver(rotatingPath, activePath string, offset int64) error {
+	src, err := os.Open(rotatingPath) //nolint:gosec // G304: path is constructed from trusted town root
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() <= offset {
+		return nil
+	}
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(activePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// rotationDue reports whether the active log exceeds the size or age limit.
+func rotationDue(activePath string, policy RotationPolicy, now time.Time) (bool, error) {
+	info, err := os.Stat(activePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if info.Size() == 0 {
+		return false, nil
+	}
+	if policy.MaxSize > 0 && info.Size() >= policy.MaxSize {
+		return true, nil
+	}
+	if policy.MaxAge > 0 {
+		oldest, ok := firstTimestamp(activePath)
+		if ok && now.Sub(oldest) >= policy.MaxAge {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// firstTimestamp returns the timestamp of the first parseable event in a log.
+func firstTimestamp(path string) (time.Time, bool) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed from trusted town root
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+
+	scanner := newLineScanner(f)
+	for scanner.Scan() {
+		if ts, _, ok := parseLine(scanner.Bytes()); ok {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compressSegment gzips the first size bytes of a plain log into a new dated
+// segment file. Returns nil if they contained no events.
+func compressSegment(townRoot, srcPath string, size int64, now time.Time) (*Segment, error) {
+	src, err := os.Open(srcPath) //nolint:gosec // G304: path is constructed from trusted town root
+	if err != nil {
+		return nil, fmt.Errorf("opening rotated log: %w", err)
+	}
+	defer src.Close()
+
+	seg := &Segment{}
+	scanner := newLineScanner(io.LimitReader(src, size))
+	tmpPath, err := writeSegment(townRoot, func(w io.Writer) error {
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(strings.TrimSpace(string(line))) == 0 {
+				continue
+			}
+			if ts, _, ok := parseLine(line); ok {
+				seg.extend(ts)
+			}
+			seg.Count++
+			if err := writeLine(w, line); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if seg.Count == 0 {
+		_ = os.Remove(tmpPath)
+		return nil, nil
+	}
+	if seg.Start.IsZero() {
+		seg.Start, seg.End = now, now
+	}
+
+	seg.File = segmentName(townRoot, seg.Start)
+	finalPath := filepath.Join(townRoot, SegmentsDir, seg.File)
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("installing segment: %w", err)
+	}
+	if info, err := os.Stat(finalPath); err == nil {
+		seg.Size = info.Size()
+	}
+	return seg, nil
+}
+
+// writeSegment streams gzip-compressed content into a temp file in the
+// segments directory and returns its path.
+func writeSegment(townRoot string, fill func(w io.Writer) error) (string, error) {
+	dir := filepath.Join(townRoot, SegmentsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating segments dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".segment-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("creating segment: %w", err)
+	}
+	gz := gzip.NewWriter(tmp)
+
+	if err := fill(gz); err != nil {
+		_ = gz.Close()
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("writing segment: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("compressing segment: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("closing segment: %w", err)
+	}
+	return tmp.Name(), nil
+}
+
+// writeLine writes one event line and its newline. The line may alias a
+// scanner buffer, so it is never appended to.
+func writeLine(w io.Writer, line []byte) error {
+	if _, err := w.Write(line); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte{'\n'})
+	return err
+}
+
+// segmentName returns an unused, date-sortable file name for a segment
+// whose first event is at start.
+func segmentName(townRoot string, start time.Time) string {
+	base := "events-" + start.UTC().Format("20060102T150405Z")
+	name := base + ".jsonl.gz"
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filepath.Join(townRoot, SegmentsDir, name)); os.IsNotExist(err) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d.jsonl.gz", base, i)
+	}
+}
+
+// extend widens the segment's time range to include ts.
+func (s *Segment) extend(ts time.Time) {
+	if s.Start.IsZero() || ts.Before(s.Start) {
+		s.Start = ts
+	}
+	if ts.After(s.End) {
+		s.End = ts
+	}
+}
+
+// Compact applies the retention policy to rotated segments: expired events
+// are dropped, segments are rewritten in place, and segments left empty are
+// deleted. Returns the number of events dropped.
+func Compact(townRoot string, policy RotationPolicy, now time.Time) (int, error) {
+	// The shortest non-zero retention bounds which segments can hold expired events
+	var shortest time.Duration
+	for _, d := range policy.Retention {
+		if d > 0 && (shortest == 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	if shortest == 0 {
+		return 0, nil
+	}
+
+	idx, err := LoadSegmentIndex(townRoot)
+	if err != nil {
+		return 0, err
+	}
+
+	dropped := 0
+	var kept []Segment
+	for _, seg := range idx.Segments {
+		if !seg.Start.IsZero() && now.Sub(seg.Start) < shortest {
+			kept = append(kept, seg)
+			continue
+		}
+
+		compacted, n, err := compactSegment(townRoot, seg, policy, now)
+		if err != nil {
+			return dropped, fmt.Errorf("compacting %s: %w", seg.File, err)
+		}
+		dropped += n
+		if compacted != nil {
+			kept = append(kept, *compacted)
+		}
+	}
+
+	idx.Segments = kept
+	if err := saveSegmentIndex(townRoot, idx); err != nil {
+		return dropped, fmt.Errorf("saving segment index: %w", err)
+	}
+	return dropped, nil
+}
+
+// compactSegment rewrites one segment without its expired events.
+// Returns the updated segment (nil if it was deleted) and the number dropped.
+// Unparseable lines are dropped too: no reader can use them.
+func compactSegment(townRoot string, seg Segment, policy RotationPolicy, now time.Time) (*Segment, int, error) {
+	path := filepath.Join(townRoot, SegmentsDir, seg.File)
+
+	var lines [][]byte
+	updated := Segment{File: seg.File}
+	dropped := 0
+	err := scanSegment(path, func(line []byte) error {
+		ts, visibility, ok := parseLine(line)
+		if !ok {
+			dropped++
+			return nil
+		}
+		if keep := policy.retentionFor(visibility); keep > 0 && now.Sub(ts) >= keep {
+			dropped++
+			return nil
+		}
+		updated.extend(ts)
+		updated.Count++
+		lines = append(lines, append([]byte(nil), line...))
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Segment vanished; drop it from the index
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	if dropped == 0 {
+		if seg.Start.IsZero() {
+			// Rebuilt index entry: record the real time range now we know it
+			updated.Size = seg.Size
+			return &updated, 0, nil
+		}
+		return &seg, 0, nil
+	}
+	if updated.Count == 0 {
+		return nil, dropped, os.Remove(path)
+	}
+
+	tmpPath, err := writeSegment(townRoot, func(w io.Writer) error {
+		for _, line := range lines {
+			if err := writeLine(w, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, 0, err
+	}
+	if info, err := os.Stat(path); err == nil {
+		updated.Size = info.Size()
+	}
+	return &updated, dropped, nil
+}
+
+// Scan calls fn for every raw event line, oldest first, across rotated
+// segments and the active log. Segments that end before since are skipped
+// without being decompressed; callers still filter individual events.
+func Scan(townRoot string, since time.Time, fn func(line []byte) error) error {
+	idx, err := LoadSegmentIndex(townRoot)
+	if err != nil {
+		return err
+	}
+
+	for _, seg := range idx.Segments {
+		if !since.IsZero() && !seg.End.IsZero() && seg.End.Before(since) {
+			continue
+		}
+		if err := scanSegment(filepath.Join(townRoot, SegmentsDir, seg.File), fn); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("reading segment %s: %w", seg.File, err)
+		}
+	}
+
+	activePath := filepath.Join(townRoot, EventsFile)
+	for _, path := range []string{activePath + rotatingSuffix, activePath} {
+		if err := scanFile(path, fn); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSince returns all events at or after since, oldest first.
+// A zero since returns every retained event.
+func ReadSince(townRoot string, since time.Time) ([]Event, error) {
+	var result []Event
+	err := Scan(townRoot, since, func(line []byte) error {
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil // Skip malformed lines
+		}
+		if !since.IsZero() {
+			ts, err := time.Parse(time.RFC3339, e.Timestamp)
+			if err != nil || ts.Before(since) {
+				return nil
+			}
+		}
+		result = append(result, e)
+		return nil
+	})
+	return result, err
+}
+
+// ActiveLogReplaced reports whether the active events log at path is no
+// longer the file f refers to, meaning it was rotated away. Tailers use this
+// to reopen the log after draining f.
+func ActiveLogReplaced(f *os.File, path string) bool {
+	current, err := os.Stat(path)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	open, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return !os.SameFile(open, current)
+}
+
+// scanSegment calls fn for each line of a gzip-compressed segment.
+func scanSegment(path string, fn func(line []byte) error) error {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed from trusted town root
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	return scanLines(gz, fn)
+}
+
+// scanFile calls fn for each line of a plain log file.
+func scanFile(path string, fn func(line []byte) error) error {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed from trusted town root
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return scanLines(f, fn)
+}
+
+// scanLines calls fn for each non-blank line read from r.
+func scanLines(r io.Reader, fn func(line []byte) error) error {
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// newLineScanner returns a line scanner that tolerates large events.
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return scanner
+}
+
+// parseLine extracts the timestamp and visibility of a raw event line.
+func parseLine(line []byte) (time.Time, string, bool) {
+	var e struct {
+		Timestamp  string `json:"ts"`
+		Visibility string `json:"visibility"`
+	}
+	if err := json.Unmarshal(line, &e); err != nil {
+		return time.Time{}, "", false
+	}
+	ts, err := time.Parse(time.RFC3339, e.Timestamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return ts, e.Visibility, true
+}
@@ -0,0 +1,244 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// writeActiveLog writes events to the town's active log, one per line.
+func writeActiveLog(t *testing.T, townRoot string, evs ...Event) {
+	t.Helper()
+	var b strings.Builder
+	for _, e := range evs {
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, EventsFile), []byte(b.String()), 0644); err != nil {
+		t.Fatalf("write events: %v", err)
+	}
+}
+
+func event(at time.Time, typ, visibility string) Event {
+	return Event{Timestamp: at.UTC().Format(time.RFC3339), Source: "gt", Type: typ, Visibility: visibility}
+}
+
+func eventTypes(t *testing.T, townRoot string, since time.Time) []string {
+	t.Helper()
+	evs, err := ReadSince(townRoot, since)
+	if err != nil {
+		t.Fatalf("ReadSince: %v", err)
+	}
+	var types []string
+	for _, e := range evs {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestRotateBySizeAndAge(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Now()
+
+	writeActiveLog(t, townRoot, event(now.Add(-time.Hour), "a", VisibilityFeed))
+
+	// Neither limit reached
+	seg, err := Rotate(townRoot, RotationPolicy{MaxSize: 1 << 20, MaxAge: 2 * time.Hour}, now)
+	if err != nil || seg != nil {
+		t.Fatalf("Rotate under limits = %v, %v; want nil, nil", seg, err)
+	}
+
+	// Age limit reached
+	seg, err = Rotate(townRoot, RotationPolicy{MaxAge: 30 * time.Minute}, now)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if seg == nil || seg.Count != 1 || !strings.HasPrefix(seg.File, "events-") || !strings.HasSuffix(seg.File, ".jsonl.gz") {
+		t.Fatalf("segment = %+v", seg)
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, EventsFile)); !os.IsNotExist(err) {
+		t.Errorf("active log still present after rotation: %v", err)
+	}
+
+	// Size limit reached
+	writeActiveLog(t, townRoot, event(now, "b", VisibilityFeed))
+	if seg, err := Rotate(townRoot, RotationPolicy{MaxSize: 1}, now); err != nil || seg == nil {
+		t.Fatalf("Rotate by size = %v, %v", seg, err)
+	}
+
+	idx, err := LoadSegmentIndex(townRoot)
+	if err != nil {
+		t.Fatalf("LoadSegmentIndex: %v", err)
+	}
+	if len(idx.Segments) != 2 {
+		t.Fatalf("index = %+v, want 2 segments", idx.Segments)
+	}
+	if idx.Segments[0].File >= idx.Segments[1].File {
+		t.Errorf("segments not ordered oldest first: %+v", idx.Segments)
+	}
+}
+
+func TestCarryOverLateEvents(t *testing.T) {
+	townRoot := t.TempDir()
+	activePath := filepath.Join(townRoot, EventsFile)
+	rotatingPath := activePath + rotatingSuffix
+
+	// "late" reached the rotated log after it was measured and compressed
+	if err := os.WriteFile(rotatingPath, []byte("rotated\nlate\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(activePath, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := carryOver(rotatingPath, activePath, int64(len("rotated\n"))); err != nil {
+		t.Fatalf("carryOver: %v", err)
+	}
+	data, err := os.ReadFile(activePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new\nlate\n" {
+		t.Errorf("active log = %q, want %q", data, "new\nlate\n")
+	}
+
+	// Nothing past the offset: the active log is untouched
+	if err := carryOver(rotatingPath, activePath, int64(len("rotated\nlate\n"))); err != nil {
+		t.Fatalf("carryOver: %v", err)
+	}
+	if data, _ := os.ReadFile(activePath); string(data) != "new\nlate\n" {
+		t.Errorf("active log = %q after empty carry-over", data)
+	}
+}
+
+func TestScanAcrossSegments(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Now()
+
+	writeActiveLog(t, townRoot, event(now.Add(-3*time.Hour), "old", VisibilityAudit))
+	if _, err := Rotate(townRoot, RotationPolicy{MaxSize: 1}, now); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	writeActiveLog(t, townRoot, event(now.Add(-time.Minute), "new", VisibilityFeed))
+
+	if got := eventTypes(t, townRoot, time.Time{}); strings.Join(got, ",") != "old,new" {
+		t.Errorf("all events = %v, want [old new]", got)
+	}
+	if got := eventTypes(t, townRoot, now.Add(-time.Hour)); strings.Join(got, ",") != "new" {
+		t.Errorf("recent events = %v, want [new]", got)
+	}
+
+	// Without the index, segments are still found on disk
+	if err := os.Remove(SegmentIndexPath(townRoot)); err != nil {
+		t.Fatalf("remove index: %v", err)
+	}
+	if got := eventTypes(t, townRoot, time.Time{}); strings.Join(got, ",") != "old,new" {
+		t.Errorf("events without index = %v, want [old new]", got)
+	}
+}
+
+func TestCompactRetentionByVisibility(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Now()
+	tenDays := now.Add(-10 * 24 * time.Hour)
+
+	writeActiveLog(t, townRoot,
+		event(tenDays, "audit-old", VisibilityAudit),
+		event(tenDays, "feed-old", VisibilityFeed),
+		event(tenDays, "both-old", VisibilityBoth),
+		event(now.Add(-time.Hour), "audit-new", VisibilityAudit),
+	)
+	if _, err := Rotate(townRoot, RotationPolicy{MaxSize: 1}, now); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	policy := RotationPolicy{Retention: map[string]time.Duration{
+		VisibilityAudit: 7 * 24 * time.Hour,
+		VisibilityFeed:  30 * 24 * time.Hour,
+	}}
+	dropped, err := Compact(townRoot, policy, now)
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if dropped != 1 {
+		t.Errorf("dropped = %d, want 1", dropped)
+	}
+	if got := eventTypes(t, townRoot, time.Time{}); strings.Join(got, ",") != "feed-old,both-old,audit-new" {
+		t.Errorf("events after compaction = %v", got)
+	}
+
+	// Once everything has expired the segment is deleted
+	dropped, err = Compact(townRoot, policy, now.Add(60*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if dropped != 3 {
+		t.Errorf("dropped = %d, want 3", dropped)
+	}
+	idx, _ := LoadSegmentIndex(townRoot)
+	if len(idx.Segments) != 0 {
+		t.Errorf("segments after full expiry = %+v, want none", idx.Segments)
+	}
+	files, _ := filepath.Glob(filepath.Join(townRoot, SegmentsDir, "events-*"))
+	if len(files) != 0 {
+		t.Errorf("segment files left behind: %v", files)
+	}
+}
+
+func TestPolicyFromConfig(t *testing.T) {
+	p := PolicyFromConfig(nil)
+	if p.MaxSize != 64*1024*1024 || p.MaxAge != 24*time.Hour {
+		t.Errorf("default policy = %+v", p)
+	}
+	if len(p.Retention) != 0 {
+		t.Errorf("default retention = %v, want events kept forever", p.Retention)
+	}
+
+	p = PolicyFromConfig(&config.EventsConfig{
+		MaxSizeMB: 1,
+		Retention: map[string]string{VisibilityAudit: "0", VisibilityFeed: "720h"},
+	})
+	if p.MaxSize != 1024*1024 || p.MaxAge != 24*time.Hour {
+		t.Errorf("policy = %+v", p)
+	}
+	if _, ok := p.Retention[VisibilityAudit]; ok {
+		t.Errorf("audit retention \"0\" should keep forever, got %v", p.Retention)
+	}
+	if p.Retention[VisibilityFeed] != 30*24*time.Hour {
+		t.Errorf("feed retention = %v, want 720h", p.Retention[VisibilityFeed])
+	}
+	if p.retentionFor(VisibilityBoth) != 0 {
+		t.Errorf("both-visibility events should follow the longer (forever) retention")
+	}
+}
+
+func TestActiveLogReplaced(t *testing.T) {
+	townRoot := t.TempDir()
+	path := filepath.Join(townRoot, EventsFile)
+	writeActiveLog(t, townRoot, event(time.Now(), "a", VisibilityFeed))
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	if ActiveLogReplaced(f, path) {
+		t.Error("fresh handle reported as replaced")
+	}
+	if _, err := Rotate(townRoot, RotationPolicy{MaxSize: 1}, time.Now()); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if !ActiveLogReplaced(f, path) {
+		t.Error("rotated log not reported as replaced")
+	}
+}
@@ -88,7 +88,7 @@ func (c *Curator) Start() error {
 	}
 
 	c.wg.Add(1)
-	go c.run(file)
+	go c.run(file, eventsPath)
 
 	return nil
 }
@@ -101,9 +101,9 @@ func (c *Curator) Stop() {
 
 // run is the main curator loop.
 // ZFC: No in-memory state to clean up - state is derived from the events file.
-func (c *Curator) run(file *os.File) {
+func (c *Curator) run(file *os.File, eventsPath string) {
 	defer c.wg.Done()
-	defer file.Close()
+	defer func() { _ = file.Close() }()
 
 	reader := bufio.NewReader(file)
 	ticker := time.NewTicker(100 * time.Millisecond)
@@ -115,6 +115,10 @@ func (c *Curator) run(file *os.File) {
 			return
 
 		case <-ticker.C:
+			// Check for rotation before draining, so events appended to the
+			// old file up to the rename are not lost
+			rotated := events.ActiveLogReplaced(file, eventsPath)
+
 			// Read available lines
 			for {
 				line, err := reader.ReadString('\n')
@@ -123,6 +127,18 @@ func (c *Curator) run(file *os.File) {
 				}
 				c.processLine(line)
 			}
+
+			// Follow the active log across rotation: the new file only holds
+			// events written since the rename
+			if rotated {
+				next, err := os.OpenFile(eventsPath, os.O_RDONLY|os.O_CREATE, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
+				if err != nil {
+					continue
+				}
+				_ = file.Close()
+				file = next
+				reader = bufio.NewReader(file)
+			}
 		}
 	}
 }
@@ -217,50 +233,15 @@ func (c *Curator) readRecentFeedEvents(window time.Duration) []FeedEvent {
 	return result
 }
 
-// readRecentEvents reads events from the events file within the given time window.
+// readRecentEvents reads events from the events log within the given time window.
 // ZFC: This is the observable state that replaces in-memory caching.
-// Uses tail-like reading for performance (reads last N lines).
+// Rotated segments older than the window are skipped via the segment index.
 func (c *Curator) readRecentEvents(window time.Duration) []events.Event {
-	eventsPath := filepath.Join(c.townRoot, events.EventsFile)
-
-	// Read the file (for small files, this is fine; for large files, consider tail-like reading)
-	data, err := os.ReadFile(eventsPath)
+	recent, err := events.ReadSince(c.townRoot, time.Now().Add(-window))
 	if err != nil {
 		return nil
 	}
-
-	now := time.Now()
-	cutoff := now.Add(-window)
-	var result []events.Event
-
-	// Parse lines from the end (most recent first) for efficiency
-	lines := strings.Split(string(data), "\n")
-	for i := len(lines) - 1; i >= 0; i-- {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
-		}
-
-		var event events.Event
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			continue
-		}
-
-		// Parse timestamp
-		ts, err := time.Parse(time.RFC3339, event.Timestamp)
-		if err != nil {
-			continue
-		}
-
-		// Stop if we've gone past the window
-		if ts.Before(cutoff) {
-			break
-		}
-
-		result = append(result, event)
-	}
-
-	return result
+	return recent
 }
 
 // countRecentSlings counts sling events from an actor within the given window.
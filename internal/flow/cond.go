@@ -0,0 +1,446 @@
+// Package flow evaluates step control flow: `when` conditions, `foreach`
+// lists and bounded `until` loops. Workflow formulas (internal/formula) and
+// markdown molecules (internal/beads) share it, so a step decides the same
+// way whichever format it was written in.
+//
+// Conditions are small boolean expressions:
+//
+//	mode == "full"
+//	{{mode}} != "dry-run" && steps.scan.status == "done"
+//	!(steps.check.iterations >= 3) || retry
+//
+// Operands are quoted strings, numbers, true/false, or references: a
+// variable (name, vars.name or {{name}}) or a prior step's state
+// (steps.<id>.<key>). An unset variable is empty. Comparisons are numeric
+// when both sides are numbers and string comparisons otherwise. A bare
+// operand is true unless it is empty, "false", "0", "no" or "off".
+package flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxIterations bounds an until loop that sets no explicit limit.
+const DefaultMaxIterations = 10
+
+// MaxIterationsLimit is the largest iteration bound a step may declare.
+const MaxIterationsLimit = 100
+
+// Lookup resolves a reference to its value. It reports false when the
+// reference is unknown.
+type Lookup func(ref string) (string, bool)
+
+// Cond is a parsed condition.
+type Cond struct {
+	src  string
+	root node
+}
+
+// ParseCond parses a condition expression.
+func ParseCond(s string) (*Cond, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %w", s, err)
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("condition is empty")
+	}
+	p := &parser{toks: toks}
+	root, err := p.or()
+	if err == nil && p.pos < len(p.toks) {
+		err = fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %w", s, err)
+	}
+	return &Cond{src: s, root: root}, nil
+}
+
+// String returns the condition as written.
+func (c *Cond) String() string { return c.src }
+
+// Refs returns the references the condition uses, in order of appearance.
+func (c *Cond) Refs() []string {
+	var refs []string
+	c.root.refs(&refs)
+	return refs
+}
+
+// Eval evaluates the condition. An unresolved reference, variable or
+// step, is empty, so a condition on an unset variable is false.
+func (c *Cond) Eval(lookup Lookup) bool {
+	return Truthy(c.root.eval(lookup))
+}
+
+// Bind replaces the variable references in a condition that lookup
+// resolves with their quoted values, leaving step references (and unset
+// variables) for evaluation at run time. Workflow and molecule steps bind
+// their variables when they are instantiated.
+func Bind(s string, lookup Lookup) (string, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return "", fmt.Errorf("condition %q: %w", s, err)
+	}
+	parts := make([]string, 0, len(toks))
+	for _, t := range toks {
+		switch t.kind {
+		case tokString:
+			parts = append(parts, Quote(t.text))
+		case tokRef:
+			if step, _, _ := SplitRef(t.text); step == "" && lookup != nil {
+				if v, ok := lookup(t.text); ok {
+					parts = append(parts, Quote(v))
+					continue
+				}
+			}
+			parts = append(parts, t.text)
+		default:
+			parts = append(parts, t.text)
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// Quote returns v as a condition string literal.
+func Quote(v string) string {
+	if strings.ContainsRune(v, '"') {
+		return "'" + v + "'"
+	}
+	return `"` + v + `"`
+}
+
+// Truthy reports whether a value counts as true.
+func Truthy(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "false", "0", "no", "off":
+		return false
+	}
+	return true
+}
+
+// SplitRef splits a reference into a step ID and key (steps.<id>.<key>) or
+// a variable name (name or vars.name).
+func SplitRef(ref string) (step, key, name string) {
+	if rest, ok := strings.CutPrefix(ref, "steps."); ok {
+		if i := strings.LastIndex(rest, "."); i > 0 {
+			return rest[:i], rest[i+1:], ""
+		}
+		return rest, "", ""
+	}
+	return "", "", strings.TrimPrefix(ref, "vars.")
+}
+
+// SplitList splits a foreach list value on commas and newlines, dropping
+// empty items.
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Boolean results of operators.
+const (
+	trueValue  = "true"
+	falseValue = ""
+)
+
+func boolValue(b bool) string {
+	if b {
+		return trueValue
+	}
+	return falseValue
+}
+
+type node interface {
+	eval(Lookup) string
+	refs(*[]string)
+}
+
+type literal string
+
+func (l literal) eval(Lookup) string { return string(l) }
+func (l literal) refs(*[]string)     {}
+
+type ref string
+
+func (r ref) eval(lookup Lookup) string {
+	if lookup != nil {
+		if v, ok := lookup(string(r)); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+func (r ref) refs(out *[]string) { *out = append(*out, string(r)) }
+
+type not struct{ x node }
+
+func (n not) eval(lookup Lookup) string { return boolValue(!Truthy(n.x.eval(lookup))) }
+func (n not) refs(out *[]string)        { n.x.refs(out) }
+
+type binary struct {
+	op   string
+	l, r node
+}
+
+func (b binary) eval(lookup Lookup) string {
+	switch b.op {
+	case "&&":
+		return boolValue(Truthy(b.l.eval(lookup)) && Truthy(b.r.eval(lookup)))
+	case "||":
+		return boolValue(Truthy(b.l.eval(lookup)) || Truthy(b.r.eval(lookup)))
+	}
+	return boolValue(compare(b.op, b.l.eval(lookup), b.r.eval(lookup)))
+}
+
+func (b binary) refs(out *[]string) {
+	b.l.refs(out)
+	b.r.refs(out)
+}
+
+// compare applies a comparison operator, numerically when both sides are
+// numbers.
+func compare(op, l, r string) bool {
+	var c int
+	lf, lerr := strconv.ParseFloat(l, 64)
+	rf, rerr := strconv.ParseFloat(r, 64)
+	switch {
+	case lerr == nil && rerr == nil:
+		switch {
+		case lf < rf:
+			c = -1
+		case lf > rf:
+			c = 1
+		}
+	default:
+		c = strings.Compare(l, r)
+	}
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokOp tokenKind = iota
+	tokString
+	tokNumber
+	tokRef
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// lex splits a condition into tokens.
+func lex(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(s[i:], "{{"):
+			end := strings.Index(s[i:], "}}")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated {{")
+			}
+			name := strings.TrimSpace(s[i+2 : i+end])
+			if !isIdent(name) {
+				return nil, fmt.Errorf("invalid reference {{%s}}", name)
+			}
+			toks = append(toks, token{tokRef, name})
+			i += end + 2
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			toks = append(toks, token{tokString, s[i+1 : i+1+end]})
+			i += end + 2
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			op := s[i : i+1]
+			if i+1 < len(s) {
+				if two := s[i : i+2]; two == "==" || two == "!=" || two == "<=" || two == ">=" || two == "&&" || two == "||" {
+					op = two
+				}
+			}
+			if op == "=" || op == "&" || op == "|" {
+				return nil, fmt.Errorf("unknown operator %q", op)
+			}
+			toks = append(toks, token{tokOp, op})
+			i += len(op)
+		case c == '(' || c == ')':
+			toks = append(toks, token{tokOp, string(c)})
+			i++
+		default:
+			j := i
+			for j < len(s) && isWordByte(s[j]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected %q", string(c))
+			}
+			word := s[i:j]
+			switch {
+			case word == "and":
+				toks = append(toks, token{tokOp, "&&"})
+			case word == "or":
+				toks = append(toks, token{tokOp, "||"})
+			case word == "not":
+				toks = append(toks, token{tokOp, "!"})
+			case word == "true" || word == "false":
+				toks = append(toks, token{tokString, boolValue(word == "true")})
+			case isNumber(word):
+				toks = append(toks, token{tokNumber, word})
+			case isIdent(word):
+				toks = append(toks, token{tokRef, word})
+			default:
+				return nil, fmt.Errorf("invalid word %q", word)
+			}
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '.' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	c := s[0]
+	if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isWordByte(s[i]) {
+			return false
+		}
+	}
+	return !strings.HasSuffix(s, ".")
+}
+
+// parser is a recursive-descent parser over condition tokens:
+//
+//	or   = and { "||" and }
+//	and  = unary { "&&" unary }
+//	unary = "!" unary | cmp
+//	cmp  = atom [ ("=="|"!="|"<"|"<="|">"|">=") atom ]
+//	atom = "(" or ")" | string | number | reference
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peekOp(ops ...string) string {
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == tokOp {
+		for _, op := range ops {
+			if p.toks[p.pos].text == op {
+				return op
+			}
+		}
+	}
+	return ""
+}
+
+func (p *parser) or() (node, error) {
+	l, err := p.and()
+	for err == nil && p.peekOp("||") != "" {
+		p.pos++
+		var r node
+		if r, err = p.and(); err == nil {
+			l = binary{"||", l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) and() (node, error) {
+	l, err := p.unary()
+	for err == nil && p.peekOp("&&") != "" {
+		p.pos++
+		var r node
+		if r, err = p.unary(); err == nil {
+			l = binary{"&&", l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) unary() (node, error) {
+	if p.peekOp("!") != "" {
+		p.pos++
+		x, err := p.unary()
+		return not{x}, err
+	}
+	l, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.peekOp("==", "!=", "<", "<=", ">", ">="); op != "" {
+		p.pos++
+		r, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		return binary{op, l, r}, nil
+	}
+	return l, nil
+}
+
+func (p *parser) atom() (node, error) {
+	if p.pos >= len(p.toks) {
+		return nil, fmt.Errorf("unexpected end")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	switch t.kind {
+	case tokString, tokNumber:
+		return literal(t.text), nil
+	case tokRef:
+		return ref(t.text), nil
+	}
+	if t.text == "(" {
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peekOp(")") == "" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
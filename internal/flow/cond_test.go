@@ -0,0 +1,111 @@
+package flow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCondEval(t *testing.T) {
+	values := map[string]string{
+		"mode":                    "full",
+		"count":                   "12",
+		"steps.scan.status":       "done",
+		"steps.check.iterations":  "3",
+		"steps.check.found_leaks": "",
+	}
+	lookup := func(ref string) (string, bool) {
+		if _, _, name := SplitRef(ref); name != "" {
+			ref = name
+		}
+		v, ok := values[ref]
+		return v, ok
+	}
+
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{`mode == "full"`, true},
+		{`vars.mode == 'full'`, true},
+		{`{{mode}} != "full"`, false},
+		{`deep`, false}, // unset variable
+		{`{{deep}} || deep == "yes"`, false},
+		{`count > 9`, true}, // numeric, not string, comparison
+		{`count >= 12 and count < 13`, true},
+		{`steps.scan.status == "done" && !steps.check.found_leaks`, true},
+		{`steps.check.iterations >= 3 || mode == "quick"`, true},
+		{`not (mode == "full" or count == 1)`, false},
+		{`steps.missing.status`, false},
+		{`true`, true},
+		{`false || 0`, false},
+	}
+	for _, tt := range tests {
+		c, err := ParseCond(tt.cond)
+		if err != nil {
+			t.Errorf("ParseCond(%q): %v", tt.cond, err)
+			continue
+		}
+		if got := c.Eval(lookup); got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.cond, got, tt.want)
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	vars := map[string]string{"mode": "full", "quote": `say "hi"`}
+	lookup := func(ref string) (string, bool) {
+		_, _, name := SplitRef(ref)
+		v, ok := vars[name]
+		return v, ok
+	}
+
+	tests := []struct{ cond, want string }{
+		{`{{mode}} == "full" && steps.scan.count > 0`, `"full" == "full" && steps.scan.count > 0`},
+		{`vars.mode != 'quick' or !deep`, `"full" != "quick" || ! deep`},
+		{`quote == 'x'`, `'say "hi"' == "x"`},
+	}
+	for _, tt := range tests {
+		got, err := Bind(tt.cond, lookup)
+		if err != nil {
+			t.Errorf("Bind(%q): %v", tt.cond, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Bind(%q) = %q, want %q", tt.cond, got, tt.want)
+		}
+		if _, err := ParseCond(got); err != nil {
+			t.Errorf("bound condition %q does not parse: %v", got, err)
+		}
+	}
+}
+
+func TestParseCondErrors(t *testing.T) {
+	for _, s := range []string{"", `mode = "full"`, `(mode == "x"`, `"open`, `mode ==`, `a b`, `{{mode`} {
+		if _, err := ParseCond(s); err == nil {
+			t.Errorf("ParseCond(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestCondRefs(t *testing.T) {
+	c, err := ParseCond(`{{mode}} == "x" && steps.a.status == "done" && 3 > n`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"mode", "steps.a.status", "n"}
+	if got := c.Refs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Refs() = %v, want %v", got, want)
+	}
+}
+
+func TestSplitRefAndList(t *testing.T) {
+	if step, key, _ := SplitRef("steps.check-inbox.status"); step != "check-inbox" || key != "status" {
+		t.Errorf("SplitRef = %q, %q", step, key)
+	}
+	if _, _, name := SplitRef("vars.rig"); name != "rig" {
+		t.Errorf("SplitRef(vars.rig) name = %q", name)
+	}
+	if got := SplitList("a, b,\n c,,"); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("SplitList = %v", got)
+	}
+}
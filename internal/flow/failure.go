@@ -0,0 +1,51 @@
+package flow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Failure policies: what happens to a step that fails once its retries are
+// used up.
+const (
+	OnFailureEscalate     = "escalate"      // leave the step open and escalate (default)
+	OnFailureSkip         = "skip"          // close the step as failed and carry on
+	OnFailureFailMolecule = "fail-molecule" // stop the whole molecule
+	OnFailureGoto         = "goto"          // run another step next: goto:<step>
+)
+
+// MaxRetries is the most retries a step may declare.
+const MaxRetries = 10
+
+// MaxFailures bounds how often a step may fail in all, so a goto loop
+// ends in escalation.
+const MaxFailures = 20
+
+// ParseOnFailure splits an on_failure policy into its action and, for
+// goto:<step>, the target step. An empty policy means escalate.
+func ParseOnFailure(policy string) (action, target string, err error) {
+	policy = strings.TrimSpace(policy)
+	switch policy {
+	case "":
+		return OnFailureEscalate, "", nil
+	case OnFailureEscalate, OnFailureSkip, OnFailureFailMolecule:
+		return policy, "", nil
+	}
+	if t, ok := strings.CutPrefix(policy, OnFailureGoto+":"); ok {
+		if t = strings.TrimSpace(t); t != "" {
+			return OnFailureGoto, t, nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid on_failure %q (want escalate, skip, fail-molecule or goto:<step>)", policy)
+}
+
+// ParseTimeout parses a step timeout, a positive Go duration such as
+// "30m" or "1h30m".
+func ParseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q (want a duration such as 30m)", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,37 @@
+package flow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOnFailure(t *testing.T) {
+	tests := []struct {
+		policy, action, target string
+		ok                     bool
+	}{
+		{"", OnFailureEscalate, "", true},
+		{"skip", OnFailureSkip, "", true},
+		{"fail-molecule", OnFailureFailMolecule, "", true},
+		{"goto: implement", OnFailureGoto, "implement", true},
+		{"goto:", "", "", false},
+		{"retry", "", "", false},
+	}
+	for _, tt := range tests {
+		action, target, err := ParseOnFailure(tt.policy)
+		if (err == nil) != tt.ok || action != tt.action || target != tt.target {
+			t.Errorf("ParseOnFailure(%q) = %q, %q, %v", tt.policy, action, target, err)
+		}
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	if d, err := ParseTimeout("1h30m"); err != nil || d != 90*time.Minute {
+		t.Errorf("ParseTimeout(1h30m) = %v, %v", d, err)
+	}
+	for _, s := range []string{"", "30", "-5m", "0s"} {
+		if _, err := ParseTimeout(s); err == nil {
+			t.Errorf("ParseTimeout(%q) should fail", s)
+		}
+	}
+}
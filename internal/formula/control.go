@@ -0,0 +1,435 @@
+package formula
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/flow"
+)
+
+// ModelTiers are the model tier hints a step may give.
+var ModelTiers = []string{"haiku", "sonnet", "opus"}
+
+// Step status values visible to conditions as steps.<id>.status.
+const (
+	StepPending = "pending"
+	StepDone    = "done"
+	StepSkipped = "skipped"
+)
+
+// RunState is the progress of a workflow run, against which step control
+// flow is evaluated. The zero value is an empty run.
+type RunState struct {
+	Vars       map[string]string            // resolved vars and inputs
+	Done       map[string]bool              // steps completed or skipped
+	Skipped    map[string]bool              // steps whose when condition was false
+	Iterations map[string]int               // completed iterations per step
+	Outputs    map[string]map[string]string // recorded outputs, by step and key
+}
+
+func (s *RunState) init() {
+	if s.Done == nil {
+		s.Done = make(map[string]bool)
+	}
+	if s.Skipped == nil {
+		s.Skipped = make(map[string]bool)
+	}
+	if s.Iterations == nil {
+		s.Iterations = make(map[string]int)
+	}
+}
+
+// Lookup resolves condition references: vars by name, and for steps
+// steps.<id>.status, steps.<id>.iterations, or a recorded output key.
+func (s *RunState) Lookup(ref string) (string, bool) {
+	step, key, name := flow.SplitRef(ref)
+	if step == "" {
+		v, ok := s.Vars[name]
+		return v, ok
+	}
+	switch key {
+	case "status":
+		switch {
+		case s.Skipped[step]:
+			return StepSkipped, true
+		case s.Done[step]:
+			return StepDone, true
+		}
+		return StepPending, true
+	case "iterations":
+		return strconv.Itoa(s.Iterations[step]), true
+	}
+	v, ok := s.Outputs[step][key]
+	return v, ok
+}
+
+// Advance evaluates when conditions for steps whose needs are met, marks
+// those that are false as done and skipped (which may unblock further
+// steps), and returns the steps that are ready to run, in formula order.
+func (f *Formula) Advance(s *RunState) []string {
+	s.init()
+	for changed := true; changed; {
+		changed = false
+		for _, step := range f.Steps {
+			if s.Done[step.ID] || step.When == "" || !s.needsMet(step.Needs) {
+				continue
+			}
+			if c, err := flow.ParseCond(step.When); err == nil && !c.Eval(s.Lookup) {
+				s.Done[step.ID] = true
+				s.Skipped[step.ID] = true
+				changed = true
+			}
+		}
+	}
+
+	var ready []string
+	for _, step := range f.Steps {
+		if !s.Done[step.ID] && s.needsMet(step.Needs) {
+			ready = append(ready, step.ID)
+		}
+	}
+	return ready
+}
+
+// CompleteStep records a finished iteration of a step. It reports true when
+// the step must run again: its until condition is still false and it has
+// iterations left. Otherwise the step is marked done.
+func (f *Formula) CompleteStep(s *RunState, id string) bool {
+	s.init()
+	s.Iterations[id]++
+	if step := f.GetStep(id); step != nil && step.Until != "" && s.Iterations[id] < step.Iterations() {
+		if c, err := flow.ParseCond(step.Until); err == nil && !c.Eval(s.Lookup) {
+			return true
+		}
+	}
+	s.Done[id] = true
+	return false
+}
+
+func (s *RunState) needsMet(needs []string) bool {
+	for _, need := range needs {
+		if !s.Done[need] {
+			return false
+		}
+	}
+	return true
+}
+
+// Iterations returns the most times an until step may run.
+func (s *Step) Iterations() int {
+	if s.MaxIterations > 0 {
+		return s.MaxIterations
+	}
+	return flow.DefaultMaxIterations
+}
+
+// validateControl checks when, until, foreach and max_iterations: conditions
+// must parse and may only reference declared vars and steps that finish
+// first, and foreach must name a declared var.
+func (f *Formula) validateControl() error {
+	for _, step := range f.Steps {
+		loopVars := map[string]bool{}
+		if step.Foreach != "" {
+			if f.VarSpec(step.Foreach) == nil {
+				return fmt.Errorf("step %q foreach references undeclared variable: %s", step.ID, step.Foreach)
+			}
+			loopVars["item"], loopVars["index"] = true, true
+		}
+
+		for _, o := range step.Outputs {
+			if !outputNamePattern.MatchString(o) || o == "status" || o == "iterations" {
+				return fmt.Errorf("step %q has invalid output name %q", step.ID, o)
+			}
+		}
+
+		before := f.ancestors(step.ID)
+		for _, text := range []string{step.Title, step.Description} {
+			for _, m := range stepOutputPattern.FindAllStringSubmatch(text, -1) {
+				if err := f.checkStepRef(m[1], before); err != nil {
+					return fmt.Errorf("step %q: %w", step.ID, err)
+				}
+			}
+		}
+		if step.When != "" {
+			if err := f.checkCond(step.When, before, loopVars); err != nil {
+				return fmt.Errorf("step %q when: %w", step.ID, err)
+			}
+		}
+		if step.Until != "" {
+			// An until condition may test the step's own outputs
+			before[step.ID] = true
+			if err := f.checkCond(step.Until, before, loopVars); err != nil {
+				return fmt.Errorf("step %q until: %w", step.ID, err)
+			}
+		} else if step.MaxIterations != 0 {
+			return fmt.Errorf("step %q sets max_iterations without until", step.ID)
+		}
+		if step.MaxIterations < 0 || step.MaxIterations > flow.MaxIterationsLimit {
+			return fmt.Errorf("step %q max_iterations must be between 1 and %d", step.ID, flow.MaxIterationsLimit)
+		}
+		if step.Tier != "" && !contains(ModelTiers, step.Tier) {
+			return fmt.Errorf("step %q has unknown tier %q (want %s)", step.ID, step.Tier, strings.Join(ModelTiers, ", "))
+		}
+		if err := f.checkFailure(step); err != nil {
+			return fmt.Errorf("step %q: %w", step.ID, err)
+		}
+	}
+	return nil
+}
+
+// checkFailure checks a step's timeout, retries and on_failure policy; a
+// goto must name another step.
+func (f *Formula) checkFailure(step Step) error {
+	if step.Timeout != "" {
+		if _, err := flow.ParseTimeout(step.Timeout); err != nil {
+			return err
+		}
+	}
+	if step.Retries < 0 || step.Retries > flow.MaxRetries {
+		return fmt.Errorf("retries must be between 0 and %d", flow.MaxRetries)
+	}
+	action, target, err := flow.ParseOnFailure(step.OnFailure)
+	if err != nil {
+		return err
+	}
+	if action == flow.OnFailureGoto && (target == step.ID || f.GetStep(target) == nil) {
+		return fmt.Errorf("on_failure goto:%s must name another step", target)
+	}
+	return nil
+}
+
+// checkCond parses a condition and checks its references. Steps must be
+// in before, so their state is settled when the condition is evaluated.
+func (f *Formula) checkCond(cond string, before, loopVars map[string]bool) error {
+	c, err := flow.ParseCond(cond)
+	if err != nil {
+		return err
+	}
+	for _, ref := range c.Refs() {
+		if _, _, name := flow.SplitRef(ref); name != "" {
+			if !loopVars[name] && f.VarSpec(name) == nil {
+				return fmt.Errorf("references undeclared variable: %s", name)
+			}
+			continue
+		}
+		if err := f.checkStepRef(ref, before); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStepRef checks a steps.<id>.<key> reference: the step must be in
+// before, and the key a state key or an output the step declares.
+func (f *Formula) checkStepRef(ref string, before map[string]bool) error {
+	id, key, _ := flow.SplitRef(ref)
+	step := f.GetStep(id)
+	switch {
+	case key == "":
+		return fmt.Errorf("reference %s needs a key, e.g. steps.%s.status", ref, id)
+	case step == nil:
+		return fmt.Errorf("references unknown step: %s", id)
+	case !before[id]:
+		return fmt.Errorf("references step %s, which it does not need", id)
+	case key != "status" && key != "iterations" && !contains(step.Outputs, key):
+		return fmt.Errorf("references output %s, which step %s does not declare", key, id)
+	}
+	return nil
+}
+
+var (
+	// outputNamePattern matches a valid step output name.
+	outputNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+	// stepOutputPattern matches {{steps.<id>.<key>}} placeholders.
+	stepOutputPattern = regexp.MustCompile(`\{\{\s*(steps\.[A-Za-z0-9_.-]+\.[A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+)
+
+// ExpandOutputs replaces {{steps.<id>.<key>}} placeholders with step state
+// and recorded outputs. Unknown references are left as-is.
+func (s *RunState) ExpandOutputs(text string) string {
+	return expandStepOutputs(text, s.Lookup)
+}
+
+func expandStepOutputs(text string, lookup flow.Lookup) string {
+	return stepOutputPattern.ReplaceAllStringFunc(text, func(m string) string {
+		if v, ok := lookup(stepOutputPattern.FindStringSubmatch(m)[1]); ok {
+			return v
+		}
+		return m
+	})
+}
+
+// ancestors returns the steps a step transitively needs.
+func (f *Formula) ancestors(id string) map[string]bool {
+	seen := make(map[string]bool)
+	var visit func(string)
+	visit = func(id string) {
+		step := f.GetStep(id)
+		if step == nil {
+			return
+		}
+		for _, need := range step.Needs {
+			if !seen[need] {
+				seen[need] = true
+				visit(need)
+			}
+		}
+	}
+	visit(id)
+	return seen
+}
+
+// isStatic reports whether a condition depends only on vars, so it can be
+// decided when the formula is planned.
+func isStatic(c *flow.Cond) bool {
+	for _, ref := range c.Refs() {
+		if step, _, _ := flow.SplitRef(ref); step != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// planWorkflow instantiates workflow steps into units. Conditions on vars
+// are decided here: false steps are dropped and their dependents inherit
+// their needs. Foreach steps become one unit per item, <id>.<n>, and
+// dependents need all of them. Conditions on step state are left on the
+// unit for the executor.
+func (f *Formula) planWorkflow(vars map[string]string) ([]Unit, error) {
+	order, err := f.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+
+	var units []Unit
+	became := make(map[string][]string) // step ID -> unit IDs standing in for it
+	for _, id := range order {
+		step := f.GetStep(id)
+		var needs []string
+		for _, need := range step.Needs {
+			for _, n := range became[need] {
+				if !contains(needs, n) {
+					needs = append(needs, n)
+				}
+			}
+		}
+
+		items := []string{""}
+		if step.Foreach != "" {
+			items = flow.SplitList(vars[step.Foreach])
+		}
+		for i, item := range items {
+			uvars := vars
+			unitID := id
+			if step.Foreach != "" {
+				uvars = make(map[string]string, len(vars)+2)
+				for k, v := range vars {
+					uvars[k] = v
+				}
+				uvars["item"], uvars["index"] = item, strconv.Itoa(i+1)
+				unitID = fmt.Sprintf("%s.%d", id, i+1)
+			}
+
+			u := Unit{
+				ID:          unitID,
+				Kind:        UnitStep,
+				Step:        id,
+				Title:       SubstituteVars(step.Title, uvars),
+				Description: SubstituteVars(step.Description, uvars),
+				Needs:       needs,
+			}
+			if step.When != "" {
+				c, err := flow.ParseCond(step.When)
+				if err != nil {
+					return nil, fmt.Errorf("step %q when: %w", id, err)
+				}
+				if isStatic(c) {
+					if !c.Eval(lookupVars(uvars)) {
+						continue
+					}
+				} else if u.When, err = flow.Bind(step.When, lookupVars(uvars)); err != nil {
+					return nil, fmt.Errorf("step %q when: %w", id, err)
+				}
+			}
+			if step.Until != "" {
+				until, err := flow.Bind(step.Until, lookupVars(uvars))
+				if err != nil {
+					return nil, fmt.Errorf("step %q until: %w", id, err)
+				}
+				u.Until = until
+				u.MaxIterations = step.Iterations()
+			}
+			u.Outputs = step.Outputs
+			u.Tier = step.Tier
+			u.Timeout, u.Retries, u.OnFailure = step.Timeout, step.Retries, step.OnFailure
+			units = append(units, u)
+			became[id] = append(became[id], unitID)
+		}
+		if len(became[id]) == 0 {
+			// Skipped entirely: dependents wait on what it waited on
+			became[id] = needs
+		}
+	}
+	return units, nil
+}
+
+func lookupVars(vars map[string]string) flow.Lookup {
+	return func(ref string) (string, bool) {
+		_, _, name := flow.SplitRef(ref)
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+// Control returns the unit's runtime control flow, declared outputs, tier
+// and failure handling as molecule step lines (When:, Until:,
+// MaxIterations:, Outputs:, Tier:, Timeout:, Retries:, OnFailure:), or ""
+// when it has none.
+func (u Unit) Control() string {
+	var lines []string
+	if u.When != "" {
+		lines = append(lines, "When: "+u.When)
+	}
+	if u.Until != "" {
+		lines = append(lines, "Until: "+u.Until, fmt.Sprintf("MaxIterations: %d", u.MaxIterations))
+	}
+	if len(u.Outputs) > 0 {
+		lines = append(lines, "Outputs: "+strings.Join(u.Outputs, ", "))
+	}
+	if u.Tier != "" {
+		lines = append(lines, "Tier: "+u.Tier)
+	}
+	if u.Timeout != "" {
+		lines = append(lines, "Timeout: "+u.Timeout)
+	}
+	if u.Retries > 0 {
+		lines = append(lines, fmt.Sprintf("Retries: %d", u.Retries))
+	}
+	if u.OnFailure != "" {
+		lines = append(lines, "OnFailure: "+u.OnFailure)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// MoleculeMarkdown renders a workflow plan in the molecule step format,
+// one "## Step:" section per unit with its needs and control lines.
+func (p *Plan) MoleculeMarkdown() string {
+	var sb strings.Builder
+	for _, u := range p.Units {
+		fmt.Fprintf(&sb, "## Step: %s\n%s\n", u.ID, u.Title)
+		if u.Description != "" {
+			fmt.Fprintf(&sb, "\n%s\n", u.Description)
+		}
+		if len(u.Needs) > 0 {
+			fmt.Fprintf(&sb, "Needs: %s\n", strings.Join(u.Needs, ", "))
+		}
+		if control := u.Control(); control != "" {
+			sb.WriteString(control + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,252 @@
+package formula
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const patrolFormula = `
+formula = "patrol"
+
+[[steps]]
+id = "inbox"
+title = "Check inbox"
+
+[[steps]]
+id = "scan"
+title = "Scan {{item}}"
+needs = ["inbox"]
+foreach = "rigs"
+outputs = ["stuck"]
+
+[[steps]]
+id = "nudge"
+title = "Nudge stuck polecats"
+needs = ["scan"]
+when = "steps.scan.stuck > 0"
+
+[[steps]]
+id = "deep"
+title = "Deep review"
+needs = ["inbox"]
+when = "mode == \"full\""
+
+[[steps]]
+id = "wait"
+title = "Wait for activity"
+needs = ["nudge", "deep"]
+until = "steps.wait.activity == \"yes\""
+max_iterations = 3
+outputs = ["activity"]
+
+[vars.rigs]
+default = "gastown, beads"
+
+[vars.mode]
+type = "enum"
+choices = ["quick", "full"]
+default = "quick"
+`
+
+func TestAdvanceWhen(t *testing.T) {
+	f, err := Parse([]byte(patrolFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	s := &RunState{Vars: map[string]string{"mode": "quick"}}
+	if got := f.Advance(s); !reflect.DeepEqual(got, []string{"inbox"}) {
+		t.Fatalf("Advance() = %v, want [inbox]", got)
+	}
+
+	// Completing inbox settles deep's condition: quick mode skips it
+	s.Done["inbox"] = true
+	if got := f.Advance(s); !reflect.DeepEqual(got, []string{"scan"}) {
+		t.Errorf("Advance() = %v, want [scan]", got)
+	}
+	if !s.Skipped["deep"] {
+		t.Error("deep should be skipped in quick mode")
+	}
+
+	// No stuck polecats: nudge is skipped too, which unblocks wait
+	s.Done["scan"] = true
+	s.Outputs = map[string]map[string]string{"scan": {"stuck": "0"}}
+	if got := f.Advance(s); !reflect.DeepEqual(got, []string{"wait"}) {
+		t.Errorf("Advance() = %v, want [wait]", got)
+	}
+	if v, _ := s.Lookup("steps.nudge.status"); v != StepSkipped {
+		t.Errorf("nudge status = %q, want skipped", v)
+	}
+	if got := s.ExpandOutputs("{{steps.scan.stuck}} stuck, {{ steps.wait.activity }}"); got != "0 stuck, {{ steps.wait.activity }}" {
+		t.Errorf("ExpandOutputs = %q", got)
+	}
+
+	// ReadySteps evaluates against an empty run: no vars, so deep is skipped
+	if got := f.ReadySteps(map[string]bool{"inbox": true}); !reflect.DeepEqual(got, []string{"scan"}) {
+		t.Errorf("ReadySteps() = %v, want [scan]", got)
+	}
+}
+
+func TestCompleteStepUntil(t *testing.T) {
+	f, err := Parse([]byte(patrolFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	s := &RunState{Outputs: map[string]map[string]string{"wait": {"activity": "no"}}}
+
+	if !f.CompleteStep(s, "wait") || !f.CompleteStep(s, "wait") {
+		t.Fatal("wait should repeat while activity is no")
+	}
+	if f.CompleteStep(s, "wait") {
+		t.Error("wait should stop at max_iterations = 3")
+	}
+	if !s.Done["wait"] || s.Iterations["wait"] != 3 {
+		t.Errorf("done = %v, iterations = %d", s.Done["wait"], s.Iterations["wait"])
+	}
+
+	s = &RunState{Outputs: map[string]map[string]string{"wait": {"activity": "yes"}}}
+	if f.CompleteStep(s, "wait") {
+		t.Error("wait should not repeat once its until condition holds")
+	}
+}
+
+func TestPlanControl(t *testing.T) {
+	f, err := Parse([]byte(patrolFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	p, err := f.Plan(PlanOptions{})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	var ids []string
+	for _, u := range p.Units {
+		ids = append(ids, u.ID)
+	}
+	// deep is decided at plan time (quick mode); nudge depends on scan output
+	want := []string{"inbox", "scan.1", "scan.2", "nudge", "wait"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("units = %v, want %v", ids, want)
+	}
+	if got := p.Unit("scan.2").Title; got != "Scan beads" {
+		t.Errorf("scan.2 title = %q", got)
+	}
+	if got := p.Unit("nudge").Needs; !reflect.DeepEqual(got, []string{"scan.1", "scan.2"}) {
+		t.Errorf("nudge needs = %v", got)
+	}
+	// wait inherits inbox from the dropped deep step
+	if got := p.Unit("wait").Needs; !reflect.DeepEqual(got, []string{"nudge", "inbox"}) {
+		t.Errorf("wait needs = %v", got)
+	}
+	if got := p.Unit("wait").Control(); got != "Until: steps.wait.activity == \"yes\"\nMaxIterations: 3\nOutputs: activity" {
+		t.Errorf("wait control = %q", got)
+	}
+
+	p, err = f.Plan(PlanOptions{Vars: map[string][]string{"mode": {"full"}}})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if p.Unit("deep") == nil {
+		t.Error("deep should be planned in full mode")
+	}
+}
+
+func TestPlanUnsetVar(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "f"
+
+[vars.deep]
+type = "bool"
+
+[vars.mode]
+default = "quick"
+
+[[steps]]
+id = "scan"
+outputs = ["stuck"]
+
+[[steps]]
+id = "deep"
+needs = ["scan"]
+when = "deep"
+
+[[steps]]
+id = "nudge"
+needs = ["scan"]
+when = "{{mode}} == \"quick\" && steps.scan.stuck > 0"
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	p, err := f.Plan(PlanOptions{})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if p.Unit("deep") != nil {
+		t.Error("deep planned although the deep var is unset")
+	}
+	// Variables are bound at plan time; the step reference waits for run time
+	if got := p.Unit("nudge").When; got != `"quick" == "quick" && steps.scan.stuck > 0` {
+		t.Errorf("nudge when = %q", got)
+	}
+
+	p, err = f.Plan(PlanOptions{Vars: map[string][]string{"deep": {"true"}}})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if p.Unit("deep") == nil {
+		t.Error("deep not planned with deep=true")
+	}
+}
+
+func TestValidateControl(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps string
+		want  string
+	}{
+		{"bad syntax", `when = "mode = 1"`, "unknown operator"},
+		{"undeclared var", `when = "level == 1"`, "undeclared variable: level"},
+		{"step not needed", `when = "steps.b.status == \"done\""`, "does not need"},
+		{"unknown foreach", `foreach = "targets"`, "undeclared variable: targets"},
+		{"max without until", `max_iterations = 2`, "without until"},
+		{"undeclared output", `until = "steps.a.done == \"yes\""`, "does not declare"},
+		{"reserved output", `outputs = ["status"]`, "invalid output name"},
+		{"max too large", "until = \"steps.a.iterations > 1\"\nmax_iterations = 1000", "between 1 and"},
+		{"unknown tier", `tier = "gpt"`, "unknown tier"},
+		{"bad timeout", `timeout = "soon"`, "invalid timeout"},
+		{"too many retries", `retries = 50`, "retries must be"},
+		{"bad policy", `on_failure = "retry"`, "invalid on_failure"},
+		{"goto self", `on_failure = "goto:a"`, "must name another step"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "formula = \"f\"\n[vars.mode]\n[[steps]]\nid = \"a\"\n" + tt.steps + "\n[[steps]]\nid = \"b\"\n"
+			_, err := Parse([]byte(src))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanTier(t *testing.T) {
+	src := "formula = \"f\"\n[[steps]]\nid = \"a\"\ntier = \"haiku\"\n[[steps]]\nid = \"b\"\nneeds = [\"a\"]\n"
+	f, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	p, err := f.Plan(PlanOptions{})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if got := p.Unit("a").Control(); got != "Tier: haiku" {
+		t.Errorf("a control = %q", got)
+	}
+	if got := p.Unit("b").Tier; got != "" {
+		t.Errorf("b tier = %q, want none", got)
+	}
+}
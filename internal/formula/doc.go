@@ -114,6 +114,133 @@
 //	ready := f.ReadySteps(completed)
 //	// Returns: ["build"] (test is done, build can run)
 //
+// # Control Flow
+//
+// Workflow steps may carry a when condition (skip the step when false), a
+// foreach list var (one step per item, as {{item}}), and an until
+// condition with max_iterations (repeat the step until true). Conditions
+// reference vars and prior steps' state (steps.<id>.status); see package
+// flow for the syntax. Advance and CompleteStep evaluate them against a
+// RunState, and Plan decides conditions on vars up front:
+//
+//	[[steps]]
+//	id = "deep-review"
+//	needs = ["scan"]
+//	when = "mode == \"full\" || steps.scan.status == \"skipped\""
+//
+// # Step Outputs
+//
+// A step may declare outputs, named values recorded when it completes
+// (gt mol step done --output key=value). Steps that need it reference them
+// as {{steps.<id>.<key>}} in titles and descriptions, or steps.<id>.<key>
+// in conditions; RunState.ExpandOutputs fills them in:
+//
+//	[[steps]]
+//	id = "design"
+//	outputs = ["approach"]
+//
+//	[[steps]]
+//	id = "implement"
+//	needs = ["design"]
+//	description = "Implement {{steps.design.approach}}."
+//
+// # Model Tiers
+//
+// A step's tier (haiku, sonnet, or opus) is a hint for which model should
+// run it. The tiers map in town or rig settings names the agent for each
+// tier; without one, claude is started with --model <tier>. gt sling
+// spawns the polecat for the first step's tier, and gt mol step done hands
+// off to a session with the next step's tier when it differs.
+//
+//	[[steps]]
+//	id = "triage"
+//	tier = "haiku"
+//
+// # Failure Handling
+//
+// A step may set a timeout, a number of retries, and an on_failure policy:
+// escalate (the default), skip, fail-molecule, or goto:<step>, which runs
+// that step and everything after it again. Agents report failure with
+// gt mol step done --failed; the witness fails steps past their timeout
+// with gt mol step overdue --fail.
+//
+//	[[steps]]
+//	id = "test"
+//	timeout = "30m"
+//	retries = 2
+//	on_failure = "goto:implement"
+//
+// # Variables
+//
+// Vars and inputs are typed: string (default), int, bool, enum, bead-id,
+// rig, path, or duration, optionally constrained by choices and an
+// anchored regex pattern. Declarations are checked at parse time, and
+// supplied values by CheckVars/ResolveVars, which reject undeclared names:
+//
+//	[vars.level]
+//	type = "enum"
+//	choices = ["patch", "minor", "major"]
+//	default = "patch"
+//
+// # Execution Plans
+//
+// Plan instantiates a formula with variables into units of work, in
+// dependency order. Workflow steps get {{var}} substitution, expansion
+// templates are instantiated once per value of their {target}, and convoy
+// legs and aspects fan in to a synthesis unit:
+//
+//	plan, err := f.Plan(formula.PlanOptions{
+//	    Vars: map[string][]string{"target": {"gt-abc", "gt-def"}},
+//	})
+//	for _, wave := range plan.Waves() {
+//	    // Dispatch each wave once the previous one completes...
+//	}
+//
+// # Testing Formulas
+//
+// Simulate runs a plan with an in-memory agent that skips steps whose
+// when condition is false, completes ready units in order, repeats until
+// steps and records outputs, following an optional script of outcomes.
+// ParseTestFile reads <name>.test.toml cases (vars, scripted outcomes and
+// expectations), and RunTest checks one against the formula:
+//
+//	tf, err := formula.ParseTestFile("patrol.test.toml")
+//	for _, c := range tf.Cases {
+//	    if res := f.RunTest(c, nil); !res.Passed {
+//	        // res.Failures describes each unmet expectation...
+//	    }
+//	}
+//
+// # Registry
+//
+// Formulas can be shared between towns by installing them from a git
+// repository or path. A Registry keeps every installed version under
+// .beads/formulas/.registry/<name>/v<N>.formula.toml, keyed by the
+// formula's version field, and records each version's source, commit and
+// sha256 in formulas.lock.json. Activate copies a version to where it is
+// found by name: the town's formulas directory, or a rig's to pin it:
+//
+//	reg, err := formula.OpenRegistry(formulasDir)
+//	src := formula.ParseSource("https://github.com/acme/formulas.git@v2")
+//	fetched, commit, err := formula.FetchSource(src)
+//	for _, ff := range fetched {
+//	    reg.Add(ff, src, commit, false)
+//	    reg.Activate(ff.Name, ff.Version, "", formulasDir, false)
+//	}
+//	err = reg.Save()
+//
+// # Graphs
+//
+// Graph returns the formula's dependency graph (package dag), which renders
+// as terminal ASCII, Graphviz DOT, Mermaid or SVG:
+//
+//	g, _ := f.Graph()
+//	out, _ := dag.Render(g, dag.FormatMermaid)
+//
+// gt formula show --format renders it; gt mol progress and gt convoy status
+// render instantiated molecules and convoys the same way, from bead
+// dependencies.
+//
 // # Embedded Formulas
 //
 // The package includes embedded formula files that can be provisioned
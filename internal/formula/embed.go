@@ -191,8 +191,13 @@ func CheckFormulaHealth(beadsPath string) (*HealthReport, error) {
 	}
 
 	report := &HealthReport{}
+	locked := lockedNames(formulasDir)
 
 	for filename, embeddedHash := range embedded {
+		// Installed from a registry source: the lockfile tracks it
+		if locked[filename] {
+			continue
+		}
 		status := FormulaStatus{
 			Name:         filename,
 			EmbeddedHash: embeddedHash,
@@ -267,7 +272,12 @@ func UpdateFormulas(beadsPath string) (updated, skipped, reinstalled int, err er
 		return 0, 0, 0, err
 	}
 
+	locked := lockedNames(formulasDir)
 	for filename, embeddedHash := range embedded {
+		// Never replace a version installed from a registry source
+		if locked[filename] {
+			continue
+		}
 		installedHash, wasInstalled := installed.Formulas[filename]
 		destPath := filepath.Join(formulasDir, filename)
 		currentHash, fileErr := computeFileHash(destPath)
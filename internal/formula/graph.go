@@ -0,0 +1,61 @@
+package formula
+
+import "github.com/steveyegge/gastown/internal/dag"
+
+// Graph returns the formula's dependency graph for rendering. Workflow steps
+// and expansion templates are added in TopologicalSort order; convoy legs and
+// aspects fan in to the synthesis, as in Plan.
+func (f *Formula) Graph() (*dag.Graph, error) {
+	g := dag.New(f.Name)
+
+	order, err := f.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+
+	switch f.Type {
+	case TypeWorkflow:
+		for _, id := range order {
+			g.AddNode(dag.Node{ID: id, Label: f.GetStep(id).Title})
+		}
+		for _, s := range f.Steps {
+			for _, need := range s.Needs {
+				g.AddEdge(need, s.ID)
+			}
+		}
+	case TypeExpansion:
+		for _, id := range order {
+			g.AddNode(dag.Node{ID: id, Label: f.GetTemplate(id).Title})
+		}
+		for _, t := range f.Template {
+			for _, need := range t.Needs {
+				g.AddEdge(need, t.ID)
+			}
+		}
+	case TypeConvoy, TypeAspect:
+		for _, id := range order {
+			label := ""
+			if leg := f.GetLeg(id); leg != nil {
+				label = leg.Title
+			} else if a := f.GetAspect(id); a != nil {
+				label = a.Title
+			}
+			g.AddNode(dag.Node{ID: id, Label: label})
+		}
+		if f.Synthesis != nil {
+			title := f.Synthesis.Title
+			if title == "" {
+				title = "Synthesis"
+			}
+			g.AddNode(dag.Node{ID: SynthesisID, Label: title})
+			needs := f.Synthesis.DependsOn
+			if len(needs) == 0 {
+				needs = order
+			}
+			for _, need := range needs {
+				g.AddEdge(need, SynthesisID)
+			}
+		}
+	}
+	return g, nil
+}
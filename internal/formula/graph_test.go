@@ -0,0 +1,73 @@
+package formula
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraph_Workflow(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "release"
+type = "workflow"
+
+[[steps]]
+id = "publish"
+title = "Publish"
+needs = ["build", "test"]
+
+[[steps]]
+id = "build"
+title = "Build"
+
+[[steps]]
+id = "test"
+title = "Test"
+needs = ["build"]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	g, err := f.Graph()
+	if err != nil {
+		t.Fatalf("Graph failed: %v", err)
+	}
+	want := [][]string{{"build"}, {"test"}, {"publish"}}
+	if got := g.Waves(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Waves() = %v, want %v", got, want)
+	}
+	if got := g.Needs("publish"); !reflect.DeepEqual(got, []string{"build", "test"}) {
+		t.Errorf("Needs(publish) = %v", got)
+	}
+	if n := g.Node("build"); n == nil || n.Label != "Build" {
+		t.Errorf("Node(build) = %+v", n)
+	}
+}
+
+func TestGraph_ConvoySynthesis(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "review"
+type = "convoy"
+
+[[legs]]
+id = "security"
+title = "Security"
+
+[[legs]]
+id = "style"
+title = "Style"
+
+[synthesis]
+title = "Combine"
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	g, err := f.Graph()
+	if err != nil {
+		t.Fatalf("Graph failed: %v", err)
+	}
+	want := [][]string{{"security", "style"}, {SynthesisID}}
+	if got := g.Waves(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Waves() = %v, want %v", got, want)
+	}
+}
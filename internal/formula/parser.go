@@ -62,6 +62,13 @@ func (f *Formula) Validate() error {
 		return fmt.Errorf("invalid formula type %q (must be convoy, workflow, expansion, or aspect)", f.Type)
 	}
 
+	if err := f.validateVars(); err != nil {
+		return err
+	}
+	if err := f.validatePlaceholders(); err != nil {
+		return err
+	}
+
 	// Type-specific validation
 	switch f.Type {
 	case TypeConvoy:
@@ -137,7 +144,7 @@ func (f *Formula) validateWorkflow() error {
 		return err
 	}
 
-	return nil
+	return f.validateControl()
 }
 
 func (f *Formula) validateExpansion() error {
@@ -324,26 +331,20 @@ func (f *Formula) TopologicalSort() ([]string, error) {
 
 // ReadySteps returns steps that have no unmet dependencies.
 // completed is a set of step IDs that have been completed.
+//
+// Workflow steps whose when condition is false are skipped rather than
+// returned, and count as completed for their dependents. Use Advance to
+// evaluate conditions against vars and step outputs.
 func (f *Formula) ReadySteps(completed map[string]bool) []string {
 	var ready []string
 
 	switch f.Type {
 	case TypeWorkflow:
-		for _, step := range f.Steps {
-			if completed[step.ID] {
-				continue
-			}
-			allMet := true
-			for _, need := range step.Needs {
-				if !completed[need] {
-					allMet = false
-					break
-				}
-			}
-			if allMet {
-				ready = append(ready, step.ID)
-			}
+		state := &RunState{Done: make(map[string]bool, len(completed))}
+		for id, done := range completed {
+			state.Done[id] = done
 		}
+		ready = f.Advance(state)
 	case TypeExpansion:
 		for _, tmpl := range f.Template {
 			if completed[tmpl.ID] {
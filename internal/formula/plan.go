@@ -0,0 +1,397 @@
+package formula
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Unit kinds in an execution plan.
+const (
+	UnitStep      = "step"      // workflow step
+	UnitTemplate  = "template"  // instantiated expansion template
+	UnitLeg       = "leg"       // convoy leg
+	UnitAspect    = "aspect"    // aspect analysis
+	UnitSynthesis = "synthesis" // combines legs or aspects
+)
+
+// SynthesisID is the unit ID of a convoy or aspect synthesis.
+const SynthesisID = "synthesis"
+
+// Unit is one dispatchable piece of work in an execution plan.
+type Unit struct {
+	ID          string   `json:"id"`
+	Kind        string   `json:"kind"`
+	Step        string   `json:"step,omitempty"` // workflow step it came from (differs from ID for foreach)
+	Title       string   `json:"title"`
+	Focus       string   `json:"focus,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Needs       []string `json:"needs,omitempty"`
+
+	// Runtime control flow, for workflow steps whose conditions depend on
+	// other steps (conditions on vars are decided at plan time).
+	When          string `json:"when,omitempty"`
+	Until         string `json:"until,omitempty"`
+	MaxIterations int    `json:"max_iterations,omitempty"`
+
+	// Outputs the unit records for later units ({{steps.<id>.<key>}}).
+	Outputs []string `json:"outputs,omitempty"`
+
+	// Tier is the model tier hint for the unit's agent.
+	Tier string `json:"tier,omitempty"`
+
+	// Failure handling: how long the unit may run, how often it is retried,
+	// and what happens when it still fails.
+	Timeout   string `json:"timeout,omitempty"`
+	Retries   int    `json:"retries,omitempty"`
+	OnFailure string `json:"on_failure,omitempty"`
+}
+
+// Plan is a formula instantiated with variables: every unit of work with
+// its dependencies, in dependency order.
+type Plan struct {
+	Formula string            `json:"formula"`
+	Type    FormulaType       `json:"type"`
+	Vars    map[string]string `json:"vars,omitempty"`
+	Units   []Unit            `json:"units"`
+}
+
+// PlanOptions control formula instantiation.
+type PlanOptions struct {
+	// Vars are the values supplied for vars and inputs. A key given more
+	// than once instantiates expansion templates once per value.
+	Vars map[string][]string
+
+	// Check verifies values against outside state, e.g. that a bead-id
+	// var names an existing bead. Optional.
+	Check ValueCheck
+
+	// Describe looks up the title and description of an expansion target
+	// (usually a bead ID) for {target.title} and {target.description}.
+	// Optional; the value itself is used when nil or empty.
+	Describe func(value string) (title, description string)
+}
+
+// Plan instantiates the formula into units of work.
+func (f *Formula) Plan(opts PlanOptions) (*Plan, error) {
+	vars, err := f.ResolveVars(opts.Vars, opts.Check)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{Formula: f.Name, Type: f.Type, Vars: vars}
+
+	switch f.Type {
+	case TypeWorkflow:
+		units, err := f.planWorkflow(vars)
+		if err != nil {
+			return nil, err
+		}
+		p.Units = units
+	case TypeExpansion:
+		units, err := f.expand(opts)
+		if err != nil {
+			return nil, err
+		}
+		p.Units = units
+		// The target is instantiated over, not a single value
+		if target, _ := f.expansionTarget(); target != "" {
+			delete(p.Vars, target)
+		}
+	case TypeConvoy:
+		base := f.Prompts["base"]
+		for _, leg := range f.Legs {
+			desc := SubstituteVars(leg.Description, vars)
+			if base != "" {
+				desc = fmt.Sprintf("%s\n\n---\nBase Prompt:\n%s", desc, base)
+			}
+			p.Units = append(p.Units, Unit{
+				ID:          leg.ID,
+				Kind:        UnitLeg,
+				Title:       SubstituteVars(leg.Title, vars),
+				Focus:       leg.Focus,
+				Description: desc,
+			})
+		}
+		p.addSynthesis(f.Synthesis, vars)
+	case TypeAspect:
+		for _, a := range f.Aspects {
+			p.Units = append(p.Units, Unit{
+				ID:          a.ID,
+				Kind:        UnitAspect,
+				Title:       SubstituteVars(a.Title, vars),
+				Focus:       a.Focus,
+				Description: SubstituteVars(a.Description, vars),
+			})
+		}
+		p.addSynthesis(f.Synthesis, vars)
+	default:
+		return nil, fmt.Errorf("unsupported formula type %q", f.Type)
+	}
+	if err := p.checkPlaceholders(f); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// checkPlaceholders fails if a declared var is still a {{name}} placeholder
+// in some unit's text: it was used but has no value or default. Steps
+// dropped by a false when condition don't count.
+func (p *Plan) checkPlaceholders(f *Formula) error {
+	var unset []string
+	for _, u := range p.Units {
+		for _, text := range []string{u.Title, u.Description} {
+			for _, m := range varPattern.FindAllStringSubmatch(text, -1) {
+				if f.VarSpec(m[1]) != nil {
+					unset = append(unset, m[1])
+				}
+			}
+		}
+	}
+	if len(unset) == 0 {
+		return nil
+	}
+	sort.Strings(unset)
+	return fmt.Errorf("variable(s) used without a value or default: %s", strings.Join(slices.Compact(unset), ", "))
+}
+
+// addSynthesis appends the synthesis unit, depending on the listed units
+// or on every unit when depends_on is empty.
+func (p *Plan) addSynthesis(s *Synthesis, vars map[string]string) {
+	if s == nil {
+		return
+	}
+	needs := s.DependsOn
+	if len(needs) == 0 {
+		for _, u := range p.Units {
+			needs = append(needs, u.ID)
+		}
+	}
+	desc := SubstituteVars(s.Description, vars)
+	if desc == "" {
+		desc = "Synthesize findings from all legs into unified output"
+	}
+	title := SubstituteVars(s.Title, vars)
+	if title == "" {
+		title = "Synthesis"
+	}
+	p.Units = append(p.Units, Unit{
+		ID:          SynthesisID,
+		Kind:        UnitSynthesis,
+		Title:       title,
+		Description: desc,
+		Needs:       needs,
+	})
+}
+
+// Unit returns the unit with the given ID, or nil.
+func (p *Plan) Unit(id string) *Unit {
+	for i := range p.Units {
+		if p.Units[i].ID == id {
+			return &p.Units[i]
+		}
+	}
+	return nil
+}
+
+// Ready returns the IDs of units with no dependencies, which can be
+// dispatched immediately.
+func (p *Plan) Ready() []string {
+	var ready []string
+	for _, u := range p.Units {
+		if len(u.Needs) == 0 {
+			ready = append(ready, u.ID)
+		}
+	}
+	return ready
+}
+
+// Waves groups unit IDs into dispatch waves: each wave only depends on
+// units in earlier waves.
+func (p *Plan) Waves() [][]string {
+	level := make(map[string]int)
+	var waves [][]string
+	// Units are in dependency order, so needs are always leveled first
+	for _, u := range p.Units {
+		l := 0
+		for _, need := range u.Needs {
+			if level[need]+1 > l {
+				l = level[need] + 1
+			}
+		}
+		level[u.ID] = l
+		for len(waves) <= l {
+			waves = append(waves, nil)
+		}
+		waves[l] = append(waves[l], u.ID)
+	}
+	return waves
+}
+
+// ResolveVars validates the supplied values (see CheckVars), applies
+// defaults, and checks that required vars and inputs are set.
+// Multi-valued keys resolve to their last value.
+func (f *Formula) ResolveVars(supplied map[string][]string, check ValueCheck) (map[string]string, error) {
+	if err := f.CheckVars(supplied, check); err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+	for k, vals := range supplied {
+		if len(vals) > 0 {
+			vars[k] = vals[len(vals)-1]
+		}
+	}
+
+	var missing []string
+	for name, v := range f.Vars {
+		if _, ok := vars[name]; ok {
+			continue
+		}
+		if v.Default != "" {
+			vars[name] = v.Default
+		} else if v.Required {
+			missing = append(missing, name)
+		}
+	}
+	for name, in := range f.Inputs {
+		if _, ok := vars[name]; ok {
+			continue
+		}
+		if in.Default != "" {
+			vars[name] = in.Default
+			continue
+		}
+		if in.Required {
+			missing = append(missing, name)
+			continue
+		}
+		if len(in.RequiredUnless) > 0 && !anySupplied(supplied, in.RequiredUnless) {
+			// Inputs that require each other report once, as a group
+			group := append([]string{name}, in.RequiredUnless...)
+			sort.Strings(group)
+			missing = append(missing, "one of "+strings.Join(group, "/"))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		missing = slices.Compact(missing)
+		return nil, fmt.Errorf("missing required variable(s): %s", strings.Join(missing, ", "))
+	}
+	return vars, nil
+}
+
+func anySupplied(supplied map[string][]string, names []string) bool {
+	for _, n := range names {
+		if len(supplied[n]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// varPattern matches {{name}} workflow placeholders.
+var varPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// SubstituteVars replaces {{name}} placeholders with their values. Unknown
+// placeholders are left as-is (Validate rejects undeclared ones, and Plan
+// declared ones with no value).
+func SubstituteVars(s string, vars map[string]string) string {
+	return varPattern.ReplaceAllStringFunc(s, func(m string) string {
+		name := varPattern.FindStringSubmatch(m)[1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// targetPattern matches {name} and {name.field} expansion placeholders.
+var targetPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(?:\.(title|description))?\}`)
+
+// expansionTarget returns the input an expansion instantiates over: the
+// placeholder used in its template IDs.
+func (f *Formula) expansionTarget() (string, error) {
+	names := make(map[string]bool)
+	for _, t := range f.Template {
+		for _, m := range targetPattern.FindAllStringSubmatch(t.ID, -1) {
+			names[m[1]] = true
+		}
+	}
+	switch len(names) {
+	case 0:
+		return "", nil
+	case 1:
+		for n := range names {
+			return n, nil
+		}
+	}
+	var list []string
+	for n := range names {
+		list = append(list, n)
+	}
+	sort.Strings(list)
+	return "", fmt.Errorf("expansion template IDs use more than one input: %s", strings.Join(list, ", "))
+}
+
+// expand instantiates the expansion templates once per value of their
+// target input, in dependency order.
+func (f *Formula) expand(opts PlanOptions) ([]Unit, error) {
+	target, err := f.expansionTarget()
+	if err != nil {
+		return nil, err
+	}
+	values := []string{""}
+	if target != "" {
+		values = opts.Vars[target]
+		if len(values) == 0 {
+			return nil, fmt.Errorf("missing required variable(s): %s (expansion target)", target)
+		}
+	}
+	order, err := f.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+
+	var units []Unit
+	seen := make(map[string]bool)
+	for _, value := range values {
+		fields := map[string]string{"": value, "title": value, "description": value}
+		if opts.Describe != nil && value != "" {
+			if title, desc := opts.Describe(value); title != "" {
+				fields["title"] = title
+				if desc != "" {
+					fields["description"] = desc
+				}
+			}
+		}
+		sub := func(s string) string {
+			return targetPattern.ReplaceAllStringFunc(s, func(m string) string {
+				parts := targetPattern.FindStringSubmatch(m)
+				if parts[1] != target {
+					return m
+				}
+				return fields[parts[2]]
+			})
+		}
+		for _, id := range order {
+			t := f.GetTemplate(id)
+			u := Unit{
+				ID:          sub(t.ID),
+				Kind:        UnitTemplate,
+				Title:       sub(t.Title),
+				Description: sub(t.Description),
+			}
+			for _, need := range t.Needs {
+				u.Needs = append(u.Needs, sub(need))
+			}
+			if seen[u.ID] {
+				return nil, fmt.Errorf("expansion produced duplicate unit %q (is %s given twice?)", u.ID, target)
+			}
+			seen[u.ID] = true
+			units = append(units, u)
+		}
+	}
+	return units, nil
+}
@@ -0,0 +1,194 @@
+package formula
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlan_Workflow(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "release"
+type = "workflow"
+
+[[steps]]
+id = "publish"
+title = "Publish {{version}}"
+needs = ["build", "test"]
+
+[[steps]]
+id = "build"
+title = "Build {{version}}"
+
+[[steps]]
+id = "test"
+title = "Test"
+
+[vars.version]
+required = true
+
+[vars.channel]
+default = "stable"
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if _, err := f.Plan(PlanOptions{}); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Fatalf("expected missing version error, got %v", err)
+	}
+
+	p, err := f.Plan(PlanOptions{Vars: map[string][]string{"version": {"1.2.0"}}})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if p.Vars["channel"] != "stable" {
+		t.Errorf("default var channel = %q, want stable", p.Vars["channel"])
+	}
+	if got := p.Unit("publish").Title; got != "Publish 1.2.0" {
+		t.Errorf("publish title = %q", got)
+	}
+	if got := p.Ready(); !reflect.DeepEqual(got, []string{"build", "test"}) {
+		t.Errorf("Ready() = %v, want [build test]", got)
+	}
+	want := [][]string{{"build", "test"}, {"publish"}}
+	if got := p.Waves(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Waves() = %v, want %v", got, want)
+	}
+}
+
+func TestPlan_Expansion(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "rule-of-two"
+type = "expansion"
+
+[[template]]
+id = "{target}.draft"
+title = "Draft: {target.title}"
+description = "Draft {target.description}"
+
+[[template]]
+id = "{target}.refine"
+title = "Refine {target}"
+needs = ["{target}.draft"]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	describe := func(v string) (string, string) {
+		if v == "gt-1" {
+			return "Login page", "the login page"
+		}
+		return "", ""
+	}
+	p, err := f.Plan(PlanOptions{
+		Vars:     map[string][]string{"target": {"gt-1", "gt-2"}},
+		Describe: describe,
+	})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	var ids []string
+	for _, u := range p.Units {
+		ids = append(ids, u.ID)
+	}
+	want := []string{"gt-1.draft", "gt-1.refine", "gt-2.draft", "gt-2.refine"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("unit IDs = %v, want %v", ids, want)
+	}
+	if u := p.Unit("gt-1.draft"); u.Title != "Draft: Login page" || u.Description != "Draft the login page" {
+		t.Errorf("gt-1.draft = %q / %q", u.Title, u.Description)
+	}
+	if u := p.Unit("gt-2.draft"); u.Title != "Draft: gt-2" {
+		t.Errorf("gt-2.draft title = %q, want value fallback", u.Title)
+	}
+	if u := p.Unit("gt-2.refine"); !reflect.DeepEqual(u.Needs, []string{"gt-2.draft"}) {
+		t.Errorf("gt-2.refine needs = %v", u.Needs)
+	}
+
+	if _, err := f.Plan(PlanOptions{}); err == nil {
+		t.Error("expected error without a target")
+	}
+	if _, err := f.Plan(PlanOptions{Vars: map[string][]string{"target": {"a", "a"}}}); err == nil {
+		t.Error("expected duplicate unit error")
+	}
+}
+
+func TestPlan_ConvoyAndAspect(t *testing.T) {
+	convoy, err := Parse([]byte(`
+formula = "review"
+type = "convoy"
+
+[inputs.pr]
+required_unless = ["branch"]
+
+[inputs.branch]
+required_unless = ["pr"]
+
+[prompts]
+base = "Review carefully."
+
+[[legs]]
+id = "a"
+title = "A"
+
+[[legs]]
+id = "b"
+title = "B"
+
+[synthesis]
+title = "Combine"
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := convoy.Plan(PlanOptions{}); err == nil {
+		t.Error("expected required_unless error")
+	}
+	p, err := convoy.Plan(PlanOptions{Vars: map[string][]string{"pr": {"12"}}})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	syn := p.Unit(SynthesisID)
+	if syn == nil || !reflect.DeepEqual(syn.Needs, []string{"a", "b"}) {
+		t.Fatalf("synthesis = %+v, want needs [a b]", syn)
+	}
+	if !strings.Contains(p.Unit("a").Description, "Review carefully.") {
+		t.Errorf("leg description lacks base prompt: %q", p.Unit("a").Description)
+	}
+
+	aspect, err := Parse([]byte(`
+formula = "audit"
+type = "aspect"
+
+[[aspects]]
+id = "auth"
+title = "Auth"
+
+[[aspects]]
+id = "data"
+title = "Data"
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	p, err = aspect.Plan(PlanOptions{})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if got := p.Ready(); !reflect.DeepEqual(got, []string{"auth", "data"}) {
+		t.Errorf("Ready() = %v", got)
+	}
+	if p.Unit(SynthesisID) != nil {
+		t.Error("aspect formula without [synthesis] should have no synthesis unit")
+	}
+}
+
+func TestSubstituteVars(t *testing.T) {
+	got := SubstituteVars("fix {{issue}} in {{ rig }} ({{unknown}})", map[string]string{"issue": "gt-1", "rig": "gastown"})
+	if want := "fix gt-1 in gastown ({{unknown}})"; got != want {
+		t.Errorf("SubstituteVars = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,446 @@
+package formula
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// LockfileName is the registry lockfile in a town's .beads/formulas/.
+const LockfileName = "formulas.lock.json"
+
+// registryDir holds every installed version, as <name>/v<N>.formula.toml.
+const registryDir = ".registry"
+
+// namePattern matches formula names that are safe to use as file names.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// ValidateName checks that a formula name can name files in the registry:
+// letters, digits, '.', '_' and '-' only, and no "..". Names come from
+// fetched formula files, so this keeps them from escaping the formulas
+// directory.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid formula name %q: use letters, digits, '.', '_' and '-' only", name)
+	}
+	return nil
+}
+
+// Lockfile records the formulas installed from a registry source: every
+// version with where it came from and its content hash, which version the
+// town uses, and which rigs are pinned to another version.
+type Lockfile struct {
+	Formulas map[string]*LockEntry `json:"formulas"` // by formula name
+}
+
+// LockEntry is one formula in the lockfile.
+type LockEntry struct {
+	Default  int                    `json:"default"`        // version the town uses
+	Versions map[int]*LockedVersion `json:"versions"`       // installed versions
+	Rigs     map[string]int         `json:"rigs,omitempty"` // rig -> pinned version
+}
+
+// LockedVersion is one installed version of a formula.
+type LockedVersion struct {
+	Source      string    `json:"source"`           // git URL or path, as given to install
+	Ref         string    `json:"ref,omitempty"`    // requested git ref (tag, branch or commit)
+	Commit      string    `json:"commit,omitempty"` // commit the ref resolved to
+	Path        string    `json:"path"`             // file within the source
+	SHA256      string    `json:"sha256"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Source is a parsed install source: <git-url|path>[@version].
+type Source struct {
+	Location string // git URL or local path
+	Ref      string // git ref for git sources; required formula version for paths
+	Git      bool
+}
+
+// ParseSource parses an install source. A trailing @<ref> is split off
+// unless it is the user part of an SSH URL (git@host:org/repo).
+func ParseSource(spec string) Source {
+	src := Source{Location: spec}
+	if i := strings.LastIndex(spec, "@"); i > 0 && !strings.ContainsAny(spec[i+1:], "/:") {
+		src.Location, src.Ref = spec[:i], spec[i+1:]
+	}
+	loc := src.Location
+	src.Git = strings.Contains(loc, "://") || strings.HasPrefix(loc, "git@") || strings.HasSuffix(loc, ".git")
+	return src
+}
+
+func (s Source) String() string {
+	if s.Ref == "" {
+		return s.Location
+	}
+	return s.Location + "@" + s.Ref
+}
+
+// FetchedFormula is a formula file read from a source.
+type FetchedFormula struct {
+	Name    string
+	Version int
+	Path    string // relative to the source root
+	Content []byte
+	SHA256  string
+}
+
+// FetchSource reads the formulas in a source. A git source is cloned to a
+// temporary directory and checked out at its ref; the commit is returned.
+// A path may be a formula file or a directory. Directories are searched
+// at the top level and in formulas/ and .beads/formulas/.
+func FetchSource(src Source) ([]FetchedFormula, string, error) {
+	root := src.Location
+	var commit string
+	if src.Git {
+		tmp, err := os.MkdirTemp("", "gt-formula-*")
+		if err != nil {
+			return nil, "", err
+		}
+		defer os.RemoveAll(tmp)
+		root = filepath.Join(tmp, "src")
+		if err := git.NewGit("").Clone(src.Location, root); err != nil {
+			return nil, "", fmt.Errorf("cloning %s: %w", src.Location, err)
+		}
+		g := git.NewGit(root)
+		if src.Ref != "" {
+			if err := g.Checkout(src.Ref); err != nil {
+				return nil, "", fmt.Errorf("checking out %s: %w", src.Ref, err)
+			}
+		}
+		if commit, err = g.Rev("HEAD"); err != nil {
+			return nil, "", err
+		}
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, "", err
+	}
+	var paths []string
+	if info.IsDir() {
+		for _, dir := range []string{".", "formulas", filepath.Join(".beads", "formulas")} {
+			matches, _ := filepath.Glob(filepath.Join(root, dir, "*.formula.toml"))
+			paths = append(paths, matches...)
+		}
+	} else {
+		paths = []string{root}
+		root = filepath.Dir(root)
+	}
+
+	var fetched []FetchedFormula
+	seen := make(map[string]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is inside the source being installed
+		if err != nil {
+			return nil, "", err
+		}
+		f, err := Parse(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", path, err)
+		}
+		rel, _ := filepath.Rel(root, path)
+		if err := ValidateName(f.Name); err != nil {
+			return nil, "", fmt.Errorf("%s: %w", rel, err)
+		}
+		if prev, ok := seen[f.Name]; ok {
+			return nil, "", fmt.Errorf("formula %s is defined twice in %s: %s and %s", f.Name, src.Location, prev, rel)
+		}
+		seen[f.Name] = rel
+		if !src.Git && src.Ref != "" && strconv.Itoa(f.Version) != strings.TrimPrefix(src.Ref, "v") {
+			return nil, "", fmt.Errorf("%s is version %d, not %s", rel, f.Version, src.Ref)
+		}
+		fetched = append(fetched, FetchedFormula{
+			Name:    f.Name,
+			Version: f.Version,
+			Path:    filepath.ToSlash(rel),
+			Content: data,
+			SHA256:  computeHash(data),
+		})
+	}
+	if len(fetched) == 0 {
+		return nil, "", fmt.Errorf("no *.formula.toml files found in %s", src.Location)
+	}
+	return fetched, commit, nil
+}
+
+// Registry is a town's installed formula versions and lockfile.
+type Registry struct {
+	Dir  string // the town's .beads/formulas
+	Lock *Lockfile
+}
+
+// OpenRegistry loads the registry in a town's formulas directory.
+func OpenRegistry(formulasDir string) (*Registry, error) {
+	r := &Registry{Dir: formulasDir, Lock: &Lockfile{Formulas: make(map[string]*LockEntry)}}
+	data, err := os.ReadFile(filepath.Join(formulasDir, LockfileName))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading lockfile: %w", err)
+	}
+	if err := json.Unmarshal(data, r.Lock); err != nil {
+		return nil, fmt.Errorf("parsing lockfile: %w", err)
+	}
+	if r.Lock.Formulas == nil {
+		r.Lock.Formulas = make(map[string]*LockEntry)
+	}
+	return r, nil
+}
+
+// Save writes the lockfile.
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(filepath.Join(r.Dir, LockfileName), r.Lock)
+}
+
+// Names returns the locked formulas, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.Lock.Formulas))
+	for name := range r.Lock.Formulas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StorePath returns where a version of a formula is kept.
+func (r *Registry) StorePath(name string, version int) string {
+	return filepath.Join(r.Dir, registryDir, name, fmt.Sprintf("v%d.formula.toml", version))
+}
+
+// Add stores a fetched formula version and records it in the lockfile.
+// It reports false if that version is already installed with the same
+// content. A version installed with different content is an error unless
+// force is set: versions are immutable, so changes need a version bump.
+func (r *Registry) Add(ff FetchedFormula, src Source, commit string, force bool) (bool, error) {
+	if err := ValidateName(ff.Name); err != nil {
+		return false, err
+	}
+	entry := r.Lock.Formulas[ff.Name]
+	if entry == nil {
+		entry = &LockEntry{Default: ff.Version, Versions: make(map[int]*LockedVersion)}
+		r.Lock.Formulas[ff.Name] = entry
+	}
+	if prev := entry.Versions[ff.Version]; prev != nil {
+		if prev.SHA256 == ff.SHA256 {
+			return false, nil
+		}
+		if !force {
+			return false, fmt.Errorf("%s version %d is already installed from %s with different content; bump its version (or use --force)", ff.Name, ff.Version, prev.Source)
+		}
+	}
+
+	path := r.StorePath(ff.Name, ff.Version)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	if err := util.AtomicWriteFile(path, ff.Content, 0644); err != nil {
+		return false, err
+	}
+	locked := &LockedVersion{
+		Source:      src.Location,
+		Commit:      commit,
+		Path:        ff.Path,
+		SHA256:      ff.SHA256,
+		InstalledAt: time.Now().UTC(),
+	}
+	if src.Git {
+		locked.Ref = src.Ref
+	} else if abs, err := filepath.Abs(src.Location); err == nil {
+		// Record where update can find it again
+		locked.Source = abs
+	}
+	entry.Versions[ff.Version] = locked
+	return true, nil
+}
+
+// Versions returns a formula's installed versions, ascending.
+func (r *Registry) Versions(name string) []int {
+	entry := r.Lock.Formulas[name]
+	if entry == nil {
+		return nil
+	}
+	versions := make([]int, 0, len(entry.Versions))
+	for v := range entry.Versions {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Resolve returns the version a rig uses: its pin, or the town default.
+// Pass rig "" for the town.
+func (r *Registry) Resolve(name, rig string) (int, bool) {
+	entry := r.Lock.Formulas[name]
+	if entry == nil {
+		return 0, false
+	}
+	if v, ok := entry.Rigs[rig]; ok && rig != "" {
+		return v, true
+	}
+	return entry.Default, true
+}
+
+// Activate makes a version the one found by name in dir: the town's
+// formulas directory for rig "", or a rig's, which pins the rig to it. A
+// file already there is only replaced if it is an unmodified copy of an
+// installed version, unless force is set.
+func (r *Registry) Activate(name string, version int, rig, dir string, force bool) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	entry := r.Lock.Formulas[name]
+	if entry == nil || entry.Versions[version] == nil {
+		return fmt.Errorf("%s version %d is not installed (installed: %s)", name, version, formatVersions(r.Versions(name)))
+	}
+	content, err := os.ReadFile(r.StorePath(name, version))
+	if err != nil {
+		return fmt.Errorf("reading %s version %d: %w", name, version, err)
+	}
+	if computeHash(content) != entry.Versions[version].SHA256 {
+		return fmt.Errorf("%s version %d does not match its lockfile hash; reinstall it", name, version)
+	}
+
+	dest := filepath.Join(dir, name+".formula.toml")
+	if !force {
+		if err := r.checkManaged(name, dest); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := util.AtomicWriteFile(dest, content, 0644); err != nil {
+		return err
+	}
+
+	if rig == "" {
+		entry.Default = version
+		return nil
+	}
+	if entry.Rigs == nil {
+		entry.Rigs = make(map[string]int)
+	}
+	entry.Rigs[rig] = version
+	return nil
+}
+
+// Unpin returns a rig to the town's version, removing its copy from dir.
+func (r *Registry) Unpin(name, rig, dir string, force bool) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	entry := r.Lock.Formulas[name]
+	if entry == nil || !hasRig(entry, rig) {
+		return fmt.Errorf("rig %s is not pinned to a version of %s", rig, name)
+	}
+	dest := filepath.Join(dir, name+".formula.toml")
+	if !force {
+		if err := r.checkManaged(name, dest); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(entry.Rigs, rig)
+	return nil
+}
+
+func hasRig(entry *LockEntry, rig string) bool {
+	_, ok := entry.Rigs[rig]
+	return ok
+}
+
+// Status reports whether the file at dir matches the locked version the
+// rig should use: "ok", "modified" (edited or replaced since install),
+// "missing", or "" if the formula isn't locked.
+func (r *Registry) Status(name, rig, dir string) string {
+	version, ok := r.Resolve(name, rig)
+	if !ok {
+		return ""
+	}
+	hash, err := computeFileHash(filepath.Join(dir, name+".formula.toml"))
+	switch {
+	case os.IsNotExist(err):
+		return "missing"
+	case err != nil || hash != r.Lock.Formulas[name].Versions[version].SHA256:
+		return "modified"
+	}
+	return "ok"
+}
+
+// checkManaged refuses to overwrite a formula file that isn't a copy of
+// an installed version: a local formula, or a locked one edited in place.
+func (r *Registry) checkManaged(name, path string) error {
+	hash, err := computeFileHash(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if entry := r.Lock.Formulas[name]; entry != nil {
+		for _, v := range entry.Versions {
+			if v.SHA256 == hash {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s has local changes not in the lockfile; move it aside (or use --force)", path)
+}
+
+// FindVersion returns the stored file for name@<version> (e.g.
+// mol-polecat-work@2 or @v2) in a town's formulas directory.
+func FindVersion(formulasDir, spec string) (string, bool) {
+	i := strings.LastIndex(spec, "@")
+	if i <= 0 {
+		return "", false
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(spec[i+1:], "v"))
+	if err != nil || ValidateName(spec[:i]) != nil {
+		return "", false
+	}
+	r := &Registry{Dir: formulasDir}
+	path := r.StorePath(spec[:i], version)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// lockedNames returns the formula filenames managed by the lockfile in a
+// formulas directory.
+func lockedNames(formulasDir string) map[string]bool {
+	names := make(map[string]bool)
+	if r, err := OpenRegistry(formulasDir); err == nil {
+		for name := range r.Lock.Formulas {
+			names[name+".formula.toml"] = true
+		}
+	}
+	return names
+}
+
+func formatVersions(versions []int) string {
+	if len(versions) == 0 {
+		return "none"
+	}
+	s := make([]string, len(versions))
+	for i, v := range versions {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,198 @@
+package formula
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeSharedFormula(t *testing.T, dir string, version int, title string) string {
+	t.Helper()
+	path := filepath.Join(dir, "shared-work.formula.toml")
+	src := "formula = \"shared-work\"\nversion = " + strconv.Itoa(version) +
+		"\n[[steps]]\nid = \"a\"\ntitle = \"" + title + "\"\n"
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Source
+	}{
+		{"https://github.com/acme/formulas.git@v2", Source{"https://github.com/acme/formulas.git", "v2", true}},
+		{"git@github.com:acme/formulas.git", Source{"git@github.com:acme/formulas.git", "", true}},
+		{"git@github.com:acme/formulas.git@main", Source{"git@github.com:acme/formulas.git", "main", true}},
+		{"../shared/work.formula.toml@3", Source{"../shared/work.formula.toml", "3", false}},
+		{"./formulas", Source{"./formulas", "", false}},
+	}
+	for _, tt := range tests {
+		if got := ParseSource(tt.spec); got != tt.want {
+			t.Errorf("ParseSource(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryInstallAndPin(t *testing.T) {
+	src := t.TempDir()
+	town := filepath.Join(t.TempDir(), "formulas")
+	rigDir := filepath.Join(t.TempDir(), "formulas")
+
+	install := func(spec string, rig, dir string) error {
+		s := ParseSource(spec)
+		fetched, commit, err := FetchSource(s)
+		if err != nil {
+			return err
+		}
+		reg, err := OpenRegistry(town)
+		if err != nil {
+			return err
+		}
+		for _, ff := range fetched {
+			if _, err := reg.Add(ff, s, commit, false); err != nil {
+				return err
+			}
+			if err := reg.Activate(ff.Name, ff.Version, rig, dir, false); err != nil {
+				return err
+			}
+		}
+		return reg.Save()
+	}
+
+	path := writeSharedFormula(t, src, 1, "first")
+	if err := install(path+"@2", "", town); err == nil || !strings.Contains(err.Error(), "version 1, not 2") {
+		t.Errorf("install with wrong version: err = %v", err)
+	}
+	if err := install(src, "", town); err != nil {
+		t.Fatalf("install v1: %v", err)
+	}
+
+	// Changing content without bumping the version is refused
+	writeSharedFormula(t, src, 1, "edited")
+	if err := install(src, "", town); err == nil || !strings.Contains(err.Error(), "bump its version") {
+		t.Errorf("reinstall changed v1: err = %v", err)
+	}
+
+	// v2 for one rig only; the town stays on v1
+	writeSharedFormula(t, src, 2, "second")
+	if err := install(src, "beads", rigDir); err != nil {
+		t.Fatalf("install v2 for rig: %v", err)
+	}
+	reg, err := OpenRegistry(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := reg.Resolve("shared-work", ""); v != 1 {
+		t.Errorf("town version = %d, want 1", v)
+	}
+	if v, _ := reg.Resolve("shared-work", "beads"); v != 2 {
+		t.Errorf("beads version = %d, want 2", v)
+	}
+	f, err := ParseFile(filepath.Join(rigDir, "shared-work.formula.toml"))
+	if err != nil || f.Steps[0].Title != "second" {
+		t.Errorf("rig copy = %v, %v; want v2", f, err)
+	}
+	if got, ok := FindVersion(town, "shared-work@v1"); !ok || !strings.HasSuffix(got, "v1.formula.toml") {
+		t.Errorf("FindVersion = %q, %v", got, ok)
+	}
+
+	// A locally edited copy is reported and not overwritten
+	if err := os.WriteFile(filepath.Join(town, "shared-work.formula.toml"), []byte("formula = \"shared-work\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := reg.Status("shared-work", "", town); got != "modified" {
+		t.Errorf("Status = %q, want modified", got)
+	}
+	if err := reg.Activate("shared-work", 2, "", town, false); err == nil || !strings.Contains(err.Error(), "local changes") {
+		t.Errorf("Activate over edited file: err = %v", err)
+	}
+
+	if err := reg.Unpin("shared-work", "beads", rigDir, false); err != nil {
+		t.Fatalf("Unpin: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rigDir, "shared-work.formula.toml")); !os.IsNotExist(err) {
+		t.Errorf("rig copy should be removed on unpin, stat err = %v", err)
+	}
+}
+
+func TestFetchSourceGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	if err := os.MkdirAll(filepath.Join(repo, "formulas"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeSharedFormula(t, filepath.Join(repo, "formulas"), 1, "first")
+	run("add", ".")
+	run("commit", "-q", "-m", "v1")
+	run("tag", "v1")
+	writeSharedFormula(t, filepath.Join(repo, "formulas"), 2, "second")
+	run("commit", "-q", "-am", "v2")
+
+	fetched, commit, err := FetchSource(ParseSource("file://" + repo + "@v1"))
+	if err != nil {
+		t.Fatalf("FetchSource: %v", err)
+	}
+	if len(fetched) != 1 || fetched[0].Version != 1 || fetched[0].Path != "formulas/shared-work.formula.toml" {
+		t.Errorf("fetched = %+v", fetched)
+	}
+	if len(commit) != 40 {
+		t.Errorf("commit = %q", commit)
+	}
+}
+
+func TestRegistryRejectsUnsafeNames(t *testing.T) {
+	src := t.TempDir()
+	evil := "formula = \"../../evil\"\nversion = 1\n[[steps]]\nid = \"a\"\ntitle = \"x\"\n"
+	if err := os.WriteFile(filepath.Join(src, "evil.formula.toml"), []byte(evil), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := FetchSource(ParseSource(src)); err == nil || !strings.Contains(err.Error(), "invalid formula name") {
+		t.Errorf("FetchSource with ../ name: err = %v", err)
+	}
+
+	for _, name := range []string{"../evil", "a/b", `a\b`, "..", "shared work", ""} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) succeeded, want error", name)
+		}
+	}
+	for _, name := range []string{"mol-polecat-work", "shared_work.v2"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q): %v", name, err)
+		}
+	}
+
+	town := filepath.Join(t.TempDir(), "formulas")
+	reg, err := OpenRegistry(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ff := FetchedFormula{Name: "../escape", Version: 1, Content: []byte("x"), SHA256: computeHash([]byte("x"))}
+	if _, err := reg.Add(ff, Source{Location: src}, "", false); err == nil {
+		t.Error("Add accepted a ../ name")
+	}
+	// A tampered lockfile can't steer Activate outside the directory either
+	reg.Lock.Formulas["../escape"] = &LockEntry{Versions: map[int]*LockedVersion{1: {SHA256: ff.SHA256}}}
+	if err := reg.Activate("../escape", 1, "", town, true); err == nil || !strings.Contains(err.Error(), "invalid formula name") {
+		t.Errorf("Activate with ../ name: err = %v", err)
+	}
+	if _, ok := FindVersion(town, "../escape@1"); ok {
+		t.Error("FindVersion resolved a ../ name")
+	}
+}
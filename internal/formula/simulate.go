@@ -0,0 +1,280 @@
+package formula
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/flow"
+)
+
+// Unit states in a simulated run.
+const (
+	SimPending = "pending"
+	SimDone    = "done"
+	SimSkipped = "skipped"
+	SimFailed  = "failed"
+)
+
+// Final states of a simulated run.
+const (
+	RunComplete = "complete" // every unit done, skipped, or failed and passed over
+	RunFailed   = "failed"   // a unit failed and was escalated, or failed the molecule
+	RunStuck    = "stuck"    // units left that can never become ready
+)
+
+// Outcome scripts one attempt of a unit in a simulated run.
+type Outcome struct {
+	Outputs map[string]string `toml:"outputs"` // outputs the agent records
+	Fail    bool              `toml:"fail"`    // the agent reports failure
+	Waits   int               `toml:"waits"`   // polls before the unit completes
+}
+
+// SimOptions control a simulated run.
+type SimOptions struct {
+	// Script holds outcomes by unit ID, or by step ID for every unit of a
+	// foreach step. Successive attempts (until iterations) use successive
+	// outcomes; the last one repeats. Unscripted units succeed.
+	Script map[string][]Outcome
+
+	// FillOutputs records a placeholder for declared outputs the script
+	// doesn't give, instead of failing the run.
+	FillOutputs bool
+
+	// MaxEvents bounds the run (default 1000).
+	MaxEvents int
+}
+
+// SimEvent is one action of the simulated agent.
+type SimEvent struct {
+	Unit      string `json:"unit"`
+	Action    string `json:"action"` // run, skip, wait, fail, retry
+	Iteration int    `json:"iteration,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// SimResult is the outcome of a simulated run.
+type SimResult struct {
+	Events   []SimEvent                   `json:"events"`
+	Order    []string                     `json:"order"` // units run, once per iteration
+	Status   map[string]string            `json:"status"`
+	Outputs  map[string]map[string]string `json:"outputs,omitempty"`
+	Rendered map[string]string            `json:"rendered"` // title and description as the agent saw them
+	State    string                       `json:"state"`
+}
+
+// UnitsWith returns the units in a status, in plan order.
+func (r *SimResult) UnitsWith(p *Plan, status string) []string {
+	var ids []string
+	for _, u := range p.Units {
+		if r.Status[u.ID] == status {
+			ids = append(ids, u.ID)
+		}
+	}
+	return ids
+}
+
+// Simulate runs the plan with a simulated agent. The plan is drafted into
+// molecule step beads as gt formula run would pour it, and the agent works
+// them with the molecule step functions gt mol step done uses: it skips
+// steps whose when condition is false, runs the first ready step,
+// records its scripted outputs, repeats until steps, and applies retries
+// and on_failure policies. {{steps.<id>.<key>}} placeholders are expanded
+// when a unit runs, as gt prime would show them.
+func (p *Plan) Simulate(opts SimOptions) (*SimResult, error) {
+	if opts.MaxEvents <= 0 {
+		opts.MaxEvents = 1000
+	}
+	steps, err := p.draftSteps()
+	if err != nil {
+		return nil, err
+	}
+	units := make(map[string]*Unit, len(p.Units))
+	for i := range p.Units {
+		units[p.Units[i].ID] = &p.Units[i]
+	}
+
+	r := &SimResult{
+		Outputs:  make(map[string]map[string]string),
+		Rendered: make(map[string]string),
+	}
+	runs := make(map[string]int) // scripted outcomes used per unit
+	halted := false
+	for !halted && len(r.Events) < opts.MaxEvents {
+		next, err := beads.FindNextStep(steps)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range next.Skip {
+			s.Status = "closed"
+			s.Labels = append(s.Labels, beads.StepSkippedLabel)
+			r.Events = append(r.Events, SimEvent{Unit: s.ID, Action: "skip", Detail: "when " + beads.ParseStepControl(s.Description).When})
+		}
+		if next.Next == nil {
+			break
+		}
+
+		s, u := next.Next, units[next.Next.ID]
+		out := scriptedOutcome(opts.Script, u, runs[u.ID])
+		runs[u.ID]++
+		r.Rendered[u.ID] = beads.ExpandStepOutputs(u.Title+"\n"+u.Description, steps)
+		ctl := beads.ParseStepControl(s.Description)
+
+		if out.Waits > 0 {
+			r.Events = append(r.Events, SimEvent{Unit: u.ID, Action: "wait", Detail: fmt.Sprintf("%d polls", out.Waits)})
+		}
+		if out.Fail {
+			f, err := beads.DecideStepFailure(s, steps)
+			if err != nil {
+				return nil, err
+			}
+			s.Description = beads.SetStepAttempt(s.Description, f.Attempt)
+			if f.Action == beads.FailureRetry {
+				r.Events = append(r.Events, SimEvent{Unit: u.ID, Action: "retry", Detail: fmt.Sprintf("attempt %d/%d", f.Attempt+1, ctl.Retries+1)})
+				continue
+			}
+			r.Events = append(r.Events, SimEvent{Unit: u.ID, Action: "fail", Iteration: ctl.Iteration + 1, Detail: "on_failure " + f.Action + prefixed(":", f.Target)})
+			if f.Action != flow.OnFailureGoto || f.Close {
+				s.Labels = append(s.Labels, beads.StepFailedLabel)
+			}
+			for _, o := range f.Reopen {
+				o.Status = "open"
+				o.Labels = withoutLabels(o.Labels, beads.StepFailedLabel, beads.StepSkippedLabel)
+			}
+			if f.Close {
+				s.Status = "closed"
+			}
+			halted = f.Action == flow.OnFailureFailMolecule
+			continue
+		}
+
+		outputs := make(map[string]string, len(out.Outputs))
+		for k, v := range out.Outputs {
+			outputs[k] = v
+		}
+		if opts.FillOutputs {
+			recorded := beads.StepOutputs(s.Description)
+			for _, k := range ctl.Outputs {
+				if _, ok := outputs[k]; !ok {
+					if _, ok := recorded[k]; !ok {
+						outputs[k] = "test-" + k
+					}
+				}
+			}
+		}
+		if err := beads.CheckStepOutputs(s, outputs, false); err != nil {
+			return nil, err
+		}
+		s.Description = beads.SetStepOutputs(s.Description, outputs)
+		r.Order = append(r.Order, u.ID)
+		r.Events = append(r.Events, SimEvent{Unit: u.ID, Action: "run", Iteration: ctl.Iteration + 1})
+
+		repeat, n, err := beads.RepeatStep(s, steps)
+		if err != nil {
+			return nil, err
+		}
+		if repeat {
+			s.Description = beads.SetStepIteration(s.Description, n)
+			continue
+		}
+		if err := beads.CheckStepOutputs(s, nil, true); err != nil {
+			recorded := beads.StepOutputs(s.Description)
+			for _, k := range ctl.Outputs {
+				if _, ok := recorded[k]; !ok {
+					return nil, fmt.Errorf("unit %s completes without declared output %q (script it under [case.steps.%s])", u.ID, k, u.ID)
+				}
+			}
+			return nil, err
+		}
+		s.Status = "closed"
+	}
+
+	r.Status = make(map[string]string, len(steps))
+	r.State = RunComplete
+	for _, s := range steps {
+		if outputs := beads.StepOutputs(s.Description); len(outputs) > 0 {
+			r.Outputs[s.ID] = outputs
+		}
+		failed := slices.Contains(s.Labels, beads.StepFailedLabel)
+		switch {
+		case failed:
+			r.Status[s.ID] = SimFailed
+		case s.Status != "closed":
+			r.Status[s.ID] = SimPending
+		case slices.Contains(s.Labels, beads.StepSkippedLabel):
+			r.Status[s.ID] = SimSkipped
+		default:
+			r.Status[s.ID] = SimDone
+		}
+		switch {
+		case halted || (failed && s.Status != "closed"):
+			r.State = RunFailed
+		case s.Status != "closed" && r.State == RunComplete:
+			r.State = RunStuck
+		}
+	}
+	return r, nil
+}
+
+// draftSteps drafts the plan's molecule step beads, named by unit ID.
+func (p *Plan) draftSteps() ([]*beads.Issue, error) {
+	root := &beads.Issue{ID: p.Formula}
+	mol := &beads.Issue{ID: p.Formula, Description: p.MoleculeMarkdown()}
+	steps, err := beads.DraftMoleculeSteps(mol, root, beads.InstantiateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) != len(p.Units) {
+		return nil, fmt.Errorf("plan has %d units but drafts %d steps", len(p.Units), len(steps))
+	}
+	ids := make(map[string]string, len(steps))
+	for i, s := range steps {
+		ids[s.ID] = p.Units[i].ID
+	}
+	for _, s := range steps {
+		s.ID = ids[s.ID]
+		for i, dep := range s.DependsOn {
+			s.DependsOn[i] = ids[dep]
+		}
+	}
+	return steps, nil
+}
+
+// withoutLabels returns labels without the given ones.
+func withoutLabels(labels []string, drop ...string) []string {
+	var kept []string
+	for _, l := range labels {
+		if !slices.Contains(drop, l) {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// prefixed returns s with a prefix, or "" when s is empty.
+func prefixed(prefix, s string) string {
+	if s == "" {
+		return ""
+	}
+	return prefix + s
+}
+
+// scriptedOutcome returns a unit's outcome for an attempt.
+func scriptedOutcome(script map[string][]Outcome, u *Unit, attempt int) Outcome {
+	outcomes, ok := script[u.ID]
+	if !ok && u.Step != "" {
+		outcomes = script[u.Step]
+	}
+	if len(outcomes) == 0 {
+		return Outcome{}
+	}
+	return outcomes[min(attempt, len(outcomes)-1)]
+}
+
+// sortedStrings returns a sorted copy.
+func sortedStrings(s []string) []string {
+	out := append([]string(nil), s...)
+	sort.Strings(out)
+	return out
+}
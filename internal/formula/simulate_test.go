@@ -0,0 +1,211 @@
+package formula
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	f, err := Parse([]byte(patrolFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	p, err := f.Plan(PlanOptions{Vars: map[string][]string{"rigs": {"gastown"}}})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	run, err := p.Simulate(SimOptions{Script: map[string][]Outcome{
+		"scan":   {{Outputs: map[string]string{"stuck": "1"}}},
+		"scan.1": {{Outputs: map[string]string{"stuck": "0"}, Waits: 2}},
+		"wait":   {{Outputs: map[string]string{"activity": "no"}}, {Outputs: map[string]string{"activity": "yes"}}},
+	}})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	// The unit script wins over the step script, so nothing is stuck
+	want := []string{"inbox", "scan.1", "wait", "wait"}
+	if !reflect.DeepEqual(run.Order, want) {
+		t.Errorf("order = %v, want %v", run.Order, want)
+	}
+	if got := run.UnitsWith(p, SimSkipped); !reflect.DeepEqual(got, []string{"nudge"}) {
+		t.Errorf("skipped = %v, want [nudge]", got)
+	}
+	if run.State != RunComplete {
+		t.Errorf("state = %s, want complete", run.State)
+	}
+	if got := run.Outputs["wait"]["activity"]; got != "yes" {
+		t.Errorf("wait activity = %q, want yes", got)
+	}
+	if run.Events[1].Action != "wait" {
+		t.Errorf("event 1 = %+v, want a wait on scan.1", run.Events[1])
+	}
+}
+
+func TestSimulateFailureAndOutputs(t *testing.T) {
+	f, err := Parse([]byte(patrolFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	p, err := f.Plan(PlanOptions{})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	run, err := p.Simulate(SimOptions{Script: map[string][]Outcome{"scan.1": {{Fail: true}}}, FillOutputs: true})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if run.State != RunFailed {
+		t.Errorf("state = %s, want failed", run.State)
+	}
+	if got := run.Status["nudge"]; got != SimPending {
+		t.Errorf("nudge status = %s, want pending behind the failure", got)
+	}
+
+	// Without FillOutputs, a unit must record its declared outputs
+	if _, err := p.Simulate(SimOptions{}); err == nil || !strings.Contains(err.Error(), `declared output "stuck"`) {
+		t.Errorf("Simulate error = %v, want missing output", err)
+	}
+	_, err = p.Simulate(SimOptions{Script: map[string][]Outcome{"inbox": {{Outputs: map[string]string{"x": "1"}}}}, FillOutputs: true})
+	if err != nil {
+		t.Errorf("undeclared outputs on a unit without outputs should be allowed: %v", err)
+	}
+}
+
+const patrolTests = `
+formula = "patrol"
+
+[[case]]
+name = "full mode with stuck polecats"
+vars = { mode = "full", rigs = "gastown, beads" }
+
+[[case.steps.scan]]
+outputs = { stuck = "2" }
+
+[[case.steps.wait]]
+outputs = { activity = "yes" }
+
+[case.expect]
+order = ["inbox", "scan.1", "scan.2", "deep", "nudge", "wait"]
+skipped = []
+state = "complete"
+vars = { mode = "full" }
+contains = { "scan.2" = "Scan beads" }
+outputs = { "wait.activity" = "yes" }
+
+[[case]]
+name = "bad mode"
+vars = { mode = "slow" }
+error = "must be one of"
+`
+
+func TestRunTest(t *testing.T) {
+	f, err := Parse([]byte(patrolFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "patrol.test.toml")
+	if err := os.WriteFile(path, []byte(patrolTests), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tf, err := ParseTestFile(path)
+	if err != nil {
+		t.Fatalf("ParseTestFile failed: %v", err)
+	}
+	if len(tf.Cases) != 2 {
+		t.Fatalf("cases = %d, want 2", len(tf.Cases))
+	}
+	for _, c := range tf.Cases {
+		if res := f.RunTest(c, nil); !res.Passed {
+			t.Errorf("%s failed: %v", c.Name, res.Failures)
+		}
+	}
+
+	// A wrong expectation is reported, not fatal
+	c := tf.Cases[0]
+	c.Expect.Order = []string{"inbox"}
+	c.Expect.State = "stuck"
+	res := f.RunTest(c, nil)
+	if res.Passed || len(res.Failures) != 2 {
+		t.Errorf("failures = %v, want order and state", res.Failures)
+	}
+}
+
+func TestParseTestFileUnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.test.toml")
+	if err := os.WriteFile(path, []byte("[[case]]\nexpected = {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseTestFile(path); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Errorf("ParseTestFile error = %v, want unknown key", err)
+	}
+}
+
+const failureFormula = `
+formula = "fix"
+
+[[steps]]
+id = "implement"
+
+[[steps]]
+id = "test"
+needs = ["implement"]
+retries = 1
+on_failure = "goto:implement"
+
+[[steps]]
+id = "report"
+needs = ["test"]
+`
+
+func TestSimulateFailurePolicies(t *testing.T) {
+	f, err := Parse([]byte(failureFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	p, err := f.Plan(PlanOptions{})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if got := p.Unit("test").Control(); got != "Retries: 1\nOnFailure: goto:implement" {
+		t.Errorf("test control = %q", got)
+	}
+
+	// Fails twice: one retry, then back to implement; passes the third time
+	fail := Outcome{Fail: true}
+	run, err := p.Simulate(SimOptions{Script: map[string][]Outcome{"test": {fail, fail, {}}}})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if want := []string{"implement", "implement", "test", "report"}; !reflect.DeepEqual(run.Order, want) {
+		t.Errorf("order = %v, want %v", run.Order, want)
+	}
+	if run.State != RunComplete {
+		t.Errorf("state = %s, want complete", run.State)
+	}
+
+	// A goto loop that never passes ends in escalation
+	run, err = p.Simulate(SimOptions{Script: map[string][]Outcome{"test": {fail}}})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if run.State != RunFailed || run.Status["report"] != SimPending {
+		t.Errorf("state = %s, report = %s; want failed, pending", run.State, run.Status["report"])
+	}
+
+	// skip carries on; fail-molecule stops everything
+	p.Units[1].OnFailure = "skip"
+	run, _ = p.Simulate(SimOptions{Script: map[string][]Outcome{"test": {fail}}})
+	if run.State != RunComplete || run.Status["test"] != SimFailed || run.Status["report"] != SimDone {
+		t.Errorf("skip: state = %s, status = %v", run.State, run.Status)
+	}
+	p.Units[1].OnFailure = "fail-molecule"
+	run, _ = p.Simulate(SimOptions{Script: map[string][]Outcome{"test": {fail}}})
+	if run.State != RunFailed || run.Status["report"] != SimPending {
+		t.Errorf("fail-molecule: state = %s, status = %v", run.State, run.Status)
+	}
+}
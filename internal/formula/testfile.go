@@ -0,0 +1,220 @@
+package formula
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TestFile holds test cases for a formula, conventionally stored next to
+// it as <name>.test.toml:
+//
+//	[[case]]
+//	name = "quick mode skips the deep review"
+//	vars = { mode = "quick", rigs = "gastown, beads" }
+//
+//	[[case.steps.wait]]        # first attempt
+//	outputs = { activity = "no" }
+//	[[case.steps.wait]]        # second attempt
+//	outputs = { activity = "yes" }
+//
+//	[case.expect]
+//	order = ["inbox", "scan.1", "scan.2", "wait", "wait"]
+//	skipped = ["deep"]
+//	state = "complete"
+//	contains = { "scan.2" = "Scan beads" }
+type TestFile struct {
+	Formula string     `toml:"formula"` // optional; checked against the formula name
+	Cases   []TestCase `toml:"case"`
+}
+
+// TestCase is one scripted run of a formula.
+type TestCase struct {
+	Name string `toml:"name"`
+
+	// Vars are the values supplied, as for gt formula run --var. A list
+	// supplies a key more than once (e.g. expansion targets).
+	Vars map[string]any `toml:"vars"`
+
+	// Steps script the agent's outcomes by unit or step ID.
+	Steps map[string][]Outcome `toml:"steps"`
+
+	// Error expects planning or the run to fail with this substring.
+	Error string `toml:"error"`
+
+	Expect Expectation `toml:"expect"`
+}
+
+// Expectation is what a test case asserts. Unset fields aren't checked.
+type Expectation struct {
+	Order    []string          `toml:"order"`    // units run, once per iteration
+	Skipped  []string          `toml:"skipped"`  // units skipped (any order)
+	Failed   []string          `toml:"failed"`   // units failed (any order)
+	State    string            `toml:"state"`    // complete, failed or stuck
+	Vars     map[string]string `toml:"vars"`     // resolved variable values
+	Contains map[string]string `toml:"contains"` // unit -> text its rendered title/description must contain
+	Outputs  map[string]string `toml:"outputs"`  // "unit.key" -> recorded value
+}
+
+// TestResult is the outcome of running a test case.
+type TestResult struct {
+	Name     string     `json:"name"`
+	Passed   bool       `json:"passed"`
+	Failures []string   `json:"failures,omitempty"`
+	Plan     *Plan      `json:"-"`
+	Run      *SimResult `json:"run,omitempty"`
+}
+
+// ParseTestFile reads a formula test file.
+func ParseTestFile(path string) (*TestFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tf TestFile
+	md, err := toml.Decode(string(data), &tf)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("parsing %s: unknown key %s", path, undecoded[0])
+	}
+	if len(tf.Cases) == 0 {
+		return nil, fmt.Errorf("%s has no [[case]] entries", path)
+	}
+	for i, c := range tf.Cases {
+		if c.Name == "" {
+			tf.Cases[i].Name = fmt.Sprintf("case %d", i+1)
+		}
+	}
+	return &tf, nil
+}
+
+// RunTest plans the formula with the case's vars, simulates it with its
+// script, and checks the expectations.
+func (f *Formula) RunTest(c TestCase, check ValueCheck) TestResult {
+	res := TestResult{Name: c.Name}
+	fail := func(format string, args ...any) {
+		res.Failures = append(res.Failures, fmt.Sprintf(format, args...))
+	}
+
+	vars, err := testVars(c.Vars)
+	if err != nil {
+		fail("%v", err)
+		return res
+	}
+	res.Plan, res.Run, err = f.simulateCase(c, vars, check)
+	switch {
+	case c.Error != "" && err == nil:
+		fail("expected error containing %q, run succeeded", c.Error)
+	case c.Error != "" && !strings.Contains(err.Error(), c.Error):
+		fail("expected error containing %q, got: %v", c.Error, err)
+	case c.Error == "" && err != nil:
+		fail("%v", err)
+	}
+	if err != nil || c.Error != "" {
+		res.Passed = len(res.Failures) == 0
+		return res
+	}
+
+	plan, run, e := res.Plan, res.Run, c.Expect
+	if e.Order != nil && !reflect.DeepEqual(run.Order, e.Order) {
+		fail("order = %v, want %v", run.Order, e.Order)
+	}
+	if e.Skipped != nil {
+		if got := sortedStrings(run.UnitsWith(plan, SimSkipped)); !sameStrings(got, sortedStrings(e.Skipped)) {
+			fail("skipped = %v, want %v", got, sortedStrings(e.Skipped))
+		}
+	}
+	if e.Failed != nil {
+		if got := sortedStrings(run.UnitsWith(plan, SimFailed)); !sameStrings(got, sortedStrings(e.Failed)) {
+			fail("failed = %v, want %v", got, sortedStrings(e.Failed))
+		}
+	}
+	if e.State != "" && run.State != e.State {
+		fail("state = %s, want %s", run.State, e.State)
+	}
+	for _, k := range sortedKeys(e.Vars) {
+		if got := plan.Vars[k]; got != e.Vars[k] {
+			fail("var %s = %q, want %q", k, got, e.Vars[k])
+		}
+	}
+	for _, id := range sortedKeys(e.Contains) {
+		rendered, ok := run.Rendered[id]
+		switch {
+		case !ok:
+			fail("unit %s never ran", id)
+		case !strings.Contains(rendered, e.Contains[id]):
+			fail("unit %s text does not contain %q:\n%s", id, e.Contains[id], indent(rendered))
+		}
+	}
+	for _, ref := range sortedKeys(e.Outputs) {
+		i := strings.LastIndex(ref, ".")
+		if i < 0 {
+			fail("outputs key %q must be <unit>.<key>", ref)
+			continue
+		}
+		if got, ok := run.Outputs[ref[:i]][ref[i+1:]]; !ok || got != e.Outputs[ref] {
+			fail("output %s = %q, want %q", ref, got, e.Outputs[ref])
+		}
+	}
+	res.Passed = len(res.Failures) == 0
+	return res
+}
+
+func (f *Formula) simulateCase(c TestCase, vars map[string][]string, check ValueCheck) (*Plan, *SimResult, error) {
+	plan, err := f.Plan(PlanOptions{Vars: vars, Check: check})
+	if err != nil {
+		return nil, nil, err
+	}
+	for id := range c.Steps {
+		if plan.Unit(id) == nil && f.GetStep(id) == nil {
+			return plan, nil, fmt.Errorf("script for unknown step %q", id)
+		}
+	}
+	run, err := plan.Simulate(SimOptions{Script: c.Steps})
+	return plan, run, err
+}
+
+// testVars converts case vars (strings, numbers, bools or lists of them)
+// to supplied values.
+func testVars(in map[string]any) (map[string][]string, error) {
+	out := make(map[string][]string)
+	for k, v := range in {
+		switch v := v.(type) {
+		case []any:
+			for _, item := range v {
+				out[k] = append(out[k], fmt.Sprint(item))
+			}
+		case map[string]any:
+			return nil, fmt.Errorf("var %s must be a value or list, not a table", k)
+		default:
+			out[k] = []string{fmt.Sprint(v)}
+		}
+	}
+	return out, nil
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n    ")
+}
@@ -59,10 +59,12 @@ type Aspect struct {
 // Input represents an input parameter for a formula.
 type Input struct {
 	Description    string   `toml:"description"`
-	Type           string   `toml:"type"`
+	Type           string   `toml:"type"` // see Var.Type
 	Required       bool     `toml:"required"`
 	RequiredUnless []string `toml:"required_unless"`
 	Default        string   `toml:"default"`
+	Choices        []string `toml:"choices"`
+	Pattern        string   `toml:"pattern"`
 }
 
 // Output configures where formula outputs are written.
@@ -93,6 +95,28 @@ type Step struct {
 	Title       string   `toml:"title"`
 	Description string   `toml:"description"`
 	Needs       []string `toml:"needs"`
+
+	// Control flow (see package flow for condition syntax).
+	When          string `toml:"when"`           // run only if true; skipped otherwise
+	Foreach       string `toml:"foreach"`        // list var; one step per item, as {{item}}
+	Until         string `toml:"until"`          // repeat the step until true
+	MaxIterations int    `toml:"max_iterations"` // bound for until (default 10)
+
+	// Outputs are named values the step records on completion (gt mol step
+	// done --output key=value); later steps use them as {{steps.<id>.<key>}}.
+	Outputs []string `toml:"outputs"`
+
+	// Tier is a model tier hint: haiku, sonnet or opus. Town and rig
+	// settings map it to the agent that runs the step.
+	Tier string `toml:"tier"`
+
+	// Failure handling (see package flow). A step that fails (gt mol step
+	// done --failed) or runs past its timeout is retried up to retries
+	// times, then handled by on_failure: escalate (default), skip,
+	// fail-molecule or goto:<step>.
+	Timeout   string `toml:"timeout"`
+	Retries   int    `toml:"retries"`
+	OnFailure string `toml:"on_failure"`
 }
 
 // Template represents a template step in an expansion formula.
@@ -106,8 +130,12 @@ type Template struct {
 // Var represents a variable definition for formulas.
 type Var struct {
 	Description string `toml:"description"`
-	Required    bool   `toml:"required"`
-	Default     string `toml:"default"`
+	// Type is string (default), int, bool, enum, bead-id, rig, path, or duration.
+	Type     string   `toml:"type"`
+	Required bool     `toml:"required"`
+	Default  string   `toml:"default"`
+	Choices  []string `toml:"choices"` // allowed values (required for enum)
+	Pattern  string   `toml:"pattern"` // regex the whole value must match
 }
 
 // IsValid returns true if the formula type is recognized.
@@ -0,0 +1,332 @@
+package formula
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Variable types for vars and inputs.
+const (
+	VarString   = "string"
+	VarInt      = "int"
+	VarBool     = "bool"
+	VarEnum     = "enum"
+	VarBeadID   = "bead-id"
+	VarRig      = "rig"
+	VarPath     = "path"
+	VarDuration = "duration"
+)
+
+// varTypes maps accepted type names, including aliases, to variable types.
+var varTypes = map[string]string{
+	"":          VarString,
+	VarString:   VarString,
+	VarInt:      VarInt,
+	"integer":   VarInt,
+	"number":    VarInt,
+	VarBool:     VarBool,
+	"boolean":   VarBool,
+	VarEnum:     VarEnum,
+	VarBeadID:   VarBeadID,
+	VarRig:      VarRig,
+	VarPath:     VarPath,
+	VarDuration: VarDuration,
+}
+
+var (
+	varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	beadIDPattern  = regexp.MustCompile(`^[a-z0-9]+-[a-z0-9][a-z0-9._-]*$`)
+	rigNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+)
+
+// VarSpec is the declaration of a variable, from [vars] or [inputs].
+type VarSpec struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	Type           string   `json:"type"`
+	Required       bool     `json:"required,omitempty"`
+	RequiredUnless []string `json:"required_unless,omitempty"`
+	Default        string   `json:"default,omitempty"`
+	Choices        []string `json:"choices,omitempty"`
+	Pattern        string   `json:"pattern,omitempty"`
+}
+
+// ValueCheck verifies a value against state the formula package can't
+// see, such as whether a bead or rig exists. It may be nil.
+type ValueCheck func(spec VarSpec, value string) error
+
+// VarSpecs returns the formula's declared variables and inputs, by name.
+func (f *Formula) VarSpecs() []VarSpec {
+	var specs []VarSpec
+	for name, v := range f.Vars {
+		specs = append(specs, VarSpec{
+			Name:        name,
+			Description: v.Description,
+			Type:        varTypes[v.Type],
+			Required:    v.Required,
+			Default:     v.Default,
+			Choices:     v.Choices,
+			Pattern:     v.Pattern,
+		})
+	}
+	for name, in := range f.Inputs {
+		specs = append(specs, VarSpec{
+			Name:           name,
+			Description:    in.Description,
+			Type:           varTypes[in.Type],
+			Required:       in.Required,
+			RequiredUnless: in.RequiredUnless,
+			Default:        in.Default,
+			Choices:        in.Choices,
+			Pattern:        in.Pattern,
+		})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+// VarSpec returns the declaration of a variable, or nil.
+func (f *Formula) VarSpec(name string) *VarSpec {
+	for _, s := range f.VarSpecs() {
+		if s.Name == name {
+			return &s
+		}
+	}
+	return nil
+}
+
+// Check validates a value against the variable's type, choices and pattern.
+func (s VarSpec) Check(value string) error {
+	switch s.Type {
+	case VarInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", s.Name, value)
+		}
+	case VarBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", s.Name, value)
+		}
+	case VarBeadID:
+		if !beadIDPattern.MatchString(value) {
+			return fmt.Errorf("%s must be a bead ID like gt-abc12, got %q", s.Name, value)
+		}
+	case VarRig:
+		if !rigNamePattern.MatchString(value) {
+			return fmt.Errorf("%s must be a rig name, got %q", s.Name, value)
+		}
+	case VarPath:
+		if value == "" || strings.ContainsRune(value, 0) {
+			return fmt.Errorf("%s must be a path, got %q", s.Name, value)
+		}
+	case VarDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("%s must be a duration like 30m, got %q", s.Name, value)
+		}
+	}
+	if len(s.Choices) > 0 && !contains(s.Choices, value) {
+		return fmt.Errorf("%s must be one of %s, got %q", s.Name, strings.Join(s.Choices, ", "), value)
+	}
+	if s.Pattern != "" {
+		// Anchored: the whole value must match
+		re, err := regexp.Compile("^(?:" + s.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("%s has invalid pattern: %w", s.Name, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s must match /%s/, got %q", s.Name, s.Pattern, value)
+		}
+	}
+	return nil
+}
+
+// validateVars checks variable declarations: known types, enum choices,
+// compilable patterns, and defaults that satisfy them.
+func (f *Formula) validateVars() error {
+	declared := func(name, typ string, choices []string, pattern string) error {
+		if !varNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+		if _, ok := varTypes[typ]; !ok {
+			return fmt.Errorf("variable %s has unknown type %q (must be string, int, bool, enum, bead-id, rig, path, or duration)", name, typ)
+		}
+		if varTypes[typ] == VarEnum && len(choices) == 0 {
+			return fmt.Errorf("enum variable %s requires choices", name)
+		}
+		if pattern != "" {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("variable %s has invalid pattern: %w", name, err)
+			}
+		}
+		return nil
+	}
+	for name, v := range f.Vars {
+		if err := declared(name, v.Type, v.Choices, v.Pattern); err != nil {
+			return err
+		}
+	}
+	for name, in := range f.Inputs {
+		if err := declared(name, in.Type, in.Choices, in.Pattern); err != nil {
+			return err
+		}
+		for _, other := range in.RequiredUnless {
+			if _, ok := f.Inputs[other]; !ok {
+				return fmt.Errorf("input %s required_unless references unknown input: %s", name, other)
+			}
+		}
+	}
+	for _, s := range f.VarSpecs() {
+		if s.Default == "" {
+			continue
+		}
+		if err := s.Check(s.Default); err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+	}
+	return nil
+}
+
+// templateKeywords are control words of the report templates agents fill
+// in ({{#if x}}...{{else}}...{{/if}}), not vars.
+var templateKeywords = []string{"else", "end"}
+
+// validatePlaceholders checks that every {{name}} placeholder in unit text
+// names a declared var or input. Foreach steps may also use {{item}} and
+// {{index}}; {{steps.<id>.<key>}} output references are not vars. Values an
+// agent fills in itself are written <name>.
+func (f *Formula) validatePlaceholders() error {
+	check := func(kind, id string, extra []string, texts ...string) error {
+		for _, text := range texts {
+			for _, m := range varPattern.FindAllStringSubmatch(text, -1) {
+				name := m[1]
+				if f.VarSpec(name) == nil && !contains(extra, name) && !contains(templateKeywords, name) {
+					return fmt.Errorf("%s %q uses undeclared variable {{%s}}", kind, id, name)
+				}
+			}
+		}
+		return nil
+	}
+	for _, step := range f.Steps {
+		var extra []string
+		if step.Foreach != "" {
+			extra = []string{"item", "index"}
+		}
+		if err := check("step", step.ID, extra, step.Title, step.Description); err != nil {
+			return err
+		}
+	}
+	for _, leg := range f.Legs {
+		if err := check("leg", leg.ID, nil, leg.Title, leg.Description); err != nil {
+			return err
+		}
+	}
+	for _, a := range f.Aspects {
+		if err := check("aspect", a.ID, nil, a.Title, a.Description); err != nil {
+			return err
+		}
+	}
+	if f.Synthesis != nil {
+		if err := check("synthesis", SynthesisID, nil, f.Synthesis.Title, f.Synthesis.Description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckVars validates supplied values without resolving them: every name
+// must be declared, and every value must fit its declaration.
+func (f *Formula) CheckVars(supplied map[string][]string, check ValueCheck) error {
+	declared := make(map[string]VarSpec)
+	for _, s := range f.VarSpecs() {
+		declared[s.Name] = s
+	}
+	if f.Type == TypeExpansion {
+		if target, err := f.expansionTarget(); err == nil && target != "" {
+			if _, ok := declared[target]; !ok {
+				declared[target] = VarSpec{Name: target, Type: VarString}
+			}
+		}
+	}
+
+	var unknown []string
+	for name := range supplied {
+		if _, ok := declared[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		var names []string
+		for name := range declared {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("formula %s declares no variables, got: %s", f.Name, strings.Join(unknown, ", "))
+		}
+		return fmt.Errorf("undeclared variable(s): %s (formula %s accepts: %s)",
+			strings.Join(unknown, ", "), f.Name, strings.Join(names, ", "))
+	}
+
+	for _, name := range sortedVarNames(supplied) {
+		spec := declared[name]
+		for _, value := range supplied[name] {
+			if err := spec.Check(value); err != nil {
+				return err
+			}
+			if check != nil {
+				if err := check(spec, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Usage returns a one-line synopsis of how to run the formula.
+func (f *Formula) Usage() string {
+	parts := []string{"gt formula run " + f.Name}
+	for _, s := range f.VarSpecs() {
+		arg := fmt.Sprintf("--var %s=<%s>", s.Name, s.placeholder())
+		if !s.Required || s.Default != "" {
+			arg = "[" + arg + "]"
+		}
+		parts = append(parts, arg)
+	}
+	if f.Type == TypeExpansion {
+		if target, err := f.expansionTarget(); err == nil && target != "" && f.VarSpec(target) == nil {
+			parts = append(parts, fmt.Sprintf("--var %s=<value>...", target))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// placeholder describes the expected value in a usage synopsis.
+func (s VarSpec) placeholder() string {
+	if len(s.Choices) > 0 {
+		return strings.Join(s.Choices, "|")
+	}
+	return s.Type
+}
+
+func sortedVarNames(m map[string][]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package formula
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVarSpecCheck(t *testing.T) {
+	tests := []struct {
+		spec  VarSpec
+		value string
+		ok    bool
+	}{
+		{VarSpec{Name: "n", Type: VarInt}, "42", true},
+		{VarSpec{Name: "n", Type: VarInt}, "4x", false},
+		{VarSpec{Name: "b", Type: VarBool}, "true", true},
+		{VarSpec{Name: "b", Type: VarBool}, "yes", false},
+		{VarSpec{Name: "e", Type: VarEnum, Choices: []string{"patch", "minor"}}, "minor", true},
+		{VarSpec{Name: "e", Type: VarEnum, Choices: []string{"patch", "minor"}}, "major", false},
+		{VarSpec{Name: "id", Type: VarBeadID}, "gt-abc12", true},
+		{VarSpec{Name: "id", Type: VarBeadID}, "not a bead", false},
+		{VarSpec{Name: "r", Type: VarRig}, "gastown", true},
+		{VarSpec{Name: "r", Type: VarRig}, "gastown/crew", false},
+		{VarSpec{Name: "p", Type: VarPath}, "src/main.go", true},
+		{VarSpec{Name: "p", Type: VarPath}, "", false},
+		{VarSpec{Name: "d", Type: VarDuration}, "90m", true},
+		{VarSpec{Name: "d", Type: VarDuration}, "soon", false},
+		{VarSpec{Name: "v", Type: VarString, Pattern: `\d+\.\d+\.\d+`}, "1.2.3", true},
+		{VarSpec{Name: "v", Type: VarString, Pattern: `\d+\.\d+\.\d+`}, "v1.2.3", false},
+	}
+	for _, tt := range tests {
+		err := tt.spec.Check(tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s(%s).Check(%q) error = %v, want ok=%v", tt.spec.Name, tt.spec.Type, tt.value, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateVars(t *testing.T) {
+	tests := []struct {
+		name string
+		vars string
+		want string
+	}{
+		{"unknown type", "[vars.x]\ntype = \"float\"", "unknown type"},
+		{"enum without choices", "[vars.x]\ntype = \"enum\"", "requires choices"},
+		{"bad pattern", "[vars.x]\npattern = \"[\"", "invalid pattern"},
+		{"bad default", "[vars.x]\ntype = \"int\"\ndefault = \"many\"", "invalid default"},
+		{"default outside choices", "[vars.x]\ntype = \"enum\"\nchoices = [\"a\"]\ndefault = \"b\"", "invalid default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte("formula = \"f\"\n[[steps]]\nid = \"s\"\n" + tt.vars))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePlaceholders(t *testing.T) {
+	tests := []struct {
+		name  string
+		toml  string
+		valid bool
+	}{
+		{"declared var", "[[steps]]\nid = \"s\"\ndescription = \"Fix {{issue}}\"\n[vars.issue]", true},
+		{"typo", "[[steps]]\nid = \"s\"\ndescription = \"Fix {{isue}}\"\n[vars.issue]", false},
+		{"undeclared in title", "[[steps]]\nid = \"s\"\ntitle = \"{{who}}\"", false},
+		{"step output", "[[steps]]\nid = \"a\"\noutputs = [\"sha\"]\n[[steps]]\nid = \"s\"\nneeds = [\"a\"]\ndescription = \"{{steps.a.sha}}\"", true},
+		{"foreach item", "[[steps]]\nid = \"s\"\nforeach = \"files\"\ndescription = \"{{index}}: {{item}}\"\n[vars.files]", true},
+		{"item outside foreach", "[[steps]]\nid = \"s\"\ndescription = \"{{item}}\"", false},
+		{"template keyword", "[[steps]]\nid = \"s\"\ndescription = \"{{#if x}}a{{else}}b{{/if}}\"", true},
+		{"convoy leg", "[[legs]]\nid = \"l\"\ndescription = \"{{pr}}\"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte("formula = \"f\"\n" + tt.toml))
+			if (err == nil) != tt.valid {
+				t.Errorf("Parse error = %v, want valid=%v", err, tt.valid)
+			}
+			if err != nil && !tt.valid && !strings.Contains(err.Error(), "undeclared variable") {
+				t.Errorf("Parse error = %v, want an undeclared variable error", err)
+			}
+		})
+	}
+}
+
+func TestPlanUnsetPlaceholder(t *testing.T) {
+	f, err := Parse([]byte(`formula = "f"
+[[steps]]
+id = "fix"
+description = "Fix {{issue}} in {{area}}"
+[[steps]]
+id = "notify"
+when = "vars.ticket != ''"
+description = "Update {{ticket}}"
+[vars.issue]
+required = true
+[vars.area]
+[vars.ticket]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	_, err = f.Plan(PlanOptions{Vars: map[string][]string{"issue": {"gt-1"}}})
+	if err == nil || !strings.Contains(err.Error(), "area") || strings.Contains(err.Error(), "ticket") {
+		t.Errorf("Plan error = %v, want area (and not the skipped step's ticket) reported unset", err)
+	}
+
+	if _, err := f.Plan(PlanOptions{Vars: map[string][]string{"issue": {"gt-1"}, "area": {"mail"}}}); err != nil {
+		t.Errorf("Plan with every used var set: %v", err)
+	}
+}
+
+func TestCheckVars(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "release"
+
+[[steps]]
+id = "bump"
+title = "Bump to {{version}}"
+
+[vars.version]
+required = true
+pattern = '\d+\.\d+\.\d+'
+
+[vars.issue]
+type = "bead-id"
+
+[vars.level]
+type = "enum"
+choices = ["patch", "minor", "major"]
+default = "patch"
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if err := f.CheckVars(map[string][]string{"versoin": {"1.0.0"}}, nil); err == nil || !strings.Contains(err.Error(), "undeclared variable(s): versoin") {
+		t.Errorf("expected undeclared error, got %v", err)
+	}
+	if err := f.CheckVars(map[string][]string{"level": {"huge"}}, nil); err == nil {
+		t.Error("expected enum error")
+	}
+	if _, err := f.ResolveVars(map[string][]string{"level": {"minor"}}, nil); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("expected missing version error, got %v", err)
+	}
+
+	missing := errors.New("bead not found")
+	check := func(spec VarSpec, value string) error {
+		if spec.Type == VarBeadID && value != "gt-1" {
+			return missing
+		}
+		return nil
+	}
+	if err := f.CheckVars(map[string][]string{"issue": {"gt-2"}}, check); !errors.Is(err, missing) {
+		t.Errorf("expected check error, got %v", err)
+	}
+	vars, err := f.ResolveVars(map[string][]string{"version": {"1.2.3"}, "issue": {"gt-1"}}, check)
+	if err != nil {
+		t.Fatalf("ResolveVars: %v", err)
+	}
+	if vars["level"] != "patch" {
+		t.Errorf("level = %q, want default patch", vars["level"])
+	}
+
+	want := "gt formula run release [--var issue=<bead-id>] [--var level=<patch|minor|major>] --var version=<string>"
+	if got := f.Usage(); got != want {
+		t.Errorf("Usage() = %q\nwant      %q", got, want)
+	}
+}
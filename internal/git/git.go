@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // GitError contains raw output from a git command for agent observation.
@@ -583,6 +584,13 @@ func (g *Git) Rev(ref string) (string, error) {
 	return g.run("rev-parse", ref)
 }
 
+// CommonDir returns the absolute path of the repository's common git directory.
+// For a linked worktree this is the main repo's .git (or the bare repo), where
+// objects and refs live.
+func (g *Git) CommonDir() (string, error) {
+	return g.run("rev-parse", "--path-format=absolute", "--git-common-dir")
+}
+
 // IsAncestor checks if ancestor is an ancestor of descendant.
 func (g *Git) IsAncestor(ancestor, descendant string) (bool, error) {
 	_, err := g.run("merge-base", "--is-ancestor", ancestor, descendant)
@@ -596,6 +604,86 @@ func (g *Git) IsAncestor(ancestor, descendant string) (bool, error) {
 	return true, nil
 }
 
+// MergeBase returns the best common ancestor commit of two refs.
+func (g *Git) MergeBase(a, b string) (string, error) {
+	return g.run("merge-base", a, b)
+}
+
+// LineRange is an inclusive range of lines in a file.
+// A pure insertion is recorded as a zero-width range at the insertion point.
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Overlaps returns true if the two ranges share or touch a line.
+// Git treats adjacent edits as conflicting, so touching ranges count.
+func (r LineRange) Overlaps(other LineRange) bool {
+	return r.Start <= other.End+1 && other.Start <= r.End+1
+}
+
+// DiffHunks returns the lines changed between base and head, keyed by file path.
+// Ranges are expressed in base-side line numbers so that hunks from two diffs
+// against the same base can be compared for overlap.
+func (g *Git) DiffHunks(base, head string) (map[string][]LineRange, error) {
+	out, err := g.run("diff", "-U0", "--no-color", "--no-ext-diff", base, head)
+	if err != nil {
+		return nil, err
+	}
+	return parseDiffHunks(out), nil
+}
+
+// parseDiffHunks parses unified diff output (with -U0) into changed base-side
+// line ranges per file. Added files are keyed by their new path.
+func parseDiffHunks(diff string) map[string][]LineRange {
+	hunks := make(map[string][]LineRange)
+	var file string
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "--- "), "a/")
+		case strings.HasPrefix(line, "+++ "):
+			if file == "/dev/null" {
+				file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			}
+			if _, ok := hunks[file]; !ok {
+				hunks[file] = nil
+			}
+		case strings.HasPrefix(line, "@@ "):
+			if r, ok := parseHunkHeader(line); ok && file != "" {
+				hunks[file] = append(hunks[file], r)
+			}
+		}
+	}
+	return hunks
+}
+
+// parseHunkHeader parses the base-side range from a hunk header
+// such as "@@ -12,3 +12,4 @@".
+func parseHunkHeader(header string) (LineRange, bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return LineRange{}, false
+	}
+
+	spec := strings.TrimPrefix(fields[1], "-")
+	start, count := 0, 1
+	if idx := strings.Index(spec, ","); idx >= 0 {
+		if _, err := fmt.Sscanf(spec[idx+1:], "%d", &count); err != nil {
+			return LineRange{}, false
+		}
+		spec = spec[:idx]
+	}
+	if _, err := fmt.Sscanf(spec, "%d", &start); err != nil {
+		return LineRange{}, false
+	}
+
+	if count == 0 {
+		return LineRange{Start: start, End: start}, true
+	}
+	return LineRange{Start: start, End: start + count - 1}, true
+}
+
 // WorktreeAdd creates a new worktree at the given path with a new branch.
 // The new branch is created from the current HEAD.
 // Sparse checkout is enabled to exclude .claude/ from source repos.
@@ -625,6 +713,19 @@ func (g *Git) WorktreeAddDetached(path, ref string) error {
 	return ConfigureSparseCheckout(path)
 }
 
+// WorktreeMove moves an existing worktree to a new path.
+// The parent of the destination must already exist.
+func (g *Git) WorktreeMove(from, to string) error {
+	_, err := g.run("worktree", "move", from, to)
+	return err
+}
+
+// CheckoutNewBranch creates a new branch at startPoint and checks it out.
+func (g *Git) CheckoutNewBranch(name, startPoint string) error {
+	_, err := g.run("checkout", "-b", name, startPoint)
+	return err
+}
+
 // WorktreeAddExisting creates a new worktree at the given path for an existing branch.
 // Sparse checkout is enabled to exclude .claude/ from source repos.
 func (g *Git) WorktreeAddExisting(path, branch string) error {
@@ -890,6 +991,45 @@ func (g *Git) CommitsAhead(base, branch string) (int, error) {
 	return count, nil
 }
 
+// CommitInfo summarizes a single commit.
+type CommitInfo struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// LogSince returns the non-merge commits reachable from ref that were
+// authored at or after since, newest first. A zero since returns all commits.
+func (g *Git) LogSince(ref string, since time.Time) ([]CommitInfo, error) {
+	args := []string{"log", "--no-merges", "--format=%H%x1f%an%x1f%aI%x1f%s"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	args = append(args, ref, "--")
+
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitInfo
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\x1f", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, parts[2])
+		commits = append(commits, CommitInfo{
+			Hash:    parts[0],
+			Author:  parts[1],
+			Date:    date,
+			Subject: parts[3],
+		})
+	}
+	return commits, nil
+}
+
 // CountCommitsBehind returns the number of commits that HEAD is behind the given ref.
 // For example, CountCommitsBehind("origin/main") returns how many commits
 // are on origin/main that are not on the current HEAD.
@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func initTestRepo(t *testing.T) string {
@@ -488,3 +489,123 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestParseDiffHunks(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -10,2 +10,3 @@ func main() {
+@@ -40 +41 @@ func helper() {
+@@ -50,0 +52,4 @@ func other() {
+diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,5 @@
+`
+
+	hunks := parseDiffHunks(diff)
+
+	main := hunks["main.go"]
+	want := []LineRange{{10, 11}, {40, 40}, {50, 50}}
+	if len(main) != len(want) {
+		t.Fatalf("main.go hunks = %v, want %v", main, want)
+	}
+	for i := range want {
+		if main[i] != want[i] {
+			t.Errorf("hunk %d = %v, want %v", i, main[i], want[i])
+		}
+	}
+
+	if _, ok := hunks["new.go"]; !ok {
+		t.Errorf("expected new.go in hunks, got %v", hunks)
+	}
+}
+
+func TestLineRangeOverlaps(t *testing.T) {
+	tests := []struct {
+		a, b LineRange
+		want bool
+	}{
+		{LineRange{1, 5}, LineRange{3, 8}, true},
+		{LineRange{1, 5}, LineRange{6, 8}, true}, // adjacent
+		{LineRange{1, 5}, LineRange{7, 8}, false},
+		{LineRange{10, 10}, LineRange{1, 3}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Overlaps(tt.b); got != tt.want {
+			t.Errorf("%v.Overlaps(%v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLogSince(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("README.md"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit("change readme"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	commits, err := g.LogSince("HEAD", time.Time{})
+	if err != nil {
+		t.Fatalf("LogSince: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("LogSince = %d commits, want 2", len(commits))
+	}
+	if commits[0].Subject != "change readme" || commits[0].Author != "Test User" || commits[0].Date.IsZero() {
+		t.Errorf("newest commit = %+v", commits[0])
+	}
+
+	future, err := g.LogSince("HEAD", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("LogSince: %v", err)
+	}
+	if len(future) != 0 {
+		t.Errorf("LogSince(future) = %v, want none", future)
+	}
+}
+
+func TestDiffHunks(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	base, err := g.Rev("HEAD")
+	if err != nil {
+		t.Fatalf("Rev: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("README.md"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit("change readme"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	mb, err := g.MergeBase(base, "HEAD")
+	if err != nil {
+		t.Fatalf("MergeBase: %v", err)
+	}
+	if mb != base {
+		t.Errorf("MergeBase = %s, want %s", mb, base)
+	}
+
+	hunks, err := g.DiffHunks(base, "HEAD")
+	if err != nil {
+		t.Fatalf("DiffHunks: %v", err)
+	}
+	if got := hunks["README.md"]; len(got) != 1 || got[0] != (LineRange{1, 1}) {
+		t.Errorf("README.md hunks = %v, want [{1 1}]", got)
+	}
+}
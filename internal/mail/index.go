@@ -0,0 +1,463 @@
+package mail
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// indexFileName is the mail search index, kept in the town's .runtime/.
+const indexFileName = "mail-index.json"
+
+// journalFileName holds the updates made since the index was last saved,
+// one JSON op per line, so a send or mark-read appends a line instead of
+// rewriting the whole index.
+const journalFileName = "mail-index.journal.jsonl"
+
+// currentIndexVersion is bumped when the index format or tokenization
+// changes; older indexes are rebuilt.
+const currentIndexVersion = 1
+
+// indexMaxAge is how old an index may get before a search rebuilds it.
+// Sends, archives and closes update the index as they happen; the rebuild
+// catches mail written by other tools. The daemon rebuilds it once stale.
+const indexMaxAge = 15 * time.Minute
+
+// MailIndex is a persistent inverted index over a town's mail: inbox
+// messages (open and hooked beads) and the archive. It maps each subject
+// and body term to the messages containing it, and keeps the messages
+// themselves so searches don't need bd.
+type MailIndex struct {
+	path string
+
+	Version  int                    `json:"version"`
+	BuiltAt  time.Time              `json:"built_at"`
+	Messages map[string]*IndexEntry `json:"messages"`
+	Terms    map[string][]string    `json:"terms"` // term -> message IDs
+}
+
+// IndexEntry is one indexed message.
+type IndexEntry struct {
+	Message  *Message `json:"message"`
+	Archived bool     `json:"archived,omitempty"`
+}
+
+// indexOp is one incremental index update, recorded in the journal.
+type indexOp struct {
+	Op       string   `json:"op"` // add, close, purge or read
+	ID       string   `json:"id,omitempty"`
+	Message  *Message `json:"message,omitempty"`
+	Archived bool     `json:"archived,omitempty"`
+	Read     bool     `json:"read,omitempty"`
+}
+
+// Index journal ops.
+const (
+	opAdd   = "add"   // index Message (Archived if it went to the archive)
+	opClose = "close" // ID left the inbox
+	opPurge = "purge" // ID was purged from the archive
+	opRead  = "read"  // ID's read state is now Read
+)
+
+// IndexPath returns where the mail index for a beads directory lives.
+func IndexPath(beadsDir string) string {
+	return filepath.Join(filepath.Dir(beadsDir), constants.DirRuntime, indexFileName)
+}
+
+// journalPath returns where the index journal for a beads directory lives.
+func journalPath(beadsDir string) string {
+	return filepath.Join(filepath.Dir(IndexPath(beadsDir)), journalFileName)
+}
+
+// newMailIndex returns an empty index that saves to path.
+func newMailIndex(path string) *MailIndex {
+	return &MailIndex{
+		path:     path,
+		Version:  currentIndexVersion,
+		Messages: make(map[string]*IndexEntry),
+		Terms:    make(map[string][]string),
+	}
+}
+
+// LoadIndex loads the mail index for a beads directory and replays the
+// journal onto it. A missing or outdated index loads as empty (zero
+// BuiltAt), which callers rebuild.
+func LoadIndex(beadsDir string) (*MailIndex, error) {
+	path := IndexPath(beadsDir)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is derived from the beads dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newMailIndex(path), nil
+		}
+		return nil, fmt.Errorf("reading mail index: %w", err)
+	}
+
+	ix := newMailIndex(path)
+	if err := json.Unmarshal(data, ix); err != nil || ix.Version != currentIndexVersion {
+		return newMailIndex(path), nil
+	}
+	if ix.Messages == nil {
+		ix.Messages = make(map[string]*IndexEntry)
+	}
+	if ix.Terms == nil {
+		ix.Terms = make(map[string][]string)
+	}
+	if err := ix.replayJournal(journalPath(beadsDir)); err != nil {
+		return nil, fmt.Errorf("reading mail index journal: %w", err)
+	}
+	return ix, nil
+}
+
+// replayJournal applies the journaled updates, skipping malformed lines.
+func (ix *MailIndex) replayJournal(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is derived from the beads dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		var op indexOp
+		if json.Unmarshal([]byte(line), &op) == nil {
+			ix.apply(op)
+		}
+	}
+	return nil
+}
+
+// apply performs one journaled update.
+func (ix *MailIndex) apply(op indexOp) {
+	switch op.Op {
+	case opAdd:
+		ix.Add(op.Message, op.Archived)
+	case opClose:
+		ix.removeFromInbox(op.ID)
+	case opPurge:
+		if e, ok := ix.Messages[op.ID]; ok && e.Archived {
+			ix.Remove(op.ID)
+		}
+	case opRead:
+		if e, ok := ix.Messages[op.ID]; ok {
+			e.Message.Read = op.Read
+		}
+	}
+}
+
+// Stale reports whether the index was never built or is older than indexMaxAge.
+func (ix *MailIndex) Stale(now time.Time) bool {
+	return ix.BuiltAt.IsZero() || now.Sub(ix.BuiltAt) > indexMaxAge
+}
+
+// Save writes the index atomically and clears the journal, whose updates
+// the index now includes. Callers hold the index lock.
+func (ix *MailIndex) Save() error {
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0755); err != nil {
+		return err
+	}
+	if err := util.AtomicWriteJSON(ix.path, ix); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(filepath.Dir(ix.path), journalFileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Add indexes a message, replacing any earlier entry with the same ID.
+func (ix *MailIndex) Add(msg *Message, archived bool) {
+	if msg == nil || msg.ID == "" {
+		return
+	}
+	ix.Remove(msg.ID)
+	ix.Messages[msg.ID] = &IndexEntry{Message: msg, Archived: archived}
+	for _, term := range uniqueTerms(msg) {
+		ix.Terms[term] = append(ix.Terms[term], msg.ID)
+	}
+}
+
+// Remove drops a message from the index.
+func (ix *MailIndex) Remove(id string) {
+	entry, ok := ix.Messages[id]
+	if !ok {
+		return
+	}
+	for _, term := range uniqueTerms(entry.Message) {
+		ids := ix.Terms[term]
+		for i, other := range ids {
+			if other == id {
+				ids = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(ix.Terms, term)
+		} else {
+			ix.Terms[term] = ids
+		}
+	}
+	delete(ix.Messages, id)
+}
+
+// removeFromInbox drops a message that left the inbox (closed in beads),
+// keeping it if it was archived.
+func (ix *MailIndex) removeFromInbox(id string) {
+	if entry, ok := ix.Messages[id]; ok && !entry.Archived {
+		ix.Remove(id)
+	}
+}
+
+// Search returns the indexed messages matching q and accepted by keep
+// (nil keeps all), newest first.
+func (ix *MailIndex) Search(q *Query, keep func(*IndexEntry) bool) []*Message {
+	candidates := ix.candidates(q)
+
+	var matches []*Message
+	for id := range candidates {
+		entry := ix.Messages[id]
+		if entry == nil || (keep != nil && !keep(entry)) || !q.Matches(entry.Message) {
+			continue
+		}
+		matches = append(matches, entry.Message)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Timestamp.Equal(matches[j].Timestamp) {
+			return matches[i].ID < matches[j].ID
+		}
+		return matches[i].Timestamp.After(matches[j].Timestamp)
+	})
+	return matches
+}
+
+// candidates narrows the search with the posting lists: the messages that
+// contain every word (as a term prefix) and every phrase token.
+func (ix *MailIndex) candidates(q *Query) map[string]bool {
+	var result map[string]bool
+	intersect := func(ids map[string]bool) {
+		if result == nil {
+			result = ids
+			return
+		}
+		for id := range result {
+			if !ids[id] {
+				delete(result, id)
+			}
+		}
+	}
+
+	for _, word := range q.Words {
+		ids := make(map[string]bool)
+		for term, postings := range ix.Terms {
+			if strings.HasPrefix(term, word) {
+				for _, id := range postings {
+					ids[id] = true
+				}
+			}
+		}
+		intersect(ids)
+	}
+	for _, phrase := range q.Phrases {
+		for _, token := range phrase {
+			ids := make(map[string]bool)
+			for _, id := range ix.Terms[token] {
+				ids[id] = true
+			}
+			intersect(ids)
+		}
+	}
+
+	if result == nil {
+		result = make(map[string]bool, len(ix.Messages))
+		for id := range ix.Messages {
+			result[id] = true
+		}
+	}
+	return result
+}
+
+// RebuildIndex re-indexes the town's mail from beads (open and hooked
+// messages) and the archive file, and saves it. The index lock is held
+// throughout, so no update made meanwhile is lost when the rebuilt index
+// replaces the saved one.
+func RebuildIndex(workDir, beadsDir string) (*MailIndex, error) {
+	var ix *MailIndex
+	err := withIndexLock(beadsDir, func() error {
+		var err error
+		ix, err = buildIndex(workDir, beadsDir)
+		if err != nil {
+			return err
+		}
+		if err := ix.Save(); err != nil {
+			return fmt.Errorf("saving mail index: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// buildIndex indexes the town's mail from beads and the archive file.
+func buildIndex(workDir, beadsDir string) (*MailIndex, error) {
+	ix := newMailIndex(IndexPath(beadsDir))
+
+	for _, status := range []string{"open", "hooked"} {
+		args := []string{"list",
+			"--type", "message",
+			"--status", status,
+			"--json",
+			"--limit=0",
+		}
+		stdout, err := runBdCommand(args, workDir, beadsDir)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s messages: %w", status, err)
+		}
+		if len(stdout) == 0 || string(stdout) == "null" {
+			continue
+		}
+		var beadsMsgs []BeadsMessage
+		if err := json.Unmarshal(stdout, &beadsMsgs); err != nil {
+			return nil, fmt.Errorf("parsing %s messages: %w", status, err)
+		}
+		for i := range beadsMsgs {
+			ix.Add(beadsMsgs[i].ToMessage(), false)
+		}
+	}
+
+	archived, err := NewMailboxWithBeadsDir("", workDir, beadsDir).ListArchived()
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	for _, msg := range archived {
+		ix.Add(msg, true)
+	}
+
+	ix.BuiltAt = timeNow()
+	return ix, nil
+}
+
+// indexCreated adds a just-sent message to the mail index, using the bead
+// ID and creation time from bd create --json output. If the output can't
+// be parsed the message is left for the next rebuild.
+func indexCreated(msg *Message, bdOutput []byte, beadsDir string) {
+	var created BeadsMessage
+	if err := json.Unmarshal(bdOutput, &created); err != nil || created.ID == "" {
+		return
+	}
+	indexed := *msg
+	indexed.ID = created.ID
+	indexed.To = identityToAddress(addressToIdentity(msg.To))
+	indexed.Timestamp = created.CreatedAt
+	if indexed.Timestamp.IsZero() {
+		indexed.Timestamp = timeNow()
+	}
+	_ = updateIndex(beadsDir, indexOp{Op: opAdd, Message: &indexed})
+}
+
+// updateIndex appends ops to the index journal under the index lock.
+// Indexing is best-effort: callers ignore the error, and a never-built
+// index is left for the next search to build in full.
+func updateIndex(beadsDir string, ops ...indexOp) error {
+	if beadsDir == "" || len(ops) == 0 {
+		return nil
+	}
+	return withIndexLock(beadsDir, func() error {
+		if _, err := os.Stat(IndexPath(beadsDir)); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		var buf []byte
+		for _, op := range ops {
+			line, err := json.Marshal(op)
+			if err != nil {
+				return err
+			}
+			buf = append(append(buf, line...), '\n')
+		}
+		f, err := os.OpenFile(journalPath(beadsDir), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: mail index is town-local operational data
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(buf); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
+
+// withIndexLock runs fn holding the index's file lock.
+func withIndexLock(beadsDir string, fn func() error) error {
+	path := IndexPath(beadsDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking mail index: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
+// tokenize splits text into lowercase letter/digit runs.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// uniqueTerms returns the distinct subject and body terms of a message.
+func uniqueTerms(msg *Message) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, t := range append(tokenize(msg.Subject), tokenize(msg.Body)...) {
+		if !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// SearchTown searches every inbox and the archive in the town, for
+// overseer-level searches across all agents' mail.
+func (r *Router) SearchTown(opts SearchOptions) ([]*Message, error) {
+	q, err := opts.query()
+	if err != nil {
+		return nil, err
+	}
+	beadsDir := r.resolveBeadsDir("")
+	ix, err := openIndex(filepath.Dir(beadsDir), beadsDir, opts.Reindex)
+	if err != nil {
+		return nil, err
+	}
+	return ix.Search(q, nil), nil
+}
+
+// RefreshIndex rebuilds the town's mail index if it is missing or stale.
+// Reports whether it rebuilt.
+func (r *Router) RefreshIndex(now time.Time) (bool, error) {
+	beadsDir := r.resolveBeadsDir("")
+	if ix, err := LoadIndex(beadsDir); err == nil && !ix.Stale(now) {
+		return false, nil
+	}
+	if _, err := RebuildIndex(filepath.Dir(beadsDir), beadsDir); err != nil {
+		return false, err
+	}
+	return true, nil
+}
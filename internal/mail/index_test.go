@@ -0,0 +1,211 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func indexTestMessages() []*Message {
+	base := time.Date(2026, 1, 6, 10, 0, 0, 0, time.UTC)
+	return []*Message{
+		{ID: "hq-1", From: "gastown/refinery", To: "mayor/", Subject: "Merge ready: gt-abc", Body: "passed checks", Timestamp: base},
+		{ID: "hq-2", From: "gastown/Toast", To: "gastown/witness", Subject: "POLECAT_DONE Toast", Body: "merged gt-abc", Timestamp: base.Add(time.Hour)},
+		{ID: "hq-3", From: "deacon/", To: "mayor/", CC: []string{"gastown/witness"}, Subject: "Patrol report", Body: "all quiet", Timestamp: base.Add(2 * time.Hour)},
+	}
+}
+
+func searchIDs(t *testing.T, ix *MailIndex, query string) []string {
+	t.Helper()
+	q, err := ParseQuery(query)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q): %v", query, err)
+	}
+	var ids []string
+	for _, m := range ix.Search(q, nil) {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestMailIndexSearch(t *testing.T) {
+	ix := newMailIndex("")
+	for _, m := range indexTestMessages() {
+		ix.Add(m, false)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"gt-abc", []string{"hq-2", "hq-1"}},
+		{"merge", []string{"hq-2", "hq-1"}},
+		{`"all quiet"`, []string{"hq-3"}},
+		{"from:deacon", []string{"hq-3"}},
+		{"to:witness", []string{"hq-3", "hq-2"}},
+		{"nothing", nil},
+		{"", []string{"hq-3", "hq-2", "hq-1"}},
+	}
+	for _, tt := range tests {
+		got := searchIDs(t, ix, tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestMailIndexRemove(t *testing.T) {
+	ix := newMailIndex("")
+	for _, m := range indexTestMessages() {
+		ix.Add(m, false)
+	}
+
+	ix.Remove("hq-1")
+	if got := searchIDs(t, ix, "gt-abc"); len(got) != 1 || got[0] != "hq-2" {
+		t.Errorf("after Remove, Search = %v, want [hq-2]", got)
+	}
+	if _, ok := ix.Terms["checks"]; ok {
+		t.Error("term 'checks' should be dropped with its only message")
+	}
+
+	// Archived entries survive leaving the inbox
+	ix.Add(indexTestMessages()[0], true)
+	ix.removeFromInbox("hq-1")
+	ix.removeFromInbox("hq-2")
+	if got := searchIDs(t, ix, "gt-abc"); len(got) != 1 || got[0] != "hq-1" {
+		t.Errorf("after removeFromInbox, Search = %v, want [hq-1]", got)
+	}
+}
+
+func TestMailIndexPersistence(t *testing.T) {
+	townRoot := t.TempDir()
+	beadsDir := filepath.Join(townRoot, ".beads")
+
+	ix, err := LoadIndex(beadsDir)
+	if err != nil {
+		t.Fatalf("LoadIndex: %v", err)
+	}
+	if !ix.Stale(time.Now()) {
+		t.Error("missing index should be stale")
+	}
+
+	// Updates are skipped until the index has been built once
+	if err := updateIndex(beadsDir, indexOp{Op: opAdd, Message: indexTestMessages()[0]}); err != nil {
+		t.Fatalf("updateIndex: %v", err)
+	}
+	if ix, _ := LoadIndex(beadsDir); len(ix.Messages) != 0 {
+		t.Errorf("unbuilt index got %d messages, want 0", len(ix.Messages))
+	}
+
+	ix.BuiltAt = time.Now()
+	if err := ix.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	snapshot, err := os.ReadFile(IndexPath(beadsDir))
+	if err != nil {
+		t.Fatalf("reading index: %v", err)
+	}
+	for _, m := range indexTestMessages() {
+		if err := updateIndex(beadsDir, indexOp{Op: opAdd, Message: m}); err != nil {
+			t.Fatalf("updateIndex: %v", err)
+		}
+	}
+	if err := updateIndex(beadsDir, indexOp{Op: opRead, ID: "hq-2", Read: true}, indexOp{Op: opClose, ID: "hq-3"}); err != nil {
+		t.Fatalf("updateIndex: %v", err)
+	}
+
+	// Updates go to the journal; the saved index is not rewritten
+	if data, _ := os.ReadFile(IndexPath(beadsDir)); string(data) != string(snapshot) {
+		t.Error("updateIndex rewrote the saved index")
+	}
+
+	loaded, err := LoadIndex(beadsDir)
+	if err != nil {
+		t.Fatalf("LoadIndex: %v", err)
+	}
+	if loaded.Stale(time.Now()) {
+		t.Error("freshly built index should not be stale")
+	}
+	if got := searchIDs(t, loaded, "merge"); len(got) != 2 {
+		t.Errorf("Search after reload = %v, want 2 messages", got)
+	}
+	if e := loaded.Messages["hq-2"]; e == nil || !e.Message.Read {
+		t.Error("journaled read state not replayed")
+	}
+	if _, ok := loaded.Messages["hq-3"]; ok {
+		t.Error("journaled close not replayed")
+	}
+
+	// Saving folds the journal into the index
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(journalPath(beadsDir)); !os.IsNotExist(err) {
+		t.Errorf("journal still present after Save: %v", err)
+	}
+	if reloaded, _ := LoadIndex(beadsDir); len(reloaded.Messages) != 2 {
+		t.Errorf("index after Save has %d messages, want 2", len(reloaded.Messages))
+	}
+	if IndexPath(beadsDir) != filepath.Join(townRoot, ".runtime", "mail-index.json") {
+		t.Errorf("IndexPath = %s", IndexPath(beadsDir))
+	}
+}
+
+func TestMailboxSearchUsesIndex(t *testing.T) {
+	townRoot := t.TempDir()
+	beadsDir := filepath.Join(townRoot, ".beads")
+
+	ix := newMailIndex(IndexPath(beadsDir))
+	for _, m := range indexTestMessages() {
+		ix.Add(m, false)
+	}
+	ix.BuiltAt = time.Now()
+	if err := ix.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// Only mail addressed or CC'd to the witness
+	mailbox := NewMailboxWithBeadsDir("gastown/witness", townRoot, beadsDir)
+	found, err := mailbox.Search(SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != "hq-3" || found[1].ID != "hq-2" {
+		t.Errorf("witness Search = %v, want [hq-3 hq-2]", found)
+	}
+
+	// Town-wide search sees every mailbox
+	router := NewRouterWithTownRoot(townRoot, townRoot)
+	all, err := router.SearchTown(SearchOptions{Query: "gt-abc"})
+	if err != nil {
+		t.Fatalf("SearchTown: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("SearchTown = %d messages, want 2", len(all))
+	}
+}
+
+func TestLegacyMailboxSearch(t *testing.T) {
+	mailbox := NewMailbox(t.TempDir())
+	for _, m := range indexTestMessages() {
+		if err := mailbox.Append(m); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	found, err := mailbox.Search(SearchOptions{Query: "toast", SubjectOnly: true})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != "hq-2" {
+		t.Errorf("Search = %v, want [hq-2]", found)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"time"
 
@@ -99,6 +98,9 @@ func (m *Mailbox) listBeads() ([]*Message, error) {
 		return nil, err
 	}
 
+	// Hide expired mail; the daemon archives it (see Router.ArchiveExpired)
+	messages = withoutExpired(messages, timeNow())
+
 	// Sort by timestamp (newest first)
 	sort.Slice(messages, func(i, j int) bool {
 		return messages[i].Timestamp.After(messages[j].Timestamp)
@@ -248,6 +250,17 @@ func (m *Mailbox) listLegacy() ([]*Message, error) {
 	return messages, nil
 }
 
+// withoutExpired filters out unpinned messages whose expiry has passed.
+func withoutExpired(messages []*Message, now time.Time) []*Message {
+	live := messages[:0]
+	for _, msg := range messages {
+		if msg.Pinned || !msg.IsExpired(now) {
+			live = append(live, msg)
+		}
+	}
+	return live
+}
+
 // ListUnread returns unread (open) messages.
 func (m *Mailbox) ListUnread() ([]*Message, error) {
 	if m.legacy {
@@ -256,7 +269,7 @@ func (m *Mailbox) ListUnread() ([]*Message, error) {
 			return nil, err
 		}
 		var unread []*Message
-		for _, msg := range all {
+		for _, msg := range withoutExpired(all, timeNow()) {
 			if !msg.Read {
 				unread = append(unread, msg)
 			}
@@ -347,6 +360,9 @@ func (m *Mailbox) closeInDir(id, beadsDir string) error {
 		return err
 	}
 
+	// Closed messages leave the inbox (archived copies stay searchable)
+	_ = updateIndex(beadsDir, indexOp{Op: opClose, ID: id})
+
 	return nil
 }
 
@@ -394,6 +410,7 @@ func (m *Mailbox) markReadOnlyBeads(id string) error {
 		return err
 	}
 
+	m.indexSetRead(id, true)
 	return nil
 }
 
@@ -423,9 +440,15 @@ func (m *Mailbox) markUnreadOnlyBeads(id string) error {
 		return err
 	}
 
+	m.indexSetRead(id, false)
 	return nil
 }
 
+// indexSetRead updates a message's read state in the mail index.
+func (m *Mailbox) indexSetRead(id string, read bool) {
+	_ = updateIndex(m.beadsDir, indexOp{Op: opRead, ID: id, Read: read})
+}
+
 // MarkUnread marks a message as unread (reopens in beads).
 func (m *Mailbox) MarkUnread(id string) error {
 	if m.legacy {
@@ -547,8 +570,14 @@ func (m *Mailbox) appendToArchive(msg *Message) error {
 		return err
 	}
 
-	_, err = file.WriteString(string(data) + "\n")
-	return err
+	if _, err := file.WriteString(string(data) + "\n"); err != nil {
+		return err
+	}
+
+	if !m.legacy {
+		_ = updateIndex(m.beadsDir, indexOp{Op: opAdd, Message: msg, Archived: true})
+	}
+	return nil
 }
 
 // ListArchived returns all messages in the archive file.
@@ -603,17 +632,17 @@ func (m *Mailbox) PurgeArchive(olderThanDays int) (int, error) {
 		if err := os.Remove(m.ArchivePath()); err != nil && !os.IsNotExist(err) {
 			return 0, err
 		}
+		m.unindexArchived(messages)
 		return len(messages), nil
 	}
 
 	// Filter by age
 	cutoff := timeNow().AddDate(0, 0, -olderThanDays)
-	var keep []*Message
-	purged := 0
+	var keep, purged []*Message
 
 	for _, msg := range messages {
 		if msg.Timestamp.Before(cutoff) {
-			purged++
+			purged = append(purged, msg)
 		} else {
 			keep = append(keep, msg)
 		}
@@ -630,7 +659,20 @@ func (m *Mailbox) PurgeArchive(olderThanDays int) (int, error) {
 		}
 	}
 
-	return purged, nil
+	m.unindexArchived(purged)
+	return len(purged), nil
+}
+
+// unindexArchived drops purged archive messages from the mail index.
+func (m *Mailbox) unindexArchived(messages []*Message) {
+	if m.legacy || len(messages) == 0 {
+		return
+	}
+	ops := make([]indexOp, 0, len(messages))
+	for _, msg := range messages {
+		ops = append(ops, indexOp{Op: opPurge, ID: msg.ID})
+	}
+	_ = updateIndex(m.beadsDir, ops...)
 }
 
 func (m *Mailbox) rewriteArchive(messages []*Message) error {
@@ -662,75 +704,96 @@ func (m *Mailbox) rewriteArchive(messages []*Message) error {
 
 // SearchOptions specifies search parameters.
 type SearchOptions struct {
-	Query       string // Regex pattern to search for
-	FromFilter  string // Optional: only match messages from this sender
+	Query       string // Search query; see Query for the syntax (no regex, so no ReDoS)
+	FromFilter  string // Optional: only match messages from this sender (substring)
 	SubjectOnly bool   // Only search subject
 	BodyOnly    bool   // Only search body
+	Reindex     bool   // Rebuild the mail index before searching
+}
+
+// query parses the options into a Query.
+func (opts SearchOptions) query() (*Query, error) {
+	q, err := ParseQuery(opts.Query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search query: %w", err)
+	}
+	if opts.FromFilter != "" {
+		q.From = opts.FromFilter
+	}
+	q.SubjectOnly = opts.SubjectOnly
+	q.BodyOnly = opts.BodyOnly
+	return q, nil
 }
 
-// Search finds messages matching the given criteria.
-// Returns messages from both inbox and archive.
-// Query and FromFilter are treated as literal strings (not regex) to prevent ReDoS.
+// Search finds messages in this mailbox's inbox and archive matching the
+// given criteria, newest first. Beads mailboxes search the town's mail
+// index (see MailIndex); legacy mailboxes are scanned.
 func (m *Mailbox) Search(opts SearchOptions) ([]*Message, error) {
-	// Use QuoteMeta to escape special regex chars - prevents ReDoS attacks
-	// and provides intuitive literal string matching for users
-	re, err := regexp.Compile("(?i)" + regexp.QuoteMeta(opts.Query))
+	q, err := opts.query()
 	if err != nil {
-		return nil, fmt.Errorf("invalid search pattern: %w", err)
+		return nil, err
 	}
 
-	var fromRe *regexp.Regexp
-	if opts.FromFilter != "" {
-		fromRe, err = regexp.Compile("(?i)" + regexp.QuoteMeta(opts.FromFilter))
+	if m.legacy {
+		inbox, err := m.List()
 		if err != nil {
-			return nil, fmt.Errorf("invalid from pattern: %w", err)
+			return nil, err
 		}
+		archived, err := m.ListArchived()
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		ix := newMailIndex("")
+		for _, msg := range inbox {
+			ix.Add(msg, false)
+		}
+		for _, msg := range archived {
+			ix.Add(msg, true)
+		}
+		return ix.Search(q, nil), nil
 	}
 
-	// Get inbox messages
-	inbox, err := m.List()
+	beadsDir := m.beadsDir
+	if beadsDir == "" {
+		beadsDir = filepath.Join(m.workDir, ".beads")
+	}
+	ix, err := openIndex(m.workDir, beadsDir, opts.Reindex)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get archived messages
-	archived, err := m.ListArchived()
-	if err != nil && !os.IsNotExist(err) {
-		return nil, err
+	identities := make(map[string]bool)
+	for _, id := range m.identityVariants() {
+		identities[addressToIdentity(id)] = true
 	}
-
-	// Combine and search
-	all := append(inbox, archived...)
-	var matches []*Message
-
-	for _, msg := range all {
-		// Apply from filter
-		if fromRe != nil && !fromRe.MatchString(msg.From) {
-			continue
+	return ix.Search(q, func(e *IndexEntry) bool {
+		if identities[addressToIdentity(e.Message.To)] {
+			return true
 		}
-
-		// Search in specified fields
-		matched := false
-		if opts.SubjectOnly {
-			matched = re.MatchString(msg.Subject)
-		} else if opts.BodyOnly {
-			matched = re.MatchString(msg.Body)
-		} else {
-			// Search in both subject and body
-			matched = re.MatchString(msg.Subject) || re.MatchString(msg.Body)
+		for _, cc := range e.Message.CC {
+			if identities[addressToIdentity(cc)] {
+				return true
+			}
 		}
+		return false
+	}), nil
+}
 
-		if matched {
-			matches = append(matches, msg)
+// openIndex loads the mail index, rebuilding it when forced, missing or
+// stale. If a rebuild fails, a previously built index is still used.
+func openIndex(workDir, beadsDir string, rebuild bool) (*MailIndex, error) {
+	ix, err := LoadIndex(beadsDir)
+	if err == nil && !rebuild && !ix.Stale(timeNow()) {
+		return ix, nil
+	}
+	fresh, rebuildErr := RebuildIndex(workDir, beadsDir)
+	if rebuildErr != nil {
+		if err == nil && !ix.BuiltAt.IsZero() {
+			return ix, nil
 		}
+		return nil, fmt.Errorf("building mail index: %w", rebuildErr)
 	}
-
-	// Sort by timestamp (newest first)
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].Timestamp.After(matches[j].Timestamp)
-	})
-
-	return matches, nil
+	return fresh, nil
 }
 
 // Count returns the total and unread message counts.
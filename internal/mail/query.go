@@ -0,0 +1,232 @@
+package mail
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a parsed mail search query.
+//
+// Syntax (all parts must match):
+//
+//	word           a subject/body term, matched as a prefix ("merg" finds "merged")
+//	"two words"    a phrase: the terms in order, adjacent
+//	gt-abc         punctuated words are phrases of their terms
+//	from:<addr>    sender contains addr (case-insensitive)
+//	to:<addr>      recipient or CC contains addr
+//	thread:<id>    thread ID
+//	type:<type>    task, scavenge, notification or reply
+//	after:<date>   sent at or after date (2006-01-02 or RFC3339)
+//	before:<date>  sent before date
+type Query struct {
+	Words   []string
+	Phrases [][]string
+
+	From   string
+	To     string
+	Thread string
+	Type   MessageType
+	After  time.Time
+	Before time.Time
+
+	// SubjectOnly and BodyOnly restrict words and phrases to one field.
+	SubjectOnly bool
+	BodyOnly    bool
+}
+
+// ParseQuery parses the mail search syntax described on Query.
+func ParseQuery(s string) (*Query, error) {
+	q := &Query{}
+	for _, part := range splitQuery(s) {
+		if part.quoted {
+			if tokens := tokenize(part.text); len(tokens) > 0 {
+				q.Phrases = append(q.Phrases, tokens)
+			}
+			continue
+		}
+
+		if field, value, ok := strings.Cut(part.text, ":"); ok && value != "" {
+			handled, err := q.setField(strings.ToLower(field), value)
+			if err != nil {
+				return nil, err
+			}
+			if handled {
+				continue
+			}
+		}
+
+		switch tokens := tokenize(part.text); len(tokens) {
+		case 0:
+		case 1:
+			q.Words = append(q.Words, tokens[0])
+		default:
+			q.Phrases = append(q.Phrases, tokens)
+		}
+	}
+	return q, nil
+}
+
+// setField applies a field:value query part. Unknown fields are not
+// handled, so "re:status" still searches as text.
+func (q *Query) setField(field, value string) (bool, error) {
+	switch field {
+	case "from":
+		q.From = value
+	case "to":
+		q.To = value
+	case "thread":
+		q.Thread = value
+	case "type":
+		t := MessageType(strings.ToLower(value))
+		if ParseMessageType(string(t)) != t {
+			return false, fmt.Errorf("invalid type %q (valid: task, scavenge, notification, reply)", value)
+		}
+		q.Type = t
+	case "after", "before":
+		t, err := parseQueryTime(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s date %q: want 2006-01-02 or RFC3339", field, value)
+		}
+		if field == "after" {
+			q.After = t
+		} else {
+			q.Before = t
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// Matches reports whether msg satisfies every part of the query.
+func (q *Query) Matches(msg *Message) bool {
+	if q.From != "" && !containsFold(msg.From, q.From) {
+		return false
+	}
+	if q.To != "" {
+		found := containsFold(msg.To, q.To)
+		for _, cc := range msg.CC {
+			found = found || containsFold(cc, q.To)
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.Thread != "" && msg.ThreadID != q.Thread {
+		return false
+	}
+	if q.Type != "" && msg.Type != q.Type {
+		return false
+	}
+	if !q.After.IsZero() && msg.Timestamp.Before(q.After) {
+		return false
+	}
+	if !q.Before.IsZero() && !msg.Timestamp.Before(q.Before) {
+		return false
+	}
+
+	if len(q.Words) == 0 && len(q.Phrases) == 0 {
+		return true
+	}
+	var fields [][]string
+	if !q.BodyOnly {
+		fields = append(fields, tokenize(msg.Subject))
+	}
+	if !q.SubjectOnly {
+		fields = append(fields, tokenize(msg.Body))
+	}
+	for _, word := range q.Words {
+		if !anyField(fields, func(tokens []string) bool { return hasPrefixToken(tokens, word) }) {
+			return false
+		}
+	}
+	for _, phrase := range q.Phrases {
+		if !anyField(fields, func(tokens []string) bool { return hasPhrase(tokens, phrase) }) {
+			return false
+		}
+	}
+	return true
+}
+
+type queryPart struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits on whitespace, keeping "quoted phrases" together.
+// A quote after a field name quotes the value: from:"gastown/witness".
+func splitQuery(s string) []queryPart {
+	var parts []queryPart
+	var cur strings.Builder
+	inQuote, phrase := false, false
+	flush := func() {
+		if cur.Len() > 0 {
+			parts = append(parts, queryPart{text: cur.String(), quoted: phrase})
+		}
+		cur.Reset()
+		phrase = false
+	}
+	for _, r := range s {
+		switch {
+		case r == '"':
+			if !inQuote && cur.Len() == 0 {
+				phrase = true // a quote opening a part makes it a phrase
+			}
+			inQuote = !inQuote
+		case unicode.IsSpace(r) && !inQuote:
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return parts
+}
+
+func parseQueryTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func anyField(fields [][]string, fn func([]string) bool) bool {
+	for _, f := range fields {
+		if fn(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefixToken(tokens []string, prefix string) bool {
+	for _, t := range tokens {
+		if strings.HasPrefix(t, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPhrase reports whether phrase appears as adjacent tokens.
+func hasPhrase(tokens, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		match := true
+		for j, p := range phrase {
+			if tokens[i+j] != p {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package mail
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *Query
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: &Query{}},
+		{name: "words", input: "Merge  Ready", want: &Query{Words: []string{"merge", "ready"}}},
+		{name: "phrase", input: `"merge ready" now`, want: &Query{Words: []string{"now"}, Phrases: [][]string{{"merge", "ready"}}}},
+		{name: "punctuated word is a phrase", input: "gt-abc12", want: &Query{Phrases: [][]string{{"gt", "abc12"}}}},
+		{
+			name:  "fields",
+			input: `from:mayor/ to:"gastown/witness" thread:thread-1 type:task status`,
+			want:  &Query{Words: []string{"status"}, From: "mayor/", To: "gastown/witness", Thread: "thread-1", Type: TypeTask},
+		},
+		{
+			name:  "dates",
+			input: "after:2026-01-05 before:2026-01-07T12:00:00Z",
+			want: &Query{
+				After:  time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local),
+				Before: time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{name: "unknown field is text", input: "re:status", want: &Query{Phrases: [][]string{{"re", "status"}}}},
+		{name: "bad type", input: "type:memo", wantErr: true},
+		{name: "bad date", input: "after:yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuery(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseQuery(%q) = %+v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuery(%q): %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	sent := time.Date(2026, 1, 6, 10, 0, 0, 0, time.UTC)
+	msg := &Message{
+		ID:        "hq-1",
+		From:      "gastown/refinery",
+		To:        "mayor/",
+		CC:        []string{"gastown/witness"},
+		Subject:   "Merge ready: gt-abc",
+		Body:      "Branch polecat/Toast passed all checks.",
+		Type:      TypeNotification,
+		ThreadID:  "thread-9",
+		Timestamp: sent,
+	}
+
+	tests := []struct {
+		query       string
+		subjectOnly bool
+		bodyOnly    bool
+		want        bool
+	}{
+		{query: "", want: true},
+		{query: "merg", want: true},
+		{query: "MERGE checks", want: true},
+		{query: `"merge ready"`, want: true},
+		{query: `"ready merge"`, want: false},
+		{query: "gt-abc", want: true},
+		{query: "gt-abd", want: false},
+		{query: "from:refinery", want: true},
+		{query: "from:witness", want: false},
+		{query: "to:witness", want: true},
+		{query: "thread:thread-9 type:notification", want: true},
+		{query: "type:task", want: false},
+		{query: "after:2026-01-06T00:00:00Z before:2026-01-07T00:00:00Z", want: true},
+		{query: "before:2026-01-06T10:00:00Z", want: false},
+		{query: "checks", subjectOnly: true, want: false},
+		{query: "checks", bodyOnly: true, want: true},
+		{query: "merge", bodyOnly: true, want: false},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+		}
+		q.SubjectOnly, q.BodyOnly = tt.subjectOnly, tt.bodyOnly
+		if got := q.Matches(msg); got != tt.want {
+			t.Errorf("Matches(%q, subject=%v, body=%v) = %v, want %v", tt.query, tt.subjectOnly, tt.bodyOnly, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dnd"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -499,7 +500,13 @@ func (r *Router) shouldBeWisp(msg *Message) bool {
 // Supports single-copy delivery for:
 // - Queues (queue:name) - stores single message for worker claiming
 // - Announces (announce:name) - bulletin board, no claiming, retention-limited
+// Messages with a future DeliverAt are held and delivered later by the daemon.
 func (r *Router) Send(msg *Message) error {
+	// Deferred delivery - hold until due (see DeliverDue)
+	if msg.DeliverAt != nil && msg.DeliverAt.After(timeNow()) {
+		return r.schedule(msg)
+	}
+
 	// Check for mailing list address
 	if isListAddress(msg.To) {
 		return r.sendToList(msg)
@@ -561,10 +568,22 @@ func (r *Router) sendToGroup(msg *Message) error {
 
 // sendToSingle sends a message to a single recipient.
 func (r *Router) sendToSingle(msg *Message) error {
+	// Apply the recipient's mail rules to a private copy; they may archive,
+	// forward or convert the message to a nudge instead of mailing it
+	ruled := *msg
+	msg = &ruled
+	handled, err := r.applyRules(msg)
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
 	// Convert addresses to beads identities
 	toIdentity := addressToIdentity(msg.To)
 
-	// Build labels for from/thread/reply-to/cc
+	// Build labels for from/thread/reply-to/cc/expires
 	var labels []string
 	labels = append(labels, "from:"+msg.From)
 	if msg.ThreadID != "" {
@@ -578,6 +597,12 @@ func (r *Router) sendToSingle(msg *Message) error {
 		ccIdentity := addressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	if msg.ExpiresAt != nil {
+		labels = append(labels, expiresLabel(*msg.ExpiresAt))
+	}
+	if msg.Read {
+		labels = append(labels, "read")
+	}
 
 	// Build command: bd create <subject> --type=message --assignee=<recipient> -d <body>
 	args := []string{"create", msg.Subject,
@@ -604,10 +629,11 @@ func (r *Router) sendToSingle(msg *Message) error {
 	}
 
 	beadsDir := r.resolveBeadsDir(msg.To)
-	_, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
+	out, err := runBdCommand(append(args, "--json"), filepath.Dir(beadsDir), beadsDir)
 	if err != nil {
 		return fmt.Errorf("sending message: %w", err)
 	}
+	indexCreated(msg, out, beadsDir)
 
 	// Notify recipient if they have an active session (best-effort notification)
 	// Skip notification for self-mail (handoffs to future-self don't need present-self notified)
@@ -688,6 +714,9 @@ func (r *Router) sendToQueue(msg *Message) error {
 		ccIdentity := addressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	if msg.ExpiresAt != nil {
+		labels = append(labels, expiresLabel(*msg.ExpiresAt))
+	}
 
 	// Build command: bd create <subject> --type=message --assignee=queue:<name> -d <body>
 	// Use queue:<name> as assignee so inbox queries can filter by queue
@@ -714,10 +743,11 @@ func (r *Router) sendToQueue(msg *Message) error {
 
 	// Queue messages go to town-level beads (shared location)
 	beadsDir := r.resolveBeadsDir("")
-	_, err = runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
+	out, err := runBdCommand(append(args, "--json"), filepath.Dir(beadsDir), beadsDir)
 	if err != nil {
 		return fmt.Errorf("sending to queue %s: %w", queueName, err)
 	}
+	indexCreated(msg, out, beadsDir)
 
 	// No notification for queue messages - workers poll or check on their own schedule
 
@@ -759,6 +789,9 @@ func (r *Router) sendToAnnounce(msg *Message) error {
 		ccIdentity := addressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	if msg.ExpiresAt != nil {
+		labels = append(labels, expiresLabel(*msg.ExpiresAt))
+	}
 
 	// Build command: bd create <subject> --type=message --assignee=announce:<name> -d <body>
 	// Use announce:<name> as assignee so queries can filter by channel
@@ -785,10 +818,11 @@ func (r *Router) sendToAnnounce(msg *Message) error {
 
 	// Announce messages go to town-level beads (shared location)
 	beadsDir := r.resolveBeadsDir("")
-	_, err = runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
+	out, err := runBdCommand(append(args, "--json"), filepath.Dir(beadsDir), beadsDir)
 	if err != nil {
 		return fmt.Errorf("sending to announce %s: %w", announceName, err)
 	}
+	indexCreated(msg, out, beadsDir)
 
 	// No notification for announce messages - readers poll or check on their own schedule
 
@@ -879,6 +913,13 @@ func (r *Router) notifyRecipient(msg *Message) error {
 
 	// Send notification to the agent's conversation history
 	notification := fmt.Sprintf("📬 You have new mail from %s. Subject: %s. Run 'gt mail inbox' to read.", msg.From, msg.Subject)
+
+	// Quiet policies hold all but urgent mail notifications for a digest
+	if msg.Priority != PriorityUrgent {
+		if decision := dnd.Check(r.townRoot, msg.To, timeNow()); decision.Quiet {
+			return dnd.Hold(r.townRoot, dnd.HeldNudge{Address: msg.To, Session: sessionID, Message: notification})
+		}
+	}
 	return r.tmux.NudgeSession(sessionID, notification)
 }
 
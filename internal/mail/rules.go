@@ -0,0 +1,259 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dnd"
+)
+
+// RuleMatch is a mail rule that matched a message, with the recipient
+// pattern it was configured under.
+type RuleMatch struct {
+	Pattern string          `json:"pattern"`
+	Index   int             `json:"index"`
+	Rule    config.MailRule `json:"rule"`
+}
+
+// Label returns the rule's name, or its position when unnamed.
+func (m RuleMatch) Label() string {
+	if m.Rule.Name != "" {
+		return m.Rule.Name
+	}
+	return fmt.Sprintf("%s[%d]", m.Pattern, m.Index)
+}
+
+// RuleOutcome is the combined effect of the mail rules matching a message.
+type RuleOutcome struct {
+	Matched  []RuleMatch `json:"matched"`
+	Archive  bool        `json:"archive,omitempty"`
+	MarkRead bool        `json:"mark_read,omitempty"`
+	Nudge    bool        `json:"nudge,omitempty"`
+	Forward  []string    `json:"forward,omitempty"`
+	Priority Priority    `json:"priority,omitempty"` // "" = unchanged
+}
+
+// Empty reports whether no rule changes delivery.
+func (o *RuleOutcome) Empty() bool {
+	return !o.Archive && !o.MarkRead && !o.Nudge && len(o.Forward) == 0 && o.Priority == ""
+}
+
+// EvaluateRules applies the messaging config's rules for msg.To to msg.
+// Rule groups for exact addresses run before wildcard patterns; within a
+// group rules run in order. Every matching rule contributes its actions
+// until one with Stop matches.
+func EvaluateRules(cfg *config.MessagingConfig, msg *Message) *RuleOutcome {
+	outcome := &RuleOutcome{}
+	for _, rm := range RulesFor(cfg, msg.To) {
+		rule := rm.Rule
+		if !ruleMatches(rule.Match, msg) {
+			continue
+		}
+		outcome.Matched = append(outcome.Matched, rm)
+		for _, action := range rule.Actions {
+			name, arg, _ := strings.Cut(action, ":")
+			switch name {
+			case "archive":
+				outcome.Archive = true
+			case "mark-read":
+				outcome.MarkRead = true
+			case "nudge":
+				outcome.Nudge = true
+			case "forward":
+				outcome.Forward = append(outcome.Forward, arg)
+			case "priority":
+				outcome.Priority = ParsePriority(arg)
+			}
+		}
+		if rule.Stop {
+			break
+		}
+	}
+	return outcome
+}
+
+// RulesFor returns the rules that apply to a recipient address, in
+// evaluation order.
+func RulesFor(cfg *config.MessagingConfig, address string) []RuleMatch {
+	if cfg == nil {
+		return nil
+	}
+	var rules []RuleMatch
+	for _, pattern := range rulePatternsFor(cfg, address) {
+		for i, rule := range cfg.Rules[pattern] {
+			rules = append(rules, RuleMatch{Pattern: pattern, Index: i, Rule: rule})
+		}
+	}
+	return rules
+}
+
+// rulePatternsFor returns the rule keys matching a recipient address,
+// exact addresses first, then patterns in sorted order.
+func rulePatternsFor(cfg *config.MessagingConfig, address string) []string {
+	var exact, wild []string
+	for pattern := range cfg.Rules {
+		if !matchAddressPattern(pattern, address) {
+			continue
+		}
+		if strings.ContainsAny(pattern, "*?[") {
+			wild = append(wild, pattern)
+		} else {
+			exact = append(exact, pattern)
+		}
+	}
+	sort.Strings(exact)
+	sort.Strings(wild)
+	return append(exact, wild...)
+}
+
+// matchAddressPattern reports whether address matches a rule address pattern.
+// Both sides are normalized ("mayor" = "mayor/", crew/ and polecats/ dropped);
+// "*" matches one path segment, and a bare "*" matches every address.
+func matchAddressPattern(pattern, address string) bool {
+	if pattern == "*" {
+		return true
+	}
+	p := strings.TrimSuffix(addressToIdentity(pattern), "/")
+	a := strings.TrimSuffix(addressToIdentity(address), "/")
+	ok, err := path.Match(p, a)
+	return err == nil && ok
+}
+
+// ruleMatches reports whether every condition set in m holds for msg.
+func ruleMatches(m config.MailRuleMatch, msg *Message) bool {
+	if m.From != "" && !matchAddressPattern(m.From, msg.From) {
+		return false
+	}
+	if m.Subject != "" {
+		re, err := regexp.Compile(m.Subject)
+		if err != nil || !re.MatchString(msg.Subject) {
+			return false
+		}
+	}
+	if m.Type != "" && MessageType(m.Type) != msg.Type {
+		return false
+	}
+	if m.Priority != "" && Priority(m.Priority) != msg.Priority {
+		return false
+	}
+	if m.Thread != "" && m.Thread != msg.ThreadID {
+		return false
+	}
+	return true
+}
+
+// loadRulesConfig loads the messaging config for rule evaluation.
+// A town without messaging.json has no rules.
+func (r *Router) loadRulesConfig() (*config.MessagingConfig, error) {
+	if r.townRoot == "" {
+		return nil, nil
+	}
+	cfg, err := config.LoadMessagingConfig(config.MessagingConfigPath(r.townRoot))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("loading messaging config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Rules returns the mail rules that apply to a recipient address.
+func (r *Router) Rules(address string) ([]RuleMatch, error) {
+	cfg, err := r.loadRulesConfig()
+	if err != nil {
+		return nil, err
+	}
+	return RulesFor(cfg, address), nil
+}
+
+// EvaluateRules returns what the recipient's mail rules would do with msg,
+// without delivering it.
+func (r *Router) EvaluateRules(msg *Message) (*RuleOutcome, error) {
+	cfg, err := r.loadRulesConfig()
+	if err != nil {
+		return nil, err
+	}
+	return EvaluateRules(cfg, msg), nil
+}
+
+// applyRules runs the recipient's mail rules before msg is stored.
+// It may re-prioritize or mark msg read, forwards copies (best-effort, like
+// notifications), and reports whether delivery is already complete
+// (archived or converted to a nudge). A broken messaging config never
+// blocks delivery.
+func (r *Router) applyRules(msg *Message) (handled bool, err error) {
+	if msg.skipRules {
+		return false, nil
+	}
+	outcome, err := r.EvaluateRules(msg)
+	if err != nil || outcome.Empty() {
+		return false, nil
+	}
+
+	if outcome.Priority != "" {
+		msg.Priority = outcome.Priority
+	}
+	if outcome.MarkRead {
+		msg.Read = true
+	}
+
+	// Forwarded copies skip rules so forwarding can't loop
+	for _, target := range outcome.Forward {
+		fwd := *msg
+		fwd.To = target
+		fwd.CC = nil
+		fwd.Read = false
+		fwd.skipRules = true
+		_ = r.Send(&fwd)
+	}
+
+	if outcome.Nudge && !outcome.Archive && r.nudgeInsteadOfMail(msg) {
+		return true, nil
+	}
+
+	if outcome.Archive {
+		if msg.ID == "" {
+			msg.ID = generateID()
+		}
+		if msg.Timestamp.IsZero() {
+			msg.Timestamp = timeNow()
+		}
+		beadsDir := r.resolveBeadsDir(msg.To)
+		mailbox := NewMailboxWithBeadsDir(msg.To, filepath.Dir(beadsDir), beadsDir)
+		if err := mailbox.appendToArchive(msg); err != nil {
+			return false, fmt.Errorf("archiving by mail rule: %w", err)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// nudgeInsteadOfMail delivers msg as a nudge to the recipient's session.
+// Returns false (deliver as mail) if the session isn't running or the
+// recipient is in a quiet window.
+func (r *Router) nudgeInsteadOfMail(msg *Message) bool {
+	sessionID := addressToSessionID(msg.To)
+	if sessionID == "" {
+		return false
+	}
+	// During a quiet window the mail is kept; its notification is held
+	if msg.Priority != PriorityUrgent && dnd.Check(r.townRoot, msg.To, timeNow()).Quiet {
+		return false
+	}
+	if hasSession, err := r.tmux.HasSession(sessionID); err != nil || !hasSession {
+		return false
+	}
+	text := fmt.Sprintf("📬 From %s: %s", msg.From, msg.Subject)
+	if msg.Body != "" {
+		text += " — " + msg.Body
+	}
+	return r.tmux.NudgeSession(sessionID, text) == nil
+}
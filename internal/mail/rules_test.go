@@ -0,0 +1,128 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestMatchAddressPattern(t *testing.T) {
+	tests := []struct {
+		pattern, address string
+		want             bool
+	}{
+		{"mayor/", "mayor/", true},
+		{"mayor/", "mayor", true},
+		{"mayor", "mayor/", true},
+		{"*/witness", "gastown/witness", true},
+		{"*/witness", "gastown/refinery", false},
+		{"gastown/*", "gastown/Toast", true},
+		{"gastown/*", "gastown/polecats/Toast", true},
+		{"gastown/*", "beads/Toast", false},
+		{"*", "gastown/Toast", true},
+		{"deacon/", "mayor/", false},
+	}
+	for _, tt := range tests {
+		if got := matchAddressPattern(tt.pattern, tt.address); got != tt.want {
+			t.Errorf("matchAddressPattern(%q, %q) = %v, want %v", tt.pattern, tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	cfg := &config.MessagingConfig{
+		Rules: map[string][]config.MailRule{
+			"*/witness": {
+				{Name: "done-noise", Match: config.MailRuleMatch{Subject: "^POLECAT_DONE"}, Actions: []string{"mark-read"}},
+				{Name: "urgent-fwd", Match: config.MailRuleMatch{Priority: "urgent"}, Actions: []string{"forward:mayor/"}},
+			},
+			"gastown/witness": {
+				{Name: "refinery", Match: config.MailRuleMatch{From: "*/refinery", Type: "notification"}, Actions: []string{"archive"}, Stop: true},
+				{Name: "boost", Match: config.MailRuleMatch{From: "gastown/*"}, Actions: []string{"priority:high"}},
+			},
+		},
+	}
+
+	t.Run("no match", func(t *testing.T) {
+		msg := &Message{From: "mayor/", To: "gastown/witness", Subject: "Hello", Type: TypeTask, Priority: PriorityNormal}
+		outcome := EvaluateRules(cfg, msg)
+		if len(outcome.Matched) != 0 || !outcome.Empty() {
+			t.Errorf("outcome = %+v, want no matches", outcome)
+		}
+	})
+
+	t.Run("exact before pattern and accumulate", func(t *testing.T) {
+		msg := &Message{From: "gastown/Toast", To: "gastown/witness", Subject: "POLECAT_DONE Toast", Type: TypeTask, Priority: PriorityNormal}
+		outcome := EvaluateRules(cfg, msg)
+		var names []string
+		for _, m := range outcome.Matched {
+			names = append(names, m.Label())
+		}
+		if len(names) != 2 || names[0] != "boost" || names[1] != "done-noise" {
+			t.Fatalf("matched = %v, want [boost done-noise]", names)
+		}
+		if outcome.Priority != PriorityHigh || !outcome.MarkRead || outcome.Archive {
+			t.Errorf("outcome = %+v, want priority high + mark-read", outcome)
+		}
+	})
+
+	t.Run("stop ends evaluation", func(t *testing.T) {
+		msg := &Message{From: "gastown/refinery", To: "gastown/witness", Subject: "POLECAT_DONE", Type: TypeNotification, Priority: PriorityUrgent}
+		outcome := EvaluateRules(cfg, msg)
+		if len(outcome.Matched) != 1 || outcome.Matched[0].Label() != "refinery" {
+			t.Fatalf("matched = %+v, want only refinery", outcome.Matched)
+		}
+		if !outcome.Archive || outcome.MarkRead || len(outcome.Forward) != 0 {
+			t.Errorf("outcome = %+v, want archive only", outcome)
+		}
+	})
+
+	t.Run("pattern only for other rig", func(t *testing.T) {
+		msg := &Message{From: "beads/Toast", To: "beads/witness", Subject: "Help", Type: TypeTask, Priority: PriorityUrgent}
+		outcome := EvaluateRules(cfg, msg)
+		if len(outcome.Forward) != 1 || outcome.Forward[0] != "mayor/" {
+			t.Errorf("forward = %v, want [mayor/]", outcome.Forward)
+		}
+	})
+
+	t.Run("unnamed rule label", func(t *testing.T) {
+		rules := RulesFor(&config.MessagingConfig{Rules: map[string][]config.MailRule{
+			"mayor/": {{Actions: []string{"archive"}}},
+		}}, "mayor")
+		if len(rules) != 1 || rules[0].Label() != "mayor/[0]" {
+			t.Errorf("rules = %+v, want one labelled mayor/[0]", rules)
+		}
+	})
+}
+
+func TestSendArchivedByRule(t *testing.T) {
+	townRoot := t.TempDir()
+	cfg := config.NewMessagingConfig()
+	cfg.Rules["mayor/"] = []config.MailRule{
+		{Match: config.MailRuleMatch{From: "*/refinery"}, Actions: []string{"archive"}},
+	}
+	if err := os.MkdirAll(filepath.Join(townRoot, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveMessagingConfig(config.MessagingConfigPath(townRoot), cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRouterWithTownRoot(townRoot, townRoot)
+	msg := NewMessage("gastown/refinery", "mayor/", "Merged gt-abc", "")
+	if err := r.Send(msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	// Archived without touching bd
+	mailbox := NewMailboxWithBeadsDir("mayor/", townRoot, filepath.Join(townRoot, ".beads"))
+	archived, err := mailbox.ListArchived()
+	if err != nil {
+		t.Fatalf("ListArchived: %v", err)
+	}
+	if len(archived) != 1 || archived[0].Subject != "Merged gt-abc" {
+		t.Fatalf("archived = %v, want the refinery message", archived)
+	}
+}
@@ -0,0 +1,177 @@
+package mail
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// scheduledDirName is the directory under the town's .runtime/ holding
+// messages waiting for their DeliverAt time, one JSON file per message.
+const scheduledDirName = "mail-scheduled"
+
+// ScheduledDir returns the directory holding deferred messages for a town.
+func ScheduledDir(townRoot string) string {
+	return filepath.Join(townRoot, constants.DirRuntime, scheduledDirName)
+}
+
+// scheduledRoot returns the root whose .runtime/ holds deferred messages.
+func (r *Router) scheduledRoot() string {
+	if r.townRoot != "" {
+		return r.townRoot
+	}
+	return r.workDir
+}
+
+// schedule holds a message until its DeliverAt time.
+func (r *Router) schedule(msg *Message) error {
+	if msg.ID == "" {
+		msg.ID = generateID()
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = timeNow()
+	}
+	path := filepath.Join(ScheduledDir(r.scheduledRoot()), msg.ID+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating scheduled mail dir: %w", err)
+	}
+	if err := util.AtomicWriteJSON(path, msg); err != nil {
+		return fmt.Errorf("scheduling message: %w", err)
+	}
+	return nil
+}
+
+// ListScheduled returns the messages waiting for deferred delivery,
+// soonest first. Unreadable entries are skipped.
+func (r *Router) ListScheduled() ([]*Message, error) {
+	dir := ScheduledDir(r.scheduledRoot())
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var messages []*Message
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name())) //nolint:gosec // G304: path is within the scheduled mail dir
+		if err != nil {
+			continue
+		}
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil || msg.DeliverAt == nil {
+			continue
+		}
+		messages = append(messages, &msg)
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].DeliverAt.Before(*messages[j].DeliverAt)
+	})
+	return messages, nil
+}
+
+// CancelScheduled drops a deferred message before it is delivered.
+func (r *Router) CancelScheduled(id string) error {
+	path := filepath.Join(ScheduledDir(r.scheduledRoot()), filepath.Base(id)+".json")
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return ErrMessageNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// DeliverDue sends every deferred message whose DeliverAt has passed.
+// Messages that expired while waiting are dropped rather than delivered.
+// Failed sends stay scheduled and are retried on the next call.
+// Returns the number of messages delivered.
+func (r *Router) DeliverDue(now time.Time) (int, error) {
+	scheduled, err := r.ListScheduled()
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	var errs []string
+	for _, msg := range scheduled {
+		if msg.DeliverAt.After(now) {
+			break // sorted soonest first
+		}
+		path := filepath.Join(ScheduledDir(r.scheduledRoot()), msg.ID+".json")
+
+		if msg.IsExpired(now) {
+			_ = os.Remove(path)
+			continue
+		}
+
+		out := *msg
+		out.DeliverAt = nil
+		if err := r.Send(&out); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", msg.ID, err))
+			continue
+		}
+		_ = os.Remove(path)
+		delivered++
+	}
+
+	if len(errs) > 0 {
+		return delivered, fmt.Errorf("some scheduled deliveries failed: %s", strings.Join(errs, "; "))
+	}
+	return delivered, nil
+}
+
+// ArchiveExpired archives unread messages whose ExpiresAt has passed, so
+// stale notifications don't linger in inboxes. Pinned messages are kept.
+// Returns the number of messages archived.
+func (r *Router) ArchiveExpired(now time.Time) (int, error) {
+	beadsDir := r.resolveBeadsDir("")
+	workDir := filepath.Dir(beadsDir)
+
+	args := []string{"list",
+		"--type", "message",
+		"--status", "open",
+		"--json",
+		"--limit=0",
+	}
+	stdout, err := runBdCommand(args, workDir, beadsDir)
+	if err != nil {
+		return 0, fmt.Errorf("listing messages: %w", err)
+	}
+
+	var beadsMsgs []BeadsMessage
+	if len(stdout) > 0 && string(stdout) != "null" {
+		if err := json.Unmarshal(stdout, &beadsMsgs); err != nil {
+			return 0, fmt.Errorf("parsing messages: %w", err)
+		}
+	}
+
+	archived := 0
+	for i := range beadsMsgs {
+		msg := beadsMsgs[i].ToMessage()
+		if !msg.IsExpired(now) || beadsMsgs[i].Pinned {
+			continue
+		}
+		mailbox := NewMailboxWithBeadsDir(msg.To, workDir, beadsDir)
+		if err := mailbox.appendToArchive(msg); err != nil {
+			return archived, fmt.Errorf("archiving %s: %w", msg.ID, err)
+		}
+		if err := mailbox.closeInDir(msg.ID, beadsDir); err != nil {
+			return archived, fmt.Errorf("closing %s: %w", msg.ID, err)
+		}
+		archived++
+	}
+	return archived, nil
+}
@@ -0,0 +1,163 @@
+package mail
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSendFutureMessageIsScheduled(t *testing.T) {
+	townRoot := t.TempDir()
+	r := NewRouterWithTownRoot(townRoot, townRoot)
+
+	deliverAt := time.Now().Add(2 * time.Hour)
+	msg := NewMessage("mayor/", "gastown/Toast", "Standup", "Post status")
+	msg.DeliverAt = &deliverAt
+
+	if err := r.Send(msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	path := filepath.Join(ScheduledDir(townRoot), msg.ID+".json")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("scheduled file not written: %v", err)
+	}
+
+	scheduled, err := r.ListScheduled()
+	if err != nil {
+		t.Fatalf("ListScheduled: %v", err)
+	}
+	if len(scheduled) != 1 || scheduled[0].ID != msg.ID {
+		t.Fatalf("ListScheduled = %v, want [%s]", scheduled, msg.ID)
+	}
+	if !scheduled[0].DeliverAt.Equal(deliverAt) {
+		t.Errorf("DeliverAt = %v, want %v", scheduled[0].DeliverAt, deliverAt)
+	}
+}
+
+func TestListScheduledSortsSoonestFirst(t *testing.T) {
+	townRoot := t.TempDir()
+	r := NewRouterWithTownRoot(townRoot, townRoot)
+
+	now := time.Now()
+	for _, d := range []time.Duration{3 * time.Hour, time.Hour, 2 * time.Hour} {
+		at := now.Add(d)
+		msg := NewMessage("mayor/", "gastown/Toast", d.String(), "")
+		msg.DeliverAt = &at
+		if err := r.Send(msg); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	scheduled, err := r.ListScheduled()
+	if err != nil {
+		t.Fatalf("ListScheduled: %v", err)
+	}
+	var subjects []string
+	for _, m := range scheduled {
+		subjects = append(subjects, m.Subject)
+	}
+	want := []string{"1h0m0s", "2h0m0s", "3h0m0s"}
+	if len(subjects) != len(want) {
+		t.Fatalf("subjects = %v, want %v", subjects, want)
+	}
+	for i := range want {
+		if subjects[i] != want[i] {
+			t.Errorf("subjects = %v, want %v", subjects, want)
+			break
+		}
+	}
+}
+
+func TestCancelScheduled(t *testing.T) {
+	townRoot := t.TempDir()
+	r := NewRouterWithTownRoot(townRoot, townRoot)
+
+	at := time.Now().Add(time.Hour)
+	msg := NewMessage("mayor/", "gastown/Toast", "Later", "")
+	msg.DeliverAt = &at
+	if err := r.Send(msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if err := r.CancelScheduled(msg.ID); err != nil {
+		t.Fatalf("CancelScheduled: %v", err)
+	}
+	if scheduled, _ := r.ListScheduled(); len(scheduled) != 0 {
+		t.Errorf("ListScheduled after cancel = %d messages, want 0", len(scheduled))
+	}
+	if err := r.CancelScheduled(msg.ID); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("second CancelScheduled = %v, want ErrMessageNotFound", err)
+	}
+}
+
+func TestDeliverDue(t *testing.T) {
+	townRoot := t.TempDir()
+	r := NewRouterWithTownRoot(townRoot, townRoot)
+
+	now := time.Now()
+
+	// Not yet due: stays scheduled
+	later := now.Add(time.Hour)
+	pending := NewMessage("mayor/", "gastown/Toast", "Pending", "")
+	pending.DeliverAt = &later
+	if err := r.Send(pending); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	// Due but already expired: dropped without delivery
+	stale := NewMessage("mayor/", "gastown/Toast", "Stale", "")
+	soon := now.Add(time.Minute)
+	expired := now.Add(2 * time.Minute)
+	stale.DeliverAt = &soon
+	stale.ExpiresAt = &expired
+	if err := r.Send(stale); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	delivered, err := r.DeliverDue(now.Add(5 * time.Minute))
+	if err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+	if delivered != 0 {
+		t.Errorf("delivered = %d, want 0", delivered)
+	}
+
+	scheduled, err := r.ListScheduled()
+	if err != nil {
+		t.Fatalf("ListScheduled: %v", err)
+	}
+	if len(scheduled) != 1 || scheduled[0].ID != pending.ID {
+		t.Errorf("remaining scheduled = %v, want only %s", scheduled, pending.ID)
+	}
+}
+
+func TestWithoutExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	messages := []*Message{
+		{ID: "live"},
+		{ID: "not-yet", ExpiresAt: &future},
+		{ID: "expired", ExpiresAt: &past},
+		{ID: "pinned", ExpiresAt: &past, Pinned: true},
+	}
+
+	got := withoutExpired(messages, now)
+	var ids []string
+	for _, m := range got {
+		ids = append(ids, m.ID)
+	}
+	want := []string{"live", "not-yet", "pinned"}
+	if len(ids) != len(want) {
+		t.Fatalf("ids = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ids = %v, want %v", ids, want)
+		}
+	}
+}
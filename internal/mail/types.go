@@ -107,6 +107,30 @@ type Message struct {
 	// CC contains addresses that should receive a copy of this message.
 	// CC'd recipients see the message in their inbox but are not the primary recipient.
 	CC []string `json:"cc,omitempty"`
+
+	// DeliverAt defers delivery: Router.Send holds the message until this time
+	// and the daemon delivers it once due. Nil means deliver immediately.
+	DeliverAt *time.Time `json:"deliver_at,omitempty"`
+
+	// ExpiresAt is when the message stops being true. Expired unread messages
+	// are hidden from the inbox and auto-archived by the daemon.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// skipRules bypasses the recipient's mail rules (set on rule forwards).
+	skipRules bool
+}
+
+// IsExpired reports whether the message has an expiry at or before now.
+func (m *Message) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && !m.ExpiresAt.After(now)
+}
+
+// expiresLabelPrefix marks a message's expiry time in beads labels.
+const expiresLabelPrefix = "expires:"
+
+// expiresLabel returns the beads label recording an expiry time.
+func expiresLabel(t time.Time) string {
+	return expiresLabelPrefix + t.UTC().Format(time.RFC3339)
 }
 
 // NewMessage creates a new message with a generated ID and thread ID.
@@ -174,16 +198,17 @@ type BeadsMessage struct {
 	Priority    int       `json:"priority"`    // 0=urgent, 1=high, 2=normal, 3=low
 	Status      string    `json:"status"`      // open=unread, closed=read
 	CreatedAt   time.Time `json:"created_at"`
-	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X)
+	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X, expires:X)
 	Pinned      bool      `json:"pinned,omitempty"`
 	Wisp        bool      `json:"wisp,omitempty"` // Ephemeral message (filtered from JSONL export)
 
 	// Cached parsed values (populated by ParseLabels)
-	sender   string
-	threadID string
-	replyTo  string
-	msgType  string
-	cc       []string // CC recipients
+	sender    string
+	threadID  string
+	replyTo   string
+	msgType   string
+	cc        []string   // CC recipients
+	expiresAt *time.Time // expiry time, if any
 }
 
 // ParseLabels extracts metadata from the labels array.
@@ -199,6 +224,10 @@ func (bm *BeadsMessage) ParseLabels() {
 			bm.msgType = strings.TrimPrefix(label, "msg-type:")
 		} else if strings.HasPrefix(label, "cc:") {
 			bm.cc = append(bm.cc, strings.TrimPrefix(label, "cc:"))
+		} else if strings.HasPrefix(label, expiresLabelPrefix) {
+			if t, err := time.Parse(time.RFC3339, strings.TrimPrefix(label, expiresLabelPrefix)); err == nil {
+				bm.expiresAt = &t
+			}
 		}
 	}
 }
@@ -263,6 +292,8 @@ func (bm *BeadsMessage) ToMessage() *Message {
 		ReplyTo:   bm.replyTo,
 		Wisp:      bm.Wisp,
 		CC:        ccAddrs,
+		Pinned:    bm.Pinned,
+		ExpiresAt: bm.expiresAt,
 	}
 }
 
@@ -349,3 +349,26 @@ func TestBeadsMessageToMessageEmptyLabels(t *testing.T) {
 		t.Errorf("ThreadID should be empty, got %q", msg.ThreadID)
 	}
 }
+
+func TestBeadsMessageToMessageWithExpiry(t *testing.T) {
+	expires := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	bm := BeadsMessage{
+		ID:       "hq-exp",
+		Title:    "Merge ready",
+		Status:   "open",
+		Assignee: "gastown/refinery",
+		Labels:   []string{"from:gastown/Toast", expiresLabel(expires)},
+	}
+
+	msg := bm.ToMessage()
+
+	if msg.ExpiresAt == nil || !msg.ExpiresAt.Equal(expires) {
+		t.Fatalf("ExpiresAt = %v, want %v", msg.ExpiresAt, expires)
+	}
+	if msg.IsExpired(expires.Add(-time.Second)) {
+		t.Error("IsExpired before expiry = true, want false")
+	}
+	if !msg.IsExpired(expires) {
+		t.Error("IsExpired at expiry = false, want true")
+	}
+}
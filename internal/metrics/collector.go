@@ -0,0 +1,351 @@
+package metrics
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/deacon"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mrqueue"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// Metric name prefix for everything Gas Town exports.
+const namespace = "gastown_"
+
+// mergeLatencyBuckets are the histogram buckets (seconds) for merge latency,
+// from the refinery starting an MR to it landing.
+var mergeLatencyBuckets = []float64{30, 60, 120, 300, 600, 1800, 3600, 7200}
+
+// Collector gathers town health metrics from on-disk state and beads.
+type Collector struct {
+	townRoot string
+	rigs     []string
+
+	// Counters count events at or after Since (typically daemon start), so
+	// they reset with the process like any other Prometheus counter.
+	since time.Time
+
+	// Hooks for bd-backed sources, replaceable in tests.
+	now             func() time.Time
+	listPolecats    func(rigName string) ([]*polecat.Polecat, error)
+	listEscalations func() ([]*beads.Issue, error)
+	readCosts       func(dir string) ([]costWisp, error)
+}
+
+// NewCollector creates a collector for the given town and rigs.
+// Counters cover events logged at or after since.
+func NewCollector(townRoot string, rigs []string, since time.Time) *Collector {
+	c := &Collector{
+		townRoot: townRoot,
+		rigs:     append([]string(nil), rigs...),
+		since:    since,
+		now:      time.Now,
+	}
+	sort.Strings(c.rigs)
+	c.listPolecats = c.rigPolecats
+	c.listEscalations = beads.New(filepath.Join(townRoot, ".beads")).ListEscalations
+	c.readCosts = sessionCostWisps
+	return c
+}
+
+// Collect gathers all metric families. Sources that fail are skipped and
+// reported through gastown_scrape_errors so one broken rig doesn't blank
+// the whole scrape.
+func (c *Collector) Collect() []*Family {
+	now := c.now()
+	scrapeErrors := &Family{
+		Name: namespace + "scrape_errors",
+		Help: "Metric sources that failed during this scrape.",
+		Type: TypeGauge,
+	}
+	fail := func(source string) { scrapeErrors.Add(1, Labels{"source": source}) }
+
+	var families []*Family
+	families = append(families, c.collectPolecats(fail)...)
+	families = append(families, c.collectMergeQueues(now, fail)...)
+	families = append(families, c.collectDeacon(now))
+	families = append(families, c.collectEvents(fail)...)
+	families = append(families, c.collectEscalations(fail))
+	families = append(families, c.collectCosts(fail))
+	families = append(families, scrapeErrors)
+	return families
+}
+
+// collectPolecats reports polecat counts per rig and state.
+func (c *Collector) collectPolecats(fail func(string)) []*Family {
+	polecats := &Family{
+		Name: namespace + "polecats",
+		Help: "Polecats per rig and state.",
+		Type: TypeGauge,
+	}
+	for _, rigName := range c.rigs {
+		list, err := c.listPolecats(rigName)
+		if err != nil {
+			fail("polecats")
+			continue
+		}
+		for _, state := range []polecat.State{polecat.StateWorking, polecat.StateDone, polecat.StateStuck} {
+			polecats.Set(0, Labels{"rig": rigName, "state": string(state)})
+		}
+		for _, p := range list {
+			state := p.State
+			if state == polecat.StateActive {
+				state = polecat.StateWorking
+			}
+			polecats.Add(1, Labels{"rig": rigName, "state": string(state)})
+		}
+	}
+	return []*Family{polecats}
+}
+
+func (c *Collector) rigPolecats(rigName string) ([]*polecat.Polecat, error) {
+	r := &rig.Rig{Name: rigName, Path: filepath.Join(c.townRoot, rigName)}
+	return polecat.NewManager(r, git.NewGit(r.Path)).List()
+}
+
+// collectMergeQueues reports MQ depth and claim age per rig, plus merge
+// outcomes and latency from each rig's MQ event log.
+func (c *Collector) collectMergeQueues(now time.Time, fail func(string)) []*Family {
+	depth := &Family{
+		Name: namespace + "mq_depth",
+		Help: "Merge requests waiting in the merge queue, by claim state.",
+		Type: TypeGauge,
+	}
+	oldest := &Family{
+		Name: namespace + "mq_oldest_age_seconds",
+		Help: "Age of the oldest merge request in the queue.",
+		Type: TypeGauge,
+		Unit: "seconds",
+	}
+	claimAge := &Family{
+		Name: namespace + "mq_claim_age_seconds",
+		Help: "Age of the oldest refinery claim on a merge request.",
+		Type: TypeGauge,
+		Unit: "seconds",
+	}
+	merges := &Family{
+		Name: namespace + "merges",
+		Help: "Merge attempts by outcome.",
+		Type: TypeCounter,
+	}
+	latency := &Family{
+		Name:    namespace + "merge_duration_seconds",
+		Help:    "Time from the refinery starting an MR to it merging.",
+		Type:    TypeHistogram,
+		Unit:    "seconds",
+		Buckets: mergeLatencyBuckets,
+	}
+
+	for _, rigName := range c.rigs {
+		rigPath := filepath.Join(c.townRoot, rigName)
+		labels := Labels{"rig": rigName}
+
+		mrs, err := mrqueue.New(rigPath).List()
+		if err != nil {
+			fail("mq")
+		} else {
+			var claimed, unclaimed float64
+			var oldestAge, oldestClaim time.Duration
+			for _, mr := range mrs {
+				if age := now.Sub(mr.CreatedAt); age > oldestAge {
+					oldestAge = age
+				}
+				if mr.ClaimedBy == "" {
+					unclaimed++
+					continue
+				}
+				claimed++
+				if mr.ClaimedAt != nil {
+					if age := now.Sub(*mr.ClaimedAt); age > oldestClaim {
+						oldestClaim = age
+					}
+				}
+			}
+			depth.Set(unclaimed, Labels{"rig": rigName, "state": "unclaimed"})
+			depth.Set(claimed, Labels{"rig": rigName, "state": "claimed"})
+			oldest.Set(oldestAge.Seconds(), labels)
+			claimAge.Set(oldestClaim.Seconds(), labels)
+		}
+
+		mqEvents, err := mrqueue.NewEventLoggerFromRig(rigPath).ReadEvents()
+		if err != nil {
+			fail("mq_events")
+			continue
+		}
+		for _, outcome := range []mrqueue.EventType{mrqueue.EventMerged, mrqueue.EventMergeFailed, mrqueue.EventMergeSkipped} {
+			merges.Set(0, Labels{"rig": rigName, "outcome": string(outcome)})
+		}
+		started := make(map[string]time.Time)
+		for _, e := range mqEvents {
+			if e.Timestamp.Before(c.since) {
+				continue
+			}
+			switch e.Type {
+			case mrqueue.EventMergeStarted:
+				started[e.MRID] = e.Timestamp
+			case mrqueue.EventMerged:
+				if start, ok := started[e.MRID]; ok {
+					latency.Observe(e.Timestamp.Sub(start).Seconds(), labels)
+					delete(started, e.MRID)
+				}
+				merges.Add(1, Labels{"rig": rigName, "outcome": string(e.Type)})
+			case mrqueue.EventMergeFailed, mrqueue.EventMergeSkipped:
+				delete(started, e.MRID)
+				merges.Add(1, Labels{"rig": rigName, "outcome": string(e.Type)})
+			}
+		}
+	}
+
+	return []*Family{depth, oldest, claimAge, merges, latency}
+}
+
+// collectDeacon reports the age of the Deacon's last heartbeat.
+// A missing heartbeat is reported as -1.
+func (c *Collector) collectDeacon(now time.Time) *Family {
+	age := &Family{
+		Name: namespace + "deacon_heartbeat_age_seconds",
+		Help: "Seconds since the Deacon last wrote its heartbeat (-1 if never).",
+		Type: TypeGauge,
+		Unit: "seconds",
+	}
+	if hb := deacon.ReadHeartbeat(c.townRoot); hb != nil {
+		age.Set(now.Sub(hb.Timestamp).Seconds(), nil)
+	} else {
+		age.Set(-1, nil)
+	}
+	return age
+}
+
+// collectEvents counts session deaths, mass-death events and escalations
+// from the town's activity log (including rotated segments).
+func (c *Collector) collectEvents(fail func(string)) []*Family {
+	deaths := &Family{
+		Name: namespace + "session_deaths",
+		Help: "Agent sessions that died or were killed.",
+		Type: TypeCounter,
+	}
+	massDeaths := &Family{
+		Name: namespace + "mass_deaths",
+		Help: "Mass-death events (several sessions dying within a short window).",
+		Type: TypeCounter,
+	}
+	escalations := &Family{
+		Name: namespace + "escalations",
+		Help: "Escalations sent, by severity.",
+		Type: TypeCounter,
+	}
+	deaths.Set(0, nil)
+	massDeaths.Set(0, nil)
+
+	err := events.Scan(c.townRoot, c.since, func(line []byte) error {
+		var e events.Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil
+		}
+		if ts, err := time.Parse(time.RFC3339, e.Timestamp); err == nil && ts.Before(c.since) {
+			return nil
+		}
+		switch e.Type {
+		case events.TypeSessionDeath:
+			deaths.Add(1, nil)
+		case events.TypeMassDeath:
+			massDeaths.Add(1, nil)
+		case events.TypeEscalationSent:
+			severity := payloadString(e.Payload, "severity")
+			if severity == "" {
+				severity = payloadString(e.Payload, "new_severity") // re-escalation
+			}
+			if severity == "" {
+				severity = "unknown"
+			}
+			escalations.Add(1, Labels{"severity": severity})
+		}
+		return nil
+	})
+	if err != nil {
+		fail("events")
+	}
+	return []*Family{deaths, massDeaths, escalations}
+}
+
+// collectEscalations reports open escalation beads by severity.
+func (c *Collector) collectEscalations(fail func(string)) *Family {
+	open := &Family{
+		Name: namespace + "escalations_open",
+		Help: "Open escalations, by severity.",
+		Type: TypeGauge,
+	}
+	issues, err := c.listEscalations()
+	if err != nil {
+		fail("escalations")
+		return open
+	}
+	for _, severity := range []string{"critical", "high", "medium", "low"} {
+		open.Set(0, Labels{"severity": severity})
+	}
+	for _, issue := range issues {
+		severity := beads.ParseEscalationFields(issue.Description).Severity
+		if severity == "" {
+			severity = "unknown"
+		}
+		open.Add(1, Labels{"severity": severity})
+	}
+	return open
+}
+
+// collectCosts reports session cost per rig from session.ended wisps that
+// haven't been rolled into a daily digest yet (see gt costs digest).
+func (c *Collector) collectCosts(fail func(string)) *Family {
+	cost := &Family{
+		Name: namespace + "session_cost_usd",
+		Help: "Session cost in USD per rig since the last daily cost digest.",
+		Type: TypeGauge,
+	}
+
+	seen := make(map[string]bool)
+	dirs := append([]string{c.townRoot}, c.rigPaths()...)
+	for _, dir := range dirs {
+		wisps, err := c.readCosts(dir)
+		if err != nil {
+			fail("costs")
+			continue
+		}
+		for _, w := range wisps {
+			// Rigs can share a beads database through redirects
+			if seen[w.ID] {
+				continue
+			}
+			seen[w.ID] = true
+
+			rigName := w.Rig
+			if rigName == "" {
+				rigName = "town"
+			}
+			cost.Add(w.CostUSD, Labels{"rig": rigName})
+		}
+	}
+	for _, rigName := range c.rigs {
+		cost.Add(0, Labels{"rig": rigName})
+	}
+	return cost
+}
+
+func (c *Collector) rigPaths() []string {
+	paths := make([]string, 0, len(c.rigs))
+	for _, rigName := range c.rigs {
+		paths = append(paths, filepath.Join(c.townRoot, rigName))
+	}
+	return paths
+}
+
+// payloadString returns a string payload field, or "" if absent.
+func payloadString(payload map[string]interface{}, key string) string {
+	s, _ := payload[key].(string)
+	return s
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/deacon"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mrqueue"
+	"github.com/steveyegge/gastown/internal/polecat"
+)
+
+func TestCollect(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-time.Hour)
+	rigPath := filepath.Join(townRoot, "gastown")
+
+	// Merge queue: one unclaimed MR, one claimed 10 minutes ago
+	q := mrqueue.New(rigPath)
+	if err := q.Submit(&mrqueue.MR{Branch: "polecat/a", Target: "main", CreatedAt: now.Add(-30 * time.Minute)}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	claimed := &mrqueue.MR{Branch: "polecat/b", Target: "main", CreatedAt: now.Add(-20 * time.Minute)}
+	if err := q.Submit(claimed); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if err := q.Claim(claimed.ID, "refinery-1"); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+
+	// MQ events: one merge in 2 minutes, one failure, one merge before since
+	mq := mrqueue.NewEventLoggerFromRig(rigPath)
+	for _, e := range []mrqueue.Event{
+		{Timestamp: now.Add(-2 * time.Hour), Type: mrqueue.EventMerged, MRID: "mr-old"},
+		{Timestamp: now.Add(-10 * time.Minute), Type: mrqueue.EventMergeStarted, MRID: "mr-1"},
+		{Timestamp: now.Add(-8 * time.Minute), Type: mrqueue.EventMerged, MRID: "mr-1"},
+		{Timestamp: now.Add(-5 * time.Minute), Type: mrqueue.EventMergeFailed, MRID: "mr-2"},
+	} {
+		if err := mq.LogEvent(e); err != nil {
+			t.Fatalf("LogEvent: %v", err)
+		}
+	}
+
+	if err := deacon.WriteHeartbeat(townRoot, &deacon.Heartbeat{Timestamp: now.Add(-90 * time.Second)}); err != nil {
+		t.Fatalf("WriteHeartbeat: %v", err)
+	}
+
+	// Activity events
+	var lines []string
+	for _, e := range []events.Event{
+		{Timestamp: now.Add(-2 * time.Hour).Format(time.RFC3339), Type: events.TypeSessionDeath},
+		{Timestamp: now.Add(-time.Minute).Format(time.RFC3339), Type: events.TypeSessionDeath},
+		{Timestamp: now.Add(-time.Minute).Format(time.RFC3339), Type: events.TypeMassDeath},
+		{Timestamp: now.Add(-time.Minute).Format(time.RFC3339), Type: events.TypeEscalationSent, Payload: map[string]interface{}{"severity": "high"}},
+		{Timestamp: now.Add(-time.Minute).Format(time.RFC3339), Type: events.TypeEscalationSent, Payload: map[string]interface{}{"new_severity": "critical"}},
+	} {
+		data, _ := json.Marshal(e)
+		lines = append(lines, string(data))
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, events.EventsFile), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("write events: %v", err)
+	}
+
+	c := NewCollector(townRoot, []string{"gastown"}, since)
+	c.now = func() time.Time { return now }
+	c.listPolecats = func(string) ([]*polecat.Polecat, error) {
+		return []*polecat.Polecat{{State: polecat.StateWorking}, {State: polecat.StateActive}, {State: polecat.StateStuck}}, nil
+	}
+	c.listEscalations = func() ([]*beads.Issue, error) {
+		return []*beads.Issue{{Description: beads.FormatEscalationDescription("x", &beads.EscalationFields{Severity: "critical"})}}, nil
+	}
+	c.readCosts = func(dir string) ([]costWisp, error) {
+		// The same wisp seen through the town and rig databases counts once
+		return []costWisp{{ID: "w-1", Rig: "gastown", CostUSD: 1.5}}, nil
+	}
+
+	var b strings.Builder
+	if err := Write(&b, c.Collect()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		`gastown_polecats{rig="gastown",state="working"} 2`,
+		`gastown_polecats{rig="gastown",state="stuck"} 1`,
+		`gastown_polecats{rig="gastown",state="done"} 0`,
+		`gastown_mq_depth{rig="gastown",state="unclaimed"} 1`,
+		`gastown_mq_depth{rig="gastown",state="claimed"} 1`,
+		`gastown_mq_oldest_age_seconds{rig="gastown"} 1800`,
+		`gastown_merges_total{outcome="merged",rig="gastown"} 1`,
+		`gastown_merges_total{outcome="merge_failed",rig="gastown"} 1`,
+		`gastown_merge_duration_seconds_bucket{rig="gastown",le="120"} 1`,
+		`gastown_merge_duration_seconds_sum{rig="gastown"} 120`,
+		`gastown_deacon_heartbeat_age_seconds 90`,
+		`gastown_session_deaths_total 1`,
+		`gastown_mass_deaths_total 1`,
+		`gastown_escalations_total{severity="high"} 1`,
+		`gastown_escalations_total{severity="critical"} 1`,
+		`gastown_escalations_open{severity="critical"} 1`,
+		`gastown_escalations_open{severity="low"} 0`,
+		`gastown_session_cost_usd{rig="gastown"} 1.5`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "gastown_scrape_errors{") {
+		t.Errorf("unexpected scrape errors:\n%s", out)
+	}
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// costWisp is a session.ended wisp reduced to what cost metrics need.
+type costWisp struct {
+	ID      string
+	Rig     string
+	CostUSD float64
+}
+
+// sessionCostWisps reads session.ended wisps from the beads database at dir.
+// These are recorded by gt costs record and removed by the daily digest.
+func sessionCostWisps(dir string) ([]costWisp, error) {
+	bd := beads.New(dir)
+
+	out, err := bd.Run("mol", "wisp", "list", "--all", "--json")
+	if err != nil {
+		// No wisps database: nothing recorded
+		return nil, nil
+	}
+	var list struct {
+		Wisps []struct {
+			ID string `json:"id"`
+		} `json:"wisps"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("parsing wisp list: %w", err)
+	}
+	if len(list.Wisps) == 0 {
+		return nil, nil
+	}
+
+	showArgs := []string{"show", "--json"}
+	for _, w := range list.Wisps {
+		showArgs = append(showArgs, w.ID)
+	}
+	out, err = bd.Run(showArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("showing wisps: %w", err)
+	}
+	var shown []struct {
+		ID        string `json:"id"`
+		EventKind string `json:"event_kind"`
+		Payload   string `json:"payload"`
+	}
+	if err := json.Unmarshal(out, &shown); err != nil {
+		return nil, fmt.Errorf("parsing wisp details: %w", err)
+	}
+
+	var wisps []costWisp
+	for _, w := range shown {
+		if w.EventKind != "session.ended" || w.Payload == "" {
+			continue
+		}
+		var payload struct {
+			Rig     string  `json:"rig"`
+			CostUSD float64 `json:"cost_usd"`
+		}
+		if err := json.Unmarshal([]byte(w.Payload), &payload); err != nil {
+			continue
+		}
+		wisps = append(wisps, costWisp{ID: w.ID, Rig: payload.Rig, CostUSD: payload.CostUSD})
+	}
+	return wisps, nil
+}
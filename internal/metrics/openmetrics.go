@@ -0,0 +1,210 @@
+// Package metrics exports Gas Town health as Prometheus/OpenMetrics metrics.
+//
+// The daemon serves these at /metrics when metrics are enabled in
+// mayor/daemon.json. Metrics are derived from state Gas Town already keeps on
+// disk (merge queues, MQ and activity event logs, the Deacon heartbeat) and
+// from beads (polecats, escalations, session costs); nothing is tracked twice.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the OpenMetrics text exposition content type.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Type is an OpenMetrics metric family type.
+type Type string
+
+const (
+	TypeGauge     Type = "gauge"
+	TypeCounter   Type = "counter"
+	TypeHistogram Type = "histogram"
+)
+
+// Labels are the label pairs of one sample.
+type Labels map[string]string
+
+// Sample is one labelled value of a gauge or counter.
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+// Histogram is one labelled histogram observation set.
+// Counts holds cumulative counts per bucket, aligned with Family.Buckets.
+type Histogram struct {
+	Labels Labels
+	Counts []uint64
+	Count  uint64
+	Sum    float64
+}
+
+// Family is a named set of samples sharing a type and help text.
+type Family struct {
+	Name string
+	Help string
+	Type Type
+	Unit string
+
+	Samples []Sample
+
+	// Histogram families only
+	Buckets    []float64
+	Histograms []Histogram
+}
+
+// Set adds or replaces the sample with the given labels.
+func (f *Family) Set(value float64, labels Labels) {
+	for i := range f.Samples {
+		if sameLabels(f.Samples[i].Labels, labels) {
+			f.Samples[i].Value = value
+			return
+		}
+	}
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: value})
+}
+
+// Add increments the sample with the given labels, creating it at zero.
+func (f *Family) Add(delta float64, labels Labels) {
+	for i := range f.Samples {
+		if sameLabels(f.Samples[i].Labels, labels) {
+			f.Samples[i].Value += delta
+			return
+		}
+	}
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: delta})
+}
+
+// Observe records a value in the histogram with the given labels.
+func (f *Family) Observe(value float64, labels Labels) {
+	idx := -1
+	for i := range f.Histograms {
+		if sameLabels(f.Histograms[i].Labels, labels) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		f.Histograms = append(f.Histograms, Histogram{Labels: labels, Counts: make([]uint64, len(f.Buckets))})
+		idx = len(f.Histograms) - 1
+	}
+	h := &f.Histograms[idx]
+	for i, le := range f.Buckets {
+		if value <= le {
+			h.Counts[i]++
+		}
+	}
+	h.Count++
+	h.Sum += value
+}
+
+// Write renders families in the OpenMetrics text format, terminated by # EOF.
+// Samples are sorted by labels so output is stable between scrapes.
+func Write(w io.Writer, families []*Family) error {
+	var b strings.Builder
+	for _, f := range families {
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.Name, f.Type)
+		if f.Unit != "" {
+			fmt.Fprintf(&b, "# UNIT %s %s\n", f.Name, f.Unit)
+		}
+		if f.Help != "" {
+			fmt.Fprintf(&b, "# HELP %s %s\n", f.Name, escapeHelp(f.Help))
+		}
+
+		switch f.Type {
+		case TypeHistogram:
+			hists := append([]Histogram(nil), f.Histograms...)
+			sort.Slice(hists, func(i, j int) bool {
+				return formatLabels(hists[i].Labels, "", "") < formatLabels(hists[j].Labels, "", "")
+			})
+			for _, h := range hists {
+				for i, le := range f.Buckets {
+					fmt.Fprintf(&b, "%s_bucket%s %d\n", f.Name, formatLabels(h.Labels, "le", formatFloat(le)), h.Counts[i])
+				}
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", f.Name, formatLabels(h.Labels, "le", "+Inf"), h.Count)
+				fmt.Fprintf(&b, "%s_count%s %d\n", f.Name, formatLabels(h.Labels, "", ""), h.Count)
+				fmt.Fprintf(&b, "%s_sum%s %s\n", f.Name, formatLabels(h.Labels, "", ""), formatFloat(h.Sum))
+			}
+		default:
+			suffix := ""
+			if f.Type == TypeCounter {
+				suffix = "_total"
+			}
+			samples := append([]Sample(nil), f.Samples...)
+			sort.Slice(samples, func(i, j int) bool {
+				return formatLabels(samples[i].Labels, "", "") < formatLabels(samples[j].Labels, "", "")
+			})
+			for _, s := range samples {
+				fmt.Fprintf(&b, "%s%s%s %s\n", f.Name, suffix, formatLabels(s.Labels, "", ""), formatFloat(s.Value))
+			}
+		}
+	}
+	b.WriteString("# EOF\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatLabels renders {k="v",...} with keys sorted, plus an optional extra
+// pair appended last (used for histogram "le").
+func formatLabels(labels Labels, extraKey, extraValue string) string {
+	if len(labels) == 0 && extraKey == "" {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, k+`="`+escapeLabel(labels[k])+`"`)
+	}
+	if extraKey != "" {
+		parts = append(parts, extraKey+`="`+escapeLabel(extraValue)+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// labelEscaper escapes label values as OpenMetrics requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func escapeHelp(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	return strings.ReplaceAll(v, "\n", `\n`)
+}
+
+func sameLabels(a, b Labels) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
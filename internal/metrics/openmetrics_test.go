@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	gauge := &Family{Name: "gastown_polecats", Help: "Polecats per rig.", Type: TypeGauge}
+	gauge.Set(2, Labels{"rig": "zoo", "state": "working"})
+	gauge.Set(1, Labels{"rig": "alpha", "state": "working"})
+	gauge.Add(1, Labels{"rig": "alpha", "state": "working"})
+
+	counter := &Family{Name: "gastown_merges", Type: TypeCounter}
+	counter.Add(3, Labels{"rig": `we"ird\rig`})
+
+	hist := &Family{Name: "gastown_merge_duration_seconds", Type: TypeHistogram, Unit: "seconds", Buckets: []float64{60, 300}}
+	hist.Observe(30, nil)
+	hist.Observe(120, nil)
+	hist.Observe(900, nil)
+
+	var b strings.Builder
+	if err := Write(&b, []*Family{gauge, counter, hist}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := `# TYPE gastown_polecats gauge
+# HELP gastown_polecats Polecats per rig.
+gastown_polecats{rig="alpha",state="working"} 2
+gastown_polecats{rig="zoo",state="working"} 2
+# TYPE gastown_merges counter
+gastown_merges_total{rig="we\"ird\\rig"} 3
+# TYPE gastown_merge_duration_seconds histogram
+# UNIT gastown_merge_duration_seconds seconds
+gastown_merge_duration_seconds_bucket{le="60"} 1
+gastown_merge_duration_seconds_bucket{le="300"} 2
+gastown_merge_duration_seconds_bucket{le="+Inf"} 3
+gastown_merge_duration_seconds_count 3
+gastown_merge_duration_seconds_sum 1050
+# EOF
+`
+	if got := b.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant:\n%s", got, want)
+	}
+}
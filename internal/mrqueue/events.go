@@ -2,6 +2,7 @@
 package mrqueue
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -150,3 +151,27 @@ func (l *EventLogger) LogMergeSkipped(mr *MR, reason string) error {
 func (l *EventLogger) LogPath() string {
 	return l.logPath
 }
+
+// ReadEvents reads all events from the MQ event log.
+// Returns nil (no error) if nothing has been logged yet.
+func (l *EventLogger) ReadEvents() ([]Event, error) {
+	f, err := os.Open(l.logPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening event log: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // Skip malformed lines
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
@@ -94,6 +94,20 @@ func TestEventLogger(t *testing.T) {
 			t.Errorf("Event %d: timestamp too old: %v", i, event.Timestamp)
 		}
 	}
+
+	// ReadEvents returns the same events in order
+	read, err := logger.ReadEvents()
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+	if len(read) != len(expectedTypes) {
+		t.Fatalf("ReadEvents returned %d events, want %d", len(read), len(expectedTypes))
+	}
+	for i, event := range read {
+		if event.Type != expectedTypes[i] || event.SourceIssue != mr.SourceIssue {
+			t.Errorf("ReadEvents[%d] = %+v", i, event)
+		}
+	}
 }
 
 func splitLines(s string) []string {
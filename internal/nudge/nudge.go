@@ -0,0 +1,382 @@
+// Package nudge delivers nudges to agent sessions with receipt tracking.
+//
+// tmux accepting keystrokes doesn't mean the agent saw them: a busy input
+// box or a mid-generation agent can drop a nudge silently. Each nudge sent
+// through this package gets an ID and an ack token appended to its text.
+// Receipt is confirmed when the agent runs "gt nudge ack <id>" or when the
+// token scrolls up out of the pane's input area. Unconfirmed nudges are
+// retried with backoff and finally fall back to durable mail.
+package nudge
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Delivery statuses.
+const (
+	StatusPending = "pending" // sent, receipt not yet confirmed
+	StatusAcked   = "acked"   // agent ran gt nudge ack
+	StatusSeen    = "seen"    // token left the input area of the pane
+	StatusMailed  = "mailed"  // undelivered, sent as mail instead
+	StatusFailed  = "failed"  // undelivered and no mail fallback
+)
+
+// recordsDirName holds one JSON record per nudge in the town's .runtime/.
+const recordsDirName = "nudges"
+
+// recordTTL is how long finished records are kept.
+const recordTTL = 24 * time.Hour
+
+// inputAreaLines is how many non-blank lines at the bottom of a pane count
+// as the agent's input area (prompt box and status line). A token above
+// them has been submitted and pushed up by later output.
+const inputAreaLines = 5
+
+// captureLines is how much of the pane is captured to look for a token.
+const captureLines = 200
+
+// ErrNotFound indicates no nudge record exists for an ID.
+var ErrNotFound = errors.New("nudge not found")
+
+// Pane is the tmux surface deliveries need. *tmux.Tmux implements it.
+type Pane interface {
+	NudgeSession(session, message string) error
+	CapturePane(session string, lines int) (string, error)
+}
+
+// Record is the persisted state of one nudge.
+type Record struct {
+	ID        string     `json:"id"`
+	Session   string     `json:"session"`
+	Address   string     `json:"address,omitempty"` // mail fallback target
+	From      string     `json:"from,omitempty"`
+	Message   string     `json:"message"`
+	Status    string     `json:"status"`
+	Attempts  int        `json:"attempts"`
+	Error     string     `json:"error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	AckedAt   *time.Time `json:"acked_at,omitempty"`
+}
+
+// Delivered reports whether receipt was confirmed.
+func (r *Record) Delivered() bool {
+	return r.Status == StatusAcked || r.Status == StatusSeen
+}
+
+// Request is a nudge to deliver.
+type Request struct {
+	Session string // tmux session to nudge
+	Address string // agent address, for the mail fallback (optional)
+	From    string // sender, for the mail fallback
+	Message string
+}
+
+// Options control retries and receipt checks.
+type Options struct {
+	// Attempts is how many times the nudge is sent (default 3).
+	Attempts int
+
+	// AckTimeout is how long each attempt waits for receipt (default 30s).
+	AckTimeout time.Duration
+
+	// Backoff is the pause before the second attempt, doubling after each
+	// further attempt (default 5s).
+	Backoff time.Duration
+
+	// RequireAck only counts an explicit gt nudge ack as receipt.
+	RequireAck bool
+
+	// MailFallback mails undelivered nudges to Request.Address.
+	MailFallback bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.Attempts <= 0 {
+		o.Attempts = 3
+	}
+	if o.AckTimeout <= 0 {
+		o.AckTimeout = 30 * time.Second
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = 5 * time.Second
+	}
+	return o
+}
+
+// pollInterval is how often an attempt checks for receipt.
+const pollInterval = time.Second
+
+// Deliverer sends nudges and tracks their receipt.
+type Deliverer struct {
+	townRoot string
+	pane     Pane
+
+	// Overridable for tests
+	now   func() time.Time
+	sleep func(time.Duration)
+	mail  func(rec *Record) error
+}
+
+// NewDeliverer creates a Deliverer for a town.
+func NewDeliverer(townRoot string, pane Pane) *Deliverer {
+	d := &Deliverer{
+		townRoot: townRoot,
+		pane:     pane,
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+	d.mail = d.sendAsMail
+	return d
+}
+
+// Send delivers a nudge, retrying until receipt is confirmed or attempts
+// run out, then falls back to mail if enabled. The returned record holds
+// the final status; err is only set when the record can't be tracked.
+func (d *Deliverer) Send(req Request, opts Options) (*Record, error) {
+	opts = opts.withDefaults()
+	d.prune()
+
+	now := d.now()
+	rec := &Record{
+		ID:        newID(),
+		Session:   req.Session,
+		Address:   req.Address,
+		From:      req.From,
+		Message:   req.Message,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := d.save(rec); err != nil {
+		return nil, err
+	}
+
+	text := WithToken(req.Message, rec.ID)
+	backoff := opts.Backoff
+	for attempt := 1; attempt <= opts.Attempts; attempt++ {
+		if attempt > 1 {
+			d.sleep(backoff)
+			backoff *= 2
+		}
+		rec.Attempts = attempt
+		if err := d.pane.NudgeSession(req.Session, text); err != nil {
+			rec.Error = err.Error()
+			_ = d.save(rec)
+			continue
+		}
+		rec.Error = ""
+		_ = d.save(rec)
+
+		if status := d.awaitReceipt(rec.ID, req.Session, opts); status != "" {
+			rec.Status = status
+			rec.UpdatedAt = d.now()
+			if status == StatusAcked {
+				if acked, err := Load(d.townRoot, rec.ID); err == nil {
+					rec.AckedAt = acked.AckedAt
+				}
+			}
+			return rec, d.save(rec)
+		}
+	}
+
+	// An ack can land after the last poll; don't mail a nudge that arrived
+	if acked, err := Load(d.townRoot, rec.ID); err == nil && acked.Status == StatusAcked {
+		rec.Status = StatusAcked
+		rec.AckedAt = acked.AckedAt
+		rec.UpdatedAt = d.now()
+		return rec, d.save(rec)
+	}
+
+	// Undelivered
+	rec.Status = StatusFailed
+	if opts.MailFallback && rec.Address != "" {
+		if err := d.mail(rec); err != nil {
+			rec.Error = fmt.Sprintf("mail fallback: %v", err)
+		} else {
+			rec.Status = StatusMailed
+		}
+	}
+	rec.UpdatedAt = d.now()
+	return rec, d.save(rec)
+}
+
+// awaitReceipt polls for an ack (or the token leaving the input area)
+// until the attempt's timeout. Returns the receipt status, or "" if none.
+func (d *Deliverer) awaitReceipt(id, session string, opts Options) string {
+	deadline := d.now().Add(opts.AckTimeout)
+	for {
+		if rec, err := Load(d.townRoot, id); err == nil && rec.Status == StatusAcked {
+			return StatusAcked
+		}
+		if !opts.RequireAck {
+			if content, err := d.pane.CapturePane(session, captureLines); err == nil && Submitted(content, id) {
+				return StatusSeen
+			}
+		}
+		if !d.now().Before(deadline) {
+			return ""
+		}
+		d.sleep(pollInterval)
+	}
+}
+
+// sendAsMail delivers an undelivered nudge as durable mail.
+func (d *Deliverer) sendAsMail(rec *Record) error {
+	from := rec.From
+	if from == "" {
+		from = "deacon/"
+	}
+	router := mail.NewRouterWithTownRoot(d.townRoot, d.townRoot)
+	return router.Send(&mail.Message{
+		From:     from,
+		To:       rec.Address,
+		Subject:  "Undelivered nudge: " + truncate(rec.Message, 60),
+		Body:     fmt.Sprintf("This nudge could not be confirmed after %d attempt(s), so it was mailed instead.\n\n%s", rec.Attempts, rec.Message),
+		Priority: mail.PriorityHigh,
+		Type:     mail.TypeNotification,
+	})
+}
+
+// WithToken appends the ack instruction for a nudge ID to its text.
+func WithToken(message, id string) string {
+	return fmt.Sprintf("%s [%s: ack with 'gt nudge ack %s']", message, id, id)
+}
+
+// Submitted reports whether a nudge's token appears in pane content above
+// the input area, i.e. the text was submitted and output followed it.
+func Submitted(content, id string) bool {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) <= inputAreaLines {
+		return false
+	}
+	for _, line := range lines[:len(lines)-inputAreaLines] {
+		if strings.Contains(line, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ack records that the agent received a nudge.
+func Ack(townRoot, id string) (*Record, error) {
+	rec, err := Load(townRoot, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status == StatusAcked {
+		return rec, nil
+	}
+	now := time.Now()
+	rec.Status = StatusAcked
+	rec.AckedAt = &now
+	rec.UpdatedAt = now
+	return rec, util.AtomicWriteJSON(recordPath(townRoot, id), rec)
+}
+
+// Load reads a nudge record.
+func Load(townRoot, id string) (*Record, error) {
+	data, err := os.ReadFile(recordPath(townRoot, id)) //nolint:gosec // G304: path is under the town runtime dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("parsing nudge %s: %w", id, err)
+	}
+	return &rec, nil
+}
+
+// List returns the town's nudge records, newest first.
+func List(townRoot string) ([]*Record, error) {
+	entries, err := os.ReadDir(recordsDir(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []*Record
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		rec, err := Load(townRoot, strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].CreatedAt.After(records[j].CreatedAt) })
+	return records, nil
+}
+
+// prune removes records older than recordTTL. Best-effort.
+func (d *Deliverer) prune() {
+	records, _ := List(d.townRoot)
+	cutoff := d.now().Add(-recordTTL)
+	for _, rec := range records {
+		if rec.UpdatedAt.Before(cutoff) {
+			_ = os.Remove(recordPath(d.townRoot, rec.ID))
+		}
+	}
+}
+
+func (d *Deliverer) save(rec *Record) error {
+	if err := os.MkdirAll(recordsDir(d.townRoot), 0755); err != nil {
+		return fmt.Errorf("creating nudge records dir: %w", err)
+	}
+	// Keep an ack that arrived while this attempt was in flight
+	if rec.Status == StatusPending {
+		if current, err := Load(d.townRoot, rec.ID); err == nil && current.Status == StatusAcked {
+			rec.Status = current.Status
+			rec.AckedAt = current.AckedAt
+		}
+	}
+	return util.AtomicWriteJSON(recordPath(d.townRoot, rec.ID), rec)
+}
+
+func recordsDir(townRoot string) string {
+	return filepath.Join(townRoot, constants.DirRuntime, recordsDirName)
+}
+
+func recordPath(townRoot, id string) string {
+	return filepath.Join(recordsDir(townRoot), filepath.Base(id)+".json")
+}
+
+// newID returns a short random nudge ID.
+func newID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("nudge-%x", time.Now().UnixNano())
+	}
+	return "nudge-" + hex.EncodeToString(b)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,188 @@
+package nudge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePane records nudges and lets a test decide what the pane shows.
+type fakePane struct {
+	sent    []string
+	sendErr error
+	onSend  func(attempt int, text string)
+	content string
+}
+
+func (p *fakePane) NudgeSession(session, message string) error {
+	p.sent = append(p.sent, message)
+	if p.onSend != nil {
+		p.onSend(len(p.sent), message)
+	}
+	return p.sendErr
+}
+
+func (p *fakePane) CapturePane(session string, lines int) (string, error) {
+	return p.content, nil
+}
+
+// newTestDeliverer returns a Deliverer with a fake clock that advances on
+// every sleep, and records mail fallbacks.
+func newTestDeliverer(t *testing.T, pane Pane) (*Deliverer, *[]*Record) {
+	t.Helper()
+	d := NewDeliverer(t.TempDir(), pane)
+	clock := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return clock }
+	d.sleep = func(dur time.Duration) { clock = clock.Add(dur) }
+	var mailed []*Record
+	d.mail = func(rec *Record) error {
+		mailed = append(mailed, rec)
+		return nil
+	}
+	return d, &mailed
+}
+
+func idFromText(text string) string {
+	start := strings.LastIndex(text, "[") + 1
+	end := strings.Index(text[start:], ":")
+	return text[start : start+end]
+}
+
+func TestSendAcked(t *testing.T) {
+	pane := &fakePane{}
+	d, mailed := newTestDeliverer(t, pane)
+
+	// The agent acks the second attempt
+	pane.onSend = func(attempt int, text string) {
+		if attempt == 2 {
+			if _, err := Ack(d.townRoot, idFromText(text)); err != nil {
+				t.Errorf("Ack: %v", err)
+			}
+		}
+	}
+
+	rec, err := d.Send(Request{Session: "gt-gastown-alpha", Address: "gastown/alpha", Message: "rebase"}, Options{RequireAck: true, MailFallback: true})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if rec.Status != StatusAcked || rec.Attempts != 2 || rec.AckedAt == nil {
+		t.Errorf("record = %+v, want acked on attempt 2", rec)
+	}
+	if len(*mailed) != 0 {
+		t.Errorf("acked nudge should not be mailed")
+	}
+	if !strings.HasPrefix(pane.sent[0], "rebase [nudge-") {
+		t.Errorf("sent text = %q, want message with ack token", pane.sent[0])
+	}
+
+	stored, err := Load(d.townRoot, rec.ID)
+	if err != nil || stored.Status != StatusAcked {
+		t.Errorf("stored record = %+v, %v; want acked", stored, err)
+	}
+}
+
+func TestSendSeenInPane(t *testing.T) {
+	pane := &fakePane{}
+	d, _ := newTestDeliverer(t, pane)
+	pane.onSend = func(_ int, text string) {
+		// Submitted text followed by a response and the input box
+		pane.content = "> " + text + "\n\n⏺ On it.\n\n╭────╮\n│ >  │\n╰────╯\n  status\n  model"
+	}
+
+	rec, err := d.Send(Request{Session: "gt-gastown-alpha", Message: "check mail"}, Options{})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if rec.Status != StatusSeen || rec.Attempts != 1 {
+		t.Errorf("record = %+v, want seen on attempt 1", rec)
+	}
+}
+
+func TestSendFallsBackToMail(t *testing.T) {
+	pane := &fakePane{}
+	d, mailed := newTestDeliverer(t, pane)
+	// Text stuck in the input box never counts as received
+	pane.onSend = func(_ int, text string) {
+		pane.content = "⏺ Working...\n╭────╮\n│ > " + text + " │\n╰────╯\n  status"
+	}
+
+	rec, err := d.Send(Request{Session: "gt-gastown-alpha", Address: "gastown/alpha", Message: "stop"},
+		Options{Attempts: 2, AckTimeout: 5 * time.Second, MailFallback: true})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if rec.Status != StatusMailed || rec.Attempts != 2 || len(pane.sent) != 2 {
+		t.Errorf("record = %+v (sent %d), want mailed after 2 attempts", rec, len(pane.sent))
+	}
+	if len(*mailed) != 1 || (*mailed)[0].Address != "gastown/alpha" {
+		t.Errorf("mailed = %+v, want one mail to gastown/alpha", *mailed)
+	}
+}
+
+func TestSendLateAckSkipsMail(t *testing.T) {
+	pane := &fakePane{}
+	d, mailed := newTestDeliverer(t, pane)
+	// The last attempt reports an error, but the agent got it and acked
+	pane.onSend = func(attempt int, text string) {
+		if attempt == 2 {
+			if _, err := Ack(d.townRoot, idFromText(text)); err != nil {
+				t.Errorf("Ack: %v", err)
+			}
+			pane.sendErr = errors.New("send-keys timed out")
+		}
+	}
+
+	rec, err := d.Send(Request{Session: "gt-gastown-alpha", Address: "gastown/alpha", Message: "stop"},
+		Options{Attempts: 2, AckTimeout: 5 * time.Second, RequireAck: true, MailFallback: true})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if rec.Status != StatusAcked || rec.AckedAt == nil {
+		t.Errorf("record = %+v, want acked", rec)
+	}
+	if len(*mailed) != 0 {
+		t.Errorf("acked nudge should not be mailed")
+	}
+}
+
+func TestSendFailsWithoutFallback(t *testing.T) {
+	pane := &fakePane{sendErr: errors.New("no session")}
+	d, mailed := newTestDeliverer(t, pane)
+
+	rec, err := d.Send(Request{Session: "gt-gone", Address: "gastown/gone", Message: "hi"}, Options{Attempts: 3})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if rec.Status != StatusFailed || rec.Attempts != 3 || rec.Error != "no session" {
+		t.Errorf("record = %+v, want failed after 3 attempts", rec)
+	}
+	if len(*mailed) != 0 {
+		t.Errorf("mail fallback disabled, but mailed %d", len(*mailed))
+	}
+}
+
+func TestSubmitted(t *testing.T) {
+	below := "\nresponse\n╭─╮\n│>│\n╰─╯\nstatus"
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"token above input area", "> msg [nudge-1]" + below, true},
+		{"token in input area", "output\nmore\n╭─╮\n│> msg [nudge-1]│\n╰─╯\nstatus", false},
+		{"blank lines don't count", "> msg [nudge-1]\n\n\n\n\n\n\n", false},
+		{"other token", "> msg [nudge-2]" + below, false},
+	}
+	for _, tt := range tests {
+		if got := Submitted(tt.content, "nudge-1"); got != tt.want {
+			t.Errorf("%s: Submitted = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAckUnknown(t *testing.T) {
+	if _, err := Ack(t.TempDir(), "nudge-missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Ack(missing) error = %v, want ErrNotFound", err)
+	}
+}
@@ -173,6 +173,9 @@ func parsePluginMD(content []byte, pluginDir string, location Location, rigName
 	if fm.Name == "" {
 		return nil, fmt.Errorf("missing required field: name")
 	}
+	if fm.Doctor != nil && fm.Doctor.Run == "" {
+		return nil, fmt.Errorf("missing required field: doctor.run")
+	}
 
 	plugin := &Plugin{
 		Name:         fm.Name,
@@ -184,12 +187,45 @@ func parsePluginMD(content []byte, pluginDir string, location Location, rigName
 		Gate:         fm.Gate,
 		Tracking:     fm.Tracking,
 		Execution:    fm.Execution,
+		Doctor:       fm.Doctor,
 		Instructions: body,
 	}
 
 	return plugin, nil
 }
 
+// DiscoverDoctorChecks returns every plugin that declares a doctor check.
+// Unlike DiscoverAll, plugins are not deduplicated by name: a check declared
+// in several rigs runs once per rig.
+func (s *Scanner) DiscoverDoctorChecks() ([]*Plugin, error) {
+	var checks []*Plugin
+
+	townPlugins, err := s.scanTownPlugins()
+	if err != nil {
+		return nil, fmt.Errorf("scanning town plugins: %w", err)
+	}
+	for _, p := range townPlugins {
+		if p.Doctor != nil {
+			checks = append(checks, p)
+		}
+	}
+
+	for _, rigName := range s.rigNames {
+		rigPlugins, err := s.scanRigPlugins(rigName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: scanning plugins for rig %q: %v\n", rigName, err)
+			continue
+		}
+		for _, p := range rigPlugins {
+			if p.Doctor != nil {
+				checks = append(checks, p)
+			}
+		}
+	}
+
+	return checks, nil
+}
+
 // GetPlugin returns a specific plugin by name.
 // Searches rig-level plugins first (more specific), then town-level.
 func (s *Scanner) GetPlugin(name string) (*Plugin, error) {
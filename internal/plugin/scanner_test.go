@@ -276,3 +276,88 @@ version = 1
 		t.Errorf("expected location 'rig', got %q", plugins[0].Location)
 	}
 }
+
+func TestParsePluginMD_DoctorCheck(t *testing.T) {
+	content := []byte(`+++
+name = "test-db"
+description = "Test DB container"
+
+[doctor]
+category = "Rig"
+run = "./check.sh"
+fix = "./fix.sh"
+timeout = "10s"
++++
+
+# Test DB
+`)
+
+	plugin, err := parsePluginMD(content, "/test/path", LocationRig, "myrig")
+	if err != nil {
+		t.Fatalf("parsePluginMD failed: %v", err)
+	}
+	if plugin.Doctor == nil {
+		t.Fatal("expected doctor to be non-nil")
+	}
+	if plugin.Doctor.Run != "./check.sh" {
+		t.Errorf("expected run './check.sh', got %q", plugin.Doctor.Run)
+	}
+	if plugin.Doctor.Fix != "./fix.sh" {
+		t.Errorf("expected fix './fix.sh', got %q", plugin.Doctor.Fix)
+	}
+	if plugin.Doctor.Category != "Rig" {
+		t.Errorf("expected category 'Rig', got %q", plugin.Doctor.Category)
+	}
+}
+
+func TestParsePluginMD_DoctorCheckMissingRun(t *testing.T) {
+	content := []byte(`+++
+name = "broken-check"
+
+[doctor]
+fix = "./fix.sh"
++++
+`)
+
+	_, err := parsePluginMD(content, "/test/path", LocationTown, "")
+	if err == nil {
+		t.Error("expected error for doctor section without run")
+	}
+}
+
+func TestScanner_DiscoverDoctorChecks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writePlugin := func(dir, content string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "plugin.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check := "+++\nname = \"env-set\"\n\n[doctor]\nrun = \"true\"\n+++\n"
+	writePlugin(filepath.Join(tmpDir, "plugins", "env-set"), check)
+	writePlugin(filepath.Join(tmpDir, "rig1", "plugins", "env-set"), check)
+	writePlugin(filepath.Join(tmpDir, "rig2", "plugins", "env-set"), check)
+	writePlugin(filepath.Join(tmpDir, "plugins", "no-check"), "+++\nname = \"no-check\"\n+++\n")
+
+	scanner := NewScanner(tmpDir, []string{"rig1", "rig2"})
+	plugins, err := scanner.DiscoverDoctorChecks()
+	if err != nil {
+		t.Fatalf("DiscoverDoctorChecks failed: %v", err)
+	}
+
+	// Same-named checks are kept per location rather than deduplicated
+	if len(plugins) != 3 {
+		t.Fatalf("expected 3 doctor checks, got %d", len(plugins))
+	}
+	if plugins[0].Location != LocationTown {
+		t.Errorf("expected town check first, got %q", plugins[0].Location)
+	}
+	if plugins[1].RigName != "rig1" || plugins[2].RigName != "rig2" {
+		t.Errorf("expected rig checks in rig order, got %q, %q", plugins[1].RigName, plugins[2].RigName)
+	}
+}
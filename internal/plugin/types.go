@@ -41,6 +41,9 @@ type Plugin struct {
 	// Execution defines timeout and notification settings.
 	Execution *Execution `json:"execution,omitempty"`
 
+	// Doctor declares a health check contributed to `gt doctor`.
+	Doctor *DoctorCheck `json:"doctor,omitempty"`
+
 	// Instructions is the markdown body (after frontmatter).
 	Instructions string `json:"instructions,omitempty"`
 }
@@ -115,14 +118,44 @@ type Execution struct {
 	Severity string `json:"severity,omitempty" toml:"severity,omitempty"`
 }
 
+// DoctorCheck declares an executable health check run by `gt doctor`.
+//
+// The run command is executed with the plugin directory as its working
+// directory. Exit code 0 means OK, 1 means warning, anything else is an
+// error. The first line of output becomes the check message and remaining
+// lines become details.
+type DoctorCheck struct {
+	// Name overrides the check name (defaults to the plugin name).
+	Name string `json:"name,omitempty" toml:"name,omitempty"`
+
+	// Description is a human-readable description of the check.
+	Description string `json:"description,omitempty" toml:"description,omitempty"`
+
+	// Category groups the check in doctor output (e.g., "Rig", "Configuration").
+	Category string `json:"category,omitempty" toml:"category,omitempty"`
+
+	// Run is the shell command that performs the check.
+	Run string `json:"run" toml:"run"`
+
+	// Fix is an optional shell command run by `gt doctor --fix`.
+	Fix string `json:"fix,omitempty" toml:"fix,omitempty"`
+
+	// FixHint is shown when the check fails and no fix command is available.
+	FixHint string `json:"fix_hint,omitempty" toml:"fix_hint,omitempty"`
+
+	// Timeout is the maximum execution time for run and fix (e.g., "30s").
+	Timeout string `json:"timeout,omitempty" toml:"timeout,omitempty"`
+}
+
 // PluginFrontmatter represents the TOML frontmatter in plugin.md files.
 type PluginFrontmatter struct {
-	Name        string     `toml:"name"`
-	Description string     `toml:"description"`
-	Version     int        `toml:"version"`
-	Gate        *Gate      `toml:"gate,omitempty"`
-	Tracking    *Tracking  `toml:"tracking,omitempty"`
-	Execution   *Execution `toml:"execution,omitempty"`
+	Name        string       `toml:"name"`
+	Description string       `toml:"description"`
+	Version     int          `toml:"version"`
+	Gate        *Gate        `toml:"gate,omitempty"`
+	Tracking    *Tracking    `toml:"tracking,omitempty"`
+	Execution   *Execution   `toml:"execution,omitempty"`
+	Doctor      *DoctorCheck `toml:"doctor,omitempty"`
 }
 
 // PluginSummary provides a concise overview of a plugin.
@@ -47,6 +47,7 @@ type Manager struct {
 	git      *git.Git
 	beads    *beads.Beads
 	namePool *NamePool
+	pool     *WorktreePool
 }
 
 // NewManager creates a new polecat manager.
@@ -61,7 +62,12 @@ func NewManager(r *rig.Rig, g *git.Git) *Manager {
 	settingsPath := filepath.Join(r.Path, "settings", "config.json")
 	var pool *NamePool
 
+	var poolCfg *config.WorktreePoolConfig
+
 	settings, err := config.LoadRigSettings(settingsPath)
+	if err == nil {
+		poolCfg = settings.WorktreePool
+	}
 	if err == nil && settings.Namepool != nil {
 		// Use configured namepool settings
 		pool = NewNamePoolWithConfig(
@@ -77,12 +83,19 @@ func NewManager(r *rig.Rig, g *git.Git) *Manager {
 	}
 	_ = pool.Load() // non-fatal: state file may not exist for new rigs
 
-	return &Manager{
+	m := &Manager{
 		rig:      r,
 		git:      g,
 		beads:    beads.NewWithBeadsDir(beadsPath, resolvedBeads),
 		namePool: pool,
 	}
+	m.pool = newWorktreePool(m, poolCfg)
+	return m
+}
+
+// WorktreePool returns the rig's pool of warm spare worktrees.
+func (m *Manager) WorktreePool() *WorktreePool {
+	return m.pool
 }
 
 // assigneeID returns the beads assignee identifier for a polecat.
@@ -180,6 +193,15 @@ func (m *Manager) repoBase() (*git.Git, error) {
 	return git.NewGit(mayorPath), nil
 }
 
+// startPoint returns the ref new polecat worktrees start from: origin/<default-branch>.
+func (m *Manager) startPoint() string {
+	defaultBranch := "main"
+	if rigCfg, err := rig.LoadRigConfig(m.rig.Path); err == nil && rigCfg.DefaultBranch != "" {
+		defaultBranch = rigCfg.DefaultBranch
+	}
+	return fmt.Sprintf("origin/%s", defaultBranch)
+}
+
 // polecatDir returns the parent directory for a polecat.
 // This is polecats/<name>/ - the polecat's home directory.
 func (m *Manager) polecatDir(name string) string {
@@ -261,25 +283,26 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		return nil, fmt.Errorf("finding repo base: %w", err)
 	}
 
-	// Fetch latest from origin to ensure worktree starts from up-to-date code
-	if err := repoGit.Fetch("origin"); err != nil {
-		// Non-fatal - proceed with potentially stale code
-		fmt.Printf("Warning: could not fetch origin: %v\n", err)
-	}
+	// Claim a warm spare if the rig keeps a worktree pool. Spares are kept
+	// synced to origin by the daemon, so no fetch or checkout is needed here.
+	claimed := m.claimSpare(repoGit, clonePath, branchName)
 
-	// Determine the start point for the new worktree
-	// Use origin/<default-branch> to ensure we start from the rig's configured branch
-	defaultBranch := "main"
-	if rigCfg, err := rig.LoadRigConfig(m.rig.Path); err == nil && rigCfg.DefaultBranch != "" {
-		defaultBranch = rigCfg.DefaultBranch
-	}
-	startPoint := fmt.Sprintf("origin/%s", defaultBranch)
+	if !claimed {
+		// Fetch latest from origin to ensure worktree starts from up-to-date code
+		if err := repoGit.Fetch("origin"); err != nil {
+			// Non-fatal - proceed with potentially stale code
+			fmt.Printf("Warning: could not fetch origin: %v\n", err)
+		}
 
-	// Always create fresh branch - unique name guarantees no collision
-	// git worktree add -b polecat/<name>-<timestamp> <path> <startpoint>
-	// Worktree goes in polecats/<name>/<rigname>/ for LLM ergonomics
-	if err := repoGit.WorktreeAddFromRef(clonePath, branchName, startPoint); err != nil {
-		return nil, fmt.Errorf("creating worktree from %s: %w", startPoint, err)
+		// Use origin/<default-branch> to ensure we start from the rig's configured branch
+		startPoint := m.startPoint()
+
+		// Always create fresh branch - unique name guarantees no collision
+		// git worktree add -b polecat/<name>-<timestamp> <path> <startpoint>
+		// Worktree goes in polecats/<name>/<rigname>/ for LLM ergonomics
+		if err := repoGit.WorktreeAddFromRef(clonePath, branchName, startPoint); err != nil {
+			return nil, fmt.Errorf("creating worktree from %s: %w", startPoint, err)
+		}
 	}
 
 	// Ensure AGENTS.md exists - critical for polecats to "land the plane"
@@ -358,6 +381,28 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 	return polecat, nil
 }
 
+// claimSpare moves a warm spare worktree to clonePath and checks out the
+// polecat branch at the spare's commit. Returns false if no spare was
+// available or the claimed spare could not be used (it is then discarded).
+func (m *Manager) claimSpare(repoGit *git.Git, clonePath, branchName string) bool {
+	spare, err := m.pool.Claim(clonePath)
+	if err != nil {
+		fmt.Printf("Warning: could not claim spare worktree: %v\n", err)
+		return false
+	}
+	if spare == nil {
+		return false
+	}
+
+	if err := git.NewGit(clonePath).CheckoutNewBranch(branchName, spare.Commit); err != nil {
+		fmt.Printf("Warning: could not use spare worktree: %v\n", err)
+		_ = repoGit.WorktreeRemove(clonePath, true)
+		_ = os.RemoveAll(clonePath)
+		return false
+	}
+	return true
+}
+
 // Remove deletes a polecat worktree.
 // If force is true, removes even with uncommitted changes (but not stashes/unpushed).
 // Use nuclear=true to bypass ALL safety checks.
@@ -551,11 +596,7 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 
 	// Determine the start point for the new worktree
 	// Use origin/<default-branch> to ensure we start from latest fetched commits
-	defaultBranch := "main"
-	if rigCfg, err := rig.LoadRigConfig(m.rig.Path); err == nil && rigCfg.DefaultBranch != "" {
-		defaultBranch = rigCfg.DefaultBranch
-	}
-	startPoint := fmt.Sprintf("origin/%s", defaultBranch)
+	startPoint := m.startPoint()
 
 	// Create fresh worktree with unique branch name, starting from origin's default branch
 	// Old branches are left behind - they're ephemeral (never pushed to origin)
@@ -0,0 +1,122 @@
+package polecat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// sandboxCommand wraps a polecat startup command in the rig's sandbox.
+// Returns the command unchanged if the rig has no sandbox enabled.
+func (m *SessionManager) sandboxCommand(command, workDir, runtimeConfigDir string) (string, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return command, nil
+		}
+		// Can't tell whether a sandbox is required; don't guess
+		return "", fmt.Errorf("loading rig settings: %w", err)
+	}
+	if settings.Sandbox == nil || !settings.Sandbox.Enabled {
+		return command, nil
+	}
+
+	spec := SandboxSpec(m.rig.Path, workDir, runtimeConfigDir, settings.Sandbox)
+	if spec.Filesystem {
+		// Writable paths are only bound if they exist, so create the town's
+		// shared state up front
+		ensureTownStatePaths(filepath.Dir(m.rig.Path))
+	}
+	return sandbox.Wrap(command, spec)
+}
+
+// SandboxSpec builds the sandbox spec for a polecat worktree.
+// Writable: the worktree, the rig's and town's shared beads, the town's
+// events log and its lock, runtime state and logs (gt commands write them), the git
+// common dir (commits write objects and refs there), the runtime's config
+// dir, and the tmux socket dir (so gt nudge/mail can reach other agents).
+// Read-only: the town's workspace marker (so gt finds the town) and tool
+// directories on PATH under $HOME. The rest of the town root, including
+// other polecats' worktrees, is not bound.
+func SandboxSpec(rigPath, workDir, runtimeConfigDir string, cfg *config.SandboxConfig) sandbox.Spec {
+	townRoot := filepath.Dir(rigPath)
+	home, _ := os.UserHomeDir()
+
+	spec := sandbox.Spec{
+		WorkDir:     workDir,
+		CPUQuota:    cfg.CPUQuota,
+		MemoryMax:   cfg.MemoryMax,
+		TasksMax:    cfg.TasksMax,
+		Filesystem:  cfg.Filesystem,
+		DenyNetwork: cfg.Network == config.SandboxNetworkDeny,
+	}
+	if !cfg.Filesystem {
+		return spec
+	}
+
+	spec.Home = home
+	spec.ReadPaths = append(spec.ReadPaths, filepath.Join(townRoot, workspace.PrimaryMarker))
+	spec.ReadPaths = append(spec.ReadPaths, sandbox.ToolPathsUnder(home)...)
+	for _, p := range cfg.ReadPaths {
+		spec.ReadPaths = append(spec.ReadPaths, expandHome(p, home))
+	}
+
+	spec.WritePaths = append(spec.WritePaths,
+		beads.ResolveBeadsDir(rigPath),
+		filepath.Join(townRoot, ".beads"),
+		filepath.Join(townRoot, events.EventsFile),
+		filepath.Join(townRoot, events.LockFile),
+		filepath.Join(townRoot, constants.DirRuntime),
+		filepath.Join(townRoot, "logs"),
+		filepath.Join(os.TempDir(), fmt.Sprintf("tmux-%d", os.Getuid())),
+	)
+	if commonDir, err := git.NewGit(workDir).CommonDir(); err == nil {
+		spec.WritePaths = append(spec.WritePaths, commonDir)
+	}
+	if runtimeConfigDir != "" {
+		spec.WritePaths = append(spec.WritePaths, runtimeConfigDir)
+	} else if home != "" {
+		// Default Claude config locations
+		spec.WritePaths = append(spec.WritePaths,
+			filepath.Join(home, ".claude"),
+			filepath.Join(home, ".claude.json"),
+		)
+	}
+	for _, p := range cfg.WritePaths {
+		spec.WritePaths = append(spec.WritePaths, expandHome(p, home))
+	}
+
+	return spec
+}
+
+// ensureTownStatePaths creates the town's events log and its lock, runtime
+// and logs directories if they don't exist yet. Best-effort: a path that can't be
+// created just stays unbound.
+func ensureTownStatePaths(townRoot string) {
+	for _, dir := range []string{constants.DirRuntime, "logs"} {
+		_ = os.MkdirAll(filepath.Join(townRoot, dir), 0755)
+	}
+	for _, file := range []string{events.EventsFile, events.LockFile} {
+		if f, err := os.OpenFile(filepath.Join(townRoot, file), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); err == nil {
+			_ = f.Close()
+		}
+	}
+}
+
+// expandHome expands a leading ~/ to the user's home directory.
+func expandHome(path, home string) string {
+	if home != "" && (path == "~" || strings.HasPrefix(path, "~/")) {
+		return filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return path
+}
@@ -0,0 +1,71 @@
+package polecat
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestSandboxSpec(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	townRoot := filepath.Join(home, "gt")
+	rigPath := filepath.Join(townRoot, "gastown")
+	workDir := filepath.Join(rigPath, "polecats", "nux", "gastown")
+
+	cfg := &config.SandboxConfig{
+		Enabled:    true,
+		MemoryMax:  "4G",
+		Filesystem: true,
+		Network:    config.SandboxNetworkDeny,
+		WritePaths: []string{"~/.cache/go-build"},
+	}
+	spec := SandboxSpec(rigPath, workDir, "", cfg)
+
+	if spec.WorkDir != workDir || spec.MemoryMax != "4G" || !spec.DenyNetwork {
+		t.Errorf("spec = %+v", spec)
+	}
+	if spec.Home != home {
+		t.Errorf("Home = %q, want %q", spec.Home, home)
+	}
+
+	has := func(paths []string, want string) bool {
+		for _, p := range paths {
+			if p == want {
+				return true
+			}
+		}
+		return false
+	}
+	if has(spec.ReadPaths, townRoot) || has(spec.WritePaths, townRoot) {
+		t.Errorf("town root bound whole: read %v, write %v", spec.ReadPaths, spec.WritePaths)
+	}
+	if !has(spec.ReadPaths, filepath.Join(townRoot, "mayor", "town.json")) {
+		t.Errorf("ReadPaths %v missing town marker", spec.ReadPaths)
+	}
+	for _, want := range []string{
+		filepath.Join(townRoot, ".beads"),
+		filepath.Join(townRoot, ".events.jsonl"),
+		filepath.Join(townRoot, ".events.jsonl.lock"),
+		filepath.Join(townRoot, ".runtime"),
+		filepath.Join(townRoot, "logs"),
+		filepath.Join(home, ".claude"),
+		filepath.Join(home, ".cache", "go-build"),
+	} {
+		if !has(spec.WritePaths, want) {
+			t.Errorf("WritePaths %v missing %s", spec.WritePaths, want)
+		}
+	}
+}
+
+func TestSandboxSpec_ResourcesOnly(t *testing.T) {
+	spec := SandboxSpec("/town/rig", "/town/rig/polecats/nux/rig", "", &config.SandboxConfig{
+		Enabled:  true,
+		CPUQuota: "100%",
+	})
+	if spec.Filesystem || spec.Home != "" || len(spec.WritePaths) != 0 {
+		t.Errorf("resource-only spec should not set up mounts: %+v", spec)
+	}
+}
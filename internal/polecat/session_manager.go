@@ -179,6 +179,13 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		command = config.PrependEnv(command, map[string]string{runtimeConfig.Session.ConfigDirEnv: opts.RuntimeConfigDir})
 	}
 
+	// Wrap in the rig's sandbox if configured. Fails closed: a polecat is
+	// never started unsandboxed when the rig asks for a sandbox.
+	command, err = m.sandboxCommand(command, workDir, opts.RuntimeConfigDir)
+	if err != nil {
+		return fmt.Errorf("sandboxing session: %w", err)
+	}
+
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
 	if err := m.tmux.NewSessionWithCommand(sessionID, workDir, command); err != nil {
@@ -0,0 +1,397 @@
+package polecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Spare marker files. Each spare lives in polecats/.pool/<id>/ and holds
+// exactly one marker next to its worktree. Renaming the marker is the lock:
+// whoever renames .ready away owns the spare.
+const (
+	spareReadyMarker      = ".ready"
+	spareClaimedMarker    = ".claimed"
+	spareRefreshingMarker = ".refreshing"
+)
+
+// DefaultPoolRefreshInterval is how often spares are re-synced to origin
+// when the rig settings don't specify a refresh_interval.
+const DefaultPoolRefreshInterval = 15 * time.Minute
+
+// spareBusyTimeout is how long a spare may sit without a ready marker
+// (being created, claimed, or refreshed) before it is considered abandoned.
+const spareBusyTimeout = 10 * time.Minute
+
+// Spare is an idle, pre-synced worktree waiting to be claimed by a polecat.
+type Spare struct {
+	ID       string    `json:"id"`
+	Path     string    `json:"path"`
+	Commit   string    `json:"commit"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// WorktreePool keeps warm spare worktrees for a rig so polecat spawn can
+// claim a checked-out tree instead of creating one from scratch.
+// Spares are detached at origin/<default-branch>; claiming one moves it into
+// the polecat's directory and creates the polecat branch in place.
+type WorktreePool struct {
+	rig             *rig.Rig
+	size            int
+	refreshInterval time.Duration
+	repoBase        func() (*git.Git, error)
+	startPoint      func() string
+	now             func() time.Time
+}
+
+// newWorktreePool creates the pool for a manager from rig settings.
+// A nil config leaves pooling disabled (size 0).
+func newWorktreePool(m *Manager, cfg *config.WorktreePoolConfig) *WorktreePool {
+	p := &WorktreePool{
+		rig:             m.rig,
+		refreshInterval: DefaultPoolRefreshInterval,
+		repoBase:        m.repoBase,
+		startPoint:      m.startPoint,
+		now:             time.Now,
+	}
+	if cfg != nil {
+		if cfg.Size > 0 {
+			p.size = cfg.Size
+		}
+		if d, err := time.ParseDuration(cfg.RefreshInterval); err == nil && d > 0 {
+			p.refreshInterval = d
+		}
+	}
+	return p
+}
+
+// Size returns the configured number of spares (0 means pooling is disabled).
+func (p *WorktreePool) Size() int {
+	return p.size
+}
+
+// RefreshInterval returns how often spares are re-synced to origin.
+func (p *WorktreePool) RefreshInterval() time.Duration {
+	return p.refreshInterval
+}
+
+// Dir returns the directory holding the rig's spares (polecats/.pool/).
+// The leading dot keeps spares out of polecat listings.
+func (p *WorktreePool) Dir() string {
+	return filepath.Join(p.rig.Path, "polecats", ".pool")
+}
+
+// spareDir returns the directory for a spare.
+func (p *WorktreePool) spareDir(id string) string {
+	return filepath.Join(p.Dir(), id)
+}
+
+// sparePath returns the worktree path for a spare.
+// polecats/.pool/<id>/<rigname>/ has the same depth as polecats/<name>/<rigname>/
+// so relative paths (e.g. the beads redirect) stay valid after the move.
+func (p *WorktreePool) sparePath(id string) string {
+	return filepath.Join(p.spareDir(id), p.rig.Name)
+}
+
+// List returns the ready spares, most recently synced first.
+func (p *WorktreePool) List() ([]Spare, error) {
+	entries, err := os.ReadDir(p.Dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading pool dir: %w", err)
+	}
+
+	var spares []Spare
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		spare, err := p.readMarker(entry.Name(), spareReadyMarker)
+		if err != nil {
+			continue // Busy or abandoned
+		}
+		spares = append(spares, *spare)
+	}
+
+	sort.Slice(spares, func(i, j int) bool {
+		return spares[i].SyncedAt.After(spares[j].SyncedAt)
+	})
+	return spares, nil
+}
+
+// Claim moves a ready spare's worktree to dest and returns it.
+// Returns nil (no error) if no spare is available. The caller is responsible
+// for checking out the polecat branch in the claimed worktree.
+func (p *WorktreePool) Claim(dest string) (*Spare, error) {
+	spares, err := p.List()
+	if err != nil || len(spares) == 0 {
+		return nil, err
+	}
+
+	repoGit, err := p.repoBase()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spare := range spares {
+		// Take ownership; another spawn may have beaten us to this spare
+		if !p.acquire(spare.ID, spareReadyMarker, spareClaimedMarker) {
+			continue
+		}
+
+		if err := repoGit.WorktreeMove(spare.Path, dest); err != nil {
+			p.discard(repoGit, spare.ID)
+			return nil, fmt.Errorf("moving spare %s: %w", spare.ID, err)
+		}
+		_ = os.RemoveAll(p.spareDir(spare.ID))
+
+		spare.Path = dest
+		return &spare, nil
+	}
+	return nil, nil
+}
+
+// Replenish creates spares until the pool holds Size of them, removes
+// abandoned spares, and trims excess spares if Size was lowered.
+// Returns the number of spares created.
+func (p *WorktreePool) Replenish() (int, error) {
+	if err := os.MkdirAll(p.Dir(), 0755); err != nil {
+		return 0, fmt.Errorf("creating pool dir: %w", err)
+	}
+
+	repoGit, err := p.repoBase()
+	if err != nil {
+		return 0, fmt.Errorf("finding repo base: %w", err)
+	}
+
+	live := p.sweep(repoGit)
+
+	// Trim: drop the stalest ready spares beyond the configured size
+	if live > p.size {
+		spares, _ := p.List()
+		for i := len(spares) - 1; i >= 0 && live > p.size; i-- {
+			if p.acquire(spares[i].ID, spareReadyMarker, spareClaimedMarker) {
+				p.discard(repoGit, spares[i].ID)
+				live--
+			}
+		}
+		return 0, nil
+	}
+
+	if live == p.size {
+		return 0, nil
+	}
+
+	// Fetch once so all new spares start at origin's tip
+	_ = repoGit.Fetch("origin") // Non-fatal: spares start from the last fetched tip
+	startPoint := p.startPoint()
+	commit, err := repoGit.Rev(startPoint)
+	if err != nil {
+		return 0, fmt.Errorf("resolving %s: %w", startPoint, err)
+	}
+
+	created := 0
+	for ; live < p.size; live++ {
+		if err := p.create(repoGit, commit); err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
+
+// Refresh re-syncs ready spares older than RefreshInterval to origin's tip.
+// Returns the number of spares refreshed.
+func (p *WorktreePool) Refresh() (int, error) {
+	spares, err := p.List()
+	if err != nil || len(spares) == 0 {
+		return 0, err
+	}
+
+	var stale []Spare
+	for _, spare := range spares {
+		if p.now().Sub(spare.SyncedAt) >= p.refreshInterval {
+			stale = append(stale, spare)
+		}
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	repoGit, err := p.repoBase()
+	if err != nil {
+		return 0, fmt.Errorf("finding repo base: %w", err)
+	}
+	if err := repoGit.Fetch("origin"); err != nil {
+		return 0, fmt.Errorf("fetching origin: %w", err)
+	}
+	startPoint := p.startPoint()
+	commit, err := repoGit.Rev(startPoint)
+	if err != nil {
+		return 0, fmt.Errorf("resolving %s: %w", startPoint, err)
+	}
+
+	refreshed := 0
+	for _, spare := range stale {
+		if !p.acquire(spare.ID, spareReadyMarker, spareRefreshingMarker) {
+			continue // Claimed since we listed
+		}
+
+		if spare.Commit != commit {
+			if err := git.NewGit(spare.Path).Checkout(commit); err != nil {
+				p.discard(repoGit, spare.ID)
+				continue
+			}
+		}
+
+		spare.Commit = commit
+		spare.SyncedAt = p.now()
+		if err := p.writeMarker(&spare, spareReadyMarker); err != nil {
+			p.discard(repoGit, spare.ID)
+			continue
+		}
+		_ = os.Remove(filepath.Join(p.spareDir(spare.ID), spareRefreshingMarker))
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// Drain removes all ready spares. Returns the number removed.
+func (p *WorktreePool) Drain() (int, error) {
+	spares, err := p.List()
+	if err != nil || len(spares) == 0 {
+		return 0, err
+	}
+
+	repoGit, err := p.repoBase()
+	if err != nil {
+		return 0, fmt.Errorf("finding repo base: %w", err)
+	}
+
+	removed := 0
+	for _, spare := range spares {
+		if p.acquire(spare.ID, spareReadyMarker, spareClaimedMarker) {
+			p.discard(repoGit, spare.ID)
+			removed++
+		}
+	}
+	_ = repoGit.WorktreePrune()
+	return removed, nil
+}
+
+// create adds one spare detached at commit.
+func (p *WorktreePool) create(repoGit *git.Git, commit string) error {
+	// IDs are time-based; bump on collision (coarse clocks, rapid creation)
+	n := p.now().UnixNano()
+	id := strconv.FormatInt(n, 36)
+	for {
+		err := os.Mkdir(p.spareDir(id), 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("creating spare dir: %w", err)
+		}
+		n++
+		id = strconv.FormatInt(n, 36)
+	}
+
+	path := p.sparePath(id)
+	if err := repoGit.WorktreeAddDetached(path, commit); err != nil {
+		_ = os.RemoveAll(p.spareDir(id))
+		return fmt.Errorf("creating spare worktree: %w", err)
+	}
+
+	spare := &Spare{ID: id, Path: path, Commit: commit, SyncedAt: p.now()}
+	if err := p.writeMarker(spare, spareReadyMarker); err != nil {
+		p.discard(repoGit, id)
+		return fmt.Errorf("writing spare marker: %w", err)
+	}
+	return nil
+}
+
+// sweep removes abandoned spares and returns the number of live ones
+// (ready, or busy for less than spareBusyTimeout).
+func (p *WorktreePool) sweep(repoGit *git.Git) int {
+	entries, err := os.ReadDir(p.Dir())
+	if err != nil {
+		return 0
+	}
+
+	live := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		id := entry.Name()
+		dir := p.spareDir(id)
+
+		if _, err := os.Stat(filepath.Join(dir, spareReadyMarker)); err == nil {
+			live++
+			continue
+		}
+
+		// Busy: use the newest of the dir and any marker as the last sign of life
+		lastSeen := time.Time{}
+		for _, name := range []string{"", spareClaimedMarker, spareRefreshingMarker} {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.ModTime().After(lastSeen) {
+				lastSeen = info.ModTime()
+			}
+		}
+		if p.now().Sub(lastSeen) < spareBusyTimeout {
+			if _, err := os.Stat(filepath.Join(dir, spareClaimedMarker)); err != nil {
+				live++ // Being created or refreshed
+			}
+			continue
+		}
+
+		p.discard(repoGit, id)
+	}
+	return live
+}
+
+// acquire atomically renames a spare's marker, returning false if it was
+// already taken by another process.
+func (p *WorktreePool) acquire(id, from, to string) bool {
+	dir := p.spareDir(id)
+	return os.Rename(filepath.Join(dir, from), filepath.Join(dir, to)) == nil
+}
+
+// discard removes a spare's worktree and directory.
+func (p *WorktreePool) discard(repoGit *git.Git, id string) {
+	if err := repoGit.WorktreeRemove(p.sparePath(id), true); err != nil {
+		_ = repoGit.WorktreePrune()
+	}
+	_ = os.RemoveAll(p.spareDir(id))
+}
+
+// readMarker loads a spare from the given marker file.
+func (p *WorktreePool) readMarker(id, marker string) (*Spare, error) {
+	data, err := os.ReadFile(filepath.Join(p.spareDir(id), marker)) //nolint:gosec // G304: path is constructed from trusted rig path
+	if err != nil {
+		return nil, err
+	}
+	var spare Spare
+	if err := json.Unmarshal(data, &spare); err != nil {
+		return nil, err
+	}
+	spare.ID = id
+	spare.Path = p.sparePath(id)
+	return &spare, nil
+}
+
+// writeMarker writes a spare's marker file.
+func (p *WorktreePool) writeMarker(spare *Spare, marker string) error {
+	return util.AtomicWriteJSON(filepath.Join(p.spareDir(spare.ID), marker), spare)
+}
@@ -0,0 +1,210 @@
+package polecat
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// setupPoolRig creates a rig whose mayor/rig is its own origin, with a
+// worktree pool of the given size configured in settings/config.json.
+func setupPoolRig(t *testing.T, size int) (*Manager, *git.Git) {
+	t.Helper()
+	root := t.TempDir()
+
+	mayorRig := filepath.Join(root, "mayor", "rig")
+	if err := os.MkdirAll(mayorRig, 0755); err != nil {
+		t.Fatalf("mkdir mayor/rig: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = mayorRig
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", args[0], err, out)
+		}
+	}
+
+	mayorGit := git.NewGit(mayorRig)
+	commitReadme(t, mayorGit, mayorRig, "# v1\n")
+
+	cmd := exec.Command("git", "remote", "add", "origin", mayorRig)
+	cmd.Dir = mayorRig
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git remote add: %v\n%s", err, out)
+	}
+	if err := mayorGit.Fetch("origin"); err != nil {
+		t.Fatalf("git fetch: %v", err)
+	}
+
+	settings := config.NewRigSettings()
+	settings.WorktreePool = &config.WorktreePoolConfig{Size: size, RefreshInterval: "1h"}
+	if err := config.SaveRigSettings(filepath.Join(root, "settings", "config.json"), settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	r := &rig.Rig{Name: "rig", Path: root}
+	return NewManager(r, git.NewGit(root)), mayorGit
+}
+
+func commitReadme(t *testing.T, g *git.Git, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("write README.md: %v", err)
+	}
+	if err := g.Add("README.md"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if err := g.Commit("update readme"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+}
+
+func TestWorktreePoolReplenishAndClaim(t *testing.T) {
+	m, _ := setupPoolRig(t, 2)
+	pool := m.WorktreePool()
+
+	if pool.Size() != 2 || pool.RefreshInterval() != time.Hour {
+		t.Fatalf("pool config = size %d, refresh %s", pool.Size(), pool.RefreshInterval())
+	}
+
+	created, err := pool.Replenish()
+	if err != nil {
+		t.Fatalf("Replenish: %v", err)
+	}
+	if created != 2 {
+		t.Errorf("created = %d, want 2", created)
+	}
+
+	// Spares must not show up as polecats
+	polecats, err := m.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(polecats) != 0 {
+		t.Errorf("List() = %d polecats, want 0 (spares are hidden)", len(polecats))
+	}
+
+	p, err := m.AddWithOptions("Toast", AddOptions{})
+	if err != nil {
+		t.Fatalf("AddWithOptions: %v", err)
+	}
+
+	spares, _ := pool.List()
+	if len(spares) != 1 {
+		t.Errorf("spares after claim = %d, want 1", len(spares))
+	}
+
+	branch, err := git.NewGit(p.ClonePath).CurrentBranch()
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+	if branch != p.Branch || !strings.HasPrefix(branch, "polecat/Toast-") {
+		t.Errorf("claimed worktree on %q, want %q", branch, p.Branch)
+	}
+	if _, err := os.Stat(filepath.Join(p.ClonePath, "README.md")); err != nil {
+		t.Errorf("claimed worktree missing README.md: %v", err)
+	}
+
+	// Removing the polecat must clean up the moved worktree normally
+	if err := m.RemoveWithOptions("Toast", true, true); err != nil {
+		t.Fatalf("RemoveWithOptions: %v", err)
+	}
+
+	// Replenish tops the pool back up
+	created, err = pool.Replenish()
+	if err != nil {
+		t.Fatalf("Replenish: %v", err)
+	}
+	if created != 1 {
+		t.Errorf("created = %d, want 1", created)
+	}
+}
+
+func TestWorktreePoolRefresh(t *testing.T) {
+	m, mayorGit := setupPoolRig(t, 1)
+	pool := m.WorktreePool()
+
+	if _, err := pool.Replenish(); err != nil {
+		t.Fatalf("Replenish: %v", err)
+	}
+	before, _ := pool.List()
+
+	// Advance origin, then pretend the refresh interval has passed
+	commitReadme(t, mayorGit, mayorGit.WorkDir(), "# v2\n")
+	pool.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	refreshed, err := pool.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if refreshed != 1 {
+		t.Fatalf("refreshed = %d, want 1", refreshed)
+	}
+
+	after, _ := pool.List()
+	if len(after) != 1 || after[0].Commit == before[0].Commit {
+		t.Fatalf("spare commit not advanced: before %v, after %v", before, after)
+	}
+	content, err := os.ReadFile(filepath.Join(after[0].Path, "README.md"))
+	if err != nil || string(content) != "# v2\n" {
+		t.Errorf("spare README.md = %q, %v; want v2", content, err)
+	}
+}
+
+func TestWorktreePoolTrimAndDrain(t *testing.T) {
+	m, _ := setupPoolRig(t, 3)
+	pool := m.WorktreePool()
+
+	if _, err := pool.Replenish(); err != nil {
+		t.Fatalf("Replenish: %v", err)
+	}
+
+	// Lowering the size trims excess spares
+	pool.size = 1
+	if _, err := pool.Replenish(); err != nil {
+		t.Fatalf("Replenish: %v", err)
+	}
+	spares, _ := pool.List()
+	if len(spares) != 1 {
+		t.Errorf("spares after trim = %d, want 1", len(spares))
+	}
+
+	removed, err := pool.Drain()
+	if err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if spares, _ := pool.List(); len(spares) != 0 {
+		t.Errorf("spares after drain = %d, want 0", len(spares))
+	}
+}
+
+func TestWorktreePoolDisabledFallsBack(t *testing.T) {
+	m, _ := setupPoolRig(t, 0)
+
+	if spare, err := m.WorktreePool().Claim(filepath.Join(t.TempDir(), "x")); spare != nil || err != nil {
+		t.Fatalf("Claim on empty pool = %v, %v; want nil, nil", spare, err)
+	}
+
+	p, err := m.AddWithOptions("Nux", AddOptions{})
+	if err != nil {
+		t.Fatalf("AddWithOptions: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(p.ClonePath, "README.md")); err != nil {
+		t.Errorf("fresh worktree missing README.md: %v", err)
+	}
+}
@@ -191,6 +191,51 @@ func formatRebaseInstructions(targetBranch string) string {
 The Refinery will retry the merge after rebase is complete.`, targetBranch, targetBranch)
 }
 
+// NewConflictWarningMessage creates a CONFLICT_WARNING protocol message.
+// Sent by the Refinery's conflict analyzer to the Witness and affected polecats
+// so overlapping work can be coordinated before it reaches the merge queue.
+func NewConflictWarningMessage(to string, payload ConflictWarningPayload) *mail.Message {
+	if payload.DetectedAt.IsZero() {
+		payload.DetectedAt = time.Now()
+	}
+
+	subjectName := payload.Polecat
+	if subjectName == "" {
+		subjectName = payload.Branch
+	}
+
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/refinery", payload.Rig),
+		to,
+		fmt.Sprintf("CONFLICT_WARNING %s", subjectName),
+		formatConflictWarningBody(payload),
+	)
+	msg.Priority = mail.PriorityNormal
+	msg.Type = mail.TypeNotification
+
+	return msg
+}
+
+// formatConflictWarningBody formats the body of a CONFLICT_WARNING message.
+func formatConflictWarningBody(p ConflictWarningPayload) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Branch: %s\n", p.Branch))
+	if p.Polecat != "" {
+		sb.WriteString(fmt.Sprintf("Polecat: %s\n", p.Polecat))
+	}
+	sb.WriteString(fmt.Sprintf("Other-Branch: %s\n", p.OtherBranch))
+	if p.OtherPolecat != "" {
+		sb.WriteString(fmt.Sprintf("Other-Polecat: %s\n", p.OtherPolecat))
+	}
+	sb.WriteString(fmt.Sprintf("Rig: %s\n", p.Rig))
+	sb.WriteString(fmt.Sprintf("Detected-At: %s\n", p.DetectedAt.Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("Files: %s\n", strings.Join(p.Files, ", ")))
+	sb.WriteString("\n")
+	sb.WriteString("These branches modify overlapping lines and are likely to conflict at merge time.\n")
+	sb.WriteString("Coordinate with the other worker, or rebase early to keep the conflict small.\n")
+	return sb.String()
+}
+
 // ParseMergeReadyPayload parses a MERGE_READY message body into a payload.
 func ParseMergeReadyPayload(body string) *MergeReadyPayload {
 	return &MergeReadyPayload{
@@ -271,6 +316,29 @@ func ParseReworkRequestPayload(body string) *ReworkRequestPayload {
 	return payload
 }
 
+// ParseConflictWarningPayload parses a CONFLICT_WARNING message body into a payload.
+func ParseConflictWarningPayload(body string) *ConflictWarningPayload {
+	payload := &ConflictWarningPayload{
+		Branch:       parseField(body, "Branch"),
+		Polecat:      parseField(body, "Polecat"),
+		OtherBranch:  parseField(body, "Other-Branch"),
+		OtherPolecat: parseField(body, "Other-Polecat"),
+		Rig:          parseField(body, "Rig"),
+	}
+
+	if ts := parseField(body, "Detected-At"); ts != "" {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			payload.DetectedAt = t
+		}
+	}
+
+	if files := parseField(body, "Files"); files != "" {
+		payload.Files = strings.Split(files, ", ")
+	}
+
+	return payload
+}
+
 // parseField extracts a field value from a key-value body format.
 // Format: "Key: value"
 func parseField(body, key string) string {
@@ -18,6 +18,7 @@ func TestParseMessageType(t *testing.T) {
 		{"MERGED Toast", TypeMerged},
 		{"MERGE_FAILED ace", TypeMergeFailed},
 		{"REWORK_REQUEST valkyrie", TypeReworkRequest},
+		{"CONFLICT_WARNING nux", TypeConflictWarning},
 		{"MERGE_READY", TypeMergeReady}, // no polecat name
 		{"Unknown subject", ""},
 		{"", ""},
@@ -151,6 +152,37 @@ func TestNewReworkRequestMessage(t *testing.T) {
 	}
 }
 
+func TestConflictWarningRoundTrip(t *testing.T) {
+	detected := time.Now().Truncate(time.Second)
+	msg := NewConflictWarningMessage("gastown/witness", ConflictWarningPayload{
+		Branch:       "polecat/nux-123",
+		Polecat:      "nux",
+		OtherBranch:  "polecat/toast-456",
+		OtherPolecat: "toast",
+		Rig:          "gastown",
+		Files:        []string{"a.go", "b.go"},
+		DetectedAt:   detected,
+	})
+
+	if msg.From != "gastown/refinery" {
+		t.Errorf("From = %q, want %q", msg.From, "gastown/refinery")
+	}
+	if msg.Subject != "CONFLICT_WARNING nux" {
+		t.Errorf("Subject = %q, want %q", msg.Subject, "CONFLICT_WARNING nux")
+	}
+
+	payload := ParseConflictWarningPayload(msg.Body)
+	if payload.OtherPolecat != "toast" || payload.OtherBranch != "polecat/toast-456" {
+		t.Errorf("other side = %q/%q", payload.OtherPolecat, payload.OtherBranch)
+	}
+	if len(payload.Files) != 2 || payload.Files[1] != "b.go" {
+		t.Errorf("Files = %v, want [a.go b.go]", payload.Files)
+	}
+	if !payload.DetectedAt.Equal(detected) {
+		t.Errorf("DetectedAt = %v, want %v", payload.DetectedAt, detected)
+	}
+}
+
 func TestParseMergeReadyPayload(t *testing.T) {
 	body := `Branch: polecat/nux/gt-abc
 Issue: gt-abc
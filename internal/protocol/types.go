@@ -8,6 +8,7 @@
 //   - MERGED: Refinery → Witness (merge succeeded, cleanup ok)
 //   - MERGE_FAILED: Refinery → Witness (merge failed, needs rework)
 //   - REWORK_REQUEST: Refinery → Witness (rebase needed)
+//   - CONFLICT_WARNING: Refinery → Witness/Polecat (likely conflict detected early)
 package protocol
 
 import (
@@ -38,6 +39,12 @@ const (
 	// branch needs rebasing due to conflicts with the target branch.
 	// Subject format: "REWORK_REQUEST <polecat-name>"
 	TypeReworkRequest MessageType = "REWORK_REQUEST"
+
+	// TypeConflictWarning is sent from Refinery to Witness and the affected
+	// polecats when conflict pre-detection finds overlapping edits between
+	// in-flight branches, before either reaches the merge queue.
+	// Subject format: "CONFLICT_WARNING <polecat-name>"
+	TypeConflictWarning MessageType = "CONFLICT_WARNING"
 )
 
 // ParseMessageType extracts the protocol message type from a mail subject.
@@ -51,6 +58,7 @@ func ParseMessageType(subject string) MessageType {
 		TypeMerged,
 		TypeMergeFailed,
 		TypeReworkRequest,
+		TypeConflictWarning,
 	}
 
 	for _, prefix := range prefixes {
@@ -165,6 +173,32 @@ type ReworkRequestPayload struct {
 	Instructions string `json:"instructions,omitempty"`
 }
 
+// ConflictWarningPayload contains the data for a CONFLICT_WARNING message.
+// Sent by the Refinery's conflict analyzer when two in-flight branches (or a
+// branch and its target) modify overlapping lines.
+type ConflictWarningPayload struct {
+	// Branch is the branch at risk.
+	Branch string `json:"branch"`
+
+	// Polecat is the worker on Branch.
+	Polecat string `json:"polecat"`
+
+	// OtherBranch is the branch (or target) that overlaps with Branch.
+	OtherBranch string `json:"other_branch"`
+
+	// OtherPolecat is the worker on OtherBranch (empty for the target).
+	OtherPolecat string `json:"other_polecat,omitempty"`
+
+	// Rig is the rig name.
+	Rig string `json:"rig"`
+
+	// Files lists files with overlapping edits.
+	Files []string `json:"files"`
+
+	// DetectedAt is when the overlap was detected.
+	DetectedAt time.Time `json:"detected_at"`
+}
+
 // IsProtocolMessage returns true if the subject matches a known protocol type.
 func IsProtocolMessage(subject string) bool {
 	return ParseMessageType(subject) != ""
@@ -0,0 +1,377 @@
+package refinery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ConflictLevel describes how likely two branches are to conflict.
+type ConflictLevel string
+
+const (
+	// ConflictFile means both branches modify the same file in different places.
+	ConflictFile ConflictLevel = "file"
+
+	// ConflictHunk means both branches modify overlapping lines of the same file.
+	// Git will almost certainly report a merge conflict.
+	ConflictHunk ConflictLevel = "hunk"
+)
+
+// ActiveBranch is an in-flight polecat branch considered for conflict analysis.
+type ActiveBranch struct {
+	Branch  string `json:"branch"`
+	Polecat string `json:"polecat,omitempty"`
+	Issue   string `json:"issue,omitempty"`
+}
+
+// BranchOverlap records files touched by two branches (or a branch and the target).
+type BranchOverlap struct {
+	// A is the first branch.
+	A string `json:"a"`
+
+	// B is the second branch, or the target branch for branch-vs-target overlaps.
+	B string `json:"b"`
+
+	// Target is true when B is the merge target rather than another polecat branch.
+	Target bool `json:"target,omitempty"`
+
+	// Level is the most severe overlap across all shared files.
+	Level ConflictLevel `json:"level"`
+
+	// Files are all files modified by both sides.
+	Files []string `json:"files"`
+
+	// HunkFiles are the files where modified line ranges overlap.
+	HunkFiles []string `json:"hunk_files,omitempty"`
+}
+
+// Key returns a stable identifier for the overlapping pair.
+func (o BranchOverlap) Key() string {
+	return o.A + "\x00" + o.B
+}
+
+// Involves returns true if the overlap includes the given branch.
+func (o BranchOverlap) Involves(branch string) bool {
+	return o.A == branch || o.B == branch
+}
+
+// Other returns the branch on the other side of the overlap.
+func (o BranchOverlap) Other(branch string) string {
+	if o.A == branch {
+		return o.B
+	}
+	return o.A
+}
+
+// ConflictMatrix is the result of one conflict pre-detection pass over a rig.
+type ConflictMatrix struct {
+	Rig         string          `json:"rig"`
+	Target      string          `json:"target"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Branches    []ActiveBranch  `json:"branches"`
+	Overlaps    []BranchOverlap `json:"overlaps"`
+
+	// Notified records when each hunk-level overlap was first reported,
+	// so warnings are sent once per pair rather than every pass.
+	Notified map[string]time.Time `json:"notified,omitempty"`
+}
+
+// ForBranch returns the overlaps involving the given branch.
+func (m *ConflictMatrix) ForBranch(branch string) []BranchOverlap {
+	var result []BranchOverlap
+	for _, o := range m.Overlaps {
+		if o.Involves(branch) {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// Polecat returns the polecat working on a branch, if known.
+func (m *ConflictMatrix) Polecat(branch string) string {
+	for _, b := range m.Branches {
+		if b.Branch == branch {
+			return b.Polecat
+		}
+	}
+	return ""
+}
+
+// ConflictMatrixPath returns the path where a rig's conflict matrix is stored.
+func ConflictMatrixPath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "conflicts.json")
+}
+
+// LoadConflictMatrix loads a rig's most recent conflict matrix.
+// Returns nil (no error) if no analysis has been run yet.
+func LoadConflictMatrix(rigPath string) (*ConflictMatrix, error) {
+	data, err := os.ReadFile(ConflictMatrixPath(rigPath)) //nolint:gosec // G304: path is constructed from trusted rig path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var m ConflictMatrix
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing conflict matrix: %w", err)
+	}
+	return &m, nil
+}
+
+// SaveConflictMatrix writes a rig's conflict matrix.
+func SaveConflictMatrix(rigPath string, m *ConflictMatrix) error {
+	path := ConflictMatrixPath(rigPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(path, m)
+}
+
+// AnalyzeConflicts computes pairwise file and hunk overlap between the given
+// branches, and between each branch and the target. Branches that cannot be
+// resolved are skipped rather than failing the whole pass.
+func AnalyzeConflicts(g *git.Git, target string, branches []ActiveBranch) (*ConflictMatrix, error) {
+	if _, err := g.Rev(target); err != nil {
+		return nil, fmt.Errorf("resolving target %s: %w", target, err)
+	}
+
+	var valid []ActiveBranch
+	for _, b := range branches {
+		if _, err := g.Rev(b.Branch); err == nil {
+			valid = append(valid, b)
+		}
+	}
+	sort.Slice(valid, func(i, j int) bool { return valid[i].Branch < valid[j].Branch })
+
+	m := &ConflictMatrix{
+		Target:      target,
+		GeneratedAt: time.Now(),
+		Branches:    valid,
+		Overlaps:    []BranchOverlap{},
+	}
+
+	// Branch vs target: has the target moved under the branch's feet?
+	for _, b := range valid {
+		if o, ok := compareRefs(g, b.Branch, target); ok {
+			o.Target = true
+			m.Overlaps = append(m.Overlaps, o)
+		}
+	}
+
+	// Branch vs branch: will these collide when both land?
+	for i := 0; i < len(valid); i++ {
+		for j := i + 1; j < len(valid); j++ {
+			if o, ok := compareRefs(g, valid[i].Branch, valid[j].Branch); ok {
+				m.Overlaps = append(m.Overlaps, o)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// compareRefs diffs both refs against their merge base and reports any overlap.
+func compareRefs(g *git.Git, a, b string) (BranchOverlap, bool) {
+	base, err := g.MergeBase(a, b)
+	if err != nil {
+		return BranchOverlap{}, false
+	}
+
+	hunksA, err := g.DiffHunks(base, a)
+	if err != nil || len(hunksA) == 0 {
+		return BranchOverlap{}, false
+	}
+	hunksB, err := g.DiffHunks(base, b)
+	if err != nil || len(hunksB) == 0 {
+		return BranchOverlap{}, false
+	}
+
+	o := BranchOverlap{A: a, B: b, Level: ConflictFile}
+	for file, rangesA := range hunksA {
+		rangesB, shared := hunksB[file]
+		if !shared {
+			continue
+		}
+		o.Files = append(o.Files, file)
+		if rangesOverlap(rangesA, rangesB) {
+			o.HunkFiles = append(o.HunkFiles, file)
+		}
+	}
+	if len(o.Files) == 0 {
+		return BranchOverlap{}, false
+	}
+
+	sort.Strings(o.Files)
+	sort.Strings(o.HunkFiles)
+	if len(o.HunkFiles) > 0 {
+		o.Level = ConflictHunk
+	}
+	return o, true
+}
+
+// rangesOverlap returns true if any range in a overlaps any range in b.
+// File-level changes without hunks (binary files, mode changes) count as overlapping.
+func rangesOverlap(a, b []git.LineRange) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, ra := range a {
+		for _, rb := range b {
+			if ra.Overlaps(rb) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ConflictAnalyzer periodically computes the conflict matrix for a rig's
+// in-flight polecat branches and warns about newly detected hunk overlaps.
+type ConflictAnalyzer struct {
+	rig    *rig.Rig
+	router *mail.Router
+}
+
+// NewConflictAnalyzer creates a conflict analyzer for a rig.
+func NewConflictAnalyzer(r *rig.Rig) *ConflictAnalyzer {
+	return &ConflictAnalyzer{
+		rig:    r,
+		router: mail.NewRouter(r.Path),
+	}
+}
+
+// Run performs one analysis pass, saves the matrix, and mails the witness and
+// affected polecats about hunk-level overlaps that weren't reported before.
+// Returns the matrix and the newly reported overlaps.
+func (a *ConflictAnalyzer) Run() (*ConflictMatrix, []BranchOverlap, error) {
+	repo := a.repoGit()
+	if repo == nil {
+		return nil, nil, fmt.Errorf("no repo base found for rig %s", a.rig.Name)
+	}
+
+	branches, err := a.activeBranches()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target := a.rig.DefaultBranch()
+	if _, err := repo.Rev("origin/" + target); err == nil {
+		target = "origin/" + target
+	}
+
+	matrix, err := AnalyzeConflicts(repo, target, branches)
+	if err != nil {
+		return nil, nil, err
+	}
+	matrix.Rig = a.rig.Name
+
+	previous, _ := LoadConflictMatrix(a.rig.Path)
+	fresh := newHunkOverlaps(previous, matrix)
+
+	for _, o := range fresh {
+		a.notify(matrix, o)
+	}
+
+	if err := SaveConflictMatrix(a.rig.Path, matrix); err != nil {
+		return matrix, fresh, fmt.Errorf("saving conflict matrix: %w", err)
+	}
+	return matrix, fresh, nil
+}
+
+// newHunkOverlaps carries forward notification state from the previous pass
+// and returns the hunk-level overlaps that have not been reported yet.
+func newHunkOverlaps(previous, current *ConflictMatrix) []BranchOverlap {
+	current.Notified = make(map[string]time.Time)
+
+	var fresh []BranchOverlap
+	for _, o := range current.Overlaps {
+		if o.Level != ConflictHunk {
+			continue
+		}
+		if previous != nil {
+			if at, ok := previous.Notified[o.Key()]; ok {
+				current.Notified[o.Key()] = at
+				continue
+			}
+		}
+		current.Notified[o.Key()] = current.GeneratedAt
+		fresh = append(fresh, o)
+	}
+	return fresh
+}
+
+// notify sends a CONFLICT_WARNING to the witness and to each affected polecat.
+func (a *ConflictAnalyzer) notify(m *ConflictMatrix, o BranchOverlap) {
+	payload := protocol.ConflictWarningPayload{
+		Branch:      o.A,
+		Polecat:     m.Polecat(o.A),
+		OtherBranch: o.B,
+		Rig:         a.rig.Name,
+		Files:       o.HunkFiles,
+		DetectedAt:  m.GeneratedAt,
+	}
+	if !o.Target {
+		payload.OtherPolecat = m.Polecat(o.B)
+	}
+
+	recipients := []string{a.rig.Name + "/witness"}
+	for _, p := range []string{payload.Polecat, payload.OtherPolecat} {
+		if p != "" {
+			recipients = append(recipients, fmt.Sprintf("%s/polecats/%s", a.rig.Name, p))
+		}
+	}
+
+	for _, to := range recipients {
+		// Best-effort: the matrix is still saved for gt mq status and the dashboard
+		_ = a.router.Send(protocol.NewConflictWarningMessage(to, payload))
+	}
+}
+
+// repoGit returns a git handle on the rig's shared repo, where polecat branches live.
+func (a *ConflictAnalyzer) repoGit() *git.Git {
+	bareRepoPath := filepath.Join(a.rig.Path, ".repo.git")
+	if info, err := os.Stat(bareRepoPath); err == nil && info.IsDir() {
+		return git.NewGitWithDir(bareRepoPath, "")
+	}
+	mayorPath := filepath.Join(a.rig.Path, "mayor", "rig")
+	if _, err := os.Stat(mayorPath); err == nil {
+		return git.NewGit(mayorPath)
+	}
+	return nil
+}
+
+// activeBranches lists the rig's polecats that are on polecat branches.
+func (a *ConflictAnalyzer) activeBranches() ([]ActiveBranch, error) {
+	mgr := polecat.NewManager(a.rig, git.NewGit(a.rig.Path))
+	polecats, err := mgr.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing polecats: %w", err)
+	}
+
+	var branches []ActiveBranch
+	for _, p := range polecats {
+		if !strings.HasPrefix(p.Branch, "polecat/") {
+			continue
+		}
+		branches = append(branches, ActiveBranch{
+			Branch:  p.Branch,
+			Polecat: p.Name,
+			Issue:   p.Issue,
+		})
+	}
+	return branches, nil
+}
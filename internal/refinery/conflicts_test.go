@@ -0,0 +1,221 @@
+package refinery
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// setupConflictRepo creates a repo with a 10-line file on main.
+func setupConflictRepo(t *testing.T) (*git.Git, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test User"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	var lines []string
+	for i := 1; i <= 10; i++ {
+		lines = append(lines, "line")
+	}
+	g := git.NewGit(dir)
+	commitFile(t, g, dir, "shared.go", strings.Join(lines, "\n")+"\n", "initial")
+	return g, dir
+}
+
+// commitFile writes a file and commits it on the current branch.
+func commitFile(t *testing.T, g *git.Git, dir, name, content, message string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	if err := g.Add(name); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit(message); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+}
+
+// editLine returns the 10-line file with one line replaced.
+func editLine(n int, text string) string {
+	var lines []string
+	for i := 1; i <= 10; i++ {
+		if i == n {
+			lines = append(lines, text)
+		} else {
+			lines = append(lines, "line")
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestAnalyzeConflicts(t *testing.T) {
+	g, dir := setupConflictRepo(t)
+
+	branches := []struct {
+		name string
+		file string
+		body string
+	}{
+		{"polecat/a", "shared.go", editLine(2, "alpha")},
+		{"polecat/b", "shared.go", editLine(2, "bravo")},   // same line as a
+		{"polecat/c", "shared.go", editLine(9, "charlie")}, // same file, different lines
+		{"polecat/d", "other.go", "delta\n"},               // no overlap
+	}
+	for _, b := range branches {
+		if err := g.CreateBranchFrom(b.name, "main"); err != nil {
+			t.Fatalf("CreateBranchFrom: %v", err)
+		}
+		if err := g.Checkout(b.name); err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		commitFile(t, g, dir, b.file, b.body, "work on "+b.name)
+		if err := g.Checkout("main"); err != nil {
+			t.Fatalf("Checkout main: %v", err)
+		}
+	}
+
+	m, err := AnalyzeConflicts(g, "main", []ActiveBranch{
+		{Branch: "polecat/a", Polecat: "a"},
+		{Branch: "polecat/b", Polecat: "b"},
+		{Branch: "polecat/c", Polecat: "c"},
+		{Branch: "polecat/d", Polecat: "d"},
+		{Branch: "polecat/missing", Polecat: "gone"},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeConflicts: %v", err)
+	}
+
+	if len(m.Branches) != 4 {
+		t.Errorf("Branches = %v, want missing branch skipped", m.Branches)
+	}
+
+	levels := make(map[string]ConflictLevel)
+	for _, o := range m.Overlaps {
+		if o.Target {
+			t.Errorf("unexpected target overlap %+v (main has not moved)", o)
+		}
+		levels[o.A+"|"+o.B] = o.Level
+	}
+
+	want := map[string]ConflictLevel{
+		"polecat/a|polecat/b": ConflictHunk,
+		"polecat/a|polecat/c": ConflictFile,
+		"polecat/b|polecat/c": ConflictFile,
+	}
+	if len(levels) != len(want) {
+		t.Errorf("overlaps = %v, want %v", levels, want)
+	}
+	for pair, level := range want {
+		if levels[pair] != level {
+			t.Errorf("overlap %s = %q, want %q", pair, levels[pair], level)
+		}
+	}
+
+	if got := m.ForBranch("polecat/d"); len(got) != 0 {
+		t.Errorf("ForBranch(polecat/d) = %v, want none", got)
+	}
+	if got := m.Polecat("polecat/b"); got != "b" {
+		t.Errorf("Polecat(polecat/b) = %q, want b", got)
+	}
+}
+
+func TestAnalyzeConflicts_TargetMoved(t *testing.T) {
+	g, dir := setupConflictRepo(t)
+
+	if err := g.CreateBranchFrom("polecat/a", "main"); err != nil {
+		t.Fatalf("CreateBranchFrom: %v", err)
+	}
+	if err := g.Checkout("polecat/a"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	commitFile(t, g, dir, "shared.go", editLine(5, "polecat"), "polecat work")
+	if err := g.Checkout("main"); err != nil {
+		t.Fatalf("Checkout main: %v", err)
+	}
+	commitFile(t, g, dir, "shared.go", editLine(5, "landed"), "landed elsewhere")
+
+	m, err := AnalyzeConflicts(g, "main", []ActiveBranch{{Branch: "polecat/a"}})
+	if err != nil {
+		t.Fatalf("AnalyzeConflicts: %v", err)
+	}
+	if len(m.Overlaps) != 1 {
+		t.Fatalf("Overlaps = %+v, want 1", m.Overlaps)
+	}
+	o := m.Overlaps[0]
+	if !o.Target || o.B != "main" || o.Level != ConflictHunk {
+		t.Errorf("overlap = %+v, want hunk overlap with target main", o)
+	}
+}
+
+func TestAnalyzeConflicts_BadTarget(t *testing.T) {
+	g, _ := setupConflictRepo(t)
+	if _, err := AnalyzeConflicts(g, "nonexistent", nil); err == nil {
+		t.Error("expected error for unknown target")
+	}
+}
+
+func TestNewHunkOverlaps(t *testing.T) {
+	first := time.Now().Add(-time.Hour)
+	hunk := BranchOverlap{A: "polecat/a", B: "polecat/b", Level: ConflictHunk}
+	file := BranchOverlap{A: "polecat/a", B: "polecat/c", Level: ConflictFile}
+	fresh := BranchOverlap{A: "polecat/b", B: "polecat/c", Level: ConflictHunk}
+
+	previous := &ConflictMatrix{Notified: map[string]time.Time{hunk.Key(): first}}
+	current := &ConflictMatrix{
+		GeneratedAt: time.Now(),
+		Overlaps:    []BranchOverlap{hunk, file, fresh},
+	}
+
+	got := newHunkOverlaps(previous, current)
+	if len(got) != 1 || got[0].Key() != fresh.Key() {
+		t.Errorf("newHunkOverlaps = %+v, want only %s", got, fresh.Key())
+	}
+	if !current.Notified[hunk.Key()].Equal(first) {
+		t.Error("notification time for previously reported overlap not carried forward")
+	}
+	if _, ok := current.Notified[file.Key()]; ok {
+		t.Error("file-level overlap should not be recorded as notified")
+	}
+}
+
+func TestConflictMatrixRoundTrip(t *testing.T) {
+	rigPath := t.TempDir()
+
+	m, err := LoadConflictMatrix(rigPath)
+	if err != nil || m != nil {
+		t.Fatalf("LoadConflictMatrix before save = %v, %v; want nil, nil", m, err)
+	}
+
+	saved := &ConflictMatrix{
+		Rig:      "gastown",
+		Target:   "origin/main",
+		Overlaps: []BranchOverlap{{A: "polecat/a", B: "polecat/b", Level: ConflictHunk, Files: []string{"x.go"}}},
+	}
+	if err := SaveConflictMatrix(rigPath, saved); err != nil {
+		t.Fatalf("SaveConflictMatrix: %v", err)
+	}
+
+	loaded, err := LoadConflictMatrix(rigPath)
+	if err != nil {
+		t.Fatalf("LoadConflictMatrix: %v", err)
+	}
+	if loaded.Rig != "gastown" || len(loaded.Overlaps) != 1 || loaded.Overlaps[0].Level != ConflictHunk {
+		t.Errorf("loaded = %+v", loaded)
+	}
+}
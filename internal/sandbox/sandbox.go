@@ -0,0 +1,207 @@
+// Package sandbox wraps agent startup commands in resource and filesystem limits.
+//
+// Resource limits use a transient cgroup v2 scope via systemd-run --user
+// (CPU quota, memory ceiling, task count). Filesystem and network isolation
+// use bubblewrap: the host root is mounted read-only, the user's home is
+// masked, and only the agent's worktree and explicitly listed paths
+// (shared beads, the git object store, runtime config) are writable.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnavailable indicates a required sandbox tool is not installed.
+var ErrUnavailable = errors.New("sandbox unavailable")
+
+// Tool names, overridable for tests.
+var (
+	systemdRunBin = "systemd-run"
+	bwrapBin      = "bwrap"
+	lookPath      = exec.LookPath
+)
+
+// Spec describes the limits to apply to one agent session.
+type Spec struct {
+	// WorkDir is the agent's worktree. Always writable; the sandbox starts here.
+	WorkDir string
+
+	// WritePaths are additional writable paths (shared beads, git common dir,
+	// runtime config). Missing paths are skipped.
+	WritePaths []string
+
+	// ReadPaths are paths re-exposed read-only after the home directory is
+	// masked (the town's workspace marker, tool directories under $HOME).
+	// Missing paths are skipped.
+	ReadPaths []string
+
+	// Home is masked with an empty tmpfs so credentials under it aren't
+	// readable. Empty means the home directory stays visible (read-only).
+	Home string
+
+	// CPUQuota is a systemd CPUQuota value (e.g., "200%" for two cores).
+	CPUQuota string
+
+	// MemoryMax is a systemd MemoryMax value (e.g., "4G").
+	MemoryMax string
+
+	// TasksMax caps the number of processes/threads (0 = no limit).
+	TasksMax int
+
+	// Filesystem enables the bubblewrap mount namespace.
+	Filesystem bool
+
+	// DenyNetwork runs the agent in an empty network namespace.
+	DenyNetwork bool
+}
+
+// hasResourceLimits returns true if any cgroup limit is requested.
+func (s Spec) hasResourceLimits() bool {
+	return s.CPUQuota != "" || s.MemoryMax != "" || s.TasksMax > 0
+}
+
+// hasNamespace returns true if bubblewrap is needed.
+func (s Spec) hasNamespace() bool {
+	return s.Filesystem || s.DenyNetwork
+}
+
+// Enabled returns true if the spec applies any limit at all.
+func (s Spec) Enabled() bool {
+	return s.hasResourceLimits() || s.hasNamespace()
+}
+
+// CheckAvailable verifies that the tools needed for spec are installed.
+func CheckAvailable(spec Spec) error {
+	if spec.hasResourceLimits() {
+		if _, err := lookPath(systemdRunBin); err != nil {
+			return fmt.Errorf("%w: %s not found (needed for cpu/memory/task limits)", ErrUnavailable, systemdRunBin)
+		}
+	}
+	if spec.hasNamespace() {
+		if _, err := lookPath(bwrapBin); err != nil {
+			return fmt.Errorf("%w: %s not found (needed for filesystem/network isolation)", ErrUnavailable, bwrapBin)
+		}
+	}
+	return nil
+}
+
+// Wrap returns command wrapped so it runs under spec's limits.
+// command is a shell command line (as passed to tmux); it is run via sh -c
+// inside the sandbox so its env exports still apply. Returns an error rather
+// than an unsandboxed command if a required tool is missing.
+func Wrap(command string, spec Spec) (string, error) {
+	if !spec.Enabled() {
+		return command, nil
+	}
+	if err := CheckAvailable(spec); err != nil {
+		return "", err
+	}
+
+	var args []string
+
+	if spec.hasResourceLimits() {
+		args = append(args, systemdRunBin, "--user", "--scope", "--quiet", "--collect")
+		if spec.CPUQuota != "" {
+			args = append(args, "-p", "CPUQuota="+spec.CPUQuota)
+		}
+		if spec.MemoryMax != "" {
+			args = append(args, "-p", "MemoryMax="+spec.MemoryMax)
+		}
+		if spec.TasksMax > 0 {
+			args = append(args, "-p", fmt.Sprintf("TasksMax=%d", spec.TasksMax))
+		}
+		args = append(args, "--")
+	}
+
+	if spec.hasNamespace() {
+		args = append(args, bwrapArgs(spec)...)
+		args = append(args, "--")
+	}
+
+	args = append(args, "sh", "-c", command)
+
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " "), nil
+}
+
+// bwrapArgs builds the bubblewrap invocation (without the trailing command).
+func bwrapArgs(spec Spec) []string {
+	args := []string{bwrapBin, "--die-with-parent"}
+
+	if spec.Filesystem {
+		args = append(args,
+			"--ro-bind", "/", "/",
+			"--dev", "/dev",
+			"--proc", "/proc",
+			"--tmpfs", "/tmp",
+		)
+		if spec.Home != "" {
+			args = append(args, "--tmpfs", spec.Home)
+		}
+		for _, p := range spec.ReadPaths {
+			args = append(args, "--ro-bind-try", p, p)
+		}
+		for _, p := range spec.WritePaths {
+			args = append(args, "--bind-try", p, p)
+		}
+		args = append(args, "--bind", spec.WorkDir, spec.WorkDir)
+		if spec.Home != "" {
+			args = append(args, "--setenv", "HOME", spec.Home)
+		}
+	} else {
+		// Network-only isolation: keep the host filesystem as-is
+		args = append(args, "--bind", "/", "/", "--dev-bind", "/dev", "/dev", "--proc", "/proc")
+	}
+
+	if spec.DenyNetwork {
+		args = append(args, "--unshare-net")
+	}
+
+	if spec.WorkDir != "" {
+		args = append(args, "--chdir", spec.WorkDir)
+	}
+	return args
+}
+
+// ToolPathsUnder returns the PATH entries under dir, so tools installed in
+// the user's home (e.g., ~/go/bin, ~/.local/bin) stay runnable after the
+// home directory is masked.
+func ToolPathsUnder(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	var paths []string
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	for _, p := range filepath.SplitList(os.Getenv("PATH")) {
+		if strings.HasPrefix(filepath.Clean(p)+string(filepath.Separator), prefix) {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// shellQuote quotes s for POSIX sh if it contains anything but safe characters.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:%,+@", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,110 @@
+package sandbox
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// withTools fakes which sandbox tools are installed.
+func withTools(t *testing.T, installed ...string) {
+	t.Helper()
+	orig := lookPath
+	lookPath = func(name string) (string, error) {
+		for _, n := range installed {
+			if n == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Cleanup(func() { lookPath = orig })
+}
+
+func TestWrapDisabled(t *testing.T) {
+	withTools(t)
+	got, err := Wrap("claude", Spec{WorkDir: "/w"})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if got != "claude" {
+		t.Errorf("Wrap with empty spec = %q, want command unchanged", got)
+	}
+}
+
+func TestWrapResourceLimits(t *testing.T) {
+	withTools(t, "systemd-run")
+	got, err := Wrap("export A=1 && claude", Spec{CPUQuota: "200%", MemoryMax: "4G", TasksMax: 512})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+
+	want := "systemd-run --user --scope --quiet --collect -p CPUQuota=200% -p MemoryMax=4G -p TasksMax=512 -- sh -c 'export A=1 && claude'"
+	if got != want {
+		t.Errorf("Wrap =\n  %s\nwant\n  %s", got, want)
+	}
+	if strings.Contains(got, "bwrap") {
+		t.Error("bwrap should not be used without filesystem/network isolation")
+	}
+}
+
+func TestWrapFilesystem(t *testing.T) {
+	withTools(t, "bwrap")
+	spec := Spec{
+		WorkDir:     "/home/u/gt/rig/polecats/nux/rig",
+		Home:        "/home/u",
+		ReadPaths:   []string{"/home/u/gt"},
+		WritePaths:  []string{"/home/u/gt/rig/.beads"},
+		Filesystem:  true,
+		DenyNetwork: true,
+	}
+	got, err := Wrap("claude --dangerously-skip-permissions", spec)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+
+	// Order matters: home is masked before paths under it are re-exposed
+	for _, want := range []string{
+		"bwrap --die-with-parent --ro-bind / / ",
+		"--tmpfs /home/u --ro-bind-try /home/u/gt /home/u/gt --bind-try /home/u/gt/rig/.beads /home/u/gt/rig/.beads --bind " + spec.WorkDir,
+		"--unshare-net",
+		"--chdir " + spec.WorkDir,
+		"-- sh -c 'claude --dangerously-skip-permissions'",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Wrap output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWrapFailsClosed(t *testing.T) {
+	withTools(t, "systemd-run")
+	_, err := Wrap("claude", Spec{WorkDir: "/w", Filesystem: true, MemoryMax: "1G"})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Wrap without bwrap = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"":              "''",
+		"plain/path-1":  "plain/path-1",
+		"CPUQuota=200%": "CPUQuota=200%",
+		"a b":           "'a b'",
+		"it's":          `'it'\''s'`,
+	}
+	for in, want := range tests {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestToolPathsUnder(t *testing.T) {
+	t.Setenv("PATH", "/home/u/go/bin:/usr/bin:/home/u/.local/bin:/home/user2/bin")
+	got := ToolPathsUnder("/home/u")
+	if len(got) != 2 || got[0] != "/home/u/go/bin" || got[1] != "/home/u/.local/bin" {
+		t.Errorf("ToolPathsUnder = %v", got)
+	}
+}
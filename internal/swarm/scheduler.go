@@ -0,0 +1,402 @@
+package swarm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Scheduler defaults.
+const (
+	// DefaultTargetWorkers is the number of concurrent workers per swarm.
+	DefaultTargetWorkers = 3
+
+	// DefaultStallTimeout is how long a task's worker may be dead before
+	// the task is re-queued.
+	DefaultStallTimeout = 10 * time.Minute
+
+	// DefaultMaxAttempts is how many times a task may be dispatched before
+	// the swarm is marked failed.
+	DefaultMaxAttempts = 3
+)
+
+// Schedule is the persisted scheduler record for one swarm.
+// Records live in <rig>/.runtime/swarms/<swarm-id>.json and are advanced
+// by the daemon heartbeat (or `gt swarm tick` from a witness patrol).
+type Schedule struct {
+	// SwarmID is the swarm (epic) being scheduled.
+	SwarmID string `json:"swarm_id"`
+
+	// RigName is the rig the swarm operates in.
+	RigName string `json:"rig_name"`
+
+	// State is the scheduler's view of the swarm lifecycle.
+	State SwarmState `json:"state"`
+
+	// TargetWorkers is the maximum number of tasks in flight at once.
+	TargetWorkers int `json:"target_workers"`
+
+	// StallTimeout is how long a task's worker may be dead before re-queue.
+	StallTimeout time.Duration `json:"stall_timeout"`
+
+	// MaxAttempts is how many dispatches a task gets before the swarm fails.
+	MaxAttempts int `json:"max_attempts"`
+
+	// Attempts counts dispatches per task.
+	Attempts map[string]int `json:"attempts,omitempty"`
+
+	// Dispatched records when each task was last dispatched.
+	Dispatched map[string]time.Time `json:"dispatched,omitempty"`
+
+	// DeadSince records when a task's worker was first seen dead.
+	DeadSince map[string]time.Time `json:"dead_since,omitempty"`
+
+	// CreatedAt is when scheduling started.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the scheduler last advanced this swarm.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Error contains failure details if State is SwarmFailed.
+	Error string `json:"error,omitempty"`
+}
+
+// NewSchedule creates a schedule for a swarm with default settings.
+func NewSchedule(swarmID, rigName string) *Schedule {
+	now := time.Now()
+	return &Schedule{
+		SwarmID:       swarmID,
+		RigName:       rigName,
+		State:         SwarmCreated,
+		TargetWorkers: DefaultTargetWorkers,
+		StallTimeout:  DefaultStallTimeout,
+		MaxAttempts:   DefaultMaxAttempts,
+		Attempts:      make(map[string]int),
+		Dispatched:    make(map[string]time.Time),
+		DeadSince:     make(map[string]time.Time),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// ScheduleDir returns the directory holding a rig's swarm schedules.
+func ScheduleDir(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "swarms")
+}
+
+// schedulePath returns the path to a swarm's schedule file.
+func schedulePath(rigPath, swarmID string) string {
+	return filepath.Join(ScheduleDir(rigPath), swarmID+".json")
+}
+
+// LoadSchedule loads a swarm's schedule from the rig runtime directory.
+// Returns ErrSwarmNotFound if the swarm is not scheduled.
+func LoadSchedule(rigPath, swarmID string) (*Schedule, error) {
+	data, err := os.ReadFile(schedulePath(rigPath, swarmID)) //nolint:gosec // G304: path is constructed from trusted rig path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrSwarmNotFound
+		}
+		return nil, err
+	}
+
+	var s Schedule
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing schedule %s: %w", swarmID, err)
+	}
+	s.ensureMaps()
+	return &s, nil
+}
+
+// SaveSchedule writes a swarm's schedule to the rig runtime directory.
+func SaveSchedule(rigPath string, s *Schedule) error {
+	if err := os.MkdirAll(ScheduleDir(rigPath), 0755); err != nil {
+		return fmt.Errorf("creating schedule dir: %w", err)
+	}
+	return util.AtomicWriteJSON(schedulePath(rigPath, s.SwarmID), s)
+}
+
+// RemoveSchedule stops scheduling a swarm. Removing a missing schedule is not an error.
+func RemoveSchedule(rigPath, swarmID string) error {
+	if err := os.Remove(schedulePath(rigPath, swarmID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ListSchedules returns all swarm schedules in a rig, sorted by swarm ID.
+func ListSchedules(rigPath string) ([]*Schedule, error) {
+	entries, err := os.ReadDir(ScheduleDir(rigPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var schedules []*Schedule
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		s, err := LoadSchedule(rigPath, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		schedules = append(schedules, s)
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].SwarmID < schedules[j].SwarmID
+	})
+	return schedules, nil
+}
+
+// ensureMaps initializes nil maps after decoding.
+func (s *Schedule) ensureMaps() {
+	if s.Attempts == nil {
+		s.Attempts = make(map[string]int)
+	}
+	if s.Dispatched == nil {
+		s.Dispatched = make(map[string]time.Time)
+	}
+	if s.DeadSince == nil {
+		s.DeadSince = make(map[string]time.Time)
+	}
+}
+
+// transition moves the schedule to a new state if the transition is valid.
+func (s *Schedule) transition(to SwarmState) bool {
+	if s.State == to || !isValidTransition(s.State, to) {
+		return false
+	}
+	s.State = to
+	return true
+}
+
+// TaskSource provides swarm state to the scheduler. *Manager implements it.
+type TaskSource interface {
+	LoadSwarm(swarmID string) (*Swarm, error)
+	GetReadyTasks(swarmID string) ([]SwarmTask, error)
+	RequeueTask(issueID string) error
+}
+
+// Dispatcher starts work on a task, typically by slinging it to a fresh polecat.
+type Dispatcher interface {
+	Dispatch(rigName, issueID string) error
+}
+
+// WorkerAliveFunc reports whether the worker assigned to a task is still running.
+type WorkerAliveFunc func(rigName, assignee string) bool
+
+// Scheduler keeps swarms fed with work: it dispatches newly unblocked tasks
+// up to a target worker count, re-queues tasks whose worker died, and
+// advances the swarm state as work completes.
+type Scheduler struct {
+	source     TaskSource
+	dispatcher Dispatcher
+	alive      WorkerAliveFunc
+	now        func() time.Time
+}
+
+// NewScheduler creates a scheduler.
+func NewScheduler(source TaskSource, dispatcher Dispatcher, alive WorkerAliveFunc) *Scheduler {
+	return &Scheduler{
+		source:     source,
+		dispatcher: dispatcher,
+		alive:      alive,
+		now:        time.Now,
+	}
+}
+
+// TickResult summarizes what one scheduler tick did.
+type TickResult struct {
+	SwarmID    string     `json:"swarm_id"`
+	State      SwarmState `json:"state"`
+	Active     int        `json:"active"`
+	Dispatched []string   `json:"dispatched,omitempty"`
+	Requeued   []string   `json:"requeued,omitempty"`
+	Errors     []string   `json:"errors,omitempty"`
+}
+
+// Tick advances one swarm's schedule. The schedule is updated in place;
+// the caller is responsible for saving it.
+func (s *Scheduler) Tick(sched *Schedule) (*TickResult, error) {
+	sched.ensureMaps()
+	result := &TickResult{SwarmID: sched.SwarmID, State: sched.State}
+
+	if sched.State.IsTerminal() {
+		return result, nil
+	}
+
+	swarm, err := s.source.LoadSwarm(sched.SwarmID)
+	if err != nil {
+		return result, fmt.Errorf("loading swarm %s: %w", sched.SwarmID, err)
+	}
+
+	now := s.now()
+	sched.UpdatedAt = now
+
+	// Epic closed: the swarm has landed
+	if swarm.State == SwarmLanded {
+		sched.transition(SwarmActive)
+		sched.transition(SwarmMerging)
+		sched.transition(SwarmLanded)
+		result.State = sched.State
+		return result, nil
+	}
+
+	// Detect stalled tasks and re-queue them
+	active := 0
+	for _, task := range swarm.Tasks {
+		if task.State != TaskInProgress {
+			delete(sched.DeadSince, task.IssueID)
+			// Count dispatches that beads hasn't caught up with yet
+			if at, ok := sched.Dispatched[task.IssueID]; ok && !task.State.IsComplete() && now.Sub(at) < sched.StallTimeout {
+				active++
+			}
+			continue
+		}
+		if task.Assignee == "" || s.alive == nil || s.alive(sched.RigName, task.Assignee) {
+			delete(sched.DeadSince, task.IssueID)
+			active++
+			continue
+		}
+
+		deadSince, seen := sched.DeadSince[task.IssueID]
+		if !seen {
+			sched.DeadSince[task.IssueID] = now
+			active++
+			continue
+		}
+		if now.Sub(deadSince) < sched.StallTimeout {
+			active++
+			continue
+		}
+
+		if sched.Attempts[task.IssueID] >= sched.MaxAttempts {
+			sched.transition(SwarmFailed)
+			sched.Error = fmt.Sprintf("task %s stalled after %d attempts", task.IssueID, sched.Attempts[task.IssueID])
+			result.State = sched.State
+			return result, nil
+		}
+
+		if err := s.source.RequeueTask(task.IssueID); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("requeue %s: %v", task.IssueID, err))
+			active++
+			continue
+		}
+		delete(sched.DeadSince, task.IssueID)
+		delete(sched.Dispatched, task.IssueID)
+		result.Requeued = append(result.Requeued, task.IssueID)
+	}
+
+	// All tasks merged: hand off to the refinery for landing
+	if len(swarm.Tasks) > 0 && allMerged(swarm.Tasks) {
+		sched.transition(SwarmActive)
+		sched.transition(SwarmMerging)
+		result.State = sched.State
+		result.Active = active
+		return result, nil
+	}
+
+	// Feed ready tasks up to the target worker count
+	slots := sched.TargetWorkers - active
+	if slots > 0 {
+		ready, err := s.source.GetReadyTasks(sched.SwarmID)
+		if err != nil && !errors.Is(err, ErrNoReadyTasks) {
+			return result, fmt.Errorf("getting ready tasks for %s: %w", sched.SwarmID, err)
+		}
+
+		for _, task := range ready {
+			if slots == 0 {
+				break
+			}
+			if task.Assignee != "" {
+				continue
+			}
+			// A recent dispatch may not be reflected in beads yet
+			if at, ok := sched.Dispatched[task.IssueID]; ok && now.Sub(at) < sched.StallTimeout {
+				continue
+			}
+			if sched.Attempts[task.IssueID] >= sched.MaxAttempts {
+				// Ready again after its last attempt: no worker finished it
+				sched.transition(SwarmFailed)
+				sched.Error = fmt.Sprintf("task %s still unfinished after %d attempts", task.IssueID, sched.Attempts[task.IssueID])
+				result.State = sched.State
+				return result, nil
+			}
+
+			if err := s.dispatcher.Dispatch(sched.RigName, task.IssueID); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("dispatch %s: %v", task.IssueID, err))
+				continue
+			}
+			sched.Attempts[task.IssueID]++
+			sched.Dispatched[task.IssueID] = now
+			result.Dispatched = append(result.Dispatched, task.IssueID)
+			active++
+			slots--
+		}
+	}
+
+	if active > 0 {
+		sched.transition(SwarmActive)
+	}
+
+	result.State = sched.State
+	result.Active = active
+	return result, nil
+}
+
+// allMerged returns true if every task has been merged.
+func allMerged(tasks []SwarmTask) bool {
+	for _, task := range tasks {
+		if task.State != TaskMerged {
+			return false
+		}
+	}
+	return true
+}
+
+// RequeueTask returns a task to the ready pool by reopening it and clearing
+// its assignee, so the scheduler can dispatch it to a fresh worker.
+func (m *Manager) RequeueTask(issueID string) error {
+	cmd := exec.Command("bd", "update", issueID, "--status=open", "--assignee=")
+	cmd.Dir = m.beadsDir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("bd update: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// SlingDispatcher dispatches tasks to fresh polecats via `gt sling`.
+type SlingDispatcher struct {
+	TownRoot string
+}
+
+// Dispatch slings the task to the rig, which spawns a fresh polecat.
+func (d *SlingDispatcher) Dispatch(rigName, issueID string) error {
+	cmd := exec.Command("gt", "sling", issueID, rigName) //nolint:gosec // G204: args are validated bead and rig names
+	cmd.Dir = d.TownRoot
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("gt sling: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package swarm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSource is an in-memory TaskSource for scheduler tests.
+type fakeSource struct {
+	swarm    *Swarm
+	requeued []string
+}
+
+func (f *fakeSource) LoadSwarm(string) (*Swarm, error) {
+	return f.swarm, nil
+}
+
+func (f *fakeSource) GetReadyTasks(string) ([]SwarmTask, error) {
+	var ready []SwarmTask
+	for _, task := range f.swarm.Tasks {
+		if task.State == TaskPending {
+			ready = append(ready, task)
+		}
+	}
+	if len(ready) == 0 {
+		return nil, ErrNoReadyTasks
+	}
+	return ready, nil
+}
+
+func (f *fakeSource) RequeueTask(issueID string) error {
+	f.requeued = append(f.requeued, issueID)
+	for i := range f.swarm.Tasks {
+		if f.swarm.Tasks[i].IssueID == issueID {
+			f.swarm.Tasks[i].State = TaskPending
+			f.swarm.Tasks[i].Assignee = ""
+		}
+	}
+	return nil
+}
+
+// fakeDispatcher records dispatches and marks tasks in progress.
+type fakeDispatcher struct {
+	source     *fakeSource
+	dispatched []string
+}
+
+func (f *fakeDispatcher) Dispatch(_, issueID string) error {
+	f.dispatched = append(f.dispatched, issueID)
+	for i := range f.source.swarm.Tasks {
+		if f.source.swarm.Tasks[i].IssueID == issueID {
+			f.source.swarm.Tasks[i].State = TaskInProgress
+			f.source.swarm.Tasks[i].Assignee = "rig/polecats/p-" + issueID
+		}
+	}
+	return nil
+}
+
+func newTestScheduler(tasks ...SwarmTask) (*Scheduler, *fakeSource, *fakeDispatcher, map[string]bool) {
+	source := &fakeSource{swarm: &Swarm{ID: "sw-1", State: SwarmActive, Tasks: tasks}}
+	dispatcher := &fakeDispatcher{source: source}
+	dead := make(map[string]bool)
+	alive := func(_, assignee string) bool { return !dead[assignee] }
+	return NewScheduler(source, dispatcher, alive), source, dispatcher, dead
+}
+
+func TestSchedulerDispatchesUpToTarget(t *testing.T) {
+	s, _, dispatcher, _ := newTestScheduler(
+		SwarmTask{IssueID: "a", State: TaskPending},
+		SwarmTask{IssueID: "b", State: TaskPending},
+		SwarmTask{IssueID: "c", State: TaskPending},
+	)
+	sched := NewSchedule("sw-1", "rig")
+	sched.TargetWorkers = 2
+
+	result, err := s.Tick(sched)
+	if err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(dispatcher.dispatched) != 2 {
+		t.Errorf("dispatched %v, want 2 tasks", dispatcher.dispatched)
+	}
+	if result.Active != 2 {
+		t.Errorf("Active = %d, want 2", result.Active)
+	}
+	if sched.State != SwarmActive {
+		t.Errorf("State = %s, want %s", sched.State, SwarmActive)
+	}
+
+	// Second tick: no free slots
+	if _, err := s.Tick(sched); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(dispatcher.dispatched) != 2 {
+		t.Errorf("dispatched %v after full tick, want still 2", dispatcher.dispatched)
+	}
+}
+
+func TestSchedulerRefillsAsWorkersFinish(t *testing.T) {
+	s, source, dispatcher, _ := newTestScheduler(
+		SwarmTask{IssueID: "a", State: TaskPending},
+		SwarmTask{IssueID: "b", State: TaskPending},
+	)
+	sched := NewSchedule("sw-1", "rig")
+	sched.TargetWorkers = 1
+
+	if _, err := s.Tick(sched); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	source.swarm.Tasks[0].State = TaskMerged
+
+	if _, err := s.Tick(sched); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(dispatcher.dispatched) != 2 || dispatcher.dispatched[1] != "b" {
+		t.Errorf("dispatched %v, want [a b]", dispatcher.dispatched)
+	}
+}
+
+func TestSchedulerRequeuesStalledTask(t *testing.T) {
+	s, source, dispatcher, dead := newTestScheduler(
+		SwarmTask{IssueID: "a", State: TaskInProgress, Assignee: "rig/polecats/dead"},
+	)
+	dead["rig/polecats/dead"] = true
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	sched := NewSchedule("sw-1", "rig")
+	sched.State = SwarmActive
+
+	// First sighting starts the stall clock
+	if _, err := s.Tick(sched); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(source.requeued) != 0 {
+		t.Fatalf("requeued %v before stall timeout", source.requeued)
+	}
+
+	now = now.Add(sched.StallTimeout)
+	result, err := s.Tick(sched)
+	if err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(result.Requeued) != 1 || result.Requeued[0] != "a" {
+		t.Errorf("Requeued = %v, want [a]", result.Requeued)
+	}
+	if len(dispatcher.dispatched) != 1 || dispatcher.dispatched[0] != "a" {
+		t.Errorf("dispatched %v, want [a] re-dispatched", dispatcher.dispatched)
+	}
+}
+
+func TestSchedulerFailsAfterMaxAttempts(t *testing.T) {
+	s, _, _, dead := newTestScheduler(
+		SwarmTask{IssueID: "a", State: TaskInProgress, Assignee: "rig/polecats/dead"},
+	)
+	dead["rig/polecats/dead"] = true
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	sched := NewSchedule("sw-1", "rig")
+	sched.State = SwarmActive
+	sched.Attempts["a"] = sched.MaxAttempts
+	sched.DeadSince["a"] = now.Add(-sched.StallTimeout)
+
+	result, err := s.Tick(sched)
+	if err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if result.State != SwarmFailed {
+		t.Errorf("State = %s, want %s", result.State, SwarmFailed)
+	}
+	if sched.Error == "" {
+		t.Error("expected failure reason")
+	}
+}
+
+func TestSchedulerFailsReadyTaskOutOfAttempts(t *testing.T) {
+	s, _, dispatcher, _ := newTestScheduler(
+		SwarmTask{IssueID: "a", State: TaskPending},
+	)
+	sched := NewSchedule("sw-1", "rig")
+	sched.State = SwarmActive
+	sched.Attempts["a"] = sched.MaxAttempts
+
+	result, err := s.Tick(sched)
+	if err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if result.State != SwarmFailed {
+		t.Errorf("State = %s, want %s", result.State, SwarmFailed)
+	}
+	if !strings.Contains(sched.Error, "task a ") || len(dispatcher.dispatched) != 0 {
+		t.Errorf("Error = %q, dispatched = %v; want failure naming a, no dispatch", sched.Error, dispatcher.dispatched)
+	}
+}
+
+func TestSchedulerStateTransitions(t *testing.T) {
+	s, source, _, _ := newTestScheduler(
+		SwarmTask{IssueID: "a", State: TaskMerged},
+	)
+	sched := NewSchedule("sw-1", "rig")
+
+	if _, err := s.Tick(sched); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if sched.State != SwarmMerging {
+		t.Errorf("State = %s, want %s", sched.State, SwarmMerging)
+	}
+
+	source.swarm.State = SwarmLanded
+	if _, err := s.Tick(sched); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if sched.State != SwarmLanded {
+		t.Errorf("State = %s, want %s", sched.State, SwarmLanded)
+	}
+}
+
+func TestScheduleRoundTrip(t *testing.T) {
+	rigPath := t.TempDir()
+
+	sched := NewSchedule("sw-1", "rig")
+	sched.Attempts["a"] = 2
+	if err := SaveSchedule(rigPath, sched); err != nil {
+		t.Fatalf("SaveSchedule: %v", err)
+	}
+
+	loaded, err := LoadSchedule(rigPath, "sw-1")
+	if err != nil {
+		t.Fatalf("LoadSchedule: %v", err)
+	}
+	if loaded.Attempts["a"] != 2 || loaded.TargetWorkers != DefaultTargetWorkers {
+		t.Errorf("loaded schedule = %+v", loaded)
+	}
+
+	all, err := ListSchedules(rigPath)
+	if err != nil || len(all) != 1 {
+		t.Fatalf("ListSchedules = %v, %v", all, err)
+	}
+
+	if err := RemoveSchedule(rigPath, "sw-1"); err != nil {
+		t.Fatalf("RemoveSchedule: %v", err)
+	}
+	if _, err := LoadSchedule(rigPath, "sw-1"); err != ErrSwarmNotFound {
+		t.Errorf("LoadSchedule after remove = %v, want ErrSwarmNotFound", err)
+	}
+}
@@ -428,6 +428,16 @@ func (t *Tmux) GetPanePID(session string) (string, error) {
 // hasClaudeChild checks if a process has a child running claude/node.
 // Used when the pane command is a shell (bash, zsh) that launched claude.
 func hasClaudeChild(pid string) bool {
+	return hasClaudeDescendant(pid, 1)
+}
+
+// hasClaudeDescendant checks up to depth levels of a process's descendants
+// for claude/node. Sandboxed agents sit several levels below the pane
+// (bwrap → bwrap → sh → claude).
+func hasClaudeDescendant(pid string, depth int) bool {
+	if depth <= 0 {
+		return false
+	}
 	// Use pgrep to find child processes
 	cmd := exec.Command("pgrep", "-P", pid, "-l")
 	out, err := cmd.Output()
@@ -448,6 +458,9 @@ func hasClaudeChild(pid string) bool {
 			if name == "node" || name == "claude" {
 				return true
 			}
+			if hasClaudeDescendant(parts[0], depth-1) {
+				return true
+			}
 		}
 	}
 	return false
@@ -670,6 +683,16 @@ func (t *Tmux) IsClaudeRunning(session string) bool {
 			break
 		}
 	}
+	// If pane command is a sandbox launcher, look further down the process tree.
+	for _, wrapper := range constants.SandboxWrappers {
+		if cmd == wrapper {
+			pid, err := t.GetPanePID(session)
+			if err == nil && pid != "" {
+				return hasClaudeDescendant(pid, 4)
+			}
+			break
+		}
+	}
 	return false
 }
 
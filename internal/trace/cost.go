@@ -0,0 +1,92 @@
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// costEvent is the subset of a session.ended event bead used for tracing.
+type costEvent struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	EventKind string    `json:"event_kind"`
+	Actor     string    `json:"actor"`
+	Target    string    `json:"target"`
+	Payload   string    `json:"payload"`
+}
+
+// sessionCosts returns cost entries for sessions attributed to beadID, read
+// from the rig's session cost wisps (gt costs record --work-item). Wisps are
+// digested daily, so older sessions only appear in the aggregate digest.
+func (t *Tracer) sessionCosts(rigPath, beadID string) ([]Entry, error) {
+	bd := beads.New(rigPath)
+
+	out, err := bd.Run("mol", "wisp", "list", "--all", "--json")
+	if err != nil {
+		// No wisps database: nothing recorded
+		return nil, nil
+	}
+	var list struct {
+		Wisps []struct {
+			ID string `json:"id"`
+		} `json:"wisps"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("parsing wisp list: %w", err)
+	}
+	if len(list.Wisps) == 0 {
+		return nil, nil
+	}
+
+	showArgs := []string{"show", "--json"}
+	for _, w := range list.Wisps {
+		showArgs = append(showArgs, w.ID)
+	}
+	out, err = bd.Run(showArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("showing wisps: %w", err)
+	}
+	var wisps []costEvent
+	if err := json.Unmarshal(out, &wisps); err != nil {
+		return nil, fmt.Errorf("parsing wisp details: %w", err)
+	}
+
+	return costEntries(wisps, beadID), nil
+}
+
+// costEntries converts session.ended events targeting beadID into entries.
+func costEntries(wisps []costEvent, beadID string) []Entry {
+	var entries []Entry
+	for _, w := range wisps {
+		if w.EventKind != "session.ended" || w.Target != beadID {
+			continue
+		}
+		var payload struct {
+			CostUSD float64 `json:"cost_usd"`
+			Role    string  `json:"role"`
+			EndedAt string  `json:"ended_at"`
+		}
+		if w.Payload != "" {
+			if err := json.Unmarshal([]byte(w.Payload), &payload); err != nil {
+				continue
+			}
+		}
+		ts := w.CreatedAt
+		if ended, err := time.Parse(time.RFC3339, payload.EndedAt); err == nil {
+			ts = ended
+		}
+		entries = append(entries, Entry{
+			Timestamp: ts,
+			Bead:      beadID,
+			Source:    SourceCost,
+			Type:      "session_cost",
+			Actor:     w.Actor,
+			Summary:   fmt.Sprintf("Session ended ($%.2f)", payload.CostUSD),
+			CostUSD:   payload.CostUSD,
+		})
+	}
+	return entries
+}
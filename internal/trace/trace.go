@@ -0,0 +1,688 @@
+// Package trace reconstructs the lifecycle of a bead (or every bead in a
+// convoy) from the places Gas Town records it: the beads database, the town
+// events log, the refinery's MQ event log, the town log, git history on the
+// polecat branch, mail, and session cost wisps.
+//
+// The result is a single ordered Timeline with the time spent in each phase:
+// queued → spawned → working → done → in_mq → merged.
+package trace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/mrqueue"
+	"github.com/steveyegge/gastown/internal/townlog"
+)
+
+// Phase is a stage in a bead's lifecycle.
+type Phase string
+
+// Lifecycle phases, in order.
+const (
+	PhaseQueued  Phase = "queued"  // Created, waiting to be slung
+	PhaseSpawned Phase = "spawned" // Slung to a worker, session starting
+	PhaseWorking Phase = "working" // On a worker's hook
+	PhaseDone    Phase = "done"    // Worker finished, MR waiting for the refinery
+	PhaseInMQ    Phase = "in_mq"   // Refinery processing the MR
+	PhaseMerged  Phase = "merged"  // Landed on the target branch
+)
+
+// Phases lists all lifecycle phases in order.
+var Phases = []Phase{PhaseQueued, PhaseSpawned, PhaseWorking, PhaseDone, PhaseInMQ, PhaseMerged}
+
+// Entry sources.
+const (
+	SourceBeads   = "beads"
+	SourceEvents  = "events"
+	SourceMQ      = "mq"
+	SourceTownlog = "townlog"
+	SourceGit     = "git"
+	SourceMail    = "mail"
+	SourceCost    = "cost"
+)
+
+// Entry is a single point on a bead's timeline.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Bead      string    `json:"bead"`
+	Source    string    `json:"source"`
+	Type      string    `json:"type"`
+	Actor     string    `json:"actor,omitempty"`
+	Summary   string    `json:"summary"`
+
+	// Milestone is set when this entry marks the start of a phase.
+	Milestone Phase `json:"milestone,omitempty"`
+
+	// CostUSD is set for session cost entries.
+	CostUSD float64 `json:"cost_usd,omitempty"`
+}
+
+// Span is the time a bead (or convoy) spent in one phase.
+type Span struct {
+	Phase    Phase         `json:"phase"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration_ns"`
+
+	// Ongoing is true for the current phase of an unfinished bead;
+	// End is then the time the trace was taken.
+	Ongoing bool `json:"ongoing,omitempty"`
+}
+
+// Timeline is the reconstructed history of a bead or convoy.
+type Timeline struct {
+	Bead    string   `json:"bead"`
+	Title   string   `json:"title"`
+	Type    string   `json:"type,omitempty"`
+	Status  string   `json:"status"`
+	Rig     string   `json:"rig,omitempty"`
+	Workers []string `json:"workers,omitempty"`
+	Branch  string   `json:"branch,omitempty"`
+
+	// Phase is the latest phase reached.
+	Phase   Phase   `json:"phase,omitempty"`
+	Phases  []Span  `json:"phases"`
+	Entries []Entry `json:"entries"`
+	CostUSD float64 `json:"cost_usd,omitempty"`
+
+	// Beads holds per-bead timelines when tracing a convoy.
+	Beads []*Timeline `json:"beads,omitempty"`
+
+	// Warnings lists sources that could not be read. The timeline is
+	// still built from the remaining sources.
+	Warnings []string `json:"warnings,omitempty"`
+
+	closedAt time.Time
+}
+
+// Tracer builds timelines for beads in a town.
+type Tracer struct {
+	townRoot string
+	now      func() time.Time
+
+	// Source hooks, overridable in tests. Mail and cost lookups shell out
+	// to bd, so they are the slow part of a trace.
+	showBead func(id string) (*beads.Issue, error)
+	readMail func(addresses []string, beadID string) ([]*mail.Message, error)
+	readCost func(rigPath, beadID string) ([]Entry, error)
+
+	// Town-wide logs, read once per Tracer (a convoy trace reuses them
+	// for every tracked bead). Events are read from eventsSince, just
+	// before the earliest traced bead was created.
+	events      []events.Event
+	eventsErr   error
+	eventsSince time.Time
+	townlog   []townlog.Event
+	townlogOK bool
+}
+
+// New creates a Tracer for the given town.
+func New(townRoot string) *Tracer {
+	t := &Tracer{
+		townRoot: townRoot,
+		now:      time.Now,
+	}
+	t.showBead = beads.New(townRoot).Show
+	t.readMail = t.searchMail
+	t.readCost = t.sessionCosts
+	return t
+}
+
+// Trace builds the timeline for a bead. Convoys are traced as the union of
+// their tracked beads, with each tracked bead's timeline in Beads.
+func (t *Tracer) Trace(id string) (*Timeline, error) {
+	issue, err := t.showBead(id)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %s: %w", id, err)
+	}
+
+	if issue.Type == "convoy" {
+		return t.traceConvoy(issue)
+	}
+	t.eventsSince = eventsSince([]*beads.Issue{issue})
+	return t.traceBead(issue), nil
+}
+
+// eventsMargin allows for clock skew between bead creation times and the
+// events log.
+const eventsMargin = 5 * time.Minute
+
+// eventsSince returns where to start reading the events log for beads:
+// their earliest creation less eventsMargin, or zero (the whole log) when
+// a creation time is unknown.
+func eventsSince(issues []*beads.Issue) time.Time {
+	var since time.Time
+	for _, issue := range issues {
+		created, err := time.Parse(time.RFC3339, issue.CreatedAt)
+		if err != nil {
+			return time.Time{}
+		}
+		if since.IsZero() || created.Before(since) {
+			since = created
+		}
+	}
+	if since.IsZero() {
+		return since
+	}
+	return since.Add(-eventsMargin)
+}
+
+// traceConvoy traces each tracked bead and merges their entries.
+func (t *Tracer) traceConvoy(convoy *beads.Issue) (*Timeline, error) {
+	tl := newTimeline(convoy)
+	tl.Entries = append(tl.Entries, createdEntry(convoy)...)
+
+	var tracked []*beads.Issue
+	for _, dep := range convoy.Dependencies {
+		if dep.DependencyType != "tracks" {
+			continue
+		}
+		id := dep.ID
+		// External references look like external:rig:issue-id
+		if strings.HasPrefix(id, "external:") {
+			if parts := strings.SplitN(id, ":", 3); len(parts) == 3 {
+				id = parts[2]
+			}
+		}
+
+		issue, err := t.showBead(id)
+		if err != nil {
+			tl.Warnings = append(tl.Warnings, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		tracked = append(tracked, issue)
+	}
+
+	t.eventsSince = eventsSince(tracked)
+	for _, issue := range tracked {
+		id := issue.ID
+		child := t.traceBead(issue)
+		tl.Beads = append(tl.Beads, child)
+		tl.Entries = append(tl.Entries, child.Entries...)
+		tl.Workers = appendUnique(tl.Workers, child.Workers...)
+		tl.CostUSD += child.CostUSD
+		for _, w := range child.Warnings {
+			tl.Warnings = appendUnique(tl.Warnings, fmt.Sprintf("%s: %s", id, w))
+		}
+	}
+
+	sortEntries(tl.Entries)
+	tl.Phases = convoySpans(convoy, tl.Beads, t.now())
+	if len(tl.Phases) > 0 {
+		tl.Phase = tl.Phases[len(tl.Phases)-1].Phase
+	}
+	return tl, nil
+}
+
+// traceBead collects entries for one bead from every source.
+func (t *Tracer) traceBead(issue *beads.Issue) *Timeline {
+	tl := newTimeline(issue)
+	tl.Entries = append(tl.Entries, createdEntry(issue)...)
+	if !tl.closedAt.IsZero() {
+		tl.Entries = append(tl.Entries, Entry{
+			Timestamp: tl.closedAt,
+			Bead:      issue.ID,
+			Source:    SourceBeads,
+			Type:      "closed",
+			Actor:     issue.Assignee,
+			Summary:   "Bead closed",
+		})
+	}
+
+	rigPath := beads.GetRigPathForPrefix(t.townRoot, beads.ExtractPrefix(issue.ID))
+	if rigPath != "" && rigPath != t.townRoot {
+		tl.Rig = filepath.Base(rigPath)
+	}
+
+	t.addEvents(tl)
+	t.addTownlog(tl)
+	if rigPath != "" {
+		t.addMQ(tl, rigPath)
+	}
+	if tl.Rig != "" && tl.Branch != "" {
+		t.addCommits(tl, filepath.Join(t.townRoot, tl.Rig))
+	}
+	t.addMail(tl)
+	if rigPath != "" {
+		if entries, err := t.readCost(rigPath, issue.ID); err != nil {
+			tl.Warnings = append(tl.Warnings, fmt.Sprintf("cost: %v", err))
+		} else {
+			for _, e := range entries {
+				tl.CostUSD += e.CostUSD
+			}
+			tl.Entries = append(tl.Entries, entries...)
+		}
+	}
+
+	sortEntries(tl.Entries)
+	tl.Phases = spans(tl.Entries, tl.closedAt, t.now())
+	if len(tl.Phases) > 0 {
+		tl.Phase = tl.Phases[len(tl.Phases)-1].Phase
+	}
+	return tl
+}
+
+// addEvents adds sling, hook, unhook and done events from the town events log.
+func (t *Tracer) addEvents(tl *Timeline) {
+	if t.events == nil && t.eventsErr == nil {
+		t.events, t.eventsErr = events.ReadSince(t.townRoot, t.eventsSince)
+	}
+	if t.eventsErr != nil {
+		tl.Warnings = append(tl.Warnings, fmt.Sprintf("events: %v", t.eventsErr))
+		return
+	}
+
+	for _, e := range t.events {
+		if bead, _ := e.Payload["bead"].(string); bead != tl.Bead {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			continue
+		}
+
+		entry := Entry{Timestamp: ts, Bead: tl.Bead, Source: SourceEvents, Type: e.Type, Actor: e.Actor}
+		switch e.Type {
+		case events.TypeSling:
+			target, _ := e.Payload["target"].(string)
+			entry.Milestone = PhaseSpawned
+			entry.Summary = "Slung to " + target
+			if target != "" && !strings.HasSuffix(target, "<new>") {
+				tl.Workers = appendUnique(tl.Workers, target)
+			}
+		case events.TypeHook:
+			entry.Milestone = PhaseWorking
+			entry.Summary = "Hooked by " + e.Actor
+			tl.Workers = appendUnique(tl.Workers, e.Actor)
+		case events.TypeUnhook:
+			entry.Summary = "Unhooked by " + e.Actor
+		case events.TypeDone:
+			branch, _ := e.Payload["branch"].(string)
+			entry.Milestone = PhaseDone
+			entry.Summary = "Done"
+			if branch != "" {
+				entry.Summary += " on " + branch
+				tl.Branch = branch
+			}
+			tl.Workers = appendUnique(tl.Workers, e.Actor)
+		default:
+			entry.Summary = e.Type
+		}
+		tl.Entries = append(tl.Entries, entry)
+	}
+}
+
+// addTownlog adds agent lifecycle entries that mention the bead.
+func (t *Tracer) addTownlog(tl *Timeline) {
+	if !t.townlogOK {
+		logged, err := townlog.ReadEvents(t.townRoot)
+		if err != nil {
+			tl.Warnings = append(tl.Warnings, fmt.Sprintf("townlog: %v", err))
+			return
+		}
+		t.townlog, t.townlogOK = logged, true
+	}
+
+	for _, e := range t.townlog {
+		if !mentions(e.Context, tl.Bead) {
+			continue
+		}
+		entry := Entry{
+			Timestamp: e.Timestamp,
+			Bead:      tl.Bead,
+			Source:    SourceTownlog,
+			Type:      string(e.Type),
+			Actor:     e.Agent,
+			Summary:   fmt.Sprintf("%s %s", e.Agent, e.Type),
+		}
+		switch e.Type {
+		case townlog.EventSpawn:
+			entry.Milestone = PhaseSpawned
+			entry.Summary = "Spawned " + e.Agent
+			tl.Workers = appendUnique(tl.Workers, e.Agent)
+		case townlog.EventDone:
+			entry.Milestone = PhaseDone
+		}
+		tl.Entries = append(tl.Entries, entry)
+	}
+}
+
+// addMQ adds the refinery's merge events for MRs sourced from the bead.
+func (t *Tracer) addMQ(tl *Timeline, rigPath string) {
+	logged, err := mrqueue.NewEventLoggerFromRig(rigPath).ReadEvents()
+	if err != nil {
+		tl.Warnings = append(tl.Warnings, fmt.Sprintf("mq: %v", err))
+		return
+	}
+
+	for _, e := range logged {
+		if e.SourceIssue != tl.Bead {
+			continue
+		}
+		entry := Entry{
+			Timestamp: e.Timestamp,
+			Bead:      tl.Bead,
+			Source:    SourceMQ,
+			Type:      string(e.Type),
+			Actor:     e.Worker,
+		}
+		switch e.Type {
+		case mrqueue.EventMergeStarted:
+			entry.Milestone = PhaseInMQ
+			entry.Summary = fmt.Sprintf("Refinery started %s → %s", e.Branch, e.Target)
+		case mrqueue.EventMerged:
+			entry.Milestone = PhaseMerged
+			entry.Summary = fmt.Sprintf("Merged %s", e.Branch)
+			if e.MergeCommit != "" {
+				entry.Summary += " as " + shortHash(e.MergeCommit)
+			}
+		case mrqueue.EventMergeFailed:
+			entry.Summary = "Merge failed: " + e.Reason
+		case mrqueue.EventMergeSkipped:
+			entry.Summary = "Merge skipped: " + e.Reason
+		}
+		if tl.Branch == "" {
+			tl.Branch = e.Branch
+		}
+		if e.Rig != "" && tl.Rig == "" {
+			tl.Rig = e.Rig
+		}
+		tl.Entries = append(tl.Entries, entry)
+	}
+}
+
+// addCommits adds commits made on the bead's branch since the bead was created.
+// Branches deleted after merge are skipped silently.
+func (t *Tracer) addCommits(tl *Timeline, rigPath string) {
+	var g *git.Git
+	if info, err := os.Stat(filepath.Join(rigPath, ".repo.git")); err == nil && info.IsDir() {
+		g = git.NewGitWithDir(filepath.Join(rigPath, ".repo.git"), "")
+	} else if _, err := os.Stat(filepath.Join(rigPath, "mayor", "rig")); err == nil {
+		g = git.NewGit(filepath.Join(rigPath, "mayor", "rig"))
+	} else {
+		return
+	}
+	if _, err := g.Rev(tl.Branch); err != nil {
+		return
+	}
+
+	var since time.Time
+	if len(tl.Entries) > 0 {
+		since = earliest(tl.Entries)
+	}
+	commits, err := g.LogSince(tl.Branch, since)
+	if err != nil {
+		tl.Warnings = append(tl.Warnings, fmt.Sprintf("git: %v", err))
+		return
+	}
+	for _, c := range commits {
+		tl.Entries = append(tl.Entries, Entry{
+			Timestamp: c.Date,
+			Bead:      tl.Bead,
+			Source:    SourceGit,
+			Type:      "commit",
+			Actor:     c.Author,
+			Summary:   fmt.Sprintf("%s %s", shortHash(c.Hash), c.Subject),
+		})
+	}
+}
+
+// addMail adds messages mentioning the bead in the mailboxes of everyone
+// involved: its workers, and the rig's witness and refinery.
+func (t *Tracer) addMail(tl *Timeline) {
+	addresses := append([]string{}, tl.Workers...)
+	if tl.Rig != "" {
+		addresses = append(addresses, tl.Rig+"/witness", tl.Rig+"/refinery")
+	}
+	if len(addresses) == 0 {
+		return
+	}
+
+	messages, err := t.readMail(addresses, tl.Bead)
+	if err != nil {
+		tl.Warnings = append(tl.Warnings, fmt.Sprintf("mail: %v", err))
+	}
+	for _, m := range messages {
+		tl.Entries = append(tl.Entries, Entry{
+			Timestamp: m.Timestamp,
+			Bead:      tl.Bead,
+			Source:    SourceMail,
+			Type:      "mail",
+			Actor:     m.From,
+			Summary:   fmt.Sprintf("→ %s: %s", m.To, m.Subject),
+		})
+	}
+}
+
+// searchMail searches each address's mailbox for messages mentioning beadID.
+// Messages are de-duplicated across mailboxes.
+func (t *Tracer) searchMail(addresses []string, beadID string) ([]*mail.Message, error) {
+	seen := make(map[string]bool)
+	var result []*mail.Message
+	var lastErr error
+	for _, addr := range addresses {
+		found, err := mail.NewMailboxFromAddress(addr, t.townRoot).Search(mail.SearchOptions{Query: beadID})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, m := range found {
+			if !seen[m.ID] {
+				seen[m.ID] = true
+				result = append(result, m)
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil, lastErr
+	}
+	return result, nil
+}
+
+// newTimeline starts a timeline from a bead's metadata.
+func newTimeline(issue *beads.Issue) *Timeline {
+	tl := &Timeline{
+		Bead:   issue.ID,
+		Title:  issue.Title,
+		Type:   issue.Type,
+		Status: issue.Status,
+	}
+	if issue.ClosedAt != "" {
+		tl.closedAt, _ = time.Parse(time.RFC3339, issue.ClosedAt)
+	}
+	return tl
+}
+
+// createdEntry returns the queued milestone for a bead's creation.
+func createdEntry(issue *beads.Issue) []Entry {
+	created, err := time.Parse(time.RFC3339, issue.CreatedAt)
+	if err != nil {
+		return nil
+	}
+	summary := "Created"
+	if issue.CreatedBy != "" {
+		summary += " by " + issue.CreatedBy
+	}
+	return []Entry{{
+		Timestamp: created,
+		Bead:      issue.ID,
+		Source:    SourceBeads,
+		Type:      "created",
+		Actor:     issue.CreatedBy,
+		Summary:   summary,
+		Milestone: PhaseQueued,
+	}}
+}
+
+// spans derives phase durations from milestone entries. Each phase starts at
+// its first milestone and ends where the next later phase starts. The last
+// phase is ongoing unless the bead is closed or merged.
+func spans(entries []Entry, closedAt, now time.Time) []Span {
+	starts := make(map[Phase]time.Time)
+	for _, e := range entries {
+		if e.Milestone == "" {
+			continue
+		}
+		if s, ok := starts[e.Milestone]; !ok || e.Timestamp.Before(s) {
+			starts[e.Milestone] = e.Timestamp
+		}
+	}
+
+	var result []Span
+	for i, phase := range Phases {
+		start, ok := starts[phase]
+		if !ok {
+			continue
+		}
+		span := Span{Phase: phase, Start: start}
+		for _, next := range Phases[i+1:] {
+			if s, ok := starts[next]; ok {
+				span.End = s
+				break
+			}
+		}
+		if span.End.IsZero() {
+			switch {
+			case phase == PhaseMerged:
+				span.End = start
+			case !closedAt.IsZero():
+				span.End = closedAt
+			default:
+				span.End = now
+				span.Ongoing = true
+			}
+		}
+		if span.End.Before(span.Start) {
+			span.End = span.Start
+		}
+		span.Duration = span.End.Sub(span.Start)
+		result = append(result, span)
+	}
+	return result
+}
+
+// convoySpans summarizes per-bead phases for a convoy: each phase runs from
+// the first tracked bead entering it to the last bead leaving it. The convoy
+// itself is queued from creation until its first bead is spawned.
+func convoySpans(convoy *beads.Issue, children []*Timeline, now time.Time) []Span {
+	byPhase := make(map[Phase]*Span)
+	for _, child := range children {
+		for _, s := range child.Phases {
+			agg, ok := byPhase[s.Phase]
+			if !ok {
+				copied := s
+				byPhase[s.Phase] = &copied
+				continue
+			}
+			if s.Start.Before(agg.Start) {
+				agg.Start = s.Start
+			}
+			if s.End.After(agg.End) {
+				agg.End = s.End
+			}
+			agg.Ongoing = agg.Ongoing || s.Ongoing
+		}
+	}
+
+	if created, err := time.Parse(time.RFC3339, convoy.CreatedAt); err == nil {
+		queued := &Span{Phase: PhaseQueued, Start: created, End: now, Ongoing: true}
+		for _, phase := range Phases[1:] {
+			if s, ok := byPhase[phase]; ok {
+				queued.End, queued.Ongoing = s.Start, false
+				break
+			}
+		}
+		byPhase[PhaseQueued] = queued
+	}
+
+	var result []Span
+	for _, phase := range Phases {
+		if s, ok := byPhase[phase]; ok {
+			s.Duration = s.End.Sub(s.Start)
+			result = append(result, *s)
+		}
+	}
+	return result
+}
+
+// sortEntries orders entries chronologically, keeping milestones in phase
+// order when timestamps tie (events are logged at second resolution).
+func sortEntries(entries []Entry) {
+	order := make(map[Phase]int)
+	for i, p := range Phases {
+		order[p] = i + 1
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			return entries[i].Timestamp.Before(entries[j].Timestamp)
+		}
+		return order[entries[i].Milestone] < order[entries[j].Milestone]
+	})
+}
+
+// earliest returns the earliest entry timestamp.
+func earliest(entries []Entry) time.Time {
+	first := entries[0].Timestamp
+	for _, e := range entries[1:] {
+		if e.Timestamp.Before(first) {
+			first = e.Timestamp
+		}
+	}
+	return first
+}
+
+// appendUnique appends values not already present.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found && v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// mentions reports whether text refers to id as a whole bead ID, so that
+// gt-abc does not match gt-abcd or the child bead gt-abc.1.
+func mentions(text, id string) bool {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], id)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(id)
+		if (start == 0 || !isIDChar(text[start-1])) && (end == len(text) || !isIDChar(text[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isIDChar reports whether c can appear inside a bead ID.
+func isIDChar(c byte) bool {
+	return c == '-' || c == '.' || c == '_' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// shortHash abbreviates a commit hash.
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
@@ -0,0 +1,272 @@
+package trace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/mrqueue"
+	"github.com/steveyegge/gastown/internal/townlog"
+)
+
+var t0 = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+// setupTown creates a town with a gastown rig routed from the gt- prefix.
+func setupTown(t *testing.T) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	for _, dir := range []string{".beads", filepath.Join("gastown", ".beads")} {
+		if err := os.MkdirAll(filepath.Join(townRoot, dir), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := beads.WriteRoutes(filepath.Join(townRoot, ".beads"), []beads.Route{
+		{Prefix: "gt-", Path: "gastown"},
+	}); err != nil {
+		t.Fatalf("WriteRoutes: %v", err)
+	}
+	return townRoot
+}
+
+// logEvents writes raw events to the town's events log.
+func logEvents(t *testing.T, townRoot string, evs ...events.Event) {
+	t.Helper()
+	f, err := os.OpenFile(filepath.Join(townRoot, events.EventsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("open events: %v", err)
+	}
+	defer f.Close()
+	for _, e := range evs {
+		data, _ := json.Marshal(e)
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			t.Fatalf("write event: %v", err)
+		}
+	}
+}
+
+func event(at time.Duration, typ, actor string, payload map[string]interface{}) events.Event {
+	return events.Event{
+		Timestamp:  t0.Add(at).Format(time.RFC3339),
+		Source:     "gt",
+		Type:       typ,
+		Actor:      actor,
+		Payload:    payload,
+		Visibility: events.VisibilityFeed,
+	}
+}
+
+// newTestTracer returns a tracer over townRoot with bd-backed sources stubbed.
+func newTestTracer(townRoot string, issues map[string]*beads.Issue) *Tracer {
+	tr := New(townRoot)
+	tr.now = func() time.Time { return t0.Add(10 * time.Hour) }
+	tr.showBead = func(id string) (*beads.Issue, error) {
+		if issue, ok := issues[id]; ok {
+			return issue, nil
+		}
+		return nil, beads.ErrNotFound
+	}
+	tr.readMail = func([]string, string) ([]*mail.Message, error) { return nil, nil }
+	tr.readCost = func(string, string) ([]Entry, error) { return nil, nil }
+	return tr
+}
+
+func TestTraceBeadLifecycle(t *testing.T) {
+	townRoot := setupTown(t)
+
+	logEvents(t, townRoot,
+		event(10*time.Minute, events.TypeSling, "mayor", events.SlingPayload("gt-abc", "gastown/polecats/Toast")),
+		event(12*time.Minute, events.TypeHook, "gastown/polecats/Toast", events.HookPayload("gt-abc")),
+		event(13*time.Minute, events.TypeHook, "gastown/polecats/Nux", events.HookPayload("gt-other")),
+		event(time.Hour, events.TypeDone, "gastown/polecats/Toast", events.DonePayload("gt-abc", "polecat/Toast-1")),
+	)
+
+	if err := townlog.NewLogger(townRoot).Log(townlog.EventSpawn, "gastown/polecats/Toast", "gt-abcd"); err != nil {
+		t.Fatalf("townlog: %v", err)
+	}
+
+	mq := mrqueue.NewEventLoggerFromRig(filepath.Join(townRoot, "gastown"))
+	mr := &mrqueue.MR{ID: "mr-1", Branch: "polecat/Toast-1", Target: "main", SourceIssue: "gt-abc", Worker: "Toast", Rig: "gastown"}
+	for _, e := range []mrqueue.Event{
+		{Timestamp: t0.Add(70 * time.Minute), Type: mrqueue.EventMergeStarted},
+		{Timestamp: t0.Add(75 * time.Minute), Type: mrqueue.EventMergeFailed, Reason: "tests"},
+		{Timestamp: t0.Add(80 * time.Minute), Type: mrqueue.EventMerged, MergeCommit: "0123456789abcdef"},
+	} {
+		e.MRID, e.Branch, e.Target, e.SourceIssue, e.Worker, e.Rig = mr.ID, mr.Branch, mr.Target, mr.SourceIssue, mr.Worker, mr.Rig
+		if err := mq.LogEvent(e); err != nil {
+			t.Fatalf("LogEvent: %v", err)
+		}
+	}
+
+	tr := newTestTracer(townRoot, map[string]*beads.Issue{
+		"gt-abc": {ID: "gt-abc", Title: "Fix it", Status: "closed", Type: "task",
+			CreatedAt: t0.Format(time.RFC3339), ClosedAt: t0.Add(81 * time.Minute).Format(time.RFC3339)},
+	})
+	tr.readCost = func(rigPath, beadID string) ([]Entry, error) {
+		return costEntries([]costEvent{
+			{EventKind: "session.ended", Target: beadID, Payload: `{"cost_usd":1.25,"ended_at":"2026-10-01T10:01:00Z"}`},
+			{EventKind: "session.ended", Target: "gt-other", Payload: `{"cost_usd":9}`},
+		}, beadID), nil
+	}
+
+	tl, err := tr.Trace("gt-abc")
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+
+	if tl.Rig != "gastown" || tl.Branch != "polecat/Toast-1" || tl.Phase != PhaseMerged {
+		t.Errorf("timeline = rig %q branch %q phase %q", tl.Rig, tl.Branch, tl.Phase)
+	}
+	if len(tl.Workers) != 1 || tl.Workers[0] != "gastown/polecats/Toast" {
+		t.Errorf("Workers = %v", tl.Workers)
+	}
+	if tl.CostUSD != 1.25 {
+		t.Errorf("CostUSD = %v, want 1.25", tl.CostUSD)
+	}
+
+	want := map[Phase]time.Duration{
+		PhaseQueued:  10 * time.Minute,
+		PhaseSpawned: 2 * time.Minute,
+		PhaseWorking: 48 * time.Minute,
+		PhaseDone:    10 * time.Minute,
+		PhaseInMQ:    10 * time.Minute,
+		PhaseMerged:  0,
+	}
+	if len(tl.Phases) != len(want) {
+		t.Fatalf("Phases = %+v", tl.Phases)
+	}
+	for _, s := range tl.Phases {
+		if s.Duration != want[s.Phase] || s.Ongoing {
+			t.Errorf("phase %s = %s (ongoing %v), want %s", s.Phase, s.Duration, s.Ongoing, want[s.Phase])
+		}
+	}
+
+	// Entries are ordered, and the townlog spawn for gt-abcd is not ours
+	for i := 1; i < len(tl.Entries); i++ {
+		if tl.Entries[i].Timestamp.Before(tl.Entries[i-1].Timestamp) {
+			t.Fatalf("entries out of order at %d: %+v", i, tl.Entries)
+		}
+	}
+	var sources []string
+	for _, e := range tl.Entries {
+		sources = append(sources, e.Source+":"+e.Type)
+	}
+	got := strings.Join(sources, ",")
+	if strings.Contains(got, "townlog") {
+		t.Errorf("townlog entry for another bead included: %s", got)
+	}
+	if !strings.Contains(got, "mq:merge_failed") || !strings.Contains(got, "cost:session_cost") {
+		t.Errorf("entries = %s", got)
+	}
+}
+
+func TestTraceOngoingBead(t *testing.T) {
+	townRoot := setupTown(t)
+	logEvents(t, townRoot,
+		event(time.Hour, events.TypeSling, "mayor", events.SlingPayload("gt-abc", "gastown")),
+	)
+
+	tr := newTestTracer(townRoot, map[string]*beads.Issue{
+		"gt-abc": {ID: "gt-abc", Status: "open", CreatedAt: t0.Format(time.RFC3339)},
+	})
+	tl, err := tr.Trace("gt-abc")
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+
+	if tl.Phase != PhaseSpawned || len(tl.Phases) != 2 {
+		t.Fatalf("phases = %+v", tl.Phases)
+	}
+	last := tl.Phases[1]
+	if !last.Ongoing || last.Duration != 9*time.Hour {
+		t.Errorf("current phase = %+v, want ongoing for 9h", last)
+	}
+}
+
+func TestTraceConvoy(t *testing.T) {
+	townRoot := setupTown(t)
+	logEvents(t, townRoot,
+		event(time.Hour, events.TypeSling, "mayor", events.SlingPayload("gt-a", "gastown")),
+		event(2*time.Hour, events.TypeSling, "mayor", events.SlingPayload("gt-b", "gastown")),
+		event(3*time.Hour, events.TypeDone, "gastown/polecats/Nux", events.DonePayload("gt-b", "polecat/Nux-1")),
+	)
+
+	created := t0.Format(time.RFC3339)
+	tr := newTestTracer(townRoot, map[string]*beads.Issue{
+		"hq-cv-1": {ID: "hq-cv-1", Type: "convoy", Status: "open", CreatedAt: created,
+			Dependencies: []beads.IssueDep{
+				{ID: "gt-a", DependencyType: "tracks"},
+				{ID: "external:gastown:gt-b", DependencyType: "tracks"},
+				{ID: "gt-parent", DependencyType: "blocks"},
+			}},
+		"gt-a": {ID: "gt-a", Status: "open", CreatedAt: created},
+		"gt-b": {ID: "gt-b", Status: "closed", CreatedAt: created, ClosedAt: t0.Add(4 * time.Hour).Format(time.RFC3339)},
+	})
+
+	tl, err := tr.Trace("hq-cv-1")
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+	if len(tl.Beads) != 2 {
+		t.Fatalf("Beads = %d, want 2 tracked", len(tl.Beads))
+	}
+
+	phases := make(map[Phase]Span)
+	for _, s := range tl.Phases {
+		phases[s.Phase] = s
+	}
+	if q := phases[PhaseQueued]; q.Duration != time.Hour {
+		t.Errorf("convoy queued = %s, want 1h (until first sling)", q.Duration)
+	}
+	if s := phases[PhaseSpawned]; !s.Start.Equal(t0.Add(time.Hour)) || !s.Ongoing {
+		t.Errorf("convoy spawned = %+v, want from first sling and still ongoing", s)
+	}
+	if tl.Phase != PhaseDone {
+		t.Errorf("convoy phase = %s, want done", tl.Phase)
+	}
+
+	beadsSeen := make(map[string]bool)
+	for _, e := range tl.Entries {
+		beadsSeen[e.Bead] = true
+	}
+	if !beadsSeen["gt-a"] || !beadsSeen["gt-b"] || !beadsSeen["hq-cv-1"] {
+		t.Errorf("convoy entries cover %v", beadsSeen)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"gt-abc", true},
+		{"spawned for gt-abc", true},
+		{"gt-abc, gt-def", true},
+		{"gt-abcd", false},
+		{"gt-abc.1", false},
+		{"xgt-abc", false},
+		{"gt-abcd then gt-abc", true},
+	}
+	for _, tt := range tests {
+		if got := mentions(tt.text, "gt-abc"); got != tt.want {
+			t.Errorf("mentions(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEventsSince(t *testing.T) {
+	at := func(d time.Duration) *beads.Issue { return &beads.Issue{CreatedAt: t0.Add(d).Format(time.RFC3339)} }
+
+	if got := eventsSince([]*beads.Issue{at(time.Hour), at(10 * time.Minute)}); !got.Equal(t0.Add(10*time.Minute - eventsMargin)) {
+		t.Errorf("eventsSince = %v, want earliest creation less the margin", got)
+	}
+	// An unknown creation time reads the whole log
+	if got := eventsSince([]*beads.Issue{at(time.Hour), {}}); !got.IsZero() {
+		t.Errorf("eventsSince = %v, want zero", got)
+	}
+}
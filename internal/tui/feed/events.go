@@ -5,14 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
 )
 
 // EventSource represents a source of events
@@ -214,9 +217,15 @@ func parseBeadContext(beadID string) (actor, rig, role string) {
 
 // GtEventsSource reads events from ~/gt/.events.jsonl (gt activity log)
 type GtEventsSource struct {
-	file   *os.File
+	path   string
 	events chan Event
 	cancel context.CancelFunc
+
+	mu      sync.Mutex // guards the fields below, shared by tail and Close
+	file    *os.File
+	reader  *bufio.Reader
+	partial string // start of a line whose newline isn't written yet
+	closed  bool
 }
 
 // GtEvent is the structure of events in .events.jsonl
@@ -231,16 +240,24 @@ type GtEvent struct {
 
 // NewGtEventsSource creates a source that tails ~/gt/.events.jsonl
 func NewGtEventsSource(townRoot string) (*GtEventsSource, error) {
-	eventsPath := filepath.Join(townRoot, ".events.jsonl")
+	eventsPath := filepath.Join(townRoot, events.EventsFile)
 	file, err := os.Open(eventsPath)
 	if err != nil {
 		return nil, err
 	}
 
+	// Seek to end for live tailing
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	source := &GtEventsSource{
+		path:   eventsPath,
 		file:   file,
+		reader: bufio.NewReader(file),
 		events: make(chan Event, 100),
 		cancel: cancel,
 	}
@@ -254,10 +271,6 @@ func NewGtEventsSource(townRoot string) (*GtEventsSource, error) {
 func (s *GtEventsSource) tail(ctx context.Context) {
 	defer close(s.events)
 
-	// Seek to end for live tailing
-	_, _ = s.file.Seek(0, 2)
-
-	scanner := bufio.NewScanner(s.file)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -266,19 +279,64 @@ func (s *GtEventsSource) tail(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			for scanner.Scan() {
-				line := scanner.Text()
-				if event := parseGtEventLine(line); event != nil {
-					select {
-					case s.events <- *event:
-					default:
-					}
-				}
+			if !s.poll() {
+				return
 			}
 		}
 	}
 }
 
+// poll sends the lines appended since the last poll and follows rotation.
+// It reports false once the source is closed.
+func (s *GtEventsSource) poll() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+
+	rotated := events.ActiveLogReplaced(s.file, s.path)
+	s.drain()
+
+	// The log was rotated into a segment: follow the new active log from
+	// its start once the old one is drained
+	if rotated {
+		if next, err := os.Open(s.path); err == nil {
+			// The segment is complete, so a line without newline is whole
+			s.send(s.partial)
+			s.partial = ""
+			_ = s.file.Close()
+			s.file = next
+			s.reader.Reset(next)
+		}
+	}
+	return true
+}
+
+// drain sends every complete line available, keeping a trailing partial
+// line until the rest of it is written.
+func (s *GtEventsSource) drain() {
+	for {
+		chunk, err := s.reader.ReadString('\n')
+		s.partial += chunk
+		if err != nil {
+			return
+		}
+		s.send(s.partial)
+		s.partial = ""
+	}
+}
+
+// send parses a line and sends its event, dropping it if the channel is full.
+func (s *GtEventsSource) send(line string) {
+	if event := parseGtEventLine(line); event != nil {
+		select {
+		case s.events <- *event:
+		default:
+		}
+	}
+}
+
 // Events returns the event channel
 func (s *GtEventsSource) Events() <-chan Event {
 	return s.events
@@ -287,6 +345,12 @@ func (s *GtEventsSource) Events() <-chan Event {
 // Close stops the source
 func (s *GtEventsSource) Close() error {
 	s.cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
 	return s.file.Close()
 }
 
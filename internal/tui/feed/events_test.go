@@ -0,0 +1,76 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func TestGtEventsSourcePartialLines(t *testing.T) {
+	town := t.TempDir()
+	path := filepath.Join(town, events.EventsFile)
+	if err := os.WriteFile(path, []byte(`{"type":"old","actor":"mayor","visibility":"feed"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := NewGtEventsSource(town)
+	if err != nil {
+		t.Fatalf("NewGtEventsSource: %v", err)
+	}
+	defer source.Close()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// A line written in two parts, across polls, arrives whole
+	if _, err := f.WriteString(`{"type":"sling","actor":"gastown/polecats/nux",`); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(250 * time.Millisecond)
+	if _, err := f.WriteString(`"payload":{"bead":"gt-abc"},"visibility":"feed"}` + "\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-source.Events():
+		if e.Target != "gt-abc" || e.Actor != "gastown/polecats/nux" {
+			t.Errorf("event = %+v, want the sling of gt-abc", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event for the line written in two parts")
+	}
+}
+
+func TestGtEventsSourceCloseWhileTailing(t *testing.T) {
+	town := t.TempDir()
+	if err := os.WriteFile(filepath.Join(town, events.EventsFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := NewGtEventsSource(town)
+	if err != nil {
+		t.Fatalf("NewGtEventsSource: %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if err := source.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if err := source.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// The tail goroutine exits and closes the channel
+	select {
+	case _, ok := <-source.Events():
+		for ok {
+			_, ok = <-source.Events()
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("events channel not closed after Close")
+	}
+}
@@ -6,15 +6,20 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/activity"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // LiveConvoyFetcher fetches convoy data from beads.
 type LiveConvoyFetcher struct {
+	townRoot  string
 	townBeads string
 }
 
@@ -26,6 +31,7 @@ func NewLiveConvoyFetcher() (*LiveConvoyFetcher, error) {
 	}
 
 	return &LiveConvoyFetcher{
+		townRoot:  townRoot,
 		townBeads: filepath.Join(townRoot, ".beads"),
 	}, nil
 }
@@ -152,6 +158,42 @@ type trackedIssueInfo struct {
 
 // getTrackedIssues fetches tracked issues for a convoy.
 func (f *LiveConvoyFetcher) getTrackedIssues(convoyID string) []trackedIssueInfo {
+	issueIDs := f.getTrackedIDs(convoyID)
+
+	// Batch fetch issue details
+	details := f.getIssueDetailsBatch(issueIDs)
+
+	// Get worker activity from tmux sessions based on assignees
+	workers := f.getWorkersFromAssignees(details)
+
+	// Build result
+	result := make([]trackedIssueInfo, 0, len(issueIDs))
+	for _, id := range issueIDs {
+		info := trackedIssueInfo{ID: id}
+
+		if d, ok := details[id]; ok {
+			info.Title = d.Title
+			info.Status = d.Status
+			info.Assignee = d.Assignee
+			info.UpdatedAt = d.UpdatedAt
+		} else {
+			info.Title = "(external)"
+			info.Status = "unknown"
+		}
+
+		if w, ok := workers[id]; ok && w.LastActivity != nil {
+			info.LastActivity = *w.LastActivity
+		}
+
+		result = append(result, info)
+	}
+
+	return result
+}
+
+// getTrackedIDs returns the IDs of the issues a convoy tracks, with
+// external refs normalized.
+func (f *LiveConvoyFetcher) getTrackedIDs(convoyID string) []string {
 	dbPath := filepath.Join(f.townBeads, "beads.db")
 
 	// Query tracked dependencies from SQLite
@@ -187,35 +229,7 @@ func (f *LiveConvoyFetcher) getTrackedIssues(convoyID string) []trackedIssueInfo
 		issueIDs = append(issueIDs, issueID)
 	}
 
-	// Batch fetch issue details
-	details := f.getIssueDetailsBatch(issueIDs)
-
-	// Get worker activity from tmux sessions based on assignees
-	workers := f.getWorkersFromAssignees(details)
-
-	// Build result
-	result := make([]trackedIssueInfo, 0, len(issueIDs))
-	for _, id := range issueIDs {
-		info := trackedIssueInfo{ID: id}
-
-		if d, ok := details[id]; ok {
-			info.Title = d.Title
-			info.Status = d.Status
-			info.Assignee = d.Assignee
-			info.UpdatedAt = d.UpdatedAt
-		} else {
-			info.Title = "(external)"
-			info.Status = "unknown"
-		}
-
-		if w, ok := workers[id]; ok && w.LastActivity != nil {
-			info.LastActivity = *w.LastActivity
-		}
-
-		result = append(result, info)
-	}
-
-	return result
+	return issueIDs
 }
 
 // issueDetail holds basic issue info.
@@ -753,3 +767,53 @@ func parseActivityTimestamp(s string) (int64, bool) {
 	}
 	return unix, true
 }
+
+// FetchConflicts returns likely merge conflicts from each rig's most recent
+// conflict matrix (written by the daemon's conflict analyzer). Hunk-level
+// overlaps are listed first.
+func (f *LiveConvoyFetcher) FetchConflicts() ([]ConflictRow, error) {
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(f.townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading rigs config: %w", err)
+	}
+
+	rigNames := make([]string, 0, len(rigsConfig.Rigs))
+	for name := range rigsConfig.Rigs {
+		rigNames = append(rigNames, name)
+	}
+	sort.Strings(rigNames)
+
+	var hunks, files []ConflictRow
+	for _, rigName := range rigNames {
+		matrix, err := refinery.LoadConflictMatrix(filepath.Join(f.townRoot, rigName))
+		if err != nil || matrix == nil {
+			continue
+		}
+
+		for _, o := range matrix.Overlaps {
+			row := ConflictRow{
+				Rig:     rigName,
+				Branch:  o.A,
+				Polecat: matrix.Polecat(o.A),
+				Other:   o.B,
+				Target:  o.Target,
+				Level:   string(o.Level),
+			}
+			if !o.Target {
+				row.OtherPolecat = matrix.Polecat(o.B)
+			}
+
+			if o.Level == refinery.ConflictHunk {
+				row.Files = strings.Join(o.HunkFiles, ", ")
+				row.ColorClass = "mq-red"
+				hunks = append(hunks, row)
+			} else {
+				row.Files = strings.Join(o.Files, ", ")
+				row.ColorClass = "mq-yellow"
+				files = append(files, row)
+			}
+		}
+	}
+
+	return append(hunks, files...), nil
+}
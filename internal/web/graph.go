@@ -0,0 +1,122 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/dag"
+)
+
+// GraphFetcher fetches the dependency graph of a convoy or molecule.
+type GraphFetcher interface {
+	FetchGraph(id string) (*dag.Graph, error)
+}
+
+// GraphData is passed to the graph template.
+type GraphData struct {
+	ID    string
+	Graph *dag.Graph
+	SVG   template.HTML // rendered by dag, which escapes node text
+}
+
+// GraphHandler serves dependency graphs at /graph/<id>.
+// Append ?format=svg, dot or mermaid for the raw rendering (same as
+// gt convoy status --format).
+type GraphHandler struct {
+	fetcher  GraphFetcher
+	template *template.Template
+}
+
+// NewGraphHandler creates a graph handler with the given fetcher.
+func NewGraphHandler(fetcher GraphFetcher) (*GraphHandler, error) {
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GraphHandler{
+		fetcher:  fetcher,
+		template: tmpl,
+	}, nil
+}
+
+// ServeHTTP handles GET /graph/<id> requests.
+func (h *GraphHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/graph/"), "/")
+	if id == "" {
+		http.Error(w, "Missing bead ID", http.StatusBadRequest)
+		return
+	}
+
+	g, err := h.fetcher.FetchGraph(id)
+	if err != nil {
+		http.Error(w, "Failed to graph "+id, http.StatusNotFound)
+		return
+	}
+	// Nodes link to their timelines
+	for i := range g.Nodes {
+		g.Nodes[i].URL = "/trace/" + g.Nodes[i].ID
+	}
+
+	if format := r.URL.Query().Get("format"); format != "" {
+		out, err := dag.Render(g, format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if format == dag.FormatSVG {
+			w.Header().Set("Content-Type", "image/svg+xml")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		_, _ = w.Write([]byte(out))
+		return
+	}
+
+	data := GraphData{
+		ID:    id,
+		Graph: g,
+		SVG:   template.HTML(g.SVG()), // #nosec G203 -- dag.SVG escapes all node text
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.template.ExecuteTemplate(w, "graph.html", data); err != nil {
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+		return
+	}
+}
+
+// FetchGraph builds the graph of a convoy's tracked issues, or of a
+// molecule's steps for any other bead.
+func (f *LiveConvoyFetcher) FetchGraph(id string) (*dag.Graph, error) {
+	b := beads.New(f.townBeads)
+	root, err := b.Show(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.Type == "convoy" {
+		tracked := f.getTrackedIDs(id)
+		issues, err := b.ShowMultiple(tracked)
+		if err != nil {
+			return nil, err
+		}
+		rigOf := func(id string) string {
+			return beads.GetRigForPrefix(f.townRoot, beads.ExtractPrefix(id))
+		}
+		return beads.ConvoyGraph(root, tracked, issues, rigOf), nil
+	}
+
+	children, err := b.List(beads.ListOptions{Parent: id, Status: "all", Priority: -1})
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		return nil, fmt.Errorf("%s has no tracked issues or steps", id)
+	}
+	return beads.MoleculeGraph(root, children), nil
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/dag"
+)
+
+// MockGraphFetcher is a mock implementation for testing.
+type MockGraphFetcher struct{}
+
+func (m *MockGraphFetcher) FetchGraph(id string) (*dag.Graph, error) {
+	if id != "hq-cv-abc" {
+		return nil, errFetchFailed
+	}
+	g := dag.New("hq-cv-abc: Release <1.0>")
+	g.AddNode(dag.Node{ID: "bd-store", Label: "Storage", Status: dag.StatusDone, Group: "beads"})
+	g.AddNode(dag.Node{ID: "gt-api", Label: "API", Status: dag.StatusActive, Group: "gastown"})
+	g.AddEdge("bd-store", "gt-api")
+	return g, nil
+}
+
+func TestGraphHandler_RendersSVG(t *testing.T) {
+	handler, err := NewGraphHandler(&MockGraphFetcher{})
+	if err != nil {
+		t.Fatalf("NewGraphHandler() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/graph/hq-cv-abc", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"<svg ", `<a href="/trace/gt-api">`, "Release &lt;1.0&gt;", "1 dependencies"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Response should contain %q", want)
+		}
+	}
+}
+
+func TestGraphHandler_Formats(t *testing.T) {
+	handler, err := NewGraphHandler(&MockGraphFetcher{})
+	if err != nil {
+		t.Fatalf("NewGraphHandler() error = %v", err)
+	}
+
+	tests := []struct {
+		format      string
+		contentType string
+		want        string
+	}{
+		{"svg", "image/svg+xml", "<svg "},
+		{"dot", "text/plain; charset=utf-8", `"bd-store" -> "gt-api";`},
+		{"mermaid", "text/plain; charset=utf-8", "n0 --> n1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/graph/hq-cv-abc?format="+tt.format, nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s Content-Type = %q, want %q", tt.format, ct, tt.contentType)
+		}
+		if !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s output missing %q:\n%s", tt.format, tt.want, w.Body.String())
+		}
+	}
+}
+
+func TestGraphHandler_Errors(t *testing.T) {
+	handler, err := NewGraphHandler(&MockGraphFetcher{})
+	if err != nil {
+		t.Fatalf("NewGraphHandler() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/graph/", http.StatusBadRequest},
+		{"/graph/gt-missing", http.StatusNotFound},
+		{"/graph/hq-cv-abc?format=png", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, tt.want)
+		}
+	}
+}
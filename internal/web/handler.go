@@ -10,6 +10,7 @@ type ConvoyFetcher interface {
 	FetchConvoys() ([]ConvoyRow, error)
 	FetchMergeQueue() ([]MergeQueueRow, error)
 	FetchPolecats() ([]PolecatRow, error)
+	FetchConflicts() ([]ConflictRow, error)
 }
 
 // ConvoyHandler handles HTTP requests for the convoy dashboard.
@@ -51,10 +52,17 @@ func (h *ConvoyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		polecats = nil
 	}
 
+	conflicts, err := h.fetcher.FetchConflicts()
+	if err != nil {
+		// Non-fatal: show convoys even if conflict analysis is unavailable
+		conflicts = nil
+	}
+
 	data := ConvoyData{
 		Convoys:    convoys,
 		MergeQueue: mergeQueue,
 		Polecats:   polecats,
+		Conflicts:  conflicts,
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
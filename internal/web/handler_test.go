@@ -20,6 +20,7 @@ type MockConvoyFetcher struct {
 	Convoys    []ConvoyRow
 	MergeQueue []MergeQueueRow
 	Polecats   []PolecatRow
+	Conflicts  []ConflictRow
 	Error      error
 }
 
@@ -35,6 +36,10 @@ func (m *MockConvoyFetcher) FetchPolecats() ([]PolecatRow, error) {
 	return m.Polecats, nil
 }
 
+func (m *MockConvoyFetcher) FetchConflicts() ([]ConflictRow, error) {
+	return m.Conflicts, nil
+}
+
 func TestConvoyHandler_RendersTemplate(t *testing.T) {
 	mock := &MockConvoyFetcher{
 		Convoys: []ConvoyRow{
@@ -944,12 +949,52 @@ func TestE2E_Server_RefineryInPolecats(t *testing.T) {
 	}
 }
 
+func TestConvoyHandler_ConflictRisk(t *testing.T) {
+	mock := &MockConvoyFetcher{
+		Conflicts: []ConflictRow{
+			{
+				Rig:          "roxas",
+				Branch:       "polecat/dag-123",
+				Polecat:      "dag",
+				Other:        "polecat/nux-456",
+				OtherPolecat: "nux",
+				Level:        "hunk",
+				Files:        "internal/foo.go",
+				ColorClass:   "mq-red",
+			},
+		},
+	}
+
+	handler, err := NewConvoyHandler(mock)
+	if err != nil {
+		t.Fatalf("NewConvoyHandler() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	body := w.Body.String()
+
+	if !strings.Contains(body, "Conflict Risk") {
+		t.Error("Response should contain Conflict Risk section")
+	}
+	if !strings.Contains(body, "internal/foo.go") {
+		t.Error("Response should contain overlapping file")
+	}
+	if !strings.Contains(body, "nux") {
+		t.Error("Response should contain the other polecat")
+	}
+}
+
 // Test that merge queue and polecat errors are non-fatal
 
 type MockConvoyFetcherWithErrors struct {
 	Convoys          []ConvoyRow
 	MergeQueueError  error
 	PolecatsError    error
+	ConflictsError   error
 }
 
 func (m *MockConvoyFetcherWithErrors) FetchConvoys() ([]ConvoyRow, error) {
@@ -964,6 +1009,10 @@ func (m *MockConvoyFetcherWithErrors) FetchPolecats() ([]PolecatRow, error) {
 	return nil, m.PolecatsError
 }
 
+func (m *MockConvoyFetcherWithErrors) FetchConflicts() ([]ConflictRow, error) {
+	return nil, m.ConflictsError
+}
+
 func TestConvoyHandler_NonFatalErrors(t *testing.T) {
 	mock := &MockConvoyFetcherWithErrors{
 		Convoys: []ConvoyRow{
@@ -971,6 +1020,7 @@ func TestConvoyHandler_NonFatalErrors(t *testing.T) {
 		},
 		MergeQueueError: errFetchFailed,
 		PolecatsError:   errFetchFailed,
+		ConflictsError:  errFetchFailed,
 	}
 
 	handler, err := NewConvoyHandler(mock)
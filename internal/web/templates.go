@@ -3,8 +3,10 @@ package web
 
 import (
 	"embed"
+	"fmt"
 	"html/template"
 	"io/fs"
+	"time"
 
 	"github.com/steveyegge/gastown/internal/activity"
 )
@@ -17,6 +19,20 @@ type ConvoyData struct {
 	Convoys    []ConvoyRow
 	MergeQueue []MergeQueueRow
 	Polecats   []PolecatRow
+	Conflicts  []ConflictRow
+}
+
+// ConflictRow represents a likely merge conflict between in-flight branches.
+type ConflictRow struct {
+	Rig          string
+	Branch       string
+	Polecat      string
+	Other        string // Other branch, or the merge target
+	OtherPolecat string
+	Target       bool   // Other is the merge target
+	Level        string // "hunk" or "file"
+	Files        string // Comma-separated overlapping files
+	ColorClass   string // "mq-red" for hunk overlaps, "mq-yellow" for file overlaps
 }
 
 // PolecatRow represents a polecat worker in the dashboard.
@@ -68,6 +84,7 @@ func LoadTemplates() (*template.Template, error) {
 		"statusClass":     statusClass,
 		"workStatusClass": workStatusClass,
 		"progressPercent": progressPercent,
+		"formatSpan":      formatSpan,
 	}
 
 	// Get the templates subdirectory
@@ -136,3 +153,15 @@ func progressPercent(completed, total int) int {
 	}
 	return (completed * 100) / total
 }
+
+// formatSpan formats a phase duration, e.g. "2h 5m" or "40s".
+func formatSpan(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
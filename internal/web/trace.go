@@ -0,0 +1,70 @@
+package web
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/trace"
+)
+
+// TraceFetcher fetches the lifecycle timeline of a bead or convoy.
+type TraceFetcher interface {
+	FetchTrace(id string) (*trace.Timeline, error)
+}
+
+// TraceHandler serves bead timelines at /trace/<id>.
+// Append ?format=json for the raw timeline (same shape as gt trace --json).
+type TraceHandler struct {
+	fetcher  TraceFetcher
+	template *template.Template
+}
+
+// NewTraceHandler creates a trace handler with the given fetcher.
+func NewTraceHandler(fetcher TraceFetcher) (*TraceHandler, error) {
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TraceHandler{
+		fetcher:  fetcher,
+		template: tmpl,
+	}, nil
+}
+
+// ServeHTTP handles GET /trace/<id> requests.
+func (h *TraceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/trace/"), "/")
+	if id == "" {
+		http.Error(w, "Missing bead ID", http.StatusBadRequest)
+		return
+	}
+
+	tl, err := h.fetcher.FetchTrace(id)
+	if err != nil {
+		http.Error(w, "Failed to trace "+id, http.StatusNotFound)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(tl)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.template.ExecuteTemplate(w, "trace.html", tl); err != nil {
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+		return
+	}
+}
+
+// FetchTrace builds the timeline for a bead or convoy.
+func (f *LiveConvoyFetcher) FetchTrace(id string) (*trace.Timeline, error) {
+	return trace.New(f.townRoot).Trace(id)
+}
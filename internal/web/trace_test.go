@@ -0,0 +1,112 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/trace"
+)
+
+// MockTraceFetcher is a mock implementation for testing.
+type MockTraceFetcher struct {
+	Timelines map[string]*trace.Timeline
+}
+
+func (m *MockTraceFetcher) FetchTrace(id string) (*trace.Timeline, error) {
+	if tl, ok := m.Timelines[id]; ok {
+		return tl, nil
+	}
+	return nil, errFetchFailed
+}
+
+func newMockTrace() *MockTraceFetcher {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	return &MockTraceFetcher{Timelines: map[string]*trace.Timeline{
+		"gt-abc": {
+			Bead:   "gt-abc",
+			Title:  "Fix the widget",
+			Status: "closed",
+			Rig:    "gastown",
+			Phase:  trace.PhaseMerged,
+			Phases: []trace.Span{
+				{Phase: trace.PhaseQueued, Start: start, End: start.Add(10 * time.Minute), Duration: 10 * time.Minute},
+				{Phase: trace.PhaseWorking, Start: start.Add(10 * time.Minute), End: start.Add(2 * time.Hour), Duration: 110 * time.Minute},
+				{Phase: trace.PhaseMerged, Start: start.Add(2 * time.Hour), End: start.Add(2 * time.Hour)},
+			},
+			Entries: []trace.Entry{
+				{Timestamp: start, Bead: "gt-abc", Source: trace.SourceBeads, Type: "created", Summary: "Bead created"},
+				{Timestamp: start.Add(2 * time.Hour), Bead: "gt-abc", Source: trace.SourceMQ, Type: "merged", Summary: "Merged polecat/Toast-1", Milestone: trace.PhaseMerged},
+			},
+		},
+	}}
+}
+
+func TestTraceHandler_RendersTimeline(t *testing.T) {
+	handler, err := NewTraceHandler(newMockTrace())
+	if err != nil {
+		t.Fatalf("NewTraceHandler() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/trace/gt-abc", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"gt-abc", "Fix the widget", "1h 50m", "Merged polecat/Toast-1", "milestone"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Response should contain %q", want)
+		}
+	}
+}
+
+func TestTraceHandler_JSON(t *testing.T) {
+	handler, err := NewTraceHandler(newMockTrace())
+	if err != nil {
+		t.Fatalf("NewTraceHandler() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/trace/gt-abc?format=json", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var tl trace.Timeline
+	if err := json.Unmarshal(w.Body.Bytes(), &tl); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if tl.Bead != "gt-abc" || len(tl.Phases) != 3 {
+		t.Errorf("timeline = %+v", tl)
+	}
+}
+
+func TestTraceHandler_Errors(t *testing.T) {
+	handler, err := NewTraceHandler(newMockTrace())
+	if err != nil {
+		t.Fatalf("NewTraceHandler() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/trace/", http.StatusBadRequest},
+		{"/trace/gt-missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, tt.want)
+		}
+	}
+}